@@ -2,13 +2,16 @@
 package analytics
 
 import (
+	"time"
+
 	"github.com/cherry-pick/pkg/analytics/core"
 	"github.com/cherry-pick/pkg/analytics/services"
 	"github.com/cherry-pick/pkg/analytics/storage"
 )
 
 type Analytics struct {
-	service core.AnalyticsService
+	service    core.AnalyticsService
+	calculator *services.CalculatorService
 }
 
 func NewAnalytics() *Analytics {
@@ -17,12 +20,16 @@ func NewAnalytics() *Analytics {
 	calculator := services.NewCalculatorService(storage)
 	aggregator := services.NewAggregatorService(storage)
 	tracker := services.NewTrackerService(storage, validator)
+	tracker.SetResolvers(services.NewBasicUserAgentResolver(), nil, core.DefaultResolverCacheSize)
 	processor := services.NewProcessorService(storage, calculator, aggregator)
 	reporter := services.NewReporterService(storage, processor, aggregator)
 	notifier := services.NewNotifierService()
 	service := services.NewAnalyticsService(tracker, processor, reporter, storage, validator, notifier)
+	service.SetRetentionPolicy(core.DefaultRetentionPolicy())
+	service.SetInsightQueue(core.DefaultWorkQueueConfig())
 	return &Analytics{
-		service: service,
+		service:    service,
+		calculator: calculator,
 	}
 }
 
@@ -30,6 +37,63 @@ func (a *Analytics) GetService() core.AnalyticsService {
 	return a.service
 }
 
+// SetRetentionPolicy restarts background purging with the given per-type windows.
+func (a *Analytics) SetRetentionPolicy(policy core.RetentionPolicy) error {
+	if service, ok := a.service.(*services.AnalyticsService); ok {
+		return service.SetRetentionPolicy(policy)
+	}
+	return nil
+}
+
+// SetPerformanceScoreConfig changes how performance events are scored, for teams that
+// weigh some Web Vitals more than others.
+func (a *Analytics) SetPerformanceScoreConfig(config core.PerformanceScoreConfig) error {
+	return a.calculator.SetPerformanceScoreConfig(config)
+}
+
+// SetRealTimeWindow sets how far back real-time metrics look for active sessions.
+func (a *Analytics) SetRealTimeWindow(window time.Duration) error {
+	if service, ok := a.service.(*services.AnalyticsService); ok {
+		return service.SetRealTimeWindow(window)
+	}
+	return nil
+}
+
+// SetInsightQueue resizes the worker pool that generates insights after page views.
+func (a *Analytics) SetInsightQueue(config core.WorkQueueConfig) error {
+	if service, ok := a.service.(*services.AnalyticsService); ok {
+		return service.SetInsightQueue(config)
+	}
+	return nil
+}
+
+// AddSink writes tracked page views and insights to sink as well as to storage.
+func (a *Analytics) AddSink(sink core.ActivitySink) {
+	if service, ok := a.service.(*services.AnalyticsService); ok {
+		service.AddSink(sink)
+	}
+}
+
+// AddFileSink writes page views and insights as JSON lines to a rotating file.
+func (a *Analytics) AddFileSink(config core.FileSinkConfig) error {
+	sink, err := services.NewFileSink(config)
+	if err != nil {
+		return err
+	}
+	a.AddSink(sink)
+	return nil
+}
+
+// Close stops background retention enforcement, drains the insight queue and closes
+// any activity sinks.
+func (a *Analytics) Close() {
+	if service, ok := a.service.(*services.AnalyticsService); ok {
+		service.StopRetention()
+		service.StopInsightQueue()
+		service.CloseSinks()
+	}
+}
+
 func (a *Analytics) GetTracker() core.AnalyticsTracker {
 	if service, ok := a.service.(*services.AnalyticsService); ok {
 		return service.GetTracker()
@@ -16,6 +16,14 @@ type AnalyticsTracker interface {
 	EndSession(sessionID string) error
 }
 
+type UserAgentResolver interface {
+	Resolve(userAgent string) (*UserAgentInfo, error)
+}
+
+type GeoIPResolver interface {
+	Lookup(ip string) (*GeoInfo, error)
+}
+
 type AnalyticsProcessor interface {
 	ProcessUserJourney(sessionID string) (*UserJourney, error)
 	ProcessFunnelAnalysis(funnelID string, startTime, endTime time.Time) (*FunnelAnalysis, error)
@@ -23,6 +31,7 @@ type AnalyticsProcessor interface {
 	ProcessInsights(sessionID string) ([]AnalyticsInsight, error)
 	ProcessAlerts() ([]AnalyticsAlert, error)
 	ProcessHeatmapData(pagePath string, startTime, endTime time.Time) ([]HeatmapPoint, error)
+	ProcessAttribution(model string) (*AttributionReport, error)
 }
 
 type AnalyticsReporter interface {
@@ -31,6 +40,7 @@ type AnalyticsReporter interface {
 	GenerateInsights(startTime, endTime time.Time) ([]AnalyticsInsight, error)
 	GenerateFunnelReport(funnelID string, startTime, endTime time.Time) (*FunnelAnalysis, error)
 	GeneratePerformanceReport(startTime, endTime time.Time) ([]PerformanceEvent, error)
+	GenerateWebVitalsReport(pagePath string, startTime, endTime time.Time) (*WebVitalsReport, error)
 	GenerateBehavioralReport(startTime, endTime time.Time) ([]BehavioralEvent, error)
 }
 
@@ -41,6 +51,7 @@ type AnalyticsStorage interface {
 	SaveInsight(insight AnalyticsInsight) error
 	SaveAlert(alert AnalyticsAlert) error
 	SaveReport(report AnalyticsReport) error
+	SaveFunnel(funnel FunnelDefinition) error
 
 	GetEvents(request AnalyticsRequest) ([]AnalyticsEvent, error)
 	GetSessions(request AnalyticsRequest) ([]UserSession, error)
@@ -48,17 +59,20 @@ type AnalyticsStorage interface {
 	GetInsights(request AnalyticsRequest) ([]AnalyticsInsight, error)
 	GetAlerts(request AnalyticsRequest) ([]AnalyticsAlert, error)
 	GetReports(request AnalyticsRequest) ([]AnalyticsReport, error)
+	GetFunnels() ([]FunnelDefinition, error)
 
 	GetSession(sessionID string) (*UserSession, error)
 	GetJourney(sessionID string) (*UserJourney, error)
 	GetInsight(insightID string) (*AnalyticsInsight, error)
 	GetAlert(alertID string) (*AnalyticsAlert, error)
 	GetReport(reportID string) (*AnalyticsReport, error)
+	GetFunnel(funnelID string) (*FunnelDefinition, error)
 
 	UpdateSession(session UserSession) error
 	UpdateJourney(journey UserJourney) error
 	UpdateInsight(insight AnalyticsInsight) error
 	UpdateAlert(alert AnalyticsAlert) error
+	UpdateFunnel(funnel FunnelDefinition) error
 
 	DeleteEvent(eventID string) error
 	DeleteSession(sessionID string) error
@@ -66,8 +80,10 @@ type AnalyticsStorage interface {
 	DeleteInsight(insightID string) error
 	DeleteAlert(alertID string) error
 	DeleteReport(reportID string) error
+	DeleteFunnel(funnelID string) error
 
 	CleanupOldData(olderThan time.Time) error
+	CleanupExpired(cutoffs RetentionCutoffs) (map[string]int, error)
 	GetStats() (map[string]interface{}, error)
 }
 
@@ -83,21 +99,35 @@ type AnalyticsService interface {
 	EndSession(sessionID string) error
 
 	GetUserJourney(sessionID string) (*UserJourney, error)
+	GetAttributionReport(model string) (*AttributionReport, error)
 	GetFunnelAnalysis(funnelID string, startTime, endTime time.Time) (*FunnelAnalysis, error)
 	GetRealTimeMetrics() (*RealTimeMetrics, error)
 	GetInsights(sessionID string) ([]AnalyticsInsight, error)
+	QueryInsights(sessionID string, query InsightQuery) (*InsightPage, error)
 	GetAlerts() ([]AnalyticsAlert, error)
 	GetHeatmapData(pagePath string, startTime, endTime time.Time) ([]HeatmapPoint, error)
+	GetHeatmapImage(pagePath string, startTime, endTime time.Time, width, height int) ([]byte, error)
+
+	CreateFunnel(funnel FunnelDefinition) (*FunnelDefinition, error)
+	GetFunnel(funnelID string) (*FunnelDefinition, error)
+	ListFunnels() ([]FunnelDefinition, error)
+	UpdateFunnel(funnel FunnelDefinition) (*FunnelDefinition, error)
+	DeleteFunnel(funnelID string) error
 
 	GenerateReport(request AnalyticsRequest) (*AnalyticsReport, error)
+	GetReport(reportID string) (*AnalyticsReport, error)
+	CompareAnalyticsReports(a, b *AnalyticsReport) *AnalyticsReportDiff
 	GenerateSummary(startTime, endTime time.Time) (*AnalyticsSummary, error)
 	GenerateInsights(startTime, endTime time.Time) ([]AnalyticsInsight, error)
 	GenerateFunnelReport(funnelID string, startTime, endTime time.Time) (*FunnelAnalysis, error)
 	GeneratePerformanceReport(startTime, endTime time.Time) ([]PerformanceEvent, error)
+	GetWebVitalsReport(pagePath string, startTime, endTime time.Time) (*WebVitalsReport, error)
 	GenerateBehavioralReport(startTime, endTime time.Time) ([]BehavioralEvent, error)
 
 	SubscribeToRealTimeMetrics(ctx context.Context) (<-chan RealTimeMetrics, error)
+	SubscribeWithOptions(ctx context.Context, options SubscriberOptions) (string, <-chan RealTimeMetrics, error)
 	UnsubscribeFromRealTimeMetrics(subscriberID string) error
+	GetSubscriberStats() []SubscriberStats
 
 	CleanupOldData(olderThan time.Time) error
 	GetStats() (map[string]interface{}, error)
@@ -108,6 +138,7 @@ type AnalyticsValidator interface {
 	ValidateSession(session UserSession) error
 	ValidateJourney(journey UserJourney) error
 	ValidateRequest(request AnalyticsRequest) error
+	ValidateFunnel(funnel FunnelDefinition) error
 }
 
 type AnalyticsNotifier interface {
@@ -116,6 +147,13 @@ type AnalyticsNotifier interface {
 	SendReport(report AnalyticsReport) error
 }
 
+// ActivitySink receives page views and insights as they are tracked, independently of
+// any terminal output.
+type ActivitySink interface {
+	WriteRecord(record ActivityRecord) error
+	Close() error
+}
+
 type AnalyticsAggregator interface {
 	AggregatePageViews(startTime, endTime time.Time) ([]PageStats, error)
 	AggregateUserSessions(startTime, endTime time.Time) ([]UserSession, error)
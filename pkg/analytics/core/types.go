@@ -1,6 +1,9 @@
 package core
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type AnalyticsEvent struct {
 	ID        string                 `json:"id"`
@@ -67,6 +70,7 @@ type PerformanceEvent struct {
 	LargestContentfulPaint int64   `json:"largestContentfulPaint"`
 	CumulativeLayoutShift  float64 `json:"cumulativeLayoutShift"`
 	FirstInputDelay        int64   `json:"firstInputDelay"`
+	InteractionToNextPaint int64   `json:"interactionToNextPaint,omitempty"`
 	TimeToInteractive      int64   `json:"timeToInteractive"`
 	TotalBlockingTime      int64   `json:"totalBlockingTime"`
 	SpeedIndex             int64   `json:"speedIndex"`
@@ -80,18 +84,21 @@ type PerformanceEvent struct {
 }
 
 type UserJourney struct {
-	SessionID      string     `json:"sessionId"`
-	UserID         string     `json:"userId,omitempty"`
-	StartTime      time.Time  `json:"startTime"`
-	EndTime        *time.Time `json:"endTime,omitempty"`
-	TotalPages     int        `json:"totalPages"`
-	TotalTime      int64      `json:"totalTime"`
-	BounceRate     bool       `json:"bounceRate"`
-	ConversionRate float64    `json:"conversionRate"`
-	GoalCompleted  bool       `json:"goalCompleted"`
-	FunnelStage    string     `json:"funnelStage,omitempty"`
-	JourneyPath    []string   `json:"journeyPath"`
-	DropOffPoint   string     `json:"dropOffPoint,omitempty"`
+	SessionID          string     `json:"sessionId"`
+	UserID             string     `json:"userId,omitempty"`
+	StartTime          time.Time  `json:"startTime"`
+	EndTime            *time.Time `json:"endTime,omitempty"`
+	TotalPages         int        `json:"totalPages"`
+	TotalTime          int64      `json:"totalTime"`
+	BounceRate         bool       `json:"bounceRate"`
+	ConversionRate     float64    `json:"conversionRate"`
+	GoalCompleted      bool       `json:"goalCompleted"`
+	CompletedGoals     []string   `json:"completedGoals,omitempty"`
+	FunnelStage        string     `json:"funnelStage,omitempty"`
+	JourneyPath        []string   `json:"journeyPath"`
+	DropOffPoint       string     `json:"dropOffPoint,omitempty"`
+	FirstTouchReferrer string     `json:"firstTouchReferrer,omitempty"`
+	LastTouchReferrer  string     `json:"lastTouchReferrer,omitempty"`
 }
 
 type FunnelAnalysis struct {
@@ -127,8 +134,258 @@ type FunnelBottleneck struct {
 	Recommendations []string `json:"recommendations"`
 }
 
+// ConversionGoal is met by any event satisfying every non-empty criterion.
+type ConversionGoal struct {
+	Name        string `json:"name"`
+	Path        string `json:"path,omitempty"`
+	EventType   string `json:"eventType,omitempty"`
+	CustomEvent string `json:"customEvent,omitempty"`
+}
+
+const (
+	AttributionFirstTouch = "first-touch"
+	AttributionLastTouch  = "last-touch"
+	AttributionLinear     = "linear"
+
+	// AttributionDirect is credited with conversions whose journey had no external referrer.
+	AttributionDirect = "direct"
+)
+
+// AttributionSource is the credit one referrer host earned. Conversions is fractional
+// under the linear model, which splits each conversion across every touch.
+type AttributionSource struct {
+	Source      string  `json:"source"`
+	Conversions float64 `json:"conversions"`
+	Share       float64 `json:"share"`
+}
+
+type AttributionReport struct {
+	Model            string              `json:"model"`
+	TotalJourneys    int                 `json:"totalJourneys"`
+	TotalConversions int                 `json:"totalConversions"`
+	Sources          []AttributionSource `json:"sources"`
+	GeneratedAt      time.Time           `json:"generatedAt"`
+}
+
+type FunnelMatchType string
+
+const (
+	FunnelMatchEquals FunnelMatchType = "equals"
+	FunnelMatchPrefix FunnelMatchType = "prefix"
+	FunnelMatchRegex  FunnelMatchType = "regex"
+)
+
+type FunnelStageRule struct {
+	Match FunnelMatchType `json:"match"`
+	Value string          `json:"value"`
+}
+
+type FunnelStageDefinition struct {
+	ID    string            `json:"id"`
+	Name  string            `json:"name"`
+	Rules []FunnelStageRule `json:"rules"`
+}
+
+type FunnelDefinition struct {
+	ID          string                  `json:"id"`
+	Name        string                  `json:"name"`
+	Description string                  `json:"description,omitempty"`
+	Stages      []FunnelStageDefinition `json:"stages"`
+	CreatedAt   time.Time               `json:"createdAt"`
+	UpdatedAt   time.Time               `json:"updatedAt"`
+}
+
+type DropPolicy string
+
+const (
+	DropPolicyDropNewest DropPolicy = "drop"
+	DropPolicyCoalesce   DropPolicy = "coalesce"
+
+	DefaultSubscriberBuffer = 10
+	MaxSubscriberBuffer     = 1000
+)
+
+type SubscriberOptions struct {
+	BufferSize int        `json:"bufferSize"`
+	DropPolicy DropPolicy `json:"dropPolicy"`
+}
+
+type SubscriberStats struct {
+	ID           string     `json:"id"`
+	BufferSize   int        `json:"bufferSize"`
+	DropPolicy   DropPolicy `json:"dropPolicy"`
+	Pending      int        `json:"pending"`
+	Delivered    int64      `json:"delivered"`
+	Dropped      int64      `json:"dropped"`
+	SubscribedAt time.Time  `json:"subscribedAt"`
+	LastDropAt   *time.Time `json:"lastDropAt,omitempty"`
+}
+
+const (
+	DefaultAnomalyAlpha     = 0.3
+	DefaultAnomalyThreshold = 3.0
+	DefaultAnomalyWarmup    = 10
+)
+
+// AnomalyConfig tunes the EWMA baseline: Threshold is in standard deviations and
+// no value is flagged until WarmupSamples observations have been seen.
+type AnomalyConfig struct {
+	Alpha         float64 `json:"alpha"`
+	Threshold     float64 `json:"threshold"`
+	WarmupSamples int     `json:"warmupSamples"`
+}
+
+type UserAgentInfo struct {
+	Browser string `json:"browser"`
+	OS      string `json:"os"`
+	Device  string `json:"device"`
+}
+
+type GeoInfo struct {
+	Country string `json:"country"`
+	Region  string `json:"region,omitempty"`
+	City    string `json:"city,omitempty"`
+}
+
+const DefaultResolverCacheSize = 1024
+
+// RetentionPolicy sets how long each kind of record is kept. A zero window keeps
+// that kind forever; Interval is how often the enforcer sweeps.
+type RetentionPolicy struct {
+	Events   time.Duration `json:"events"`
+	Sessions time.Duration `json:"sessions"`
+	Journeys time.Duration `json:"journeys"`
+	Insights time.Duration `json:"insights"`
+	Alerts   time.Duration `json:"alerts"`
+	Reports  time.Duration `json:"reports"`
+	Interval time.Duration `json:"interval"`
+}
+
+func DefaultRetentionPolicy() RetentionPolicy {
+	return RetentionPolicy{
+		Events:   30 * 24 * time.Hour,
+		Sessions: 30 * 24 * time.Hour,
+		Journeys: 30 * 24 * time.Hour,
+		Insights: 90 * 24 * time.Hour,
+		Alerts:   90 * 24 * time.Hour,
+		Reports:  180 * 24 * time.Hour,
+		Interval: time.Hour,
+	}
+}
+
+// RetentionCutoffs holds the per-type instant before which records are purged; a zero
+// time leaves that type untouched.
+type RetentionCutoffs struct {
+	Events   time.Time
+	Sessions time.Time
+	Journeys time.Time
+	Insights time.Time
+	Alerts   time.Time
+	Reports  time.Time
+}
+
+type RetentionResult struct {
+	RanAt    time.Time      `json:"ranAt"`
+	Purged   map[string]int `json:"purged"`
+	Duration time.Duration  `json:"duration"`
+}
+
+const (
+	DefaultInsightWorkers   = 4
+	DefaultInsightQueueSize = 1024
+)
+
+// WorkQueueConfig bounds background insight generation: Workers goroutines drain a
+// queue of at most QueueSize jobs, and jobs submitted while it is full are dropped.
+type WorkQueueConfig struct {
+	Workers   int `json:"workers"`
+	QueueSize int `json:"queueSize"`
+}
+
+func DefaultWorkQueueConfig() WorkQueueConfig {
+	return WorkQueueConfig{
+		Workers:   DefaultInsightWorkers,
+		QueueSize: DefaultInsightQueueSize,
+	}
+}
+
+// MetricScore weighs one metric in the performance score. The metric scores 1 at zero
+// and falls linearly to 0 at Threshold, in the metric's own unit.
+type MetricScore struct {
+	Weight    float64 `json:"weight"`
+	Threshold float64 `json:"threshold"`
+}
+
+// PerformanceScoreConfig sets how much each metric counts toward a page's performance
+// score. Weights are relative: the score is divided by their sum.
+type PerformanceScoreConfig struct {
+	LoadTime               MetricScore `json:"loadTime"`
+	FirstContentfulPaint   MetricScore `json:"firstContentfulPaint"`
+	LargestContentfulPaint MetricScore `json:"largestContentfulPaint"`
+	CumulativeLayoutShift  MetricScore `json:"cumulativeLayoutShift"`
+	FirstInputDelay        MetricScore `json:"firstInputDelay"`
+}
+
+func DefaultPerformanceScoreConfig() PerformanceScoreConfig {
+	return PerformanceScoreConfig{
+		LoadTime:               MetricScore{Weight: 0.3, Threshold: 5000},
+		FirstContentfulPaint:   MetricScore{Weight: 0.3, Threshold: 3000},
+		LargestContentfulPaint: MetricScore{Weight: 0.2, Threshold: 4000},
+		CumulativeLayoutShift:  MetricScore{Weight: 0.1, Threshold: 0.25},
+		FirstInputDelay:        MetricScore{Weight: 0.1, Threshold: 300},
+	}
+}
+
+type WorkQueueStats struct {
+	Workers   int   `json:"workers"`
+	Capacity  int   `json:"capacity"`
+	Queued    int   `json:"queued"`
+	Active    int64 `json:"active"`
+	Processed int64 `json:"processed"`
+	Failed    int64 `json:"failed"`
+	Dropped   int64 `json:"dropped"`
+}
+
+const (
+	ActivityPageView = "page_view"
+	ActivityInsight  = "insight"
+)
+
+// ActivityRecord is one line of the structured activity log written to sinks.
+type ActivityRecord struct {
+	Type        string      `json:"type"`
+	Timestamp   time.Time   `json:"timestamp"`
+	SessionID   string      `json:"sessionId,omitempty"`
+	WorkspaceID string      `json:"workspaceId,omitempty"`
+	Data        interface{} `json:"data"`
+}
+
+// FileSinkConfig rotates the activity log once it reaches MaxSizeBytes or has been open
+// for MaxAge; zero disables that trigger. Rotated files get a timestamp suffix and only
+// the newest MaxBackups are kept, or all of them when MaxBackups is zero.
+type FileSinkConfig struct {
+	Path         string        `json:"path"`
+	MaxSizeBytes int64         `json:"maxSizeBytes"`
+	MaxAge       time.Duration `json:"maxAge"`
+	MaxBackups   int           `json:"maxBackups"`
+}
+
+type ResolverCacheStats struct {
+	Capacity  int   `json:"capacity"`
+	Size      int   `json:"size"`
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions"`
+}
+
+// DefaultRealTimeWindow is how far back real-time metrics look for activity.
+const DefaultRealTimeWindow = 5 * time.Minute
+
+// RealTimeMetrics covers the WindowSeconds before Timestamp. A session is active when it
+// started or had an event in that window.
 type RealTimeMetrics struct {
 	Timestamp          time.Time        `json:"timestamp"`
+	WindowSeconds      int              `json:"windowSeconds"`
 	ActiveUsers        int              `json:"activeUsers"`
 	ActiveSessions     int              `json:"activeSessions"`
 	PageViewsPerMinute int              `json:"pageViewsPerMinute"`
@@ -203,6 +460,33 @@ type AnalyticsInsight struct {
 	Actionable      bool                   `json:"actionable"`
 }
 
+const (
+	InsightSortTimestamp  = "timestamp"
+	InsightSortConfidence = "confidence"
+
+	DefaultInsightPageSize = 50
+	MaxInsightPageSize     = 500
+)
+
+// InsightQuery filters, orders and pages insights. Empty filters match every insight and
+// Actionable is ignored when nil.
+type InsightQuery struct {
+	Type       string
+	Impact     string
+	Actionable *bool
+	Sort       string
+	Limit      int
+	Offset     int
+}
+
+// InsightPage is one page of a query; Total counts the matches before paging.
+type InsightPage struct {
+	Insights []AnalyticsInsight `json:"insights"`
+	Total    int                `json:"total"`
+	Limit    int                `json:"limit"`
+	Offset   int                `json:"offset"`
+}
+
 type AnalyticsReport struct {
 	ID              string                 `json:"id"`
 	Title           string                 `json:"title"`
@@ -217,6 +501,7 @@ type AnalyticsReport struct {
 	BehavioralData  []BehavioralEvent      `json:"behavioralData,omitempty"`
 	Insights        []AnalyticsInsight     `json:"insights"`
 	Recommendations []string               `json:"recommendations"`
+	TopPages        []PageStats            `json:"topPages,omitempty"`
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`
 }
 
@@ -236,6 +521,67 @@ type AnalyticsSummary struct {
 	TopBrowser         string  `json:"topBrowser"`
 }
 
+const (
+	DefaultReportTopPages = 10
+
+	DirectionUp        = "up"
+	DirectionDown      = "down"
+	DirectionUnchanged = "unchanged"
+	DirectionNew       = "new"
+	DirectionDropped   = "dropped"
+)
+
+// ReportComparisonRequest names two saved reports by ID, or carries them inline.
+type ReportComparisonRequest struct {
+	BaseReportID    string           `json:"baseReportId,omitempty"`
+	CompareReportID string           `json:"compareReportId,omitempty"`
+	Base            *AnalyticsReport `json:"base,omitempty"`
+	Compare         *AnalyticsReport `json:"compare,omitempty"`
+}
+
+// AnalyticsReportDiff describes how the compared report moved against the base one.
+type AnalyticsReportDiff struct {
+	BaseReportID     string             `json:"baseReportId"`
+	CompareReportID  string             `json:"compareReportId"`
+	Metrics          []MetricDelta      `json:"metrics"`
+	TopPages         []PageRankChange   `json:"topPages"`
+	NewInsights      []AnalyticsInsight `json:"newInsights"`
+	ResolvedInsights []AnalyticsInsight `json:"resolvedInsights"`
+	GeneratedAt      time.Time          `json:"generatedAt"`
+}
+
+// MetricDelta is the change in one summary metric. PercentChange is nil when the base
+// value is zero.
+type MetricDelta struct {
+	Metric        string   `json:"metric"`
+	Base          float64  `json:"base"`
+	Compare       float64  `json:"compare"`
+	Change        float64  `json:"change"`
+	PercentChange *float64 `json:"percentChange,omitempty"`
+	Direction     string   `json:"direction"`
+}
+
+// PageRankChange tracks a top page between reports. Ranks are 1-based and 0 when the
+// page is missing from that report; a positive Movement means the page climbed.
+type PageRankChange struct {
+	Path         string `json:"path"`
+	BaseRank     int    `json:"baseRank"`
+	CompareRank  int    `json:"compareRank"`
+	Movement     int    `json:"movement"`
+	BaseViews    int    `json:"baseViews"`
+	CompareViews int    `json:"compareViews"`
+	Direction    string `json:"direction"`
+}
+
+const (
+	DefaultHeatmapCellSize    = 10.0
+	DefaultHeatmapImageWidth  = 1280
+	DefaultHeatmapImageHeight = 800
+	MaxHeatmapImageDimension  = 4096
+)
+
+// HeatmapPoint is one grid cell: X and Y are the cell centre and Intensity is Count
+// normalized against the busiest cell, so it always falls in (0, 1].
 type HeatmapPoint struct {
 	X         float64 `json:"x"`
 	Y         float64 `json:"y"`
@@ -243,6 +589,54 @@ type HeatmapPoint struct {
 	Count     int     `json:"count"`
 }
 
+const (
+	WebVitalLCP = "lcp"
+	WebVitalCLS = "cls"
+	WebVitalFID = "fid"
+	WebVitalINP = "inp"
+
+	WebVitalGood             = "good"
+	WebVitalNeedsImprovement = "needs-improvement"
+	WebVitalPoor             = "poor"
+)
+
+// WebVitalThreshold holds a Core Web Vital's boundaries: values up to Good are good,
+// values above Poor are poor and anything between needs improvement.
+type WebVitalThreshold struct {
+	Good float64 `json:"good"`
+	Poor float64 `json:"poor"`
+}
+
+// WebVitalThresholds are Google's published boundaries. LCP, FID and INP are in
+// milliseconds; CLS is unitless.
+var WebVitalThresholds = map[string]WebVitalThreshold{
+	WebVitalLCP: {Good: 2500, Poor: 4000},
+	WebVitalCLS: {Good: 0.1, Poor: 0.25},
+	WebVitalFID: {Good: 100, Poor: 300},
+	WebVitalINP: {Good: 200, Poor: 500},
+}
+
+// WebVitalStat is the 75th percentile of one metric and its rating.
+type WebVitalStat struct {
+	P75     float64 `json:"p75"`
+	Samples int     `json:"samples"`
+	Rating  string  `json:"rating"`
+}
+
+// WebVitalsReport rates a page's Core Web Vitals by their 75th percentile, as Google's
+// methodology does. An empty PagePath covers every page, and a metric without samples
+// is left out.
+type WebVitalsReport struct {
+	PagePath  string        `json:"pagePath,omitempty"`
+	StartTime time.Time     `json:"startTime"`
+	EndTime   time.Time     `json:"endTime"`
+	Samples   int           `json:"samples"`
+	LCP       *WebVitalStat `json:"lcp,omitempty"`
+	CLS       *WebVitalStat `json:"cls,omitempty"`
+	FID       *WebVitalStat `json:"fid,omitempty"`
+	INP       *WebVitalStat `json:"inp,omitempty"`
+}
+
 type AnalyticsRequest struct {
 	SessionID string                 `json:"sessionId,omitempty"`
 	UserID    string                 `json:"userId,omitempty"`
@@ -253,9 +647,43 @@ type AnalyticsRequest struct {
 	Offset    int                    `json:"offset,omitempty"`
 }
 
+const (
+	BatchEventPageView    = "pageview"
+	BatchEventBehavioral  = "behavioral"
+	BatchEventPerformance = "performance"
+	BatchEventCustom      = "custom"
+
+	MaxBatchEvents = 500
+	MaxBatchBytes  = 2 << 20
+)
+
+type BatchEvent struct {
+	Type  string          `json:"type"`
+	Event json.RawMessage `json:"event"`
+}
+
+type BatchRequest struct {
+	Events []BatchEvent `json:"events"`
+}
+
+type BatchItemResult struct {
+	Index   int    `json:"index"`
+	Type    string `json:"type"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+type BatchResponse struct {
+	Accepted int               `json:"accepted"`
+	Rejected int               `json:"rejected"`
+	Results  []BatchItemResult `json:"results"`
+}
+
 type AnalyticsResponse struct {
 	Success bool        `json:"success"`
 	Data    interface{} `json:"data,omitempty"`
 	Message string      `json:"message,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	Code    string      `json:"code,omitempty"`
+	Details interface{} `json:"details,omitempty"`
 }
@@ -129,9 +129,10 @@ func (as *AggregatorService) AggregateFunnelData(funnelID string, startTime, end
 		return nil, fmt.Errorf("failed to get events: %w", err)
 	}
 
+	funnel := resolveFunnel(as.storage, funnelID)
 	funnelAnalysis := &core.FunnelAnalysis{
 		FunnelID:   funnelID,
-		FunnelName: fmt.Sprintf("Funnel %s", funnelID),
+		FunnelName: funnel.Name,
 		Stages:     []core.FunnelStage{},
 	}
 
@@ -142,22 +143,27 @@ func (as *AggregatorService) AggregateFunnelData(funnelID string, startTime, end
 
 	funnelAnalysis.TotalUsers = len(sessionEvents)
 
-	stages := []string{"landing", "product", "cart", "checkout", "purchase"}
-
-	for i, stage := range stages {
+	for _, stage := range newStageMatchers(funnel) {
 		stageUsers := 0
 		for _, sessionEventList := range sessionEvents {
-			if as.hasReachedStage(sessionEventList, stage) {
+			if stage.reachedBy(sessionEventList) {
 				stageUsers++
 			}
 		}
 
+		conversionRate := safeRatio(stageUsers, funnelAnalysis.TotalUsers)
+
+		pagePath := ""
+		if len(stage.stage.Rules) > 0 {
+			pagePath = stage.stage.Rules[0].Value
+		}
+
 		funnelStage := core.FunnelStage{
-			StageID:        fmt.Sprintf("stage_%d", i),
-			StageName:      stage,
-			PagePath:       fmt.Sprintf("/%s", stage),
+			StageID:        stage.stage.ID,
+			StageName:      stage.stage.Name,
+			PagePath:       pagePath,
 			Users:          stageUsers,
-			ConversionRate: float64(stageUsers) / float64(funnelAnalysis.TotalUsers),
+			ConversionRate: conversionRate,
 			AverageTime:    0,
 			BounceRate:     0,
 			ExitRate:       0,
@@ -168,43 +174,3 @@ func (as *AggregatorService) AggregateFunnelData(funnelID string, startTime, end
 
 	return funnelAnalysis, nil
 }
-
-func (as *AggregatorService) hasReachedStage(events []core.AnalyticsEvent, stage string) bool {
-	for _, event := range events {
-		if event.Type == "page_view" {
-			if path, ok := event.Metadata["path"].(string); ok {
-				if as.isStagePage(path, stage) {
-					return true
-				}
-			}
-		}
-
-		if event.Type == "custom" {
-			if event.Metadata["stage"] == stage {
-				return true
-			}
-		}
-	}
-
-	return false
-}
-
-func (as *AggregatorService) isStagePage(path, stage string) bool {
-	stagePages := map[string][]string{
-		"landing":  {"/", "/home", "/landing"},
-		"product":  {"/product", "/products", "/item"},
-		"cart":     {"/cart", "/basket"},
-		"checkout": {"/checkout", "/payment"},
-		"purchase": {"/thank-you", "/success", "/confirmation"},
-	}
-
-	if pages, exists := stagePages[stage]; exists {
-		for _, page := range pages {
-			if path == page {
-				return true
-			}
-		}
-	}
-
-	return false
-}
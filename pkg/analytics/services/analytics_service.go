@@ -3,18 +3,31 @@ package services
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/cherry-pick/pkg/analytics/core"
+	"github.com/cherry-pick/pkg/logging"
+	"github.com/cherry-pick/pkg/utils"
 )
 
 type AnalyticsService struct {
-	tracker   core.AnalyticsTracker
-	processor core.AnalyticsProcessor
-	reporter  core.AnalyticsReporter
-	storage   core.AnalyticsStorage
-	validator core.AnalyticsValidator
-	notifier  core.AnalyticsNotifier
+	tracker     core.AnalyticsTracker
+	processor   core.AnalyticsProcessor
+	reporter    core.AnalyticsReporter
+	storage     core.AnalyticsStorage
+	validator   core.AnalyticsValidator
+	notifier    core.AnalyticsNotifier
+	broadcaster *Broadcaster
+	retention   *RetentionEnforcer
+	retentionMu sync.Mutex
+
+	insightQueue    *WorkQueue
+	pendingInsights map[string]bool
+	insightMu       sync.Mutex
+
+	sinks  []core.ActivitySink
+	sinkMu sync.RWMutex
 }
 
 func (as *AnalyticsService) GetTracker() core.AnalyticsTracker {
@@ -41,18 +54,108 @@ func NewAnalyticsService(
 	validator core.AnalyticsValidator,
 	notifier core.AnalyticsNotifier,
 ) *AnalyticsService {
-	return &AnalyticsService{
+	as := &AnalyticsService{
 		tracker:   tracker,
 		processor: processor,
 		reporter:  reporter,
 		storage:   storage,
 		validator: validator,
 		notifier:  notifier,
+
+		pendingInsights: make(map[string]bool),
 	}
+	as.broadcaster = NewBroadcaster(DefaultBroadcastInterval, as.GetRealTimeMetrics)
+	return as
 }
 
 func (as *AnalyticsService) TrackPageView(event core.PageViewEvent) error {
-	return as.tracker.TrackPageView(event)
+	if err := as.tracker.TrackPageView(event); err != nil {
+		return err
+	}
+	as.writeActivity(core.ActivityPageView, event.SessionID, event)
+	as.queueInsights(event.SessionID)
+	return nil
+}
+
+// AddSink also writes tracked page views and generated insights to sink.
+func (as *AnalyticsService) AddSink(sink core.ActivitySink) {
+	as.sinkMu.Lock()
+	defer as.sinkMu.Unlock()
+
+	as.sinks = append(as.sinks, sink)
+}
+
+// CloseSinks closes and removes every sink, returning the first error.
+func (as *AnalyticsService) CloseSinks() error {
+	as.sinkMu.Lock()
+	sinks := as.sinks
+	as.sinks = nil
+	as.sinkMu.Unlock()
+
+	var firstErr error
+	for _, sink := range sinks {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// writeActivity hands a record to every sink. A failing sink is logged rather than
+// failing the tracking call.
+func (as *AnalyticsService) writeActivity(recordType, sessionID string, data interface{}) {
+	as.sinkMu.RLock()
+	defer as.sinkMu.RUnlock()
+
+	if len(as.sinks) == 0 {
+		return
+	}
+	record := core.ActivityRecord{
+		Type:      recordType,
+		Timestamp: time.Now(),
+		SessionID: sessionID,
+		Data:      data,
+	}
+	for _, sink := range as.sinks {
+		if err := sink.WriteRecord(record); err != nil {
+			logging.Default().Warn("Failed to write to activity sink", "recordType", recordType, "error", err)
+		}
+	}
+}
+
+// queueInsights schedules insight generation for a session on the bounded queue. A
+// session already waiting in the queue is not queued again, so bursts of page views
+// from one session collapse into a single job.
+func (as *AnalyticsService) queueInsights(sessionID string) {
+	if sessionID == "" {
+		return
+	}
+
+	as.insightMu.Lock()
+	queue := as.insightQueue
+	if queue == nil || as.pendingInsights[sessionID] {
+		as.insightMu.Unlock()
+		return
+	}
+	as.pendingInsights[sessionID] = true
+	as.insightMu.Unlock()
+
+	accepted := queue.Submit(func() error {
+		as.insightMu.Lock()
+		delete(as.pendingInsights, sessionID)
+		as.insightMu.Unlock()
+
+		insights, err := as.processor.ProcessInsights(sessionID)
+		for _, insight := range insights {
+			as.writeActivity(core.ActivityInsight, sessionID, insight)
+		}
+		return err
+	})
+	if !accepted {
+		as.insightMu.Lock()
+		delete(as.pendingInsights, sessionID)
+		as.insightMu.Unlock()
+	}
 }
 
 func (as *AnalyticsService) TrackBehavioralPattern(event core.BehavioralEvent) error {
@@ -87,6 +190,10 @@ func (as *AnalyticsService) GetUserJourney(sessionID string) (*core.UserJourney,
 	return as.processor.ProcessUserJourney(sessionID)
 }
 
+func (as *AnalyticsService) GetAttributionReport(model string) (*core.AttributionReport, error) {
+	return as.processor.ProcessAttribution(model)
+}
+
 func (as *AnalyticsService) GetFunnelAnalysis(funnelID string, startTime, endTime time.Time) (*core.FunnelAnalysis, error) {
 	return as.processor.ProcessFunnelAnalysis(funnelID, startTime, endTime)
 }
@@ -99,6 +206,22 @@ func (as *AnalyticsService) GetInsights(sessionID string) ([]core.AnalyticsInsig
 	return as.processor.ProcessInsights(sessionID)
 }
 
+// QueryInsights pages through the session's insights, or the last day's when sessionID
+// is empty.
+func (as *AnalyticsService) QueryInsights(sessionID string, query core.InsightQuery) (*core.InsightPage, error) {
+	var insights []core.AnalyticsInsight
+	var err error
+	if sessionID != "" {
+		insights, err = as.GetInsights(sessionID)
+	} else {
+		insights, err = as.GenerateInsights(time.Now().Add(-24*time.Hour), time.Now())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pageInsights(insights, query)
+}
+
 func (as *AnalyticsService) GetAlerts() ([]core.AnalyticsAlert, error) {
 	return as.processor.ProcessAlerts()
 }
@@ -107,10 +230,28 @@ func (as *AnalyticsService) GetHeatmapData(pagePath string, startTime, endTime t
 	return as.processor.ProcessHeatmapData(pagePath, startTime, endTime)
 }
 
+func (as *AnalyticsService) GetHeatmapImage(pagePath string, startTime, endTime time.Time, width, height int) ([]byte, error) {
+	points, err := as.processor.ProcessHeatmapData(pagePath, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	return RenderHeatmapPNG(points, width, height)
+}
+
 func (as *AnalyticsService) GenerateReport(request core.AnalyticsRequest) (*core.AnalyticsReport, error) {
 	return as.reporter.GenerateReport(request)
 }
 
+func (as *AnalyticsService) GetReport(reportID string) (*core.AnalyticsReport, error) {
+	return as.storage.GetReport(reportID)
+}
+
+// CompareAnalyticsReports diffs report b against report a: summary metric deltas, top
+// page rank movements and the insights that are new in b or resolved since a.
+func (as *AnalyticsService) CompareAnalyticsReports(a, b *core.AnalyticsReport) *core.AnalyticsReportDiff {
+	return compareReports(a, b)
+}
+
 func (as *AnalyticsService) GenerateSummary(startTime, endTime time.Time) (*core.AnalyticsSummary, error) {
 	return as.reporter.GenerateSummary(startTime, endTime)
 }
@@ -127,37 +268,95 @@ func (as *AnalyticsService) GeneratePerformanceReport(startTime, endTime time.Ti
 	return as.reporter.GeneratePerformanceReport(startTime, endTime)
 }
 
+func (as *AnalyticsService) GetWebVitalsReport(pagePath string, startTime, endTime time.Time) (*core.WebVitalsReport, error) {
+	return as.reporter.GenerateWebVitalsReport(pagePath, startTime, endTime)
+}
+
 func (as *AnalyticsService) GenerateBehavioralReport(startTime, endTime time.Time) ([]core.BehavioralEvent, error) {
 	return as.reporter.GenerateBehavioralReport(startTime, endTime)
 }
 
 func (as *AnalyticsService) SubscribeToRealTimeMetrics(ctx context.Context) (<-chan core.RealTimeMetrics, error) {
-	metricsChan := make(chan core.RealTimeMetrics, 10)
-	go func() {
-		ticker := time.NewTicker(5 * time.Second)
-		defer ticker.Stop()
-		defer close(metricsChan)
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				metrics, err := as.GetRealTimeMetrics()
-				if err != nil {
-					continue
-				}
-				select {
-				case metricsChan <- *metrics:
-				case <-ctx.Done():
-					return
-				}
-			}
-		}
-	}()
-	return metricsChan, nil
+	_, metricsChan, err := as.broadcaster.Subscribe(ctx, core.SubscriberOptions{})
+	return metricsChan, err
+}
+
+func (as *AnalyticsService) SubscribeWithOptions(ctx context.Context, options core.SubscriberOptions) (string, <-chan core.RealTimeMetrics, error) {
+	return as.broadcaster.Subscribe(ctx, options)
 }
 
 func (as *AnalyticsService) UnsubscribeFromRealTimeMetrics(subscriberID string) error {
+	return as.broadcaster.Unsubscribe(subscriberID)
+}
+
+func (as *AnalyticsService) GetSubscriberStats() []core.SubscriberStats {
+	return as.broadcaster.Stats()
+}
+
+func (as *AnalyticsService) CreateFunnel(funnel core.FunnelDefinition) (*core.FunnelDefinition, error) {
+	if err := as.validator.ValidateFunnel(funnel); err != nil {
+		return nil, fmt.Errorf("invalid funnel: %w", err)
+	}
+	if funnel.ID == "" {
+		funnel.ID = nextID("funnel")
+	}
+	now := time.Now()
+	funnel.CreatedAt = now
+	funnel.UpdatedAt = now
+	if err := as.storage.SaveFunnel(funnel); err != nil {
+		return nil, fmt.Errorf("failed to save funnel: %w", err)
+	}
+	return &funnel, nil
+}
+
+func (as *AnalyticsService) GetFunnel(funnelID string) (*core.FunnelDefinition, error) {
+	return as.storage.GetFunnel(funnelID)
+}
+
+func (as *AnalyticsService) ListFunnels() ([]core.FunnelDefinition, error) {
+	return as.storage.GetFunnels()
+}
+
+func (as *AnalyticsService) UpdateFunnel(funnel core.FunnelDefinition) (*core.FunnelDefinition, error) {
+	existing, err := as.storage.GetFunnel(funnel.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := as.validator.ValidateFunnel(funnel); err != nil {
+		return nil, fmt.Errorf("invalid funnel: %w", err)
+	}
+	funnel.CreatedAt = existing.CreatedAt
+	funnel.UpdatedAt = time.Now()
+	if err := as.storage.UpdateFunnel(funnel); err != nil {
+		return nil, fmt.Errorf("failed to update funnel: %w", err)
+	}
+	return &funnel, nil
+}
+
+func (as *AnalyticsService) DeleteFunnel(funnelID string) error {
+	return as.storage.DeleteFunnel(funnelID)
+}
+
+func (as *AnalyticsService) SetConversionGoals(goals []core.ConversionGoal) error {
+	processor, ok := as.processor.(*ProcessorService)
+	if !ok {
+		return fmt.Errorf("processor does not support conversion goals")
+	}
+	return processor.SetConversionGoals(goals)
+}
+
+func (as *AnalyticsService) SetRealTimeWindow(window time.Duration) error {
+	processor, ok := as.processor.(*ProcessorService)
+	if !ok {
+		return fmt.Errorf("processor does not support a real-time window")
+	}
+	return processor.SetRealTimeWindow(window)
+}
+
+func (as *AnalyticsService) GetConversionGoals() []core.ConversionGoal {
+	if processor, ok := as.processor.(*ProcessorService); ok {
+		return processor.ConversionGoals()
+	}
 	return nil
 }
 
@@ -165,6 +364,91 @@ func (as *AnalyticsService) CleanupOldData(olderThan time.Time) error {
 	return as.storage.CleanupOldData(olderThan)
 }
 
+// SetRetentionPolicy replaces the running retention enforcer, if any, and starts a new
+// one for policy.
+func (as *AnalyticsService) SetRetentionPolicy(policy core.RetentionPolicy) error {
+	enforcer, err := NewRetentionEnforcer(as.storage, policy, nil)
+	if err != nil {
+		return err
+	}
+
+	as.retentionMu.Lock()
+	defer as.retentionMu.Unlock()
+
+	if as.retention != nil {
+		as.retention.Stop()
+	}
+	as.retention = enforcer
+	as.retention.Start()
+	return nil
+}
+
+// StopRetention stops automatic purging; records are then kept until CleanupOldData is called.
+func (as *AnalyticsService) StopRetention() {
+	as.retentionMu.Lock()
+	defer as.retentionMu.Unlock()
+
+	if as.retention != nil {
+		as.retention.Stop()
+		as.retention = nil
+	}
+}
+
+// SetInsightQueue replaces the queue used for insight generation after page views. Jobs
+// already queued on the previous queue finish before it is discarded.
+func (as *AnalyticsService) SetInsightQueue(config core.WorkQueueConfig) error {
+	queue, err := NewWorkQueue(config)
+	if err != nil {
+		return err
+	}
+
+	as.insightMu.Lock()
+	previous := as.insightQueue
+	as.insightQueue = queue
+	as.insightMu.Unlock()
+
+	if previous != nil {
+		previous.Stop()
+	}
+	return nil
+}
+
+// StopInsightQueue drains queued insight jobs; later page views no longer generate insights.
+func (as *AnalyticsService) StopInsightQueue() {
+	as.insightMu.Lock()
+	queue := as.insightQueue
+	as.insightQueue = nil
+	as.insightMu.Unlock()
+
+	if queue != nil {
+		queue.Stop()
+	}
+}
+
 func (as *AnalyticsService) GetStats() (map[string]interface{}, error) {
-	return as.storage.GetStats()
+	stats, err := as.storage.GetStats()
+	if err != nil {
+		return nil, err
+	}
+	if tracker, ok := as.tracker.(*TrackerService); ok {
+		stats["resolver_cache"] = tracker.ResolverStats()
+	}
+	stats["runtime"] = utils.ReadRuntimeStats()
+
+	as.retentionMu.Lock()
+	if as.retention != nil {
+		stats["retention"] = map[string]interface{}{
+			"policy":   as.retention.Policy(),
+			"last_run": as.retention.LastRun(),
+		}
+	}
+	as.retentionMu.Unlock()
+
+	as.insightMu.Lock()
+	if as.insightQueue != nil {
+		stats["insight_queue"] = as.insightQueue.Stats()
+	}
+	as.insightMu.Unlock()
+
+	return stats, nil
 }
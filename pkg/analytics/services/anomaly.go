@@ -0,0 +1,110 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/cherry-pick/pkg/analytics/core"
+)
+
+type ewmaBaseline struct {
+	mean     float64
+	variance float64
+	samples  int
+}
+
+type AnomalyDetector struct {
+	config    core.AnomalyConfig
+	baselines map[string]*ewmaBaseline
+	mu        sync.Mutex
+}
+
+func NewAnomalyDetector(config core.AnomalyConfig) *AnomalyDetector {
+	if config.Alpha <= 0 || config.Alpha >= 1 {
+		config.Alpha = core.DefaultAnomalyAlpha
+	}
+	if config.Threshold <= 0 {
+		config.Threshold = core.DefaultAnomalyThreshold
+	}
+	if config.WarmupSamples <= 0 {
+		config.WarmupSamples = core.DefaultAnomalyWarmup
+	}
+
+	return &AnomalyDetector{
+		config:    config,
+		baselines: make(map[string]*ewmaBaseline),
+	}
+}
+
+// Observe feeds one real-time sample into each metric's baseline and returns an
+// anomaly alert for every metric outside its deviation band.
+func (ad *AnomalyDetector) Observe(metrics *core.RealTimeMetrics) []core.AnalyticsAlert {
+	values := []struct {
+		name  string
+		value float64
+	}{
+		{"page_views_per_minute", float64(metrics.PageViewsPerMinute)},
+		{"error_rate", metrics.ErrorRate},
+		{"performance_score", metrics.PerformanceScore},
+	}
+
+	ad.mu.Lock()
+	defer ad.mu.Unlock()
+
+	var alerts []core.AnalyticsAlert
+	for _, v := range values {
+		if alert := ad.observe(v.name, v.value, metrics.Timestamp); alert != nil {
+			alerts = append(alerts, *alert)
+		}
+	}
+	return alerts
+}
+
+func (ad *AnomalyDetector) observe(metric string, value float64, timestamp time.Time) *core.AnalyticsAlert {
+	baseline, exists := ad.baselines[metric]
+	if !exists {
+		ad.baselines[metric] = &ewmaBaseline{mean: value, samples: 1}
+		return nil
+	}
+
+	var alert *core.AnalyticsAlert
+	stdDev := math.Sqrt(baseline.variance)
+	deviation := value - baseline.mean
+	if baseline.samples >= ad.config.WarmupSamples && math.Abs(deviation) > ad.config.Threshold*math.Max(stdDev, 1e-6) {
+		deviations := math.Abs(deviation) / math.Max(stdDev, 1e-6)
+		if timestamp.IsZero() {
+			timestamp = time.Now()
+		}
+		alert = &core.AnalyticsAlert{
+			ID:        generateAlertID(),
+			Type:      "anomaly",
+			Severity:  anomalySeverity(deviations, ad.config.Threshold),
+			Title:     fmt.Sprintf("Anomalous %s", metric),
+			Message:   fmt.Sprintf("%s is %.2f, expected %.2f ± %.2f", metric, value, baseline.mean, ad.config.Threshold*stdDev),
+			Timestamp: timestamp,
+			Metadata: map[string]interface{}{
+				"metric":     metric,
+				"value":      value,
+				"baseline":   baseline.mean,
+				"stdDev":     stdDev,
+				"deviations": deviations,
+			},
+		}
+	}
+
+	increment := ad.config.Alpha * deviation
+	baseline.mean += increment
+	baseline.variance = (1 - ad.config.Alpha) * (baseline.variance + deviation*increment)
+	baseline.samples++
+
+	return alert
+}
+
+func anomalySeverity(deviations, threshold float64) string {
+	if deviations > threshold*2 {
+		return "high"
+	}
+	return "medium"
+}
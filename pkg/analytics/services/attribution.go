@@ -0,0 +1,144 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cherry-pick/pkg/analytics/core"
+)
+
+func validAttributionModel(model string) bool {
+	switch model {
+	case core.AttributionFirstTouch, core.AttributionLastTouch, core.AttributionLinear:
+		return true
+	}
+	return false
+}
+
+// referrerTouches returns the hosts of external referrers on the page views, oldest
+// first. A referrer from the page's own host is internal navigation and is skipped, as
+// is a repeat of the touch before it.
+func referrerTouches(events []core.AnalyticsEvent) []string {
+	pageViews := make([]core.AnalyticsEvent, 0, len(events))
+	for _, event := range events {
+		if event.Type == "page_view" {
+			pageViews = append(pageViews, event)
+		}
+	}
+	sort.SliceStable(pageViews, func(i, j int) bool {
+		return pageViews[i].Timestamp.Before(pageViews[j].Timestamp)
+	})
+
+	var touches []string
+	for _, event := range pageViews {
+		referrer, _ := event.Metadata["referrer"].(string)
+		source := referrerHost(referrer)
+		if source == "" {
+			continue
+		}
+		page, _ := event.Metadata["url"].(string)
+		if source == referrerHost(page) {
+			continue
+		}
+		if len(touches) > 0 && touches[len(touches)-1] == source {
+			continue
+		}
+		touches = append(touches, source)
+	}
+	return touches
+}
+
+// referrerHost reduces a referrer to its lower-cased host without a leading "www.",
+// so links from different pages of one site count as the same source.
+func referrerHost(referrer string) string {
+	referrer = strings.TrimSpace(referrer)
+	if referrer == "" {
+		return ""
+	}
+	if !strings.Contains(referrer, "://") {
+		referrer = "//" + referrer
+	}
+	parsed, err := url.Parse(referrer)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
+
+// attributeConversion credits one conversion to touches under model. A journey without
+// touches credits AttributionDirect.
+func attributeConversion(model string, touches []string, credit map[string]float64) {
+	if len(touches) == 0 {
+		credit[core.AttributionDirect]++
+		return
+	}
+	switch model {
+	case core.AttributionFirstTouch:
+		credit[touches[0]]++
+	case core.AttributionLastTouch:
+		credit[touches[len(touches)-1]]++
+	case core.AttributionLinear:
+		share := 1 / float64(len(touches))
+		for _, touch := range touches {
+			credit[touch] += share
+		}
+	}
+}
+
+// ProcessAttribution groups events into journeys by session and credits each journey
+// that completed a conversion goal to its referrers under model.
+func (ps *ProcessorService) ProcessAttribution(model string) (*core.AttributionReport, error) {
+	if !validAttributionModel(model) {
+		return nil, fmt.Errorf("unsupported attribution model: %s", model)
+	}
+
+	events, err := ps.storage.GetEvents(core.AnalyticsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events: %w", err)
+	}
+
+	sessionEvents := make(map[string][]core.AnalyticsEvent)
+	for _, event := range events {
+		sessionEvents[event.SessionID] = append(sessionEvents[event.SessionID], event)
+	}
+
+	report := &core.AttributionReport{
+		Model:         model,
+		TotalJourneys: len(sessionEvents),
+		Sources:       []core.AttributionSource{},
+		GeneratedAt:   time.Now(),
+	}
+
+	goals := ps.ConversionGoals()
+	if len(goals) == 0 {
+		return report, nil
+	}
+
+	credit := make(map[string]float64)
+	for _, list := range sessionEvents {
+		if len(completedGoals(goals, list)) == 0 {
+			continue
+		}
+		report.TotalConversions++
+		attributeConversion(model, referrerTouches(list), credit)
+	}
+
+	for source, conversions := range credit {
+		report.Sources = append(report.Sources, core.AttributionSource{
+			Source:      source,
+			Conversions: conversions,
+			Share:       conversions / float64(report.TotalConversions),
+		})
+	}
+	sort.Slice(report.Sources, func(i, j int) bool {
+		if report.Sources[i].Conversions != report.Sources[j].Conversions {
+			return report.Sources[i].Conversions > report.Sources[j].Conversions
+		}
+		return report.Sources[i].Source < report.Sources[j].Source
+	})
+
+	return report, nil
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cherry-pick/pkg/analytics/core"
+)
+
+const DefaultBroadcastInterval = 5 * time.Second
+
+type subscriber struct {
+	id           string
+	ch           chan core.RealTimeMetrics
+	options      core.SubscriberOptions
+	delivered    int64
+	dropped      int64
+	subscribedAt time.Time
+	lastDropAt   *time.Time
+}
+
+type Broadcaster struct {
+	interval    time.Duration
+	source      func() (*core.RealTimeMetrics, error)
+	subscribers map[string]*subscriber
+	stop        chan struct{}
+	mu          sync.Mutex
+}
+
+func NewBroadcaster(interval time.Duration, source func() (*core.RealTimeMetrics, error)) *Broadcaster {
+	if interval <= 0 {
+		interval = DefaultBroadcastInterval
+	}
+
+	return &Broadcaster{
+		interval:    interval,
+		source:      source,
+		subscribers: make(map[string]*subscriber),
+	}
+}
+
+func (b *Broadcaster) Subscribe(ctx context.Context, options core.SubscriberOptions) (string, <-chan core.RealTimeMetrics, error) {
+	if options.BufferSize == 0 {
+		options.BufferSize = core.DefaultSubscriberBuffer
+	}
+	if options.BufferSize < 1 || options.BufferSize > core.MaxSubscriberBuffer {
+		return "", nil, fmt.Errorf("buffer size must be between 1 and %d", core.MaxSubscriberBuffer)
+	}
+	if options.DropPolicy == "" {
+		options.DropPolicy = core.DropPolicyDropNewest
+	}
+	if options.DropPolicy != core.DropPolicyDropNewest && options.DropPolicy != core.DropPolicyCoalesce {
+		return "", nil, fmt.Errorf("unsupported drop policy: %s", options.DropPolicy)
+	}
+
+	sub := &subscriber{
+		id:           nextID("subscriber"),
+		ch:           make(chan core.RealTimeMetrics, options.BufferSize),
+		options:      options,
+		subscribedAt: time.Now(),
+	}
+
+	b.mu.Lock()
+	b.subscribers[sub.id] = sub
+	if b.stop == nil {
+		b.stop = make(chan struct{})
+		go b.run(b.stop)
+	}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.Unsubscribe(sub.id)
+	}()
+
+	return sub.id, sub.ch, nil
+}
+
+func (b *Broadcaster) Unsubscribe(subscriberID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub, exists := b.subscribers[subscriberID]
+	if !exists {
+		return fmt.Errorf("subscriber not found: %s", subscriberID)
+	}
+
+	delete(b.subscribers, subscriberID)
+	close(sub.ch)
+
+	if len(b.subscribers) == 0 && b.stop != nil {
+		close(b.stop)
+		b.stop = nil
+	}
+	return nil
+}
+
+func (b *Broadcaster) Stats() []core.SubscriberStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := make([]core.SubscriberStats, 0, len(b.subscribers))
+	for _, sub := range b.subscribers {
+		stats = append(stats, core.SubscriberStats{
+			ID:           sub.id,
+			BufferSize:   sub.options.BufferSize,
+			DropPolicy:   sub.options.DropPolicy,
+			Pending:      len(sub.ch),
+			Delivered:    sub.delivered,
+			Dropped:      sub.dropped,
+			SubscribedAt: sub.subscribedAt,
+			LastDropAt:   sub.lastDropAt,
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].SubscribedAt.Before(stats[j].SubscribedAt)
+	})
+	return stats
+}
+
+func (b *Broadcaster) run(stop chan struct{}) {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			metrics, err := b.source()
+			if err != nil || metrics == nil {
+				continue
+			}
+			b.Publish(*metrics)
+		}
+	}
+}
+
+// Publish never blocks: a full subscriber either loses this update or, when coalescing, its oldest pending one.
+func (b *Broadcaster) Publish(metrics core.RealTimeMetrics) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, sub := range b.subscribers {
+		select {
+		case sub.ch <- metrics:
+			sub.delivered++
+			continue
+		default:
+		}
+
+		now := time.Now()
+		sub.dropped++
+		sub.lastDropAt = &now
+
+		if sub.options.DropPolicy != core.DropPolicyCoalesce {
+			continue
+		}
+
+		select {
+		case <-sub.ch:
+		default:
+		}
+		select {
+		case sub.ch <- metrics:
+			sub.delivered++
+		default:
+		}
+	}
+}
@@ -4,21 +4,65 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/cherry-pick/pkg/analytics/core"
 )
 
 type CalculatorService struct {
-	storage core.AnalyticsStorage
+	storage   core.AnalyticsStorage
+	scoringMu sync.RWMutex
+	scoring   core.PerformanceScoreConfig
 }
 
 func NewCalculatorService(storage core.AnalyticsStorage) *CalculatorService {
 	return &CalculatorService{
 		storage: storage,
+		scoring: core.DefaultPerformanceScoreConfig(),
 	}
 }
 
+// SetPerformanceScoreConfig replaces the weights and thresholds used to score
+// performance events.
+func (cs *CalculatorService) SetPerformanceScoreConfig(config core.PerformanceScoreConfig) error {
+	if err := ValidatePerformanceScoreConfig(config); err != nil {
+		return err
+	}
+
+	cs.scoringMu.Lock()
+	cs.scoring = config
+	cs.scoringMu.Unlock()
+	return nil
+}
+
+// ValidatePerformanceScoreConfig rejects negative weights, weighted metrics without a
+// positive threshold and configs where no metric counts at all.
+func ValidatePerformanceScoreConfig(config core.PerformanceScoreConfig) error {
+	metrics := map[string]core.MetricScore{
+		"loadTime":               config.LoadTime,
+		"firstContentfulPaint":   config.FirstContentfulPaint,
+		"largestContentfulPaint": config.LargestContentfulPaint,
+		"cumulativeLayoutShift":  config.CumulativeLayoutShift,
+		"firstInputDelay":        config.FirstInputDelay,
+	}
+
+	var totalWeight float64
+	for name, metric := range metrics {
+		if metric.Weight < 0 {
+			return fmt.Errorf("%s weight cannot be negative", name)
+		}
+		if metric.Weight > 0 && metric.Threshold <= 0 {
+			return fmt.Errorf("%s threshold must be positive", name)
+		}
+		totalWeight += metric.Weight
+	}
+	if totalWeight == 0 {
+		return fmt.Errorf("at least one performance metric needs a weight")
+	}
+	return nil
+}
+
 func (cs *CalculatorService) CalculateBounceRate(sessionID string) (float64, error) {
 	session, err := cs.storage.GetSession(sessionID)
 	if err != nil {
@@ -93,11 +137,7 @@ func (cs *CalculatorService) CalculateConversionRate(funnelID string, startTime,
 		}
 	}
 
-	if totalSessions == 0 {
-		return 0, nil
-	}
-
-	return float64(completedSessions) / float64(totalSessions), nil
+	return safeRatio(completedSessions, totalSessions), nil
 }
 
 func (cs *CalculatorService) CalculatePerformanceScore(events []core.PerformanceEvent) (float64, error) {
@@ -185,12 +225,15 @@ func (cs *CalculatorService) CalculateFunnelDropOff(funnelID string, startTime,
 		sessionEvents[event.SessionID] = append(sessionEvents[event.SessionID], event)
 	}
 
-	stages := []string{"landing", "product", "cart", "checkout", "purchase"}
+	stages := newStageMatchers(resolveFunnel(cs.storage, funnelID))
+	if len(stages) < 2 {
+		return []float64{}, nil
+	}
 	stageCounts := make([]int, len(stages))
 
 	for _, sessionEventList := range sessionEvents {
 		for i, stage := range stages {
-			if cs.hasReachedStage(sessionEventList, stage) {
+			if stage.reachedBy(sessionEventList) {
 				stageCounts[i]++
 			}
 		}
@@ -198,11 +241,7 @@ func (cs *CalculatorService) CalculateFunnelDropOff(funnelID string, startTime,
 
 	dropOffRates := make([]float64, len(stages)-1)
 	for i := 0; i < len(stages)-1; i++ {
-		if stageCounts[i] == 0 {
-			dropOffRates[i] = 0
-		} else {
-			dropOffRates[i] = float64(stageCounts[i]-stageCounts[i+1]) / float64(stageCounts[i])
-		}
+		dropOffRates[i] = safeRatio(stageCounts[i]-stageCounts[i+1], stageCounts[i])
 	}
 
 	return dropOffRates, nil
@@ -233,75 +272,48 @@ func (cs *CalculatorService) hasCompletedFunnel(events []core.AnalyticsEvent, fu
 	return false
 }
 
-func (cs *CalculatorService) hasReachedStage(events []core.AnalyticsEvent, stage string) bool {
-	for _, event := range events {
-		if event.Type == "page_view" {
-			if path, ok := event.Metadata["path"].(string); ok {
-				if cs.isStagePage(path, stage) {
-					return true
-				}
-			}
-		}
-
-		if event.Type == "custom" {
-			if event.Metadata["stage"] == stage {
-				return true
-			}
-		}
-	}
-
-	return false
-}
-
-func (cs *CalculatorService) isStagePage(path, stage string) bool {
-	stagePages := map[string][]string{
-		"landing":  {"/", "/home", "/landing"},
-		"product":  {"/product", "/products", "/item"},
-		"cart":     {"/cart", "/basket"},
-		"checkout": {"/checkout", "/payment"},
-		"purchase": {"/thank-you", "/success", "/confirmation"},
-	}
+func (cs *CalculatorService) calculateEventPerformanceScore(event core.PerformanceEvent) float64 {
+	cs.scoringMu.RLock()
+	config := cs.scoring
+	cs.scoringMu.RUnlock()
 
-	if pages, exists := stagePages[stage]; exists {
-		for _, page := range pages {
-			if path == page {
-				return true
-			}
-		}
+	totalWeight := config.LoadTime.Weight + config.FirstContentfulPaint.Weight +
+		config.LargestContentfulPaint.Weight + config.CumulativeLayoutShift.Weight + config.FirstInputDelay.Weight
+	if totalWeight <= 0 {
+		return 0
 	}
 
-	return false
-}
-
-func (cs *CalculatorService) calculateEventPerformanceScore(event core.PerformanceEvent) float64 {
 	var score float64
 
 	if event.LoadTime > 0 {
-		loadTimeScore := math.Max(0, 1.0-float64(event.LoadTime)/5000.0)
-		score += loadTimeScore * 0.3
+		score += metricScore(float64(event.LoadTime), config.LoadTime)
 	}
 
 	if event.FirstContentfulPaint > 0 {
-		fcpScore := math.Max(0, 1.0-float64(event.FirstContentfulPaint)/3000.0)
-		score += fcpScore * 0.3
+		score += metricScore(float64(event.FirstContentfulPaint), config.FirstContentfulPaint)
 	}
 
 	if event.LargestContentfulPaint > 0 {
-		lcpScore := math.Max(0, 1.0-float64(event.LargestContentfulPaint)/4000.0)
-		score += lcpScore * 0.2
+		score += metricScore(float64(event.LargestContentfulPaint), config.LargestContentfulPaint)
 	}
 
 	if event.CumulativeLayoutShift >= 0 {
-		clsScore := math.Max(0, 1.0-event.CumulativeLayoutShift/0.25)
-		score += clsScore * 0.1
+		score += metricScore(event.CumulativeLayoutShift, config.CumulativeLayoutShift)
 	}
 
 	if event.FirstInputDelay > 0 {
-		fidScore := math.Max(0, 1.0-float64(event.FirstInputDelay)/300.0)
-		score += fidScore * 0.1
+		score += metricScore(float64(event.FirstInputDelay), config.FirstInputDelay)
 	}
 
-	return math.Min(score, 1.0)
+	return math.Min(score/totalWeight, 1.0)
+}
+
+// metricScore is the weighted share of value under metric's threshold.
+func metricScore(value float64, metric core.MetricScore) float64 {
+	if metric.Weight <= 0 || metric.Threshold <= 0 {
+		return 0
+	}
+	return math.Max(0, 1.0-value/metric.Threshold) * metric.Weight
 }
 
 func (cs *CalculatorService) CalculateAverageSessionDuration(sessions []core.UserSession) time.Duration {
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/cherry-pick/pkg/analytics/core"
+)
+
+func DefaultFunnelDefinition(funnelID string) core.FunnelDefinition {
+	stage := func(name string, paths ...string) core.FunnelStageDefinition {
+		rules := make([]core.FunnelStageRule, 0, len(paths))
+		for _, path := range paths {
+			rules = append(rules, core.FunnelStageRule{Match: core.FunnelMatchEquals, Value: path})
+		}
+		return core.FunnelStageDefinition{ID: name, Name: name, Rules: rules}
+	}
+
+	return core.FunnelDefinition{
+		ID:   funnelID,
+		Name: "Funnel " + funnelID,
+		Stages: []core.FunnelStageDefinition{
+			stage("landing", "/", "/home", "/landing"),
+			stage("product", "/product", "/products", "/item"),
+			stage("cart", "/cart", "/basket"),
+			stage("checkout", "/checkout", "/payment"),
+			stage("purchase", "/thank-you", "/success", "/confirmation"),
+		},
+	}
+}
+
+func resolveFunnel(storage core.AnalyticsStorage, funnelID string) core.FunnelDefinition {
+	if funnel, err := storage.GetFunnel(funnelID); err == nil {
+		return *funnel
+	}
+	return DefaultFunnelDefinition(funnelID)
+}
+
+type stageMatcher struct {
+	stage   core.FunnelStageDefinition
+	regexes []*regexp.Regexp
+}
+
+func newStageMatchers(funnel core.FunnelDefinition) []stageMatcher {
+	matchers := make([]stageMatcher, 0, len(funnel.Stages))
+	for _, stage := range funnel.Stages {
+		matcher := stageMatcher{stage: stage}
+		for _, rule := range stage.Rules {
+			if rule.Match == core.FunnelMatchRegex {
+				if re, err := regexp.Compile(rule.Value); err == nil {
+					matcher.regexes = append(matcher.regexes, re)
+				}
+			}
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers
+}
+
+func (sm stageMatcher) matchesPath(path string) bool {
+	for _, rule := range sm.stage.Rules {
+		switch rule.Match {
+		case core.FunnelMatchEquals, "":
+			if path == rule.Value {
+				return true
+			}
+		case core.FunnelMatchPrefix:
+			if strings.HasPrefix(path, rule.Value) {
+				return true
+			}
+		}
+	}
+	for _, re := range sm.regexes {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (sm stageMatcher) reachedBy(events []core.AnalyticsEvent) bool {
+	for _, event := range events {
+		if event.Type == "page_view" {
+			if path, ok := event.Metadata["path"].(string); ok {
+				if sm.matchesPath(path) {
+					return true
+				}
+			}
+		}
+
+		if event.Type == "custom" {
+			if stage := event.Metadata["stage"]; stage == sm.stage.ID || stage == sm.stage.Name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/cherry-pick/pkg/analytics/core"
+)
+
+func validateConversionGoals(goals []core.ConversionGoal) error {
+	seen := make(map[string]bool)
+	for i, goal := range goals {
+		if goal.Name == "" {
+			return fmt.Errorf("goal %d: name is required", i)
+		}
+		if seen[goal.Name] {
+			return fmt.Errorf("duplicate goal name: %s", goal.Name)
+		}
+		seen[goal.Name] = true
+		if goal.Path == "" && goal.EventType == "" && goal.CustomEvent == "" {
+			return fmt.Errorf("goal %s: path, event type or custom event is required", goal.Name)
+		}
+	}
+	return nil
+}
+
+// completedGoals returns the names of goals reached by the events, in goal order.
+func completedGoals(goals []core.ConversionGoal, events []core.AnalyticsEvent) []string {
+	var completed []string
+	for _, goal := range goals {
+		for _, event := range events {
+			if goalMatchesEvent(goal, event) {
+				completed = append(completed, goal.Name)
+				break
+			}
+		}
+	}
+	return completed
+}
+
+func goalMatchesEvent(goal core.ConversionGoal, event core.AnalyticsEvent) bool {
+	if goal.EventType != "" && event.Type != goal.EventType {
+		return false
+	}
+	if goal.Path != "" {
+		path, _ := event.Metadata["path"].(string)
+		if event.Type != "page_view" || path != goal.Path {
+			return false
+		}
+	}
+	if goal.CustomEvent != "" {
+		name, _ := event.Metadata["name"].(string)
+		if event.Type != "custom" || name != goal.CustomEvent {
+			return false
+		}
+	}
+	return true
+}
+
+func sessionConversionRate(goals []core.ConversionGoal, events []core.AnalyticsEvent) float64 {
+	if len(goals) == 0 {
+		return 0
+	}
+
+	sessionEvents := make(map[string][]core.AnalyticsEvent)
+	for _, event := range events {
+		sessionEvents[event.SessionID] = append(sessionEvents[event.SessionID], event)
+	}
+
+	converted := 0
+	for _, list := range sessionEvents {
+		if len(completedGoals(goals, list)) > 0 {
+			converted++
+		}
+	}
+	return safeRatio(converted, len(sessionEvents))
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"sort"
+
+	"github.com/cherry-pick/pkg/analytics/core"
+)
+
+const heatmapBlurRadius = 24
+
+type heatmapCell struct {
+	x, y int64
+}
+
+// binHeatmapPoints merges click coordinates into square cells of cellSize pixels and
+// normalizes intensity against the busiest cell. Points are ordered top-to-bottom, left-to-right.
+func binHeatmapPoints(events []core.AnalyticsEvent, cellSize float64) []core.HeatmapPoint {
+	if cellSize <= 0 {
+		cellSize = core.DefaultHeatmapCellSize
+	}
+
+	counts := make(map[heatmapCell]int)
+	maxCount := 0
+	for _, event := range events {
+		x, y, ok := eventCoordinates(event.Metadata["coordinates"])
+		if !ok {
+			continue
+		}
+
+		cell := heatmapCell{
+			x: int64(math.Floor(x / cellSize)),
+			y: int64(math.Floor(y / cellSize)),
+		}
+		counts[cell]++
+		if counts[cell] > maxCount {
+			maxCount = counts[cell]
+		}
+	}
+
+	points := make([]core.HeatmapPoint, 0, len(counts))
+	for cell, count := range counts {
+		points = append(points, core.HeatmapPoint{
+			X:         (float64(cell.x) + 0.5) * cellSize,
+			Y:         (float64(cell.y) + 0.5) * cellSize,
+			Intensity: float64(count) / float64(maxCount),
+			Count:     count,
+		})
+	}
+
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].Y != points[j].Y {
+			return points[i].Y < points[j].Y
+		}
+		return points[i].X < points[j].X
+	})
+
+	return points
+}
+
+// eventCoordinates accepts both the typed map used internally and the generic map
+// produced when events are decoded from JSON.
+func eventCoordinates(value interface{}) (float64, float64, bool) {
+	switch coordinates := value.(type) {
+	case map[string]float64:
+		x, okX := coordinates["x"]
+		y, okY := coordinates["y"]
+		return x, y, okX && okY
+	case map[string]interface{}:
+		x, okX := coordinates["x"].(float64)
+		y, okY := coordinates["y"].(float64)
+		return x, y, okX && okY
+	}
+	return 0, 0, false
+}
+
+// RenderHeatmapPNG draws the points as a blurred intensity overlay on a transparent
+// width x height canvas. Points outside the canvas are ignored.
+func RenderHeatmapPNG(points []core.HeatmapPoint, width, height int) ([]byte, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("heatmap dimensions must be positive")
+	}
+	if width > core.MaxHeatmapImageDimension || height > core.MaxHeatmapImageDimension {
+		return nil, fmt.Errorf("heatmap dimensions cannot exceed %d pixels", core.MaxHeatmapImageDimension)
+	}
+
+	kernel := gaussianKernel(heatmapBlurRadius)
+	density := make([]float64, width*height)
+	maxDensity := 0.0
+
+	for _, point := range points {
+		cx, cy := int(point.X), int(point.Y)
+		if cx < 0 || cy < 0 || cx >= width || cy >= height {
+			continue
+		}
+
+		for dy := -heatmapBlurRadius; dy <= heatmapBlurRadius; dy++ {
+			py := cy + dy
+			if py < 0 || py >= height {
+				continue
+			}
+			for dx := -heatmapBlurRadius; dx <= heatmapBlurRadius; dx++ {
+				px := cx + dx
+				if px < 0 || px >= width {
+					continue
+				}
+				weight := kernel[(dy+heatmapBlurRadius)*(2*heatmapBlurRadius+1)+dx+heatmapBlurRadius]
+				if weight == 0 {
+					continue
+				}
+				index := py*width + px
+				density[index] += point.Intensity * weight
+				if density[index] > maxDensity {
+					maxDensity = density[index]
+				}
+			}
+		}
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	if maxDensity > 0 {
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				if value := density[y*width+x] / maxDensity; value > 0.01 {
+					img.SetNRGBA(x, y, heatmapColor(value))
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode heatmap: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func gaussianKernel(radius int) []float64 {
+	size := 2*radius + 1
+	sigma := float64(radius) / 2
+	kernel := make([]float64, size*size)
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			distance := float64(x*x + y*y)
+			if distance > float64(radius*radius) {
+				continue
+			}
+			kernel[(y+radius)*size+x+radius] = math.Exp(-distance / (2 * sigma * sigma))
+		}
+	}
+	return kernel
+}
+
+// heatmapColor maps a normalized value onto a blue-green-yellow-red ramp whose
+// opacity grows with the value, so cold areas stay see-through.
+func heatmapColor(value float64) color.NRGBA {
+	value = math.Max(0, math.Min(1, value))
+
+	var r, g, b float64
+	switch {
+	case value < 0.25:
+		b, g = 1, value/0.25
+	case value < 0.5:
+		g, b = 1, 1-(value-0.25)/0.25
+	case value < 0.75:
+		g, r = 1, (value-0.5)/0.25
+	default:
+		r, g = 1, 1-(value-0.75)/0.25
+	}
+
+	return color.NRGBA{
+		R: uint8(r * 255),
+		G: uint8(g * 255),
+		B: uint8(b * 255),
+		A: uint8(64 + value*160),
+	}
+}
@@ -0,0 +1,13 @@
+package services
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+var idSequence uint64
+
+func nextID(prefix string) string {
+	return fmt.Sprintf("%s_%d_%d", prefix, time.Now().UnixNano(), atomic.AddUint64(&idSequence, 1))
+}
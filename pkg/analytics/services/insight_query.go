@@ -0,0 +1,70 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cherry-pick/pkg/analytics/core"
+)
+
+// pageInsights filters insights by query and returns the requested page, newest first
+// or, with InsightSortConfidence, most confident first. Ties fall back to the ID so
+// pages stay stable between requests.
+func pageInsights(insights []core.AnalyticsInsight, query core.InsightQuery) (*core.InsightPage, error) {
+	if query.Sort == "" {
+		query.Sort = core.InsightSortTimestamp
+	}
+	if query.Sort != core.InsightSortTimestamp && query.Sort != core.InsightSortConfidence {
+		return nil, fmt.Errorf("unsupported insight sort: %s", query.Sort)
+	}
+	if query.Limit <= 0 {
+		query.Limit = core.DefaultInsightPageSize
+	}
+	if query.Limit > core.MaxInsightPageSize {
+		query.Limit = core.MaxInsightPageSize
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+
+	matched := make([]core.AnalyticsInsight, 0, len(insights))
+	for _, insight := range insights {
+		if query.Type != "" && !strings.EqualFold(insight.Type, query.Type) {
+			continue
+		}
+		if query.Impact != "" && !strings.EqualFold(insight.Impact, query.Impact) {
+			continue
+		}
+		if query.Actionable != nil && insight.Actionable != *query.Actionable {
+			continue
+		}
+		matched = append(matched, insight)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if query.Sort == core.InsightSortConfidence && a.Confidence != b.Confidence {
+			return a.Confidence > b.Confidence
+		}
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.After(b.Timestamp)
+		}
+		return a.ID < b.ID
+	})
+
+	page := &core.InsightPage{
+		Insights: []core.AnalyticsInsight{},
+		Total:    len(matched),
+		Limit:    query.Limit,
+		Offset:   query.Offset,
+	}
+	if query.Offset < len(matched) {
+		end := query.Offset + query.Limit
+		if end > len(matched) {
+			end = len(matched)
+		}
+		page.Insights = matched[query.Offset:end]
+	}
+	return page, nil
+}
@@ -0,0 +1,26 @@
+package services
+
+import "math"
+
+// safePercent is count as a percentage of total, held within [0, 100]. An empty total
+// gives 0 rather than NaN or +Inf, which encoding/json refuses to marshal.
+func safePercent(count, total int) float64 {
+	return clampPercent(safeRatio(count, total)*100, 0, 100)
+}
+
+// safeRatio is count/total as a fraction, or 0 when total is not positive.
+func safeRatio(count, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(count) / float64(total)
+}
+
+// clampPercent bounds value to [floor, ceiling]. NaN becomes floor, so the result is
+// always safe to serialize.
+func clampPercent(value, floor, ceiling float64) float64 {
+	if math.IsNaN(value) {
+		return floor
+	}
+	return math.Max(floor, math.Min(ceiling, value))
+}
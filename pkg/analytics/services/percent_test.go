@@ -0,0 +1,74 @@
+package services
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/cherry-pick/pkg/analytics/core"
+	"github.com/cherry-pick/pkg/analytics/storage"
+)
+
+func TestSafePercentEmptyTotals(t *testing.T) {
+	tests := []struct {
+		name  string
+		count int
+		total int
+		want  float64
+	}{
+		{"empty", 0, 0, 0},
+		{"count without total", 3, 0, 0},
+		{"negative total", 5, -1, 0},
+		{"half", 1, 2, 50},
+		{"count above total", 150, 100, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := safePercent(tt.count, tt.total)
+			if math.IsNaN(got) || math.IsInf(got, 0) {
+				t.Fatalf("Expected a finite percentage, got %v", got)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSafeRatioEmptyTotal(t *testing.T) {
+	if got := safeRatio(0, 0); got != 0 {
+		t.Errorf("Expected 0, got %v", got)
+	}
+	if got := safeRatio(2, 4); got != 0.5 {
+		t.Errorf("Expected 0.5, got %v", got)
+	}
+}
+
+func TestClampPercentNaN(t *testing.T) {
+	if got := clampPercent(math.NaN(), 0, 100); got != 0 {
+		t.Errorf("Expected NaN to clamp to 0, got %v", got)
+	}
+	if got := clampPercent(math.Inf(1), 0, 100); got != 100 {
+		t.Errorf("Expected +Inf to clamp to 100, got %v", got)
+	}
+}
+
+func TestEmptyReportMarshals(t *testing.T) {
+	reporter := NewReporterService(storage.NewMemoryStorage(), nil, nil)
+
+	report, err := reporter.GenerateReport(core.AnalyticsRequest{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Summary.BounceRate != 0 {
+		t.Errorf("Expected a bounce rate of 0 without sessions, got %v", report.Summary.BounceRate)
+	}
+	if _, err := json.Marshal(report); err != nil {
+		t.Errorf("Expected the report to marshal, got %v", err)
+	}
+
+	breakdown := []core.ReferrerStats{{Referrer: "none", Percentage: safePercent(0, 0)}}
+	if _, err := json.Marshal(breakdown); err != nil {
+		t.Errorf("Expected the breakdown to marshal, got %v", err)
+	}
+}
@@ -3,15 +3,23 @@ package services
 import (
 	"fmt"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/cherry-pick/pkg/analytics/core"
+	"github.com/cherry-pick/pkg/notification"
 )
 
 type ProcessorService struct {
 	storage     core.AnalyticsStorage
 	calculator  core.AnalyticsCalculator
 	aggregator  core.AnalyticsAggregator
+	dispatcher  *notification.Dispatcher
+	goals       []core.ConversionGoal
+	anomalies   *AnomalyDetector
+	cellSize    float64
+	window      time.Duration
+	mu          sync.RWMutex
 }
 
 func NewProcessorService(
@@ -23,9 +31,69 @@ func NewProcessorService(
 		storage:    storage,
 		calculator: calculator,
 		aggregator: aggregator,
+		dispatcher: notification.Default(),
+		anomalies:  NewAnomalyDetector(core.AnomalyConfig{}),
+		cellSize:   core.DefaultHeatmapCellSize,
+		window:     core.DefaultRealTimeWindow,
 	}
 }
 
+func (ps *ProcessorService) SetDispatcher(dispatcher *notification.Dispatcher) {
+	ps.dispatcher = dispatcher
+}
+
+// SetAnomalyConfig replaces the anomaly detector, discarding any baseline built so far.
+func (ps *ProcessorService) SetAnomalyConfig(config core.AnomalyConfig) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.anomalies = NewAnomalyDetector(config)
+}
+
+// SetHeatmapCellSize sets the grid cell edge in pixels; clicks falling in the same cell merge.
+func (ps *ProcessorService) SetHeatmapCellSize(size float64) error {
+	if size <= 0 {
+		return fmt.Errorf("heatmap cell size must be positive")
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.cellSize = size
+	return nil
+}
+
+// SetRealTimeWindow sets how far back real-time metrics look for active sessions, page
+// views and the top-N lists.
+func (ps *ProcessorService) SetRealTimeWindow(window time.Duration) error {
+	if window <= 0 {
+		return fmt.Errorf("real-time window must be positive")
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.window = window
+	return nil
+}
+
+func (ps *ProcessorService) RealTimeWindow() time.Duration {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	return ps.window
+}
+
+func (ps *ProcessorService) SetConversionGoals(goals []core.ConversionGoal) error {
+	if err := validateConversionGoals(goals); err != nil {
+		return err
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.goals = append([]core.ConversionGoal(nil), goals...)
+	return nil
+}
+
+func (ps *ProcessorService) ConversionGoals() []core.ConversionGoal {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	return append([]core.ConversionGoal(nil), ps.goals...)
+}
+
 func (ps *ProcessorService) ProcessUserJourney(sessionID string) (*core.UserJourney, error) {
 	session, err := ps.storage.GetSession(sessionID)
 	if err != nil {
@@ -54,12 +122,18 @@ func (ps *ProcessorService) ProcessUserJourney(sessionID string) (*core.UserJour
 	for _, event := range events {
 		if event.Type == "page_view" {
 			pageViews++
-			journeyPath = append(journeyPath, event.Metadata["path"].(string))
+			if path, ok := event.Metadata["path"].(string); ok {
+				journeyPath = append(journeyPath, path)
+			}
 		}
 	}
 
 	journey.TotalPages = pageViews
 	journey.JourneyPath = journeyPath
+	if touches := referrerTouches(events); len(touches) > 0 {
+		journey.FirstTouchReferrer = touches[0]
+		journey.LastTouchReferrer = touches[len(touches)-1]
+	}
 
 	if session.EndTime != nil {
 		journey.TotalTime = session.EndTime.Sub(session.StartTime).Milliseconds()
@@ -73,6 +147,11 @@ func (ps *ProcessorService) ProcessUserJourney(sessionID string) (*core.UserJour
 	}
 	journey.BounceRate = bounceRate > 0.5
 
+	goals := ps.ConversionGoals()
+	journey.CompletedGoals = completedGoals(goals, events)
+	journey.GoalCompleted = len(journey.CompletedGoals) > 0
+	journey.ConversionRate = safeRatio(len(journey.CompletedGoals), len(goals))
+
 	if err := ps.storage.SaveJourney(*journey); err != nil {
 		return nil, fmt.Errorf("failed to save journey: %w", err)
 	}
@@ -109,25 +188,27 @@ func (ps *ProcessorService) ProcessFunnelAnalysis(funnelID string, startTime, en
 
 func (ps *ProcessorService) ProcessRealTimeMetrics() (*core.RealTimeMetrics, error) {
 	now := time.Now()
-	startTime := now.Add(-5 * time.Minute)
+	window := ps.RealTimeWindow()
+	startTime := now.Add(-window)
 
 	request := core.AnalyticsRequest{
 		StartTime: &startTime,
 		EndTime:   &now,
 	}
-	sessions, err := ps.storage.GetSessions(request)
+	events, err := ps.storage.GetEvents(request)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get sessions: %w", err)
+		return nil, fmt.Errorf("failed to get events: %w", err)
 	}
 
-	events, err := ps.storage.GetEvents(request)
+	sessions, err := ps.activeSessions(request, events)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get events: %w", err)
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
 	}
 
 	metrics := &core.RealTimeMetrics{
 		Timestamp:      now,
-		ActiveUsers:    len(sessions),
+		WindowSeconds:  int(window / time.Second),
+		ActiveUsers:    countActiveUsers(sessions),
 		ActiveSessions: len(sessions),
 	}
 
@@ -137,7 +218,7 @@ func (ps *ProcessorService) ProcessRealTimeMetrics() (*core.RealTimeMetrics, err
 			pageViewCount++
 		}
 	}
-	metrics.PageViewsPerMinute = pageViewCount / 5
+	metrics.PageViewsPerMinute = int(float64(pageViewCount) / window.Minutes())
 
 	topPages, err := ps.aggregateTopPages(events)
 	if err != nil {
@@ -177,6 +258,7 @@ func (ps *ProcessorService) ProcessRealTimeMetrics() (*core.RealTimeMetrics, err
 	metrics.PerformanceScore = performanceScore
 
 	metrics.BounceRate = ps.calculateBounceRate(sessions)
+	metrics.ConversionRate = sessionConversionRate(ps.ConversionGoals(), events)
 
 	alerts, err := ps.storage.GetAlerts(request)
 	if err != nil {
@@ -187,6 +269,43 @@ func (ps *ProcessorService) ProcessRealTimeMetrics() (*core.RealTimeMetrics, err
 	return metrics, nil
 }
 
+// activeSessions returns the sessions that started within the request's window or had
+// an event in it, so a long-lived session still counts while it is in use.
+func (ps *ProcessorService) activeSessions(request core.AnalyticsRequest, events []core.AnalyticsEvent) ([]core.UserSession, error) {
+	sessions, err := ps.storage.GetSessions(request)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(sessions))
+	for _, session := range sessions {
+		seen[session.SessionID] = true
+	}
+	for _, event := range events {
+		if event.SessionID == "" || seen[event.SessionID] {
+			continue
+		}
+		seen[event.SessionID] = true
+		if session, err := ps.storage.GetSession(event.SessionID); err == nil {
+			sessions = append(sessions, *session)
+		}
+	}
+	return sessions, nil
+}
+
+// countActiveUsers counts distinct users, treating each anonymous session as its own user.
+func countActiveUsers(sessions []core.UserSession) int {
+	users := make(map[string]bool, len(sessions))
+	for _, session := range sessions {
+		if session.UserID != "" {
+			users["user:"+session.UserID] = true
+		} else {
+			users["session:"+session.SessionID] = true
+		}
+	}
+	return len(users)
+}
+
 func (ps *ProcessorService) ProcessInsights(sessionID string) ([]core.AnalyticsInsight, error) {
 	session, err := ps.storage.GetSession(sessionID)
 	if err != nil {
@@ -220,10 +339,24 @@ func (ps *ProcessorService) ProcessAlerts() ([]core.AnalyticsAlert, error) {
 
 	alerts := ps.generateAlerts(metrics)
 
+	ps.mu.RLock()
+	anomalies := ps.anomalies
+	ps.mu.RUnlock()
+	alerts = append(alerts, anomalies.Observe(metrics)...)
+
 	for _, alert := range alerts {
 		if err := ps.storage.SaveAlert(alert); err != nil {
 			return nil, fmt.Errorf("failed to save alert: %w", err)
 		}
+		ps.dispatcher.Dispatch(notification.Notification{
+			ID:        alert.ID,
+			Source:    "analytics",
+			Title:     alert.Title,
+			Message:   alert.Message,
+			Severity:  alert.Severity,
+			Timestamp: alert.Timestamp,
+			Metadata:  alert.Metadata,
+		})
 	}
 
 	return alerts, nil
@@ -361,7 +494,7 @@ func (ps *ProcessorService) aggregateTopReferrers(events []core.AnalyticsEvent)
 
 	var topReferrers []core.ReferrerStats
 	for referrer, count := range referrerCounts {
-		percentage := float64(count) / float64(totalEvents) * 100
+		percentage := safePercent(count, totalEvents)
 		topReferrers = append(topReferrers, core.ReferrerStats{
 			Referrer:   referrer,
 			Count:      count,
@@ -392,7 +525,7 @@ func (ps *ProcessorService) aggregateTopCountries(sessions []core.UserSession) (
 
 	var topCountries []core.CountryStats
 	for country, count := range countryCounts {
-		percentage := float64(count) / float64(totalSessions) * 100
+		percentage := safePercent(count, totalSessions)
 		topCountries = append(topCountries, core.CountryStats{
 			Country:    country,
 			Count:      count,
@@ -423,7 +556,7 @@ func (ps *ProcessorService) aggregateTopDevices(sessions []core.UserSession) ([]
 
 	var topDevices []core.DeviceStats
 	for device, count := range deviceCounts {
-		percentage := float64(count) / float64(totalSessions) * 100
+		percentage := safePercent(count, totalSessions)
 		topDevices = append(topDevices, core.DeviceStats{
 			Device:     device,
 			Count:      count,
@@ -454,7 +587,7 @@ func (ps *ProcessorService) aggregateTopBrowsers(sessions []core.UserSession) ([
 
 	var topBrowsers []core.BrowserStats
 	for browser, count := range browserCounts {
-		percentage := float64(count) / float64(totalSessions) * 100
+		percentage := safePercent(count, totalSessions)
 		topBrowsers = append(topBrowsers, core.BrowserStats{
 			Browser:    browser,
 			Count:      count,
@@ -489,10 +622,6 @@ func (ps *ProcessorService) filterPerformanceEvents(events []core.AnalyticsEvent
 }
 
 func (ps *ProcessorService) calculateBounceRate(sessions []core.UserSession) float64 {
-	if len(sessions) == 0 {
-		return 0
-	}
-
 	bounceCount := 0
 	for _, session := range sessions {
 		if session.EndTime != nil && session.StartTime.Add(time.Minute).After(*session.EndTime) {
@@ -500,7 +629,7 @@ func (ps *ProcessorService) calculateBounceRate(sessions []core.UserSession) flo
 		}
 	}
 
-	return float64(bounceCount) / float64(len(sessions))
+	return safeRatio(bounceCount, len(sessions))
 }
 
 func (ps *ProcessorService) generateInsights(session *core.UserSession, events []core.AnalyticsEvent) []core.AnalyticsInsight {
@@ -556,35 +685,11 @@ func (ps *ProcessorService) generateAlerts(metrics *core.RealTimeMetrics) []core
 }
 
 func (ps *ProcessorService) processHeatmapPoints(events []core.AnalyticsEvent) []core.HeatmapPoint {
-	pointMap := make(map[string]core.HeatmapPoint)
-
-	for _, event := range events {
-		if coordinates, ok := event.Metadata["coordinates"].(map[string]float64); ok {
-			x := coordinates["x"]
-			y := coordinates["y"]
-			key := fmt.Sprintf("%.2f,%.2f", x, y)
-
-			if point, exists := pointMap[key]; exists {
-				point.Count++
-				point.Intensity = float64(point.Count) / 10.0
-				pointMap[key] = point
-			} else {
-				pointMap[key] = core.HeatmapPoint{
-					X:         x,
-					Y:         y,
-					Intensity: 1.0,
-					Count:     1,
-				}
-			}
-		}
-	}
-
-	var points []core.HeatmapPoint
-	for _, point := range pointMap {
-		points = append(points, point)
-	}
+	ps.mu.RLock()
+	cellSize := ps.cellSize
+	ps.mu.RUnlock()
 
-	return points
+	return binHeatmapPoints(events, cellSize)
 }
 
 func (ps *ProcessorService) identifyRootCause(stage core.FunnelStage, dropOffRate float64) string {
@@ -611,9 +716,9 @@ func (ps *ProcessorService) generateBottleneckRecommendations(stage core.FunnelS
 }
 
 func generateInsightID() string {
-	return fmt.Sprintf("insight_%d", time.Now().UnixNano())
+	return nextID("insight")
 }
 
 func generateAlertID() string {
-	return fmt.Sprintf("alert_%d", time.Now().UnixNano())
+	return nextID("alert")
 }
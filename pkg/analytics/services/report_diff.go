@@ -0,0 +1,128 @@
+package services
+
+import (
+	"time"
+
+	"github.com/cherry-pick/pkg/analytics/core"
+)
+
+// compareReports computes how compare moved against base: a delta per summary metric,
+// rank changes in the top pages, and the insights that appeared or went away. Insights
+// are matched by type and title, since every report generates fresh insight IDs.
+func compareReports(base, compare *core.AnalyticsReport) *core.AnalyticsReportDiff {
+	diff := &core.AnalyticsReportDiff{
+		BaseReportID:    base.ID,
+		CompareReportID: compare.ID,
+		GeneratedAt:     time.Now(),
+	}
+
+	before, after := base.Summary, compare.Summary
+	diff.Metrics = []core.MetricDelta{
+		metricDelta("totalSessions", float64(before.TotalSessions), float64(after.TotalSessions)),
+		metricDelta("totalPageViews", float64(before.TotalPageViews), float64(after.TotalPageViews)),
+		metricDelta("uniqueUsers", float64(before.UniqueUsers), float64(after.UniqueUsers)),
+		metricDelta("avgSessionDuration", float64(before.AvgSessionDuration), float64(after.AvgSessionDuration)),
+		metricDelta("bounceRate", before.BounceRate, after.BounceRate),
+		metricDelta("conversionRate", before.ConversionRate, after.ConversionRate),
+		metricDelta("avgPageLoadTime", float64(before.AvgPageLoadTime), float64(after.AvgPageLoadTime)),
+		metricDelta("performanceScore", before.PerformanceScore, after.PerformanceScore),
+	}
+
+	diff.TopPages = pageRankChanges(base.TopPages, compare.TopPages)
+	diff.NewInsights = insightsMissingFrom(compare.Insights, base.Insights)
+	diff.ResolvedInsights = insightsMissingFrom(base.Insights, compare.Insights)
+
+	return diff
+}
+
+func metricDelta(metric string, before, after float64) core.MetricDelta {
+	delta := core.MetricDelta{
+		Metric:    metric,
+		Base:      before,
+		Compare:   after,
+		Change:    after - before,
+		Direction: core.DirectionUnchanged,
+	}
+	if before != 0 {
+		percent := delta.Change / before * 100
+		delta.PercentChange = &percent
+	}
+
+	switch {
+	case delta.Change > 0:
+		delta.Direction = core.DirectionUp
+	case delta.Change < 0:
+		delta.Direction = core.DirectionDown
+	}
+	return delta
+}
+
+// pageRankChanges lists the compared report's pages in rank order, then the base pages
+// that dropped out of it.
+func pageRankChanges(base, compare []core.PageStats) []core.PageRankChange {
+	baseRanks := make(map[string]int, len(base))
+	for i, page := range base {
+		baseRanks[page.Path] = i + 1
+	}
+
+	changes := make([]core.PageRankChange, 0, len(base)+len(compare))
+	seen := make(map[string]bool, len(compare))
+	for i, page := range compare {
+		seen[page.Path] = true
+		change := core.PageRankChange{
+			Path:         page.Path,
+			CompareRank:  i + 1,
+			CompareViews: page.Views,
+			Direction:    core.DirectionNew,
+		}
+
+		if rank, exists := baseRanks[page.Path]; exists {
+			change.BaseRank = rank
+			change.BaseViews = base[rank-1].Views
+			change.Movement = rank - change.CompareRank
+			switch {
+			case change.Movement > 0:
+				change.Direction = core.DirectionUp
+			case change.Movement < 0:
+				change.Direction = core.DirectionDown
+			default:
+				change.Direction = core.DirectionUnchanged
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	for i, page := range base {
+		if seen[page.Path] {
+			continue
+		}
+		changes = append(changes, core.PageRankChange{
+			Path:      page.Path,
+			BaseRank:  i + 1,
+			BaseViews: page.Views,
+			Direction: core.DirectionDropped,
+		})
+	}
+
+	return changes
+}
+
+// insightsMissingFrom returns the insights in list that have no counterpart in other.
+func insightsMissingFrom(list, other []core.AnalyticsInsight) []core.AnalyticsInsight {
+	present := make(map[string]bool, len(other))
+	for _, insight := range other {
+		present[insightKey(insight)] = true
+	}
+
+	missing := []core.AnalyticsInsight{}
+	for _, insight := range list {
+		if !present[insightKey(insight)] {
+			missing = append(missing, insight)
+		}
+	}
+	return missing
+}
+
+func insightKey(insight core.AnalyticsInsight) string {
+	return insight.Type + "\x00" + insight.Title
+}
@@ -70,6 +70,12 @@ func (rs *ReporterService) GenerateReport(request core.AnalyticsRequest) (*core.
 		return nil, fmt.Errorf("failed to generate insights: %w", err)
 	}
 
+	events, err := rs.storage.GetEvents(core.AnalyticsRequest{StartTime: request.StartTime, EndTime: request.EndTime})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events: %w", err)
+	}
+	topPages := NewCalculatorService(rs.storage).CalculateTopPages(events, core.DefaultReportTopPages)
+
 	report := &core.AnalyticsReport{
 		ID:              generateReportID(),
 		Title:           "Analytics Report",
@@ -84,6 +90,7 @@ func (rs *ReporterService) GenerateReport(request core.AnalyticsRequest) (*core.
 		BehavioralData:  behavioralData,
 		Insights:        insights,
 		Recommendations: rs.generateRecommendations(summary, insights),
+		TopPages:        topPages,
 		Metadata: map[string]interface{}{
 			"generated_by": "analytics_service",
 			"version":      "1.0.0",
@@ -239,10 +246,6 @@ func (rs *ReporterService) countUniqueUsers(sessions []core.UserSession) int {
 }
 
 func (rs *ReporterService) calculateBounceRate(sessions []core.UserSession) float64 {
-	if len(sessions) == 0 {
-		return 0
-	}
-
 	bounceCount := 0
 	for _, session := range sessions {
 		if session.EndTime != nil && session.StartTime.Add(time.Minute).After(*session.EndTime) {
@@ -250,7 +253,7 @@ func (rs *ReporterService) calculateBounceRate(sessions []core.UserSession) floa
 		}
 	}
 
-	return float64(bounceCount) / float64(len(sessions))
+	return safeRatio(bounceCount, len(sessions))
 }
 
 func (rs *ReporterService) getTopPage(events []core.AnalyticsEvent) string {
@@ -403,5 +406,5 @@ func (rs *ReporterService) generateAdditionalInsights(startTime, endTime time.Ti
 }
 
 func generateReportID() string {
-	return fmt.Sprintf("report_%d", time.Now().UnixNano())
+	return nextID("report")
 }
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/cherry-pick/pkg/analytics/core"
+)
+
+type BasicUserAgentResolver struct{}
+
+func NewBasicUserAgentResolver() *BasicUserAgentResolver {
+	return &BasicUserAgentResolver{}
+}
+
+func (r *BasicUserAgentResolver) Resolve(userAgent string) (*core.UserAgentInfo, error) {
+	if userAgent == "" {
+		return nil, fmt.Errorf("user agent is empty")
+	}
+	ua := strings.ToLower(userAgent)
+	return &core.UserAgentInfo{
+		Browser: detectBrowser(ua),
+		OS:      detectOS(ua),
+		Device:  detectDevice(ua),
+	}, nil
+}
+
+func detectBrowser(ua string) string {
+	switch {
+	case strings.Contains(ua, "edg/"):
+		return "Edge"
+	case strings.Contains(ua, "opr/") || strings.Contains(ua, "opera"):
+		return "Opera"
+	case strings.Contains(ua, "firefox/"):
+		return "Firefox"
+	case strings.Contains(ua, "chrome/") || strings.Contains(ua, "crios/"):
+		return "Chrome"
+	case strings.Contains(ua, "safari/"):
+		return "Safari"
+	case strings.Contains(ua, "bot") || strings.Contains(ua, "crawler") || strings.Contains(ua, "spider"):
+		return "Bot"
+	default:
+		return "Other"
+	}
+}
+
+func detectOS(ua string) string {
+	switch {
+	case strings.Contains(ua, "android"):
+		return "Android"
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad") || strings.Contains(ua, "ios"):
+		return "iOS"
+	case strings.Contains(ua, "windows"):
+		return "Windows"
+	case strings.Contains(ua, "mac os") || strings.Contains(ua, "macintosh"):
+		return "macOS"
+	case strings.Contains(ua, "linux"):
+		return "Linux"
+	default:
+		return "Other"
+	}
+}
+
+func detectDevice(ua string) string {
+	switch {
+	case strings.Contains(ua, "ipad") || strings.Contains(ua, "tablet"):
+		return "Tablet"
+	case strings.Contains(ua, "mobi") || strings.Contains(ua, "iphone") || strings.Contains(ua, "android"):
+		return "Mobile"
+	default:
+		return "Desktop"
+	}
+}
+
+type lruEntry struct {
+	key   string
+	value interface{}
+}
+
+// resolverCache is a plain LRU without expiry: UA strings and IP locations are stable enough to keep until evicted.
+type resolverCache struct {
+	capacity  int
+	entries   map[string]*list.Element
+	order     *list.List
+	hits      int64
+	misses    int64
+	evictions int64
+	mu        sync.Mutex
+}
+
+func newResolverCache(capacity int) *resolverCache {
+	if capacity <= 0 {
+		capacity = core.DefaultResolverCacheSize
+	}
+	return &resolverCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *resolverCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, exists := c.entries[key]
+	if !exists {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(element)
+	return element.Value.(*lruEntry).value, true
+}
+
+func (c *resolverCache) set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, exists := c.entries[key]; exists {
+		element.Value.(*lruEntry).value = value
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+		c.order.Remove(oldest)
+		c.evictions++
+	}
+}
+
+func (c *resolverCache) stats() core.ResolverCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return core.ResolverCacheStats{
+		Capacity:  c.capacity,
+		Size:      c.order.Len(),
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
+
+type CachedUserAgentResolver struct {
+	resolver core.UserAgentResolver
+	cache    *resolverCache
+}
+
+func NewCachedUserAgentResolver(resolver core.UserAgentResolver, capacity int) *CachedUserAgentResolver {
+	return &CachedUserAgentResolver{
+		resolver: resolver,
+		cache:    newResolverCache(capacity),
+	}
+}
+
+func (r *CachedUserAgentResolver) Resolve(userAgent string) (*core.UserAgentInfo, error) {
+	if cached, ok := r.cache.get(userAgent); ok {
+		info := cached.(core.UserAgentInfo)
+		return &info, nil
+	}
+	info, err := r.resolver.Resolve(userAgent)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.set(userAgent, *info)
+	return info, nil
+}
+
+func (r *CachedUserAgentResolver) Stats() core.ResolverCacheStats {
+	return r.cache.stats()
+}
+
+type CachedGeoIPResolver struct {
+	resolver core.GeoIPResolver
+	cache    *resolverCache
+}
+
+func NewCachedGeoIPResolver(resolver core.GeoIPResolver, capacity int) *CachedGeoIPResolver {
+	return &CachedGeoIPResolver{
+		resolver: resolver,
+		cache:    newResolverCache(capacity),
+	}
+}
+
+func (r *CachedGeoIPResolver) Lookup(ip string) (*core.GeoInfo, error) {
+	if cached, ok := r.cache.get(ip); ok {
+		info := cached.(core.GeoInfo)
+		return &info, nil
+	}
+	info, err := r.resolver.Lookup(ip)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.set(ip, *info)
+	return info, nil
+}
+
+func (r *CachedGeoIPResolver) Stats() core.ResolverCacheStats {
+	return r.cache.stats()
+}
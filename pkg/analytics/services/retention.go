@@ -0,0 +1,157 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cherry-pick/pkg/analytics/core"
+	"github.com/cherry-pick/pkg/logging"
+)
+
+// RetentionEnforcer periodically purges records older than the policy allows.
+// Start and Stop may be called from any goroutine; Stop waits for an in-flight sweep.
+type RetentionEnforcer struct {
+	storage core.AnalyticsStorage
+	policy  core.RetentionPolicy
+	now     func() time.Time
+	last    *core.RetentionResult
+	stop    chan struct{}
+	done    chan struct{}
+	mu      sync.Mutex
+}
+
+// NewRetentionEnforcer uses now as its clock; pass nil for time.Now.
+func NewRetentionEnforcer(storage core.AnalyticsStorage, policy core.RetentionPolicy, now func() time.Time) (*RetentionEnforcer, error) {
+	if err := validateRetentionPolicy(policy); err != nil {
+		return nil, err
+	}
+	if policy.Interval == 0 {
+		policy.Interval = core.DefaultRetentionPolicy().Interval
+	}
+	if now == nil {
+		now = time.Now
+	}
+
+	return &RetentionEnforcer{
+		storage: storage,
+		policy:  policy,
+		now:     now,
+	}, nil
+}
+
+func validateRetentionPolicy(policy core.RetentionPolicy) error {
+	windows := map[string]time.Duration{
+		"events":   policy.Events,
+		"sessions": policy.Sessions,
+		"journeys": policy.Journeys,
+		"insights": policy.Insights,
+		"alerts":   policy.Alerts,
+		"reports":  policy.Reports,
+		"interval": policy.Interval,
+	}
+	for name, window := range windows {
+		if window < 0 {
+			return fmt.Errorf("retention %s cannot be negative", name)
+		}
+	}
+	return nil
+}
+
+func (re *RetentionEnforcer) Start() {
+	re.mu.Lock()
+	defer re.mu.Unlock()
+
+	if re.stop != nil {
+		return
+	}
+	re.stop = make(chan struct{})
+	re.done = make(chan struct{})
+	go re.run(re.stop, re.done)
+}
+
+func (re *RetentionEnforcer) Stop() {
+	re.mu.Lock()
+	stop, done := re.stop, re.done
+	re.stop, re.done = nil, nil
+	re.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+func (re *RetentionEnforcer) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(re.policy.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if _, err := re.RunOnce(); err != nil {
+				logging.Default().Warn("Retention sweep failed", "error", err)
+			}
+		}
+	}
+}
+
+// RunOnce performs a single sweep using the enforcer's clock.
+func (re *RetentionEnforcer) RunOnce() (core.RetentionResult, error) {
+	now := re.now()
+	purged, err := re.storage.CleanupExpired(re.Cutoffs(now))
+	if err != nil {
+		return core.RetentionResult{}, fmt.Errorf("failed to purge expired data: %w", err)
+	}
+
+	result := core.RetentionResult{
+		RanAt:    now,
+		Purged:   purged,
+		Duration: re.now().Sub(now),
+	}
+
+	re.mu.Lock()
+	re.last = &result
+	re.mu.Unlock()
+
+	return result, nil
+}
+
+func (re *RetentionEnforcer) Cutoffs(now time.Time) core.RetentionCutoffs {
+	cutoff := func(window time.Duration) time.Time {
+		if window <= 0 {
+			return time.Time{}
+		}
+		return now.Add(-window)
+	}
+
+	return core.RetentionCutoffs{
+		Events:   cutoff(re.policy.Events),
+		Sessions: cutoff(re.policy.Sessions),
+		Journeys: cutoff(re.policy.Journeys),
+		Insights: cutoff(re.policy.Insights),
+		Alerts:   cutoff(re.policy.Alerts),
+		Reports:  cutoff(re.policy.Reports),
+	}
+}
+
+func (re *RetentionEnforcer) Policy() core.RetentionPolicy {
+	return re.policy
+}
+
+// LastRun returns the most recent sweep, or nil if none has run yet.
+func (re *RetentionEnforcer) LastRun() *core.RetentionResult {
+	re.mu.Lock()
+	defer re.mu.Unlock()
+
+	if re.last == nil {
+		return nil
+	}
+	last := *re.last
+	return &last
+}
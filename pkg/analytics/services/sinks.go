@@ -0,0 +1,187 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cherry-pick/pkg/analytics/core"
+)
+
+const rotatedFileTimeFormat = "20060102T150405.000000000"
+
+// JSONSink writes each record as a single JSON line to w.
+type JSONSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewJSONSink(w io.Writer) *JSONSink {
+	return &JSONSink{w: w}
+}
+
+func (js *JSONSink) WriteRecord(record core.ActivityRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode activity record: %w", err)
+	}
+
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	_, err = js.w.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the underlying writer when it is an io.Closer.
+func (js *JSONSink) Close() error {
+	if closer, ok := js.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// FileSink appends JSON lines to a file and rotates it by size and age.
+type FileSink struct {
+	mu       sync.Mutex
+	config   core.FileSinkConfig
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+func NewFileSink(config core.FileSinkConfig) (*FileSink, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("file sink path is required")
+	}
+	if config.MaxSizeBytes < 0 || config.MaxAge < 0 || config.MaxBackups < 0 {
+		return nil, fmt.Errorf("file sink limits must not be negative")
+	}
+
+	fs := &FileSink{config: config}
+	if err := fs.open(); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+func (fs *FileSink) WriteRecord(record core.ActivityRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode activity record: %w", err)
+	}
+	line = append(line, '\n')
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.file == nil {
+		return fmt.Errorf("file sink is closed")
+	}
+	if fs.shouldRotate(int64(len(line))) {
+		if err := fs.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := fs.file.Write(line)
+	fs.size += int64(n)
+	return err
+}
+
+func (fs *FileSink) Close() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.file == nil {
+		return nil
+	}
+	err := fs.file.Close()
+	fs.file = nil
+	return err
+}
+
+// shouldRotate never rotates an empty file, so a single oversized record is still written.
+func (fs *FileSink) shouldRotate(next int64) bool {
+	if fs.size == 0 {
+		return false
+	}
+	if fs.config.MaxSizeBytes > 0 && fs.size+next > fs.config.MaxSizeBytes {
+		return true
+	}
+	return fs.config.MaxAge > 0 && time.Since(fs.openedAt) >= fs.config.MaxAge
+}
+
+func (fs *FileSink) open() error {
+	if dir := filepath.Dir(fs.config.Path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create activity log directory: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(fs.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open activity log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat activity log: %w", err)
+	}
+
+	fs.file = file
+	fs.size = info.Size()
+	fs.openedAt = time.Now()
+	return nil
+}
+
+func (fs *FileSink) rotate() error {
+	if err := fs.file.Close(); err != nil {
+		return fmt.Errorf("failed to close activity log: %w", err)
+	}
+	fs.file = nil
+
+	rotated := fs.config.Path + "." + time.Now().UTC().Format(rotatedFileTimeFormat)
+	if err := os.Rename(fs.config.Path, rotated); err != nil {
+		return fmt.Errorf("failed to rotate activity log: %w", err)
+	}
+	if err := fs.pruneBackups(); err != nil {
+		return err
+	}
+	return fs.open()
+}
+
+// pruneBackups removes the oldest rotated files beyond MaxBackups. The timestamp suffix
+// sorts lexically in time order.
+func (fs *FileSink) pruneBackups() error {
+	if fs.config.MaxBackups == 0 {
+		return nil
+	}
+
+	backups, err := filepath.Glob(fs.config.Path + ".*")
+	if err != nil {
+		return err
+	}
+	prefix := fs.config.Path + "."
+	rotated := backups[:0]
+	for _, backup := range backups {
+		if _, err := time.Parse(rotatedFileTimeFormat, strings.TrimPrefix(backup, prefix)); err == nil {
+			rotated = append(rotated, backup)
+		}
+	}
+	sort.Strings(rotated)
+
+	for len(rotated) > fs.config.MaxBackups {
+		if err := os.Remove(rotated[0]); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove old activity log: %w", err)
+		}
+		rotated = rotated[1:]
+	}
+	return nil
+}
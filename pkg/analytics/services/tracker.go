@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"net/url"
 	"sync"
 	"time"
 
@@ -9,9 +10,11 @@ import (
 )
 
 type TrackerService struct {
-	storage   core.AnalyticsStorage
-	validator core.AnalyticsValidator
-	mu        sync.RWMutex
+	storage     core.AnalyticsStorage
+	validator   core.AnalyticsValidator
+	uaResolver  *CachedUserAgentResolver
+	geoResolver *CachedGeoIPResolver
+	mu          sync.RWMutex
 }
 
 func NewTrackerService(storage core.AnalyticsStorage, validator core.AnalyticsValidator) *TrackerService {
@@ -21,6 +24,33 @@ func NewTrackerService(storage core.AnalyticsStorage, validator core.AnalyticsVa
 	}
 }
 
+// SetResolvers wraps the given resolvers in LRU caches; a nil resolver disables that enrichment.
+func (ts *TrackerService) SetResolvers(uaResolver core.UserAgentResolver, geoResolver core.GeoIPResolver, cacheSize int) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.uaResolver = nil
+	ts.geoResolver = nil
+	if uaResolver != nil {
+		ts.uaResolver = NewCachedUserAgentResolver(uaResolver, cacheSize)
+	}
+	if geoResolver != nil {
+		ts.geoResolver = NewCachedGeoIPResolver(geoResolver, cacheSize)
+	}
+}
+
+func (ts *TrackerService) ResolverStats() map[string]core.ResolverCacheStats {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	stats := make(map[string]core.ResolverCacheStats)
+	if ts.uaResolver != nil {
+		stats["userAgent"] = ts.uaResolver.Stats()
+	}
+	if ts.geoResolver != nil {
+		stats["geoIP"] = ts.geoResolver.Stats()
+	}
+	return stats
+}
+
 func (ts *TrackerService) TrackPageView(event core.PageViewEvent) error {
 	if err := ts.validator.ValidateEvent(event.AnalyticsEvent); err != nil {
 		return fmt.Errorf("invalid page view event: %w", err)
@@ -31,6 +61,7 @@ func (ts *TrackerService) TrackPageView(event core.PageViewEvent) error {
 	if event.ID == "" {
 		event.ID = generateEventID()
 	}
+	event.AnalyticsEvent.Metadata = pageViewMetadata(event)
 	if err := ts.storage.SaveEvent(event.AnalyticsEvent); err != nil {
 		return fmt.Errorf("failed to save page view event: %w", err)
 	}
@@ -63,6 +94,7 @@ func (ts *TrackerService) TrackPerformance(event core.PerformanceEvent) error {
 	if event.ID == "" {
 		event.ID = generateEventID()
 	}
+	event.AnalyticsEvent.Metadata = performanceMetadata(event)
 	if err := ts.storage.SaveEvent(event.AnalyticsEvent); err != nil {
 		return fmt.Errorf("failed to save performance event: %w", err)
 	}
@@ -97,6 +129,7 @@ func (ts *TrackerService) CreateSession(session core.UserSession) error {
 		session.StartTime = time.Now()
 	}
 	session.IsActive = true
+	ts.enrichSession(&session)
 	if err := ts.storage.SaveSession(session); err != nil {
 		return fmt.Errorf("failed to save session: %w", err)
 	}
@@ -127,6 +160,88 @@ func (ts *TrackerService) EndSession(sessionID string) error {
 	return nil
 }
 
+func (ts *TrackerService) enrichSession(session *core.UserSession) {
+	ts.mu.RLock()
+	uaResolver, geoResolver := ts.uaResolver, ts.geoResolver
+	ts.mu.RUnlock()
+
+	if uaResolver != nil && session.UserAgent != "" {
+		if info, err := uaResolver.Resolve(session.UserAgent); err == nil {
+			if session.Browser == "" {
+				session.Browser = info.Browser
+			}
+			if session.OS == "" {
+				session.OS = info.OS
+			}
+			if session.Device == "" {
+				session.Device = info.Device
+			}
+		}
+	}
+	if geoResolver != nil && session.IPAddress != "" && session.Country == "" {
+		if info, err := geoResolver.Lookup(session.IPAddress); err == nil {
+			session.Country = info.Country
+		}
+	}
+}
+
+// pageViewMetadata copies the page view's URL, path and referrer into a copy of its
+// metadata, where journeys and reports read them, without overwriting keys the client set.
+func pageViewMetadata(event core.PageViewEvent) map[string]interface{} {
+	metadata := make(map[string]interface{}, len(event.Metadata)+3)
+	for key, value := range event.Metadata {
+		metadata[key] = value
+	}
+	fields := map[string]string{"url": event.URL, "path": event.Path, "referrer": event.Referrer}
+	for key, value := range fields {
+		if _, exists := metadata[key]; !exists && value != "" {
+			metadata[key] = value
+		}
+	}
+	return metadata
+}
+
+// performanceMetadata copies the page and its Core Web Vitals into a copy of the event's
+// metadata, where the web vitals report reads them, without overwriting keys the client
+// set. Unmeasured vitals are left out; CLS is kept at 0 when the page reported LCP,
+// since a page that never shifted scores 0.
+func performanceMetadata(event core.PerformanceEvent) map[string]interface{} {
+	metadata := make(map[string]interface{}, len(event.Metadata)+6)
+	for key, value := range event.Metadata {
+		metadata[key] = value
+	}
+
+	fields := map[string]interface{}{}
+	if event.URL != "" {
+		fields["url"] = event.URL
+		if parsed, err := url.Parse(event.URL); err == nil && parsed.Path != "" {
+			fields["path"] = parsed.Path
+		}
+	}
+	if event.PageID != "" {
+		fields["pageId"] = event.PageID
+	}
+	if event.LargestContentfulPaint > 0 {
+		fields["largestContentfulPaint"] = event.LargestContentfulPaint
+	}
+	if event.CumulativeLayoutShift > 0 || event.LargestContentfulPaint > 0 {
+		fields["cumulativeLayoutShift"] = event.CumulativeLayoutShift
+	}
+	if event.FirstInputDelay > 0 {
+		fields["firstInputDelay"] = event.FirstInputDelay
+	}
+	if event.InteractionToNextPaint > 0 {
+		fields["interactionToNextPaint"] = event.InteractionToNextPaint
+	}
+
+	for key, value := range fields {
+		if _, exists := metadata[key]; !exists {
+			metadata[key] = value
+		}
+	}
+	return metadata
+}
+
 func generateEventID() string {
-	return fmt.Sprintf("event_%d", time.Now().UnixNano())
+	return nextID("event")
 }
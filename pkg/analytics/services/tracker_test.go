@@ -0,0 +1,101 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cherry-pick/pkg/analytics/core"
+	"github.com/cherry-pick/pkg/analytics/storage"
+)
+
+// acceptingValidator lets events without an ID through, so the tracker assigns one.
+type acceptingValidator struct{}
+
+func (acceptingValidator) ValidateEvent(event core.AnalyticsEvent) error       { return nil }
+func (acceptingValidator) ValidateSession(session core.UserSession) error      { return nil }
+func (acceptingValidator) ValidateJourney(journey core.UserJourney) error      { return nil }
+func (acceptingValidator) ValidateRequest(request core.AnalyticsRequest) error { return nil }
+func (acceptingValidator) ValidateFunnel(funnel core.FunnelDefinition) error   { return nil }
+
+func TestGeneratedIDsAreUniqueAcrossGoroutines(t *testing.T) {
+	const goroutines, perGoroutine = 8, 500
+	generators := []func() string{generateEventID, generateInsightID, generateAlertID, generateReportID}
+
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	var wg sync.WaitGroup
+
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			generate := generators[g%len(generators)]
+			ids := make([]string, 0, perGoroutine)
+			for i := 0; i < perGoroutine; i++ {
+				ids = append(ids, generate())
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, id := range ids {
+				seen[id] = true
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if len(seen) != goroutines*perGoroutine {
+		t.Errorf("Expected %d unique IDs, got %d", goroutines*perGoroutine, len(seen))
+	}
+}
+
+func TestTrackCustomEventConcurrently(t *testing.T) {
+	const goroutines, perGoroutine = 8, 100
+	store := storage.NewMemoryStorage()
+	tracker := NewTrackerService(store, acceptingValidator{})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines*perGoroutine)
+
+	for g := 0; g < goroutines; g++ {
+		wg.Add(2)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				event := core.AnalyticsEvent{
+					Type:      "custom",
+					SessionID: fmt.Sprintf("session-%d", g),
+					Timestamp: time.Now(),
+					Metadata:  map[string]interface{}{"i": i},
+				}
+				if err := tracker.TrackCustomEvent(event); err != nil {
+					errs <- err
+				}
+			}
+		}(g)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGoroutine/10; i++ {
+				if _, err := store.GetEvents(core.AnalyticsRequest{}); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	events, err := store.GetEvents(core.AnalyticsRequest{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(events) != goroutines*perGoroutine {
+		t.Errorf("Expected %d stored events, got %d", goroutines*perGoroutine, len(events))
+	}
+}
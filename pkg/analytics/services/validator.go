@@ -125,6 +125,43 @@ func (vs *ValidatorService) ValidateRequest(request core.AnalyticsRequest) error
 	return nil
 }
 
+func (vs *ValidatorService) ValidateFunnel(funnel core.FunnelDefinition) error {
+	if strings.TrimSpace(funnel.Name) == "" {
+		return fmt.Errorf("funnel name is required")
+	}
+	if len(funnel.Stages) < 2 {
+		return fmt.Errorf("funnel must have at least 2 stages")
+	}
+	seen := make(map[string]bool, len(funnel.Stages))
+	for i, stage := range funnel.Stages {
+		if stage.ID == "" {
+			return fmt.Errorf("stage %d: ID is required", i)
+		}
+		if seen[stage.ID] {
+			return fmt.Errorf("stage %d: duplicate stage ID %s", i, stage.ID)
+		}
+		seen[stage.ID] = true
+		if len(stage.Rules) == 0 {
+			return fmt.Errorf("stage %s: at least one matching rule is required", stage.ID)
+		}
+		for _, rule := range stage.Rules {
+			switch rule.Match {
+			case core.FunnelMatchEquals, core.FunnelMatchPrefix:
+			case core.FunnelMatchRegex:
+				if _, err := regexp.Compile(rule.Value); err != nil {
+					return fmt.Errorf("stage %s: invalid regex %q: %w", stage.ID, rule.Value, err)
+				}
+			default:
+				return fmt.Errorf("stage %s: unsupported match type: %s", stage.ID, rule.Match)
+			}
+			if rule.Value == "" {
+				return fmt.Errorf("stage %s: rule value is required", stage.ID)
+			}
+		}
+	}
+	return nil
+}
+
 func (vs *ValidatorService) isValidEventType(eventType string, validTypes []string) bool {
 	for _, validType := range validTypes {
 		if eventType == validType {
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/cherry-pick/pkg/analytics/core"
+)
+
+// webVitalMetadataKeys maps each Core Web Vital to the metadata key TrackPerformance
+// stores it under.
+var webVitalMetadataKeys = map[string]string{
+	core.WebVitalLCP: "largestContentfulPaint",
+	core.WebVitalCLS: "cumulativeLayoutShift",
+	core.WebVitalFID: "firstInputDelay",
+	core.WebVitalINP: "interactionToNextPaint",
+}
+
+// GenerateWebVitalsReport rates the p75 of each Core Web Vital over the performance
+// events recorded for pagePath between startTime and endTime. An empty pagePath covers
+// every page.
+func (rs *ReporterService) GenerateWebVitalsReport(pagePath string, startTime, endTime time.Time) (*core.WebVitalsReport, error) {
+	events, err := rs.storage.GetEvents(core.AnalyticsRequest{
+		StartTime: &startTime,
+		EndTime:   &endTime,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get performance events: %w", err)
+	}
+
+	var matched []core.AnalyticsEvent
+	for _, event := range events {
+		if event.Type != "performance" {
+			continue
+		}
+		if pagePath != "" && eventPagePath(event) != pagePath {
+			continue
+		}
+		matched = append(matched, event)
+	}
+
+	return buildWebVitalsReport(matched, pagePath, startTime, endTime), nil
+}
+
+func buildWebVitalsReport(events []core.AnalyticsEvent, pagePath string, startTime, endTime time.Time) *core.WebVitalsReport {
+	samples := make(map[string][]float64, len(webVitalMetadataKeys))
+	for _, event := range events {
+		for metric, key := range webVitalMetadataKeys {
+			if value, ok := metadataNumber(event.Metadata[key]); ok && value >= 0 {
+				samples[metric] = append(samples[metric], value)
+			}
+		}
+	}
+
+	return &core.WebVitalsReport{
+		PagePath:  pagePath,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Samples:   len(events),
+		LCP:       webVitalStat(core.WebVitalLCP, samples[core.WebVitalLCP]),
+		CLS:       webVitalStat(core.WebVitalCLS, samples[core.WebVitalCLS]),
+		FID:       webVitalStat(core.WebVitalFID, samples[core.WebVitalFID]),
+		INP:       webVitalStat(core.WebVitalINP, samples[core.WebVitalINP]),
+	}
+}
+
+func webVitalStat(metric string, values []float64) *core.WebVitalStat {
+	if len(values) == 0 {
+		return nil
+	}
+
+	p75 := percentile75(values)
+	return &core.WebVitalStat{
+		P75:     p75,
+		Samples: len(values),
+		Rating:  rateWebVital(metric, p75),
+	}
+}
+
+// percentile75 uses the nearest-rank method, so the result is always an observed value.
+func percentile75(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(0.75 * float64(len(sorted))))
+	return sorted[rank-1]
+}
+
+func rateWebVital(metric string, value float64) string {
+	threshold := core.WebVitalThresholds[metric]
+	switch {
+	case value <= threshold.Good:
+		return core.WebVitalGood
+	case value > threshold.Poor:
+		return core.WebVitalPoor
+	default:
+		return core.WebVitalNeedsImprovement
+	}
+}
+
+// eventPagePath reads the page path from metadata, falling back to the path of the
+// event's URL.
+func eventPagePath(event core.AnalyticsEvent) string {
+	if path, ok := event.Metadata["path"].(string); ok && path != "" {
+		return path
+	}
+	if raw, ok := event.Metadata["url"].(string); ok {
+		if parsed, err := url.Parse(raw); err == nil {
+			return parsed.Path
+		}
+	}
+	return ""
+}
+
+// metadataNumber accepts the numeric types stored by the tracker as well as the
+// float64 produced when events are decoded from JSON.
+func metadataNumber(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case float64:
+		return number, true
+	case float32:
+		return float64(number), true
+	case int:
+		return float64(number), true
+	case int64:
+		return float64(number), true
+	case int32:
+		return float64(number), true
+	}
+	return 0, false
+}
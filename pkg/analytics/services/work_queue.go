@@ -0,0 +1,103 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cherry-pick/pkg/analytics/core"
+)
+
+// WorkQueue runs jobs on a fixed set of workers. Submit never blocks: when the queue is
+// full the job is dropped and counted, so ingest latency is unaffected by slow jobs.
+type WorkQueue struct {
+	config    core.WorkQueueConfig
+	jobs      chan func() error
+	wg        sync.WaitGroup
+	mu        sync.RWMutex
+	closed    bool
+	active    int64
+	processed int64
+	failed    int64
+	dropped   int64
+}
+
+func NewWorkQueue(config core.WorkQueueConfig) (*WorkQueue, error) {
+	if config.Workers <= 0 {
+		return nil, fmt.Errorf("work queue needs at least one worker")
+	}
+	if config.QueueSize < 0 {
+		return nil, fmt.Errorf("work queue size cannot be negative")
+	}
+
+	wq := &WorkQueue{
+		config: config,
+		jobs:   make(chan func() error, config.QueueSize),
+	}
+	wq.wg.Add(config.Workers)
+	for i := 0; i < config.Workers; i++ {
+		go wq.work()
+	}
+	return wq, nil
+}
+
+func (wq *WorkQueue) work() {
+	defer wq.wg.Done()
+
+	for job := range wq.jobs {
+		atomic.AddInt64(&wq.active, 1)
+		err := job()
+		atomic.AddInt64(&wq.active, -1)
+
+		if err != nil {
+			atomic.AddInt64(&wq.failed, 1)
+		} else {
+			atomic.AddInt64(&wq.processed, 1)
+		}
+	}
+}
+
+// Submit queues job and reports whether it was accepted.
+func (wq *WorkQueue) Submit(job func() error) bool {
+	wq.mu.RLock()
+	defer wq.mu.RUnlock()
+
+	if wq.closed {
+		atomic.AddInt64(&wq.dropped, 1)
+		return false
+	}
+
+	select {
+	case wq.jobs <- job:
+		return true
+	default:
+		atomic.AddInt64(&wq.dropped, 1)
+		return false
+	}
+}
+
+// Stop rejects new jobs and waits for the workers to drain those already queued.
+func (wq *WorkQueue) Stop() {
+	wq.mu.Lock()
+	if wq.closed {
+		wq.mu.Unlock()
+		return
+	}
+	wq.closed = true
+	close(wq.jobs)
+	wq.mu.Unlock()
+
+	wq.wg.Wait()
+}
+
+func (wq *WorkQueue) Stats() core.WorkQueueStats {
+	return core.WorkQueueStats{
+		Workers:   wq.config.Workers,
+		Capacity:  wq.config.QueueSize,
+		Queued:    len(wq.jobs),
+		Active:    atomic.LoadInt64(&wq.active),
+		Processed: atomic.LoadInt64(&wq.processed),
+		Failed:    atomic.LoadInt64(&wq.failed),
+		Dropped:   atomic.LoadInt64(&wq.dropped),
+	}
+}
@@ -0,0 +1,56 @@
+package storage
+
+import "github.com/cherry-pick/pkg/analytics/core"
+
+// The clone helpers give stored records their own maps and slices, both when they are
+// saved and when they are read back. Callers can then modify or keep iterating what they
+// got without racing with later writes or reaching into the store. Metadata is copied
+// one level deep; nested values are shared.
+
+func cloneMetadata(metadata map[string]interface{}) map[string]interface{} {
+	if metadata == nil {
+		return nil
+	}
+	cloned := make(map[string]interface{}, len(metadata))
+	for key, value := range metadata {
+		cloned[key] = value
+	}
+	return cloned
+}
+
+func cloneStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append([]string(nil), values...)
+}
+
+func cloneEvent(event core.AnalyticsEvent) core.AnalyticsEvent {
+	event.Metadata = cloneMetadata(event.Metadata)
+	return event
+}
+
+func cloneSession(session core.UserSession) core.UserSession {
+	if session.EndTime != nil {
+		endTime := *session.EndTime
+		session.EndTime = &endTime
+	}
+	session.Metadata = cloneMetadata(session.Metadata)
+	return session
+}
+
+func cloneJourney(journey core.UserJourney) core.UserJourney {
+	if journey.EndTime != nil {
+		endTime := *journey.EndTime
+		journey.EndTime = &endTime
+	}
+	journey.CompletedGoals = cloneStrings(journey.CompletedGoals)
+	journey.JourneyPath = cloneStrings(journey.JourneyPath)
+	return journey
+}
+
+func cloneInsight(insight core.AnalyticsInsight) core.AnalyticsInsight {
+	insight.Data = cloneMetadata(insight.Data)
+	insight.Recommendations = cloneStrings(insight.Recommendations)
+	return insight
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -15,6 +16,7 @@ type MemoryStorage struct {
 	insights   map[string]core.AnalyticsInsight
 	alerts     map[string]core.AnalyticsAlert
 	reports    map[string]core.AnalyticsReport
+	funnels    map[string]core.FunnelDefinition
 	mu         sync.RWMutex
 }
 
@@ -26,13 +28,14 @@ func NewMemoryStorage() *MemoryStorage {
 		insights: make(map[string]core.AnalyticsInsight),
 		alerts:   make(map[string]core.AnalyticsAlert),
 		reports:  make(map[string]core.AnalyticsReport),
+		funnels:  make(map[string]core.FunnelDefinition),
 	}
 }
 
 func (ms *MemoryStorage) SaveEvent(event core.AnalyticsEvent) error {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
-	ms.events[event.ID] = event
+	ms.events[event.ID] = cloneEvent(event)
 	return nil
 }
 
@@ -42,22 +45,14 @@ func (ms *MemoryStorage) GetEvents(request core.AnalyticsRequest) ([]core.Analyt
 	var events []core.AnalyticsEvent
 	for _, event := range ms.events {
 		if ms.matchesEventFilters(event, request) {
-			events = append(events, event)
+			events = append(events, cloneEvent(event))
 		}
 	}
-	if request.Limit > 0 {
-		start := request.Offset
-		end := start + request.Limit
-		if end > len(events) {
-			end = len(events)
-		}
-		if start < len(events) {
-			events = events[start:end]
-		} else {
-			events = []core.AnalyticsEvent{}
-		}
-	}
-	return events, nil
+	sort.Slice(events, func(i, j int) bool {
+		return orderedBefore(events[i].Timestamp, events[j].Timestamp, events[i].ID, events[j].ID)
+	})
+	start, end := pageBounds(len(events), request)
+	return events[start:end], nil
 }
 
 func (ms *MemoryStorage) DeleteEvent(eventID string) error {
@@ -73,7 +68,7 @@ func (ms *MemoryStorage) DeleteEvent(eventID string) error {
 func (ms *MemoryStorage) SaveSession(session core.UserSession) error {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
-	ms.sessions[session.SessionID] = session
+	ms.sessions[session.SessionID] = cloneSession(session)
 	return nil
 }
 
@@ -84,6 +79,7 @@ func (ms *MemoryStorage) GetSession(sessionID string) (*core.UserSession, error)
 	if !exists {
 		return nil, fmt.Errorf("session with ID %s not found", sessionID)
 	}
+	session = cloneSession(session)
 	return &session, nil
 }
 
@@ -93,10 +89,14 @@ func (ms *MemoryStorage) GetSessions(request core.AnalyticsRequest) ([]core.User
 	var sessions []core.UserSession
 	for _, session := range ms.sessions {
 		if ms.matchesSessionFilters(session, request) {
-			sessions = append(sessions, session)
+			sessions = append(sessions, cloneSession(session))
 		}
 	}
-	return sessions, nil
+	sort.Slice(sessions, func(i, j int) bool {
+		return orderedBefore(sessions[i].StartTime, sessions[j].StartTime, sessions[i].SessionID, sessions[j].SessionID)
+	})
+	start, end := pageBounds(len(sessions), request)
+	return sessions[start:end], nil
 }
 
 func (ms *MemoryStorage) UpdateSession(session core.UserSession) error {
@@ -105,7 +105,7 @@ func (ms *MemoryStorage) UpdateSession(session core.UserSession) error {
 	if _, exists := ms.sessions[session.SessionID]; !exists {
 		return fmt.Errorf("session with ID %s not found", session.SessionID)
 	}
-	ms.sessions[session.SessionID] = session
+	ms.sessions[session.SessionID] = cloneSession(session)
 	return nil
 }
 
@@ -122,7 +122,7 @@ func (ms *MemoryStorage) DeleteSession(sessionID string) error {
 func (ms *MemoryStorage) SaveJourney(journey core.UserJourney) error {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
-	ms.journeys[journey.SessionID] = journey
+	ms.journeys[journey.SessionID] = cloneJourney(journey)
 	return nil
 }
 
@@ -133,6 +133,7 @@ func (ms *MemoryStorage) GetJourney(sessionID string) (*core.UserJourney, error)
 	if !exists {
 		return nil, fmt.Errorf("journey with session ID %s not found", sessionID)
 	}
+	journey = cloneJourney(journey)
 	return &journey, nil
 }
 
@@ -142,10 +143,14 @@ func (ms *MemoryStorage) GetJourneys(request core.AnalyticsRequest) ([]core.User
 	var journeys []core.UserJourney
 	for _, journey := range ms.journeys {
 		if ms.matchesJourneyFilters(journey, request) {
-			journeys = append(journeys, journey)
+			journeys = append(journeys, cloneJourney(journey))
 		}
 	}
-	return journeys, nil
+	sort.Slice(journeys, func(i, j int) bool {
+		return orderedBefore(journeys[i].StartTime, journeys[j].StartTime, journeys[i].SessionID, journeys[j].SessionID)
+	})
+	start, end := pageBounds(len(journeys), request)
+	return journeys[start:end], nil
 }
 
 func (ms *MemoryStorage) UpdateJourney(journey core.UserJourney) error {
@@ -154,7 +159,7 @@ func (ms *MemoryStorage) UpdateJourney(journey core.UserJourney) error {
 	if _, exists := ms.journeys[journey.SessionID]; !exists {
 		return fmt.Errorf("journey with session ID %s not found", journey.SessionID)
 	}
-	ms.journeys[journey.SessionID] = journey
+	ms.journeys[journey.SessionID] = cloneJourney(journey)
 	return nil
 }
 
@@ -171,7 +176,7 @@ func (ms *MemoryStorage) DeleteJourney(sessionID string) error {
 func (ms *MemoryStorage) SaveInsight(insight core.AnalyticsInsight) error {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
-	ms.insights[insight.ID] = insight
+	ms.insights[insight.ID] = cloneInsight(insight)
 	return nil
 }
 
@@ -182,6 +187,7 @@ func (ms *MemoryStorage) GetInsight(insightID string) (*core.AnalyticsInsight, e
 	if !exists {
 		return nil, fmt.Errorf("insight with ID %s not found", insightID)
 	}
+	insight = cloneInsight(insight)
 	return &insight, nil
 }
 
@@ -191,10 +197,14 @@ func (ms *MemoryStorage) GetInsights(request core.AnalyticsRequest) ([]core.Anal
 	var insights []core.AnalyticsInsight
 	for _, insight := range ms.insights {
 		if ms.matchesInsightFilters(insight, request) {
-			insights = append(insights, insight)
+			insights = append(insights, cloneInsight(insight))
 		}
 	}
-	return insights, nil
+	sort.Slice(insights, func(i, j int) bool {
+		return orderedBefore(insights[i].Timestamp, insights[j].Timestamp, insights[i].ID, insights[j].ID)
+	})
+	start, end := pageBounds(len(insights), request)
+	return insights[start:end], nil
 }
 
 func (ms *MemoryStorage) UpdateInsight(insight core.AnalyticsInsight) error {
@@ -203,7 +213,7 @@ func (ms *MemoryStorage) UpdateInsight(insight core.AnalyticsInsight) error {
 	if _, exists := ms.insights[insight.ID]; !exists {
 		return fmt.Errorf("insight with ID %s not found", insight.ID)
 	}
-	ms.insights[insight.ID] = insight
+	ms.insights[insight.ID] = cloneInsight(insight)
 	return nil
 }
 
@@ -243,7 +253,11 @@ func (ms *MemoryStorage) GetAlerts(request core.AnalyticsRequest) ([]core.Analyt
 			alerts = append(alerts, alert)
 		}
 	}
-	return alerts, nil
+	sort.Slice(alerts, func(i, j int) bool {
+		return orderedBefore(alerts[i].Timestamp, alerts[j].Timestamp, alerts[i].ID, alerts[j].ID)
+	})
+	start, end := pageBounds(len(alerts), request)
+	return alerts[start:end], nil
 }
 
 func (ms *MemoryStorage) UpdateAlert(alert core.AnalyticsAlert) error {
@@ -305,6 +319,59 @@ func (ms *MemoryStorage) DeleteReport(reportID string) error {
 	return nil
 }
 
+func (ms *MemoryStorage) SaveFunnel(funnel core.FunnelDefinition) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if _, exists := ms.funnels[funnel.ID]; exists {
+		return fmt.Errorf("funnel with ID %s already exists", funnel.ID)
+	}
+	ms.funnels[funnel.ID] = funnel
+	return nil
+}
+
+func (ms *MemoryStorage) GetFunnel(funnelID string) (*core.FunnelDefinition, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	funnel, exists := ms.funnels[funnelID]
+	if !exists {
+		return nil, fmt.Errorf("funnel with ID %s not found", funnelID)
+	}
+	return &funnel, nil
+}
+
+func (ms *MemoryStorage) GetFunnels() ([]core.FunnelDefinition, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	funnels := make([]core.FunnelDefinition, 0, len(ms.funnels))
+	for _, funnel := range ms.funnels {
+		funnels = append(funnels, funnel)
+	}
+	sort.Slice(funnels, func(i, j int) bool {
+		return funnels[i].CreatedAt.Before(funnels[j].CreatedAt)
+	})
+	return funnels, nil
+}
+
+func (ms *MemoryStorage) UpdateFunnel(funnel core.FunnelDefinition) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if _, exists := ms.funnels[funnel.ID]; !exists {
+		return fmt.Errorf("funnel with ID %s not found", funnel.ID)
+	}
+	ms.funnels[funnel.ID] = funnel
+	return nil
+}
+
+func (ms *MemoryStorage) DeleteFunnel(funnelID string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if _, exists := ms.funnels[funnelID]; !exists {
+		return fmt.Errorf("funnel with ID %s not found", funnelID)
+	}
+	delete(ms.funnels, funnelID)
+	return nil
+}
+
 func (ms *MemoryStorage) CleanupOldData(olderThan time.Time) error {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
@@ -331,6 +398,64 @@ func (ms *MemoryStorage) CleanupOldData(olderThan time.Time) error {
 	return nil
 }
 
+// CleanupExpired purges each record type against its own cutoff and reports how many
+// records of each type were removed.
+func (ms *MemoryStorage) CleanupExpired(cutoffs core.RetentionCutoffs) (map[string]int, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	purged := map[string]int{}
+	if !cutoffs.Events.IsZero() {
+		for id, event := range ms.events {
+			if event.Timestamp.Before(cutoffs.Events) {
+				delete(ms.events, id)
+				purged["events"]++
+			}
+		}
+	}
+	if !cutoffs.Sessions.IsZero() {
+		for id, session := range ms.sessions {
+			if session.StartTime.Before(cutoffs.Sessions) {
+				delete(ms.sessions, id)
+				purged["sessions"]++
+			}
+		}
+	}
+	if !cutoffs.Journeys.IsZero() {
+		for id, journey := range ms.journeys {
+			if journey.StartTime.Before(cutoffs.Journeys) {
+				delete(ms.journeys, id)
+				purged["journeys"]++
+			}
+		}
+	}
+	if !cutoffs.Insights.IsZero() {
+		for id, insight := range ms.insights {
+			if insight.Timestamp.Before(cutoffs.Insights) {
+				delete(ms.insights, id)
+				purged["insights"]++
+			}
+		}
+	}
+	if !cutoffs.Alerts.IsZero() {
+		for id, alert := range ms.alerts {
+			if alert.Timestamp.Before(cutoffs.Alerts) {
+				delete(ms.alerts, id)
+				purged["alerts"]++
+			}
+		}
+	}
+	if !cutoffs.Reports.IsZero() {
+		for id, report := range ms.reports {
+			if report.GeneratedAt.Before(cutoffs.Reports) {
+				delete(ms.reports, id)
+				purged["reports"]++
+			}
+		}
+	}
+	return purged, nil
+}
+
 func (ms *MemoryStorage) GetStats() (map[string]interface{}, error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
@@ -341,10 +466,37 @@ func (ms *MemoryStorage) GetStats() (map[string]interface{}, error) {
 		"total_insights": len(ms.insights),
 		"total_alerts":   len(ms.alerts),
 		"total_reports":  len(ms.reports),
+		"total_funnels":  len(ms.funnels),
 	}
 	return stats, nil
 }
 
+// orderedBefore orders records oldest first, breaking ties by ID so that every call
+// returns matches in the same order and offset pagination is stable.
+func orderedBefore(ti, tj time.Time, idi, idj string) bool {
+	if !ti.Equal(tj) {
+		return ti.Before(tj)
+	}
+	return idi < idj
+}
+
+// pageBounds returns the slice bounds of the page request selects from total ordered
+// matches. A Limit of zero or less means no limit.
+func pageBounds(total int, request core.AnalyticsRequest) (int, int) {
+	start := request.Offset
+	if start < 0 {
+		start = 0
+	}
+	if start > total {
+		start = total
+	}
+	end := total
+	if request.Limit > 0 && start+request.Limit < total {
+		end = start + request.Limit
+	}
+	return start, end
+}
+
 func (ms *MemoryStorage) matchesEventFilters(event core.AnalyticsEvent, request core.AnalyticsRequest) bool {
 	if request.StartTime != nil && event.Timestamp.Before(*request.StartTime) {
 		return false
@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cherry-pick/pkg/analytics/core"
+)
+
+func TestMemoryStorageConcurrentReadersAndWriters(t *testing.T) {
+	const goroutines, iterations = 8, 200
+	ms := NewMemoryStorage()
+
+	ms.SaveSession(core.UserSession{SessionID: "s1", StartTime: time.Now(), Metadata: map[string]interface{}{"n": 0}})
+	ms.SaveJourney(core.UserJourney{SessionID: "s1", StartTime: time.Now(), JourneyPath: []string{"/"}})
+	ms.SaveInsight(core.AnalyticsInsight{ID: "i1", Data: map[string]interface{}{"n": 0}, Recommendations: []string{"r"}})
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(2)
+
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				ms.UpdateSession(core.UserSession{SessionID: "s1", StartTime: time.Now(), Metadata: map[string]interface{}{"n": i}})
+				ms.UpdateJourney(core.UserJourney{SessionID: "s1", StartTime: time.Now(), JourneyPath: []string{"/", fmt.Sprintf("/%d", i)}})
+				ms.SaveEvent(core.AnalyticsEvent{ID: fmt.Sprintf("e-%d-%d", g, i), Timestamp: time.Now(), Metadata: map[string]interface{}{"g": g}})
+			}
+		}(g)
+
+		// Readers modify what they get back, which races with the writers unless the
+		// store hands out copies.
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				if session, err := ms.GetSession("s1"); err == nil {
+					session.Metadata["reader"] = i
+				}
+				if sessions, err := ms.GetSessions(core.AnalyticsRequest{}); err == nil {
+					for _, session := range sessions {
+						session.Metadata["reader"] = i
+					}
+				}
+				if journey, err := ms.GetJourney("s1"); err == nil {
+					journey.JourneyPath[0] = "/changed"
+				}
+				if insights, err := ms.GetInsights(core.AnalyticsRequest{}); err == nil {
+					for _, insight := range insights {
+						insight.Data["reader"] = i
+						insight.Recommendations[0] = "changed"
+					}
+				}
+				if events, err := ms.GetEvents(core.AnalyticsRequest{Limit: 10}); err == nil {
+					for _, event := range events {
+						event.Metadata["reader"] = i
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	events, err := ms.GetEvents(core.AnalyticsRequest{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(events) != goroutines*iterations {
+		t.Errorf("Expected %d events, got %d", goroutines*iterations, len(events))
+	}
+}
+
+func TestMemoryStorageReturnsCopies(t *testing.T) {
+	ms := NewMemoryStorage()
+
+	metadata := map[string]interface{}{"plan": "free"}
+	ms.SaveSession(core.UserSession{SessionID: "s1", StartTime: time.Now(), Metadata: metadata})
+	metadata["plan"] = "changed after save"
+
+	session, err := ms.GetSession("s1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if session.Metadata["plan"] != "free" {
+		t.Errorf("Expected the saved metadata to be copied, got %v", session.Metadata["plan"])
+	}
+
+	session.Metadata["plan"] = "changed after get"
+	again, _ := ms.GetSession("s1")
+	if again.Metadata["plan"] != "free" {
+		t.Errorf("Expected the returned metadata to be a copy, got %v", again.Metadata["plan"])
+	}
+
+	ms.SaveJourney(core.UserJourney{SessionID: "s1", JourneyPath: []string{"/"}})
+	journey, _ := ms.GetJourney("s1")
+	journey.JourneyPath[0] = "/changed"
+	if again, _ := ms.GetJourney("s1"); again.JourneyPath[0] != "/" {
+		t.Errorf("Expected the returned journey path to be a copy, got %v", again.JourneyPath)
+	}
+}
+
+func TestGetEventsPagesCoverEveryEventOnce(t *testing.T) {
+	ms := NewMemoryStorage()
+
+	// Several events share a timestamp, so the order has to fall back on the ID.
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	const total = 23
+	for i := 0; i < total; i++ {
+		ms.SaveEvent(core.AnalyticsEvent{
+			ID:        fmt.Sprintf("e%02d", i),
+			Timestamp: base.Add(time.Duration(i/3) * time.Second),
+		})
+	}
+
+	for pass := 0; pass < 2; pass++ {
+		seen := make(map[string]bool)
+		var previous *core.AnalyticsEvent
+
+		for offset := 0; ; offset += 5 {
+			page, err := ms.GetEvents(core.AnalyticsRequest{Limit: 5, Offset: offset})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(page) == 0 {
+				break
+			}
+
+			for i := range page {
+				event := page[i]
+				if seen[event.ID] {
+					t.Errorf("Pass %d: expected pages not to overlap, got %s twice", pass, event.ID)
+				}
+				seen[event.ID] = true
+
+				if previous != nil && !orderedBefore(previous.Timestamp, event.Timestamp, previous.ID, event.ID) {
+					t.Errorf("Pass %d: expected %s before %s", pass, previous.ID, event.ID)
+				}
+				previous = &page[i]
+			}
+		}
+
+		if len(seen) != total {
+			t.Errorf("Pass %d: expected %d events across all pages, got %d", pass, total, len(seen))
+		}
+	}
+}
+
+func TestGetEventsOffsetPastEnd(t *testing.T) {
+	ms := NewMemoryStorage()
+	ms.SaveEvent(core.AnalyticsEvent{ID: "e1", Timestamp: time.Now()})
+
+	events, err := ms.GetEvents(core.AnalyticsRequest{Limit: 10, Offset: 5})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("Expected an empty page, got %d events", len(events))
+	}
+}
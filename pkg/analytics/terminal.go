@@ -0,0 +1,34 @@
+package analytics
+
+import (
+	"os"
+	"strconv"
+)
+
+// TrackerOptions controls optional tracker behaviour. The terminal UI writes ANSI escapes
+// straight to stdout, so it is only enabled by default when stdout is a terminal.
+type TrackerOptions struct {
+	TerminalUI bool
+}
+
+// DefaultTrackerOptions enables the terminal UI only for interactive sessions. Setting
+// ANALYTICS_TERMINAL_UI to true or false overrides the detection.
+func DefaultTrackerOptions() TrackerOptions {
+	options := TrackerOptions{TerminalUI: IsTerminal(os.Stdout)}
+	if enabled, err := strconv.ParseBool(os.Getenv("ANALYTICS_TERMINAL_UI")); err == nil {
+		options.TerminalUI = enabled
+	}
+	return options
+}
+
+// IsTerminal reports whether f is attached to a character device such as a TTY.
+func IsTerminal(f *os.File) bool {
+	if f == nil {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,168 @@
+package analytics
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cherry-pick/pkg/analytics/core"
+	"github.com/cherry-pick/pkg/analytics/services"
+)
+
+const DefaultMaxWorkspaces = 1000
+
+// Workspaces keeps one Analytics instance per workspace, each with its own storage,
+// so one workspace's events, sessions and reports are never visible to another.
+type Workspaces struct {
+	mu            sync.Mutex
+	instances     map[string]*Analytics
+	maxWorkspaces int
+	sinks         []core.ActivitySink
+	scoring       *core.PerformanceScoreConfig
+	window        time.Duration
+}
+
+// NewWorkspaces holds the instances created by Add. At most maxWorkspaces are held,
+// since each runs its own background workers.
+func NewWorkspaces(maxWorkspaces int) *Workspaces {
+	if maxWorkspaces <= 0 {
+		maxWorkspaces = DefaultMaxWorkspaces
+	}
+
+	return &Workspaces{
+		instances:     make(map[string]*Analytics),
+		maxWorkspaces: maxWorkspaces,
+	}
+}
+
+// AddSink shares sink between all workspaces created afterwards, with each record
+// stamped with its workspace ID.
+func (w *Workspaces) AddSink(sink core.ActivitySink) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.sinks = append(w.sinks, sink)
+}
+
+// AddFileSink writes every workspace's page views and insights to one rotating file.
+func (w *Workspaces) AddFileSink(config core.FileSinkConfig) error {
+	sink, err := services.NewFileSink(config)
+	if err != nil {
+		return err
+	}
+	w.AddSink(sink)
+	return nil
+}
+
+// SetPerformanceScoreConfig applies config to every workspace, including those created
+// afterwards.
+func (w *Workspaces) SetPerformanceScoreConfig(config core.PerformanceScoreConfig) error {
+	if err := services.ValidatePerformanceScoreConfig(config); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.scoring = &config
+	for _, instance := range w.instances {
+		if err := instance.SetPerformanceScoreConfig(config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetRealTimeWindow applies window to every workspace, including those created afterwards.
+func (w *Workspaces) SetRealTimeWindow(window time.Duration) error {
+	if window <= 0 {
+		return fmt.Errorf("real-time window must be positive")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.window = window
+	for _, instance := range w.instances {
+		if err := instance.SetRealTimeWindow(window); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get returns the instance of a workspace created by Add. Lookups never create one, so
+// arbitrary workspace IDs cannot use up the workspace limit.
+func (w *Workspaces) Get(workspaceID string) (*Analytics, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	instance, exists := w.instances[workspaceID]
+	if !exists {
+		return nil, fmt.Errorf("workspace %s not found", workspaceID)
+	}
+	return instance, nil
+}
+
+// Add creates the instance of a workspace, applying the sinks and settings configured
+// so far. Adding an existing workspace returns its instance.
+func (w *Workspaces) Add(workspaceID string) (*Analytics, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if instance, exists := w.instances[workspaceID]; exists {
+		return instance, nil
+	}
+	if len(w.instances) >= w.maxWorkspaces {
+		return nil, fmt.Errorf("workspace limit of %d reached", w.maxWorkspaces)
+	}
+
+	instance := NewAnalytics()
+	for _, sink := range w.sinks {
+		instance.AddSink(&workspaceSink{workspaceID: workspaceID, sink: sink})
+	}
+	if w.scoring != nil {
+		if err := instance.SetPerformanceScoreConfig(*w.scoring); err != nil {
+			instance.Close()
+			return nil, err
+		}
+	}
+	if w.window > 0 {
+		if err := instance.SetRealTimeWindow(w.window); err != nil {
+			instance.Close()
+			return nil, err
+		}
+	}
+	w.instances[workspaceID] = instance
+	return instance, nil
+}
+
+func (w *Workspaces) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for workspaceID, instance := range w.instances {
+		instance.Close()
+		delete(w.instances, workspaceID)
+	}
+	for _, sink := range w.sinks {
+		sink.Close()
+	}
+	w.sinks = nil
+}
+
+// workspaceSink stamps records with their workspace. Closing it leaves the shared sink
+// open, since Workspaces closes that once every instance is done with it.
+type workspaceSink struct {
+	workspaceID string
+	sink        core.ActivitySink
+}
+
+func (ws *workspaceSink) WriteRecord(record core.ActivityRecord) error {
+	record.WorkspaceID = ws.workspaceID
+	return ws.sink.WriteRecord(record)
+}
+
+func (ws *workspaceSink) Close() error {
+	return nil
+}
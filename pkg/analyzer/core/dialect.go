@@ -0,0 +1,46 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Dialect supplies the catalog queries the SQL analyzer runs against one kind of
+// database. Dialects are registered by database type, so engines beyond the built-in
+// MySQL, PostgreSQL and SQLite ones can be analyzed without changing the analyzer.
+//
+// An empty schema means the connection's default schema. Errors from the per-table
+// methods are logged by the analyzer and leave that part of the table out.
+type Dialect interface {
+	// TableNames lists the tables of the default schema, or, when schemas is not
+	// empty, the tables of those schemas qualified as schema.table.
+	TableNames(ctx context.Context, db *sql.DB, schemas []string) ([]string, error)
+	Columns(ctx context.Context, db *sql.DB, schema, table string) ([]ColumnInfo, error)
+	Indexes(ctx context.Context, db *sql.DB, schema, table string) ([]IndexInfo, error)
+	Constraints(ctx context.Context, db *sql.DB, schema, table string) ([]Constraint, error)
+	Relationships(ctx context.Context, db *sql.DB, schema, table string) ([]Relationship, error)
+	// TableSize is the table's size formatted for display, such as "12.50 MB".
+	TableSize(ctx context.Context, db *sql.DB, schema, table string) (string, error)
+	PerformanceMetrics(ctx context.Context, db *sql.DB) (*PerformanceMetrics, error)
+}
+
+// TableCommentDialect is implemented by dialects whose catalog stores table comments.
+type TableCommentDialect interface {
+	TableComment(ctx context.Context, db *sql.DB, schema, table string) (string, error)
+}
+
+// NumericSampleDialect is implemented by dialects that can aggregate a random sample of
+// a column. Large tables on dialects without it are aggregated in full rather than with
+// sampling syntax the database may not understand.
+type NumericSampleDialect interface {
+	// SampledNumericAggregates returns MIN, MAX and AVG over about sampleRows of the
+	// column's non-null values. table is already qualified with its schema.
+	SampledNumericAggregates(ctx context.Context, db *sql.DB, table, column string, rowCount int64, sampleRows int) (min, max, avg sql.NullFloat64, err error)
+}
+
+// JSONKeysDialect is implemented by dialects with a native function listing the
+// top-level keys of JSON documents. Other dialects infer keys from sampled documents.
+type JSONKeysDialect interface {
+	// JSONKeys returns the distinct top-level keys of up to limit object documents.
+	JSONKeys(ctx context.Context, db *sql.DB, table string, column ColumnInfo, limit int) ([]string, error)
+}
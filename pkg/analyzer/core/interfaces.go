@@ -41,6 +41,7 @@ type DatabaseConnector interface {
 	IsConnected() bool
 	GetDatabaseName() string
 	GetDatabaseType() DatabaseType
+	GetDatabase() interface{}
 	TestConnection(ctx context.Context) error
 }
 
@@ -0,0 +1,42 @@
+package core
+
+import "fmt"
+
+type AnalysisPreset string
+
+const (
+	PresetQuick    AnalysisPreset = "quick"
+	PresetStandard AnalysisPreset = "standard"
+	PresetDeep     AnalysisPreset = "deep"
+)
+
+// PresetOptions returns the option flags a preset expands to. Callers layer explicitly
+// supplied options on top, so a preset only fills in what the request left unset.
+func PresetOptions(preset AnalysisPreset) (AnalysisOptions, error) {
+	switch preset {
+	case PresetQuick:
+		return AnalysisOptions{
+			IncludeSchema: true,
+		}, nil
+	case PresetStandard:
+		return AnalysisOptions{
+			IncludeSchema:    true,
+			IncludeIndexes:   true,
+			IncludeRelations: true,
+		}, nil
+	case PresetDeep:
+		return AnalysisOptions{
+			IncludeSchema:      true,
+			IncludeIndexes:     true,
+			IncludeRelations:   true,
+			IncludeData:        true,
+			IncludePerformance: true,
+		}, nil
+	default:
+		return AnalysisOptions{}, fmt.Errorf("unknown analysis preset: %s", preset)
+	}
+}
+
+func AnalysisPresets() []AnalysisPreset {
+	return []AnalysisPreset{PresetQuick, PresetStandard, PresetDeep}
+}
@@ -11,10 +11,53 @@ const (
 	DatabaseTypeMongoDB  DatabaseType = "mongodb"
 )
 
+type SamplingStrategy string
+
+const (
+	SamplingStrategyFirst  SamplingStrategy = "first"
+	SamplingStrategyRandom SamplingStrategy = "random"
+	SamplingStrategyRecent SamplingStrategy = "recent"
+)
+
+const (
+	DefaultColumnSampleSize     = 10
+	DefaultColumnProfileTimeout = 5 * time.Second
+	DefaultMongoCommandTimeout  = 10 * time.Second
+	// MongoCommandAttempts bounds how often an admin or stats command is sent when the
+	// server cannot be reached or does not answer in time.
+	MongoCommandAttempts = 3
+	LargeTableRowThreshold      = 100000
+	NumericAggregateSampleRows  = 10000
+	MaxJSONSchemaFields         = 200
+	DefaultMaxDocumentDepth     = 20
+	DefaultMaxTrackedFields     = 1000
+	ApproxDistinctScanRows      = 1000000
+	DefaultTopValues            = 5
+	MaxTopValues                = 100
+	// TopValuesMaxCardinality is the most distinct values a column may have for its
+	// top values to be profiled; above it the counts say little and the GROUP BY is costly.
+	TopValuesMaxCardinality = 1000
+)
+
+// DefaultExcludedTables are glob patterns for catalog and bookkeeping tables that are
+// always skipped, in addition to any request.Options.ExcludeTables.
+var DefaultExcludedTables = []string{
+	"sqlite_*",
+	"pg_*",
+	"information_schema*",
+	"performance_schema*",
+	"mysql.*",
+	"sys.*",
+	"system.*",
+}
+
 type AnalysisRequest struct {
 	DatabaseType DatabaseType `json:"databaseType"`
 	ConnectionID string       `json:"connectionId"`
+	Preset       AnalysisPreset  `json:"preset,omitempty"`
 	Options      AnalysisOptions `json:"options"`
+	// WorkspaceID is set by the API from the caller's workspace, never from the body.
+	WorkspaceID string `json:"-"`
 }
 
 type AnalysisOptions struct {
@@ -25,6 +68,34 @@ type AnalysisOptions struct {
 	IncludePerformance bool `json:"includePerformance"`
 	SampleSize        int  `json:"sampleSize"`
 	MaxCollections    int  `json:"maxCollections"`
+	MaxTables         int  `json:"maxTables,omitempty"`
+	SamplingStrategy  SamplingStrategy `json:"samplingStrategy,omitempty"`
+	Refresh           bool             `json:"refresh,omitempty"`
+	ProfileTimeoutSeconds int          `json:"profileTimeoutSeconds,omitempty"`
+	CommandTimeoutSeconds int          `json:"commandTimeoutSeconds,omitempty"`
+	MaxDocumentDepth  int              `json:"maxDocumentDepth,omitempty"`
+	MaxTrackedFields  int              `json:"maxTrackedFields,omitempty"`
+	IncludeTables     []string         `json:"includeTables,omitempty"`
+	ExcludeTables     []string         `json:"excludeTables,omitempty"`
+	ApproximateDistinct bool           `json:"approximateDistinct,omitempty"`
+	Schema            string           `json:"schema,omitempty"`
+	Schemas           []string         `json:"schemas,omitempty"`
+	TopValues         int              `json:"topValues,omitempty"`
+}
+
+// SchemaNames merges Schema and Schemas, dropping repeats. An empty result means the
+// connection's default schema.
+func (o AnalysisOptions) SchemaNames() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, schema := range append([]string{o.Schema}, o.Schemas...) {
+		if schema == "" || seen[schema] {
+			continue
+		}
+		seen[schema] = true
+		names = append(names, schema)
+	}
+	return names
 }
 
 type AnalysisResult struct {
@@ -37,6 +108,24 @@ type AnalysisResult struct {
 	Insights      []DatabaseInsight `json:"insights"`
 	Recommendations []string       `json:"recommendations"`
 	Performance   *PerformanceMetrics `json:"performance,omitempty"`
+	Warnings      []TableWarning  `json:"warnings,omitempty"`
+	Truncation    *ObjectTruncation `json:"truncation,omitempty"`
+	WorkspaceID   string          `json:"workspaceId,omitempty"`
+}
+
+// ObjectTruncation records that MaxTables or MaxCollections cut the list of tables or
+// collections to analyze; the skipped objects are not in the result.
+type ObjectTruncation struct {
+	Limit   int `json:"limit"`
+	Total   int `json:"total"`
+	Skipped int `json:"skipped"`
+}
+
+// TableWarning records a table or collection that could not be analyzed and was left
+// out of an otherwise successful analysis.
+type TableWarning struct {
+	Table string `json:"table"`
+	Error string `json:"error"`
 }
 
 type DatabaseSummary struct {
@@ -46,10 +135,12 @@ type DatabaseSummary struct {
 	TotalSize       string  `json:"totalSize"`
 	HealthScore     float64 `json:"healthScore"`
 	ComplexityScore float64 `json:"complexityScore"`
+	FailedTables    int     `json:"failedTables"`
 }
 
 type TableInfo struct {
 	Name         string        `json:"name"`
+	Comment      string        `json:"comment,omitempty"`
 	RowCount     int64         `json:"rowCount"`
 	Size         string        `json:"size"`
 	LastModified time.Time     `json:"lastModified"`
@@ -62,6 +153,7 @@ type TableInfo struct {
 type ColumnInfo struct {
 	Name         string      `json:"name"`
 	DataType     string      `json:"dataType"`
+	Comment      string      `json:"comment,omitempty"`
 	IsNullable   bool        `json:"isNullable"`
 	IsPrimaryKey bool        `json:"isPrimaryKey"`
 	DefaultValue string      `json:"defaultValue,omitempty"`
@@ -70,16 +162,40 @@ type ColumnInfo struct {
 	Scale        int         `json:"scale,omitempty"`
 	DataProfile  DataProfile `json:"dataProfile"`
 	UniqueValues int64       `json:"uniqueValues"`
+	UniqueValuesApproximate bool `json:"uniqueValuesApproximate,omitempty"`
 	NullCount    int64       `json:"nullCount"`
+	JSONSchema   *JSONSchemaSummary `json:"jsonSchema,omitempty"`
+}
+
+type JSONSchemaSummary struct {
+	SampledDocuments int             `json:"sampledDocuments"`
+	TopLevelKeys     []string        `json:"topLevelKeys"`
+	KeySource        string          `json:"keySource"`
+	Fields           []JSONFieldInfo `json:"fields"`
+	Truncated        bool            `json:"truncated,omitempty"`
+}
+
+type JSONFieldInfo struct {
+	Path      string   `json:"path"`
+	Types     []string `json:"types"`
+	Frequency float64  `json:"frequency"`
 }
 
 type DataProfile struct {
-	SampleData []string  `json:"sampleData,omitempty"`
-	Min        float64   `json:"min,omitempty"`
-	Max        float64   `json:"max,omitempty"`
-	Avg        float64   `json:"avg,omitempty"`
-	Pattern    string    `json:"pattern,omitempty"`
-	Quality    float64   `json:"quality"`
+	SampleData []string     `json:"sampleData,omitempty"`
+	Min        float64      `json:"min,omitempty"`
+	Max        float64      `json:"max,omitempty"`
+	Avg        float64      `json:"avg,omitempty"`
+	Pattern    string       `json:"pattern,omitempty"`
+	Quality    float64      `json:"quality"`
+	Sampled    bool         `json:"sampled,omitempty"`
+	TopValues  []ValueCount `json:"topValues,omitempty"`
+}
+
+// ValueCount is how many rows hold one value of a column.
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
 }
 
 type IndexInfo struct {
@@ -87,6 +203,7 @@ type IndexInfo struct {
 	Type     string   `json:"type"`
 	IsUnique bool     `json:"isUnique"`
 	Columns  []string `json:"columns"`
+	Size     string   `json:"size,omitempty"`
 }
 
 type Constraint struct {
@@ -159,6 +276,17 @@ type MongoCollectionInfo struct {
 	Indexes        []MongoIndexInfo       `json:"indexes"`
 	SampleDocument map[string]interface{} `json:"sampleDocument,omitempty"`
 	IsSharded      bool                   `json:"isSharded"`
+	ShardKey       map[string]interface{} `json:"shardKey,omitempty"`
+	SchemaTruncation *SchemaTruncation    `json:"schemaTruncation,omitempty"`
+}
+
+// SchemaTruncation records why schema inference stopped early; fields past the limits are not reported.
+type SchemaTruncation struct {
+	DepthLimitHit  bool `json:"depthLimitHit"`
+	FieldLimitHit  bool `json:"fieldLimitHit"`
+	MaxDepth       int  `json:"maxDepth"`
+	MaxFields      int  `json:"maxFields"`
+	SkippedFields  int  `json:"skippedFields"`
 }
 
 type MongoFieldInfo struct {
@@ -191,4 +319,17 @@ type MongoDatabaseStats struct {
 	StorageSize int64  `json:"storageSize"`
 	IndexSize   int64  `json:"indexSize"`
 	TotalSize   int64  `json:"totalSize"`
+	Topology    *MongoTopology `json:"topology,omitempty"`
+}
+
+type MongoTopology struct {
+	Kind       string               `json:"kind"`
+	ReplicaSet string               `json:"replicaSet,omitempty"`
+	Members    []MongoReplicaMember `json:"members,omitempty"`
+}
+
+type MongoReplicaMember struct {
+	Host    string `json:"host"`
+	Role    string `json:"role"`
+	Healthy bool   `json:"healthy"`
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/cherry-pick/pkg/analyzer/core"
+	"github.com/cherry-pick/pkg/tracing"
 )
 
 type AnalyzerService struct {
@@ -36,6 +37,22 @@ func NewAnalyzerService(
 }
 
 func (as *AnalyzerService) AnalyzeDatabase(ctx context.Context, request core.AnalysisRequest) (*core.AnalysisResult, error) {
+	ctx, span := tracing.Start(ctx, "analyzer.AnalyzeDatabase",
+		tracing.AttrDatabaseType.String(string(request.DatabaseType)))
+
+	result, err := as.analyzeDatabase(ctx, request)
+	if result != nil {
+		span.SetAttributes(
+			tracing.AttrDatabaseName.String(result.DatabaseName),
+			tracing.AttrTableCount.Int(len(result.Tables)),
+		)
+	}
+	tracing.End(span, err)
+
+	return result, err
+}
+
+func (as *AnalyzerService) analyzeDatabase(ctx context.Context, request core.AnalysisRequest) (*core.AnalysisResult, error) {
 	if err := as.validator.ValidateRequest(request); err != nil {
 		return nil, fmt.Errorf("invalid analysis request: %w", err)
 	}
@@ -54,6 +71,7 @@ func (as *AnalyzerService) AnalyzeDatabase(ctx context.Context, request core.Ana
 		as.notifier.NotifyAnalysisError(ctx, err)
 		return nil, fmt.Errorf("analysis failed: %w", err)
 	}
+	result.WorkspaceID = request.WorkspaceID
 
 	if err := as.storage.SaveAnalysis(ctx, result); err != nil {
 		return result, fmt.Errorf("failed to save analysis: %w", err)
@@ -76,13 +94,10 @@ func (as *AnalyzerService) DeleteAnalysis(ctx context.Context, analysisID string
 	return as.storage.DeleteAnalysis(ctx, analysisID)
 }
 
+// GetSupportedDatabaseTypes lists the SQL database types with a registered dialect,
+// followed by MongoDB.
 func (as *AnalyzerService) GetSupportedDatabaseTypes() []core.DatabaseType {
-	return []core.DatabaseType{
-		core.DatabaseTypeMySQL,
-		core.DatabaseTypePostgres,
-		core.DatabaseTypeSQLite,
-		core.DatabaseTypeMongoDB,
-	}
+	return append(DefaultDialects().Types(), core.DatabaseTypeMongoDB)
 }
 
 func (as *AnalyzerService) GetAnalysisOptions() core.AnalysisOptions {
@@ -94,5 +109,9 @@ func (as *AnalyzerService) GetAnalysisOptions() core.AnalysisOptions {
 		IncludePerformance: true,
 		SampleSize:        100,
 		MaxCollections:    50,
+		MaxTables:         50,
+		SamplingStrategy:  core.SamplingStrategyRandom,
+		ProfileTimeoutSeconds: int(core.DefaultColumnProfileTimeout / time.Second),
+		CommandTimeoutSeconds: int(core.DefaultMongoCommandTimeout / time.Second),
 	}
 }
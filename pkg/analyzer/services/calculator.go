@@ -1,7 +1,6 @@
 package services
 
 import (
-	"fmt"
 	"math"
 
 	"github.com/cherry-pick/pkg/analyzer/core"
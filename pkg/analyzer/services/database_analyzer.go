@@ -4,10 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"strconv"
 	"time"
 
 	"github.com/cherry-pick/pkg/analyzer/core"
+	"github.com/cherry-pick/pkg/logging"
+	"github.com/cherry-pick/pkg/tracing"
+	"github.com/cherry-pick/pkg/utils"
 )
 
 type DatabaseAnalyzerService struct {
@@ -15,6 +18,9 @@ type DatabaseAnalyzerService struct {
 	calculator  core.AnalysisCalculator
 	aggregator  core.AnalysisAggregator
 	validator   core.AnalysisValidator
+	logger      logging.Logger
+	cache       *utils.TTLCache
+	dialects    *DialectRegistry
 }
 
 func NewDatabaseAnalyzerService(
@@ -28,6 +34,26 @@ func NewDatabaseAnalyzerService(
 		calculator: calculator,
 		aggregator: aggregator,
 		validator:  validator,
+		logger:     logging.Default(),
+		cache:      utils.NewTTLCache(utils.DefaultCacheTTL, utils.DefaultCacheEntries),
+		dialects:   DefaultDialects(),
+	}
+}
+
+func (das *DatabaseAnalyzerService) SetCache(cache *utils.TTLCache) {
+	das.cache = cache
+}
+
+// SetDialects makes the analyzer look up dialects in registry instead of the default one.
+func (das *DatabaseAnalyzerService) SetDialects(registry *DialectRegistry) {
+	if registry != nil {
+		das.dialects = registry
+	}
+}
+
+func (das *DatabaseAnalyzerService) SetLogger(logger logging.Logger) {
+	if logger != nil {
+		das.logger = logger
 	}
 }
 
@@ -41,14 +67,15 @@ func (das *DatabaseAnalyzerService) AnalyzeDatabase(ctx context.Context, request
 	}
 
 	startTime := time.Now()
-	log.Printf("Starting database analysis for %s", request.DatabaseType)
+	das.logger.Info("Starting database analysis", "databaseType", request.DatabaseType)
 
-	tables, err := das.AnalyzeTables(ctx, request)
+	tables, warnings, truncation, err := das.analyzeTables(ctx, request)
 	if err != nil {
 		return nil, fmt.Errorf("failed to analyze tables: %w", err)
 	}
 
 	summary := das.aggregator.AggregateTableStats(tables)
+	summary.FailedTables = len(warnings)
 	insights := das.generateInsights(tables)
 	recommendations := das.generateRecommendations(insights)
 
@@ -56,7 +83,7 @@ func (das *DatabaseAnalyzerService) AnalyzeDatabase(ctx context.Context, request
 	if request.Options.IncludePerformance {
 		performance, err = das.GetPerformanceMetrics(ctx, request)
 		if err != nil {
-			log.Printf("Warning: Could not get performance metrics: %v", err)
+			das.logger.Warn("Could not get performance metrics", "error", err)
 		}
 	}
 
@@ -70,34 +97,67 @@ func (das *DatabaseAnalyzerService) AnalyzeDatabase(ctx context.Context, request
 		Insights:       insights,
 		Recommendations: recommendations,
 		Performance:    performance,
+		Warnings:       warnings,
+		Truncation:     truncation,
 	}
 
-	log.Printf("Database analysis completed in %v", time.Since(startTime))
+	das.logger.Info("Database analysis completed", "duration", time.Since(startTime), "tables", len(tables), "failedTables", len(warnings))
 	return result, nil
 }
 
 func (das *DatabaseAnalyzerService) AnalyzeTables(ctx context.Context, request core.AnalysisRequest) ([]core.TableInfo, error) {
-	tableNames, err := das.GetTableNames(ctx, request)
+	tables, _, _, err := das.analyzeTables(ctx, request)
+	return tables, err
+}
+
+// analyzeTables skips tables that fail to analyze, returning a warning for each so the
+// caller can report them instead of presenting a partial result as complete. The
+// truncation is non-nil when MaxTables left tables out.
+func (das *DatabaseAnalyzerService) analyzeTables(ctx context.Context, request core.AnalysisRequest) ([]core.TableInfo, []core.TableWarning, *core.ObjectTruncation, error) {
+	tableNames, truncation, err := das.listTableNames(ctx, request)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get table names: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to get table names: %w", err)
 	}
 
-	var tables []core.TableInfo
+	tables := []core.TableInfo{}
+	var warnings []core.TableWarning
 	for _, tableName := range tableNames {
-		log.Printf("Analyzing table: %s", tableName)
+		das.logger.Debug("Analyzing table", "table", tableName)
 
 		table, err := das.AnalyzeTable(ctx, tableName, request)
 		if err != nil {
-			log.Printf("Warning: Failed to analyze table %s: %v", tableName, err)
+			das.logger.Warn("Failed to analyze table", "table", tableName, "error", err)
+			warnings = append(warnings, core.TableWarning{Table: tableName, Error: err.Error()})
 			continue
 		}
 		tables = append(tables, *table)
 	}
 
-	return tables, nil
+	return tables, warnings, truncation, nil
 }
 
 func (das *DatabaseAnalyzerService) AnalyzeTable(ctx context.Context, tableName string, request core.AnalysisRequest) (*core.TableInfo, error) {
+	ctx, span := tracing.Start(ctx, "analyzer.AnalyzeTable",
+		tracing.AttrDatabaseType.String(string(das.connector.GetDatabaseType())),
+		tracing.AttrTableName.String(tableName))
+
+	table, err := das.analyzeTable(ctx, tableName, request)
+	if table != nil {
+		span.SetAttributes(tracing.AttrRowCount.Int64(table.RowCount))
+	}
+	tracing.End(span, err)
+
+	return table, err
+}
+
+func (das *DatabaseAnalyzerService) analyzeTable(ctx context.Context, tableName string, request core.AnalysisRequest) (*core.TableInfo, error) {
+	dialect, err := das.dialects.Lookup(das.connector.GetDatabaseType())
+	if err != nil {
+		return nil, err
+	}
+
+	db := das.connector.GetDatabase().(*sql.DB)
+	ref := resolveTableRef(tableName, request.Options)
 	table := &core.TableInfo{
 		Name:         tableName,
 		LastModified: time.Now(),
@@ -106,19 +166,27 @@ func (das *DatabaseAnalyzerService) AnalyzeTable(ctx context.Context, tableName
 	if request.Options.IncludeData {
 		rowCount, err := das.getRowCount(ctx, tableName)
 		if err != nil {
-			log.Printf("Warning: Could not get row count for %s: %v", tableName, err)
+			das.logger.Warn("Could not get row count", "table", tableName, "error", err)
 		}
 		table.RowCount = rowCount
 
-		size, err := das.getTableSize(ctx, tableName)
+		size, err := dialect.TableSize(ctx, db, ref.schema, ref.name)
 		if err != nil {
-			log.Printf("Warning: Could not get table size for %s: %v", tableName, err)
+			das.logger.Warn("Could not get table size", "table", tableName, "error", err)
 		}
 		table.Size = size
 	}
 
 	if request.Options.IncludeSchema {
-		columns, err := das.analyzeColumns(ctx, tableName, request)
+		if commenter, ok := dialect.(core.TableCommentDialect); ok {
+			comment, err := commenter.TableComment(ctx, db, ref.schema, ref.name)
+			if err != nil {
+				das.logger.Warn("Could not get table comment", "table", tableName, "error", err)
+			}
+			table.Comment = comment
+		}
+
+		columns, err := das.analyzeColumns(ctx, dialect, ref, table.RowCount, request)
 		if err != nil {
 			return table, fmt.Errorf("failed to analyze columns: %w", err)
 		}
@@ -126,23 +194,23 @@ func (das *DatabaseAnalyzerService) AnalyzeTable(ctx context.Context, tableName
 	}
 
 	if request.Options.IncludeIndexes {
-		indexes, err := das.getIndexes(ctx, tableName)
+		indexes, err := dialect.Indexes(ctx, db, ref.schema, ref.name)
 		if err != nil {
-			log.Printf("Warning: Could not get indexes for %s: %v", tableName, err)
+			das.logger.Warn("Could not get indexes", "table", tableName, "error", err)
 		}
 		table.Indexes = indexes
 	}
 
 	if request.Options.IncludeRelations {
-		constraints, err := das.getConstraints(ctx, tableName)
+		constraints, err := dialect.Constraints(ctx, db, ref.schema, ref.name)
 		if err != nil {
-			log.Printf("Warning: Could not get constraints for %s: %v", tableName, err)
+			das.logger.Warn("Could not get constraints", "table", tableName, "error", err)
 		}
 		table.Constraints = constraints
 
-		relationships, err := das.getRelationships(ctx, tableName)
+		relationships, err := dialect.Relationships(ctx, db, ref.schema, ref.name)
 		if err != nil {
-			log.Printf("Warning: Could not get relationships for %s: %v", tableName, err)
+			das.logger.Warn("Could not get relationships", "table", tableName, "error", err)
 		}
 		table.Relationships = relationships
 	}
@@ -150,38 +218,32 @@ func (das *DatabaseAnalyzerService) AnalyzeTable(ctx context.Context, tableName
 	return table, nil
 }
 
+// GetTableNames lists the tables of the connection's default schema, or, when
+// request.Options names schemas, the tables of those schemas qualified as schema.table.
+// At most request.Options.MaxTables names are returned.
 func (das *DatabaseAnalyzerService) GetTableNames(ctx context.Context, request core.AnalysisRequest) ([]string, error) {
-	db := das.connector.GetDatabase().(*sql.DB)
-	dbType := string(request.DatabaseType)
+	names, _, err := das.listTableNames(ctx, request)
+	return names, err
+}
 
-	var query string
-	switch dbType {
-	case "mysql":
-		query = "SHOW TABLES"
-	case "postgres":
-		query = "SELECT tablename FROM pg_tables WHERE schemaname = 'public'"
-	case "sqlite3":
-		query = "SELECT name FROM sqlite_master WHERE type='table'"
-	default:
-		return nil, fmt.Errorf("unsupported database type: %s", dbType)
+func (das *DatabaseAnalyzerService) listTableNames(ctx context.Context, request core.AnalysisRequest) ([]string, *core.ObjectTruncation, error) {
+	dialect, err := das.dialects.Lookup(request.DatabaseType)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	rows, err := db.QueryContext(ctx, query)
+	db := das.connector.GetDatabase().(*sql.DB)
+	tables, err := dialect.TableNames(ctx, db, request.Options.SchemaNames())
 	if err != nil {
-		return nil, fmt.Errorf("failed to query table names: %w", err)
+		return nil, nil, err
 	}
-	defer rows.Close()
 
-	var tables []string
-	for rows.Next() {
-		var tableName string
-		if err := rows.Scan(&tableName); err != nil {
-			return nil, fmt.Errorf("failed to scan table name: %w", err)
-		}
-		tables = append(tables, tableName)
+	tables, truncation := limitObjectNames(filterTableNames(tables, request.Options), request.Options.MaxTables)
+	if truncation != nil {
+		das.logger.Warn("Table limit reached, skipping remaining tables",
+			"limit", truncation.Limit, "total", truncation.Total, "skipped", truncation.Skipped)
 	}
-
-	return tables, rows.Err()
+	return tables, truncation, nil
 }
 
 func (das *DatabaseAnalyzerService) GetTableStats(ctx context.Context, tableName string, request core.AnalysisRequest) (*core.TableInfo, error) {
@@ -189,21 +251,13 @@ func (das *DatabaseAnalyzerService) GetTableStats(ctx context.Context, tableName
 }
 
 func (das *DatabaseAnalyzerService) GetPerformanceMetrics(ctx context.Context, request core.AnalysisRequest) (*core.PerformanceMetrics, error) {
-	db := das.connector.GetDatabase().(*sql.DB)
-	dbType := string(request.DatabaseType)
-
-	metrics := &core.PerformanceMetrics{}
-
-	switch dbType {
-	case "mysql":
-		return das.getMySQLPerformanceMetrics(ctx, db)
-	case "postgres":
-		return das.getPostgresPerformanceMetrics(ctx, db)
-	case "sqlite3":
-		return das.getSQLitePerformanceMetrics(ctx, db)
-	default:
-		return metrics, fmt.Errorf("performance metrics not supported for database type: %s", dbType)
+	dialect, err := das.dialects.Lookup(request.DatabaseType)
+	if err != nil {
+		return &core.PerformanceMetrics{}, fmt.Errorf("performance metrics not supported: %w", err)
 	}
+
+	db := das.connector.GetDatabase().(*sql.DB)
+	return dialect.PerformanceMetrics(ctx, db)
 }
 
 func (das *DatabaseAnalyzerService) getRowCount(ctx context.Context, tableName string) (int64, error) {
@@ -218,188 +272,102 @@ func (das *DatabaseAnalyzerService) getRowCount(ctx context.Context, tableName s
 	return count, nil
 }
 
-func (das *DatabaseAnalyzerService) getTableSize(ctx context.Context, tableName string) (string, error) {
+func (das *DatabaseAnalyzerService) analyzeColumns(ctx context.Context, dialect core.Dialect, ref tableRef, rowCount int64, request core.AnalysisRequest) ([]core.ColumnInfo, error) {
 	db := das.connector.GetDatabase().(*sql.DB)
-	dbType := das.connector.GetDatabaseType()
-
-	var query string
-	var args []interface{}
-
-	switch dbType {
-	case core.DatabaseTypeMySQL:
-		query = `
-			SELECT 
-				ROUND(((data_length + index_length) / 1024 / 1024), 2) AS size_mb
-			FROM information_schema.tables 
-			WHERE table_schema = DATABASE() AND table_name = ?`
-		args = append(args, tableName)
-	case core.DatabaseTypePostgres:
-		query = `SELECT pg_size_pretty(pg_total_relation_size($1)) AS size`
-		args = append(args, tableName)
-	case core.DatabaseTypeSQLite:
-		query = `SELECT COUNT(*) * 1024 as approx_bytes FROM pragma_table_info(?) LIMIT 1`
-		args = append(args, tableName)
-	default:
-		return "Unknown", fmt.Errorf("unsupported database type: %s", dbType)
-	}
-
-	var sizeResult interface{}
-	err := db.QueryRowContext(ctx, query, args...).Scan(&sizeResult)
+	tableName := ref.qualify(ref.name)
+
+	columns, err := dialect.Columns(ctx, db, ref.schema, ref.name)
 	if err != nil {
-		return "Unknown", fmt.Errorf("failed to get table size: %w", err)
+		return nil, err
+	}
+	if !request.Options.IncludeData {
+		return columns, nil
 	}
 
-	switch dbType {
-	case core.DatabaseTypeMySQL:
-		if size, ok := sizeResult.(float64); ok {
-			return fmt.Sprintf("%.2f MB", size), nil
+	profileOptions := newColumnProfileOptions(request.Options, rowCount)
+	for i := range columns {
+		col := &columns[i]
+		col.DataProfile = das.cachedColumnData(ctx, db, dialect, tableName, col.Name, col.DataType, profileOptions, request.Options.Refresh)
+		if request.Options.ApproximateDistinct {
+			col.UniqueValues = das.getApproxUniqueValueCount(ctx, tableName, col.Name, rowCount)
+			col.UniqueValuesApproximate = true
+		} else {
+			col.UniqueValues = das.getUniqueValueCount(ctx, tableName, col.Name)
 		}
-	case core.DatabaseTypePostgres:
-		if size, ok := sizeResult.(string); ok {
-			return size, nil
+		col.NullCount = das.getNullCount(ctx, tableName, col.Name)
+		if col.UniqueValues > 0 && col.UniqueValues <= core.TopValuesMaxCardinality {
+			col.DataProfile.TopValues = das.getTopValues(ctx, tableName, col.Name, profileOptions)
 		}
-	case core.DatabaseTypeSQLite:
-		if size, ok := sizeResult.(int64); ok {
-			if size < 1024 {
-				return fmt.Sprintf("%d bytes", size), nil
-			} else if size < 1024*1024 {
-				return fmt.Sprintf("%.2f KB", float64(size)/1024), nil
-			} else {
-				return fmt.Sprintf("%.2f MB", float64(size)/(1024*1024)), nil
-			}
+		if das.isJSONColumn(*col) {
+			col.JSONSchema = das.analyzeJSONColumn(ctx, db, dialect, tableName, *col, profileOptions)
 		}
 	}
 
-	return "Unknown", nil
+	return columns, nil
 }
 
-func (das *DatabaseAnalyzerService) analyzeColumns(ctx context.Context, tableName string, request core.AnalysisRequest) ([]core.ColumnInfo, error) {
-	db := das.connector.GetDatabase().(*sql.DB)
-	dbType := das.connector.GetDatabaseType()
-
-	var query string
-	var args []interface{}
-
-	switch dbType {
-	case core.DatabaseTypeMySQL:
-		query = `
-			SELECT 
-				COLUMN_NAME, DATA_TYPE, IS_NULLABLE, COLUMN_DEFAULT,
-				CHARACTER_MAXIMUM_LENGTH, NUMERIC_PRECISION, NUMERIC_SCALE,
-				COLUMN_KEY
-			FROM INFORMATION_SCHEMA.COLUMNS 
-			WHERE TABLE_NAME = ? AND TABLE_SCHEMA = DATABASE()
-			ORDER BY ORDINAL_POSITION`
-		args = append(args, tableName)
-	case core.DatabaseTypePostgres:
-		query = `
-			SELECT 
-				column_name, data_type, is_nullable, column_default,
-				character_maximum_length, numeric_precision, numeric_scale,
-				CASE WHEN column_name IN (
-					SELECT column_name FROM information_schema.table_constraints tc
-					JOIN information_schema.key_column_usage kcu ON tc.constraint_name = kcu.constraint_name
-					WHERE tc.table_name = $1 AND tc.constraint_type = 'PRIMARY KEY'
-				) THEN 'PRI' ELSE '' END as column_key
-			FROM information_schema.columns 
-			WHERE table_name = $1
-			ORDER BY ordinal_position`
-		args = append(args, tableName)
-	case core.DatabaseTypeSQLite:
-		query = fmt.Sprintf("PRAGMA table_info(%s)", tableName)
-	default:
-		return nil, fmt.Errorf("unsupported database type: %s", dbType)
-	}
-
-	rows, err := db.QueryContext(ctx, query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query columns: %w", err)
-	}
-	defer rows.Close()
-
-	var columns []core.ColumnInfo
-	for rows.Next() {
-		var col core.ColumnInfo
-		var maxLength, precision, scale sql.NullInt64
-		var defaultVal sql.NullString
-		var columnKey string
-
-		if dbType == core.DatabaseTypeSQLite {
-			var cid int
-			var notNull int
-			var pk int
-			err = rows.Scan(&cid, &col.Name, &col.DataType, &notNull, &defaultVal, &pk)
-			col.IsNullable = notNull == 0
-			col.IsPrimaryKey = pk == 1
-		} else {
-			var nullable string
-			err = rows.Scan(&col.Name, &col.DataType, &nullable, &defaultVal,
-				&maxLength, &precision, &scale, &columnKey)
-			col.IsNullable = nullable == "YES"
-			col.IsPrimaryKey = columnKey == "PRI"
-		}
+type columnProfileOptions struct {
+	sampleSize int
+	topValues  int
+	timeout    time.Duration
+	rowCount   int64
+}
 
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan column info: %w", err)
-		}
+func newColumnProfileOptions(options core.AnalysisOptions, rowCount int64) columnProfileOptions {
+	profileOptions := columnProfileOptions{
+		sampleSize: options.SampleSize,
+		topValues:  options.TopValues,
+		timeout:    time.Duration(options.ProfileTimeoutSeconds) * time.Second,
+		rowCount:   rowCount,
+	}
+	if profileOptions.sampleSize <= 0 {
+		profileOptions.sampleSize = core.DefaultColumnSampleSize
+	}
+	if profileOptions.topValues <= 0 {
+		profileOptions.topValues = core.DefaultTopValues
+	}
+	if profileOptions.timeout <= 0 {
+		profileOptions.timeout = core.DefaultColumnProfileTimeout
+	}
+	return profileOptions
+}
 
-		if defaultVal.Valid {
-			col.DefaultValue = defaultVal.String
-		}
-		if maxLength.Valid {
-			col.MaxLength = int(maxLength.Int64)
-		}
-		if precision.Valid {
-			col.Precision = int(precision.Int64)
-		}
-		if scale.Valid {
-			col.Scale = int(scale.Int64)
-		}
+// cachedColumnData caches only complete profiles, so a query that failed or timed out is
+// retried on the next analysis instead of serving the partial profile until it expires.
+func (das *DatabaseAnalyzerService) cachedColumnData(ctx context.Context, db *sql.DB, dialect core.Dialect, tableName, columnName, dataType string, options columnProfileOptions, refresh bool) core.DataProfile {
+	if das.cache == nil {
+		profile, _ := das.analyzeColumnData(ctx, db, dialect, tableName, columnName, dataType, options)
+		return profile
+	}
 
-		if request.Options.IncludeData {
-			col.DataProfile = das.analyzeColumnData(ctx, tableName, col.Name, col.DataType)
-			col.UniqueValues = das.getUniqueValueCount(ctx, tableName, col.Name)
-			col.NullCount = das.getNullCount(ctx, tableName, col.Name)
+	key := utils.CacheKey(string(das.connector.GetDatabaseType()), das.connector.GetDatabaseName(),
+		"column-profile", tableName, columnName, dataType, strconv.Itoa(options.sampleSize))
+	if !refresh {
+		if cached, ok := das.cache.Get(key); ok {
+			return cached.(core.DataProfile)
 		}
-
-		columns = append(columns, col)
 	}
 
-	return columns, rows.Err()
+	profile, err := das.analyzeColumnData(ctx, db, dialect, tableName, columnName, dataType, options)
+	if err == nil {
+		das.cache.Set(key, profile)
+	}
+	return profile
 }
 
-func (das *DatabaseAnalyzerService) analyzeColumnData(ctx context.Context, tableName, columnName, dataType string) core.DataProfile {
+// analyzeColumnData returns what it could profile along with the first query error, if any.
+func (das *DatabaseAnalyzerService) analyzeColumnData(ctx context.Context, db *sql.DB, dialect core.Dialect, tableName, columnName, dataType string, options columnProfileOptions) (core.DataProfile, error) {
 	profile := core.DataProfile{}
 
-	db := das.connector.GetDatabase().(*sql.DB)
-	sampleQuery := fmt.Sprintf("SELECT DISTINCT %s FROM %s WHERE %s IS NOT NULL LIMIT 10",
-		columnName, tableName, columnName)
-
-	rows, err := db.QueryContext(ctx, sampleQuery)
-	if err != nil {
-		return profile
-	}
-	defer rows.Close()
-
-	var samples []string
-	for rows.Next() {
-		var value sql.NullString
-		if err := rows.Scan(&value); err != nil {
-			continue
-		}
-		if value.Valid {
-			samples = append(samples, value.String)
-		}
-	}
+	samples, profileErr := das.sampleColumnValues(ctx, db, tableName, columnName, options)
 	profile.SampleData = samples
 
 	if das.isNumericType(dataType) {
-		minQuery := fmt.Sprintf("SELECT MIN(%s), MAX(%s), AVG(%s) FROM %s WHERE %s IS NOT NULL",
-			columnName, columnName, columnName, tableName, columnName)
-
-		var min, max, avg sql.NullFloat64
-		err := db.QueryRowContext(ctx, minQuery).Scan(&min, &max, &avg)
+		queryCtx, cancel := context.WithTimeout(ctx, options.timeout)
+		min, max, avg, sampled, err := numericAggregates(queryCtx, db, dialect, tableName, columnName, options.rowCount)
+		cancel()
 		if err == nil {
+			profile.Sampled = sampled
 			if min.Valid {
 				profile.Min = min.Float64
 			}
@@ -409,6 +377,11 @@ func (das *DatabaseAnalyzerService) analyzeColumnData(ctx context.Context, table
 			if avg.Valid {
 				profile.Avg = avg.Float64
 			}
+		} else {
+			das.logger.Debug("Column aggregate query failed", "table", tableName, "column", columnName, "error", err)
+			if profileErr == nil {
+				profileErr = err
+			}
 		}
 	}
 
@@ -417,315 +390,142 @@ func (das *DatabaseAnalyzerService) analyzeColumnData(ctx context.Context, table
 		DataProfile: profile,
 	})
 
-	return profile
+	return profile, profileErr
 }
 
-func (das *DatabaseAnalyzerService) getUniqueValueCount(ctx context.Context, tableName, columnName string) int64 {
-	db := das.connector.GetDatabase().(*sql.DB)
-	query := fmt.Sprintf("SELECT COUNT(DISTINCT %s) FROM %s", columnName, tableName)
-	
-	var count int64
-	err := db.QueryRowContext(ctx, query).Scan(&count)
-	if err != nil {
-		return 0
-	}
-	return count
-}
+func (das *DatabaseAnalyzerService) sampleColumnValues(ctx context.Context, db *sql.DB, tableName, columnName string, options columnProfileOptions) ([]string, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, options.timeout)
+	defer cancel()
 
-func (das *DatabaseAnalyzerService) getNullCount(ctx context.Context, tableName, columnName string) int64 {
-	db := das.connector.GetDatabase().(*sql.DB)
-	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s IS NULL", tableName, columnName)
-	
-	var count int64
-	err := db.QueryRowContext(ctx, query).Scan(&count)
-	if err != nil {
-		return 0
-	}
-	return count
-}
+	sampleQuery := fmt.Sprintf("SELECT DISTINCT %s FROM %s WHERE %s IS NOT NULL LIMIT %d",
+		columnName, tableName, columnName, options.sampleSize)
 
-func (das *DatabaseAnalyzerService) getIndexes(ctx context.Context, tableName string) ([]core.IndexInfo, error) {
-	db := das.connector.GetDatabase().(*sql.DB)
-	dbType := das.connector.GetDatabaseType()
-
-	var query string
-	var args []interface{}
-
-	switch dbType {
-	case core.DatabaseTypeMySQL:
-		query = "SHOW INDEX FROM " + tableName
-	case core.DatabaseTypePostgres:
-		query = `
-			SELECT 
-				indexname, 
-				indexdef,
-				CASE WHEN indisunique THEN true ELSE false END as is_unique
-			FROM pg_indexes 
-			JOIN pg_class ON pg_class.relname = indexname
-			JOIN pg_index ON pg_index.indexrelid = pg_class.oid
-			WHERE tablename = $1`
-		args = append(args, tableName)
-	case core.DatabaseTypeSQLite:
-		query = "PRAGMA index_list(" + tableName + ")"
-	default:
-		return []core.IndexInfo{}, fmt.Errorf("unsupported database type: %s", dbType)
-	}
-
-	rows, err := db.QueryContext(ctx, query, args...)
+	rows, err := db.QueryContext(queryCtx, sampleQuery)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query indexes: %w", err)
+		das.logger.Debug("Column sample query failed", "table", tableName, "column", columnName, "error", err)
+		return nil, err
 	}
 	defer rows.Close()
 
-	var indexes []core.IndexInfo
-	for rows.Next() {
-		var index core.IndexInfo
-
-		switch dbType {
-		case core.DatabaseTypeMySQL:
-			var seq, cardinality sql.NullInt64
-			var collation, subPart, packed, null, comment sql.NullString
-			var nonUnique int
-			var columnName string
-			err = rows.Scan(&tableName, &nonUnique, &index.Name, &seq,
-				&columnName, &collation, &cardinality, &subPart, &packed, &null, &index.Type, &comment)
-			if err != nil {
-				continue
-			}
-			index.IsUnique = nonUnique == 0
-			index.Columns = []string{columnName}
-		case core.DatabaseTypePostgres:
-			var indexDef string
-			err = rows.Scan(&index.Name, &indexDef, &index.IsUnique)
-			if err != nil {
-				continue
-			}
-			index.Type = "btree"
-			index.Columns = []string{"parsed_from_def"}
-		case core.DatabaseTypeSQLite:
-			var seq int
-			var unique int
-			var origin string
-			var partial int
-			err = rows.Scan(&seq, &index.Name, &unique, &origin, &partial)
-			if err != nil {
-				continue
-			}
-			index.IsUnique = unique == 1
-			index.Type = "btree"
-
-			colQuery := "PRAGMA index_info(" + index.Name + ")"
-			colRows, colErr := db.QueryContext(ctx, colQuery)
-			if colErr == nil {
-				var columns []string
-				for colRows.Next() {
-					var seqno, cid int
-					var name string
-					if colRows.Scan(&seqno, &cid, &name) == nil {
-						columns = append(columns, name)
-					}
-				}
-				colRows.Close()
-				index.Columns = columns
-			}
+	var samples []string
+	for rows.Next() && len(samples) < options.sampleSize {
+		var value sql.NullString
+		if err := rows.Scan(&value); err != nil {
+			continue
 		}
-
-		if index.Name != "" {
-			indexes = append(indexes, index)
+		if value.Valid {
+			samples = append(samples, value.String)
 		}
 	}
-
-	return indexes, rows.Err()
+	return samples, rows.Err()
 }
 
-func (das *DatabaseAnalyzerService) getConstraints(ctx context.Context, tableName string) ([]core.Constraint, error) {
+// getTopValues returns the column's most common non-null values with their row counts,
+// most frequent first.
+func (das *DatabaseAnalyzerService) getTopValues(ctx context.Context, tableName, columnName string, options columnProfileOptions) []core.ValueCount {
+	queryCtx, cancel := context.WithTimeout(ctx, options.timeout)
+	defer cancel()
+
 	db := das.connector.GetDatabase().(*sql.DB)
-	dbType := das.connector.GetDatabaseType()
-
-	var query string
-	var args []interface{}
-
-	switch dbType {
-	case core.DatabaseTypeMySQL:
-		query = `
-			SELECT 
-				CONSTRAINT_NAME, CONSTRAINT_TYPE, COLUMN_NAME
-			FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
-			LEFT JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE kcu 
-				ON tc.CONSTRAINT_NAME = kcu.CONSTRAINT_NAME 
-				AND tc.TABLE_NAME = kcu.TABLE_NAME
-			WHERE tc.TABLE_NAME = ? AND tc.TABLE_SCHEMA = DATABASE()`
-		args = append(args, tableName)
-	case core.DatabaseTypePostgres:
-		query = `
-			SELECT 
-				tc.constraint_name, tc.constraint_type, kcu.column_name
-			FROM information_schema.table_constraints tc
-			LEFT JOIN information_schema.key_column_usage kcu 
-				ON tc.constraint_name = kcu.constraint_name 
-				AND tc.table_name = kcu.table_name
-			WHERE tc.table_name = $1`
-		args = append(args, tableName)
-	case core.DatabaseTypeSQLite:
-		query = "PRAGMA foreign_key_list(" + tableName + ")"
-	default:
-		return []core.Constraint{}, fmt.Errorf("unsupported database type: %s", dbType)
-	}
-
-	rows, err := db.QueryContext(ctx, query, args...)
+	query := fmt.Sprintf("SELECT %s, COUNT(*) FROM %s WHERE %s IS NOT NULL GROUP BY %s ORDER BY COUNT(*) DESC, %s LIMIT %d",
+		columnName, tableName, columnName, columnName, columnName, options.topValues)
+
+	rows, err := db.QueryContext(queryCtx, query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query constraints: %w", err)
+		das.logger.Debug("Column top values query failed", "table", tableName, "column", columnName, "error", err)
+		return nil
 	}
 	defer rows.Close()
 
-	var constraints []core.Constraint
+	var values []core.ValueCount
 	for rows.Next() {
-		var constraint core.Constraint
-
-		switch dbType {
-		case core.DatabaseTypeMySQL, core.DatabaseTypePostgres:
-			var columnName sql.NullString
-			err = rows.Scan(&constraint.Name, &constraint.Type, &columnName)
-			if err != nil {
-				continue
-			}
-			if columnName.Valid {
-				constraint.Columns = []string{columnName.String}
-			}
-		case core.DatabaseTypeSQLite:
-			var id int
-			var seq int
-			var table, from, to, onUpdate, onDelete, match string
-			err = rows.Scan(&id, &seq, &table, &from, &to, &onUpdate, &onDelete, &match)
-			if err != nil {
-				continue
-			}
-			constraint.Name = fmt.Sprintf("fk_%d", id)
-			constraint.Type = "FOREIGN KEY"
-			constraint.Columns = []string{from}
-			constraint.RefTable = table
-			constraint.RefColumns = []string{to}
+		var value sql.NullString
+		var count int64
+		if err := rows.Scan(&value, &count); err != nil {
+			continue
 		}
+		values = append(values, core.ValueCount{Value: value.String, Count: count})
+	}
+	return values
+}
 
-		if constraint.Name != "" {
-			constraints = append(constraints, constraint)
+// numericAggregates reads MIN, MAX and AVG of a column, from a sample when the table is
+// large and the dialect knows how to sample it. sampled reports which it did.
+func numericAggregates(ctx context.Context, db *sql.DB, dialect core.Dialect, tableName, columnName string, rowCount int64) (min, max, avg sql.NullFloat64, sampled bool, err error) {
+	if rowCount > core.LargeTableRowThreshold {
+		if sampler, ok := dialect.(core.NumericSampleDialect); ok {
+			min, max, avg, err = sampler.SampledNumericAggregates(ctx, db, tableName, columnName, rowCount, core.NumericAggregateSampleRows)
+			return min, max, avg, true, err
 		}
 	}
 
-	return constraints, rows.Err()
+	query := fmt.Sprintf("SELECT MIN(%s), MAX(%s), AVG(%s) FROM %s WHERE %s IS NOT NULL",
+		columnName, columnName, columnName, tableName, columnName)
+	err = db.QueryRowContext(ctx, query).Scan(&min, &max, &avg)
+	return min, max, avg, false, err
 }
 
-func (das *DatabaseAnalyzerService) getRelationships(ctx context.Context, tableName string) ([]core.Relationship, error) {
+func (das *DatabaseAnalyzerService) getUniqueValueCount(ctx context.Context, tableName, columnName string) int64 {
 	db := das.connector.GetDatabase().(*sql.DB)
-	dbType := das.connector.GetDatabaseType()
-
-	var query string
-	var args []interface{}
-
-	switch dbType {
-	case core.DatabaseTypeMySQL:
-		query = `
-			SELECT 
-				kcu.COLUMN_NAME,
-				kcu.REFERENCED_TABLE_NAME,
-				kcu.REFERENCED_COLUMN_NAME,
-				rc.UPDATE_RULE,
-				rc.DELETE_RULE
-			FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE kcu
-			JOIN INFORMATION_SCHEMA.REFERENTIAL_CONSTRAINTS rc 
-				ON kcu.CONSTRAINT_NAME = rc.CONSTRAINT_NAME
-			WHERE kcu.TABLE_NAME = ? 
-				AND kcu.REFERENCED_TABLE_NAME IS NOT NULL
-				AND kcu.TABLE_SCHEMA = DATABASE()`
-		args = append(args, tableName)
-	case core.DatabaseTypePostgres:
-		query = `
-			SELECT 
-				kcu.column_name,
-				ccu.table_name AS referenced_table,
-				ccu.column_name AS referenced_column,
-				rc.update_rule,
-				rc.delete_rule
-			FROM information_schema.key_column_usage kcu
-			JOIN information_schema.referential_constraints rc 
-				ON kcu.constraint_name = rc.constraint_name
-			JOIN information_schema.constraint_column_usage ccu 
-				ON rc.unique_constraint_name = ccu.constraint_name
-			WHERE kcu.table_name = $1`
-		args = append(args, tableName)
-	case core.DatabaseTypeSQLite:
-		query = "PRAGMA foreign_key_list(" + tableName + ")"
-	default:
-		return []core.Relationship{}, fmt.Errorf("unsupported database type: %s", dbType)
-	}
-
-	rows, err := db.QueryContext(ctx, query, args...)
+	query := fmt.Sprintf("SELECT COUNT(DISTINCT %s) FROM %s", columnName, tableName)
+	
+	var count int64
+	err := db.QueryRowContext(ctx, query).Scan(&count)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query relationships: %w", err)
+		return 0
 	}
-	defer rows.Close()
+	return count
+}
 
-	var relationships []core.Relationship
-	for rows.Next() {
-		var rel core.Relationship
+// getApproxUniqueValueCount streams the column's non-null values into a HyperLogLog
+// sketch instead of asking the database to sort or hash them all for COUNT(DISTINCT).
+// At most ApproxDistinctScanRows values are read; when the scan stops early and the
+// sampled values were nearly all distinct, the count is extrapolated to rowCount.
+func (das *DatabaseAnalyzerService) getApproxUniqueValueCount(ctx context.Context, tableName, columnName string, rowCount int64) int64 {
+	db := das.connector.GetDatabase().(*sql.DB)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s IS NOT NULL LIMIT %d",
+		columnName, tableName, columnName, core.ApproxDistinctScanRows)
 
-		switch dbType {
-		case core.DatabaseTypeMySQL, core.DatabaseTypePostgres:
-			var updateRule, deleteRule sql.NullString
-			err = rows.Scan(&rel.SourceColumn, &rel.TargetTable,
-				&rel.TargetColumn, &updateRule, &deleteRule)
-			if err != nil {
-				continue
-			}
-			rel.Type = "FOREIGN KEY"
-		case core.DatabaseTypeSQLite:
-			var id, seq int
-			var onUpdate, onDelete, match string
-			err = rows.Scan(&id, &seq, &rel.TargetTable, &rel.SourceColumn,
-				&rel.TargetColumn, &onUpdate, &onDelete, &match)
-			if err != nil {
-				continue
-			}
-			rel.Type = "FOREIGN KEY"
-		}
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return 0
+	}
+	defer rows.Close()
 
-		if rel.TargetTable != "" {
-			relationships = append(relationships, rel)
+	sketch := utils.NewHyperLogLog(utils.DefaultHyperLogLogPrecision)
+	var scanned int64
+	for rows.Next() {
+		var value sql.RawBytes
+		if err := rows.Scan(&value); err != nil {
+			return 0
 		}
+		sketch.Add(value)
+		scanned++
 	}
-
-	return relationships, rows.Err()
-}
-
-func (das *DatabaseAnalyzerService) getMySQLPerformanceMetrics(ctx context.Context, db *sql.DB) (*core.PerformanceMetrics, error) {
-	metrics := &core.PerformanceMetrics{}
-
-	query := "SHOW STATUS LIKE 'Connections'"
-	var name string
-	var value int
-	err := db.QueryRowContext(ctx, query).Scan(&name, &value)
-	if err == nil {
-		metrics.Connections.TotalCreated = value
+	if rows.Err() != nil {
+		return 0
 	}
 
-	return metrics, nil
-}
-
-func (das *DatabaseAnalyzerService) getPostgresPerformanceMetrics(ctx context.Context, db *sql.DB) (*core.PerformanceMetrics, error) {
-	metrics := &core.PerformanceMetrics{}
-
-	query := "SELECT count(*) FROM pg_stat_activity"
-	var connections int
-	err := db.QueryRowContext(ctx, query).Scan(&connections)
-	if err == nil {
-		metrics.Connections.Current = connections
+	estimate := int64(sketch.Estimate())
+	if estimate > scanned {
+		estimate = scanned
 	}
-
-	return metrics, nil
+	if scanned == core.ApproxDistinctScanRows && rowCount > scanned && float64(estimate) >= 0.9*float64(scanned) {
+		estimate = int64(float64(estimate) / float64(scanned) * float64(rowCount))
+	}
+	return estimate
 }
 
-func (das *DatabaseAnalyzerService) getSQLitePerformanceMetrics(ctx context.Context, db *sql.DB) (*core.PerformanceMetrics, error) {
-	return &core.PerformanceMetrics{}, nil
+func (das *DatabaseAnalyzerService) getNullCount(ctx context.Context, tableName, columnName string) int64 {
+	db := das.connector.GetDatabase().(*sql.DB)
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s IS NULL", tableName, columnName)
+	
+	var count int64
+	err := db.QueryRowContext(ctx, query).Scan(&count)
+	if err != nil {
+		return 0
+	}
+	return count
 }
 
 func (das *DatabaseAnalyzerService) isNumericType(dataType string) bool {
@@ -739,7 +539,7 @@ func (das *DatabaseAnalyzerService) isNumericType(dataType string) bool {
 }
 
 func (das *DatabaseAnalyzerService) generateInsights(tables []core.TableInfo) []core.DatabaseInsight {
-	var insights []core.DatabaseInsight
+	insights := []core.DatabaseInsight{}
 
 	for _, table := range tables {
 		if table.RowCount > 1000000 {
@@ -767,13 +567,42 @@ func (das *DatabaseAnalyzerService) generateInsights(tables []core.TableInfo) []
 			}
 			insights = append(insights, insight)
 		}
+
+		if len(table.Columns) > 0 && !hasRowIdentity(table) {
+			insight := core.DatabaseInsight{
+				Type:           "schema",
+				Severity:       "high",
+				Title:          "Table Without Primary Key",
+				Description:    fmt.Sprintf("Table '%s' has no primary key or unique index, so its rows cannot be identified reliably", table.Name),
+				Suggestion:     "Add a surrogate primary key, such as an auto-incrementing id column",
+				AffectedTables: []string{table.Name},
+				MetricValue:    table.RowCount,
+			}
+			insights = append(insights, insight)
+		}
 	}
 
 	return insights
 }
 
+// hasRowIdentity reports whether the table has a primary key column or a unique index,
+// which replication and ORMs need to address single rows.
+func hasRowIdentity(table core.TableInfo) bool {
+	for _, column := range table.Columns {
+		if column.IsPrimaryKey {
+			return true
+		}
+	}
+	for _, index := range table.Indexes {
+		if index.IsUnique {
+			return true
+		}
+	}
+	return false
+}
+
 func (das *DatabaseAnalyzerService) generateRecommendations(insights []core.DatabaseInsight) []string {
-	var recommendations []string
+	recommendations := []string{}
 
 	highPriorityCount := 0
 	for _, insight := range insights {
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/cherry-pick/pkg/analyzer/core"
+	"github.com/cherry-pick/pkg/utils"
+)
+
+// sqliteConnector hands out an already opened database.
+type sqliteConnector struct {
+	core.DatabaseConnector
+	db *sql.DB
+}
+
+func (c *sqliteConnector) GetDatabaseName() string            { return "test" }
+func (c *sqliteConnector) GetDatabaseType() core.DatabaseType { return core.DatabaseTypeSQLite }
+func (c *sqliteConnector) GetDatabase() interface{}           { return c.db }
+
+func newCachingAnalyzer(t *testing.T) (*DatabaseAnalyzerService, *sql.DB) {
+	t.Helper()
+
+	db := openTestDB(t)
+	das := NewDatabaseAnalyzerService(&sqliteConnector{db: db}, NewCalculatorService(), nil, nil)
+	das.SetCache(utils.NewTTLCache(time.Minute, 10))
+	return das, db
+}
+
+func TestColumnProfileIsCached(t *testing.T) {
+	das, db := newCachingAnalyzer(t)
+	options := newColumnProfileOptions(core.AnalysisOptions{}, 4)
+
+	first := das.cachedColumnData(context.Background(), db, fakeDialect{}, "orders", "amount", "integer", options, false)
+	if first.Max != 6 || das.cache.Len() != 1 {
+		t.Fatalf("Expected a cached profile with max 6, got max %v and %d entries", first.Max, das.cache.Len())
+	}
+
+	if _, err := db.Exec("INSERT INTO orders (amount) VALUES (100)"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	cached := das.cachedColumnData(context.Background(), db, fakeDialect{}, "orders", "amount", "integer", options, false)
+	if cached.Max != 6 {
+		t.Errorf("Expected the cached max 6, got %v", cached.Max)
+	}
+	refreshed := das.cachedColumnData(context.Background(), db, fakeDialect{}, "orders", "amount", "integer", options, true)
+	if refreshed.Max != 100 {
+		t.Errorf("Expected a refresh to read max 100, got %v", refreshed.Max)
+	}
+}
+
+func TestFailedColumnProfileIsNotCached(t *testing.T) {
+	das, db := newCachingAnalyzer(t)
+	options := newColumnProfileOptions(core.AnalysisOptions{}, 4)
+
+	das.cachedColumnData(context.Background(), db, fakeDialect{}, "orders", "missing", "integer", options, false)
+	if das.cache.Len() != 0 {
+		t.Errorf("Expected no cache entry for a failed profile, got %d", das.cache.Len())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	das.cachedColumnData(ctx, db, fakeDialect{}, "orders", "amount", "integer", options, false)
+	if das.cache.Len() != 0 {
+		t.Errorf("Expected no cache entry for a cancelled profile, got %d", das.cache.Len())
+	}
+
+	profile := das.cachedColumnData(context.Background(), db, fakeDialect{}, "orders", "amount", "integer", options, false)
+	if profile.Max != 6 || das.cache.Len() != 1 {
+		t.Errorf("Expected the retried profile to be read and cached, got max %v and %d entries", profile.Max, das.cache.Len())
+	}
+}
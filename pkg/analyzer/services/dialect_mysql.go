@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/cherry-pick/pkg/analyzer/core"
+)
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) TableNames(ctx context.Context, db *sql.DB, schemas []string) ([]string, error) {
+	if len(schemas) == 0 {
+		return queryTableNames(ctx, db, "SHOW TABLES", nil, nil)
+	}
+
+	in, args := schemaPlaceholders(schemas, func(int) string { return "?" })
+	query := `SELECT TABLE_SCHEMA, TABLE_NAME FROM INFORMATION_SCHEMA.TABLES
+			WHERE TABLE_TYPE = 'BASE TABLE' AND TABLE_SCHEMA IN (` + in + `)
+			ORDER BY TABLE_SCHEMA, TABLE_NAME`
+	return queryTableNames(ctx, db, query, args, schemas)
+}
+
+func (mysqlDialect) Columns(ctx context.Context, db *sql.DB, schema, table string) ([]core.ColumnInfo, error) {
+	ref := tableRef{schema: schema, name: table}
+	schemaExpr, schemaArgs := ref.schemaExpr(core.DatabaseTypeMySQL, "?")
+	query := `
+			SELECT
+				COLUMN_NAME, DATA_TYPE, IS_NULLABLE, COLUMN_DEFAULT,
+				CHARACTER_MAXIMUM_LENGTH, NUMERIC_PRECISION, NUMERIC_SCALE,
+				COLUMN_KEY, COLUMN_COMMENT
+			FROM INFORMATION_SCHEMA.COLUMNS
+			WHERE TABLE_NAME = ? AND TABLE_SCHEMA = ` + schemaExpr + `
+			ORDER BY ORDINAL_POSITION`
+
+	rows, err := db.QueryContext(ctx, query, append([]interface{}{table}, schemaArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns: %w", err)
+	}
+	return scanCatalogColumns(rows)
+}
+
+func (mysqlDialect) Indexes(ctx context.Context, db *sql.DB, schema, table string) ([]core.IndexInfo, error) {
+	tableName := tableRef{schema: schema}.qualify(table)
+	rows, err := db.QueryContext(ctx, "SHOW INDEX FROM "+tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %w", err)
+	}
+	defer rows.Close()
+
+	indexes := []core.IndexInfo{}
+	for rows.Next() {
+		var index core.IndexInfo
+		var seq, cardinality sql.NullInt64
+		var collation, subPart, packed, null, comment sql.NullString
+		var nonUnique int
+		var indexTable, columnName string
+		err = rows.Scan(&indexTable, &nonUnique, &index.Name, &seq,
+			&columnName, &collation, &cardinality, &subPart, &packed, &null, &index.Type, &comment)
+		if err != nil {
+			continue
+		}
+		index.IsUnique = nonUnique == 0
+		index.Columns = []string{columnName}
+
+		if index.Name != "" {
+			indexes = append(indexes, index)
+		}
+	}
+	return indexes, rows.Err()
+}
+
+func (mysqlDialect) Constraints(ctx context.Context, db *sql.DB, schema, table string) ([]core.Constraint, error) {
+	ref := tableRef{schema: schema, name: table}
+	schemaExpr, schemaArgs := ref.schemaExpr(core.DatabaseTypeMySQL, "?")
+	query := `
+			SELECT
+				tc.CONSTRAINT_NAME, tc.CONSTRAINT_TYPE, kcu.COLUMN_NAME
+			FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+			LEFT JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE kcu
+				ON tc.CONSTRAINT_NAME = kcu.CONSTRAINT_NAME
+				AND tc.TABLE_NAME = kcu.TABLE_NAME
+				AND tc.TABLE_SCHEMA = kcu.TABLE_SCHEMA
+			WHERE tc.TABLE_NAME = ? AND tc.TABLE_SCHEMA = ` + schemaExpr
+
+	rows, err := db.QueryContext(ctx, query, append([]interface{}{table}, schemaArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query constraints: %w", err)
+	}
+	return scanCatalogConstraints(rows)
+}
+
+func (mysqlDialect) Relationships(ctx context.Context, db *sql.DB, schema, table string) ([]core.Relationship, error) {
+	ref := tableRef{schema: schema, name: table}
+	schemaExpr, schemaArgs := ref.schemaExpr(core.DatabaseTypeMySQL, "?")
+	query := `
+			SELECT
+				kcu.COLUMN_NAME,
+				kcu.REFERENCED_TABLE_SCHEMA,
+				kcu.REFERENCED_TABLE_NAME,
+				kcu.REFERENCED_COLUMN_NAME,
+				rc.UPDATE_RULE,
+				rc.DELETE_RULE
+			FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE kcu
+			JOIN INFORMATION_SCHEMA.REFERENTIAL_CONSTRAINTS rc
+				ON kcu.CONSTRAINT_NAME = rc.CONSTRAINT_NAME
+				AND kcu.CONSTRAINT_SCHEMA = rc.CONSTRAINT_SCHEMA
+			WHERE kcu.TABLE_NAME = ?
+				AND kcu.REFERENCED_TABLE_NAME IS NOT NULL
+				AND kcu.TABLE_SCHEMA = ` + schemaExpr
+
+	rows, err := db.QueryContext(ctx, query, append([]interface{}{table}, schemaArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query relationships: %w", err)
+	}
+	return scanCatalogRelationships(rows, ref)
+}
+
+func (mysqlDialect) TableSize(ctx context.Context, db *sql.DB, schema, table string) (string, error) {
+	ref := tableRef{schema: schema, name: table}
+	schemaExpr, schemaArgs := ref.schemaExpr(core.DatabaseTypeMySQL, "?")
+	query := `
+			SELECT
+				ROUND(((data_length + index_length) / 1024 / 1024), 2) AS size_mb
+			FROM information_schema.tables
+			WHERE table_name = ? AND table_schema = ` + schemaExpr
+
+	var sizeResult interface{}
+	err := db.QueryRowContext(ctx, query, append([]interface{}{table}, schemaArgs...)...).Scan(&sizeResult)
+	if err != nil {
+		return "Unknown", fmt.Errorf("failed to get table size: %w", err)
+	}
+	if size, ok := sizeResult.(float64); ok {
+		return fmt.Sprintf("%.2f MB", size), nil
+	}
+	return "Unknown", nil
+}
+
+func (mysqlDialect) TableComment(ctx context.Context, db *sql.DB, schema, table string) (string, error) {
+	ref := tableRef{schema: schema, name: table}
+	schemaExpr, schemaArgs := ref.schemaExpr(core.DatabaseTypeMySQL, "?")
+	query := "SELECT TABLE_COMMENT FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_NAME = ? AND TABLE_SCHEMA = " + schemaExpr
+	return queryTableComment(ctx, db, query, append([]interface{}{table}, schemaArgs...))
+}
+
+func (mysqlDialect) PerformanceMetrics(ctx context.Context, db *sql.DB) (*core.PerformanceMetrics, error) {
+	metrics := &core.PerformanceMetrics{}
+
+	query := "SHOW STATUS LIKE 'Connections'"
+	var name string
+	var value int
+	err := db.QueryRowContext(ctx, query).Scan(&name, &value)
+	if err == nil {
+		metrics.Connections.TotalCreated = value
+	}
+
+	return metrics, nil
+}
+
+func (mysqlDialect) SampledNumericAggregates(ctx context.Context, db *sql.DB, table, column string, rowCount int64, sampleRows int) (min, max, avg sql.NullFloat64, err error) {
+	query := fmt.Sprintf("SELECT MIN(v), MAX(v), AVG(v) FROM (SELECT %s AS v FROM %s WHERE %s IS NOT NULL ORDER BY RAND() LIMIT %d) AS sampled",
+		column, table, column, sampleRows)
+	err = db.QueryRowContext(ctx, query).Scan(&min, &max, &avg)
+	return min, max, avg, err
+}
+
+// JSONKeys reads JSON_KEYS arrays, one per document.
+func (mysqlDialect) JSONKeys(ctx context.Context, db *sql.DB, table string, column core.ColumnInfo, limit int) ([]string, error) {
+	query := fmt.Sprintf("SELECT JSON_KEYS(%s) FROM %s WHERE %s IS NOT NULL AND JSON_TYPE(%s) = 'OBJECT' LIMIT %d",
+		column.Name, table, column.Name, column.Name, limit)
+	return queryJSONKeys(ctx, db, query)
+}
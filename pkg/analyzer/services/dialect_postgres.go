@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/cherry-pick/pkg/analyzer/core"
+)
+
+type postgresDialect struct{}
+
+func (postgresDialect) TableNames(ctx context.Context, db *sql.DB, schemas []string) ([]string, error) {
+	if len(schemas) == 0 {
+		return queryTableNames(ctx, db, "SELECT tablename FROM pg_tables WHERE schemaname = 'public'", nil, nil)
+	}
+
+	in, args := schemaPlaceholders(schemas, func(i int) string { return fmt.Sprintf("$%d", i+1) })
+	query := `SELECT schemaname, tablename FROM pg_tables
+			WHERE schemaname IN (` + in + `)
+			ORDER BY schemaname, tablename`
+	return queryTableNames(ctx, db, query, args, schemas)
+}
+
+func (postgresDialect) Columns(ctx context.Context, db *sql.DB, schema, table string) ([]core.ColumnInfo, error) {
+	ref := tableRef{schema: schema, name: table}
+	schemaExpr, schemaArgs := ref.schemaExpr(core.DatabaseTypePostgres, "$2")
+	query := `
+			SELECT
+				column_name, data_type, is_nullable, column_default,
+				character_maximum_length, numeric_precision, numeric_scale,
+				CASE WHEN column_name IN (
+					SELECT column_name FROM information_schema.table_constraints tc
+					JOIN information_schema.key_column_usage kcu
+						ON tc.constraint_name = kcu.constraint_name AND tc.table_schema = kcu.table_schema
+					WHERE tc.table_name = $1 AND tc.table_schema = ` + schemaExpr + ` AND tc.constraint_type = 'PRIMARY KEY'
+				) THEN 'PRI' ELSE '' END as column_key,
+				col_description(format('%I.%I', table_schema, table_name)::regclass, ordinal_position) as column_comment
+			FROM information_schema.columns
+			WHERE table_name = $1 AND table_schema = ` + schemaExpr + `
+			ORDER BY ordinal_position`
+
+	rows, err := db.QueryContext(ctx, query, append([]interface{}{table}, schemaArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns: %w", err)
+	}
+	return scanCatalogColumns(rows)
+}
+
+func (postgresDialect) Indexes(ctx context.Context, db *sql.DB, schema, table string) ([]core.IndexInfo, error) {
+	ref := tableRef{schema: schema, name: table}
+	schemaExpr, schemaArgs := ref.schemaExpr(core.DatabaseTypePostgres, "$2")
+	query := `
+			SELECT
+				indexname,
+				indexdef,
+				CASE WHEN indisunique THEN true ELSE false END as is_unique
+			FROM pg_indexes
+			JOIN pg_namespace ON pg_namespace.nspname = pg_indexes.schemaname
+			JOIN pg_class ON pg_class.relname = indexname AND pg_class.relnamespace = pg_namespace.oid
+			JOIN pg_index ON pg_index.indexrelid = pg_class.oid
+			WHERE tablename = $1 AND schemaname = ` + schemaExpr
+
+	rows, err := db.QueryContext(ctx, query, append([]interface{}{table}, schemaArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %w", err)
+	}
+	defer rows.Close()
+
+	indexes := []core.IndexInfo{}
+	for rows.Next() {
+		var index core.IndexInfo
+		var indexDef string
+		if err := rows.Scan(&index.Name, &indexDef, &index.IsUnique); err != nil {
+			continue
+		}
+		index.Type = "btree"
+		index.Columns = []string{"parsed_from_def"}
+
+		if index.Name != "" {
+			indexes = append(indexes, index)
+		}
+	}
+	return indexes, rows.Err()
+}
+
+func (postgresDialect) Constraints(ctx context.Context, db *sql.DB, schema, table string) ([]core.Constraint, error) {
+	ref := tableRef{schema: schema, name: table}
+	schemaExpr, schemaArgs := ref.schemaExpr(core.DatabaseTypePostgres, "$2")
+	query := `
+			SELECT
+				tc.constraint_name, tc.constraint_type, kcu.column_name
+			FROM information_schema.table_constraints tc
+			LEFT JOIN information_schema.key_column_usage kcu
+				ON tc.constraint_name = kcu.constraint_name
+				AND tc.table_name = kcu.table_name
+				AND tc.table_schema = kcu.table_schema
+			WHERE tc.table_name = $1 AND tc.table_schema = ` + schemaExpr
+
+	rows, err := db.QueryContext(ctx, query, append([]interface{}{table}, schemaArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query constraints: %w", err)
+	}
+	return scanCatalogConstraints(rows)
+}
+
+func (postgresDialect) Relationships(ctx context.Context, db *sql.DB, schema, table string) ([]core.Relationship, error) {
+	ref := tableRef{schema: schema, name: table}
+	schemaExpr, schemaArgs := ref.schemaExpr(core.DatabaseTypePostgres, "$2")
+	query := `
+			SELECT
+				kcu.column_name,
+				ccu.table_schema AS referenced_schema,
+				ccu.table_name AS referenced_table,
+				ccu.column_name AS referenced_column,
+				rc.update_rule,
+				rc.delete_rule
+			FROM information_schema.key_column_usage kcu
+			JOIN information_schema.referential_constraints rc
+				ON kcu.constraint_name = rc.constraint_name
+				AND kcu.constraint_schema = rc.constraint_schema
+			JOIN information_schema.constraint_column_usage ccu
+				ON rc.unique_constraint_name = ccu.constraint_name
+				AND rc.unique_constraint_schema = ccu.constraint_schema
+			WHERE kcu.table_name = $1 AND kcu.table_schema = ` + schemaExpr
+
+	rows, err := db.QueryContext(ctx, query, append([]interface{}{table}, schemaArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query relationships: %w", err)
+	}
+	return scanCatalogRelationships(rows, ref)
+}
+
+func (postgresDialect) TableSize(ctx context.Context, db *sql.DB, schema, table string) (string, error) {
+	ref := tableRef{schema: schema, name: table}
+
+	var sizeResult interface{}
+	err := db.QueryRowContext(ctx, `SELECT pg_size_pretty(pg_total_relation_size($1)) AS size`, ref.qualify(table)).Scan(&sizeResult)
+	if err != nil {
+		return "Unknown", fmt.Errorf("failed to get table size: %w", err)
+	}
+	if size, ok := sizeResult.(string); ok {
+		return size, nil
+	}
+	return "Unknown", nil
+}
+
+func (postgresDialect) TableComment(ctx context.Context, db *sql.DB, schema, table string) (string, error) {
+	ref := tableRef{schema: schema, name: table}
+	schemaExpr, schemaArgs := ref.schemaExpr(core.DatabaseTypePostgres, "$2")
+	query := `
+			SELECT obj_description(c.oid, 'pg_class')
+			FROM pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE c.relname = $1 AND n.nspname = ` + schemaExpr
+	return queryTableComment(ctx, db, query, append([]interface{}{table}, schemaArgs...))
+}
+
+func (postgresDialect) PerformanceMetrics(ctx context.Context, db *sql.DB) (*core.PerformanceMetrics, error) {
+	metrics := &core.PerformanceMetrics{}
+
+	query := "SELECT count(*) FROM pg_stat_activity"
+	var connections int
+	err := db.QueryRowContext(ctx, query).Scan(&connections)
+	if err == nil {
+		metrics.Connections.Current = connections
+	}
+
+	return metrics, nil
+}
+
+func (postgresDialect) SampledNumericAggregates(ctx context.Context, db *sql.DB, table, column string, rowCount int64, sampleRows int) (min, max, avg sql.NullFloat64, err error) {
+	percent := float64(sampleRows) / float64(rowCount) * 100
+	query := fmt.Sprintf("SELECT MIN(%s), MAX(%s), AVG(%s) FROM %s TABLESAMPLE SYSTEM (%.4f) WHERE %s IS NOT NULL",
+		column, column, column, table, percent, column)
+	err = db.QueryRowContext(ctx, query).Scan(&min, &max, &avg)
+	return min, max, avg, err
+}
+
+func (postgresDialect) JSONKeys(ctx context.Context, db *sql.DB, table string, column core.ColumnInfo, limit int) ([]string, error) {
+	keysFunc, typeofFunc := "json_object_keys", "json_typeof"
+	if strings.EqualFold(column.DataType, "jsonb") {
+		keysFunc, typeofFunc = "jsonb_object_keys", "jsonb_typeof"
+	}
+	query := fmt.Sprintf("SELECT DISTINCT %s(v) FROM (SELECT %s AS v FROM %s WHERE %s IS NOT NULL AND %s(%s) = 'object' LIMIT %d) AS sampled",
+		keysFunc, column.Name, table, column.Name, typeofFunc, column.Name, limit)
+	return queryJSONKeys(ctx, db, query)
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/cherry-pick/pkg/analyzer/core"
+	"github.com/cherry-pick/pkg/logging"
+)
+
+type sqliteDialect struct{}
+
+// TableNames queries each requested schema on its own, since SQLite has no catalog
+// spanning attached databases.
+func (sqliteDialect) TableNames(ctx context.Context, db *sql.DB, schemas []string) ([]string, error) {
+	if len(schemas) == 0 {
+		return queryTableNames(ctx, db, "SELECT name FROM sqlite_master WHERE type='table'", nil, nil)
+	}
+
+	parts := make([]string, len(schemas))
+	for i, schema := range schemas {
+		parts[i] = fmt.Sprintf("SELECT '%s', name FROM %s.sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%%'", schema, schema)
+	}
+	return queryTableNames(ctx, db, strings.Join(parts, " UNION ALL ")+" ORDER BY 1, 2", nil, schemas)
+}
+
+func (sqliteDialect) Columns(ctx context.Context, db *sql.DB, schema, table string) ([]core.ColumnInfo, error) {
+	ref := tableRef{schema: schema, name: table}
+	rows, err := db.QueryContext(ctx, ref.sqlitePragma("table_info", table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns: %w", err)
+	}
+	defer rows.Close()
+
+	columns := []core.ColumnInfo{}
+	for rows.Next() {
+		var col core.ColumnInfo
+		var cid, notNull, pk int
+		var defaultVal sql.NullString
+		if err := rows.Scan(&cid, &col.Name, &col.DataType, &notNull, &defaultVal, &pk); err != nil {
+			return nil, fmt.Errorf("failed to scan column info: %w", err)
+		}
+		col.IsNullable = notNull == 0
+		col.IsPrimaryKey = pk == 1
+		if defaultVal.Valid {
+			col.DefaultValue = defaultVal.String
+		}
+		columns = append(columns, col)
+	}
+	return columns, rows.Err()
+}
+
+func (sqliteDialect) Indexes(ctx context.Context, db *sql.DB, schema, table string) ([]core.IndexInfo, error) {
+	ref := tableRef{schema: schema, name: table}
+	rows, err := db.QueryContext(ctx, ref.sqlitePragma("index_list", table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %w", err)
+	}
+	defer rows.Close()
+
+	indexes := []core.IndexInfo{}
+	for rows.Next() {
+		var index core.IndexInfo
+		var seq, unique, partial int
+		var origin string
+		if err := rows.Scan(&seq, &index.Name, &unique, &origin, &partial); err != nil {
+			continue
+		}
+		index.IsUnique = unique == 1
+		index.Type = "btree"
+
+		colRows, colErr := db.QueryContext(ctx, ref.sqlitePragma("index_info", index.Name))
+		if colErr == nil {
+			var columns []string
+			for colRows.Next() {
+				var seqno, cid int
+				var name string
+				if colRows.Scan(&seqno, &cid, &name) == nil {
+					columns = append(columns, name)
+				}
+			}
+			colRows.Close()
+			index.Columns = columns
+		}
+
+		if index.Name != "" {
+			indexes = append(indexes, index)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return indexes, err
+	}
+
+	for i := range indexes {
+		if size, exact, _ := sqliteObjectSize(ctx, db, schema, indexes[i].Name); exact {
+			indexes[i].Size = formatByteSize(size)
+		}
+	}
+	return indexes, nil
+}
+
+// Constraints reports SQLite's foreign keys; other constraints are not in a pragma.
+func (sqliteDialect) Constraints(ctx context.Context, db *sql.DB, schema, table string) ([]core.Constraint, error) {
+	ref := tableRef{schema: schema, name: table}
+	rows, err := db.QueryContext(ctx, ref.sqlitePragma("foreign_key_list", table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query constraints: %w", err)
+	}
+	defer rows.Close()
+
+	constraints := []core.Constraint{}
+	for rows.Next() {
+		var id, seq int
+		var target, from, to, onUpdate, onDelete, match string
+		if err := rows.Scan(&id, &seq, &target, &from, &to, &onUpdate, &onDelete, &match); err != nil {
+			continue
+		}
+		constraints = append(constraints, core.Constraint{
+			Name:       fmt.Sprintf("fk_%d", id),
+			Type:       "FOREIGN KEY",
+			Columns:    []string{from},
+			RefTable:   ref.qualify(target),
+			RefColumns: []string{to},
+		})
+	}
+	return constraints, rows.Err()
+}
+
+func (sqliteDialect) Relationships(ctx context.Context, db *sql.DB, schema, table string) ([]core.Relationship, error) {
+	ref := tableRef{schema: schema, name: table}
+	rows, err := db.QueryContext(ctx, ref.sqlitePragma("foreign_key_list", table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query relationships: %w", err)
+	}
+	defer rows.Close()
+
+	relationships := []core.Relationship{}
+	for rows.Next() {
+		var rel core.Relationship
+		var id, seq int
+		var onUpdate, onDelete, match string
+		if err := rows.Scan(&id, &seq, &rel.TargetTable, &rel.SourceColumn,
+			&rel.TargetColumn, &onUpdate, &onDelete, &match); err != nil {
+			continue
+		}
+		rel.TargetTable = ref.qualify(rel.TargetTable)
+		rel.Type = "FOREIGN KEY"
+
+		if rel.TargetTable != "" {
+			relationships = append(relationships, rel)
+		}
+	}
+	return relationships, rows.Err()
+}
+
+// TableSize reads the exact size from dbstat and estimates it from the page count when
+// SQLite was built without dbstat.
+func (sqliteDialect) TableSize(ctx context.Context, db *sql.DB, schema, table string) (string, error) {
+	size, exact, err := sqliteObjectSize(ctx, db, schema, table)
+	if !exact {
+		logging.Default().Debug("dbstat unavailable, estimating table size from page count", "table", table, "error", err)
+		size, err = sqliteEstimatedTableSize(ctx, db, schema, table)
+		if err != nil {
+			return "Unknown", fmt.Errorf("failed to get table size: %w", err)
+		}
+	}
+	return formatByteSize(size), nil
+}
+
+func (sqliteDialect) PerformanceMetrics(ctx context.Context, db *sql.DB) (*core.PerformanceMetrics, error) {
+	return &core.PerformanceMetrics{}, nil
+}
+
+func (sqliteDialect) SampledNumericAggregates(ctx context.Context, db *sql.DB, table, column string, rowCount int64, sampleRows int) (min, max, avg sql.NullFloat64, err error) {
+	query := fmt.Sprintf("SELECT MIN(v), MAX(v), AVG(v) FROM (SELECT %s AS v FROM %s WHERE %s IS NOT NULL ORDER BY RANDOM() LIMIT %d)",
+		column, table, column, sampleRows)
+	err = db.QueryRowContext(ctx, query).Scan(&min, &max, &avg)
+	return min, max, avg, err
+}
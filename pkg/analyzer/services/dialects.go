@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cherry-pick/pkg/analyzer/core"
+)
+
+// DialectRegistry maps SQL database types to the Dialect that analyzes them.
+type DialectRegistry struct {
+	mu       sync.RWMutex
+	dialects map[core.DatabaseType]core.Dialect
+}
+
+var defaultDialects = NewDialectRegistry()
+
+// NewDialectRegistry returns a registry holding the built-in MySQL, PostgreSQL and
+// SQLite dialects.
+func NewDialectRegistry() *DialectRegistry {
+	return &DialectRegistry{
+		dialects: map[core.DatabaseType]core.Dialect{
+			core.DatabaseTypeMySQL:    mysqlDialect{},
+			core.DatabaseTypePostgres: postgresDialect{},
+			core.DatabaseTypeSQLite:   sqliteDialect{},
+		},
+	}
+}
+
+// DefaultDialects is the registry analyzers and validators use unless given another.
+func DefaultDialects() *DialectRegistry {
+	return defaultDialects
+}
+
+// RegisterDialect adds dialect to the default registry, replacing any dialect already
+// registered for dbType.
+func RegisterDialect(dbType core.DatabaseType, dialect core.Dialect) error {
+	return defaultDialects.Register(dbType, dialect)
+}
+
+func (r *DialectRegistry) Register(dbType core.DatabaseType, dialect core.Dialect) error {
+	if dbType == "" {
+		return fmt.Errorf("dialect database type is required")
+	}
+	if dbType == core.DatabaseTypeMongoDB {
+		return fmt.Errorf("%s is analyzed by the MongoDB analyzer and cannot have a SQL dialect", dbType)
+	}
+	if dialect == nil {
+		return fmt.Errorf("dialect for %s is nil", dbType)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dialects[dbType] = dialect
+	return nil
+}
+
+func (r *DialectRegistry) Lookup(dbType core.DatabaseType) (core.Dialect, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	dialect, ok := r.dialects[dbType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported database type: %s", dbType)
+	}
+	return dialect, nil
+}
+
+// Types lists the registered database types in name order.
+func (r *DialectRegistry) Types() []core.DatabaseType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]core.DatabaseType, 0, len(r.dialects))
+	for dbType := range r.dialects {
+		types = append(types, dbType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// queryTableNames runs a table listing query. With schemas it expects schema, name
+// pairs and qualifies each name with its schema.
+func queryTableNames(ctx context.Context, db *sql.DB, query string, args []interface{}, schemas []string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table names: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var schema, tableName string
+		if len(schemas) > 0 {
+			err = rows.Scan(&schema, &tableName)
+		} else {
+			err = rows.Scan(&tableName)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan table name: %w", err)
+		}
+		tables = append(tables, tableRef{schema: schema}.qualify(tableName))
+	}
+	return tables, rows.Err()
+}
+
+// schemaPlaceholders binds schemas as the arguments of an IN list.
+func schemaPlaceholders(schemas []string, placeholder func(i int) string) (string, []interface{}) {
+	args := make([]interface{}, len(schemas))
+	placeholders := make([]string, len(schemas))
+	for i, schema := range schemas {
+		args[i] = schema
+		placeholders[i] = placeholder(i)
+	}
+	return strings.Join(placeholders, ", "), args
+}
+
+// scanCatalogColumns reads the information_schema column listing shared by the MySQL
+// and PostgreSQL dialects.
+func scanCatalogColumns(rows *sql.Rows) ([]core.ColumnInfo, error) {
+	defer rows.Close()
+
+	columns := []core.ColumnInfo{}
+	for rows.Next() {
+		var col core.ColumnInfo
+		var maxLength, precision, scale sql.NullInt64
+		var defaultVal, comment sql.NullString
+		var nullable, columnKey string
+
+		if err := rows.Scan(&col.Name, &col.DataType, &nullable, &defaultVal,
+			&maxLength, &precision, &scale, &columnKey, &comment); err != nil {
+			return nil, fmt.Errorf("failed to scan column info: %w", err)
+		}
+		col.IsNullable = nullable == "YES"
+		col.IsPrimaryKey = columnKey == "PRI"
+
+		if defaultVal.Valid {
+			col.DefaultValue = defaultVal.String
+		}
+		if comment.Valid {
+			col.Comment = comment.String
+		}
+		if maxLength.Valid {
+			col.MaxLength = int(maxLength.Int64)
+		}
+		if precision.Valid {
+			col.Precision = int(precision.Int64)
+		}
+		if scale.Valid {
+			col.Scale = int(scale.Int64)
+		}
+		columns = append(columns, col)
+	}
+	return columns, rows.Err()
+}
+
+func scanCatalogConstraints(rows *sql.Rows) ([]core.Constraint, error) {
+	defer rows.Close()
+
+	constraints := []core.Constraint{}
+	for rows.Next() {
+		var constraint core.Constraint
+		var columnName sql.NullString
+		if err := rows.Scan(&constraint.Name, &constraint.Type, &columnName); err != nil {
+			continue
+		}
+		if columnName.Valid {
+			constraint.Columns = []string{columnName.String}
+		}
+		if constraint.Name != "" {
+			constraints = append(constraints, constraint)
+		}
+	}
+	return constraints, rows.Err()
+}
+
+// scanCatalogRelationships qualifies targets with their own schema when ref names one,
+// since it may differ from the referencing table's.
+func scanCatalogRelationships(rows *sql.Rows, ref tableRef) ([]core.Relationship, error) {
+	defer rows.Close()
+
+	relationships := []core.Relationship{}
+	for rows.Next() {
+		var rel core.Relationship
+		var targetSchema, updateRule, deleteRule sql.NullString
+		if err := rows.Scan(&rel.SourceColumn, &targetSchema, &rel.TargetTable,
+			&rel.TargetColumn, &updateRule, &deleteRule); err != nil {
+			continue
+		}
+		if ref.schema != "" {
+			rel.TargetTable = tableRef{schema: targetSchema.String}.qualify(rel.TargetTable)
+		}
+		rel.Type = "FOREIGN KEY"
+
+		if rel.TargetTable != "" {
+			relationships = append(relationships, rel)
+		}
+	}
+	return relationships, rows.Err()
+}
+
+func queryTableComment(ctx context.Context, db *sql.DB, query string, args []interface{}) (string, error) {
+	var comment sql.NullString
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&comment); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+	return comment.String, nil
+}
+
+// queryJSONKeys runs a JSON key listing query whose rows each hold either one key or a
+// JSON array of keys, and returns the distinct keys in name order.
+func queryJSONKeys(ctx context.Context, db *sql.DB, query string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var value sql.NullString
+		if err := rows.Scan(&value); err != nil || !value.Valid {
+			continue
+		}
+
+		var keys []string
+		if json.Unmarshal([]byte(value.String), &keys) != nil {
+			keys = []string{value.String}
+		}
+		for _, key := range keys {
+			seen[key] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
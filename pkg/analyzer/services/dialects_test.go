@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/cherry-pick/pkg/analyzer/core"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const fakeDatabaseType core.DatabaseType = "fakedb"
+
+// fakeDialect implements only the required Dialect methods, as a third-party dialect
+// registered without any of the optional interfaces would.
+type fakeDialect struct{}
+
+func (fakeDialect) TableNames(ctx context.Context, db *sql.DB, schemas []string) ([]string, error) {
+	return []string{"orders"}, nil
+}
+
+func (fakeDialect) Columns(ctx context.Context, db *sql.DB, schema, table string) ([]core.ColumnInfo, error) {
+	return []core.ColumnInfo{{Name: "amount", DataType: "integer"}}, nil
+}
+
+func (fakeDialect) Indexes(ctx context.Context, db *sql.DB, schema, table string) ([]core.IndexInfo, error) {
+	return nil, nil
+}
+
+func (fakeDialect) Constraints(ctx context.Context, db *sql.DB, schema, table string) ([]core.Constraint, error) {
+	return nil, nil
+}
+
+func (fakeDialect) Relationships(ctx context.Context, db *sql.DB, schema, table string) ([]core.Relationship, error) {
+	return nil, nil
+}
+
+func (fakeDialect) TableSize(ctx context.Context, db *sql.DB, schema, table string) (string, error) {
+	return "Unknown", nil
+}
+
+func (fakeDialect) PerformanceMetrics(ctx context.Context, db *sql.DB) (*core.PerformanceMetrics, error) {
+	return &core.PerformanceMetrics{}, nil
+}
+
+// samplingDialect adds the optional sampling and JSON key interfaces to fakeDialect.
+type samplingDialect struct {
+	fakeDialect
+}
+
+func (samplingDialect) SampledNumericAggregates(ctx context.Context, db *sql.DB, table, column string, rowCount int64, sampleRows int) (min, max, avg sql.NullFloat64, err error) {
+	return sql.NullFloat64{Float64: 1, Valid: true}, sql.NullFloat64{Float64: 9, Valid: true}, sql.NullFloat64{Float64: 5, Valid: true}, nil
+}
+
+func (samplingDialect) JSONKeys(ctx context.Context, db *sql.DB, table string, column core.ColumnInfo, limit int) ([]string, error) {
+	return []string{"id", "name"}, nil
+}
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	statements := []string{
+		"CREATE TABLE orders (amount INTEGER)",
+		"INSERT INTO orders (amount) VALUES (2), (4), (6), (NULL)",
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	return db
+}
+
+func TestDialectRegistryRegisterAndLookup(t *testing.T) {
+	registry := NewDialectRegistry()
+	if err := registry.Register(fakeDatabaseType, fakeDialect{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	dialect, err := registry.Lookup(fakeDatabaseType)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := dialect.(fakeDialect); !ok {
+		t.Errorf("Expected the registered fake dialect, got %T", dialect)
+	}
+
+	want := []core.DatabaseType{fakeDatabaseType, core.DatabaseTypeMySQL, core.DatabaseTypePostgres, core.DatabaseTypeSQLite}
+	if got := registry.Types(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if _, err := DefaultDialects().Lookup(fakeDatabaseType); err == nil {
+		t.Errorf("Expected the default registry to be unaffected")
+	}
+}
+
+func TestDialectRegistryRejectsInvalidDialects(t *testing.T) {
+	registry := NewDialectRegistry()
+
+	tests := []struct {
+		name    string
+		dbType  core.DatabaseType
+		dialect core.Dialect
+	}{
+		{"empty type", "", fakeDialect{}},
+		{"mongodb", core.DatabaseTypeMongoDB, fakeDialect{}},
+		{"nil dialect", fakeDatabaseType, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := registry.Register(tt.dbType, tt.dialect); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
+
+func TestDialectRegistryLookupUnknown(t *testing.T) {
+	if _, err := NewDialectRegistry().Lookup(fakeDatabaseType); err == nil {
+		t.Errorf("Expected an error for an unregistered database type")
+	}
+}
+
+func TestValidatorAcceptsRegisteredDialect(t *testing.T) {
+	registry := NewDialectRegistry()
+	validator := NewValidatorService()
+	validator.SetDialects(registry)
+
+	if err := validator.ValidateDatabaseType(fakeDatabaseType); err == nil {
+		t.Fatalf("Expected an error before the dialect is registered")
+	}
+	if err := registry.Register(fakeDatabaseType, fakeDialect{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := validator.ValidateDatabaseType(fakeDatabaseType); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestNumericAggregatesWithoutSamplingDialect(t *testing.T) {
+	db := openTestDB(t)
+
+	min, max, avg, sampled, err := numericAggregates(context.Background(), db, fakeDialect{}, "orders", "amount", core.LargeTableRowThreshold+1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if sampled {
+		t.Errorf("Expected a dialect without sampling support to aggregate the full table")
+	}
+	if min.Float64 != 2 || max.Float64 != 6 || avg.Float64 != 4 {
+		t.Errorf("Expected min 2, max 6, avg 4, got %v, %v, %v", min.Float64, max.Float64, avg.Float64)
+	}
+}
+
+func TestNumericAggregatesUsesSamplingDialect(t *testing.T) {
+	db := openTestDB(t)
+
+	min, max, avg, sampled, err := numericAggregates(context.Background(), db, samplingDialect{}, "orders", "amount", core.LargeTableRowThreshold+1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !sampled {
+		t.Errorf("Expected the dialect's sampler to be used for a large table")
+	}
+	if min.Float64 != 1 || max.Float64 != 9 || avg.Float64 != 5 {
+		t.Errorf("Expected the sampler's values, got %v, %v, %v", min.Float64, max.Float64, avg.Float64)
+	}
+}
+
+func TestNumericAggregatesSmallTableIgnoresSampler(t *testing.T) {
+	db := openTestDB(t)
+
+	_, max, _, sampled, err := numericAggregates(context.Background(), db, samplingDialect{}, "orders", "amount", 3)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if sampled || max.Float64 != 6 {
+		t.Errorf("Expected a full aggregate for a small table, got sampled=%v max=%v", sampled, max.Float64)
+	}
+}
+
+func TestQueryJSONKeysRequiresDialectSupport(t *testing.T) {
+	das := NewDatabaseAnalyzerService(nil, nil, nil, nil)
+	options := newColumnProfileOptions(core.AnalysisOptions{}, 0)
+	column := core.ColumnInfo{Name: "payload", DataType: "json"}
+
+	if keys := das.queryJSONKeys(context.Background(), nil, fakeDialect{}, "events", column, options); keys != nil {
+		t.Errorf("Expected no keys from a dialect without JSON support, got %v", keys)
+	}
+
+	keys := das.queryJSONKeys(context.Background(), nil, samplingDialect{}, "events", column, options)
+	if want := []string{"id", "name"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("Expected %v, got %v", want, keys)
+	}
+}
+
+func TestBuiltInDialectsImplementOptionalInterfaces(t *testing.T) {
+	for _, dbType := range DefaultDialects().Types() {
+		dialect, _ := DefaultDialects().Lookup(dbType)
+		if _, ok := dialect.(core.NumericSampleDialect); !ok {
+			t.Errorf("Expected %s to support sampled aggregates", dbType)
+		}
+	}
+	for _, dbType := range []core.DatabaseType{core.DatabaseTypeMySQL, core.DatabaseTypePostgres} {
+		dialect, _ := DefaultDialects().Lookup(dbType)
+		if _, ok := dialect.(core.JSONKeysDialect); !ok {
+			t.Errorf("Expected %s to list JSON keys", dbType)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cherry-pick/pkg/analyzer/core"
+)
+
+const (
+	jsonKeySourceDatabase = "database"
+	jsonKeySourceSample   = "sample"
+)
+
+func (das *DatabaseAnalyzerService) isJSONColumn(column core.ColumnInfo) bool {
+	if strings.Contains(strings.ToLower(column.DataType), "json") {
+		return true
+	}
+
+	if das.connector.GetDatabaseType() != core.DatabaseTypeSQLite || das.isNumericType(column.DataType) {
+		return false
+	}
+
+	samples := column.DataProfile.SampleData
+	if len(samples) == 0 {
+		return false
+	}
+	for _, sample := range samples {
+		trimmed := strings.TrimSpace(sample)
+		if !(strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) || !json.Valid([]byte(trimmed)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (das *DatabaseAnalyzerService) analyzeJSONColumn(ctx context.Context, db *sql.DB, dialect core.Dialect, tableName string, column core.ColumnInfo, options columnProfileOptions) *core.JSONSchemaSummary {
+	documents := das.sampleJSONDocuments(ctx, db, tableName, column.Name, options)
+	summary := inferJSONSchema(documents)
+
+	if keys := das.queryJSONKeys(ctx, db, dialect, tableName, column, options); len(keys) > 0 {
+		summary.TopLevelKeys = keys
+		summary.KeySource = jsonKeySourceDatabase
+	}
+
+	return summary
+}
+
+func (das *DatabaseAnalyzerService) sampleJSONDocuments(ctx context.Context, db *sql.DB, tableName, columnName string, options columnProfileOptions) []string {
+	queryCtx, cancel := context.WithTimeout(ctx, options.timeout)
+	defer cancel()
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s IS NOT NULL LIMIT %d",
+		columnName, tableName, columnName, options.sampleSize)
+
+	rows, err := db.QueryContext(queryCtx, query)
+	if err != nil {
+		das.logger.Debug("JSON sample query failed", "table", tableName, "column", columnName, "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var documents []string
+	for rows.Next() {
+		var value sql.NullString
+		if err := rows.Scan(&value); err != nil {
+			continue
+		}
+		if value.Valid {
+			documents = append(documents, value.String)
+		}
+	}
+	return documents
+}
+
+// queryJSONKeys asks the database for top-level keys when its dialect has a native
+// function for it.
+func (das *DatabaseAnalyzerService) queryJSONKeys(ctx context.Context, db *sql.DB, dialect core.Dialect, tableName string, column core.ColumnInfo, options columnProfileOptions) []string {
+	lister, ok := dialect.(core.JSONKeysDialect)
+	if !ok {
+		return nil
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, options.timeout)
+	defer cancel()
+
+	keys, err := lister.JSONKeys(queryCtx, db, tableName, column, options.sampleSize)
+	if err != nil {
+		das.logger.Debug("JSON key query failed", "table", tableName, "column", column.Name, "error", err)
+		return nil
+	}
+	return keys
+}
+
+type jsonFieldStats struct {
+	types map[string]bool
+	docs  int
+}
+
+func inferJSONSchema(documents []string) *core.JSONSchemaSummary {
+	summary := &core.JSONSchemaSummary{
+		TopLevelKeys: []string{},
+		KeySource:    jsonKeySourceSample,
+		Fields:       []core.JSONFieldInfo{},
+	}
+
+	fields := make(map[string]*jsonFieldStats)
+	topLevel := make(map[string]bool)
+
+	for _, document := range documents {
+		var value interface{}
+		if err := json.Unmarshal([]byte(document), &value); err != nil {
+			continue
+		}
+		summary.SampledDocuments++
+
+		if object, ok := value.(map[string]interface{}); ok {
+			for key := range object {
+				topLevel[key] = true
+			}
+		}
+
+		seen := make(map[string]bool)
+		walkJSON(value, "$", fields, seen)
+		for path := range seen {
+			fields[path].docs++
+		}
+	}
+
+	for key := range topLevel {
+		summary.TopLevelKeys = append(summary.TopLevelKeys, key)
+	}
+	sort.Strings(summary.TopLevelKeys)
+
+	if summary.SampledDocuments == 0 {
+		return summary
+	}
+
+	for path, stats := range fields {
+		types := make([]string, 0, len(stats.types))
+		for t := range stats.types {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+
+		summary.Fields = append(summary.Fields, core.JSONFieldInfo{
+			Path:      path,
+			Types:     types,
+			Frequency: float64(stats.docs) / float64(summary.SampledDocuments),
+		})
+	}
+
+	sort.Slice(summary.Fields, func(i, j int) bool {
+		return summary.Fields[i].Path < summary.Fields[j].Path
+	})
+	if len(summary.Fields) > core.MaxJSONSchemaFields {
+		summary.Fields = summary.Fields[:core.MaxJSONSchemaFields]
+		summary.Truncated = true
+	}
+
+	return summary
+}
+
+func walkJSON(value interface{}, path string, fields map[string]*jsonFieldStats, seen map[string]bool) {
+	stats, exists := fields[path]
+	if !exists {
+		stats = &jsonFieldStats{types: make(map[string]bool)}
+		fields[path] = stats
+	}
+	stats.types[jsonValueType(value)] = true
+	seen[path] = true
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			walkJSON(child, path+"."+key, fields, seen)
+		}
+	case []interface{}:
+		for _, element := range v {
+			walkJSON(element, path+"[]", fields, seen)
+		}
+	}
+}
+
+func jsonValueType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return "unknown"
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"github.com/cherry-pick/pkg/analyzer/core"
+	"github.com/cherry-pick/pkg/tracing"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -46,7 +48,7 @@ func (mas *MongoAnalyzerService) AnalyzeDatabase(ctx context.Context, request co
 	startTime := time.Now()
 	log.Printf("Starting MongoDB analysis for %s", request.DatabaseType)
 
-	collections, err := mas.AnalyzeCollections(ctx, request)
+	collections, warnings, truncation, err := mas.analyzeCollections(ctx, request)
 	if err != nil {
 		return nil, fmt.Errorf("failed to analyze collections: %w", err)
 	}
@@ -58,6 +60,7 @@ func (mas *MongoAnalyzerService) AnalyzeDatabase(ctx context.Context, request co
 
 	tables := mas.convertCollectionsToTables(collections)
 	summary := mas.generateSummary(collections, dbStats)
+	summary.FailedTables = len(warnings)
 	insights := mas.generateInsights(collections, dbStats)
 	recommendations := mas.generateRecommendations(insights)
 
@@ -79,6 +82,8 @@ func (mas *MongoAnalyzerService) AnalyzeDatabase(ctx context.Context, request co
 		Insights:       insights,
 		Recommendations: recommendations,
 		Performance:    performance,
+		Warnings:       warnings,
+		Truncation:     truncation,
 	}
 
 	log.Printf("MongoDB analysis completed in %v", time.Since(startTime))
@@ -86,32 +91,64 @@ func (mas *MongoAnalyzerService) AnalyzeDatabase(ctx context.Context, request co
 }
 
 func (mas *MongoAnalyzerService) AnalyzeCollections(ctx context.Context, request core.AnalysisRequest) ([]core.MongoCollectionInfo, error) {
-	collectionNames, err := mas.GetCollectionNames(ctx, request)
+	collections, _, _, err := mas.analyzeCollections(ctx, request)
+	return collections, err
+}
+
+// analyzeCollections skips collections that fail to analyze and returns a warning for each.
+// The truncation is non-nil when MaxCollections left collections out.
+func (mas *MongoAnalyzerService) analyzeCollections(ctx context.Context, request core.AnalysisRequest) ([]core.MongoCollectionInfo, []core.TableWarning, *core.ObjectTruncation, error) {
+	collectionNames, truncation, err := mas.listCollectionNames(ctx, request)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get collection names: %w", err)
+	}
+
+	sharded, err := mas.getShardedCollections(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get collection names: %w", err)
+		log.Printf("Warning: Could not determine sharded collections: %v", err)
 	}
 
-	var collections []core.MongoCollectionInfo
+	collections := []core.MongoCollectionInfo{}
+	var warnings []core.TableWarning
 	for _, name := range collectionNames {
 		log.Printf("Analyzing collection: %s", name)
 
 		collection, err := mas.AnalyzeCollection(ctx, name, request)
 		if err != nil {
 			log.Printf("Warning: Failed to analyze collection %s: %v", name, err)
+			warnings = append(warnings, core.TableWarning{Table: name, Error: err.Error()})
 			continue
 		}
+		if shardKey, ok := sharded[name]; ok {
+			collection.IsSharded = true
+			collection.ShardKey = shardKey
+		}
 		collections = append(collections, *collection)
 	}
 
-	return collections, nil
+	return collections, warnings, truncation, nil
 }
 
 func (mas *MongoAnalyzerService) AnalyzeCollection(ctx context.Context, collectionName string, request core.AnalysisRequest) (*core.MongoCollectionInfo, error) {
+	ctx, span := tracing.Start(ctx, "analyzer.AnalyzeCollection",
+		tracing.AttrDatabaseType.String(string(core.DatabaseTypeMongoDB)),
+		tracing.AttrTableName.String(collectionName))
+
+	collInfo, err := mas.analyzeCollection(ctx, collectionName, request)
+	if collInfo != nil {
+		span.SetAttributes(tracing.AttrRowCount.Int64(collInfo.DocumentCount))
+	}
+	tracing.End(span, err)
+
+	return collInfo, err
+}
+
+func (mas *MongoAnalyzerService) analyzeCollection(ctx context.Context, collectionName string, request core.AnalysisRequest) (*core.MongoCollectionInfo, error) {
 	db := mas.connector.GetDatabase().(*mongo.Database)
 	collection := db.Collection(collectionName)
 
 	var stats bson.M
-	err := db.RunCommand(ctx, bson.D{{"collStats", collectionName}}).Decode(&stats)
+	err := runMongoCommand(ctx, db, bson.D{{Key: "collStats", Value: collectionName}}, commandTimeout(request.Options), &stats)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get collection stats: %w", err)
 	}
@@ -152,11 +189,12 @@ func (mas *MongoAnalyzerService) AnalyzeCollection(ctx context.Context, collecti
 	}
 
 	if request.Options.IncludeSchema {
-		fields, err := mas.AnalyzeSchema(ctx, collectionName, request)
+		fields, truncation, err := mas.analyzeSchema(ctx, collectionName, request)
 		if err != nil {
 			log.Printf("Warning: Could not analyze schema for %s: %v", collectionName, err)
 		}
 		collInfo.Fields = fields
+		collInfo.SchemaTruncation = truncation
 	}
 
 	if request.Options.IncludeData {
@@ -171,18 +209,24 @@ func (mas *MongoAnalyzerService) AnalyzeCollection(ctx context.Context, collecti
 }
 
 func (mas *MongoAnalyzerService) GetCollectionNames(ctx context.Context, request core.AnalysisRequest) ([]string, error) {
+	names, _, err := mas.listCollectionNames(ctx, request)
+	return names, err
+}
+
+func (mas *MongoAnalyzerService) listCollectionNames(ctx context.Context, request core.AnalysisRequest) ([]string, *core.ObjectTruncation, error) {
 	db := mas.connector.GetDatabase().(*mongo.Database)
 
 	names, err := db.ListCollectionNames(ctx, bson.D{})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list collection names: %w", err)
+		return nil, nil, fmt.Errorf("failed to list collection names: %w", err)
 	}
 
-	if request.Options.MaxCollections > 0 && len(names) > request.Options.MaxCollections {
-		names = names[:request.Options.MaxCollections]
+	names, truncation := limitObjectNames(filterTableNames(names, request.Options), request.Options.MaxCollections)
+	if truncation != nil {
+		log.Printf("Warning: Collection limit of %d reached, skipping %d of %d collections",
+			truncation.Limit, truncation.Skipped, truncation.Total)
 	}
-
-	return names, nil
+	return names, truncation, nil
 }
 
 func (mas *MongoAnalyzerService) GetCollectionStats(ctx context.Context, collectionName string, request core.AnalysisRequest) (*core.MongoCollectionInfo, error) {
@@ -190,6 +234,11 @@ func (mas *MongoAnalyzerService) GetCollectionStats(ctx context.Context, collect
 }
 
 func (mas *MongoAnalyzerService) AnalyzeSchema(ctx context.Context, collectionName string, request core.AnalysisRequest) ([]core.MongoFieldInfo, error) {
+	fields, _, err := mas.analyzeSchema(ctx, collectionName, request)
+	return fields, err
+}
+
+func (mas *MongoAnalyzerService) analyzeSchema(ctx context.Context, collectionName string, request core.AnalysisRequest) ([]core.MongoFieldInfo, *core.SchemaTruncation, error) {
 	db := mas.connector.GetDatabase().(*mongo.Database)
 	collection := db.Collection(collectionName)
 
@@ -198,13 +247,13 @@ func (mas *MongoAnalyzerService) AnalyzeSchema(ctx context.Context, collectionNa
 		sampleSize = 100
 	}
 
-	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetLimit(int64(sampleSize)))
+	cursor, err := mas.sampleDocuments(ctx, collection, sampleSize, request.Options.SamplingStrategy)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get sample documents: %w", err)
+		return nil, nil, fmt.Errorf("failed to get sample documents: %w", err)
 	}
 	defer cursor.Close(ctx)
 
-	fieldMap := make(map[string]*core.MongoFieldInfo)
+	walker := mas.newDocumentWalker(request.Options.MaxDocumentDepth, request.Options.MaxTrackedFields)
 	totalDocs := 0
 
 	for cursor.Next(ctx) {
@@ -214,16 +263,47 @@ func (mas *MongoAnalyzerService) AnalyzeSchema(ctx context.Context, collectionNa
 		}
 
 		totalDocs++
-		mas.analyzeDocument(doc, fieldMap, "")
+		walker.walk(doc)
+	}
+
+	fields := []core.MongoFieldInfo{}
+	if totalDocs == 0 {
+		return fields, walker.truncation(), nil
 	}
 
-	var fields []core.MongoFieldInfo
-	for _, field := range fieldMap {
+	for _, field := range walker.fields {
 		field.Frequency = field.Frequency / float64(totalDocs)
 		fields = append(fields, *field)
 	}
 
-	return fields, nil
+	return fields, walker.truncation(), nil
+}
+
+func (mas *MongoAnalyzerService) sampleDocuments(ctx context.Context, collection *mongo.Collection, sampleSize int, strategy core.SamplingStrategy) (*mongo.Cursor, error) {
+	if strategy == "" {
+		strategy = core.SamplingStrategyRandom
+	}
+
+	switch strategy {
+	case core.SamplingStrategyRecent:
+		return collection.Find(ctx, bson.D{}, options.Find().
+			SetSort(bson.D{{Key: "_id", Value: -1}}).
+			SetLimit(int64(sampleSize)))
+	case core.SamplingStrategyRandom:
+		count, err := collection.EstimatedDocumentCount(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count documents: %w", err)
+		}
+		// $sample gains nothing over a plain scan when the whole collection fits in the sample
+		if count > int64(sampleSize) {
+			pipeline := mongo.Pipeline{
+				{{Key: "$sample", Value: bson.D{{Key: "size", Value: sampleSize}}}},
+			}
+			return collection.Aggregate(ctx, pipeline)
+		}
+	}
+
+	return collection.Find(ctx, bson.D{}, options.Find().SetLimit(int64(sampleSize)))
 }
 
 func (mas *MongoAnalyzerService) GetIndexes(ctx context.Context, collectionName string, request core.AnalysisRequest) ([]core.MongoIndexInfo, error) {
@@ -235,7 +315,7 @@ func (mas *MongoAnalyzerService) GetIndexes(ctx context.Context, collectionName
 	}
 	defer cursor.Close(ctx)
 
-	var indexes []core.MongoIndexInfo
+	indexes := []core.MongoIndexInfo{}
 	for cursor.Next(ctx) {
 		var indexDoc bson.M
 		if err := cursor.Decode(&indexDoc); err != nil {
@@ -278,7 +358,7 @@ func (mas *MongoAnalyzerService) GetDatabaseStats(ctx context.Context, request c
 	db := mas.connector.GetDatabase().(*mongo.Database)
 
 	var stats bson.M
-	err := db.RunCommand(ctx, bson.D{{"dbStats", 1}}).Decode(&stats)
+	err := runMongoCommand(ctx, db, bson.D{{Key: "dbStats", Value: 1}}, commandTimeout(request.Options), &stats)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get database stats: %w", err)
 	}
@@ -325,6 +405,12 @@ func (mas *MongoAnalyzerService) GetDatabaseStats(ctx context.Context, request c
 
 	dbStats.TotalSize = dbStats.DataSize + dbStats.IndexSize
 
+	topology, err := mas.GetTopology(ctx)
+	if err != nil {
+		log.Printf("Warning: Could not determine MongoDB topology: %v", err)
+	}
+	dbStats.Topology = topology
+
 	return dbStats, nil
 }
 
@@ -332,7 +418,7 @@ func (mas *MongoAnalyzerService) GetPerformanceMetrics(ctx context.Context, requ
 	db := mas.connector.GetDatabase().(*mongo.Database)
 
 	var serverStatus bson.M
-	err := db.RunCommand(ctx, bson.D{{"serverStatus", 1}}).Decode(&serverStatus)
+	err := runMongoCommand(ctx, db, bson.D{{Key: "serverStatus", Value: 1}}, commandTimeout(request.Options), &serverStatus)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get server status: %w", err)
 	}
@@ -404,37 +490,34 @@ func (mas *MongoAnalyzerService) getSampleDocument(ctx context.Context, collecti
 	return doc, nil
 }
 
-func (mas *MongoAnalyzerService) analyzeDocument(doc bson.M, fieldMap map[string]*core.MongoFieldInfo, prefix string) {
-	for key, value := range doc {
-		fieldName := key
-		if prefix != "" {
-			fieldName = prefix + "." + key
-		}
-
-		field, exists := fieldMap[fieldName]
-		if !exists {
-			field = &core.MongoFieldInfo{
-				Name:        fieldName,
-				SampleValue: value,
-				Frequency:   0,
-			}
-			fieldMap[fieldName] = field
-		}
-
-		field.Frequency++
-		field.Type = mas.getFieldType(value)
-
-		if nestedDoc, ok := value.(bson.M); ok {
-			mas.analyzeDocument(nestedDoc, fieldMap, fieldName)
-		}
-	}
-}
-
+// getFieldType names the BSON type of a decoded value. The driver's primitive types are
+// matched first, since by kind alone an ObjectID is an array and a DateTime an int.
 func (mas *MongoAnalyzerService) getFieldType(value interface{}) string {
 	if value == nil {
 		return "null"
 	}
 
+	switch value.(type) {
+	case primitive.ObjectID:
+		return "objectId"
+	case primitive.DateTime, time.Time:
+		return "date"
+	case primitive.Timestamp:
+		return "timestamp"
+	case primitive.Decimal128:
+		return "decimal"
+	case primitive.Binary:
+		return "binary"
+	case primitive.Regex:
+		return "regex"
+	case primitive.A:
+		return "array"
+	case primitive.M, primitive.D:
+		return "object"
+	case primitive.Null, primitive.Undefined:
+		return "null"
+	}
+
 	switch reflect.TypeOf(value).Kind() {
 	case reflect.String:
 		return "string"
@@ -454,7 +537,7 @@ func (mas *MongoAnalyzerService) getFieldType(value interface{}) string {
 }
 
 func (mas *MongoAnalyzerService) convertCollectionsToTables(collections []core.MongoCollectionInfo) []core.TableInfo {
-	var tables []core.TableInfo
+	tables := []core.TableInfo{}
 	for _, coll := range collections {
 		table := core.TableInfo{
 			Name:         coll.Name,
@@ -492,7 +575,7 @@ func (mas *MongoAnalyzerService) convertCollectionsToTables(collections []core.M
 }
 
 func (mas *MongoAnalyzerService) generateInsights(collections []core.MongoCollectionInfo, stats *core.MongoDatabaseStats) []core.DatabaseInsight {
-	var insights []core.DatabaseInsight
+	insights := []core.DatabaseInsight{}
 
 	for _, coll := range collections {
 		if coll.DocumentCount > 1000000 {
@@ -548,7 +631,7 @@ func (mas *MongoAnalyzerService) generateSummary(collections []core.MongoCollect
 }
 
 func (mas *MongoAnalyzerService) generateRecommendations(insights []core.DatabaseInsight) []string {
-	var recommendations []string
+	recommendations := []string{}
 
 	highPriorityCount := 0
 	for _, insight := range insights {
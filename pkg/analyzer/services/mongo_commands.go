@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/cherry-pick/pkg/analyzer/core"
+	"github.com/cherry-pick/pkg/logging"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoCommandRetryBackoff is the pause before the first retry; it doubles on each
+// further attempt.
+var mongoCommandRetryBackoff = 200 * time.Millisecond
+
+// mongoCommandRunner is the part of *mongo.Database that admin and stats commands use.
+type mongoCommandRunner interface {
+	RunCommand(ctx context.Context, runCommand interface{}, opts ...*options.RunCmdOptions) *mongo.SingleResult
+}
+
+// commandTimeout is how long a single attempt of an admin or stats command may take.
+func commandTimeout(options core.AnalysisOptions) time.Duration {
+	if options.CommandTimeoutSeconds > 0 {
+		return time.Duration(options.CommandTimeoutSeconds) * time.Second
+	}
+	return core.DefaultMongoCommandTimeout
+}
+
+// runMongoCommand runs command with a timeout per attempt and decodes the reply into
+// result. Network errors and attempts that time out are retried up to
+// core.MongoCommandAttempts times; command errors such as failed authorization are
+// returned at once, as is any error after ctx itself is done.
+func runMongoCommand(ctx context.Context, runner mongoCommandRunner, command interface{}, timeout time.Duration, result interface{}) error {
+	backoff := mongoCommandRetryBackoff
+
+	var err error
+	for attempt := 1; attempt <= core.MongoCommandAttempts; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		err = runner.RunCommand(attemptCtx, command).Decode(result)
+		cancel()
+
+		if err == nil || !isTransientMongoError(err) || ctx.Err() != nil {
+			return err
+		}
+		if attempt == core.MongoCommandAttempts {
+			break
+		}
+
+		logging.Default().Warn("MongoDB command failed, retrying", "attempt", attempt, "attempts", core.MongoCommandAttempts, "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		backoff *= 2
+	}
+	return err
+}
+
+func isTransientMongoError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	return mongo.IsNetworkError(err) || mongo.IsTimeout(err)
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"github.com/cherry-pick/pkg/analyzer/core"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// documentWalker accumulates field statistics across sampled documents while bounding
+// recursion depth and the number of distinct dotted paths it will track.
+type documentWalker struct {
+	mas           *MongoAnalyzerService
+	maxDepth      int
+	maxFields     int
+	fields        map[string]*core.MongoFieldInfo
+	seen          map[string]bool
+	depthLimitHit bool
+	fieldLimitHit bool
+	skippedFields map[string]bool
+}
+
+func (mas *MongoAnalyzerService) newDocumentWalker(maxDepth, maxFields int) *documentWalker {
+	if maxDepth <= 0 {
+		maxDepth = core.DefaultMaxDocumentDepth
+	}
+	if maxFields <= 0 {
+		maxFields = core.DefaultMaxTrackedFields
+	}
+
+	return &documentWalker{
+		mas:           mas,
+		maxDepth:      maxDepth,
+		maxFields:     maxFields,
+		fields:        make(map[string]*core.MongoFieldInfo),
+		skippedFields: make(map[string]bool),
+	}
+}
+
+func (w *documentWalker) walk(doc bson.M) {
+	w.seen = make(map[string]bool)
+	w.walkDocument(doc, "", 1)
+}
+
+func (w *documentWalker) walkDocument(doc bson.M, prefix string, depth int) {
+	if depth > w.maxDepth {
+		w.depthLimitHit = true
+		return
+	}
+
+	for key, value := range doc {
+		fieldName := key
+		if prefix != "" {
+			fieldName = prefix + "." + key
+		}
+		w.walkValue(fieldName, value, depth)
+	}
+}
+
+func (w *documentWalker) walkValue(fieldName string, value interface{}, depth int) {
+	field, exists := w.fields[fieldName]
+	if !exists {
+		if len(w.fields) >= w.maxFields {
+			w.fieldLimitHit = true
+			// Bounded so a pathological document cannot grow memory through the skip set either.
+			if len(w.skippedFields) < w.maxFields {
+				w.skippedFields[fieldName] = true
+			}
+			return
+		}
+		field = &core.MongoFieldInfo{
+			Name:        fieldName,
+			SampleValue: value,
+		}
+		w.fields[fieldName] = field
+	}
+
+	// Array elements share a path, so a field counts at most once per document.
+	if !w.seen[fieldName] {
+		w.seen[fieldName] = true
+		field.Frequency++
+	}
+	field.Type = w.mas.getFieldType(value)
+
+	switch v := value.(type) {
+	case bson.M:
+		w.walkDocument(v, fieldName, depth+1)
+	case bson.D:
+		w.walkDocument(documentToMap(v), fieldName, depth+1)
+	case bson.A:
+		if depth+1 > w.maxDepth {
+			w.depthLimitHit = true
+			return
+		}
+		for _, element := range v {
+			switch e := element.(type) {
+			case bson.M:
+				w.walkDocument(e, fieldName+"[]", depth+1)
+			case bson.D:
+				w.walkDocument(documentToMap(e), fieldName+"[]", depth+1)
+			}
+		}
+	}
+}
+
+func (w *documentWalker) truncation() *core.SchemaTruncation {
+	if !w.depthLimitHit && !w.fieldLimitHit {
+		return nil
+	}
+	return &core.SchemaTruncation{
+		DepthLimitHit: w.depthLimitHit,
+		FieldLimitHit: w.fieldLimitHit,
+		MaxDepth:      w.maxDepth,
+		MaxFields:     w.maxFields,
+		SkippedFields: len(w.skippedFields),
+	}
+}
+
+func documentToMap(doc bson.D) bson.M {
+	m := make(bson.M, len(doc))
+	for _, element := range doc {
+		m[element.Key] = element.Value
+	}
+	return m
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cherry-pick/pkg/analyzer/core"
+	"github.com/cherry-pick/pkg/logging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	MongoTopologyStandalone = "standalone"
+	MongoTopologyReplicaSet = "replicaSet"
+	MongoTopologySharded    = "sharded"
+)
+
+// GetTopology reports whether the server is a mongos, replica set member or standalone.
+// Member roles come from replSetGetStatus, which needs clusterMonitor; without it the
+// hello response's host lists are used instead.
+func (mas *MongoAnalyzerService) GetTopology(ctx context.Context) (*core.MongoTopology, error) {
+	db := mas.connector.GetDatabase().(*mongo.Database)
+	admin := db.Client().Database("admin")
+
+	var hello bson.M
+	if err := admin.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		if err := admin.RunCommand(ctx, bson.D{{Key: "isMaster", Value: 1}}).Decode(&hello); err != nil {
+			return nil, fmt.Errorf("failed to run hello: %w", err)
+		}
+	}
+
+	topology := &core.MongoTopology{Kind: MongoTopologyStandalone}
+	if msg, _ := hello["msg"].(string); msg == "isdbgrid" {
+		topology.Kind = MongoTopologySharded
+		return topology, nil
+	}
+
+	setName, _ := hello["setName"].(string)
+	if setName == "" {
+		return topology, nil
+	}
+	topology.Kind = MongoTopologyReplicaSet
+	topology.ReplicaSet = setName
+
+	members, err := mas.getReplicaMembers(ctx, admin)
+	if err != nil {
+		logging.Default().Warn("Could not read replica set status, falling back to hello", "error", err)
+		members = replicaMembersFromHello(hello)
+	}
+	topology.Members = members
+
+	return topology, nil
+}
+
+func (mas *MongoAnalyzerService) getReplicaMembers(ctx context.Context, admin *mongo.Database) ([]core.MongoReplicaMember, error) {
+	var status bson.M
+	if err := admin.RunCommand(ctx, bson.D{{Key: "replSetGetStatus", Value: 1}}).Decode(&status); err != nil {
+		return nil, err
+	}
+
+	raw, ok := status["members"].(bson.A)
+	if !ok {
+		return nil, fmt.Errorf("replSetGetStatus returned no members")
+	}
+
+	members := make([]core.MongoReplicaMember, 0, len(raw))
+	for _, item := range raw {
+		member, ok := item.(bson.M)
+		if !ok {
+			continue
+		}
+		host, _ := member["name"].(string)
+		state, _ := member["stateStr"].(string)
+		health, _ := member["health"].(float64)
+		members = append(members, core.MongoReplicaMember{
+			Host:    host,
+			Role:    strings.ToLower(state),
+			Healthy: health == 1,
+		})
+	}
+	return members, nil
+}
+
+func replicaMembersFromHello(hello bson.M) []core.MongoReplicaMember {
+	primary, _ := hello["primary"].(string)
+
+	var members []core.MongoReplicaMember
+	for _, key := range []string{"hosts", "passives", "arbiters"} {
+		hosts, ok := hello[key].(bson.A)
+		if !ok {
+			continue
+		}
+		for _, h := range hosts {
+			host, _ := h.(string)
+			role := "secondary"
+			switch {
+			case key == "arbiters":
+				role = "arbiter"
+			case key == "passives":
+				role = "passive"
+			case host == primary:
+				role = "primary"
+			}
+			// hello does not report member health; only reachable members are listed.
+			members = append(members, core.MongoReplicaMember{Host: host, Role: role, Healthy: true})
+		}
+	}
+	return members
+}
+
+// getShardedCollections reads config.collections through mongos and returns the shard key per
+// collection name. Users without read access to the config database get an error, not a guess.
+func (mas *MongoAnalyzerService) getShardedCollections(ctx context.Context) (map[string]map[string]interface{}, error) {
+	db := mas.connector.GetDatabase().(*mongo.Database)
+	prefix := db.Name() + "."
+
+	filter := bson.M{
+		"_id":     bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)},
+		"dropped": bson.M{"$ne": true},
+	}
+	cursor, err := db.Client().Database("config").Collection("collections").Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config.collections: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	sharded := make(map[string]map[string]interface{})
+	for cursor.Next(ctx) {
+		var entry bson.M
+		if err := cursor.Decode(&entry); err != nil {
+			continue
+		}
+		namespace, _ := entry["_id"].(string)
+		key, _ := entry["key"].(bson.M)
+		sharded[strings.TrimPrefix(namespace, prefix)] = map[string]interface{}(key)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read config.collections: %w", err)
+	}
+	return sharded, nil
+}
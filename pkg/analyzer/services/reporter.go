@@ -95,6 +95,13 @@ Performance Metrics:
 		)
 	}
 
+	if len(result.Warnings) > 0 {
+		summary += fmt.Sprintf("\nTables Not Analyzed: %d\n", len(result.Warnings))
+		for _, warning := range result.Warnings {
+			summary += fmt.Sprintf("- %s: %s\n", warning.Table, warning.Error)
+		}
+	}
+
 	return summary, nil
 }
 
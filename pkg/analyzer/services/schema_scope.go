@@ -0,0 +1,67 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cherry-pick/pkg/analyzer/core"
+)
+
+var schemaNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+
+// tableRef is a table and the schema it was listed from. An empty schema means the
+// connection's default: DATABASE() on MySQL, public on PostgreSQL and main on SQLite.
+type tableRef struct {
+	schema string
+	name   string
+}
+
+// resolveTableRef splits a name returned by GetTableNames back into schema and table.
+// Only a prefix naming one of the requested schemas is split off, so a dotted table
+// name in the default schema is left whole.
+func resolveTableRef(tableName string, options core.AnalysisOptions) tableRef {
+	for _, schema := range options.SchemaNames() {
+		if name, found := strings.CutPrefix(tableName, schema+"."); found {
+			return tableRef{schema: schema, name: name}
+		}
+	}
+	return tableRef{name: tableName}
+}
+
+// qualify prefixes a table in the same schema as t, such as a foreign key target.
+func (t tableRef) qualify(table string) string {
+	if t.schema == "" || table == "" {
+		return table
+	}
+	return t.schema + "." + table
+}
+
+// schemaExpr is the SQL expression a catalog query compares its schema column with,
+// and the argument to bind when it is the placeholder.
+func (t tableRef) schemaExpr(dbType core.DatabaseType, placeholder string) (string, []interface{}) {
+	if t.schema != "" {
+		return placeholder, []interface{}{t.schema}
+	}
+	if dbType == core.DatabaseTypeMySQL {
+		return "DATABASE()", nil
+	}
+	return "'public'", nil
+}
+
+// sqlitePragma addresses a table pragma to the attached database holding the table.
+func (t tableRef) sqlitePragma(pragma, argument string) string {
+	if t.schema == "" {
+		return fmt.Sprintf("PRAGMA %s(%s)", pragma, argument)
+	}
+	return fmt.Sprintf("PRAGMA %s.%s(%s)", t.schema, pragma, argument)
+}
+
+func validateSchemaNames(schemas []string) error {
+	for _, schema := range schemas {
+		if !schemaNamePattern.MatchString(schema) {
+			return fmt.Errorf("%q is not a valid schema name", schema)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// sqliteObjectSize reads an object's size from dbstat, in the attached database schema
+// or in main when schema is empty.
+func sqliteObjectSize(ctx context.Context, db *sql.DB, schema, name string) (int64, bool, error) {
+	if schema == "" {
+		schema = "main"
+	}
+	var size sql.NullInt64
+	err := db.QueryRowContext(ctx, `SELECT SUM(pgsize) FROM dbstat(?) WHERE name = ?`, schema, name).Scan(&size)
+	if err != nil {
+		return 0, false, err
+	}
+	return size.Int64, true, nil
+}
+
+func sqliteDatabaseSize(ctx context.Context, db *sql.DB, schema string) (int64, error) {
+	if schema == "" {
+		schema = "main"
+	}
+	var pageCount, pageSize int64
+	if err := db.QueryRowContext(ctx, fmt.Sprintf(`PRAGMA %s.page_count`, schema)).Scan(&pageCount); err != nil {
+		return 0, fmt.Errorf("failed to read page_count: %w", err)
+	}
+	if err := db.QueryRowContext(ctx, fmt.Sprintf(`PRAGMA %s.page_size`, schema)).Scan(&pageSize); err != nil {
+		return 0, fmt.Errorf("failed to read page_size: %w", err)
+	}
+	return pageCount * pageSize, nil
+}
+
+func sqliteEstimatedTableSize(ctx context.Context, db *sql.DB, schema, tableName string) (int64, error) {
+	if schema == "" {
+		schema = "main"
+	}
+	total, err := sqliteDatabaseSize(ctx, db, schema)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(`SELECT name FROM %s.sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%%'`, schema))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list tables: %w", err)
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if rows.Scan(&name) == nil {
+			tables = append(tables, name)
+		}
+	}
+	rows.Close()
+
+	var tableRows, allRows int64
+	for _, name := range tables {
+		var count int64
+		if err := db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s."%s"`, schema, name)).Scan(&count); err != nil {
+			continue
+		}
+		allRows += count
+		if name == tableName {
+			tableRows = count
+		}
+	}
+
+	if allRows == 0 {
+		if len(tables) == 0 {
+			return 0, nil
+		}
+		return total / int64(len(tables)), nil
+	}
+	return int64(float64(total) * float64(tableRows) / float64(allRows)), nil
+}
+
+func formatByteSize(size int64) string {
+	switch {
+	case size < 1024:
+		return fmt.Sprintf("%d bytes", size)
+	case size < 1024*1024:
+		return fmt.Sprintf("%.2f KB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%.2f MB", float64(size)/(1024*1024))
+	}
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"path"
+	"strings"
+
+	"github.com/cherry-pick/pkg/analyzer/core"
+)
+
+// filterTableNames keeps the names selected by options.IncludeTables (all names when empty)
+// and drops anything matching options.ExcludeTables or core.DefaultExcludedTables.
+// Excludes always win over includes. Matching is case-insensitive.
+func filterTableNames(names []string, options core.AnalysisOptions) []string {
+	excludes := append(append([]string{}, core.DefaultExcludedTables...), options.ExcludeTables...)
+
+	filtered := make([]string, 0, len(names))
+	for _, name := range names {
+		if matchesAnyPattern(name, excludes) {
+			continue
+		}
+		if len(options.IncludeTables) > 0 && !matchesAnyPattern(name, options.IncludeTables) {
+			continue
+		}
+		filtered = append(filtered, name)
+	}
+	return filtered
+}
+
+// limitObjectNames keeps the first limit names, reporting how many were cut. A limit of
+// zero or less keeps every name.
+func limitObjectNames(names []string, limit int) ([]string, *core.ObjectTruncation) {
+	if limit <= 0 || len(names) <= limit {
+		return names, nil
+	}
+	return names[:limit], &core.ObjectTruncation{
+		Limit:   limit,
+		Total:   len(names),
+		Skipped: len(names) - limit,
+	}
+}
+
+func matchesAnyPattern(name string, patterns []string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range patterns {
+		if matched, err := path.Match(strings.ToLower(pattern), name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+func validateTablePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
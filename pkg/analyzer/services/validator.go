@@ -6,10 +6,20 @@ import (
 	"github.com/cherry-pick/pkg/analyzer/core"
 )
 
-type ValidatorService struct{}
+type ValidatorService struct {
+	dialects *DialectRegistry
+}
 
 func NewValidatorService() *ValidatorService {
-	return &ValidatorService{}
+	return &ValidatorService{dialects: DefaultDialects()}
+}
+
+// SetDialects makes the validator accept the SQL database types registered in registry
+// instead of the default one.
+func (vs *ValidatorService) SetDialects(registry *DialectRegistry) {
+	if registry != nil {
+		vs.dialects = registry
+	}
 }
 
 func (vs *ValidatorService) ValidateRequest(request core.AnalysisRequest) error {
@@ -28,21 +38,18 @@ func (vs *ValidatorService) ValidateRequest(request core.AnalysisRequest) error
 	return nil
 }
 
+// ValidateDatabaseType accepts MongoDB and any SQL database type with a registered dialect.
 func (vs *ValidatorService) ValidateDatabaseType(dbType core.DatabaseType) error {
-	supportedTypes := []core.DatabaseType{
-		core.DatabaseTypeMySQL,
-		core.DatabaseTypePostgres,
-		core.DatabaseTypeSQLite,
-		core.DatabaseTypeMongoDB,
+	if dbType == core.DatabaseTypeMongoDB {
+		return nil
 	}
 
-	for _, supportedType := range supportedTypes {
-		if dbType == supportedType {
-			return nil
-		}
+	dialects := vs.dialects
+	if dialects == nil {
+		dialects = DefaultDialects()
 	}
-
-	return fmt.Errorf("unsupported database type: %s", dbType)
+	_, err := dialects.Lookup(dbType)
+	return err
 }
 
 func (vs *ValidatorService) ValidateOptions(options core.AnalysisOptions) error {
@@ -54,6 +61,30 @@ func (vs *ValidatorService) ValidateOptions(options core.AnalysisOptions) error
 		return fmt.Errorf("sample size cannot exceed 10000")
 	}
 
+	if options.ProfileTimeoutSeconds < 0 {
+		return fmt.Errorf("profile timeout cannot be negative")
+	}
+
+	if options.ProfileTimeoutSeconds > 300 {
+		return fmt.Errorf("profile timeout cannot exceed 300 seconds")
+	}
+
+	if options.CommandTimeoutSeconds < 0 {
+		return fmt.Errorf("command timeout cannot be negative")
+	}
+
+	if options.CommandTimeoutSeconds > 300 {
+		return fmt.Errorf("command timeout cannot exceed 300 seconds")
+	}
+
+	if options.TopValues < 0 {
+		return fmt.Errorf("top values cannot be negative")
+	}
+
+	if options.TopValues > core.MaxTopValues {
+		return fmt.Errorf("top values cannot exceed %d", core.MaxTopValues)
+	}
+
 	if options.MaxCollections < 0 {
 		return fmt.Errorf("max collections cannot be negative")
 	}
@@ -62,5 +93,31 @@ func (vs *ValidatorService) ValidateOptions(options core.AnalysisOptions) error
 		return fmt.Errorf("max collections cannot exceed 1000")
 	}
 
+	if options.MaxTables < 0 {
+		return fmt.Errorf("max tables cannot be negative")
+	}
+
+	if options.MaxTables > 1000 {
+		return fmt.Errorf("max tables cannot exceed 1000")
+	}
+
+	if err := validateTablePatterns(options.IncludeTables); err != nil {
+		return fmt.Errorf("invalid include table pattern: %w", err)
+	}
+
+	if err := validateTablePatterns(options.ExcludeTables); err != nil {
+		return fmt.Errorf("invalid exclude table pattern: %w", err)
+	}
+
+	if err := validateSchemaNames(options.SchemaNames()); err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+
+	switch options.SamplingStrategy {
+	case "", core.SamplingStrategyFirst, core.SamplingStrategyRandom, core.SamplingStrategyRecent:
+	default:
+		return fmt.Errorf("unsupported sampling strategy: %s", options.SamplingStrategy)
+	}
+
 	return nil
 }
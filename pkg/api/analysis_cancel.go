@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/cherry-pick/pkg/types"
+	"github.com/gin-gonic/gin"
+)
+
+// analysisRegistry holds the cancel functions of in-flight analyses by connection, so a
+// request can stop analyses that other requests started.
+type analysisRegistry struct {
+	mu      sync.Mutex
+	nextID  uint64
+	running map[string]map[uint64]context.CancelFunc
+}
+
+func newAnalysisRegistry() *analysisRegistry {
+	return &analysisRegistry{running: make(map[string]map[uint64]context.CancelFunc)}
+}
+
+// start derives a cancellable context for an analysis of connection id. The returned
+// func must be called when the analysis ends.
+func (ar *analysisRegistry) start(parent context.Context, id string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+
+	ar.mu.Lock()
+	ar.nextID++
+	key := ar.nextID
+	if ar.running[id] == nil {
+		ar.running[id] = make(map[uint64]context.CancelFunc)
+	}
+	ar.running[id][key] = cancel
+	ar.mu.Unlock()
+
+	return ctx, func() {
+		ar.mu.Lock()
+		delete(ar.running[id], key)
+		if len(ar.running[id]) == 0 {
+			delete(ar.running, id)
+		}
+		ar.mu.Unlock()
+		cancel()
+	}
+}
+
+// cancel stops every in-flight analysis of connection id and returns how many there were.
+func (ar *analysisRegistry) cancel(id string) int {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	for _, cancel := range ar.running[id] {
+		cancel()
+	}
+	return len(ar.running[id])
+}
+
+// cancelledAnalysisError reports an analysis stopped by its context as CANCELLED, keeping
+// the progress the service described in its message.
+func cancelledAnalysisError(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return types.NewAPIError(types.ErrCodeCancelled, err.Error())
+	}
+	return err
+}
+
+func (s *Server) cancelAnalysis(c *gin.Context) {
+	id := c.Param("id")
+
+	mutex.RLock()
+	_, exists := connections[id]
+	mutex.RUnlock()
+
+	if !exists {
+		s.sendError(c, http.StatusNotFound,
+			types.NewAPIError(types.ErrCodeConnectionNotFound, "Connection not found"), "Connection not found")
+		return
+	}
+
+	cancelled := s.analyses.cancel(id)
+	if cancelled == 0 {
+		s.sendError(c, http.StatusNotFound,
+			types.NewAPIError(types.ErrCodeNotFound, "No analysis is running for this connection"), "Nothing to cancel")
+		return
+	}
+
+	s.sendSuccess(c, map[string]interface{}{
+		"status":    "cancelled",
+		"cancelled": cancelled,
+	}, "Analysis cancelled")
+}
@@ -1,15 +1,24 @@
 package analytics
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/cherry-pick/pkg/analytics/core"
+	"github.com/cherry-pick/pkg/types"
 	"github.com/gin-gonic/gin"
 )
 
+const serviceContextKey = "analyticsService"
+
 type Handler struct {
 	service AnalyticsService
+	resolve func(c *gin.Context) (AnalyticsService, error)
 }
 
 type AnalyticsService interface {
@@ -22,19 +31,32 @@ type AnalyticsService interface {
 	UpdateSession(session core.UserSession) error
 	EndSession(sessionID string) error
 	GetUserJourney(sessionID string) (*core.UserJourney, error)
+	GetAttributionReport(model string) (*core.AttributionReport, error)
 	GetFunnelAnalysis(funnelID string, startTime, endTime time.Time) (*core.FunnelAnalysis, error)
 	GetRealTimeMetrics() (*core.RealTimeMetrics, error)
 	GetInsights(sessionID string) ([]core.AnalyticsInsight, error)
+	QueryInsights(sessionID string, query core.InsightQuery) (*core.InsightPage, error)
 	GetAlerts() ([]core.AnalyticsAlert, error)
 	GetHeatmapData(pagePath string, startTime, endTime time.Time) ([]core.HeatmapPoint, error)
+	GetHeatmapImage(pagePath string, startTime, endTime time.Time, width, height int) ([]byte, error)
+	CreateFunnel(funnel core.FunnelDefinition) (*core.FunnelDefinition, error)
+	GetFunnel(funnelID string) (*core.FunnelDefinition, error)
+	ListFunnels() ([]core.FunnelDefinition, error)
+	UpdateFunnel(funnel core.FunnelDefinition) (*core.FunnelDefinition, error)
+	DeleteFunnel(funnelID string) error
 	GenerateReport(request core.AnalyticsRequest) (*core.AnalyticsReport, error)
+	GetReport(reportID string) (*core.AnalyticsReport, error)
+	CompareAnalyticsReports(a, b *core.AnalyticsReport) *core.AnalyticsReportDiff
 	GenerateSummary(startTime, endTime time.Time) (*core.AnalyticsSummary, error)
 	GenerateInsights(startTime, endTime time.Time) ([]core.AnalyticsInsight, error)
 	GenerateFunnelReport(funnelID string, startTime, endTime time.Time) (*core.FunnelAnalysis, error)
 	GeneratePerformanceReport(startTime, endTime time.Time) ([]core.PerformanceEvent, error)
+	GetWebVitalsReport(pagePath string, startTime, endTime time.Time) (*core.WebVitalsReport, error)
 	GenerateBehavioralReport(startTime, endTime time.Time) ([]core.BehavioralEvent, error)
 	SubscribeToRealTimeMetrics(ctx context.Context) (<-chan core.RealTimeMetrics, error)
+	SubscribeWithOptions(ctx context.Context, options core.SubscriberOptions) (string, <-chan core.RealTimeMetrics, error)
 	UnsubscribeFromRealTimeMetrics(subscriberID string) error
+	GetSubscriberStats() []core.SubscriberStats
 	CleanupOldData(olderThan time.Time) error
 	GetStats() (map[string]interface{}, error)
 }
@@ -45,6 +67,40 @@ func NewHandler(service AnalyticsService) *Handler {
 	}
 }
 
+// NewWorkspaceHandler picks the service for each request with resolve, so every
+// workspace reads and writes only its own analytics data.
+func NewWorkspaceHandler(resolve func(c *gin.Context) (AnalyticsService, error)) *Handler {
+	return &Handler{
+		resolve: resolve,
+	}
+}
+
+// serviceMiddleware resolves the request's service once, before the handler runs.
+func (h *Handler) serviceMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.resolve == nil {
+			c.Next()
+			return
+		}
+
+		service, err := h.resolve(c)
+		if err != nil {
+			h.sendError(c, http.StatusServiceUnavailable, err, "Analytics unavailable")
+			c.Abort()
+			return
+		}
+		c.Set(serviceContextKey, service)
+		c.Next()
+	}
+}
+
+func (h *Handler) serviceFor(c *gin.Context) AnalyticsService {
+	if service, ok := c.Get(serviceContextKey); ok {
+		return service.(AnalyticsService)
+	}
+	return h.service
+}
+
 func (h *Handler) TrackPageView(c *gin.Context) {
 	var event core.PageViewEvent
 	if err := c.ShouldBindJSON(&event); err != nil {
@@ -52,7 +108,7 @@ func (h *Handler) TrackPageView(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.TrackPageView(event); err != nil {
+	if err := h.serviceFor(c).TrackPageView(event); err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to track page view")
 		return
 	}
@@ -71,7 +127,7 @@ func (h *Handler) TrackBehavioralPattern(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.TrackBehavioralPattern(event); err != nil {
+	if err := h.serviceFor(c).TrackBehavioralPattern(event); err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to track behavioral pattern")
 		return
 	}
@@ -90,7 +146,7 @@ func (h *Handler) TrackPerformance(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.TrackPerformance(event); err != nil {
+	if err := h.serviceFor(c).TrackPerformance(event); err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to track performance")
 		return
 	}
@@ -109,7 +165,7 @@ func (h *Handler) TrackCustomEvent(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.TrackCustomEvent(event); err != nil {
+	if err := h.serviceFor(c).TrackCustomEvent(event); err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to track custom event")
 		return
 	}
@@ -121,6 +177,86 @@ func (h *Handler) TrackCustomEvent(c *gin.Context) {
 	h.sendSuccess(c, response)
 }
 
+func (h *Handler) TrackBatch(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, core.MaxBatchBytes)
+
+	var request core.BatchRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.sendError(c, http.StatusRequestEntityTooLarge,
+				fmt.Errorf("batch payload exceeds %d bytes", core.MaxBatchBytes), "Batch payload too large")
+			return
+		}
+		h.sendError(c, http.StatusBadRequest, err, "Invalid request data")
+		return
+	}
+
+	if len(request.Events) == 0 {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("batch contains no events"), "Invalid request data")
+		return
+	}
+	if len(request.Events) > core.MaxBatchEvents {
+		h.sendError(c, http.StatusRequestEntityTooLarge,
+			fmt.Errorf("batch contains %d events, maximum is %d", len(request.Events), core.MaxBatchEvents), "Batch too large")
+		return
+	}
+
+	response := core.BatchResponse{
+		Results: make([]core.BatchItemResult, 0, len(request.Events)),
+	}
+	service := h.serviceFor(c)
+	for i, item := range request.Events {
+		result := core.BatchItemResult{Index: i, Type: item.Type, Success: true}
+		if err := h.trackBatchEvent(service, item); err != nil {
+			result.Success = false
+			result.Error = err.Error()
+			response.Rejected++
+		} else {
+			response.Accepted++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	h.sendSuccess(c, response, fmt.Sprintf("Processed %d events (%d accepted, %d rejected)",
+		len(request.Events), response.Accepted, response.Rejected))
+}
+
+func (h *Handler) trackBatchEvent(service AnalyticsService, item core.BatchEvent) error {
+	if len(item.Event) == 0 {
+		return fmt.Errorf("event payload is required")
+	}
+
+	switch item.Type {
+	case core.BatchEventPageView:
+		var event core.PageViewEvent
+		if err := json.Unmarshal(item.Event, &event); err != nil {
+			return fmt.Errorf("invalid page view event: %w", err)
+		}
+		return service.TrackPageView(event)
+	case core.BatchEventBehavioral:
+		var event core.BehavioralEvent
+		if err := json.Unmarshal(item.Event, &event); err != nil {
+			return fmt.Errorf("invalid behavioral event: %w", err)
+		}
+		return service.TrackBehavioralPattern(event)
+	case core.BatchEventPerformance:
+		var event core.PerformanceEvent
+		if err := json.Unmarshal(item.Event, &event); err != nil {
+			return fmt.Errorf("invalid performance event: %w", err)
+		}
+		return service.TrackPerformance(event)
+	case core.BatchEventCustom:
+		var event core.AnalyticsEvent
+		if err := json.Unmarshal(item.Event, &event); err != nil {
+			return fmt.Errorf("invalid custom event: %w", err)
+		}
+		return service.TrackCustomEvent(event)
+	default:
+		return fmt.Errorf("unsupported event type: %q", item.Type)
+	}
+}
+
 func (h *Handler) CreateSession(c *gin.Context) {
 	var session core.UserSession
 	if err := c.ShouldBindJSON(&session); err != nil {
@@ -128,7 +264,7 @@ func (h *Handler) CreateSession(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.CreateSession(session); err != nil {
+	if err := h.serviceFor(c).CreateSession(session); err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to create session")
 		return
 	}
@@ -148,7 +284,7 @@ func (h *Handler) GetSession(c *gin.Context) {
 		return
 	}
 
-	session, err := h.service.GetSession(sessionID)
+	session, err := h.serviceFor(c).GetSession(sessionID)
 	if err != nil {
 		h.sendError(c, http.StatusNotFound, err, "Session not found")
 		return
@@ -172,7 +308,7 @@ func (h *Handler) UpdateSession(c *gin.Context) {
 
 	session.SessionID = sessionID
 
-	if err := h.service.UpdateSession(session); err != nil {
+	if err := h.serviceFor(c).UpdateSession(session); err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to update session")
 		return
 	}
@@ -192,7 +328,7 @@ func (h *Handler) EndSession(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.EndSession(sessionID); err != nil {
+	if err := h.serviceFor(c).EndSession(sessionID); err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to end session")
 		return
 	}
@@ -211,7 +347,7 @@ func (h *Handler) GetUserJourney(c *gin.Context) {
 		return
 	}
 
-	journey, err := h.service.GetUserJourney(sessionID)
+	journey, err := h.serviceFor(c).GetUserJourney(sessionID)
 	if err != nil {
 		h.sendError(c, http.StatusNotFound, err, "User journey not found")
 		return
@@ -220,6 +356,26 @@ func (h *Handler) GetUserJourney(c *gin.Context) {
 	h.sendSuccess(c, journey)
 }
 
+// GetAttributionReport credits converted journeys to their referrers. The model query
+// parameter is first-touch, last-touch or linear, and defaults to last-touch.
+func (h *Handler) GetAttributionReport(c *gin.Context) {
+	model := c.DefaultQuery("model", core.AttributionLastTouch)
+	switch model {
+	case core.AttributionFirstTouch, core.AttributionLastTouch, core.AttributionLinear:
+	default:
+		h.sendError(c, http.StatusBadRequest, nil, fmt.Sprintf("Unsupported attribution model: %s", model))
+		return
+	}
+
+	report, err := h.serviceFor(c).GetAttributionReport(model)
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, err, "Failed to get attribution report")
+		return
+	}
+
+	h.sendSuccess(c, report)
+}
+
 func (h *Handler) GetFunnelAnalysis(c *gin.Context) {
 	funnelID := c.Param("funnelId")
 	if funnelID == "" {
@@ -253,7 +409,7 @@ func (h *Handler) GetFunnelAnalysis(c *gin.Context) {
 		endTime = time.Now()
 	}
 
-	funnelAnalysis, err := h.service.GetFunnelAnalysis(funnelID, startTime, endTime)
+	funnelAnalysis, err := h.serviceFor(c).GetFunnelAnalysis(funnelID, startTime, endTime)
 	if err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to get funnel analysis")
 		return
@@ -263,7 +419,7 @@ func (h *Handler) GetFunnelAnalysis(c *gin.Context) {
 }
 
 func (h *Handler) GetRealTimeMetrics(c *gin.Context) {
-	metrics, err := h.service.GetRealTimeMetrics()
+	metrics, err := h.serviceFor(c).GetRealTimeMetrics()
 	if err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to get real-time metrics")
 		return
@@ -272,28 +428,57 @@ func (h *Handler) GetRealTimeMetrics(c *gin.Context) {
 	h.sendSuccess(c, metrics)
 }
 
+// GetInsights returns a page of the session's insights, or of the last day's when no
+// sessionId is given, filtered by type, impact and actionable and sorted by timestamp
+// or confidence.
 func (h *Handler) GetInsights(c *gin.Context) {
-	sessionID := c.Query("sessionId")
-	
-	var insights []core.AnalyticsInsight
+	query := core.InsightQuery{
+		Type:   c.Query("type"),
+		Impact: c.Query("impact"),
+		Sort:   c.DefaultQuery("sort", core.InsightSortTimestamp),
+	}
+	if query.Sort != core.InsightSortTimestamp && query.Sort != core.InsightSortConfidence {
+		h.sendError(c, http.StatusBadRequest, nil, "Sort must be confidence or timestamp")
+		return
+	}
+	if actionable := c.Query("actionable"); actionable != "" {
+		parsed, err := strconv.ParseBool(actionable)
+		if err != nil {
+			h.sendError(c, http.StatusBadRequest, err, "Invalid actionable")
+			return
+		}
+		query.Actionable = &parsed
+	}
+
 	var err error
-	
-	if sessionID != "" {
-		insights, err = h.service.GetInsights(sessionID)
-	} else {
-		insights, err = h.service.GenerateInsights(time.Now().Add(-24*time.Hour), time.Now())
+	if query.Limit, err = insightPageParam(c, "limit", core.DefaultInsightPageSize); err != nil || query.Limit < 1 || query.Limit > core.MaxInsightPageSize {
+		h.sendError(c, http.StatusBadRequest, err, "Limit must be between 1 and 500")
+		return
 	}
-	
+	if query.Offset, err = insightPageParam(c, "offset", 0); err != nil || query.Offset < 0 {
+		h.sendError(c, http.StatusBadRequest, err, "Offset cannot be negative")
+		return
+	}
+
+	page, err := h.serviceFor(c).QueryInsights(c.Query("sessionId"), query)
 	if err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to get insights")
 		return
 	}
 
-	h.sendSuccess(c, insights)
+	h.sendSuccess(c, page)
+}
+
+func insightPageParam(c *gin.Context, name string, fallback int) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
 }
 
 func (h *Handler) GetAlerts(c *gin.Context) {
-	alerts, err := h.service.GetAlerts()
+	alerts, err := h.serviceFor(c).GetAlerts()
 	if err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to get alerts")
 		return
@@ -309,39 +494,87 @@ func (h *Handler) GetHeatmapData(c *gin.Context) {
 		return
 	}
 
-	startTimeStr := c.Query("startTime")
-	endTimeStr := c.Query("endTime")
-	
-	var startTime, endTime time.Time
-	var err error
-	
-	if startTimeStr != "" {
-		startTime, err = time.Parse(time.RFC3339, startTimeStr)
+	startTime, endTime, ok := h.heatmapTimeRange(c)
+	if !ok {
+		return
+	}
+
+	heatmapData, err := h.serviceFor(c).GetHeatmapData(pagePath, startTime, endTime)
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, err, "Failed to get heatmap data")
+		return
+	}
+
+	h.sendSuccess(c, heatmapData)
+}
+
+func (h *Handler) GetHeatmapImage(c *gin.Context) {
+	pagePath := c.Param("pagePath")
+	if pagePath == "" {
+		h.sendError(c, http.StatusBadRequest, nil, "Page path is required")
+		return
+	}
+
+	startTime, endTime, ok := h.heatmapTimeRange(c)
+	if !ok {
+		return
+	}
+
+	width, height := core.DefaultHeatmapImageWidth, core.DefaultHeatmapImageHeight
+	if widthStr := c.Query("width"); widthStr != "" {
+		parsed, err := strconv.Atoi(widthStr)
 		if err != nil {
-			h.sendError(c, http.StatusBadRequest, err, "Invalid start time format")
+			h.sendError(c, http.StatusBadRequest, err, "Invalid width")
 			return
 		}
-	} else {
-		startTime = time.Now().Add(-24 * time.Hour)
+		width = parsed
 	}
-	
-	if endTimeStr != "" {
-		endTime, err = time.Parse(time.RFC3339, endTimeStr)
+	if heightStr := c.Query("height"); heightStr != "" {
+		parsed, err := strconv.Atoi(heightStr)
 		if err != nil {
-			h.sendError(c, http.StatusBadRequest, err, "Invalid end time format")
+			h.sendError(c, http.StatusBadRequest, err, "Invalid height")
 			return
 		}
-	} else {
-		endTime = time.Now()
+		height = parsed
+	}
+	if width <= 0 || height <= 0 || width > core.MaxHeatmapImageDimension || height > core.MaxHeatmapImageDimension {
+		h.sendError(c, http.StatusBadRequest, nil, "Width and height must be between 1 and 4096")
+		return
 	}
 
-	heatmapData, err := h.service.GetHeatmapData(pagePath, startTime, endTime)
+	image, err := h.serviceFor(c).GetHeatmapImage(pagePath, startTime, endTime, width, height)
 	if err != nil {
-		h.sendError(c, http.StatusInternalServerError, err, "Failed to get heatmap data")
+		h.sendError(c, http.StatusInternalServerError, err, "Failed to render heatmap")
 		return
 	}
 
-	h.sendSuccess(c, heatmapData)
+	c.Data(http.StatusOK, "image/png", image)
+}
+
+// heatmapTimeRange reads startTime/endTime (RFC 3339), defaulting to the last 24 hours.
+func (h *Handler) heatmapTimeRange(c *gin.Context) (time.Time, time.Time, bool) {
+	startTime := time.Now().Add(-24 * time.Hour)
+	endTime := time.Now()
+
+	if startTimeStr := c.Query("startTime"); startTimeStr != "" {
+		parsed, err := time.Parse(time.RFC3339, startTimeStr)
+		if err != nil {
+			h.sendError(c, http.StatusBadRequest, err, "Invalid start time format")
+			return startTime, endTime, false
+		}
+		startTime = parsed
+	}
+
+	if endTimeStr := c.Query("endTime"); endTimeStr != "" {
+		parsed, err := time.Parse(time.RFC3339, endTimeStr)
+		if err != nil {
+			h.sendError(c, http.StatusBadRequest, err, "Invalid end time format")
+			return startTime, endTime, false
+		}
+		endTime = parsed
+	}
+
+	return startTime, endTime, true
 }
 
 func (h *Handler) GenerateReport(c *gin.Context) {
@@ -351,7 +584,7 @@ func (h *Handler) GenerateReport(c *gin.Context) {
 		return
 	}
 
-	report, err := h.service.GenerateReport(request)
+	report, err := h.serviceFor(c).GenerateReport(request)
 	if err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to generate report")
 		return
@@ -360,6 +593,41 @@ func (h *Handler) GenerateReport(c *gin.Context) {
 	h.sendSuccess(c, report)
 }
 
+// CompareReports diffs two reports, given inline or by the IDs of saved reports. The
+// compare report is measured against the base one.
+func (h *Handler) CompareReports(c *gin.Context) {
+	var request core.ReportComparisonRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.sendError(c, http.StatusBadRequest, err, "Invalid request data")
+		return
+	}
+
+	service := h.serviceFor(c)
+	base, err := h.comparedReport(service, request.Base, request.BaseReportID)
+	if err != nil {
+		h.sendError(c, http.StatusNotFound, err, "Base report not found")
+		return
+	}
+	compare, err := h.comparedReport(service, request.Compare, request.CompareReportID)
+	if err != nil {
+		h.sendError(c, http.StatusNotFound, err, "Compare report not found")
+		return
+	}
+	if base == nil || compare == nil {
+		h.sendError(c, http.StatusBadRequest, nil, "Both a base and a compare report are required")
+		return
+	}
+
+	h.sendSuccess(c, service.CompareAnalyticsReports(base, compare))
+}
+
+func (h *Handler) comparedReport(service AnalyticsService, inline *core.AnalyticsReport, reportID string) (*core.AnalyticsReport, error) {
+	if inline != nil || reportID == "" {
+		return inline, nil
+	}
+	return service.GetReport(reportID)
+}
+
 func (h *Handler) GenerateSummary(c *gin.Context) {
 	startTimeStr := c.Query("startTime")
 	endTimeStr := c.Query("endTime")
@@ -387,7 +655,7 @@ func (h *Handler) GenerateSummary(c *gin.Context) {
 		endTime = time.Now()
 	}
 
-	summary, err := h.service.GenerateSummary(startTime, endTime)
+	summary, err := h.serviceFor(c).GenerateSummary(startTime, endTime)
 	if err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to generate summary")
 		return
@@ -429,7 +697,7 @@ func (h *Handler) GenerateFunnelReport(c *gin.Context) {
 		endTime = time.Now()
 	}
 
-	report, err := h.service.GenerateFunnelReport(funnelID, startTime, endTime)
+	report, err := h.serviceFor(c).GenerateFunnelReport(funnelID, startTime, endTime)
 	if err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to generate funnel report")
 		return
@@ -465,7 +733,7 @@ func (h *Handler) GeneratePerformanceReport(c *gin.Context) {
 		endTime = time.Now()
 	}
 
-	report, err := h.service.GeneratePerformanceReport(startTime, endTime)
+	report, err := h.serviceFor(c).GeneratePerformanceReport(startTime, endTime)
 	if err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to generate performance report")
 		return
@@ -474,6 +742,23 @@ func (h *Handler) GeneratePerformanceReport(c *gin.Context) {
 	h.sendSuccess(c, report)
 }
 
+// GetWebVitalsReport rates the p75 Core Web Vitals of the page given by ?page=, or of
+// every page when it is omitted, over startTime/endTime (default: the last 24 hours).
+func (h *Handler) GetWebVitalsReport(c *gin.Context) {
+	startTime, endTime, ok := h.heatmapTimeRange(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.serviceFor(c).GetWebVitalsReport(c.Query("page"), startTime, endTime)
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, err, "Failed to generate web vitals report")
+		return
+	}
+
+	h.sendSuccess(c, report)
+}
+
 func (h *Handler) GenerateBehavioralReport(c *gin.Context) {
 	startTimeStr := c.Query("startTime")
 	endTimeStr := c.Query("endTime")
@@ -501,7 +786,7 @@ func (h *Handler) GenerateBehavioralReport(c *gin.Context) {
 		endTime = time.Now()
 	}
 
-	report, err := h.service.GenerateBehavioralReport(startTime, endTime)
+	report, err := h.serviceFor(c).GenerateBehavioralReport(startTime, endTime)
 	if err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to generate behavioral report")
 		return
@@ -511,11 +796,38 @@ func (h *Handler) GenerateBehavioralReport(c *gin.Context) {
 }
 
 func (h *Handler) SubscribeToRealTimeMetrics(c *gin.Context) {
-	response := core.AnalyticsResponse{
-		Success: true,
-		Message: "Real-time metrics subscription not implemented yet",
+	options := core.SubscriberOptions{
+		DropPolicy: core.DropPolicy(c.Query("dropPolicy")),
 	}
-	h.sendSuccess(c, response)
+	if bufferSize := c.Query("bufferSize"); bufferSize != "" {
+		size, err := strconv.Atoi(bufferSize)
+		if err != nil {
+			h.sendError(c, http.StatusBadRequest, err, "Invalid bufferSize")
+			return
+		}
+		options.BufferSize = size
+	}
+
+	subscriberID, metrics, err := h.serviceFor(c).SubscribeWithOptions(c.Request.Context(), options)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, err, "Failed to subscribe to real-time metrics")
+		return
+	}
+	defer h.serviceFor(c).UnsubscribeFromRealTimeMetrics(subscriberID)
+
+	c.Header("X-Subscriber-ID", subscriberID)
+	c.Stream(func(w io.Writer) bool {
+		snapshot, ok := <-metrics
+		if !ok {
+			return false
+		}
+		c.SSEvent("metrics", snapshot)
+		return true
+	})
+}
+
+func (h *Handler) GetSubscriberStats(c *gin.Context) {
+	h.sendSuccess(c, h.serviceFor(c).GetSubscriberStats())
 }
 
 func (h *Handler) CleanupOldData(c *gin.Context) {
@@ -531,7 +843,7 @@ func (h *Handler) CleanupOldData(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.CleanupOldData(olderThan); err != nil {
+	if err := h.serviceFor(c).CleanupOldData(olderThan); err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to cleanup old data")
 		return
 	}
@@ -544,7 +856,7 @@ func (h *Handler) CleanupOldData(c *gin.Context) {
 }
 
 func (h *Handler) GetStats(c *gin.Context) {
-	stats, err := h.service.GetStats()
+	stats, err := h.serviceFor(c).GetStats()
 	if err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to get stats")
 		return
@@ -553,6 +865,73 @@ func (h *Handler) GetStats(c *gin.Context) {
 	h.sendSuccess(c, stats)
 }
 
+func (h *Handler) CreateFunnel(c *gin.Context) {
+	var funnel core.FunnelDefinition
+	if err := c.ShouldBindJSON(&funnel); err != nil {
+		h.sendError(c, http.StatusBadRequest, err, "Invalid request data")
+		return
+	}
+
+	created, err := h.serviceFor(c).CreateFunnel(funnel)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, err, "Failed to create funnel")
+		return
+	}
+
+	h.sendSuccess(c, created, "Funnel created successfully")
+}
+
+func (h *Handler) ListFunnels(c *gin.Context) {
+	funnels, err := h.serviceFor(c).ListFunnels()
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, err, "Failed to list funnels")
+		return
+	}
+
+	h.sendSuccess(c, funnels)
+}
+
+func (h *Handler) GetFunnel(c *gin.Context) {
+	funnel, err := h.serviceFor(c).GetFunnel(c.Param("funnelId"))
+	if err != nil {
+		h.sendError(c, http.StatusNotFound, err, "Funnel not found")
+		return
+	}
+
+	h.sendSuccess(c, funnel)
+}
+
+func (h *Handler) UpdateFunnel(c *gin.Context) {
+	var funnel core.FunnelDefinition
+	if err := c.ShouldBindJSON(&funnel); err != nil {
+		h.sendError(c, http.StatusBadRequest, err, "Invalid request data")
+		return
+	}
+	funnel.ID = c.Param("funnelId")
+
+	if _, err := h.serviceFor(c).GetFunnel(funnel.ID); err != nil {
+		h.sendError(c, http.StatusNotFound, err, "Funnel not found")
+		return
+	}
+
+	updated, err := h.serviceFor(c).UpdateFunnel(funnel)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, err, "Failed to update funnel")
+		return
+	}
+
+	h.sendSuccess(c, updated, "Funnel updated successfully")
+}
+
+func (h *Handler) DeleteFunnel(c *gin.Context) {
+	if err := h.serviceFor(c).DeleteFunnel(c.Param("funnelId")); err != nil {
+		h.sendError(c, http.StatusNotFound, err, "Funnel not found")
+		return
+	}
+
+	h.sendSuccess(c, nil, "Funnel deleted successfully")
+}
+
 func (h *Handler) sendSuccess(c *gin.Context, data interface{}, message ...string) {
 	response := core.AnalyticsResponse{
 		Success: true,
@@ -565,9 +944,12 @@ func (h *Handler) sendSuccess(c *gin.Context, data interface{}, message ...strin
 }
 
 func (h *Handler) sendError(c *gin.Context, statusCode int, err error, message ...string) {
+	apiErr, statusCode := types.ToAPIError(err, statusCode, types.ErrCodeInternal)
 	response := core.AnalyticsResponse{
 		Success: false,
-		Error:   err.Error(),
+		Error:   apiErr.Message,
+		Code:    string(apiErr.Code),
+		Details: apiErr.Details,
 	}
 	if len(message) > 0 {
 		response.Message = message[0]
@@ -0,0 +1,124 @@
+package analytics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cherry-pick/pkg/analytics/core"
+	"github.com/gin-gonic/gin"
+)
+
+// mockService records what is tracked. Any call it does not override panics on the nil
+// embedded interface.
+type mockService struct {
+	AnalyticsService
+	pageViews    []core.PageViewEvent
+	customEvents []core.AnalyticsEvent
+}
+
+func (m *mockService) TrackPageView(event core.PageViewEvent) error {
+	m.pageViews = append(m.pageViews, event)
+	return nil
+}
+
+func (m *mockService) TrackCustomEvent(event core.AnalyticsEvent) error {
+	m.customEvents = append(m.customEvents, event)
+	return nil
+}
+
+func newTestRouter(service AnalyticsService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	SetupRoutes(router.Group("/api"), NewHandler(service))
+	return router
+}
+
+func serve(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestBatchRejectsOnlyTheInvalidItem(t *testing.T) {
+	service := &mockService{}
+	router := newTestRouter(service)
+
+	w := serve(router, http.MethodPost, "/api/analytics/batch", `{"events":[
+		{"type":"pageview","event":{"sessionId":"s1","path":"/home"}},
+		{"type":"pageview","event":"not an object"},
+		{"type":"custom","event":{"sessionId":"s1","type":"signup"}}
+	]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Data core.BatchResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Expected a JSON response, got %v", err)
+	}
+	if response.Data.Accepted != 2 || response.Data.Rejected != 1 {
+		t.Errorf("Expected 2 accepted and 1 rejected, got %d and %d", response.Data.Accepted, response.Data.Rejected)
+	}
+	if len(response.Data.Results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(response.Data.Results))
+	}
+	for i, result := range response.Data.Results {
+		if result.Index != i {
+			t.Errorf("Expected result %d to have index %d, got %d", i, i, result.Index)
+		}
+		if wantSuccess := i != 1; result.Success != wantSuccess {
+			t.Errorf("Expected result %d success=%v, got %v (%s)", i, wantSuccess, result.Success, result.Error)
+		}
+	}
+	if response.Data.Results[1].Error == "" {
+		t.Error("Expected an error message for the invalid item")
+	}
+
+	if len(service.pageViews) != 1 || len(service.customEvents) != 1 {
+		t.Errorf("Expected 1 page view and 1 custom event tracked, got %d and %d",
+			len(service.pageViews), len(service.customEvents))
+	}
+}
+
+func TestBatchRejectsUnknownEventType(t *testing.T) {
+	router := newTestRouter(&mockService{})
+
+	w := serve(router, http.MethodPost, "/api/analytics/batch", `{"events":[{"type":"click","event":{}}]}`)
+
+	var response struct {
+		Data core.BatchResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Expected a JSON response, got %v", err)
+	}
+	if response.Data.Rejected != 1 || !strings.Contains(response.Data.Results[0].Error, "unsupported event type") {
+		t.Errorf("Expected the unknown type to be rejected, got %+v", response.Data)
+	}
+}
+
+func TestBatchLimits(t *testing.T) {
+	router := newTestRouter(&mockService{})
+
+	w := serve(router, http.MethodPost, "/api/analytics/batch", `{"events":[]}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an empty batch, got %d", w.Code)
+	}
+
+	events := make([]string, core.MaxBatchEvents+1)
+	for i := range events {
+		events[i] = `{"type":"custom","event":{}}`
+	}
+	w = serve(router, http.MethodPost, "/api/analytics/batch", `{"events":[`+strings.Join(events, ",")+`]}`)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413 for %d events, got %d", len(events), w.Code)
+	}
+}
@@ -4,13 +4,15 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRoutes(router *gin.RouterGroup, handler *Handler) {
-	analytics := router.Group("/analytics")
+func SetupRoutes(router *gin.RouterGroup, handler *Handler, ingestMiddleware ...gin.HandlerFunc) {
+	analytics := router.Group("/analytics", handler.serviceMiddleware())
 	{
-		analytics.POST("/track/pageview", handler.TrackPageView)
-		analytics.POST("/track/behavior", handler.TrackBehavioralPattern)
-		analytics.POST("/track/performance", handler.TrackPerformance)
-		analytics.POST("/track/event", handler.TrackCustomEvent)
+		ingest := analytics.Group("", ingestMiddleware...)
+		ingest.POST("/track/pageview", handler.TrackPageView)
+		ingest.POST("/track/behavior", handler.TrackBehavioralPattern)
+		ingest.POST("/track/performance", handler.TrackPerformance)
+		ingest.POST("/track/event", handler.TrackCustomEvent)
+		ingest.POST("/batch", handler.TrackBatch)
 		
 		analytics.POST("/sessions", handler.CreateSession)
 		analytics.GET("/sessions/:sessionId", handler.GetSession)
@@ -18,19 +20,29 @@ func SetupRoutes(router *gin.RouterGroup, handler *Handler) {
 		analytics.DELETE("/sessions/:sessionId", handler.EndSession)
 		
 		analytics.GET("/journey/:sessionId", handler.GetUserJourney)
+		analytics.GET("/attribution", handler.GetAttributionReport)
 		analytics.GET("/funnel/:funnelId", handler.GetFunnelAnalysis)
+		analytics.POST("/funnels", handler.CreateFunnel)
+		analytics.GET("/funnels", handler.ListFunnels)
+		analytics.GET("/funnels/:funnelId", handler.GetFunnel)
+		analytics.PUT("/funnels/:funnelId", handler.UpdateFunnel)
+		analytics.DELETE("/funnels/:funnelId", handler.DeleteFunnel)
 		analytics.GET("/realtime", handler.GetRealTimeMetrics)
 		analytics.GET("/insights", handler.GetInsights)
 		analytics.GET("/alerts", handler.GetAlerts)
 		analytics.GET("/heatmap/:pagePath", handler.GetHeatmapData)
+		analytics.GET("/heatmap/:pagePath/image", handler.GetHeatmapImage)
 		
 		analytics.POST("/reports", handler.GenerateReport)
+		analytics.POST("/reports/compare", handler.CompareReports)
 		analytics.GET("/summary", handler.GenerateSummary)
 		analytics.GET("/funnel/:funnelId/report", handler.GenerateFunnelReport)
 		analytics.GET("/performance/report", handler.GeneratePerformanceReport)
+		analytics.GET("/performance/web-vitals", handler.GetWebVitalsReport)
 		analytics.GET("/behavioral/report", handler.GenerateBehavioralReport)
 		
 		analytics.GET("/stream", handler.SubscribeToRealTimeMetrics)
+		analytics.GET("/stream/subscribers", handler.GetSubscriberStats)
 		
 		analytics.POST("/cleanup", handler.CleanupOldData)
 		analytics.GET("/stats", handler.GetStats)
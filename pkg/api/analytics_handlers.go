@@ -7,7 +7,6 @@ import (
 
 	"github.com/cherry-pick/pkg/analytics"
 	"github.com/gin-gonic/gin"
-	"github.com/gorilla/websocket"
 )
 
 var analyticsTracker *analytics.Tracker
@@ -16,8 +15,11 @@ var analyticsTracker *analytics.Tracker
 func InitializeAnalytics() {
 	analyticsTracker = analytics.NewTracker()
 
-	// Start terminal UI
-	analyticsTracker.StartTerminalUI()
+	// Start terminal UI only when stdout is interactive, so services and containers
+	// without a TTY don't get ANSI escapes mixed into their logs
+	if analytics.DefaultTrackerOptions().TerminalUI {
+		analyticsTracker.StartTerminalUI()
+	}
 }
 
 // TrackPageView handles page view tracking
@@ -161,49 +163,6 @@ func (s *Server) getFunnelAnalysis(c *gin.Context) {
 	})
 }
 
-// GetAnalyticsInsights returns generated insights
-func (s *Server) getAnalyticsInsights(c *gin.Context) {
-	// Get insights from the tracker
-	// This would be implemented to return insights based on query parameters
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data": gin.H{
-			"insights": []gin.H{
-				{
-					"id":          "insight-1",
-					"type":        "performance",
-					"title":       "Slow Page Load Detected",
-					"description": "Multiple pages are loading slower than recommended",
-					"impact":      "high",
-					"confidence":  0.9,
-					"timestamp":   time.Now(),
-					"actionable":  true,
-					"recommendations": []string{
-						"Optimize images and assets",
-						"Enable compression",
-						"Use a CDN",
-					},
-				},
-				{
-					"id":          "insight-2",
-					"type":        "user_behavior",
-					"title":       "Low Engagement Detected",
-					"description": "Users are not engaging deeply with content",
-					"impact":      "medium",
-					"confidence":  0.7,
-					"timestamp":   time.Now(),
-					"actionable":  true,
-					"recommendations": []string{
-						"Improve content quality",
-						"Add engaging visual elements",
-						"Optimize page layout",
-					},
-				},
-			},
-		},
-	})
-}
-
 // GetAnalyticsReport generates a comprehensive analytics report
 func (s *Server) getAnalyticsReport(c *gin.Context) {
 	period := c.DefaultQuery("period", "day")
@@ -252,13 +211,13 @@ func (s *Server) getAnalyticsReport(c *gin.Context) {
 
 // SubscribeToRealTimeAnalytics handles WebSocket connection for real-time analytics
 func (s *Server) subscribeToRealTimeAnalytics(c *gin.Context) {
-	// Upgrade to WebSocket
-	upgrader := websocket.Upgrader{
-		CheckOrigin: func(r *http.Request) bool {
-			return true // Allow all origins for development
-		},
+	if !s.authorizeRealTime(c) {
+		return
 	}
 
+	// Upgrade to WebSocket
+	upgrader := s.realTimeAuth.upgrader()
+
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
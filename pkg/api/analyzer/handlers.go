@@ -1,15 +1,23 @@
 package analyzer
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
 	"net/http"
 	"strconv"
 
 	"github.com/cherry-pick/pkg/analyzer/core"
+	"github.com/cherry-pick/pkg/types"
+	"github.com/cherry-pick/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 type Handler struct {
-	service AnalyzerService
+	service     AnalyzerService
+	safeMode    *SafeModePolicy
+	workspaceOf WorkspaceFunc
 }
 
 type AnalyzerService interface {
@@ -28,12 +36,16 @@ func NewHandler(service AnalyzerService) *Handler {
 }
 
 func (h *Handler) AnalyzeDatabase(c *gin.Context) {
-	var request core.AnalysisRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
+	request, err := bindAnalysisRequest(c)
+	if err != nil {
 		h.sendError(c, http.StatusBadRequest, err, "Invalid request data")
 		return
 	}
-
+	if err := h.safeMode.check(c, request); err != nil {
+		h.sendError(c, http.StatusPreconditionFailed, err, "Confirmation required")
+		return
+	}
+	request.WorkspaceID = h.workspace(c)
 	result, err := h.service.AnalyzeDatabase(c.Request.Context(), request)
 	if err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to analyze database")
@@ -43,6 +55,66 @@ func (h *Handler) AnalyzeDatabase(c *gin.Context) {
 	h.sendSuccess(c, result, "Database analysis completed successfully")
 }
 
+// bindAnalysisRequest expands the preset (body or ?preset=) first and then decodes the body
+// over it, so any option the client sends explicitly overrides the preset's value. Options
+// given as query parameters are applied last. The body may be empty when everything is
+// passed in the query.
+func bindAnalysisRequest(c *gin.Context) (core.AnalysisRequest, error) {
+	var request core.AnalysisRequest
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return request, err
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		body = []byte("{}")
+	}
+
+	var peek struct {
+		Preset core.AnalysisPreset `json:"preset"`
+	}
+	if err := json.Unmarshal(body, &peek); err != nil {
+		return request, err
+	}
+	preset := peek.Preset
+	if preset == "" {
+		preset = core.AnalysisPreset(c.Query("preset"))
+	}
+
+	if preset != "" {
+		options, err := core.PresetOptions(preset)
+		if err != nil {
+			return request, types.NewAPIError(types.ErrCodeInvalidRequest, err.Error())
+		}
+		request.Preset = preset
+		request.Options = options
+	}
+
+	if err := json.Unmarshal(body, &request); err != nil {
+		return request, err
+	}
+	if request.Preset == "" {
+		request.Preset = preset
+	}
+	if err := applyQueryOptions(c, &request.Options); err != nil {
+		return request, err
+	}
+	if err := binding.Validator.ValidateStruct(&request); err != nil {
+		return request, err
+	}
+	return request, nil
+}
+
+func (h *Handler) GetAnalysisPresets(c *gin.Context) {
+	presets := make(map[core.AnalysisPreset]core.AnalysisOptions)
+	for _, preset := range core.AnalysisPresets() {
+		options, _ := core.PresetOptions(preset)
+		presets[preset] = options
+	}
+	h.sendSuccess(c, presets, "Analysis presets retrieved successfully")
+}
+
 func (h *Handler) GetAnalysisHistory(c *gin.Context) {
 	limitStr := c.DefaultQuery("limit", "10")
 	limit, err := strconv.Atoi(limitStr)
@@ -51,12 +123,25 @@ func (h *Handler) GetAnalysisHistory(c *gin.Context) {
 		return
 	}
 
-	history, err := h.service.GetAnalysisHistory(c.Request.Context(), limit)
+	// The limit applies after filtering, so the whole history is fetched.
+	history, err := h.service.GetAnalysisHistory(c.Request.Context(), 0)
 	if err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to get analysis history")
 		return
 	}
 
+	workspace := h.workspace(c)
+	visible := []core.AnalysisResult{}
+	for _, result := range history {
+		if result.WorkspaceID == workspace {
+			visible = append(visible, result)
+		}
+	}
+	if limit > 0 && len(visible) > limit {
+		visible = visible[:limit]
+	}
+	history = visible
+
 	h.sendSuccess(c, history, "Analysis history retrieved successfully")
 }
 
@@ -67,7 +152,7 @@ func (h *Handler) GetAnalysisByID(c *gin.Context) {
 		return
 	}
 
-	result, err := h.service.GetAnalysisByID(c.Request.Context(), analysisID)
+	result, err := h.ownedAnalysis(c, analysisID)
 	if err != nil {
 		h.sendError(c, http.StatusNotFound, err, "Analysis not found")
 		return
@@ -83,6 +168,11 @@ func (h *Handler) DeleteAnalysis(c *gin.Context) {
 		return
 	}
 
+	if _, err := h.ownedAnalysis(c, analysisID); err != nil {
+		h.sendError(c, http.StatusNotFound, err, "Analysis not found")
+		return
+	}
+
 	err := h.service.DeleteAnalysis(c.Request.Context(), analysisID)
 	if err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to delete analysis")
@@ -110,15 +200,20 @@ func (h *Handler) sendSuccess(c *gin.Context, data interface{}, message ...strin
 	if len(message) > 0 {
 		response["message"] = message[0]
 	}
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, utils.WithEmptySlices(response))
 }
 
 func (h *Handler) sendError(c *gin.Context, statusCode int, err error, message ...string) {
+	apiErr, statusCode := types.ToAPIError(err, statusCode, types.ErrCodeDatabaseError)
 	response := map[string]interface{}{
 		"success": false,
+		"code":    apiErr.Code,
 	}
 	if err != nil {
-		response["error"] = err.Error()
+		response["error"] = apiErr.Message
+	}
+	if apiErr.Details != nil {
+		response["details"] = apiErr.Details
 	}
 	if len(message) > 0 {
 		response["message"] = message[0]
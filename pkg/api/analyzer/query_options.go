@@ -0,0 +1,112 @@
+package analyzer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cherry-pick/pkg/analyzer/core"
+	"github.com/cherry-pick/pkg/types"
+	"github.com/gin-gonic/gin"
+)
+
+// applyQueryOptions overrides options with any analysis options given as query parameters,
+// e.g. ?includeData=true&includeTables=users,orders&schema=sales,hr. Query values take precedence over both
+// the preset and the request body.
+func applyQueryOptions(c *gin.Context, options *core.AnalysisOptions) error {
+	flags := map[string]*bool{
+		"includeSchema":       &options.IncludeSchema,
+		"includeData":         &options.IncludeData,
+		"includeIndexes":      &options.IncludeIndexes,
+		"includeRelations":    &options.IncludeRelations,
+		"includePerformance":  &options.IncludePerformance,
+		"refresh":             &options.Refresh,
+		"approximateDistinct": &options.ApproximateDistinct,
+	}
+	for name, target := range flags {
+		raw, ok := c.GetQuery(name)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return invalidQueryOption(name, raw, "expected true or false")
+		}
+		*target = value
+	}
+
+	numbers := map[string]*int{
+		"sampleSize":            &options.SampleSize,
+		"maxCollections":        &options.MaxCollections,
+		"maxTables":             &options.MaxTables,
+		"profileTimeoutSeconds": &options.ProfileTimeoutSeconds,
+		"maxDocumentDepth":      &options.MaxDocumentDepth,
+		"maxTrackedFields":      &options.MaxTrackedFields,
+		"topValues":             &options.TopValues,
+	}
+	for name, target := range numbers {
+		raw, ok := c.GetQuery(name)
+		if !ok {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			return invalidQueryOption(name, raw, "expected a non-negative integer")
+		}
+		*target = value
+	}
+
+	if raw, ok := c.GetQuery("samplingStrategy"); ok {
+		strategy := core.SamplingStrategy(raw)
+		switch strategy {
+		case core.SamplingStrategyFirst, core.SamplingStrategyRandom, core.SamplingStrategyRecent:
+			options.SamplingStrategy = strategy
+		default:
+			return invalidQueryOption("samplingStrategy", raw, "expected first, random or recent")
+		}
+	}
+
+	if raw, ok := c.GetQuery("includeTables"); ok {
+		options.IncludeTables = splitTableList(raw)
+	}
+	if raw, ok := c.GetQuery("excludeTables"); ok {
+		options.ExcludeTables = splitTableList(raw)
+	}
+	if raw, ok := c.GetQuery("schema"); ok {
+		options.Schema = ""
+		options.Schemas = splitTableList(raw)
+	}
+
+	return validateOptionCombination(*options)
+}
+
+// validateOptionCombination rejects a table pattern that is both included and excluded,
+// since exclusion would otherwise silently win.
+func validateOptionCombination(options core.AnalysisOptions) error {
+	excluded := make(map[string]bool, len(options.ExcludeTables))
+	for _, table := range options.ExcludeTables {
+		excluded[strings.ToLower(table)] = true
+	}
+	for _, table := range options.IncludeTables {
+		if excluded[strings.ToLower(table)] {
+			return types.NewAPIError(types.ErrCodeInvalidRequest,
+				fmt.Sprintf("table %q is both included and excluded", table))
+		}
+	}
+	return nil
+}
+
+func splitTableList(raw string) []string {
+	var tables []string
+	for _, table := range strings.Split(raw, ",") {
+		if table = strings.TrimSpace(table); table != "" {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+func invalidQueryOption(name, value, expected string) error {
+	return types.NewAPIError(types.ErrCodeInvalidRequest,
+		fmt.Sprintf("invalid value %q for %s: %s", value, name, expected))
+}
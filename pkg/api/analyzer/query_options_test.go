@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/cherry-pick/pkg/analyzer/core"
+)
+
+// recordingService keeps the last request the handler passed to the service.
+type recordingService struct {
+	*mockService
+	request core.AnalysisRequest
+}
+
+func (r *recordingService) AnalyzeDatabase(ctx context.Context, request core.AnalysisRequest) (*core.AnalysisResult, error) {
+	r.request = request
+	return r.mockService.AnalyzeDatabase(ctx, request)
+}
+
+func TestQueryOptionsReachTheService(t *testing.T) {
+	service := &recordingService{mockService: newMockService()}
+	router := newWorkspaceRouter(service)
+
+	w := serve(router, http.MethodPost,
+		"/api/analyzer/analyze?includeData=true&includePerformance=false&sampleSize=250&samplingStrategy=random&includeTables=users,orders&refresh=1",
+		"workspace-a", `{"databaseType":"postgres","connectionId":"conn-1","options":{"includePerformance":true,"sampleSize":10}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	options := service.request.Options
+	if !options.IncludeData || options.IncludePerformance || !options.Refresh {
+		t.Errorf("Expected the query flags to override the body, got %+v", options)
+	}
+	if options.SampleSize != 250 {
+		t.Errorf("Expected sample size 250, got %d", options.SampleSize)
+	}
+	if options.SamplingStrategy != core.SamplingStrategyRandom {
+		t.Errorf("Expected the random sampling strategy, got %q", options.SamplingStrategy)
+	}
+	if want := []string{"users", "orders"}; !reflect.DeepEqual(options.IncludeTables, want) {
+		t.Errorf("Expected include tables %v, got %v", want, options.IncludeTables)
+	}
+	if service.request.ConnectionID != "conn-1" {
+		t.Errorf("Expected the body's connection conn-1, got %q", service.request.ConnectionID)
+	}
+}
+
+func TestQueryOptionsWithEmptyBody(t *testing.T) {
+	service := &recordingService{mockService: newMockService()}
+	router := newWorkspaceRouter(service)
+
+	w := serve(router, http.MethodPost, "/api/analyzer/analyze?maxCollections=5", "workspace-a", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 with an empty body, got %d: %s", w.Code, w.Body.String())
+	}
+	if service.request.Options.MaxCollections != 5 {
+		t.Errorf("Expected max collections 5, got %d", service.request.Options.MaxCollections)
+	}
+}
+
+func TestInvalidQueryOptionsAreRejected(t *testing.T) {
+	queries := []string{
+		"includeData=maybe",
+		"sampleSize=-1",
+		"sampleSize=lots",
+		"samplingStrategy=everything",
+		"includeTables=users&excludeTables=USERS",
+	}
+
+	for _, query := range queries {
+		service := &recordingService{mockService: newMockService()}
+		router := newWorkspaceRouter(service)
+
+		w := serve(router, http.MethodPost, "/api/analyzer/analyze?"+query, "workspace-a", "")
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", query, w.Code)
+		}
+		if service.mockService.nextID != 0 {
+			t.Errorf("Expected no analysis to run for %s", query)
+		}
+	}
+}
@@ -13,5 +13,6 @@ func SetupRoutes(router *gin.RouterGroup, handler *Handler) {
 		analyzer.DELETE("/:id", handler.DeleteAnalysis)
 		analyzer.GET("/types", handler.GetSupportedDatabaseTypes)
 		analyzer.GET("/options", handler.GetAnalysisOptions)
+		analyzer.GET("/presets", handler.GetAnalysisPresets)
 	}
 }
@@ -0,0 +1,51 @@
+package analyzer
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cherry-pick/pkg/analyzer/core"
+	"github.com/cherry-pick/pkg/types"
+	"github.com/gin-gonic/gin"
+)
+
+// ConfirmationHeader confirms a deep analysis of a production connection, the same as
+// ?confirm=true.
+const ConfirmationHeader = "X-Confirm-Analysis"
+
+// SafeModePolicy makes analyses that read table data (IncludeData, as in the deep
+// preset) opt-in per request for production connections. Schema-only analyses are
+// never blocked.
+type SafeModePolicy struct {
+	// IsProduction reports whether the connection points at a production database.
+	IsProduction func(connectionID string) bool
+}
+
+// SetSafeMode enables policy for AnalyzeDatabase; nil turns it off.
+func (h *Handler) SetSafeMode(policy *SafeModePolicy) {
+	h.safeMode = policy
+}
+
+// check returns a CONFIRMATION_REQUIRED error when request would read data from a
+// production connection without the caller confirming it.
+func (p *SafeModePolicy) check(c *gin.Context, request core.AnalysisRequest) error {
+	if p == nil || p.IsProduction == nil || !request.Options.IncludeData {
+		return nil
+	}
+	if !p.IsProduction(request.ConnectionID) || analysisConfirmed(c) {
+		return nil
+	}
+
+	return types.NewAPIError(types.ErrCodeConfirmationRequired, fmt.Sprintf(
+		"connection %s is a production connection and this analysis reads table data; "+
+			"repeat the request with ?confirm=true or the %s: true header, or leave out includeData for a schema-only analysis",
+		request.ConnectionID, ConfirmationHeader))
+}
+
+func analysisConfirmed(c *gin.Context) bool {
+	if confirmed, err := strconv.ParseBool(c.Query("confirm")); err == nil && confirmed {
+		return true
+	}
+	confirmed, _ := strconv.ParseBool(c.GetHeader(ConfirmationHeader))
+	return confirmed
+}
@@ -0,0 +1,38 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/cherry-pick/pkg/analyzer/core"
+	"github.com/cherry-pick/pkg/types"
+	"github.com/gin-gonic/gin"
+)
+
+// WorkspaceFunc names the workspace a request acts in.
+type WorkspaceFunc func(c *gin.Context) string
+
+// SetWorkspaceFunc scopes analyses to the workspace fn returns for each request.
+// Without it every analysis is in one shared workspace.
+func (h *Handler) SetWorkspaceFunc(fn WorkspaceFunc) {
+	h.workspaceOf = fn
+}
+
+func (h *Handler) workspace(c *gin.Context) string {
+	if h.workspaceOf == nil {
+		return ""
+	}
+	return h.workspaceOf(c)
+}
+
+// ownedAnalysis loads an analysis and treats one saved by another workspace as missing,
+// so the two cases cannot be told apart.
+func (h *Handler) ownedAnalysis(c *gin.Context, analysisID string) (*core.AnalysisResult, error) {
+	result, err := h.service.GetAnalysisByID(c.Request.Context(), analysisID)
+	if err == nil && result.WorkspaceID != h.workspace(c) {
+		err = types.NewAPIError(types.ErrCodeNotFound, fmt.Sprintf("analysis not found: %s", analysisID))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
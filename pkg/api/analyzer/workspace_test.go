@@ -0,0 +1,151 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cherry-pick/pkg/analyzer/core"
+	"github.com/gin-gonic/gin"
+)
+
+// mockService keeps analyses in a map the way the memory storage does.
+type mockService struct {
+	analyses map[string]*core.AnalysisResult
+	nextID   int
+}
+
+func newMockService() *mockService {
+	return &mockService{analyses: make(map[string]*core.AnalysisResult)}
+}
+
+func (m *mockService) AnalyzeDatabase(ctx context.Context, request core.AnalysisRequest) (*core.AnalysisResult, error) {
+	m.nextID++
+	result := &core.AnalysisResult{
+		ID:           fmt.Sprintf("analysis-%d", m.nextID),
+		DatabaseType: request.DatabaseType,
+		WorkspaceID:  request.WorkspaceID,
+	}
+	m.analyses[result.ID] = result
+	return result, nil
+}
+
+func (m *mockService) GetAnalysisHistory(ctx context.Context, limit int) ([]core.AnalysisResult, error) {
+	history := []core.AnalysisResult{}
+	for i := 1; i <= m.nextID; i++ {
+		if result, exists := m.analyses[fmt.Sprintf("analysis-%d", i)]; exists {
+			history = append(history, *result)
+		}
+		if limit > 0 && len(history) >= limit {
+			break
+		}
+	}
+	return history, nil
+}
+
+func (m *mockService) GetAnalysisByID(ctx context.Context, analysisID string) (*core.AnalysisResult, error) {
+	result, exists := m.analyses[analysisID]
+	if !exists {
+		return nil, fmt.Errorf("analysis not found: %s", analysisID)
+	}
+	return result, nil
+}
+
+func (m *mockService) DeleteAnalysis(ctx context.Context, analysisID string) error {
+	delete(m.analyses, analysisID)
+	return nil
+}
+
+func (m *mockService) GetSupportedDatabaseTypes() []core.DatabaseType {
+	return []core.DatabaseType{core.DatabaseTypePostgres}
+}
+
+func (m *mockService) GetAnalysisOptions() core.AnalysisOptions {
+	return core.AnalysisOptions{}
+}
+
+func newWorkspaceRouter(service AnalyzerService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewHandler(service)
+	handler.SetWorkspaceFunc(func(c *gin.Context) string {
+		return c.GetHeader("X-Workspace-ID")
+	})
+
+	router := gin.New()
+	SetupRoutes(router.Group("/api"), handler)
+	return router
+}
+
+func serve(router *gin.Engine, method, path, workspace, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Workspace-ID", workspace)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func analyzeIn(t *testing.T, router *gin.Engine, workspace string) string {
+	t.Helper()
+
+	w := serve(router, http.MethodPost, "/api/analyzer/analyze", workspace,
+		`{"databaseType":"postgres","connectionId":"conn-1"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 analyzing, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Data core.AnalysisResult `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Expected a JSON response, got %v", err)
+	}
+	return response.Data.ID
+}
+
+func TestWorkspaceCannotReachAnotherWorkspacesAnalysis(t *testing.T) {
+	service := newMockService()
+	router := newWorkspaceRouter(service)
+
+	analysisID := analyzeIn(t, router, "workspace-a")
+
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		w := serve(router, method, "/api/analyzer/"+analysisID, "workspace-b", "")
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for %s from another workspace, got %d", method, w.Code)
+		}
+	}
+	if _, exists := service.analyses[analysisID]; !exists {
+		t.Errorf("Expected the analysis to survive a delete from another workspace")
+	}
+
+	w := serve(router, http.MethodGet, "/api/analyzer/"+analysisID, "workspace-a", "")
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected the owning workspace to read its analysis, got %d", w.Code)
+	}
+}
+
+func TestAnalysisHistoryIsScopedToWorkspace(t *testing.T) {
+	router := newWorkspaceRouter(newMockService())
+
+	analyzeIn(t, router, "workspace-a")
+	analyzeIn(t, router, "workspace-a")
+	own := analyzeIn(t, router, "workspace-b")
+
+	w := serve(router, http.MethodGet, "/api/analyzer/history?limit=1", "workspace-b", "")
+	var history struct {
+		Data []core.AnalysisResult `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &history); err != nil {
+		t.Fatalf("Expected a JSON response, got %v", err)
+	}
+	if len(history.Data) != 1 || history.Data[0].ID != own {
+		t.Errorf("Expected only %s in the history, got %+v", own, history.Data)
+	}
+}
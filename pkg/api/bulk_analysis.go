@@ -0,0 +1,151 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/cherry-pick/pkg/types"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DefaultBulkAnalysisConcurrency = 4
+	MaxBulkAnalysisConcurrency     = 16
+	MaxBulkAnalysisConnections     = 100
+)
+
+type BulkAnalysisRequest struct {
+	ConnectionIDs []string `json:"connectionIds" binding:"required,min=1"`
+	Concurrency   int      `json:"concurrency,omitempty"`
+}
+
+// BulkAnalysisResult holds either the report or the error for one connection.
+type BulkAnalysisResult struct {
+	Report *types.DatabaseReport `json:"report,omitempty"`
+	Error  *types.APIError       `json:"error,omitempty"`
+}
+
+// BulkAnalysisSummary aggregates the successful reports. CriticalConnections lists the
+// connections whose report has at least one high or critical severity insight.
+type BulkAnalysisSummary struct {
+	Requested             int      `json:"requested"`
+	Succeeded             int      `json:"succeeded"`
+	Failed                int      `json:"failed"`
+	TotalTables           int      `json:"totalTables"`
+	WorstHealthScore      *float64 `json:"worstHealthScore,omitempty"`
+	WorstHealthConnection string   `json:"worstHealthConnection,omitempty"`
+	CriticalConnections   []string `json:"criticalConnections"`
+}
+
+type BulkAnalysisResponse struct {
+	Results map[string]BulkAnalysisResult `json:"results"`
+	Summary BulkAnalysisSummary           `json:"summary"`
+}
+
+// analyzeBulk analyzes several connections concurrently. It answers 200 even when some
+// analyses fail; each failure is reported under its connection ID. Connections outside
+// the caller's workspace fail as not found.
+func (s *Server) analyzeBulk(c *gin.Context) {
+	var req BulkAnalysisRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.sendError(c, http.StatusBadRequest, err, "Invalid request body")
+		return
+	}
+
+	ids := uniqueStrings(req.ConnectionIDs)
+	if len(ids) > MaxBulkAnalysisConnections {
+		s.sendError(c, http.StatusBadRequest,
+			types.NewAPIError(types.ErrCodeInvalidRequest, "too many connections in one bulk analysis"),
+			"Invalid request body")
+		return
+	}
+
+	concurrency := req.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBulkAnalysisConcurrency
+	}
+	if concurrency > MaxBulkAnalysisConcurrency {
+		concurrency = MaxBulkAnalysisConcurrency
+	}
+
+	workspace := workspaceID(c)
+	results := make(map[string]BulkAnalysisResult, len(ids))
+	var resultsMu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+
+	for _, id := range ids {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			var result BulkAnalysisResult
+			if !ownsConnection(workspace, id) {
+				result.Error = types.NewAPIError(types.ErrCodeConnectionNotFound, "Connection not found")
+			} else if report, err := s.runAnalysis(c.Request.Context(), id); err != nil {
+				result.Error, _ = types.ToAPIError(err, errorStatus(err, http.StatusInternalServerError), types.ErrCodeDatabaseError)
+			} else {
+				result.Report = report
+			}
+
+			resultsMu.Lock()
+			results[id] = result
+			resultsMu.Unlock()
+		}(id)
+	}
+	wg.Wait()
+
+	s.sendSuccess(c, BulkAnalysisResponse{
+		Results: results,
+		Summary: summarizeBulkAnalysis(ids, results),
+	}, "Bulk analysis completed")
+}
+
+func summarizeBulkAnalysis(ids []string, results map[string]BulkAnalysisResult) BulkAnalysisSummary {
+	summary := BulkAnalysisSummary{
+		Requested:           len(ids),
+		CriticalConnections: []string{},
+	}
+
+	for _, id := range ids {
+		report := results[id].Report
+		if report == nil {
+			summary.Failed++
+			continue
+		}
+		summary.Succeeded++
+		summary.TotalTables += len(report.Tables)
+
+		score := report.Summary.HealthScore
+		if summary.WorstHealthScore == nil || score < *summary.WorstHealthScore {
+			summary.WorstHealthScore = &score
+			summary.WorstHealthConnection = id
+		}
+
+		for _, insight := range report.Insights {
+			if insight.Severity == "critical" || insight.Severity == "high" {
+				summary.CriticalConnections = append(summary.CriticalConnections, id)
+				break
+			}
+		}
+	}
+
+	sort.Strings(summary.CriticalConnections)
+	return summary
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		unique = append(unique, value)
+	}
+	return unique
+}
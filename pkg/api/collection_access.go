@@ -0,0 +1,76 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/cherry-pick/pkg/types"
+	"github.com/gin-gonic/gin"
+)
+
+// CollectionAccess limits which collections of a connection can be browsed or searched.
+// Entries are collection names or path.Match patterns such as "logs_*". Deny wins over
+// Allow, and an empty Allow list allows every collection that is not denied.
+type CollectionAccess struct {
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+}
+
+func (ca *CollectionAccess) Validate() error {
+	for _, pattern := range append(append([]string{}, ca.Allow...), ca.Deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid collection pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func (ca *CollectionAccess) Allows(collection string) bool {
+	if ca == nil {
+		return true
+	}
+	if matchesAny(ca.Deny, collection) {
+		return false
+	}
+	return len(ca.Allow) == 0 || matchesAny(ca.Allow, collection)
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// WithDataBrowsingDisabled turns off the endpoints that return raw documents, whatever
+// each connection's CollectionAccess allows.
+func WithDataBrowsingDisabled(disabled bool) ServerOption {
+	return func(s *Server) {
+		s.dataBrowsingDisabled = disabled
+	}
+}
+
+// allowCollectionData answers 403 and returns false when documents of collection may
+// not be read through connectionID. It runs before any query reaches the database.
+func (s *Server) allowCollectionData(c *gin.Context, connectionID, collection string) bool {
+	if s.dataBrowsingDisabled {
+		s.sendError(c, http.StatusForbidden,
+			types.NewAPIError(types.ErrCodeForbidden, "Data browsing is disabled"), "Data browsing is disabled on this server")
+		return false
+	}
+
+	mutex.RLock()
+	connection, exists := connections[connectionID]
+	mutex.RUnlock()
+
+	if exists && !connection.CollectionAccess.Allows(collection) {
+		s.sendError(c, http.StatusForbidden,
+			types.NewAPIError(types.ErrCodeForbidden, fmt.Sprintf("Access to collection %q is not allowed", collection)),
+			"Collection access denied")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DefaultStatsSampleDocuments = 100
+	MaxStatsSampleDocuments     = 1000
+	DefaultStatsSampleValues    = 5
+	MaxStatsSampleValues        = 50
+	MaxSampleValueLength        = 200
+)
+
+// collectionSampling bounds how many documents collection stats read and how many
+// sample values each field reports.
+type collectionSampling struct {
+	documents int64
+	values    int
+}
+
+// parseCollectionSampling reads the sampleSize and sampleValues query parameters. Values
+// that are missing or not positive fall back to the defaults; larger ones are capped.
+func parseCollectionSampling(c *gin.Context) collectionSampling {
+	sampling := collectionSampling{
+		documents: DefaultStatsSampleDocuments,
+		values:    DefaultStatsSampleValues,
+	}
+
+	if parsed, err := strconv.ParseInt(c.Query("sampleSize"), 10, 64); err == nil && parsed > 0 {
+		sampling.documents = parsed
+		if sampling.documents > MaxStatsSampleDocuments {
+			sampling.documents = MaxStatsSampleDocuments
+		}
+	}
+	if parsed, err := strconv.Atoi(c.Query("sampleValues")); err == nil && parsed > 0 {
+		sampling.values = parsed
+		if sampling.values > MaxStatsSampleValues {
+			sampling.values = MaxStatsSampleValues
+		}
+	}
+
+	return sampling
+}
+
+// truncateSampleValue keeps at most MaxSampleValueLength characters of value, marking a
+// cut with an ellipsis, so one large document field cannot bloat the response.
+func truncateSampleValue(value string) string {
+	if utf8.RuneCountInString(value) <= MaxSampleValueLength {
+		return value
+	}
+	runes := []rune(value)
+	return string(runes[:MaxSampleValueLength]) + "…"
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cherry-pick/pkg/types"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	EnvironmentDev     = "dev"
+	EnvironmentStaging = "staging"
+	EnvironmentProd    = "prod"
+
+	MaxConnectionTags      = 20
+	MaxConnectionTagLength = 64
+)
+
+// UpdateConnectionRequest changes how a connection is organised. Omitted fields are left
+// as they are; an empty tags list or environment clears them.
+type UpdateConnectionRequest struct {
+	Tags        *[]string `json:"tags,omitempty"`
+	Environment *string   `json:"environment,omitempty"`
+}
+
+// normalizeTags lowercases and trims tags and drops empty or repeated ones, keeping the
+// caller's order.
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > MaxConnectionTagLength {
+			return nil, fmt.Errorf("tag %q is longer than %d characters", tag, MaxConnectionTagLength)
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > MaxConnectionTags {
+		return nil, fmt.Errorf("a connection can have at most %d tags", MaxConnectionTags)
+	}
+	return normalized, nil
+}
+
+func normalizeEnvironment(environment string) (string, error) {
+	environment = strings.ToLower(strings.TrimSpace(environment))
+	switch environment {
+	case "", EnvironmentDev, EnvironmentStaging, EnvironmentProd:
+		return environment, nil
+	}
+	return "", fmt.Errorf("environment must be one of %s, %s or %s", EnvironmentDev, EnvironmentStaging, EnvironmentProd)
+}
+
+// connectionFilter selects connections that carry every tag and, when set, are in
+// environment.
+type connectionFilter struct {
+	tags        []string
+	environment string
+}
+
+func parseConnectionFilter(c *gin.Context) (connectionFilter, error) {
+	tags, err := normalizeTags(c.QueryArray("tag"))
+	if err != nil {
+		return connectionFilter{}, err
+	}
+	environment, err := normalizeEnvironment(c.Query("environment"))
+	if err != nil {
+		return connectionFilter{}, err
+	}
+	return connectionFilter{tags: tags, environment: environment}, nil
+}
+
+func (f connectionFilter) matches(connection *ConnectionInfo) bool {
+	if f.environment != "" && connection.Environment != f.environment {
+		return false
+	}
+	for _, tag := range f.tags {
+		if !hasTag(connection.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, candidate := range tags {
+		if candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) updateConnection(c *gin.Context) {
+	id := c.Param("id")
+
+	var req UpdateConnectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.sendError(c, http.StatusBadRequest, err, "Invalid request data")
+		return
+	}
+
+	var tags []string
+	if req.Tags != nil {
+		var err error
+		if tags, err = normalizeTags(*req.Tags); err != nil {
+			s.sendError(c, http.StatusBadRequest, types.NewAPIError(types.ErrCodeInvalidRequest, err.Error()), "Invalid tags")
+			return
+		}
+	}
+	var environment string
+	if req.Environment != nil {
+		var err error
+		if environment, err = normalizeEnvironment(*req.Environment); err != nil {
+			s.sendError(c, http.StatusBadRequest, types.NewAPIError(types.ErrCodeInvalidRequest, err.Error()), "Invalid environment")
+			return
+		}
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	connection, exists := connections[id]
+	if !exists {
+		s.sendError(c, http.StatusNotFound,
+			types.NewAPIError(types.ErrCodeConnectionNotFound, "Connection not found"), "Connection not found")
+		return
+	}
+
+	if req.Tags != nil {
+		connection.Tags = tags
+	}
+	if req.Environment != nil {
+		connection.Environment = environment
+	}
+
+	s.sendSuccess(c, connection.masked(), "Connection updated successfully")
+}
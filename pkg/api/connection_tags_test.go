@@ -0,0 +1,123 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTaggedServer(t *testing.T) *Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	tagged := []*ConnectionInfo{
+		{ID: "orders-prod", WorkspaceID: "workspace-a", Tags: []string{"orders", "critical"}, Environment: EnvironmentProd},
+		{ID: "orders-dev", WorkspaceID: "workspace-a", Tags: []string{"orders"}, Environment: EnvironmentDev},
+		{ID: "billing-prod", WorkspaceID: "workspace-a", Tags: []string{"billing", "critical"}, Environment: EnvironmentProd},
+		{ID: "other-orders", WorkspaceID: "workspace-b", Tags: []string{"orders", "critical"}, Environment: EnvironmentProd},
+	}
+
+	mutex.Lock()
+	for _, connection := range tagged {
+		connections[connection.ID] = connection
+	}
+	mutex.Unlock()
+	t.Cleanup(func() {
+		mutex.Lock()
+		for _, connection := range tagged {
+			delete(connections, connection.ID)
+		}
+		mutex.Unlock()
+	})
+
+	return NewServer("0", WithWorkspaces(WorkspaceAccess{"workspace-a": "", "workspace-b": ""}))
+}
+
+func listConnectionIDs(t *testing.T, s *Server, query string) []string {
+	t.Helper()
+
+	w := serveWorkspace(s, http.MethodGet, "/api/connections"+query, "workspace-a")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for %s, got %d: %s", query, w.Code, w.Body.String())
+	}
+
+	var listed struct {
+		Data []ConnectionInfo `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("Expected a JSON response, got %v", err)
+	}
+
+	ids := []string{}
+	for _, connection := range listed.Data {
+		ids = append(ids, connection.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestConnectionListFiltersByTagAndEnvironment(t *testing.T) {
+	s := newTaggedServer(t)
+
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"billing-prod", "orders-dev", "orders-prod"}},
+		{"?tag=orders", []string{"orders-dev", "orders-prod"}},
+		{"?tag=ORDERS&tag=critical", []string{"orders-prod"}},
+		{"?environment=prod", []string{"billing-prod", "orders-prod"}},
+		{"?tag=orders&environment=dev", []string{"orders-dev"}},
+		{"?tag=missing", []string{}},
+	}
+
+	for _, c := range cases {
+		if got := listConnectionIDs(t, s, c.query); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Expected %v for %q, got %v", c.want, c.query, got)
+		}
+	}
+}
+
+func TestConnectionListRejectsUnknownEnvironment(t *testing.T) {
+	s := newTaggedServer(t)
+
+	w := serveWorkspace(s, http.MethodGet, "/api/connections?environment=qa", "workspace-a")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestUpdateConnectionNormalizesTags(t *testing.T) {
+	s := newTaggedServer(t)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/connections/orders-dev",
+		strings.NewReader(`{"tags":[" Orders ","team-a","orders",""],"environment":"Staging"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WorkspaceHeader, "workspace-a")
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	mutex.RLock()
+	connection := *connections["orders-dev"]
+	mutex.RUnlock()
+
+	if want := []string{"orders", "team-a"}; !reflect.DeepEqual(connection.Tags, want) {
+		t.Errorf("Expected tags %v, got %v", want, connection.Tags)
+	}
+	if connection.Environment != EnvironmentStaging {
+		t.Errorf("Expected environment %s, got %s", EnvironmentStaging, connection.Environment)
+	}
+	if got := listConnectionIDs(t, s, "?tag=team-a&environment=staging"); !reflect.DeepEqual(got, []string{"orders-dev"}) {
+		t.Errorf("Expected the updated connection in the filtered list, got %v", got)
+	}
+}
@@ -1,13 +1,21 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"sync"
 	"time"
 
+	"github.com/cherry-pick/pkg/connector"
+	"github.com/cherry-pick/pkg/insights"
 	"github.com/cherry-pick/pkg/intelligence"
+	"github.com/cherry-pick/pkg/logging"
+	"github.com/cherry-pick/pkg/security"
 	"github.com/cherry-pick/pkg/types"
+	"github.com/cherry-pick/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -19,31 +27,78 @@ var (
 )
 
 type ConnectionInfo struct {
-	ID               string     `json:"id"`
-	Name             string     `json:"name"`
-	Driver           string     `json:"driver"`
-	ConnectionString string     `json:"connectionString"`
-	Status           string     `json:"status"`
-	LastConnected    *time.Time `json:"lastConnected,omitempty"`
+	ID               string               `json:"id"`
+	Name             string               `json:"name"`
+	Driver           string               `json:"driver"`
+	ConnectionString string               `json:"connectionString"`
+	TLS              *connector.TLSConfig `json:"tls,omitempty"`
+	Status           string               `json:"status"`
+	LastConnected    *time.Time           `json:"lastConnected,omitempty"`
+	WorkspaceID      string               `json:"workspaceId"`
+	CollectionAccess *CollectionAccess    `json:"collectionAccess,omitempty"`
+	Tags             []string             `json:"tags"`
+	Environment      string               `json:"environment,omitempty"`
+}
+
+// masked returns a copy safe to send to clients, with any inline password hidden.
+func (ci *ConnectionInfo) masked() *ConnectionInfo {
+	masked := *ci
+	masked.ConnectionString = connector.RedactDSN(ci.Driver, ci.ConnectionString)
+	masked.Tags = append([]string{}, ci.Tags...)
+	return &masked
 }
 
 type CreateConnectionRequest struct {
-	Name             string `json:"name" binding:"required"`
-	Driver           string `json:"driver" binding:"required"`
-	ConnectionString string `json:"connectionString" binding:"required"`
+	Name             string               `json:"name" binding:"required"`
+	Driver           string               `json:"driver" binding:"required"`
+	ConnectionString string               `json:"connectionString" binding:"required"`
+	TLS              *connector.TLSConfig `json:"tls,omitempty"`
+	CollectionAccess *CollectionAccess    `json:"collectionAccess,omitempty"`
+	Tags             []string             `json:"tags,omitempty"`
+	Environment      string               `json:"environment,omitempty"`
+}
+
+type reportStreamLine struct {
+	Type    string               `json:"type"`
+	Table   *types.TableInfo     `json:"table,omitempty"`
+	Summary *reportStreamSummary `json:"summary,omitempty"`
+	Error   string               `json:"error,omitempty"`
+}
+
+type reportStreamSummary struct {
+	DatabaseName       string                   `json:"database_name"`
+	DatabaseType       string                   `json:"database_type"`
+	AnalysisTime       time.Time                `json:"analysis_time"`
+	TableCount         int                      `json:"table_count"`
+	Summary            types.DatabaseSummary    `json:"summary"`
+	Insights           []types.DatabaseInsight  `json:"insights"`
+	Recommendations    []string                 `json:"recommendations"`
+	PerformanceMetrics types.PerformanceMetrics `json:"performance_metrics"`
 }
 
 type OptimizeQueryRequest struct {
 	Query string `json:"query" binding:"required"`
 }
 
+// getConnections lists the workspace's connections, narrowed by any ?tag= (repeatable,
+// all must match) and ?environment= filters.
 func (s *Server) getConnections(c *gin.Context) {
+	filter, err := parseConnectionFilter(c)
+	if err != nil {
+		s.sendError(c, http.StatusBadRequest, types.NewAPIError(types.ErrCodeInvalidRequest, err.Error()), "Invalid connection filter")
+		return
+	}
+
 	mutex.RLock()
 	defer mutex.RUnlock()
 
+	workspace := workspaceID(c)
 	connectionList := make([]*ConnectionInfo, 0, len(connections))
 	for _, conn := range connections {
-		connectionList = append(connectionList, conn)
+		if conn.WorkspaceID != workspace || !filter.matches(conn) {
+			continue
+		}
+		connectionList = append(connectionList, conn.masked())
 	}
 
 	s.sendSuccess(c, connectionList)
@@ -55,6 +110,28 @@ func (s *Server) createConnection(c *gin.Context) {
 		s.sendError(c, http.StatusBadRequest, err, "Invalid request data")
 		return
 	}
+	if req.TLS != nil {
+		if err := req.TLS.Validate(); err != nil {
+			s.sendError(c, http.StatusBadRequest, types.NewAPIError(types.ErrCodeInvalidRequest, err.Error()), "Invalid TLS configuration")
+			return
+		}
+	}
+	if req.CollectionAccess != nil {
+		if err := req.CollectionAccess.Validate(); err != nil {
+			s.sendError(c, http.StatusBadRequest, types.NewAPIError(types.ErrCodeInvalidRequest, err.Error()), "Invalid collection access")
+			return
+		}
+	}
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		s.sendError(c, http.StatusBadRequest, types.NewAPIError(types.ErrCodeInvalidRequest, err.Error()), "Invalid tags")
+		return
+	}
+	environment, err := normalizeEnvironment(req.Environment)
+	if err != nil {
+		s.sendError(c, http.StatusBadRequest, types.NewAPIError(types.ErrCodeInvalidRequest, err.Error()), "Invalid environment")
+		return
+	}
 
 	mutex.Lock()
 	defer mutex.Unlock()
@@ -65,40 +142,106 @@ func (s *Server) createConnection(c *gin.Context) {
 		Name:             req.Name,
 		Driver:           req.Driver,
 		ConnectionString: req.ConnectionString,
+		TLS:              req.TLS,
 		Status:           "disconnected",
+		WorkspaceID:      workspaceID(c),
+		CollectionAccess: req.CollectionAccess,
+		Tags:             tags,
+		Environment:      environment,
 	}
 
 	connections[id] = connection
-	s.sendSuccess(c, connection, "Connection created successfully")
+	s.sendSuccess(c, connection.masked(), "Connection created successfully")
 }
 
 func (s *Server) testConnection(c *gin.Context) {
 	id := c.Param("id")
 
-	mutex.Lock()
-	defer mutex.Unlock()
-
+	mutex.RLock()
 	connection, exists := connections[id]
+	var driver, connectionString string
+	var tls *connector.TLSConfig
+	if exists {
+		driver, connectionString, tls = connection.Driver, connection.ConnectionString, connection.TLS
+	}
+	mutex.RUnlock()
+
 	if !exists {
 		s.sendError(c, http.StatusNotFound,
-			&APIError{Message: "Connection not found"}, "Connection not found")
+			types.NewAPIError(types.ErrCodeConnectionNotFound, "Connection not found"), "Connection not found")
 		return
 	}
 
-	service, err := intelligence.CreateSimpleService(connection.Driver, connection.ConnectionString)
-	if err != nil {
+	// Connecting can take up to the connect timeout, so it runs without the lock and the
+	// result is swapped in afterwards.
+	service, buildErr := intelligence.NewServiceBuilder(driver, connectionString).
+		WithTLS(tls).
+		WithConnectTimeout(s.connectTimeout).
+		Build()
+
+	mutex.Lock()
+	// The connection may have been deleted or replaced while connecting.
+	if connections[id] != connection {
+		mutex.Unlock()
+		if buildErr == nil {
+			service.Close()
+		}
+		s.sendError(c, http.StatusNotFound,
+			types.NewAPIError(types.ErrCodeConnectionNotFound, "Connection not found"), "Connection not found")
+		return
+	}
+
+	// A failed test drops any earlier service, so later requests see the connection as
+	// unreachable instead of running against a stale pool.
+	previous := services[id]
+	delete(services, id)
+	if buildErr != nil {
 		connection.Status = "error"
+	} else {
+		services[id] = service
+		now := time.Now()
+		connection.Status = "connected"
+		connection.LastConnected = &now
+	}
+	mutex.Unlock()
+
+	if previous != nil {
+		previous.Close()
+	}
+
+	if buildErr != nil {
+		err := connector.RedactError(driver, connectionString, buildErr)
 		s.sendError(c, http.StatusBadRequest, err, "Failed to connect to database")
 		return
 	}
+	s.sendSuccess(c, map[string]string{"status": "connected"}, "Connection test successful")
+}
 
-	services[id] = service
+func (s *Server) getConnectionHealth(c *gin.Context) {
+	id := c.Param("id")
 
-	now := time.Now()
-	connection.Status = "connected"
-	connection.LastConnected = &now
+	mutex.RLock()
+	service, serviceExists := services[id]
+	mutex.RUnlock()
 
-	s.sendSuccess(c, map[string]string{"status": "connected"}, "Connection test successful")
+	if !serviceExists {
+		s.sendError(c, http.StatusBadRequest,
+			types.NewAPIError(types.ErrCodeNotConnected, "Connection not established"), "Please test the connection first")
+		return
+	}
+
+	health := service.Health()
+	if health.Breaker == connector.BreakerOpen {
+		c.JSON(http.StatusServiceUnavailable, APIResponse{
+			Success: false,
+			Data:    health,
+			Error:   connector.ErrCircuitOpen.Error(),
+			Code:    types.ErrCodeUnavailable,
+		})
+		return
+	}
+
+	s.sendSuccess(c, health)
 }
 
 func (s *Server) deleteConnection(c *gin.Context) {
@@ -109,7 +252,7 @@ func (s *Server) deleteConnection(c *gin.Context) {
 
 	if _, exists := connections[id]; !exists {
 		s.sendError(c, http.StatusNotFound,
-			&APIError{Message: "Connection not found"}, "Connection not found")
+			types.NewAPIError(types.ErrCodeConnectionNotFound, "Connection not found"), "Connection not found")
 		return
 	}
 
@@ -120,6 +263,9 @@ func (s *Server) deleteConnection(c *gin.Context) {
 
 	delete(connections, id)
 	delete(reports, id)
+	s.reportHistory.Delete(id)
+	s.savedQueries.DeleteConnection(id)
+	s.statsCache.DeletePrefix(utils.CacheKey(id, ""))
 
 	s.sendSuccess(c, nil, "Connection deleted successfully")
 }
@@ -128,8 +274,13 @@ func (s *Server) getReports(c *gin.Context) {
 	mutex.RLock()
 	defer mutex.RUnlock()
 
+	// Reports are keyed by the connection they were generated for.
+	workspace := workspaceID(c)
 	reportList := make([]*types.DatabaseReport, 0, len(reports))
-	for _, report := range reports {
+	for id, report := range reports {
+		if connection, exists := connections[id]; !exists || connection.WorkspaceID != workspace {
+			continue
+		}
 		reportList = append(reportList, report)
 	}
 
@@ -145,7 +296,7 @@ func (s *Server) getReport(c *gin.Context) {
 	report, exists := reports[id]
 	if !exists {
 		s.sendError(c, http.StatusNotFound,
-			&APIError{Message: "Report not found"}, "Report not found")
+			types.NewAPIError(types.ErrCodeNotFound, "Report not found"), "Report not found")
 		return
 	}
 
@@ -153,31 +304,122 @@ func (s *Server) getReport(c *gin.Context) {
 }
 
 func (s *Server) analyzeDatabase(c *gin.Context) {
+	report, err := s.runAnalysis(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		message := "Failed to analyze database"
+		var apiErr *types.APIError
+		if errors.As(err, &apiErr) {
+			switch apiErr.Code {
+			case types.ErrCodeConnectionNotFound:
+				message = "Connection not found"
+			case types.ErrCodeNotConnected:
+				message = "Please test the connection first"
+			case types.ErrCodeCancelled:
+				message = "Analysis cancelled"
+			}
+		}
+		s.sendError(c, errorStatus(err, http.StatusInternalServerError), err, message)
+		return
+	}
+
+	s.sendSuccess(c, report, "Database analysis completed")
+}
+
+// runAnalysis analyzes connection id and stores the report as its latest and in its
+// history. Missing or untested connections are reported as API errors, as is an
+// analysis stopped through cancelAnalysis or by ctx.
+func (s *Server) runAnalysis(ctx context.Context, id string) (*types.DatabaseReport, error) {
+	mutex.RLock()
+	service, serviceExists := services[id]
+	connection, connExists := connections[id]
+	mutex.RUnlock()
+
+	if !connExists {
+		return nil, types.NewAPIError(types.ErrCodeConnectionNotFound, "Connection not found")
+	}
+	if !serviceExists {
+		return nil, types.NewAPIError(types.ErrCodeNotConnected, "Connection not established")
+	}
+
+	ctx, done := s.analyses.start(ctx, id)
+	defer done()
+
+	report, err := service.AnalyzeDatabaseContext(ctx)
+	if err != nil {
+		return nil, cancelledAnalysisError(connector.RedactError(connection.Driver, connection.ConnectionString, err))
+	}
+
+	mutex.Lock()
+	reports[id] = report
+	mutex.Unlock()
+
+	if err := s.reportHistory.Record(id, report); err != nil {
+		logging.Default().Warn("Failed to record report history", "connection", id, "error", err)
+	}
+	return report, nil
+}
+
+func (s *Server) getRedundantIndexes(c *gin.Context) {
 	id := c.Param("id")
 
 	mutex.RLock()
 	service, serviceExists := services[id]
-	connExists := false
-	if _, exists := connections[id]; exists {
-		connExists = true
+	mutex.RUnlock()
+
+	if !serviceExists {
+		s.sendError(c, http.StatusBadRequest,
+			types.NewAPIError(types.ErrCodeNotConnected, "Connection not established"), "Please test the connection first")
+		return
+	}
+
+	findings, err := service.FindRedundantIndexes()
+	if err != nil {
+		s.sendError(c, errorStatus(err, http.StatusInternalServerError), err, "Failed to find redundant indexes")
+		return
 	}
+
+	s.sendSuccess(c, findings, "Redundant index analysis completed")
+}
+
+func (s *Server) streamAnalysis(c *gin.Context) {
+	id := c.Param("id")
+
+	mutex.RLock()
+	service, serviceExists := services[id]
+	connection, connExists := connections[id]
 	mutex.RUnlock()
 
 	if !connExists {
 		s.sendError(c, http.StatusNotFound,
-			&APIError{Message: "Connection not found"}, "Connection not found")
+			types.NewAPIError(types.ErrCodeConnectionNotFound, "Connection not found"), "Connection not found")
 		return
 	}
 
 	if !serviceExists {
 		s.sendError(c, http.StatusBadRequest,
-			&APIError{Message: "Connection not established"}, "Please test the connection first")
+			types.NewAPIError(types.ErrCodeNotConnected, "Connection not established"), "Please test the connection first")
 		return
 	}
 
-	report, err := service.AnalyzeDatabase()
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Cache-Control", "no-cache")
+	c.Status(http.StatusOK)
+
+	ctx, done := s.analyses.start(c.Request.Context(), id)
+	defer done()
+
+	encoder := json.NewEncoder(c.Writer)
+	report, err := service.AnalyzeDatabaseStream(func(table types.TableInfo) error {
+		if err := encoder.Encode(reportStreamLine{Type: "table", Table: &table}); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return ctx.Err()
+	})
 	if err != nil {
-		s.sendError(c, http.StatusInternalServerError, err, "Failed to analyze database")
+		err = connector.RedactError(connection.Driver, connection.ConnectionString, err)
+		encoder.Encode(reportStreamLine{Type: "error", Error: err.Error()})
+		c.Writer.Flush()
 		return
 	}
 
@@ -185,7 +427,47 @@ func (s *Server) analyzeDatabase(c *gin.Context) {
 	reports[id] = report
 	mutex.Unlock()
 
-	s.sendSuccess(c, report, "Database analysis completed")
+	if err := s.reportHistory.Record(id, report); err != nil {
+		logging.Default().Warn("Failed to record report history", "connection", id, "error", err)
+	}
+
+	encoder.Encode(reportStreamLine{
+		Type: "summary",
+		Summary: &reportStreamSummary{
+			DatabaseName:       report.DatabaseName,
+			DatabaseType:       report.DatabaseType,
+			AnalysisTime:       report.AnalysisTime,
+			TableCount:         len(report.Tables),
+			Summary:            report.Summary,
+			Insights:           report.Insights,
+			Recommendations:    report.Recommendations,
+			PerformanceMetrics: report.PerformanceMetrics,
+		},
+	})
+	c.Writer.Flush()
+}
+
+func (s *Server) getTrends(c *gin.Context) {
+	id := c.Param("id")
+	metric := c.DefaultQuery("metric", types.TrendMetricHealthScore)
+
+	mutex.RLock()
+	_, exists := connections[id]
+	mutex.RUnlock()
+
+	if !exists {
+		s.sendError(c, http.StatusNotFound,
+			types.NewAPIError(types.ErrCodeConnectionNotFound, "Connection not found"), "Connection not found")
+		return
+	}
+
+	trends, err := s.reportHistory.GetTrends(id, metric)
+	if err != nil {
+		s.sendError(c, http.StatusInternalServerError, err, "Failed to get trends")
+		return
+	}
+
+	s.sendSuccess(c, trends)
 }
 
 func (s *Server) getSecurityIssues(c *gin.Context) {
@@ -197,25 +479,66 @@ func (s *Server) getSecurityIssues(c *gin.Context) {
 
 	if !serviceExists {
 		s.sendError(c, http.StatusBadRequest,
-			&APIError{Message: "Connection not established"}, "Please test the connection first")
+			types.NewAPIError(types.ErrCodeNotConnected, "Connection not established"), "Please test the connection first")
 		return
 	}
 
 	issues, err := service.AnalyzeSecurity()
 	if err != nil {
-		s.sendError(c, http.StatusInternalServerError, err, "Failed to analyze security")
+		s.sendError(c, errorStatus(err, http.StatusInternalServerError), err, "Failed to analyze security")
 		return
 	}
 
 	s.sendSuccess(c, issues)
 }
 
+func (s *Server) getAccessControl(c *gin.Context) {
+	id := c.Param("id")
+
+	mutex.RLock()
+	service, serviceExists := services[id]
+	mutex.RUnlock()
+
+	if !serviceExists {
+		s.sendError(c, http.StatusBadRequest,
+			types.NewAPIError(types.ErrCodeNotConnected, "Connection not established"), "Please test the connection first")
+		return
+	}
+
+	findings, err := service.AnalyzeAccessControl()
+	if errors.Is(err, security.ErrAccessControlDenied) {
+		s.sendError(c, http.StatusForbidden, err, "The connected user cannot read roles and grants")
+		return
+	}
+	if err != nil {
+		s.sendError(c, errorStatus(err, http.StatusInternalServerError), err, "Failed to analyze access control")
+		return
+	}
+	if findings == nil {
+		findings = []types.AccessFinding{}
+	}
+
+	s.sendSuccess(c, findings)
+}
+
 func (s *Server) analyzeSecurity(c *gin.Context) {
 	s.getSecurityIssues(c)
 }
 
 func (s *Server) getOptimizationHistory(c *gin.Context) {
-	s.sendSuccess(c, []interface{}{})
+	id := c.Param("id")
+
+	mutex.RLock()
+	_, exists := connections[id]
+	mutex.RUnlock()
+
+	if !exists {
+		s.sendError(c, http.StatusNotFound,
+			types.NewAPIError(types.ErrCodeConnectionNotFound, "Connection not found"), "Connection not found")
+		return
+	}
+
+	s.sendSuccess(c, s.savedQueries.History(id, c.Query("queryId")))
 }
 
 func (s *Server) optimizeQuery(c *gin.Context) {
@@ -232,7 +555,7 @@ func (s *Server) optimizeQuery(c *gin.Context) {
 
 	if !serviceExists {
 		s.sendError(c, http.StatusBadRequest,
-			&APIError{Message: "Connection not established"}, "Please test the connection first")
+			types.NewAPIError(types.ErrCodeNotConnected, "Connection not established"), "Please test the connection first")
 		return
 	}
 
@@ -242,6 +565,10 @@ func (s *Server) optimizeQuery(c *gin.Context) {
 		return
 	}
 
+	if _, err := s.savedQueries.RecordSuggestion(id, "", req.Query, *optimization); err != nil {
+		logging.Default().Warn("Failed to record optimization history", "connection", id, "error", err)
+	}
+
 	s.sendSuccess(c, optimization)
 }
 
@@ -252,16 +579,21 @@ func (s *Server) getAlerts(c *gin.Context) {
 	service, serviceExists := services[connectionID]
 	mutex.RUnlock()
 
+	if connectionID != "" && !ownsConnection(workspaceID(c), connectionID) {
+		s.sendError(c, http.StatusNotFound,
+			types.NewAPIError(types.ErrCodeConnectionNotFound, "Connection not found"), "Connection not found")
+		return
+	}
 	if connectionID != "" && !serviceExists {
 		s.sendError(c, http.StatusBadRequest,
-			&APIError{Message: "Connection not established"}, "Please test the connection first")
+			types.NewAPIError(types.ErrCodeNotConnected, "Connection not established"), "Please test the connection first")
 		return
 	}
 
 	if serviceExists {
 		alerts, err := service.CheckAlerts()
 		if err != nil {
-			s.sendError(c, http.StatusInternalServerError, err, "Failed to get alerts")
+			s.sendError(c, errorStatus(err, http.StatusInternalServerError), err, "Failed to get alerts")
 			return
 		}
 		s.sendSuccess(c, alerts)
@@ -270,8 +602,37 @@ func (s *Server) getAlerts(c *gin.Context) {
 	}
 }
 
+// acknowledgeAlert takes the connection the alert was raised on as ?connectionId=, so the
+// alert can be checked against the caller's workspace. An alert that is not active on a
+// connection the workspace owns is reported as not found.
 func (s *Server) acknowledgeAlert(c *gin.Context) {
 	alertID := c.Param("id")
+	connectionID := c.Query("connectionId")
+	if connectionID == "" {
+		s.sendError(c, http.StatusBadRequest,
+			types.NewAPIError(types.ErrCodeInvalidRequest, "connectionId is required"), "Connection ID is required")
+		return
+	}
+
+	mutex.RLock()
+	service, serviceExists := services[connectionID]
+	mutex.RUnlock()
+
+	found := false
+	if serviceExists && ownsConnection(workspaceID(c), connectionID) {
+		for _, alert := range service.ActiveAlerts() {
+			if alert.ID == alertID {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		s.sendError(c, http.StatusNotFound,
+			types.NewAPIError(types.ErrCodeNotFound, "Alert not found"), "Alert not found")
+		return
+	}
+
 	s.sendSuccess(c, nil, "Alert "+alertID+" acknowledged")
 }
 
@@ -294,13 +655,13 @@ func (s *Server) getLineage(c *gin.Context) {
 
 	if !serviceExists {
 		s.sendError(c, http.StatusBadRequest,
-			&APIError{Message: "Connection not established"}, "Please test the connection first")
+			types.NewAPIError(types.ErrCodeNotConnected, "Connection not established"), "Please test the connection first")
 		return
 	}
 
 	lineage, err := service.TrackLineage()
 	if err != nil {
-		s.sendError(c, http.StatusInternalServerError, err, "Failed to get lineage")
+		s.sendError(c, errorStatus(err, http.StatusInternalServerError), err, "Failed to get lineage")
 		return
 	}
 
@@ -311,6 +672,75 @@ func (s *Server) trackLineage(c *gin.Context) {
 	s.getLineage(c)
 }
 
+func (s *Server) getLineageGraph(c *gin.Context) {
+	id := c.Param("id")
+	format := c.DefaultQuery("format", "dot")
+
+	if format != "dot" && format != "mermaid" {
+		s.sendError(c, http.StatusBadRequest,
+			types.NewAPIError(types.ErrCodeInvalidRequest, "Unsupported graph format: "+format), "Format must be dot or mermaid")
+		return
+	}
+
+	mutex.RLock()
+	service, serviceExists := services[id]
+	mutex.RUnlock()
+
+	if !serviceExists {
+		s.sendError(c, http.StatusBadRequest,
+			types.NewAPIError(types.ErrCodeNotConnected, "Connection not established"), "Please test the connection first")
+		return
+	}
+
+	graph, err := service.ExportLineageGraph(format)
+	if err != nil {
+		s.sendError(c, errorStatus(err, http.StatusInternalServerError), err, "Failed to export lineage graph")
+		return
+	}
+
+	contentType := "text/vnd.graphviz; charset=utf-8"
+	if format == "mermaid" {
+		contentType = "text/plain; charset=utf-8"
+	}
+	c.Data(http.StatusOK, contentType, graph)
+}
+
+func (s *Server) getSchemaDDL(c *gin.Context) {
+	id := c.Param("id")
+	dialect := c.Query("dialect")
+
+	switch dialect {
+	case "", insights.DialectPostgres, insights.DialectMySQL, insights.DialectSQLite:
+	default:
+		s.sendError(c, http.StatusBadRequest,
+			types.NewAPIError(types.ErrCodeInvalidRequest, "Unsupported DDL dialect: "+dialect), "Dialect must be postgres, mysql or sqlite")
+		return
+	}
+
+	mutex.RLock()
+	service, serviceExists := services[id]
+	mutex.RUnlock()
+
+	if !serviceExists {
+		s.sendError(c, http.StatusBadRequest,
+			types.NewAPIError(types.ErrCodeNotConnected, "Connection not established"), "Please test the connection first")
+		return
+	}
+	if service.GetMongoService() != nil {
+		s.sendError(c, http.StatusBadRequest,
+			types.NewAPIError(types.ErrCodeInvalidRequest, "Not a SQL connection"), "Schema DDL is not available for MongoDB")
+		return
+	}
+
+	ddl, err := service.ExportSchemaDDL(dialect)
+	if err != nil {
+		s.sendError(c, errorStatus(err, http.StatusInternalServerError), err, "Failed to export schema")
+		return
+	}
+
+	c.Data(http.StatusOK, "application/sql; charset=utf-8", []byte(ddl))
+}
+
 type SearchCollectionRequest struct {
 	Query string `json:"query" binding:"required"`
 }
@@ -335,10 +765,11 @@ type FieldStats struct {
 }
 
 type CollectionStatsResponse struct {
-	CollectionName string       `json:"collectionName"`
-	DocumentCount  int64        `json:"documentCount"`
-	Fields         []FieldStats `json:"fields"`
-	Indexes        []IndexStats `json:"indexes"`
+	CollectionName   string       `json:"collectionName"`
+	DocumentCount    int64        `json:"documentCount"`
+	SampledDocuments int64        `json:"sampledDocuments"`
+	Fields           []FieldStats `json:"fields"`
+	Indexes          []IndexStats `json:"indexes"`
 }
 
 type IndexStats struct {
@@ -351,6 +782,9 @@ type IndexStats struct {
 func (s *Server) getCollectionData(c *gin.Context) {
 	connectionID := c.Param("id")
 	collectionName := c.Param("collection")
+	if !s.allowCollectionData(c, connectionID, collectionName) {
+		return
+	}
 
 	page := 1
 	limit := 20
@@ -373,14 +807,14 @@ func (s *Server) getCollectionData(c *gin.Context) {
 
 	if !serviceExists {
 		s.sendError(c, http.StatusBadRequest,
-			&APIError{Message: "Connection not established"}, "Please test the connection first")
+			types.NewAPIError(types.ErrCodeNotConnected, "Connection not established"), "Please test the connection first")
 		return
 	}
 
 	mongoService := service.GetMongoService()
 	if mongoService == nil {
 		s.sendError(c, http.StatusBadRequest,
-			&APIError{Message: "Not a MongoDB connection"}, "Collection data only available for MongoDB")
+			types.NewAPIError(types.ErrCodeInvalidRequest, "Not a MongoDB connection"), "Collection data only available for MongoDB")
 		return
 	}
 
@@ -403,6 +837,9 @@ func (s *Server) getCollectionData(c *gin.Context) {
 func (s *Server) getCollectionStats(c *gin.Context) {
 	connectionID := c.Param("id")
 	collectionName := c.Param("collection")
+	if !s.allowCollectionData(c, connectionID, collectionName) {
+		return
+	}
 
 	mutex.RLock()
 	service, serviceExists := services[connectionID]
@@ -410,30 +847,124 @@ func (s *Server) getCollectionStats(c *gin.Context) {
 
 	if !serviceExists {
 		s.sendError(c, http.StatusBadRequest,
-			&APIError{Message: "Connection not established"}, "Please test the connection first")
+			types.NewAPIError(types.ErrCodeNotConnected, "Connection not established"), "Please test the connection first")
 		return
 	}
 
 	mongoService := service.GetMongoService()
 	if mongoService == nil {
 		s.sendError(c, http.StatusBadRequest,
-			&APIError{Message: "Not a MongoDB connection"}, "Collection stats only available for MongoDB")
+			types.NewAPIError(types.ErrCodeInvalidRequest, "Not a MongoDB connection"), "Collection stats only available for MongoDB")
 		return
 	}
 
-	stats, err := s.getMongoCollectionStats(service, collectionName)
+	sampling := parseCollectionSampling(c)
+	cacheKey := utils.CacheKey(connectionID, "collection-stats", collectionName,
+		strconv.FormatInt(sampling.documents, 10), strconv.Itoa(sampling.values))
+	if c.Query("refresh") != "true" {
+		if cached, ok := s.statsCache.Get(cacheKey); ok {
+			s.sendSuccess(c, cached)
+			return
+		}
+	}
+
+	stats, err := s.getMongoCollectionStats(service, collectionName, sampling)
 	if err != nil {
 		s.sendError(c, http.StatusInternalServerError, err, "Failed to fetch collection stats")
 		return
 	}
+	s.statsCache.Set(cacheKey, stats)
 
 	s.sendSuccess(c, stats)
 }
 
+func (s *Server) getCollectionFieldSizes(c *gin.Context) {
+	connectionID := c.Param("id")
+	collectionName := c.Param("collection")
+	if !s.allowCollectionData(c, connectionID, collectionName) {
+		return
+	}
+
+	mutex.RLock()
+	service, serviceExists := services[connectionID]
+	mutex.RUnlock()
+
+	if !serviceExists {
+		s.sendError(c, http.StatusBadRequest,
+			types.NewAPIError(types.ErrCodeNotConnected, "Connection not established"), "Please test the connection first")
+		return
+	}
+
+	mongoService := service.GetMongoService()
+	if mongoService == nil {
+		s.sendError(c, http.StatusBadRequest,
+			types.NewAPIError(types.ErrCodeInvalidRequest, "Not a MongoDB connection"), "Field sizes only available for MongoDB")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	report, err := mongoService.FieldSizeReport(ctx, collectionName)
+	if err != nil {
+		s.sendError(c, http.StatusInternalServerError, err, "Failed to analyze field sizes")
+		return
+	}
+
+	s.sendSuccess(c, report)
+}
+
+func (s *Server) compareCollections(c *gin.Context) {
+	connectionID := c.Param("id")
+	collectionA := c.Query("a")
+	collectionB := c.Query("b")
+
+	if collectionA == "" || collectionB == "" {
+		s.sendError(c, http.StatusBadRequest,
+			types.NewAPIError(types.ErrCodeInvalidRequest, "Both a and b query parameters are required"), "Invalid comparison request")
+		return
+	}
+	if !s.allowCollectionData(c, connectionID, collectionA) || !s.allowCollectionData(c, connectionID, collectionB) {
+		return
+	}
+
+	mutex.RLock()
+	service, serviceExists := services[connectionID]
+	mutex.RUnlock()
+
+	if !serviceExists {
+		s.sendError(c, http.StatusBadRequest,
+			types.NewAPIError(types.ErrCodeNotConnected, "Connection not established"), "Please test the connection first")
+		return
+	}
+
+	mongoService := service.GetMongoService()
+	if mongoService == nil {
+		s.sendError(c, http.StatusBadRequest,
+			types.NewAPIError(types.ErrCodeInvalidRequest, "Not a MongoDB connection"), "Collection comparison only available for MongoDB")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+	defer cancel()
+
+	comparison, err := mongoService.CompareCollections(ctx, collectionA, collectionB)
+	if err != nil {
+		s.sendError(c, http.StatusInternalServerError, err, "Failed to compare collections")
+		return
+	}
+
+	s.sendSuccess(c, comparison)
+}
+
 func (s *Server) searchCollection(c *gin.Context) {
 	connectionID := c.Param("id")
 	collectionName := c.Param("collection")
 
+	if !s.allowCollectionData(c, connectionID, collectionName) {
+		return
+	}
+
 	var req SearchCollectionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		s.sendError(c, http.StatusBadRequest, err, "Invalid search request")
@@ -446,14 +977,14 @@ func (s *Server) searchCollection(c *gin.Context) {
 
 	if !serviceExists {
 		s.sendError(c, http.StatusBadRequest,
-			&APIError{Message: "Connection not established"}, "Please test the connection first")
+			types.NewAPIError(types.ErrCodeNotConnected, "Connection not established"), "Please test the connection first")
 		return
 	}
 
 	mongoService := service.GetMongoService()
 	if mongoService == nil {
 		s.sendError(c, http.StatusBadRequest,
-			&APIError{Message: "Not a MongoDB connection"}, "Collection search only available for MongoDB")
+			types.NewAPIError(types.ErrCodeInvalidRequest, "Not a MongoDB connection"), "Collection search only available for MongoDB")
 		return
 	}
 
@@ -466,10 +997,9 @@ func (s *Server) searchCollection(c *gin.Context) {
 	s.sendSuccess(c, documents)
 }
 
-type APIError struct {
-	Message string
-}
-
-func (e *APIError) Error() string {
-	return e.Message
+func errorStatus(err error, fallback int) int {
+	if errors.Is(err, connector.ErrCircuitOpen) {
+		return http.StatusServiceUnavailable
+	}
+	return fallback
 }
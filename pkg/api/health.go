@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cherry-pick/pkg/intelligence"
+	"github.com/gin-gonic/gin"
+)
+
+const DefaultReadinessTimeout = 2 * time.Second
+
+// ReadinessConfig controls /readyz. With no Required IDs every active connection must answer;
+// otherwise only the listed connections gate readiness and the rest are reported for information.
+type ReadinessConfig struct {
+	Timeout  time.Duration
+	Required []string
+}
+
+type ConnectionCheck struct {
+	ID        string `json:"id"`
+	Name      string `json:"name,omitempty"`
+	Required  bool   `json:"required"`
+	Healthy   bool   `json:"healthy"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+type ReadinessReport struct {
+	Ready       bool              `json:"ready"`
+	CheckedAt   time.Time         `json:"checked_at"`
+	Connections []ConnectionCheck `json:"connections"`
+}
+
+func WithReadiness(config ReadinessConfig) ServerOption {
+	return func(s *Server) {
+		s.readiness = config
+	}
+}
+
+func (s *Server) healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func (s *Server) readyz(c *gin.Context) {
+	report := s.checkReadiness(c.Request.Context())
+
+	status := http.StatusOK
+	if !report.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+func (s *Server) checkReadiness(ctx context.Context) ReadinessReport {
+	timeout := s.readiness.Timeout
+	if timeout <= 0 {
+		timeout = DefaultReadinessTimeout
+	}
+
+	required := make(map[string]bool, len(s.readiness.Required))
+	for _, id := range s.readiness.Required {
+		required[id] = true
+	}
+
+	mutex.RLock()
+	active := make(map[string]*intelligence.Service, len(services))
+	for id, service := range services {
+		active[id] = service
+	}
+	names := make(map[string]string, len(connections))
+	for id, conn := range connections {
+		names[id] = conn.Name
+	}
+	mutex.RUnlock()
+
+	checks := make(chan ConnectionCheck, len(active))
+	for id, service := range active {
+		go func(id string, service *intelligence.Service) {
+			check := ConnectionCheck{
+				ID:       id,
+				Name:     names[id],
+				Required: len(required) == 0 || required[id],
+			}
+
+			pingCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := service.Ping(pingCtx)
+			check.LatencyMs = time.Since(start).Milliseconds()
+			check.Healthy = err == nil
+			if err != nil {
+				check.Error = err.Error()
+			}
+			checks <- check
+		}(id, service)
+	}
+
+	report := ReadinessReport{Ready: true, CheckedAt: time.Now()}
+	for range active {
+		check := <-checks
+		if check.Required && !check.Healthy {
+			report.Ready = false
+		}
+		report.Connections = append(report.Connections, check)
+	}
+
+	for id := range required {
+		if _, ok := active[id]; !ok {
+			report.Ready = false
+			report.Connections = append(report.Connections, ConnectionCheck{
+				ID:       id,
+				Name:     names[id],
+				Required: true,
+				Error:    "connection not established",
+			})
+		}
+	}
+
+	sort.Slice(report.Connections, func(i, j int) bool {
+		return report.Connections[i].ID < report.Connections[j].ID
+	})
+	return report
+}
+
+func getReadinessConfig() ReadinessConfig {
+	config := ReadinessConfig{Timeout: DefaultReadinessTimeout}
+	if duration, err := time.ParseDuration(os.Getenv("READINESS_TIMEOUT")); err == nil {
+		config.Timeout = duration
+	}
+	if required := os.Getenv("READINESS_REQUIRED_CONNECTIONS"); required != "" {
+		for _, id := range strings.Split(required, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				config.Required = append(config.Required, id)
+			}
+		}
+	}
+	return config
+}
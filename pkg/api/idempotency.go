@@ -0,0 +1,174 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cherry-pick/pkg/types"
+	"github.com/cherry-pick/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DefaultIdempotencyTTL     = 10 * time.Minute
+	DefaultIdempotencyEntries = 1024
+
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+)
+
+type idempotentResponse struct {
+	fingerprint string
+	status      int
+	contentType string
+	body        []byte
+}
+
+// IdempotencyStore remembers successful responses by Idempotency-Key so a retried or
+// double-submitted request gets the original response instead of running again. A
+// request arriving while the first with the same key is still running waits for it.
+type IdempotencyStore struct {
+	responses *utils.TTLCache
+	inFlight  map[string]chan struct{}
+	mu        sync.Mutex
+}
+
+func NewIdempotencyStore(ttl time.Duration, maxEntries int) *IdempotencyStore {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultIdempotencyEntries
+	}
+
+	return &IdempotencyStore{
+		responses: utils.NewTTLCache(ttl, maxEntries),
+		inFlight:  make(map[string]chan struct{}),
+	}
+}
+
+// begin returns the stored response for key, or a channel to wait on while another
+// request with the key runs. When both are nil the caller owns the key until finish.
+func (is *IdempotencyStore) begin(key string) (*idempotentResponse, chan struct{}) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	if value, ok := is.responses.Get(key); ok {
+		return value.(*idempotentResponse), nil
+	}
+	if wait, running := is.inFlight[key]; running {
+		return nil, wait
+	}
+	is.inFlight[key] = make(chan struct{})
+	return nil, nil
+}
+
+// finish stores response, unless it is nil, and releases any requests waiting on key.
+func (is *IdempotencyStore) finish(key string, response *idempotentResponse) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	if response != nil {
+		is.responses.Set(key, response)
+	}
+	if wait, running := is.inFlight[key]; running {
+		close(wait)
+		delete(is.inFlight, key)
+	}
+}
+
+// Middleware replays the stored response when a request repeats an Idempotency-Key for
+// the same path in the same workspace. Only 2xx responses are stored, so failed requests can be retried with
+// the same key. Reusing a key with a different body is rejected.
+func (is *IdempotencyStore) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idempotencyKey := c.GetHeader(IdempotencyKeyHeader)
+		if idempotencyKey == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   "Failed to read request body",
+				Code:    types.ErrCodeInvalidRequest,
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.Sum256(body)
+		fingerprint := hex.EncodeToString(sum[:])
+		key := utils.CacheKey(workspaceID(c), c.Request.Method, c.Request.URL.Path, idempotencyKey)
+
+		for {
+			stored, wait := is.begin(key)
+			if stored != nil {
+				if stored.fingerprint != fingerprint {
+					c.AbortWithStatusJSON(http.StatusUnprocessableEntity, APIResponse{
+						Success: false,
+						Error:   "Idempotency-Key was already used with a different request body",
+						Code:    types.ErrCodeInvalidRequest,
+					})
+					return
+				}
+				c.Header(IdempotencyReplayedHeader, "true")
+				c.Data(stored.status, stored.contentType, stored.body)
+				c.Abort()
+				return
+			}
+			if wait == nil {
+				break
+			}
+
+			select {
+			case <-wait:
+			case <-c.Request.Context().Done():
+				c.Abort()
+				return
+			}
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		var response *idempotentResponse
+		defer func() {
+			is.finish(key, response)
+		}()
+
+		c.Next()
+
+		if status := recorder.Status(); status >= 200 && status < 300 {
+			response = &idempotentResponse{
+				fingerprint: fingerprint,
+				status:      status,
+				contentType: recorder.Header().Get("Content-Type"),
+				body:        recorder.body.Bytes(),
+			}
+		}
+	}
+}
+
+// responseRecorder copies the response body while it is written to the client.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (rr *responseRecorder) Write(data []byte) (int, error) {
+	rr.body.Write(data)
+	return rr.ResponseWriter.Write(data)
+}
+
+func (rr *responseRecorder) WriteString(data string) (int, error) {
+	rr.body.WriteString(data)
+	return rr.ResponseWriter.WriteString(data)
+}
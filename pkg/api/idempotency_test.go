@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const idempotencyWorkspace = "idempotency"
+
+func newIdempotencyServer(t *testing.T) *Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	t.Cleanup(func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		for id, connection := range connections {
+			if connection.WorkspaceID == idempotencyWorkspace {
+				delete(connections, id)
+			}
+		}
+	})
+
+	return NewServer("0", WithWorkspaces(WorkspaceAccess{idempotencyWorkspace: ""}))
+}
+
+func createConnectionWithKey(s *Server, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/connections", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WorkspaceHeader, idempotencyWorkspace)
+	req.Header.Set(IdempotencyKeyHeader, key)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func createdConnectionID(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	var response struct {
+		Data ConnectionInfo `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Expected a JSON response, got %v", err)
+	}
+	return response.Data.ID
+}
+
+func workspaceConnectionCount() int {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	count := 0
+	for _, connection := range connections {
+		if connection.WorkspaceID == idempotencyWorkspace {
+			count++
+		}
+	}
+	return count
+}
+
+const idempotentBody = `{"name":"orders","driver":"postgres","connectionString":"postgres://localhost/orders"}`
+
+func TestIdempotencyKeyCreatesConnectionOnce(t *testing.T) {
+	s := newIdempotencyServer(t)
+
+	first := createConnectionWithKey(s, "create-orders", idempotentBody)
+	if first.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", first.Code, first.Body.String())
+	}
+	second := createConnectionWithKey(s, "create-orders", idempotentBody)
+	if second.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 on replay, got %d: %s", second.Code, second.Body.String())
+	}
+
+	if second.Header().Get(IdempotencyReplayedHeader) != "true" {
+		t.Errorf("Expected the %s header on the replayed response", IdempotencyReplayedHeader)
+	}
+	if firstID, secondID := createdConnectionID(t, first), createdConnectionID(t, second); firstID != secondID {
+		t.Errorf("Expected the replay to return connection %s, got %s", firstID, secondID)
+	}
+	if count := workspaceConnectionCount(); count != 1 {
+		t.Errorf("Expected 1 connection, got %d", count)
+	}
+}
+
+func TestIdempotencyKeyConcurrentDuplicatesCreateOnce(t *testing.T) {
+	s := newIdempotencyServer(t)
+
+	var wg sync.WaitGroup
+	responses := make([]*httptest.ResponseRecorder, 5)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i] = createConnectionWithKey(s, "create-concurrently", idempotentBody)
+		}(i)
+	}
+	wg.Wait()
+
+	ids := make([]string, len(responses))
+	for i, w := range responses {
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		ids[i] = createdConnectionID(t, w)
+	}
+	for _, id := range ids {
+		if id != ids[0] {
+			t.Fatalf("Expected every request to return the same connection, got %v", ids)
+		}
+	}
+	if count := workspaceConnectionCount(); count != 1 {
+		t.Errorf("Expected 1 connection, got %d", count)
+	}
+}
+
+func TestIdempotencyKeyReusedWithDifferentBody(t *testing.T) {
+	s := newIdempotencyServer(t)
+
+	if w := createConnectionWithKey(s, "create-once", idempotentBody); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	w := createConnectionWithKey(s, "create-once",
+		`{"name":"billing","driver":"postgres","connectionString":"postgres://localhost/billing"}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status 422, got %d", w.Code)
+	}
+	if count := workspaceConnectionCount(); count != 1 {
+		t.Errorf("Expected 1 connection, got %d", count)
+	}
+}
+
+func TestIdempotencyKeyFailedRequestCanBeRetried(t *testing.T) {
+	s := newIdempotencyServer(t)
+
+	if w := createConnectionWithKey(s, "retry", `{"name":"orders"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 for an invalid request, got %d", w.Code)
+	}
+	if w := createConnectionWithKey(s, "retry", idempotentBody); w.Code != http.StatusOK {
+		t.Errorf("Expected the retry with a valid body to succeed, got %d", w.Code)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/cherry-pick/pkg/loadbalancer/core"
+	"github.com/cherry-pick/pkg/types"
 	"github.com/gin-gonic/gin"
 )
 
@@ -128,7 +129,7 @@ func (h *Handler) GetAllAlerts(c *gin.Context) {
 		return
 	}
 
-	h.sendSuccess(c, alerts)
+	h.sendSuccess(c, h.visibleAlerts(h.workspace(c), alerts))
 }
 
 func (h *Handler) GetAlertTriggers(c *gin.Context) {
@@ -148,13 +149,13 @@ func (h *Handler) GetAlertTriggers(c *gin.Context) {
 }
 
 func (h *Handler) GetAlertStats(c *gin.Context) {
-	stats, err := h.service.GetAlertStats()
+	alerts, err := h.service.GetAllAlerts()
 	if err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to get alert statistics")
 		return
 	}
 
-	h.sendSuccess(c, stats)
+	h.sendSuccess(c, core.NewAlertStats(h.visibleAlerts(h.workspace(c), alerts)))
 }
 
 
@@ -234,6 +235,40 @@ func (h *Handler) DeleteAlertTemplate(c *gin.Context) {
 	h.sendSuccess(c, response, "Alert template deleted successfully")
 }
 
+func (h *Handler) InstantiateAlertTemplate(c *gin.Context) {
+	templateID := c.Param("templateId")
+	if templateID == "" {
+		h.sendError(c, http.StatusBadRequest, nil, "Template ID is required")
+		return
+	}
+
+	var req core.AlertTemplateInstantiation
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.sendError(c, http.StatusBadRequest, err, "Invalid request data")
+		return
+	}
+	if !h.owners.owns(h.workspace(c), req.TestID) {
+		h.sendError(c, http.StatusNotFound,
+			types.NewAPIError(types.ErrCodeNotFound, "Test not found"), "Test not found")
+		return
+	}
+
+	alert, err := h.service.InstantiateAlertFromTemplate(templateID, req.TestID, req.Variables)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, err, "Failed to instantiate alert template")
+		return
+	}
+
+	response := core.AlertResponse{
+		ID:      alert.ID,
+		Status:  "created",
+		Message: "Alert created from template",
+		Alert:   alert,
+	}
+
+	h.sendSuccess(c, response, "Alert created from template")
+}
+
 func (h *Handler) GetAllAlertTemplates(c *gin.Context) {
 	templates, err := h.service.GetAllAlertTemplates()
 	if err != nil {
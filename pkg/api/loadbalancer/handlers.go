@@ -1,18 +1,24 @@
 package loadbalancer
 
 import (
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/cherry-pick/pkg/loadbalancer/core"
+	"github.com/cherry-pick/pkg/loadbalancer/reporter"
+	"github.com/cherry-pick/pkg/types"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 )
 
 type Handler struct {
-	service LoadBalancerService
-	upgrader websocket.Upgrader
+	service     LoadBalancerService
+	upgrader    websocket.Upgrader
+	owners      *testOwners
+	workspaceOf WorkspaceFunc
 }
 
 var upgrader = websocket.Upgrader{
@@ -23,8 +29,9 @@ var upgrader = websocket.Upgrader{
 
 func NewHandler(service LoadBalancerService) *Handler {
 	return &Handler{
-		service: service,
+		service:  service,
 		upgrader: upgrader,
+		owners:   newTestOwners(),
 	}
 }
 
@@ -40,10 +47,33 @@ func (h *Handler) StartLoadTest(c *gin.Context) {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to start load test")
 		return
 	}
+	h.owners.set(response.TestID, h.workspace(c))
 
 	h.sendSuccess(c, response, "Load test started successfully")
 }
 
+// ValidateLoadTest checks a test request and probes its target once, so a bad URL or an
+// unreachable host is caught before a full test is started.
+func (h *Handler) ValidateLoadTest(c *gin.Context) {
+	var req core.LoadTestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.sendError(c, http.StatusBadRequest, err, "Invalid request data")
+		return
+	}
+
+	result, err := h.service.ValidateLoadTest(req)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, err, "Invalid load test configuration")
+		return
+	}
+
+	message := "Target is reachable"
+	if !result.Reachable {
+		message = "Target is unreachable"
+	}
+	h.sendSuccess(c, result, message)
+}
+
 func (h *Handler) GetTestStatus(c *gin.Context) {
 	testID := c.Param("testId")
 	if testID == "" {
@@ -94,6 +124,53 @@ func (h *Handler) GetTestResults(c *gin.Context) {
 	h.sendSuccess(c, response)
 }
 
+func (h *Handler) ExportResultsCSV(c *gin.Context) {
+	h.exportResults(c, "text/csv", ".csv", reporter.WriteResultsCSV)
+}
+
+func (h *Handler) ExportResultsJSONL(c *gin.Context) {
+	h.exportResults(c, "application/x-ndjson", ".jsonl", reporter.WriteResultsJSONL)
+}
+
+func (h *Handler) exportResults(c *gin.Context, contentType, extension string, write func(io.Writer, []core.LoadTestResult) error) {
+	testID := c.Param("testId")
+	if testID == "" {
+		h.sendError(c, http.StatusBadRequest, nil, "Test ID is required")
+		return
+	}
+
+	results, err := h.service.GetRawTestResults(testID)
+	if err != nil {
+		h.sendError(c, http.StatusNotFound, err, "Test results not found")
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=loadtest_%s_results%s", testID, extension))
+	c.Status(http.StatusOK)
+	if err := write(c.Writer, results); err != nil {
+		c.Error(err)
+	}
+}
+
+// ExportJUnit serves the test's SLA and alert verdicts as JUnit XML for CI test reports.
+func (h *Handler) ExportJUnit(c *gin.Context) {
+	testID := c.Param("testId")
+	if testID == "" {
+		h.sendError(c, http.StatusBadRequest, nil, "Test ID is required")
+		return
+	}
+
+	report, err := h.service.ExportJUnit(testID)
+	if err != nil {
+		h.sendError(c, http.StatusNotFound, err, "Test summary not found")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=loadtest_%s_junit.xml", testID))
+	c.Data(http.StatusOK, "application/xml", report)
+}
+
 func (h *Handler) CancelTest(c *gin.Context) {
 	testID := c.Param("testId")
 	if testID == "" {
@@ -110,6 +187,38 @@ func (h *Handler) CancelTest(c *gin.Context) {
 	h.sendSuccess(c, response, "Load test cancelled successfully")
 }
 
+func (h *Handler) PauseTest(c *gin.Context) {
+	testID := c.Param("testId")
+	if testID == "" {
+		h.sendError(c, http.StatusBadRequest, nil, "Test ID is required")
+		return
+	}
+
+	response, err := h.service.PauseTest(testID)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, err, "Failed to pause test")
+		return
+	}
+
+	h.sendSuccess(c, response, "Load test paused successfully")
+}
+
+func (h *Handler) ResumeTest(c *gin.Context) {
+	testID := c.Param("testId")
+	if testID == "" {
+		h.sendError(c, http.StatusBadRequest, nil, "Test ID is required")
+		return
+	}
+
+	response, err := h.service.ResumeTest(testID)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, err, "Failed to resume test")
+		return
+	}
+
+	h.sendSuccess(c, response, "Load test resumed successfully")
+}
+
 func (h *Handler) GetAllTests(c *gin.Context) {
 	tests, err := h.service.GetAllTests()
 	if err != nil {
@@ -117,6 +226,13 @@ func (h *Handler) GetAllTests(c *gin.Context) {
 		return
 	}
 
+	workspace := h.workspace(c)
+	for testID := range tests {
+		if !h.owners.owns(workspace, testID) {
+			delete(tests, testID)
+		}
+	}
+
 	h.sendSuccess(c, tests)
 }
 
@@ -136,6 +252,22 @@ func (h *Handler) GetRealTimeMetrics(c *gin.Context) {
 	h.sendSuccess(c, metrics)
 }
 
+func (h *Handler) GetCapturedFailures(c *gin.Context) {
+	testID := c.Param("testId")
+	if testID == "" {
+		h.sendError(c, http.StatusBadRequest, nil, "Test ID is required")
+		return
+	}
+
+	failures, err := h.service.GetCapturedFailures(testID)
+	if err != nil {
+		h.sendError(c, http.StatusNotFound, err, "Test not found")
+		return
+	}
+
+	h.sendSuccess(c, failures)
+}
+
 func (h *Handler) GenerateReport(c *gin.Context) {
 	testID := c.Param("testId")
 	if testID == "" {
@@ -176,6 +308,9 @@ func (h *Handler) CleanupOldTests(c *gin.Context) {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to cleanup tests")
 		return
 	}
+	if tests, err := h.service.GetAllTests(); err == nil {
+		h.owners.retain(tests)
+	}
 
 	h.sendSuccess(c, response, "Cleanup completed successfully")
 }
@@ -187,6 +322,15 @@ func (h *Handler) GetTestHistory(c *gin.Context) {
 		return
 	}
 
+	workspace := h.workspace(c)
+	visible := []core.LoadTestHistory{}
+	for _, entry := range history {
+		if h.owners.owns(workspace, entry.TestID) {
+			visible = append(visible, entry)
+		}
+	}
+	history = visible
+
 	h.sendSuccess(c, history)
 }
 
@@ -197,7 +341,7 @@ func (h *Handler) AnalyzeURL(c *gin.Context) {
 		return
 	}
 
-	result, err := h.service.AnalyzeURL(req.URL)
+	result, err := h.service.AnalyzeURL(c.Request.Context(), req.URL)
 	if err != nil {
 		h.sendError(c, http.StatusInternalServerError, err, "Failed to analyze URL")
 		return
@@ -296,19 +440,15 @@ func (h *Handler) sendSuccess(c *gin.Context, data interface{}, message ...strin
 }
 
 func (h *Handler) sendError(c *gin.Context, statusCode int, err error, message ...string) {
+	apiErr, statusCode := types.ToAPIError(err, statusCode, types.ErrCodeInternal)
 	response := APIResponse{
 		Success: false,
-		Error:   getErrorMessage(err),
+		Error:   apiErr.Message,
+		Code:    string(apiErr.Code),
+		Details: apiErr.Details,
 	}
 	if len(message) > 0 {
 		response.Message = message[0]
 	}
 	c.JSON(statusCode, response)
 }
-
-func getErrorMessage(err error) string {
-	if err == nil {
-		return ""
-	}
-	return err.Error()
-}
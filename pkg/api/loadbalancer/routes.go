@@ -5,15 +5,22 @@ import (
 )
 
 func SetupRoutes(router *gin.RouterGroup, handler *Handler) {
-	loadbalancer := router.Group("/loadbalancer")
+	loadbalancer := router.Group("/loadbalancer", handler.testAccess(), handler.alertAccess())
 	{
 		// Test management
 		loadbalancer.POST("/tests", handler.StartLoadTest)
+		loadbalancer.POST("/validate", handler.ValidateLoadTest)
 		loadbalancer.GET("/tests", handler.GetAllTests)
 		loadbalancer.GET("/tests/:testId", handler.GetTestStatus)
 		loadbalancer.GET("/tests/:testId/summary", handler.GetTestSummary)
 		loadbalancer.GET("/tests/:testId/results", handler.GetTestResults)
+		loadbalancer.GET("/tests/:testId/results.csv", handler.ExportResultsCSV)
+		loadbalancer.GET("/tests/:testId/results.jsonl", handler.ExportResultsJSONL)
+		loadbalancer.GET("/tests/:testId/junit.xml", handler.ExportJUnit)
+		loadbalancer.GET("/tests/:testId/failures", handler.GetCapturedFailures)
 		loadbalancer.DELETE("/tests/:testId", handler.CancelTest)
+		loadbalancer.POST("/tests/:testId/pause", handler.PauseTest)
+		loadbalancer.POST("/tests/:testId/resume", handler.ResumeTest)
 		
 		// Real-time monitoring
 		loadbalancer.GET("/tests/:testId/metrics", handler.GetRealTimeMetrics)
@@ -66,6 +73,7 @@ func SetupRoutes(router *gin.RouterGroup, handler *Handler) {
 		loadbalancer.GET("/alert-templates/:templateId", handler.GetAlertTemplate)
 		loadbalancer.PUT("/alert-templates/:templateId", handler.UpdateAlertTemplate)
 		loadbalancer.DELETE("/alert-templates/:templateId", handler.DeleteAlertTemplate)
+		loadbalancer.POST("/alert-templates/:templateId/instantiate", handler.InstantiateAlertTemplate)
 		
 		// Alert utilities
 		loadbalancer.GET("/alerts/metrics", handler.GetSupportedMetrics)
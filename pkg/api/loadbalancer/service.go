@@ -1,29 +1,39 @@
 package loadbalancer
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/cherry-pick/pkg/loadbalancer"
 	"github.com/cherry-pick/pkg/loadbalancer/alerting"
 	"github.com/cherry-pick/pkg/loadbalancer/core"
+	"github.com/cherry-pick/pkg/loadbalancer/reporter"
 	"github.com/cherry-pick/pkg/loadbalancer/storage"
 	"github.com/cherry-pick/pkg/loadbalancer/utils"
+	sharedutils "github.com/cherry-pick/pkg/utils"
 )
 
 type LoadBalancerService interface {
 	StartLoadTest(req core.LoadTestRequest) (*core.LoadTestResponse, error)
+	ValidateLoadTest(req core.LoadTestRequest) (*core.ProbeResult, error)
 	GetTestStatus(testID string) (*core.LoadTestStatus, error)
 	GetTestSummary(testID string) (*core.LoadTestSummary, error)
 	GetTestResults(testID string, includeResults bool) (map[string]interface{}, error)
+	GetRawTestResults(testID string) ([]core.LoadTestResult, error)
 	CancelTest(testID string) (*core.LoadTestResponse, error)
+	PauseTest(testID string) (*core.LoadTestResponse, error)
+	ResumeTest(testID string) (*core.LoadTestResponse, error)
 	GetAllTests() (map[string]*core.LoadTestStatus, error)
 	GetRealTimeMetrics(testID string) (*core.RealTimeMetrics, error)
+	GetCapturedFailures(testID string) ([]core.CapturedFailure, error)
 	GenerateReport(testID string) (map[string]string, error)
+	ExportJUnit(testID string) ([]byte, error)
 	GetStats() (map[string]interface{}, error)
 	CleanupOldTests(olderThan time.Duration) (map[string]string, error)
 	GetTestHistory() ([]core.LoadTestHistory, error)
-	AnalyzeURL(url string) (*core.URLAnalysisResult, error)
+	AnalyzeURL(ctx context.Context, url string) (*core.URLAnalysisResult, error)
 	
 	CreateAlert(testID string, req core.AlertRequest) (*core.Alert, error)
 	GetAlert(alertID string) (*core.Alert, error)
@@ -40,6 +50,7 @@ type LoadBalancerService interface {
 	UpdateAlertTemplate(templateID string, req core.AlertTemplate) (*core.AlertTemplate, error)
 	DeleteAlertTemplate(templateID string) error
 	GetAllAlertTemplates() ([]*core.AlertTemplate, error)
+	InstantiateAlertFromTemplate(templateID, testID string, vars map[string]string) (*core.Alert, error)
 	
 	GetSupportedMetrics() []string
 	GetSupportedOperators() []string
@@ -85,6 +96,14 @@ func (s *service) StartLoadTest(req core.LoadTestRequest) (*core.LoadTestRespons
 		return nil, fmt.Errorf("failed to start load test: %w", err)
 	}
 	
+	if status, err := s.loadBalancer.GetTestStatus(testID); err == nil && status.QueuePosition > 0 {
+		return &core.LoadTestResponse{
+			TestID:  testID,
+			Status:  "queued",
+			Message: fmt.Sprintf("Load test queued at position %d", status.QueuePosition),
+		}, nil
+	}
+	
 	return &core.LoadTestResponse{
 		TestID:  testID,
 		Status:  "started",
@@ -92,6 +111,16 @@ func (s *service) StartLoadTest(req core.LoadTestRequest) (*core.LoadTestRespons
 	}, nil
 }
 
+func (s *service) ValidateLoadTest(req core.LoadTestRequest) (*core.ProbeResult, error) {
+	config := s.loadBalancer.ConvertRequestToConfig(req)
+
+	result, err := s.loadBalancer.ProbeTarget(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return result, nil
+}
+
 func (s *service) GetTestStatus(testID string) (*core.LoadTestStatus, error) {
 	return s.loadBalancer.GetTestStatus(testID)
 }
@@ -121,6 +150,10 @@ func (s *service) GetTestResults(testID string, includeResults bool) (map[string
 	return response, nil
 }
 
+func (s *service) GetRawTestResults(testID string) ([]core.LoadTestResult, error) {
+	return s.loadBalancer.GetTestResults(testID)
+}
+
 func (s *service) CancelTest(testID string) (*core.LoadTestResponse, error) {
 	if err := s.loadBalancer.CancelTest(testID); err != nil {
 		return nil, err
@@ -133,6 +166,30 @@ func (s *service) CancelTest(testID string) (*core.LoadTestResponse, error) {
 	}, nil
 }
 
+func (s *service) PauseTest(testID string) (*core.LoadTestResponse, error) {
+	if err := s.loadBalancer.PauseTest(testID); err != nil {
+		return nil, err
+	}
+	
+	return &core.LoadTestResponse{
+		TestID:  testID,
+		Status:  "paused",
+		Message: "Load test paused successfully",
+	}, nil
+}
+
+func (s *service) ResumeTest(testID string) (*core.LoadTestResponse, error) {
+	if err := s.loadBalancer.ResumeTest(testID); err != nil {
+		return nil, err
+	}
+	
+	return &core.LoadTestResponse{
+		TestID:  testID,
+		Status:  "running",
+		Message: "Load test resumed successfully",
+	}, nil
+}
+
 func (s *service) GetAllTests() (map[string]*core.LoadTestStatus, error) {
 	return s.loadBalancer.GetAllTests(), nil
 }
@@ -143,8 +200,9 @@ func (s *service) GetRealTimeMetrics(testID string) (*core.RealTimeMetrics, erro
 		return nil, err
 	}
 
+	// Without retained raw results the engine's digest-based figures are all there is.
 	results, err := s.loadBalancer.GetTestResults(testID)
-	if err != nil {
+	if err != nil || len(results) == 0 {
 		return basicMetrics, nil
 	}
 
@@ -174,6 +232,10 @@ func (s *service) GetRealTimeMetrics(testID string) (*core.RealTimeMetrics, erro
 	return mergedMetrics, nil
 }
 
+func (s *service) GetCapturedFailures(testID string) ([]core.CapturedFailure, error) {
+	return s.loadBalancer.GetCapturedFailures(testID)
+}
+
 func (s *service) GenerateReport(testID string) (map[string]string, error) {
 	if err := s.loadBalancer.GenerateReport(testID); err != nil {
 		return nil, err
@@ -186,8 +248,37 @@ func (s *service) GenerateReport(testID string) (map[string]string, error) {
 	}, nil
 }
 
+// ExportJUnit renders the test's SLA verdict and the outcome of its alerts as JUnit XML.
+func (s *service) ExportJUnit(testID string) ([]byte, error) {
+	summary, err := s.loadBalancer.GetTestSummary(testID)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts, err := s.storage.GetAlertsByTest(testID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].CreatedAt.Before(alerts[j].CreatedAt)
+	})
+
+	outcomes := make([]reporter.AlertOutcome, 0, len(alerts))
+	for _, alert := range alerts {
+		triggers, err := s.alertManager.GetAlertTriggers(alert.ID)
+		if err != nil {
+			return nil, err
+		}
+		outcomes = append(outcomes, reporter.AlertOutcome{Alert: alert, Triggers: triggers})
+	}
+
+	return reporter.MarshalJUnit(summary, outcomes)
+}
+
 func (s *service) GetStats() (map[string]interface{}, error) {
-	return s.loadBalancer.GetEngineStats(), nil
+	stats := s.loadBalancer.GetEngineStats()
+	stats["runtime"] = sharedutils.ReadRuntimeStats()
+	return stats, nil
 }
 
 func (s *service) CleanupOldTests(olderThan time.Duration) (map[string]string, error) {
@@ -217,8 +308,8 @@ func (s *service) GetTestHistory() ([]core.LoadTestHistory, error) {
 	return history, nil
 }
 
-func (s *service) AnalyzeURL(url string) (*core.URLAnalysisResult, error) {
-	return s.analyzer.AnalyzeURL(url)
+func (s *service) AnalyzeURL(ctx context.Context, url string) (*core.URLAnalysisResult, error) {
+	return s.analyzer.AnalyzeURL(ctx, url)
 }
 
 
@@ -324,6 +415,25 @@ func (s *service) GetAllAlertTemplates() ([]*core.AlertTemplate, error) {
 	return s.storage.GetAllAlertTemplates()
 }
 
+func (s *service) InstantiateAlertFromTemplate(templateID, testID string, vars map[string]string) (*core.Alert, error) {
+	template, err := s.storage.GetAlertTemplate(templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	alert, err := s.alertManager.InstantiateAlert(template, testID, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.storage.SaveAlert(alert)
+	if err != nil {
+		return nil, err
+	}
+
+	return alert, nil
+}
+
 func (s *service) GetSupportedMetrics() []string {
 	return s.alertManager.GetSupportedMetrics()
 }
@@ -0,0 +1,117 @@
+package loadbalancer
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/cherry-pick/pkg/loadbalancer/core"
+	"github.com/cherry-pick/pkg/types"
+	"github.com/gin-gonic/gin"
+)
+
+// WorkspaceFunc names the workspace a request acts in.
+type WorkspaceFunc func(c *gin.Context) string
+
+// testOwners records the workspace that started each test, so a test is only visible
+// within that workspace.
+type testOwners struct {
+	mu     sync.RWMutex
+	owners map[string]string
+}
+
+func newTestOwners() *testOwners {
+	return &testOwners{owners: make(map[string]string)}
+}
+
+func (o *testOwners) set(testID, workspace string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.owners[testID] = workspace
+}
+
+func (o *testOwners) owns(workspace, testID string) bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	owner, exists := o.owners[testID]
+	return exists && owner == workspace
+}
+
+// retain forgets the tests missing from tests, once they have been cleaned up.
+func (o *testOwners) retain(tests map[string]*core.LoadTestStatus) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for testID := range o.owners {
+		if _, exists := tests[testID]; !exists {
+			delete(o.owners, testID)
+		}
+	}
+}
+
+// SetWorkspaceFunc scopes load tests to the workspace fn returns for each request.
+// Without it every test is in one shared workspace.
+func (h *Handler) SetWorkspaceFunc(fn WorkspaceFunc) {
+	h.workspaceOf = fn
+}
+
+func (h *Handler) workspace(c *gin.Context) string {
+	if h.workspaceOf == nil {
+		return ""
+	}
+	return h.workspaceOf(c)
+}
+
+// testAccess answers 404 for a :testId that does not exist or was started in another
+// workspace, so the two cases cannot be told apart. Routes without :testId pass.
+func (h *Handler) testAccess() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		testID := c.Param("testId")
+		if testID == "" {
+			c.Next()
+			return
+		}
+
+		if !h.owners.owns(h.workspace(c), testID) {
+			h.sendError(c, http.StatusNotFound,
+				types.NewAPIError(types.ErrCodeNotFound, "Test not found"), "Test not found")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// alertAccess answers 404 for an :alertId that does not exist or watches a test from
+// another workspace, so alerts are scoped like the tests they belong to. Routes without
+// :alertId pass.
+func (h *Handler) alertAccess() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		alertID := c.Param("alertId")
+		if alertID == "" {
+			c.Next()
+			return
+		}
+
+		alert, err := h.service.GetAlert(alertID)
+		if err != nil || alert == nil || !h.owners.owns(h.workspace(c), alert.TestID) {
+			h.sendError(c, http.StatusNotFound,
+				types.NewAPIError(types.ErrCodeNotFound, "Alert not found"), "Alert not found")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// visibleAlerts keeps the alerts on tests started in workspace.
+func (h *Handler) visibleAlerts(workspace string, alerts []*core.Alert) []*core.Alert {
+	visible := []*core.Alert{}
+	for _, alert := range alerts {
+		if h.owners.owns(workspace, alert.TestID) {
+			visible = append(visible, alert)
+		}
+	}
+	return visible
+}
@@ -0,0 +1,186 @@
+package loadbalancer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cherry-pick/pkg/loadbalancer/core"
+	"github.com/gin-gonic/gin"
+)
+
+// mockService answers the calls the workspace checks make. Any other call panics on
+// the nil embedded interface.
+type mockService struct {
+	LoadBalancerService
+	alerts map[string]*core.Alert
+	nextID int
+}
+
+func newMockService() *mockService {
+	return &mockService{alerts: make(map[string]*core.Alert)}
+}
+
+func (m *mockService) StartLoadTest(req core.LoadTestRequest) (*core.LoadTestResponse, error) {
+	m.nextID++
+	return &core.LoadTestResponse{TestID: fmt.Sprintf("test-%d", m.nextID)}, nil
+}
+
+func (m *mockService) GetTestStatus(testID string) (*core.LoadTestStatus, error) {
+	return &core.LoadTestStatus{TestID: testID}, nil
+}
+
+func (m *mockService) CreateAlert(testID string, req core.AlertRequest) (*core.Alert, error) {
+	alert := &core.Alert{ID: "alert-" + testID, TestID: testID, Name: req.Name, Severity: req.Severity}
+	m.alerts[alert.ID] = alert
+	return alert, nil
+}
+
+func (m *mockService) GetAlert(alertID string) (*core.Alert, error) {
+	alert, exists := m.alerts[alertID]
+	if !exists {
+		return nil, errors.New("alert not found")
+	}
+	return alert, nil
+}
+
+func (m *mockService) UpdateAlert(alertID string, req core.AlertRequest) (*core.Alert, error) {
+	alert := m.alerts[alertID]
+	alert.Name = req.Name
+	return alert, nil
+}
+
+func (m *mockService) DeleteAlert(alertID string) error {
+	delete(m.alerts, alertID)
+	return nil
+}
+
+func (m *mockService) GetAllAlerts() ([]*core.Alert, error) {
+	alerts := make([]*core.Alert, 0, len(m.alerts))
+	for _, alert := range m.alerts {
+		alerts = append(alerts, alert)
+	}
+	return alerts, nil
+}
+
+func (m *mockService) GetAlertTriggers(alertID string) ([]*core.AlertTrigger, error) {
+	return []*core.AlertTrigger{}, nil
+}
+
+func newWorkspaceRouter(service LoadBalancerService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewHandler(service)
+	handler.SetWorkspaceFunc(func(c *gin.Context) string {
+		return c.GetHeader("X-Workspace-ID")
+	})
+
+	router := gin.New()
+	SetupRoutes(router.Group("/api"), handler)
+	return router
+}
+
+func serve(router *gin.Engine, method, path, workspace, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Workspace-ID", workspace)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func startTestIn(t *testing.T, router *gin.Engine, workspace string) string {
+	t.Helper()
+
+	w := serve(router, http.MethodPost, "/api/loadbalancer/tests", workspace,
+		`{"url":"http://example.com","concurrentUsers":1,"duration":1}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 starting a test, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Data core.LoadTestResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Expected a JSON response, got %v", err)
+	}
+	return response.Data.TestID
+}
+
+func TestWorkspaceCannotReachAnotherWorkspacesTestsOrAlerts(t *testing.T) {
+	router := newWorkspaceRouter(newMockService())
+
+	testID := startTestIn(t, router, "workspace-a")
+
+	w := serve(router, http.MethodPost, "/api/loadbalancer/tests/"+testID+"/alerts", "workspace-a",
+		`{"name":"slow","condition":"avg_response_time > 100","metric":"avg_response_time","operator":">","threshold":100,"severity":"high"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 creating an alert, got %d: %s", w.Code, w.Body.String())
+	}
+	alertID := "alert-" + testID
+
+	requests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodGet, "/api/loadbalancer/tests/" + testID, ""},
+		{http.MethodDelete, "/api/loadbalancer/tests/" + testID, ""},
+		{http.MethodPost, "/api/loadbalancer/tests/" + testID + "/alerts", `{"name":"x","condition":"error_rate > 1","metric":"error_rate","operator":">","threshold":1}`},
+		{http.MethodGet, "/api/loadbalancer/alerts/" + alertID, ""},
+		{http.MethodPut, "/api/loadbalancer/alerts/" + alertID, `{"name":"renamed","condition":"error_rate > 1","metric":"error_rate","operator":">","threshold":1}`},
+		{http.MethodDelete, "/api/loadbalancer/alerts/" + alertID, ""},
+		{http.MethodGet, "/api/loadbalancer/alerts/" + alertID + "/triggers", ""},
+		{http.MethodPost, "/api/loadbalancer/alert-templates/template-1/instantiate", `{"testId":"` + testID + `"}`},
+	}
+
+	for _, r := range requests {
+		w := serve(router, r.method, r.path, "workspace-b", r.body)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for %s %s from another workspace, got %d", r.method, r.path, w.Code)
+		}
+	}
+
+	w = serve(router, http.MethodGet, "/api/loadbalancer/alerts/"+alertID, "workspace-a", "")
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected the owning workspace to still read its alert, got %d", w.Code)
+	}
+}
+
+func TestAlertListingAndStatsAreScopedToWorkspace(t *testing.T) {
+	router := newWorkspaceRouter(newMockService())
+
+	testA := startTestIn(t, router, "workspace-a")
+	testB := startTestIn(t, router, "workspace-b")
+
+	alert := `{"name":"slow","condition":"avg_response_time > 100","metric":"avg_response_time","operator":">","threshold":100,"severity":"critical"}`
+	serve(router, http.MethodPost, "/api/loadbalancer/tests/"+testA+"/alerts", "workspace-a", alert)
+	serve(router, http.MethodPost, "/api/loadbalancer/tests/"+testB+"/alerts", "workspace-b", alert)
+
+	w := serve(router, http.MethodGet, "/api/loadbalancer/alerts", "workspace-b", "")
+	var listed struct {
+		Data []core.Alert `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("Expected a JSON response, got %v", err)
+	}
+	if len(listed.Data) != 1 || listed.Data[0].TestID != testB {
+		t.Errorf("Expected only the alert on %s, got %+v", testB, listed.Data)
+	}
+
+	w = serve(router, http.MethodGet, "/api/loadbalancer/alerts/stats", "workspace-b", "")
+	var stats struct {
+		Data core.AlertStats `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("Expected a JSON response, got %v", err)
+	}
+	if stats.Data.TotalAlerts != 1 || stats.Data.CriticalAlerts != 1 {
+		t.Errorf("Expected stats over 1 critical alert, got %+v", stats.Data)
+	}
+}
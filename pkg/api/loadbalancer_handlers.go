@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/cherry-pick/pkg/loadbalancer"
+	lbanalyzer "github.com/cherry-pick/pkg/loadbalancer/analyzer"
 	"github.com/gin-gonic/gin"
 )
 
@@ -18,7 +19,9 @@ func InitializeLoadBalancer() {
 
 // URLAnalysisRequest represents the request to analyze a URL
 type URLAnalysisRequest struct {
-	URL string `json:"url" binding:"required"`
+	URL     string            `json:"url" binding:"required"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Cookies map[string]string `json:"cookies,omitempty"`
 }
 
 // analyzeURL analyzes a URL and discovers its pages
@@ -30,10 +33,15 @@ func (s *Server) analyzeURL(c *gin.Context) {
 	}
 
 	// Create URL analyzer
-	analyzer := loadbalancer.NewURLAnalyzer()
+	analyzer := lbanalyzer.NewURLAnalyzer()
+	credentials := lbanalyzer.CrawlCredentials{Headers: req.Headers, Cookies: req.Cookies}
+	if err := analyzer.SetCredentials(credentials); err != nil {
+		s.sendError(c, http.StatusBadRequest, err, "Invalid crawl credentials")
+		return
+	}
 
 	// Analyze the URL
-	result, err := analyzer.AnalyzeURL(req.URL)
+	result, err := analyzer.AnalyzeURL(c.Request.Context(), req.URL)
 	if err != nil {
 		s.sendError(c, http.StatusInternalServerError, err, "Failed to analyze URL")
 		return
@@ -158,6 +166,9 @@ func (s *Server) getLoadTestResults(c *gin.Context) {
 			return
 		}
 		response["results"] = results
+		if !summary.Config.RetainRawResults {
+			response["message"] = "Raw results were not kept for this test; the summary is built from the latency accumulator"
+		}
 	}
 
 	s.sendSuccess(c, response)
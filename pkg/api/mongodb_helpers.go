@@ -73,7 +73,7 @@ func (s *Server) getMongoCollectionData(service *intelligence.Service, collectio
 	return documents, totalCount, nil
 }
 
-func (s *Server) getMongoCollectionStats(service *intelligence.Service, collectionName string) (*CollectionStatsResponse, error) {
+func (s *Server) getMongoCollectionStats(service *intelligence.Service, collectionName string, sampling collectionSampling) (*CollectionStatsResponse, error) {
 	mongoService := service.GetMongoService()
 	if mongoService == nil {
 		return nil, fmt.Errorf("not a MongoDB service")
@@ -95,7 +95,7 @@ func (s *Server) getMongoCollectionStats(service *intelligence.Service, collecti
 		return nil, fmt.Errorf("failed to count documents: %w", err)
 	}
 
-	sampleSize := int64(100)
+	sampleSize := sampling.documents
 	if docCount < sampleSize {
 		sampleSize = docCount
 	}
@@ -139,8 +139,8 @@ func (s *Server) getMongoCollectionStats(service *intelligence.Service, collecti
 			} else {
 				stat.Type = getValueType(value)
 
-				if len(stat.SampleValues) < 5 {
-					stat.SampleValues = append(stat.SampleValues, fmt.Sprintf("%v", value))
+				if len(stat.SampleValues) < sampling.values {
+					stat.SampleValues = append(stat.SampleValues, truncateSampleValue(fmt.Sprintf("%v", value)))
 				}
 			}
 		}
@@ -168,10 +168,11 @@ func (s *Server) getMongoCollectionStats(service *intelligence.Service, collecti
 	}
 
 	response := &CollectionStatsResponse{
-		CollectionName: collectionName,
-		DocumentCount:  docCount,
-		Fields:         fields,
-		Indexes:        indexes,
+		CollectionName:   collectionName,
+		DocumentCount:    docCount,
+		SampledDocuments: int64(len(documents)),
+		Fields:           fields,
+		Indexes:          indexes,
 	}
 
 	return response, nil
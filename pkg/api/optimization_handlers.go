@@ -0,0 +1,202 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/cherry-pick/pkg/types"
+	"github.com/gin-gonic/gin"
+)
+
+type SavedQueryRequest struct {
+	Name  string `json:"name"`
+	Query string `json:"query" binding:"required"`
+}
+
+type UpdateSavedQueryRequest struct {
+	Name    *string `json:"name,omitempty"`
+	Query   *string `json:"query,omitempty"`
+	Applied *bool   `json:"applied,omitempty"`
+}
+
+// IndexRecommendationRequest carries queries to recommend indexes for, such as entries
+// from a slow query log. Frequency weights a query; zero counts once.
+type IndexRecommendationRequest struct {
+	Queries []types.SlowQuery `json:"queries"`
+}
+
+func (s *Server) connectionExists(c *gin.Context, id string) bool {
+	mutex.RLock()
+	_, exists := connections[id]
+	mutex.RUnlock()
+
+	if !exists {
+		s.sendError(c, http.StatusNotFound,
+			types.NewAPIError(types.ErrCodeConnectionNotFound, "Connection not found"), "Connection not found")
+	}
+	return exists
+}
+
+func (s *Server) listSavedQueries(c *gin.Context) {
+	id := c.Param("id")
+	if !s.connectionExists(c, id) {
+		return
+	}
+
+	s.sendSuccess(c, s.savedQueries.List(id))
+}
+
+func (s *Server) createSavedQuery(c *gin.Context) {
+	id := c.Param("id")
+	var req SavedQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.sendError(c, http.StatusBadRequest, err, "Invalid request data")
+		return
+	}
+	if !s.connectionExists(c, id) {
+		return
+	}
+
+	query, err := s.savedQueries.Save(types.SavedQuery{
+		ConnectionID: id,
+		Name:         req.Name,
+		Query:        req.Query,
+	})
+	if err != nil {
+		s.sendError(c, http.StatusBadRequest, types.NewAPIError(types.ErrCodeInvalidRequest, err.Error()), "Failed to save query")
+		return
+	}
+
+	s.sendSuccess(c, query, "Query saved successfully")
+}
+
+func (s *Server) getSavedQuery(c *gin.Context) {
+	id := c.Param("id")
+	if !s.connectionExists(c, id) {
+		return
+	}
+
+	query, err := s.savedQueries.Get(id, c.Param("queryId"))
+	if err != nil {
+		s.sendError(c, http.StatusNotFound, types.NewAPIError(types.ErrCodeNotFound, err.Error()), "Saved query not found")
+		return
+	}
+
+	s.sendSuccess(c, query)
+}
+
+func (s *Server) updateSavedQuery(c *gin.Context) {
+	id := c.Param("id")
+	var req UpdateSavedQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.sendError(c, http.StatusBadRequest, err, "Invalid request data")
+		return
+	}
+	if !s.connectionExists(c, id) {
+		return
+	}
+
+	queryID := c.Param("queryId")
+	if _, err := s.savedQueries.Get(id, queryID); err != nil {
+		s.sendError(c, http.StatusNotFound, types.NewAPIError(types.ErrCodeNotFound, err.Error()), "Saved query not found")
+		return
+	}
+
+	query, err := s.savedQueries.Update(id, queryID, req.Name, req.Query, req.Applied)
+	if err != nil {
+		s.sendError(c, http.StatusBadRequest, types.NewAPIError(types.ErrCodeInvalidRequest, err.Error()), "Failed to update saved query")
+		return
+	}
+
+	s.sendSuccess(c, query, "Saved query updated successfully")
+}
+
+func (s *Server) deleteSavedQuery(c *gin.Context) {
+	id := c.Param("id")
+	if !s.connectionExists(c, id) {
+		return
+	}
+
+	if err := s.savedQueries.Delete(id, c.Param("queryId")); err != nil {
+		s.sendError(c, http.StatusNotFound, types.NewAPIError(types.ErrCodeNotFound, err.Error()), "Saved query not found")
+		return
+	}
+
+	s.sendSuccess(c, nil, "Saved query deleted successfully")
+}
+
+func (s *Server) optimizeSavedQuery(c *gin.Context) {
+	id := c.Param("id")
+	queryID := c.Param("queryId")
+
+	query, err := s.savedQueries.Get(id, queryID)
+	if err != nil {
+		s.sendError(c, http.StatusNotFound, types.NewAPIError(types.ErrCodeNotFound, err.Error()), "Saved query not found")
+		return
+	}
+
+	mutex.RLock()
+	service, serviceExists := services[id]
+	mutex.RUnlock()
+
+	if !serviceExists {
+		s.sendError(c, http.StatusBadRequest,
+			types.NewAPIError(types.ErrCodeNotConnected, "Connection not established"), "Please test the connection first")
+		return
+	}
+
+	suggestion, err := service.OptimizeQuery(query.Query)
+	if err != nil {
+		s.sendError(c, http.StatusInternalServerError, err, "Failed to optimize query")
+		return
+	}
+
+	entry, err := s.savedQueries.RecordSuggestion(id, queryID, query.Query, *suggestion)
+	if err != nil {
+		s.sendError(c, http.StatusNotFound, types.NewAPIError(types.ErrCodeNotFound, err.Error()), "Saved query not found")
+		return
+	}
+
+	s.sendSuccess(c, entry)
+}
+
+// recommendIndexes proposes composite and partial indexes for the posted queries. With no
+// queries it uses the slow queries recorded in the connection's latest report.
+func (s *Server) recommendIndexes(c *gin.Context) {
+	id := c.Param("id")
+	var req IndexRecommendationRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		s.sendError(c, http.StatusBadRequest, err, "Invalid request data")
+		return
+	}
+
+	mutex.RLock()
+	service, serviceExists := services[id]
+	report := reports[id]
+	mutex.RUnlock()
+
+	if !serviceExists {
+		s.sendError(c, http.StatusBadRequest,
+			types.NewAPIError(types.ErrCodeNotConnected, "Connection not established"), "Please test the connection first")
+		return
+	}
+
+	queries := req.Queries
+	if len(queries) == 0 && report != nil {
+		queries = report.PerformanceMetrics.SlowQueries
+	}
+	if len(queries) == 0 {
+		s.sendError(c, http.StatusBadRequest,
+			types.NewAPIError(types.ErrCodeInvalidRequest, "No queries given and no slow queries recorded"), "No queries to analyze")
+		return
+	}
+
+	recommendations, err := service.RecommendIndexes(queries)
+	if err != nil {
+		s.sendError(c, http.StatusBadRequest, types.NewAPIError(types.ErrCodeInvalidRequest, err.Error()), "Failed to recommend indexes")
+		return
+	}
+
+	s.sendSuccess(c, recommendations)
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/cherry-pick/pkg/logging"
+	"github.com/cherry-pick/pkg/types"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DefaultOverviewInsights    = 5
+	MaxOverviewInsights        = 50
+	DefaultOverviewTrendPoints = 10
+)
+
+var insightSeverityRank = map[string]int{
+	"critical": 4,
+	"high":     3,
+	"medium":   2,
+	"low":      1,
+}
+
+// getOverview combines the latest stored report, its most severe insights, the alerts
+// from the last alert check and recent health-score trend points. It never starts an
+// analysis, so it stays cheap enough for dashboards to poll.
+func (s *Server) getOverview(c *gin.Context) {
+	id := c.Param("id")
+
+	limit := DefaultOverviewInsights
+	if parsed, err := strconv.Atoi(c.Query("insights")); err == nil && parsed > 0 {
+		limit = parsed
+		if limit > MaxOverviewInsights {
+			limit = MaxOverviewInsights
+		}
+	}
+
+	mutex.RLock()
+	_, exists := connections[id]
+	report := reports[id]
+	service, serviceExists := services[id]
+	mutex.RUnlock()
+
+	if !exists {
+		s.sendError(c, http.StatusNotFound,
+			types.NewAPIError(types.ErrCodeConnectionNotFound, "Connection not found"), "Connection not found")
+		return
+	}
+
+	history, err := s.reportHistory.List(id)
+	if err != nil {
+		logging.Default().Warn("Failed to read report history", "connection", id, "error", err)
+	}
+	if report == nil && len(history) > 0 {
+		report = history[len(history)-1]
+	}
+
+	overview := types.DatabaseOverview{
+		ConnectionID: id,
+		TopInsights:  []types.DatabaseInsight{},
+		ActiveAlerts: []types.MonitoringAlert{},
+		Trends:       []types.TrendPoint{},
+	}
+
+	if report != nil {
+		analysisTime := report.AnalysisTime
+		summary := report.Summary
+		overview.Analyzed = true
+		overview.DatabaseName = report.DatabaseName
+		overview.DatabaseType = report.DatabaseType
+		overview.AnalysisTime = &analysisTime
+		overview.Summary = &summary
+		overview.TotalIssues = len(report.Insights)
+		overview.TopInsights = topInsights(report.Insights, limit)
+	}
+
+	if serviceExists {
+		overview.ActiveAlerts = service.ActiveAlerts()
+	}
+
+	if start := len(history) - DefaultOverviewTrendPoints; start > 0 {
+		history = history[start:]
+	}
+	for _, entry := range history {
+		overview.Trends = append(overview.Trends, types.TrendPoint{
+			Timestamp: entry.AnalysisTime,
+			Metric:    types.TrendMetricHealthScore,
+			Value:     entry.Summary.HealthScore,
+		})
+	}
+
+	s.sendSuccess(c, overview)
+}
+
+// topInsights returns up to limit insights, most severe first, keeping the report's
+// order within a severity.
+func topInsights(insights []types.DatabaseInsight, limit int) []types.DatabaseInsight {
+	sorted := make([]types.DatabaseInsight, len(insights))
+	copy(sorted, insights)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return insightSeverityRank[sorted[i].Severity] > insightSeverityRank[sorted[j].Severity]
+	})
+
+	if len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	return sorted
+}
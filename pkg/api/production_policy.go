@@ -0,0 +1,40 @@
+package api
+
+import "strings"
+
+// DefaultProductionTag marks a connection as production, alongside the "prod"
+// environment.
+const DefaultProductionTag = "prod"
+
+// ProductionPolicy decides whether analyses that read table data from production
+// connections need an explicit ?confirm=true. A connection is production when its
+// environment is "prod" or it carries one of Tags.
+type ProductionPolicy struct {
+	RequireConfirmation bool
+	Tags                []string
+}
+
+func WithProductionPolicy(policy ProductionPolicy) ServerOption {
+	return func(s *Server) {
+		s.productionPolicy = policy
+	}
+}
+
+func (p ProductionPolicy) isProduction(connectionID string) bool {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	connection, exists := connections[connectionID]
+	if !exists {
+		return false
+	}
+	if connection.Environment == EnvironmentProd {
+		return true
+	}
+	for _, tag := range p.Tags {
+		if hasTag(connection.Tags, strings.ToLower(strings.TrimSpace(tag))) {
+			return true
+		}
+	}
+	return false
+}
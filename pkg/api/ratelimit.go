@@ -0,0 +1,135 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cherry-pick/pkg/types"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DefaultRateLimitRPS   = 20.0
+	DefaultRateLimitBurst = 40
+	DefaultRateLimitIdle  = 10 * time.Minute
+
+	RateLimitSessionHeader = "X-Session-ID"
+)
+
+type RateLimitConfig struct {
+	RequestsPerSecond float64
+	Burst             int
+	PerSession        bool
+	IdleTTL           time.Duration
+}
+
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		RequestsPerSecond: DefaultRateLimitRPS,
+		Burst:             DefaultRateLimitBurst,
+		IdleTTL:           DefaultRateLimitIdle,
+	}
+}
+
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+type RateLimiter struct {
+	config      RateLimitConfig
+	buckets     map[string]*tokenBucket
+	lastCleanup time.Time
+	now         func() time.Time
+	mu          sync.Mutex
+}
+
+func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+	if config.Burst <= 0 {
+		config.Burst = int(math.Max(1, math.Ceil(config.RequestsPerSecond)))
+	}
+	if config.IdleTTL <= 0 {
+		config.IdleTTL = DefaultRateLimitIdle
+	}
+
+	return &RateLimiter{
+		config:      config,
+		buckets:     make(map[string]*tokenBucket),
+		lastCleanup: time.Now(),
+		now:         time.Now,
+	}
+}
+
+// Allow takes a token from the bucket for key and, when empty, reports how long until the next token.
+func (rl *RateLimiter) Allow(key string) (bool, time.Duration) {
+	if rl.config.RequestsPerSecond <= 0 {
+		return true, 0
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	rl.cleanup(now)
+
+	bucket, exists := rl.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: float64(rl.config.Burst), lastSeen: now}
+		rl.buckets[key] = bucket
+	}
+
+	elapsed := now.Sub(bucket.lastSeen).Seconds()
+	bucket.tokens = math.Min(float64(rl.config.Burst), bucket.tokens+elapsed*rl.config.RequestsPerSecond)
+	bucket.lastSeen = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+
+	wait := (1 - bucket.tokens) / rl.config.RequestsPerSecond
+	return false, time.Duration(wait * float64(time.Second))
+}
+
+func (rl *RateLimiter) cleanup(now time.Time) {
+	if now.Sub(rl.lastCleanup) < rl.config.IdleTTL {
+		return
+	}
+	for key, bucket := range rl.buckets {
+		if now.Sub(bucket.lastSeen) > rl.config.IdleTTL {
+			delete(rl.buckets, key)
+		}
+	}
+	rl.lastCleanup = now
+}
+
+func (rl *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, retryAfter := rl.Allow("ip:" + c.ClientIP())
+		if allowed && rl.config.PerSession {
+			if sessionID := c.GetHeader(RateLimitSessionHeader); sessionID != "" {
+				allowed, retryAfter = rl.Allow("session:" + sessionID)
+			}
+		}
+
+		if !allowed {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			if seconds < 1 {
+				seconds = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, APIResponse{
+				Success: false,
+				Error:   "Rate limit exceeded",
+				Code:    types.ErrCodeRateLimited,
+				Details: map[string]int{"retryAfterSeconds": seconds},
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeClock stands in for RateLimiter.now so refills happen only when the test says so.
+type fakeClock struct {
+	now time.Time
+}
+
+func (fc *fakeClock) Now() time.Time { return fc.now }
+
+func newRateLimitedRouter(config RateLimitConfig) (*gin.Engine, *fakeClock) {
+	gin.SetMode(gin.TestMode)
+
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(config)
+	limiter.now = clock.Now
+	limiter.lastCleanup = clock.now
+
+	router := gin.New()
+	router.GET("/events", limiter.Middleware(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router, clock
+}
+
+func sendRateLimited(router *gin.Engine, sessionID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	if sessionID != "" {
+		req.Header.Set(RateLimitSessionHeader, sessionID)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimiterRejectsRequestsPastBurst(t *testing.T) {
+	router, clock := newRateLimitedRouter(RateLimitConfig{RequestsPerSecond: 0.5, Burst: 3})
+
+	for i := 0; i < 3; i++ {
+		if w := sendRateLimited(router, ""); w.Code != http.StatusOK {
+			t.Fatalf("Expected request %d within the burst to pass, got %d", i+1, w.Code)
+		}
+	}
+
+	w := sendRateLimited(router, "")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429 past the burst, got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Expected Retry-After 2 at 0.5 requests per second, got %q", got)
+	}
+
+	clock.now = clock.now.Add(2 * time.Second)
+	if w := sendRateLimited(router, ""); w.Code != http.StatusOK {
+		t.Errorf("Expected a request to pass once a token refilled, got %d", w.Code)
+	}
+	if w := sendRateLimited(router, ""); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429 after spending the refilled token, got %d", w.Code)
+	}
+}
+
+func TestRateLimiterRetryAfterIsAtLeastOneSecond(t *testing.T) {
+	router, _ := newRateLimitedRouter(RateLimitConfig{RequestsPerSecond: 100, Burst: 1})
+
+	sendRateLimited(router, "")
+	w := sendRateLimited(router, "")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429, got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Expected Retry-After 1, got %q", got)
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	router, _ := newRateLimitedRouter(RateLimitConfig{RequestsPerSecond: 0})
+
+	for i := 0; i < 100; i++ {
+		if w := sendRateLimited(router, ""); w.Code != http.StatusOK {
+			t.Fatalf("Expected no limit with RequestsPerSecond 0, got %d", w.Code)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cherry-pick/pkg/types"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// RealTimeSubprotocol is the subprotocol the real-time analytics socket selects.
+	// Browsers that pass their token as a subprotocol must offer it as well, because a
+	// handshake that selects none of the offered subprotocols fails.
+	RealTimeSubprotocol = "cherry-pick.analytics.v1"
+
+	// RealTimeTokenProtocolPrefix marks the subprotocol carrying the access token, as in
+	// new WebSocket(url, ["cherry-pick.analytics.v1", "bearer." + token]).
+	RealTimeTokenProtocolPrefix = "bearer."
+
+	realTimeTokenQueryParam = "token"
+)
+
+// RealTimeAuthConfig guards the real-time analytics WebSocket. Browsers don't apply
+// CORS to WebSocket handshakes, so the Origin header is checked here before upgrading.
+type RealTimeAuthConfig struct {
+	// AllowedOrigins lists the origins that may open the socket; "*" allows any.
+	// Handshakes without an Origin header come from non-browser clients and are not
+	// origin checked.
+	AllowedOrigins []string
+	// Tokens lists the accepted access tokens. No token is required when it is empty.
+	Tokens []string
+}
+
+func WithRealTimeAuth(config RealTimeAuthConfig) ServerOption {
+	return func(s *Server) {
+		s.realTimeAuth = config
+	}
+}
+
+// authorizeRealTime answers 403 for a handshake from a disallowed origin and 401 for
+// one without a valid token, so the connection is never upgraded. It reports whether
+// the handshake may proceed.
+func (s *Server) authorizeRealTime(c *gin.Context) bool {
+	if !s.realTimeAuth.originAllowed(c.Request) {
+		s.sendError(c, http.StatusForbidden,
+			types.NewAPIError(types.ErrCodeForbidden, "Origin not allowed"), "Origin not allowed")
+		return false
+	}
+	if !s.realTimeAuth.tokenAllowed(c.Request) {
+		s.sendError(c, http.StatusUnauthorized,
+			types.NewAPIError(types.ErrCodeUnauthorized, "A valid access token is required"),
+			"Pass the token as ?token=, a Bearer Authorization header or a "+RealTimeTokenProtocolPrefix+" subprotocol")
+		return false
+	}
+	return true
+}
+
+// upgrader checks the origin again itself, so the socket stays closed to other origins
+// even if a handler upgrades without calling authorizeRealTime first.
+func (config RealTimeAuthConfig) upgrader() websocket.Upgrader {
+	return websocket.Upgrader{
+		CheckOrigin:  config.originAllowed,
+		Subprotocols: []string{RealTimeSubprotocol},
+	}
+}
+
+func (config RealTimeAuthConfig) originAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	origin = strings.ToLower(parsed.Scheme + "://" + parsed.Host)
+
+	for _, allowed := range config.AllowedOrigins {
+		allowed = strings.ToLower(strings.TrimRight(strings.TrimSpace(allowed), "/"))
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+func (config RealTimeAuthConfig) tokenAllowed(r *http.Request) bool {
+	if len(config.Tokens) == 0 {
+		return true
+	}
+
+	for _, candidate := range realTimeTokenCandidates(r) {
+		for _, token := range config.Tokens {
+			token = strings.TrimSpace(token)
+			if token != "" && subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func realTimeTokenCandidates(r *http.Request) []string {
+	var candidates []string
+	if token := r.URL.Query().Get(realTimeTokenQueryParam); token != "" {
+		candidates = append(candidates, token)
+	}
+	if header := r.Header.Get("Authorization"); len(header) > len("Bearer ") && strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		candidates = append(candidates, strings.TrimSpace(header[len("Bearer "):]))
+	}
+	for _, protocol := range websocket.Subprotocols(r) {
+		if strings.HasPrefix(protocol, RealTimeTokenProtocolPrefix) {
+			candidates = append(candidates, strings.TrimPrefix(protocol, RealTimeTokenProtocolPrefix))
+		}
+	}
+	return candidates
+}
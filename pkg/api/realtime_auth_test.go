@@ -0,0 +1,159 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newRealTimeRequest(origin, target string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	if origin != "" {
+		r.Header.Set("Origin", origin)
+	}
+	return r
+}
+
+func TestRealTimeOriginAllowed(t *testing.T) {
+	config := RealTimeAuthConfig{AllowedOrigins: []string{"https://app.example.com/", " http://localhost:3000"}}
+
+	tests := []struct {
+		name   string
+		origin string
+		want   bool
+	}{
+		{"no origin", "", true},
+		{"allowed", "https://app.example.com", true},
+		{"case insensitive", "HTTPS://App.Example.com", true},
+		{"trimmed entry", "http://localhost:3000", true},
+		{"other origin", "https://evil.example.com", false},
+		{"other scheme", "http://app.example.com", false},
+		{"malformed", "not an origin", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := config.originAllowed(newRealTimeRequest(tt.origin, "/ws")); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRealTimeOriginWildcard(t *testing.T) {
+	config := RealTimeAuthConfig{AllowedOrigins: []string{"*"}}
+	if !config.originAllowed(newRealTimeRequest("https://anywhere.example.com", "/ws")) {
+		t.Errorf("Expected a wildcard to allow any origin")
+	}
+}
+
+func TestRealTimeTokenCandidates(t *testing.T) {
+	r := newRealTimeRequest("", "/ws?token=query-token")
+	r.Header.Set("Authorization", "bearer header-token")
+	r.Header.Set("Sec-WebSocket-Protocol", RealTimeSubprotocol+", "+RealTimeTokenProtocolPrefix+"protocol-token")
+
+	want := []string{"query-token", "header-token", "protocol-token"}
+	if got := realTimeTokenCandidates(r); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestRealTimeTokenCandidatesIgnoresOtherSchemes(t *testing.T) {
+	r := newRealTimeRequest("", "/ws")
+	r.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+
+	if got := realTimeTokenCandidates(r); len(got) != 0 {
+		t.Errorf("Expected no candidates, got %v", got)
+	}
+}
+
+func TestRealTimeTokenAllowed(t *testing.T) {
+	config := RealTimeAuthConfig{Tokens: []string{"alpha", " beta ", ""}}
+
+	tests := []struct {
+		name   string
+		target string
+		want   bool
+	}{
+		{"no token", "/ws", false},
+		{"valid token", "/ws?token=alpha", true},
+		{"trimmed token", "/ws?token=beta", true},
+		{"wrong token", "/ws?token=gamma", false},
+		{"empty token", "/ws?token=", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := config.tokenAllowed(newRealTimeRequest("", tt.target)); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRealTimeTokenNotRequiredWithoutTokens(t *testing.T) {
+	if !(RealTimeAuthConfig{}).tokenAllowed(newRealTimeRequest("", "/ws")) {
+		t.Errorf("Expected no token to be required when none are configured")
+	}
+}
+
+func TestAuthorizeRealTime(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := &Server{realTimeAuth: RealTimeAuthConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+		Tokens:         []string{"secret"},
+	}}
+
+	tests := []struct {
+		name       string
+		origin     string
+		target     string
+		wantOK     bool
+		wantStatus int
+	}{
+		{"authorized", "https://app.example.com", "/ws?token=secret", true, http.StatusOK},
+		{"bad origin", "https://evil.example.com", "/ws?token=secret", false, http.StatusForbidden},
+		{"missing token", "https://app.example.com", "/ws", false, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = newRealTimeRequest(tt.origin, tt.target)
+
+			if ok := server.authorizeRealTime(c); ok != tt.wantOK {
+				t.Fatalf("Expected %v, got %v", tt.wantOK, ok)
+			}
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, recorder.Code)
+			}
+		})
+	}
+}
+
+func TestGetRealTimeAuth(t *testing.T) {
+	t.Setenv("REALTIME_ALLOWED_ORIGINS", "https://app.example.com")
+	t.Setenv("REALTIME_AUTH_TOKENS", "alpha,beta")
+
+	config := getRealTimeAuth([]string{"http://localhost:3000"})
+	if want := []string{"https://app.example.com"}; !reflect.DeepEqual(config.AllowedOrigins, want) {
+		t.Errorf("Expected %v, got %v", want, config.AllowedOrigins)
+	}
+	if want := []string{"alpha", "beta"}; !reflect.DeepEqual(config.Tokens, want) {
+		t.Errorf("Expected %v, got %v", want, config.Tokens)
+	}
+}
+
+func TestGetRealTimeAuthDefaults(t *testing.T) {
+	t.Setenv("REALTIME_ALLOWED_ORIGINS", "")
+	t.Setenv("REALTIME_AUTH_TOKENS", "")
+
+	config := getRealTimeAuth([]string{"http://localhost:3000"})
+	if want := []string{"http://localhost:3000"}; !reflect.DeepEqual(config.AllowedOrigins, want) {
+		t.Errorf("Expected the CORS origins, got %v", config.AllowedOrigins)
+	}
+	if len(config.Tokens) != 0 {
+		t.Errorf("Expected no tokens, got %v", config.Tokens)
+	}
+}
@@ -3,14 +3,23 @@ package api
 import (
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/cherry-pick/pkg/analytics"
+	analyticscore "github.com/cherry-pick/pkg/analytics/core"
+	analyticsapi "github.com/cherry-pick/pkg/api/analytics"
 	"github.com/cherry-pick/pkg/api/analyzer"
-	"github.com/cherry-pick/pkg/api/loadbalancer"
+	loadbalancerapi "github.com/cherry-pick/pkg/api/loadbalancer"
 	"github.com/cherry-pick/pkg/analyzer"
+	"github.com/cherry-pick/pkg/connector"
+	"github.com/cherry-pick/pkg/insights"
 	"github.com/cherry-pick/pkg/loadbalancer"
+	"github.com/cherry-pick/pkg/logging"
+	"github.com/cherry-pick/pkg/optimization"
+	"github.com/cherry-pick/pkg/types"
+	"github.com/cherry-pick/pkg/utils"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
@@ -20,9 +29,32 @@ type Server struct {
 	port          string
 	loadBalancer  *loadbalancer.LoadBalancer
 	urlAnalyzer   *loadbalancer.URLAnalyzer
+	statsCache    *utils.TTLCache
+	reportHistory *insights.ReportHistoryStore
+	rateLimit     RateLimitConfig
+	realTimeAuth  RealTimeAuthConfig
+	readiness     ReadinessConfig
+	savedQueries  *optimization.SavedQueryStore
+	idempotency   *IdempotencyStore
+	analyses      *analysisRegistry
+
+	connectTimeout time.Duration
+
+	requireWorkspace     bool
+	workspaces           WorkspaceAccess
+	dataBrowsingDisabled bool
+	productionPolicy     ProductionPolicy
 }
 
-func NewServer(port string) *Server {
+type ServerOption func(*Server)
+
+func WithAnalyticsRateLimit(config RateLimitConfig) ServerOption {
+	return func(s *Server) {
+		s.rateLimit = config
+	}
+}
+
+func NewServer(port string, opts ...ServerOption) *Server {
 	router := gin.Default()
 
 	allowedOrigins := getCORSOrigins()
@@ -42,6 +74,7 @@ func NewServer(port string) *Server {
 	}))
 
 	lb := loadbalancer.NewLoadBalancer("./reports")
+	lb.SetMaxConcurrentTests(getMaxConcurrentLoadTests())
 	analyzer := loadbalancer.NewURLAnalyzer()
 
 	server := &Server{
@@ -49,7 +82,32 @@ func NewServer(port string) *Server {
 		port:         port,
 		loadBalancer: lb,
 		urlAnalyzer:  analyzer,
+		statsCache:   utils.NewTTLCache(getStatsCacheTTL(), getStatsCacheSize()),
+		rateLimit:    getAnalyticsRateLimit(),
+		realTimeAuth: getRealTimeAuth(allowedOrigins),
+		readiness:    getReadinessConfig(),
+		savedQueries: optimization.NewSavedQueryStore(optimization.DefaultHistorySize),
+		idempotency:  NewIdempotencyStore(getIdempotencyTTL(), DefaultIdempotencyEntries),
+		analyses:     newAnalysisRegistry(),
+
+		connectTimeout: getConnectTimeout(),
+
+		requireWorkspace:     getWorkspaceRequired(),
+		workspaces:           getWorkspaceAccess(),
+		dataBrowsingDisabled: getDataBrowsingDisabled(),
+		productionPolicy:     getProductionPolicy(),
+	}
+
+	for _, opt := range opts {
+		opt(server)
+	}
+
+	history, err := insights.NewReportHistoryStore(os.Getenv("REPORT_HISTORY_DIR"), getReportHistorySize())
+	if err != nil {
+		logging.Default().Warn("Falling back to in-memory report history", "error", err)
+		history, _ = insights.NewReportHistoryStore("", getReportHistorySize())
 	}
+	server.reportHistory = history
 
 	InitializeAnalytics()
 
@@ -58,37 +116,60 @@ func NewServer(port string) *Server {
 }
 
 func (s *Server) setupRoutes() {
-	api := s.router.Group("/api")
+	s.router.GET("/healthz", s.healthz)
+	s.router.GET("/readyz", s.readyz)
+
+	api := s.router.Group("/api", s.workspaceMiddleware())
 	{
 		// @Connection routes
-		connections := api.Group("/connections")
+		connections := api.Group("/connections", s.connectionAccess())
 		{
 			connections.GET("", s.getConnections)
-			connections.POST("", s.createConnection)
+			connections.POST("", s.idempotency.Middleware(), s.createConnection)
+			connections.PATCH("/:id", s.updateConnection)
 			connections.POST("/:id/test", s.testConnection)
+			connections.GET("/:id/health", s.getConnectionHealth)
+			connections.GET("/:id/lineage/graph", s.getLineageGraph)
+			connections.GET("/:id/schema.sql", s.getSchemaDDL)
+			connections.GET("/:id/trends", s.getTrends)
+			connections.GET("/:id/overview", s.getOverview)
+			connections.GET("/:id/analyze/stream", s.streamAnalysis)
+			connections.POST("/:id/analyze/cancel", s.cancelAnalysis)
+			connections.GET("/:id/collections/compare", s.compareCollections)
 			connections.DELETE("/:id", s.deleteConnection)
 		}
 
 		// @Analysis routes
-		analysis := api.Group("/analysis")
+		analysis := api.Group("/analysis", s.connectionAccess())
 		{
 			analysis.GET("/reports", s.getReports)
 			analysis.GET("/:id/report", s.getReport)
-			analysis.POST("/:id/analyze", s.analyzeDatabase)
+			analysis.POST("/:id/analyze", s.idempotency.Middleware(), s.analyzeDatabase)
+			analysis.GET("/:id/redundant-indexes", s.getRedundantIndexes)
 		}
 
+		api.POST("/analyze/bulk", s.idempotency.Middleware(), s.analyzeBulk)
+
 		// @Security routes
-		security := api.Group("/security")
+		security := api.Group("/security", s.connectionAccess())
 		{
 			security.GET("/:id/issues", s.getSecurityIssues)
 			security.POST("/:id/analyze", s.analyzeSecurity)
+			security.GET("/:id/access", s.getAccessControl)
 		}
 
 		// @Optimization routes
-		optimization := api.Group("/optimization")
+		optimization := api.Group("/optimization", s.connectionAccess())
 		{
 			optimization.GET("/:id/history", s.getOptimizationHistory)
 			optimization.POST("/:id/optimize", s.optimizeQuery)
+			optimization.POST("/:id/index-recommendations", s.recommendIndexes)
+			optimization.GET("/:id/queries", s.listSavedQueries)
+			optimization.POST("/:id/queries", s.createSavedQuery)
+			optimization.GET("/:id/queries/:queryId", s.getSavedQuery)
+			optimization.PUT("/:id/queries/:queryId", s.updateSavedQuery)
+			optimization.DELETE("/:id/queries/:queryId", s.deleteSavedQuery)
+			optimization.POST("/:id/queries/:queryId/optimize", s.optimizeSavedQuery)
 		}
 
 		// @Monitoring routes
@@ -96,37 +177,64 @@ func (s *Server) setupRoutes() {
 		{
 			monitoring.GET("/alerts", s.getAlerts)
 			monitoring.POST("/alerts/:id/acknowledge", s.acknowledgeAlert)
-			monitoring.GET("/:id/metrics", s.getMetrics)
+			monitoring.GET("/:id/metrics", s.connectionAccess(), s.getMetrics)
 		}
 
 		// @Lineage routes
-		lineage := api.Group("/lineage")
+		lineage := api.Group("/lineage", s.connectionAccess())
 		{
 			lineage.GET("/:id", s.getLineage)
 			lineage.POST("/:id/track", s.trackLineage)
 		}
 
 		// @Collection routes
-		collections := api.Group("/collections")
+		collections := api.Group("/collections", s.connectionAccess())
 		{
 			collections.GET("/:id/:collection/data", s.getCollectionData)
 			collections.GET("/:id/:collection/stats", s.getCollectionStats)
+			collections.GET("/:id/:collection/field-sizes", s.getCollectionFieldSizes)
 			collections.POST("/:id/:collection/search", s.searchCollection)
 		}
 
 		// @Load Balancer routes
-		loadBalancerService := loadbalancer.NewService(s.loadBalancer, s.urlAnalyzer)
-		loadBalancerHandler := loadbalancer.NewHandler(loadBalancerService)
-		loadbalancer.SetupRoutes(api, loadBalancerHandler)
+		loadBalancerService := loadbalancerapi.NewService(s.loadBalancer, s.urlAnalyzer)
+		loadBalancerHandler := loadbalancerapi.NewHandler(loadBalancerService)
+		loadBalancerHandler.SetWorkspaceFunc(workspaceID)
+		loadbalancerapi.SetupRoutes(api, loadBalancerHandler)
 
 		// @Analytics routes
-		analyticsService := analytics.NewAnalytics()
-		analyticsHandler := analytics.NewHandler(analyticsService.GetService())
-		analytics.SetupRoutes(api, analyticsHandler)
+		workspaces := analytics.NewWorkspaces(analytics.DefaultMaxWorkspaces)
+		if window := getAnalyticsRealTimeWindow(); window != 0 {
+			if err := workspaces.SetRealTimeWindow(window); err != nil {
+				logging.Default().Warn("Invalid analytics real-time window", "window", window, "error", err)
+			}
+		}
+		if activityLog := getAnalyticsActivityLog(); activityLog.Path != "" {
+			if err := workspaces.AddFileSink(activityLog); err != nil {
+				logging.Default().Warn("Analytics activity log disabled", "path", activityLog.Path, "error", err)
+			}
+		}
+		for _, id := range s.workspaces.IDs() {
+			if _, err := workspaces.Add(id); err != nil {
+				logging.Default().Warn("Analytics disabled for workspace", "workspace", id, "error", err)
+			}
+		}
+		analyticsHandler := analyticsapi.NewWorkspaceHandler(func(c *gin.Context) (analyticsapi.AnalyticsService, error) {
+			instance, err := workspaces.Get(workspaceID(c))
+			if err != nil {
+				return nil, err
+			}
+			return instance.GetService(), nil
+		})
+		analyticsapi.SetupRoutes(api, analyticsHandler, NewRateLimiter(s.rateLimit).Middleware())
 
 		// @Analyzer routes
 		analyzerService := analyzer.NewAnalyzer()
 		analyzerHandler := analyzer.NewHandler(analyzerService.GetService())
+		analyzerHandler.SetWorkspaceFunc(workspaceID)
+		if s.productionPolicy.RequireConfirmation {
+			analyzerHandler.SetSafeMode(&analyzer.SafeModePolicy{IsProduction: s.productionPolicy.isProduction})
+		}
 		analyzer.SetupRoutes(api, analyzerHandler)
 	}
 
@@ -142,10 +250,12 @@ func (s *Server) Run() error {
 }
 
 type APIResponse struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Message string      `json:"message,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Success bool            `json:"success"`
+	Data    interface{}     `json:"data,omitempty"`
+	Message string          `json:"message,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	Code    types.ErrorCode `json:"code,omitempty"`
+	Details interface{}     `json:"details,omitempty"`
 }
 
 func (s *Server) sendSuccess(c *gin.Context, data interface{}, message ...string) {
@@ -156,13 +266,16 @@ func (s *Server) sendSuccess(c *gin.Context, data interface{}, message ...string
 	if len(message) > 0 {
 		response.Message = message[0]
 	}
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, utils.WithEmptySlices(response))
 }
 
 func (s *Server) sendError(c *gin.Context, statusCode int, err error, message ...string) {
+	apiErr, statusCode := types.ToAPIError(err, statusCode, types.ErrCodeDatabaseError)
 	response := APIResponse{
 		Success: false,
-		Error:   err.Error(),
+		Error:   apiErr.Message,
+		Code:    apiErr.Code,
+		Details: apiErr.Details,
 	}
 	if len(message) > 0 {
 		response.Message = message[0]
@@ -189,7 +302,7 @@ func getCORSMethods() []string {
 func getCORSHeaders() []string {
 	headers := os.Getenv("CORS_ALLOWED_HEADERS")
 	if headers == "" {
-		return []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With"}
+		return []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", IdempotencyKeyHeader, WorkspaceHeader}
 	}
 	return strings.Split(headers, ",")
 }
@@ -197,7 +310,7 @@ func getCORSHeaders() []string {
 func getCORSExposeHeaders() []string {
 	headers := os.Getenv("CORS_EXPOSE_HEADERS")
 	if headers == "" {
-		return []string{"Content-Length", "Content-Type"}
+		return []string{"Content-Length", "Content-Type", IdempotencyReplayedHeader}
 	}
 	return strings.Split(headers, ",")
 }
@@ -219,3 +332,143 @@ func getCORSMaxAge() time.Duration {
 	
 	return 12 * time.Hour
 }
+
+func getIdempotencyTTL() time.Duration {
+	if duration, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_TTL")); err == nil {
+		return duration
+	}
+	return DefaultIdempotencyTTL
+}
+
+func getConnectTimeout() time.Duration {
+	if duration, err := time.ParseDuration(os.Getenv("DB_CONNECT_TIMEOUT")); err == nil && duration > 0 {
+		return duration
+	}
+	return connector.DefaultConnectTimeout
+}
+
+func getWorkspaceRequired() bool {
+	if required, err := strconv.ParseBool(os.Getenv("REQUIRE_WORKSPACE_ID")); err == nil {
+		return required
+	}
+	return true
+}
+
+// getWorkspaceAccess reads WORKSPACES, a comma-separated list of workspace IDs, each
+// optionally followed by =token. Without it only the default workspace is served.
+func getWorkspaceAccess() WorkspaceAccess {
+	access := WorkspaceAccess{}
+	for _, entry := range strings.Split(os.Getenv("WORKSPACES"), ",") {
+		id, token, _ := strings.Cut(strings.TrimSpace(entry), "=")
+		if id == "" {
+			continue
+		}
+		if !workspaceIDPattern.MatchString(id) {
+			logging.Default().Warn("Ignoring invalid workspace ID in WORKSPACES", "workspace", id)
+			continue
+		}
+		access[id] = token
+	}
+	if len(access) == 0 {
+		access[DefaultWorkspaceID] = ""
+	}
+	return access
+}
+
+func getDataBrowsingDisabled() bool {
+	disabled, _ := strconv.ParseBool(os.Getenv("DISABLE_DATA_BROWSING"))
+	return disabled
+}
+
+func getProductionPolicy() ProductionPolicy {
+	policy := ProductionPolicy{RequireConfirmation: true, Tags: []string{DefaultProductionTag}}
+	if required, err := strconv.ParseBool(os.Getenv("REQUIRE_PROD_CONFIRMATION")); err == nil {
+		policy.RequireConfirmation = required
+	}
+	if tags := os.Getenv("PROD_CONNECTION_TAGS"); tags != "" {
+		policy.Tags = strings.Split(tags, ",")
+	}
+	return policy
+}
+
+func getStatsCacheTTL() time.Duration {
+	if duration, err := time.ParseDuration(os.Getenv("STATS_CACHE_TTL")); err == nil {
+		return duration
+	}
+	return utils.DefaultCacheTTL
+}
+
+func getAnalyticsRateLimit() RateLimitConfig {
+	config := DefaultRateLimitConfig()
+	if rps, err := strconv.ParseFloat(os.Getenv("ANALYTICS_RATE_LIMIT_RPS"), 64); err == nil {
+		config.RequestsPerSecond = rps
+	}
+	if burst, err := strconv.Atoi(os.Getenv("ANALYTICS_RATE_LIMIT_BURST")); err == nil {
+		config.Burst = burst
+	}
+	if perSession, err := strconv.ParseBool(os.Getenv("ANALYTICS_RATE_LIMIT_PER_SESSION")); err == nil {
+		config.PerSession = perSession
+	}
+	return config
+}
+
+func getAnalyticsActivityLog() analyticscore.FileSinkConfig {
+	config := analyticscore.FileSinkConfig{Path: os.Getenv("ANALYTICS_ACTIVITY_LOG")}
+	if sizeMB, err := strconv.ParseInt(os.Getenv("ANALYTICS_ACTIVITY_LOG_MAX_SIZE_MB"), 10, 64); err == nil {
+		config.MaxSizeBytes = sizeMB * 1024 * 1024
+	}
+	if maxAge, err := time.ParseDuration(os.Getenv("ANALYTICS_ACTIVITY_LOG_MAX_AGE")); err == nil {
+		config.MaxAge = maxAge
+	}
+	if backups, err := strconv.Atoi(os.Getenv("ANALYTICS_ACTIVITY_LOG_MAX_BACKUPS")); err == nil {
+		config.MaxBackups = backups
+	}
+	return config
+}
+
+// getRealTimeAuth falls back to the CORS origins when REALTIME_ALLOWED_ORIGINS is unset.
+// REALTIME_AUTH_TOKENS is a comma-separated list; leaving it unset requires no token,
+// which is logged at startup since the socket is then open to any allowed origin.
+func getRealTimeAuth(corsOrigins []string) RealTimeAuthConfig {
+	config := RealTimeAuthConfig{AllowedOrigins: corsOrigins}
+	if origins := os.Getenv("REALTIME_ALLOWED_ORIGINS"); origins != "" {
+		config.AllowedOrigins = strings.Split(origins, ",")
+	}
+	if tokens := os.Getenv("REALTIME_AUTH_TOKENS"); tokens != "" {
+		config.Tokens = strings.Split(tokens, ",")
+	} else {
+		logging.Default().Warn("REALTIME_AUTH_TOKENS is not set; the real-time analytics socket accepts connections without a token",
+			"allowed_origins", config.AllowedOrigins)
+	}
+	return config
+}
+
+// getAnalyticsRealTimeWindow returns 0 when ANALYTICS_REALTIME_WINDOW is unset, leaving
+// the analytics default in place.
+func getAnalyticsRealTimeWindow() time.Duration {
+	if window, err := time.ParseDuration(os.Getenv("ANALYTICS_REALTIME_WINDOW")); err == nil {
+		return window
+	}
+	return 0
+}
+
+func getReportHistorySize() int {
+	if size, err := strconv.Atoi(os.Getenv("REPORT_HISTORY_SIZE")); err == nil {
+		return size
+	}
+	return insights.DefaultReportHistorySize
+}
+
+func getMaxConcurrentLoadTests() int {
+	if limit, err := strconv.Atoi(os.Getenv("LOADTEST_MAX_CONCURRENT")); err == nil {
+		return limit
+	}
+	return loadbalancer.DefaultMaxConcurrentTests
+}
+
+func getStatsCacheSize() int {
+	if size, err := strconv.Atoi(os.Getenv("STATS_CACHE_SIZE")); err == nil {
+		return size
+	}
+	return utils.DefaultCacheEntries
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"regexp"
+	"sort"
+
+	"github.com/cherry-pick/pkg/types"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	WorkspaceHeader      = "X-Workspace-ID"
+	WorkspaceTokenHeader = "X-Workspace-Token"
+	DefaultWorkspaceID   = "default"
+
+	workspaceContextKey = "workspaceID"
+)
+
+var workspaceIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// WorkspaceAccess lists the workspaces the API serves, each mapped to the token requests
+// must send in X-Workspace-Token, or to "" when it needs none. Requests naming any other
+// workspace are rejected, which also bounds how many workspaces can hold data.
+type WorkspaceAccess map[string]string
+
+// IDs lists the configured workspaces in name order.
+func (access WorkspaceAccess) IDs() []string {
+	ids := make([]string, 0, len(access))
+	for id := range access {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// WithWorkspaceRequired controls whether API requests must carry X-Workspace-ID. It is
+// required by default; when it isn't, requests without one fall into the default workspace.
+func WithWorkspaceRequired(required bool) ServerOption {
+	return func(s *Server) {
+		s.requireWorkspace = required
+	}
+}
+
+// WithWorkspaces replaces the workspaces read from WORKSPACES.
+func WithWorkspaces(access WorkspaceAccess) ServerOption {
+	return func(s *Server) {
+		s.workspaces = access
+	}
+}
+
+// workspaceMiddleware resolves the workspace a request acts in and checks it against the
+// configured workspaces. Connections, reports, load tests and analytics data are only
+// visible within the workspace that created them.
+func (s *Server) workspaceMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workspace := c.GetHeader(WorkspaceHeader)
+		if workspace == "" {
+			if s.requireWorkspace {
+				c.AbortWithStatusJSON(http.StatusBadRequest, APIResponse{
+					Success: false,
+					Error:   WorkspaceHeader + " header is required",
+					Code:    types.ErrCodeInvalidRequest,
+				})
+				return
+			}
+			workspace = DefaultWorkspaceID
+		}
+
+		if !workspaceIDPattern.MatchString(workspace) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   "Workspace ID must be 1-64 letters, digits, '-' or '_'",
+				Code:    types.ErrCodeInvalidRequest,
+			})
+			return
+		}
+
+		token, known := s.workspaces[workspace]
+		if !known {
+			c.AbortWithStatusJSON(http.StatusForbidden, APIResponse{
+				Success: false,
+				Error:   "Unknown workspace",
+				Code:    types.ErrCodeForbidden,
+			})
+			return
+		}
+		if token != "" && subtle.ConstantTimeCompare([]byte(c.GetHeader(WorkspaceTokenHeader)), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, APIResponse{
+				Success: false,
+				Error:   "A valid " + WorkspaceTokenHeader + " header is required for this workspace",
+				Code:    types.ErrCodeUnauthorized,
+			})
+			return
+		}
+
+		c.Set(workspaceContextKey, workspace)
+		c.Next()
+	}
+}
+
+func workspaceID(c *gin.Context) string {
+	if workspace := c.GetString(workspaceContextKey); workspace != "" {
+		return workspace
+	}
+	return DefaultWorkspaceID
+}
+
+// connectionAccess answers 404 for a connection :id that does not exist or belongs to
+// another workspace, so the two cases cannot be told apart. Routes without :id pass.
+func (s *Server) connectionAccess() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if id == "" {
+			c.Next()
+			return
+		}
+
+		if !ownsConnection(workspaceID(c), id) {
+			s.sendError(c, http.StatusNotFound,
+				types.NewAPIError(types.ErrCodeConnectionNotFound, "Connection not found"), "Connection not found")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func ownsConnection(workspace, id string) bool {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	connection, exists := connections[id]
+	return exists && connection.WorkspaceID == workspace
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newWorkspaceServer(t *testing.T) *Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	mutex.Lock()
+	connections["conn-a"] = &ConnectionInfo{ID: "conn-a", Name: "orders", Driver: "postgres", WorkspaceID: "workspace-a"}
+	mutex.Unlock()
+	t.Cleanup(func() {
+		mutex.Lock()
+		delete(connections, "conn-a")
+		mutex.Unlock()
+	})
+
+	return NewServer("0", WithWorkspaces(WorkspaceAccess{"workspace-a": "", "workspace-b": ""}))
+}
+
+func serveWorkspace(s *Server, method, path, workspace string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set(WorkspaceHeader, workspace)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestWorkspaceCannotReachAnotherWorkspacesConnection(t *testing.T) {
+	s := newWorkspaceServer(t)
+
+	requests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/api/connections/conn-a/health"},
+		{http.MethodDelete, "/api/connections/conn-a"},
+		{http.MethodGet, "/api/analysis/conn-a/report"},
+		{http.MethodGet, "/api/security/conn-a/issues"},
+		{http.MethodGet, "/api/monitoring/alerts?connectionId=conn-a"},
+		{http.MethodPost, "/api/monitoring/alerts/alert-1/acknowledge?connectionId=conn-a"},
+	}
+
+	for _, r := range requests {
+		w := serveWorkspace(s, r.method, r.path, "workspace-b")
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for %s %s from another workspace, got %d", r.method, r.path, w.Code)
+		}
+	}
+
+	w := serveWorkspace(s, http.MethodGet, "/api/connections", "workspace-b")
+	var listed struct {
+		Data []ConnectionInfo `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("Expected a JSON response, got %v", err)
+	}
+	if len(listed.Data) != 0 {
+		t.Errorf("Expected no connections listed for another workspace, got %d", len(listed.Data))
+	}
+}
+
+func TestUnknownWorkspaceIsForbidden(t *testing.T) {
+	s := newWorkspaceServer(t)
+
+	w := serveWorkspace(s, http.MethodGet, "/api/connections/conn-a/health", "workspace-c")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
+func TestAcknowledgeAlertRequiresConnection(t *testing.T) {
+	s := newWorkspaceServer(t)
+
+	w := serveWorkspace(s, http.MethodPost, "/api/monitoring/alerts/alert-1/acknowledge", "workspace-a")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without connectionId, got %d", w.Code)
+	}
+
+	w = serveWorkspace(s, http.MethodPost, "/api/monitoring/alerts/alert-1/acknowledge?connectionId=conn-a", "workspace-a")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an alert that is not active, got %d", w.Code)
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"regexp"
 
 	"github.com/cherry-pick/pkg/interfaces"
+	"github.com/cherry-pick/pkg/monitoring"
 	"github.com/cherry-pick/pkg/types"
 )
 
@@ -30,8 +32,10 @@ func (cm *ConfigManagerImpl) LoadConfig(configPath string) error {
 			EnableAlerts: true,
 		},
 		SecuritySettings: types.SecuritySettings{
-			EnablePIIDetection: true,
-			PIIPatterns:        []string{"email", "phone", "ssn", "address"},
+			EnablePIIDetection:  true,
+			PIIPatterns:         []string{"email", "phone", "ssn", "address"},
+			MaxSecurityScanRows: types.DefaultMaxSecurityScanRows,
+			PIIMinConfidence:    types.DefaultPIIMinConfidence,
 		},
 	}
 
@@ -88,6 +92,27 @@ func (cm *ConfigManagerImpl) ValidateConfig(config *types.Config) error {
 		return fmt.Errorf("large table threshold cannot be negative")
 	}
 
+	if config.SecuritySettings.MaxSecurityScanRows < 0 {
+		return fmt.Errorf("max security scan rows cannot be negative")
+	}
+
+	if config.SecuritySettings.PIIMinConfidence < 0 || config.SecuritySettings.PIIMinConfidence > 1 {
+		return fmt.Errorf("pii min confidence must be between 0 and 1")
+	}
+
+	for _, namePattern := range config.SecuritySettings.PIINamePatterns {
+		if _, err := regexp.Compile(namePattern.Pattern); err != nil {
+			return fmt.Errorf("invalid pii name pattern %q: %w", namePattern.Pattern, err)
+		}
+		if namePattern.Category == "" {
+			return fmt.Errorf("pii name pattern %q needs a category", namePattern.Pattern)
+		}
+	}
+
+	if _, err := monitoring.NewAlertRuleSet(config.AlertSettings.Rules); err != nil {
+		return fmt.Errorf("invalid alert rules: %w", err)
+	}
+
 	if config.AnalysisSettings.QualityScoreMinimum < 0 || config.AnalysisSettings.QualityScoreMinimum > 1 {
 		return fmt.Errorf("quality score minimum must be between 0 and 1")
 	}
@@ -138,4 +163,10 @@ func (cm *ConfigManagerImpl) mergeWithDefaults(fileConfig *types.Config) {
 	if len(fileConfig.SecuritySettings.PIIPatterns) == 0 {
 		fileConfig.SecuritySettings.PIIPatterns = []string{"email", "phone", "ssn", "address"}
 	}
+	if fileConfig.SecuritySettings.MaxSecurityScanRows == 0 {
+		fileConfig.SecuritySettings.MaxSecurityScanRows = types.DefaultMaxSecurityScanRows
+	}
+	if fileConfig.SecuritySettings.PIIMinConfidence == 0 {
+		fileConfig.SecuritySettings.PIIMinConfidence = types.DefaultPIIMinConfidence
+	}
 }
@@ -0,0 +1,200 @@
+package connector
+
+import (
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/cherry-pick/pkg/interfaces"
+)
+
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+const (
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open: database is unavailable")
+
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	threshold int
+	cooldown  time.Duration
+	openedAt  time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		threshold = DefaultBreakerThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultBreakerCooldown
+	}
+
+	return &CircuitBreaker{
+		state:     BreakerClosed,
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+func (cb *CircuitBreaker) Execute(fn func() error) error {
+	if err := cb.allow(); err != nil {
+		return err
+	}
+
+	err := fn()
+	cb.record(err)
+	return err
+}
+
+func (cb *CircuitBreaker) State() BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == BreakerOpen && cb.now().Sub(cb.openedAt) >= cb.cooldown {
+		return BreakerHalfOpen
+	}
+	return cb.state
+}
+
+func (cb *CircuitBreaker) Failures() int {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.failures
+}
+
+// check reports ErrCircuitOpen while the circuit is open, without starting a probe.
+func (cb *CircuitBreaker) check() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == BreakerOpen && cb.now().Sub(cb.openedAt) < cb.cooldown {
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+func (cb *CircuitBreaker) allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case BreakerOpen:
+		if cb.now().Sub(cb.openedAt) < cb.cooldown {
+			return ErrCircuitOpen
+		}
+		cb.state = BreakerHalfOpen
+		cb.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if cb.probing {
+			return ErrCircuitOpen
+		}
+		cb.probing = true
+		return nil
+	}
+
+	return nil
+}
+
+func (cb *CircuitBreaker) record(err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.probing = false
+
+	if err == nil {
+		cb.state = BreakerClosed
+		cb.failures = 0
+		return
+	}
+
+	cb.failures++
+	if cb.state == BreakerHalfOpen || cb.failures >= cb.threshold {
+		cb.state = BreakerOpen
+		cb.openedAt = cb.now()
+	}
+}
+
+type BreakerConnector struct {
+	interfaces.DatabaseConnector
+	breaker *CircuitBreaker
+	// observed is set when the breaker watches every connection of the pool GetDB returns.
+	observed bool
+}
+
+// NewBreakerConnector puts connector behind breaker. Callers such as the analyzer query
+// GetDB() directly, so for a DatabaseConnectorImpl not yet connected the breaker also
+// watches each driver call on its pool; other connectors only have the calls made
+// through the BreakerConnector counted.
+func NewBreakerConnector(connector interfaces.DatabaseConnector, breaker *CircuitBreaker) *BreakerConnector {
+	if breaker == nil {
+		breaker = NewCircuitBreaker(DefaultBreakerThreshold, DefaultBreakerCooldown)
+	}
+
+	bc := &BreakerConnector{
+		DatabaseConnector: connector,
+		breaker:           breaker,
+	}
+	if impl, ok := connector.(*DatabaseConnectorImpl); ok && impl.db == nil {
+		impl.wrap = func(c driver.Connector) driver.Connector {
+			return &breakerDriverConnector{Connector: c, breaker: breaker}
+		}
+		bc.observed = true
+	}
+	return bc
+}
+
+func (bc *BreakerConnector) Connect() error {
+	return bc.run(bc.DatabaseConnector.Connect)
+}
+
+func (bc *BreakerConnector) Ping() error {
+	return bc.run(bc.DatabaseConnector.Ping)
+}
+
+func (bc *BreakerConnector) GetDatabaseName() (string, error) {
+	var name string
+	err := bc.run(func() error {
+		var err error
+		name, err = bc.DatabaseConnector.GetDatabaseName()
+		return err
+	})
+	return name, err
+}
+
+// Execute runs fn, typically a call that queries GetDB(), unless the circuit is open.
+func (bc *BreakerConnector) Execute(fn func() error) error {
+	return bc.run(fn)
+}
+
+func (bc *BreakerConnector) Breaker() *CircuitBreaker {
+	return bc.breaker
+}
+
+// run counts fn's result itself only when the pool is not observed. Otherwise each query
+// fn makes is already counted, and counting fn as a whole would count a failure twice or
+// let a call that tolerates some failing queries reset the count.
+func (bc *BreakerConnector) run(fn func() error) error {
+	if !bc.observed {
+		return bc.breaker.Execute(fn)
+	}
+	if err := bc.breaker.check(); err != nil {
+		return err
+	}
+	return fn()
+}
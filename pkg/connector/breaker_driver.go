@@ -0,0 +1,261 @@
+package connector
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// observe runs fn through the breaker like Execute, for a single driver call. An error
+// the database itself returned, such as a syntax or permission error, still shows it is
+// reachable and counts as a success, as does a call the caller cancelled.
+func (cb *CircuitBreaker) observe(fn func() error) error {
+	if err := cb.allow(); err != nil {
+		return err
+	}
+
+	err := fn()
+	switch {
+	case errors.Is(err, driver.ErrSkip):
+		cb.abandon()
+	case databaseAnswered(err):
+		cb.record(nil)
+	default:
+		cb.record(err)
+	}
+	return err
+}
+
+// abandon ends a probe without a result, for a call the driver declined to handle.
+func (cb *CircuitBreaker) abandon() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.probing = false
+}
+
+func databaseAnswered(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	var mysqlErr *mysql.MySQLError
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &pqErr) || errors.As(err, &mysqlErr) || errors.As(err, &sqliteErr)
+}
+
+// openDriverConnector returns the driver.Connector sql.Open would use for dataSourceName.
+func openDriverConnector(driverName, dataSourceName string) (driver.Connector, error) {
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	drv := db.Driver()
+	db.Close()
+
+	if driverContext, ok := drv.(driver.DriverContext); ok {
+		return driverContext.OpenConnector(dataSourceName)
+	}
+	return dsnConnector{dsn: dataSourceName, driver: drv}, nil
+}
+
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// breakerDriverConnector opens connections whose every call goes through the breaker,
+// so a query failing anywhere on the pool is counted, not just the calls made through
+// BreakerConnector itself.
+type breakerDriverConnector struct {
+	driver.Connector
+	breaker *CircuitBreaker
+}
+
+func (c *breakerDriverConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	var conn driver.Conn
+	err := c.breaker.observe(func() error {
+		var err error
+		conn, err = c.Connector.Connect(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &breakerConn{Conn: conn, breaker: c.breaker}, nil
+}
+
+type breakerConn struct {
+	driver.Conn
+	breaker *CircuitBreaker
+}
+
+func (c *breakerConn) Ping(ctx context.Context) error {
+	pinger, ok := c.Conn.(driver.Pinger)
+	if !ok {
+		return nil
+	}
+	return c.breaker.observe(func() error {
+		return pinger.Ping(ctx)
+	})
+}
+
+func (c *breakerConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	var rows driver.Rows
+	err := c.breaker.observe(func() error {
+		var err error
+		rows, err = queryer.QueryContext(ctx, query, args)
+		return err
+	})
+	return rows, err
+}
+
+func (c *breakerConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	var result driver.Result
+	err := c.breaker.observe(func() error {
+		var err error
+		result, err = execer.ExecContext(ctx, query, args)
+		return err
+	})
+	return result, err
+}
+
+func (c *breakerConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	err := c.breaker.observe(func() error {
+		var err error
+		if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+			stmt, err = preparer.PrepareContext(ctx, query)
+		} else {
+			stmt, err = c.Conn.Prepare(query)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &breakerStmt{Stmt: stmt, conn: c}, nil
+}
+
+func (c *breakerConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var tx driver.Tx
+	err := c.breaker.observe(func() error {
+		var err error
+		if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+			tx, err = beginner.BeginTx(ctx, opts)
+			return err
+		}
+		if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) || opts.ReadOnly {
+			return errors.New("driver does not support non-default transaction options")
+		}
+		tx, err = c.Conn.Begin()
+		return err
+	})
+	return tx, err
+}
+
+func (c *breakerConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+func (c *breakerConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *breakerConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+type breakerStmt struct {
+	driver.Stmt
+	conn *breakerConn
+}
+
+func (s *breakerStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	var rows driver.Rows
+	err := s.conn.breaker.observe(func() error {
+		var err error
+		if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+			rows, err = queryer.QueryContext(ctx, args)
+			return err
+		}
+		values, err := namedValuesToValues(args)
+		if err != nil {
+			return err
+		}
+		rows, err = s.Stmt.Query(values)
+		return err
+	})
+	return rows, err
+}
+
+func (s *breakerStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	var result driver.Result
+	err := s.conn.breaker.observe(func() error {
+		var err error
+		if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+			result, err = execer.ExecContext(ctx, args)
+			return err
+		}
+		values, err := namedValuesToValues(args)
+		if err != nil {
+			return err
+		}
+		result, err = s.Stmt.Exec(values)
+		return err
+	})
+	return result, err
+}
+
+// CheckNamedValue defers to the statement's own checker, then the connection's, since
+// database/sql consults only the statement once it implements one.
+func (s *breakerStmt) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return s.conn.CheckNamedValue(value)
+}
+
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("driver does not support named parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
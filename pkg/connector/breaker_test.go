@@ -0,0 +1,437 @@
+package connector
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+var errUnreachable = errors.New("dial tcp: connection refused")
+
+// mockConnector is a DatabaseConnector whose calls fail while failing is set.
+type mockConnector struct {
+	mu      sync.Mutex
+	failing bool
+	pings   int
+}
+
+func (m *mockConnector) setFailing(failing bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failing = failing
+}
+
+func (m *mockConnector) result() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pings++
+	if m.failing {
+		return errUnreachable
+	}
+	return nil
+}
+
+func (m *mockConnector) Connect() error                   { return m.result() }
+func (m *mockConnector) Close() error                     { return nil }
+func (m *mockConnector) Ping() error                      { return m.result() }
+func (m *mockConnector) GetDB() *sql.DB                   { return nil }
+func (m *mockConnector) GetDatabaseName() (string, error) { return "app", m.result() }
+func (m *mockConnector) GetDatabaseType() string          { return "mock" }
+
+// fakeClock drives a breaker's cooldown without sleeping.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestBreaker(threshold int, cooldown time.Duration) (*CircuitBreaker, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	breaker := NewCircuitBreaker(threshold, cooldown)
+	breaker.now = clock.Now
+	return breaker, clock
+}
+
+func TestNewCircuitBreakerDefaults(t *testing.T) {
+	breaker := NewCircuitBreaker(0, 0)
+	if breaker.threshold != DefaultBreakerThreshold {
+		t.Errorf("Expected threshold %d, got %d", DefaultBreakerThreshold, breaker.threshold)
+	}
+	if breaker.cooldown != DefaultBreakerCooldown {
+		t.Errorf("Expected cooldown %s, got %s", DefaultBreakerCooldown, breaker.cooldown)
+	}
+	if state := breaker.State(); state != BreakerClosed {
+		t.Errorf("Expected %s, got %s", BreakerClosed, state)
+	}
+}
+
+func TestBreakerConnectorStaysClosedBelowThreshold(t *testing.T) {
+	mock := &mockConnector{failing: true}
+	breaker, _ := newTestBreaker(3, time.Minute)
+	bc := NewBreakerConnector(mock, breaker)
+
+	for i := 0; i < 2; i++ {
+		if err := bc.Ping(); !errors.Is(err, errUnreachable) {
+			t.Fatalf("Expected the connector's error, got %v", err)
+		}
+	}
+	if state := breaker.State(); state != BreakerClosed {
+		t.Errorf("Expected %s, got %s", BreakerClosed, state)
+	}
+	if failures := breaker.Failures(); failures != 2 {
+		t.Errorf("Expected 2 failures, got %d", failures)
+	}
+
+	mock.setFailing(false)
+	if err := bc.Ping(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if failures := breaker.Failures(); failures != 0 {
+		t.Errorf("Expected a success to reset the failures, got %d", failures)
+	}
+}
+
+func TestBreakerConnectorOpensAtThreshold(t *testing.T) {
+	mock := &mockConnector{failing: true}
+	breaker, _ := newTestBreaker(3, time.Minute)
+	bc := NewBreakerConnector(mock, breaker)
+
+	for i := 0; i < 3; i++ {
+		bc.Ping()
+	}
+	if state := breaker.State(); state != BreakerOpen {
+		t.Fatalf("Expected %s, got %s", BreakerOpen, state)
+	}
+
+	pings := mock.pings
+	if _, err := bc.GetDatabaseName(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if err := bc.Execute(func() error { return nil }); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if mock.pings != pings {
+		t.Errorf("Expected no calls to reach the connector while open, got %d", mock.pings-pings)
+	}
+}
+
+func TestBreakerConnectorHalfOpen(t *testing.T) {
+	mock := &mockConnector{failing: true}
+	breaker, clock := newTestBreaker(1, time.Minute)
+	bc := NewBreakerConnector(mock, breaker)
+
+	bc.Ping()
+	clock.Advance(time.Minute)
+	if state := breaker.State(); state != BreakerHalfOpen {
+		t.Fatalf("Expected %s, got %s", BreakerHalfOpen, state)
+	}
+
+	// A failed probe opens the circuit again for another cooldown.
+	if err := bc.Ping(); !errors.Is(err, errUnreachable) {
+		t.Fatalf("Expected the probe to reach the connector, got %v", err)
+	}
+	if state := breaker.State(); state != BreakerOpen {
+		t.Fatalf("Expected %s, got %s", BreakerOpen, state)
+	}
+
+	// A successful probe closes it.
+	clock.Advance(time.Minute)
+	mock.setFailing(false)
+	if err := bc.Ping(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if state := breaker.State(); state != BreakerClosed {
+		t.Errorf("Expected %s, got %s", BreakerClosed, state)
+	}
+}
+
+func TestCircuitBreakerAllowsOneProbe(t *testing.T) {
+	breaker, clock := newTestBreaker(1, time.Minute)
+	breaker.Execute(func() error { return errUnreachable })
+	clock.Advance(time.Minute)
+
+	probing := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- breaker.Execute(func() error {
+			close(probing)
+			<-release
+			return nil
+		})
+	}()
+
+	<-probing
+	if err := breaker.Execute(func() error { return nil }); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected a second call during the probe to be rejected, got %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if state := breaker.State(); state != BreakerClosed {
+		t.Errorf("Expected %s, got %s", BreakerClosed, state)
+	}
+}
+
+// The driver below stands in for a database the pool can lose contact with, so the
+// breaker can be tested against queries run straight on GetDB().
+const breakerTestDriver = "breaker-test"
+
+var breakerTestServer = &fakeServer{}
+
+func init() {
+	sql.Register(breakerTestDriver, fakeDriver{server: breakerTestServer})
+}
+
+type fakeServer struct {
+	mu          sync.Mutex
+	unreachable bool
+	queries     int
+}
+
+func (s *fakeServer) setUnreachable(unreachable bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unreachable = unreachable
+}
+
+func (s *fakeServer) query(query string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries++
+	if s.unreachable {
+		return errUnreachable
+	}
+	return nil
+}
+
+func (s *fakeServer) queryCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queries
+}
+
+type fakeDriver struct {
+	server *fakeServer
+}
+
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	if err := d.server.query(""); err != nil {
+		return nil, err
+	}
+	return &fakeConn{server: d.server}, nil
+}
+
+type fakeConn struct {
+	server *fakeServer
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *fakeConn) Ping(context.Context) error {
+	return c.server.query("")
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := c.server.query(query); err != nil {
+		return nil, err
+	}
+	return &fakeRows{}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.server.query(query); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(0), nil
+}
+
+type fakeRows struct {
+	done bool
+}
+
+func (r *fakeRows) Columns() []string { return []string{"value"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+func newObservedConnector(t *testing.T, threshold int) (*BreakerConnector, *fakeClock) {
+	t.Helper()
+	breakerTestServer.setUnreachable(false)
+	breaker, clock := newTestBreaker(threshold, time.Minute)
+	bc := NewBreakerConnector(NewDatabaseConnector(breakerTestDriver, "fake"), breaker)
+	if err := bc.Connect(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(func() { bc.Close() })
+	return bc, clock
+}
+
+func TestBreakerCountsQueriesOnGetDB(t *testing.T) {
+	bc, _ := newObservedConnector(t, 3)
+	db := bc.GetDB()
+
+	var value int
+	if err := db.QueryRow("SELECT 1").Scan(&value); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	breakerTestServer.setUnreachable(true)
+	for i := 0; i < 3; i++ {
+		if err := db.QueryRow("SELECT 1").Scan(&value); err == nil {
+			t.Fatalf("Expected the query to fail")
+		}
+	}
+	if state := bc.Breaker().State(); state != BreakerOpen {
+		t.Fatalf("Expected failing queries on GetDB() to open the circuit, got %s", state)
+	}
+
+	queries := breakerTestServer.queryCount()
+	if err := db.QueryRow("SELECT 1").Scan(&value); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if got := breakerTestServer.queryCount(); got != queries {
+		t.Errorf("Expected no queries to reach the database while open, got %d", got-queries)
+	}
+}
+
+func TestBreakerExecuteDoesNotHideFailedQueries(t *testing.T) {
+	bc, _ := newObservedConnector(t, 3)
+	db := bc.GetDB()
+
+	breakerTestServer.setUnreachable(true)
+	// Like AnalyzeTables, the call tolerates failing queries and returns no error.
+	err := bc.Execute(func() error {
+		for i := 0; i < 2; i++ {
+			db.Exec("SELECT 1")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if failures := bc.Breaker().Failures(); failures < 2 {
+		t.Errorf("Expected the failed queries to be counted, got %d failures", failures)
+	}
+}
+
+func TestDatabaseAnswered(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"success", nil, true},
+		{"cancelled", context.Canceled, true},
+		{"postgres error", fmt.Errorf("query: %w", &pq.Error{Message: "syntax error"}), true},
+		{"mysql error", &mysql.MySQLError{Number: 1064}, true},
+		{"sqlite error", sqlite3.Error{Code: sqlite3.ErrError}, true},
+		{"connection refused", errUnreachable, false},
+		{"timeout", context.DeadlineExceeded, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := databaseAnswered(tt.err); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBreakerHalfOpenOnGetDB(t *testing.T) {
+	bc, clock := newObservedConnector(t, 1)
+	db := bc.GetDB()
+
+	breakerTestServer.setUnreachable(true)
+	db.Exec("SELECT 1")
+	if state := bc.Breaker().State(); state != BreakerOpen {
+		t.Fatalf("Expected %s, got %s", BreakerOpen, state)
+	}
+
+	clock.Advance(time.Minute)
+	if state := bc.Breaker().State(); state != BreakerHalfOpen {
+		t.Fatalf("Expected %s, got %s", BreakerHalfOpen, state)
+	}
+
+	breakerTestServer.setUnreachable(false)
+	if err := bc.Ping(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if state := bc.Breaker().State(); state != BreakerClosed {
+		t.Errorf("Expected %s, got %s", BreakerClosed, state)
+	}
+}
+
+func TestBreakerConnectorOnSQLite(t *testing.T) {
+	breaker, _ := newTestBreaker(1, time.Minute)
+	bc := NewBreakerConnector(NewDatabaseConnector("sqlite3", filepath.Join(t.TempDir(), "app.db")), breaker)
+	if err := bc.Connect(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer bc.Close()
+	db := bc.GetDB()
+
+	if _, err := db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stmt, err := tx.Prepare("INSERT INTO users (name) VALUES (?)")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, name := range []string{"ada", "grace"} {
+		if _, err := stmt.Exec(name); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	stmt.Close()
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM users WHERE name <> ?", "").Scan(&count); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 rows, got %d", count)
+	}
+
+	// The database answered, so a bad query must not open the circuit.
+	if _, err := db.Query("SELECT * FROM missing"); err == nil {
+		t.Fatalf("Expected an error for a missing table")
+	}
+	if state := breaker.State(); state != BreakerClosed {
+		t.Errorf("Expected %s, got %s", BreakerClosed, state)
+	}
+
+	if name, err := bc.GetDatabaseName(); err != nil || name != "SQLite Database" {
+		t.Errorf("Expected SQLite Database, got %q, %v", name, err)
+	}
+}
@@ -1,8 +1,11 @@
 package connector
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"time"
 
 	"github.com/cherry-pick/pkg/interfaces"
 
@@ -11,26 +14,49 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// DefaultConnectTimeout bounds how long Connect waits for the database to answer.
+const DefaultConnectTimeout = 10 * time.Second
+
 type DatabaseConnectorImpl struct {
 	db             *sql.DB
 	driverName     string
 	dataSourceName string
+	connectTimeout time.Duration
+	// wrap, when set, wraps the driver connector every pooled connection is opened with.
+	wrap func(driver.Connector) driver.Connector
 }
 
 func NewDatabaseConnector(driverName, dataSourceName string) interfaces.DatabaseConnector {
+	return NewDatabaseConnectorWithTimeout(driverName, dataSourceName, DefaultConnectTimeout)
+}
+
+func NewDatabaseConnectorWithTimeout(driverName, dataSourceName string, connectTimeout time.Duration) interfaces.DatabaseConnector {
+	if connectTimeout <= 0 {
+		connectTimeout = DefaultConnectTimeout
+	}
 	return &DatabaseConnectorImpl{
 		driverName:     driverName,
 		dataSourceName: dataSourceName,
+		connectTimeout: connectTimeout,
 	}
 }
 
+// Connect opens the pool and pings it, since sql.Open alone never dials and a bad
+// data source would otherwise only fail on the first query.
 func (dc *DatabaseConnectorImpl) Connect() error {
-	db, err := sql.Open(dc.driverName, dc.dataSourceName)
+	db, err := dc.open()
 	if err != nil {
-		return fmt.Errorf("failed to open database connection: %w", err)
+		return fmt.Errorf("failed to open database connection: %w", RedactError(dc.driverName, dc.dataSourceName, err))
 	}
 
-	if err := db.Ping(); err != nil {
+	ctx, cancel := context.WithTimeout(context.Background(), dc.connectTimeout)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		err = RedactError(dc.driverName, dc.dataSourceName, err)
+		if ctx.Err() != nil {
+			return fmt.Errorf("database did not respond within %s: %w", dc.connectTimeout, err)
+		}
 		return fmt.Errorf("failed to ping database: %w", err)
 	}
 
@@ -38,6 +64,18 @@ func (dc *DatabaseConnectorImpl) Connect() error {
 	return nil
 }
 
+func (dc *DatabaseConnectorImpl) open() (*sql.DB, error) {
+	if dc.wrap == nil {
+		return sql.Open(dc.driverName, dc.dataSourceName)
+	}
+
+	connector, err := openDriverConnector(dc.driverName, dc.dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(dc.wrap(connector)), nil
+}
+
 func (dc *DatabaseConnectorImpl) Close() error {
 	if dc.db == nil {
 		return nil
@@ -52,7 +90,10 @@ func (dc *DatabaseConnectorImpl) Ping() error {
 	if dc.db == nil {
 		return fmt.Errorf("database connection is not established")
 	}
-	return dc.db.Ping()
+
+	ctx, cancel := context.WithTimeout(context.Background(), dc.connectTimeout)
+	defer cancel()
+	return dc.db.PingContext(ctx)
 }
 
 func (dc *DatabaseConnectorImpl) GetDB() *sql.DB {
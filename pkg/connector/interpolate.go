@@ -0,0 +1,41 @@
+package connector
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const maskedSecret = "****"
+
+var envReferencePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ExpandEnv replaces ${VAR} references in a connection string with values from the process
+// environment. It fails on the first unset variable instead of silently substituting "".
+func ExpandEnv(dataSourceName string) (string, error) {
+	var missing []string
+	expanded := envReferencePattern.ReplaceAllStringFunc(dataSourceName, func(ref string) string {
+		name := envReferencePattern.FindStringSubmatch(ref)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+			return ref
+		}
+		return value
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("connection string references unset environment variable(s): %s", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// maskSecret masks a password for display. ${VAR} references are left visible since
+// they hold no secret.
+func maskSecret(secret string) string {
+	if secret == "" || envReferencePattern.FindString(secret) == secret {
+		return secret
+	}
+	return maskedSecret
+}
@@ -0,0 +1,71 @@
+package connector
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("CHERRY_TEST_USER", "app")
+	t.Setenv("CHERRY_TEST_PASSWORD", "s3cret")
+
+	got, err := ExpandEnv("postgres://${CHERRY_TEST_USER}:${CHERRY_TEST_PASSWORD}@db/app")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if want := "postgres://app:s3cret@db/app"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestExpandEnvLeavesOtherDollarSignsAlone(t *testing.T) {
+	dsn := "host=db password=pa$$word user=$USER"
+	got, err := ExpandEnv(dsn)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got != dsn {
+		t.Errorf("Expected %q, got %q", dsn, got)
+	}
+}
+
+func TestExpandEnvAllowsEmptyValues(t *testing.T) {
+	t.Setenv("CHERRY_TEST_EMPTY", "")
+
+	got, err := ExpandEnv("host=db password=${CHERRY_TEST_EMPTY}")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if want := "host=db password="; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestExpandEnvReportsUnsetVariables(t *testing.T) {
+	_, err := ExpandEnv("postgres://${CHERRY_TEST_UNSET_A}:${CHERRY_TEST_UNSET_B}@db/app")
+	if err == nil {
+		t.Fatalf("Expected an error for unset variables")
+	}
+	for _, name := range []string{"CHERRY_TEST_UNSET_A", "CHERRY_TEST_UNSET_B"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("Expected %s in %q", name, err.Error())
+		}
+	}
+}
+
+func TestMaskSecret(t *testing.T) {
+	tests := []struct {
+		secret string
+		want   string
+	}{
+		{"", ""},
+		{"s3cret", maskedSecret},
+		{"${DB_PASSWORD}", "${DB_PASSWORD}"},
+		{"x${DB_PASSWORD}", maskedSecret},
+	}
+	for _, tt := range tests {
+		if got := maskSecret(tt.secret); got != tt.want {
+			t.Errorf("Expected %q for %q, got %q", tt.want, tt.secret, got)
+		}
+	}
+}
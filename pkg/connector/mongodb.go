@@ -2,6 +2,7 @@ package connector
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"time"
 
@@ -14,6 +15,7 @@ type MongoConnectorImpl struct {
 	client           *mongo.Client
 	connectionString string
 	databaseName     string
+	tlsConfig        *tls.Config
 }
 
 func NewMongoConnector(connectionString, databaseName string) interfaces.MongoConnector {
@@ -23,13 +25,29 @@ func NewMongoConnector(connectionString, databaseName string) interfaces.MongoCo
 	}
 }
 
+func NewMongoConnectorWithTLS(connectionString, databaseName string, tlsConfig *tls.Config) interfaces.MongoConnector {
+	return &MongoConnectorImpl{
+		connectionString: connectionString,
+		databaseName:     databaseName,
+		tlsConfig:        tlsConfig,
+	}
+}
+
 func (mc *MongoConnectorImpl) Connect(ctx context.Context) error {
 	clientOptions := options.Client().ApplyURI(mc.connectionString)
 	clientOptions.SetConnectTimeout(10 * time.Second)
 	clientOptions.SetServerSelectionTimeout(5 * time.Second)
+	if mc.tlsConfig != nil {
+		clientOptions.SetTLSConfig(mc.tlsConfig)
+	}
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
-		return fmt.Errorf("failed to connect to MongoDB: %w", err)
+		return fmt.Errorf("failed to connect to MongoDB: %w", RedactError("mongodb", mc.connectionString, err))
+	}
+	// mongo.Connect does not dial, so ping to surface a bad URI or unreachable server now.
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		return fmt.Errorf("failed to ping MongoDB: %w", RedactError("mongodb", mc.connectionString, err))
 	}
 	mc.client = client
 	return nil
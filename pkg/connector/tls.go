@@ -0,0 +1,249 @@
+package connector
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+var ErrInsecureTLS = errors.New("insecureSkipVerify requires allowInsecure to be set explicitly")
+
+type TLSConfig struct {
+	CAFile             string `json:"caFile,omitempty"`
+	CertFile           string `json:"certFile,omitempty"`
+	KeyFile            string `json:"keyFile,omitempty"`
+	ServerName         string `json:"serverName,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+	AllowInsecure      bool   `json:"allowInsecure,omitempty"`
+}
+
+func (c *TLSConfig) Validate() error {
+	if c.InsecureSkipVerify && !c.AllowInsecure {
+		return ErrInsecureTLS
+	}
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return fmt.Errorf("certFile and keyFile must be provided together")
+	}
+	return nil
+}
+
+func (c *TLSConfig) Build() (*tls.Config, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CAFile != "" {
+		caCert, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no valid certificates found in CA file %s", c.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func (c *TLSConfig) key() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		c.CAFile, c.CertFile, c.KeyFile, c.ServerName, fmt.Sprint(c.InsecureSkipVerify),
+	}, "|")))
+	return hex.EncodeToString(sum[:8])
+}
+
+var (
+	mysqlTLSConfigs = make(map[string]bool)
+	mysqlTLSMutex   sync.Mutex
+)
+
+func ApplyTLS(driverName, dataSourceName string, config *TLSConfig) (string, error) {
+	if config == nil {
+		return dataSourceName, nil
+	}
+	if err := config.Validate(); err != nil {
+		return "", err
+	}
+
+	switch driverName {
+	case "mysql":
+		return applyMySQLTLS(dataSourceName, config)
+	case "postgres":
+		return applyPostgresTLS(dataSourceName, config)
+	default:
+		return "", fmt.Errorf("TLS configuration is not supported for driver %s", driverName)
+	}
+}
+
+func applyMySQLTLS(dataSourceName string, config *TLSConfig) (string, error) {
+	dsn, err := mysql.ParseDSN(dataSourceName)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse MySQL DSN: %w", err)
+	}
+
+	name := "custom-" + config.key()
+
+	mysqlTLSMutex.Lock()
+	defer mysqlTLSMutex.Unlock()
+
+	if !mysqlTLSConfigs[name] {
+		tlsConfig, err := config.Build()
+		if err != nil {
+			return "", err
+		}
+		if err := mysql.RegisterTLSConfig(name, tlsConfig); err != nil {
+			return "", fmt.Errorf("failed to register MySQL TLS config: %w", err)
+		}
+		mysqlTLSConfigs[name] = true
+	}
+
+	dsn.TLSConfig = name
+	dsn.TLS = nil
+	return dsn.FormatDSN(), nil
+}
+
+func applyPostgresTLS(dataSourceName string, config *TLSConfig) (string, error) {
+	if config.ServerName != "" {
+		return "", fmt.Errorf("serverName is not supported for postgres; connect using the certificate host name")
+	}
+
+	params := [][2]string{{"sslmode", "verify-full"}}
+	if config.InsecureSkipVerify {
+		params[0][1] = "require"
+	}
+	if config.CAFile != "" {
+		params = append(params, [2]string{"sslrootcert", config.CAFile})
+	}
+	if config.CertFile != "" {
+		params = append(params, [2]string{"sslcert", config.CertFile}, [2]string{"sslkey", config.KeyFile})
+	}
+
+	if strings.HasPrefix(dataSourceName, "postgres://") || strings.HasPrefix(dataSourceName, "postgresql://") {
+		parsed, err := url.Parse(dataSourceName)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse postgres URL: %w", err)
+		}
+		query := parsed.Query()
+		for _, param := range params {
+			query.Set(param[0], param[1])
+		}
+		parsed.RawQuery = query.Encode()
+		return parsed.String(), nil
+	}
+
+	fields, err := splitKeyValueDSN(dataSourceName)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse postgres connection string: %w", err)
+	}
+
+	var builder strings.Builder
+	for _, field := range fields {
+		if field.key == "sslmode" || field.key == "sslrootcert" || field.key == "sslcert" || field.key == "sslkey" {
+			continue
+		}
+		builder.WriteString(field.raw)
+		builder.WriteString(" ")
+	}
+	for _, param := range params {
+		fmt.Fprintf(&builder, "%s='%s' ", param[0], quoteKeyValue(param[1]))
+	}
+	return strings.TrimSpace(builder.String()), nil
+}
+
+// keyValueField is one setting of a libpq key=value connection string, with raw holding
+// its text exactly as written so quoted values survive being written back out.
+type keyValueField struct {
+	key string
+	raw string
+}
+
+// splitKeyValueDSN splits a libpq key=value connection string into its settings. Values
+// may be single-quoted to contain spaces, and a backslash escapes the next character.
+func splitKeyValueDSN(dsn string) ([]keyValueField, error) {
+	var fields []keyValueField
+	i := 0
+	skipSpaces := func() {
+		for i < len(dsn) && isKeyValueSpace(dsn[i]) {
+			i++
+		}
+	}
+
+	for {
+		skipSpaces()
+		if i == len(dsn) {
+			return fields, nil
+		}
+
+		start := i
+		for i < len(dsn) && dsn[i] != '=' && !isKeyValueSpace(dsn[i]) {
+			i++
+		}
+		key := dsn[start:i]
+		skipSpaces()
+		if i == len(dsn) || dsn[i] != '=' {
+			return nil, fmt.Errorf("missing \"=\" after %q", key)
+		}
+		i++
+		skipSpaces()
+
+		if i < len(dsn) && dsn[i] == '\'' {
+			i++
+			for i < len(dsn) && dsn[i] != '\'' {
+				if dsn[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(dsn) {
+				return nil, fmt.Errorf("unterminated quoted value for %q", key)
+			}
+			i++
+		} else {
+			for i < len(dsn) && !isKeyValueSpace(dsn[i]) {
+				if dsn[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i > len(dsn) {
+				i = len(dsn)
+			}
+		}
+
+		fields = append(fields, keyValueField{key: key, raw: dsn[start:i]})
+	}
+}
+
+func isKeyValueSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+// quoteKeyValue escapes value for use inside single quotes in a key=value connection string.
+func quoteKeyValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+}
@@ -0,0 +1,197 @@
+package connector
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestTLSConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  TLSConfig
+		wantErr bool
+	}{
+		{"empty", TLSConfig{}, false},
+		{"insecure without opt-in", TLSConfig{InsecureSkipVerify: true}, true},
+		{"insecure with opt-in", TLSConfig{InsecureSkipVerify: true, AllowInsecure: true}, false},
+		{"cert without key", TLSConfig{CertFile: "client.crt"}, true},
+		{"cert and key", TLSConfig{CertFile: "client.crt", KeyFile: "client.key"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if err := (&TLSConfig{InsecureSkipVerify: true}).Validate(); !errors.Is(err, ErrInsecureTLS) {
+		t.Errorf("Expected ErrInsecureTLS, got %v", err)
+	}
+}
+
+func TestTLSConfigBuildRejectsInvalidCAFile(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := (&TLSConfig{CAFile: caFile}).Build(); err == nil {
+		t.Errorf("Expected an error for a CA file without certificates")
+	}
+	if _, err := (&TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}).Build(); err == nil {
+		t.Errorf("Expected an error for a missing CA file")
+	}
+}
+
+func TestApplyTLSNilConfig(t *testing.T) {
+	dsn := "host=db user=app"
+	got, err := ApplyTLS("postgres", dsn, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got != dsn {
+		t.Errorf("Expected %q, got %q", dsn, got)
+	}
+}
+
+func TestApplyTLSUnsupportedDriver(t *testing.T) {
+	if _, err := ApplyTLS("sqlite3", "app.db", &TLSConfig{}); err == nil {
+		t.Errorf("Expected an error for sqlite3")
+	}
+}
+
+func TestApplyTLSPostgresURL(t *testing.T) {
+	got, err := ApplyTLS("postgres", "postgres://app:s3cret@db:5432/app?sslmode=disable",
+		&TLSConfig{CAFile: "/etc/ssl/ca.pem"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := "postgres://app:s3cret@db:5432/app?sslmode=verify-full&sslrootcert=%2Fetc%2Fssl%2Fca.pem"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestApplyTLSPostgresKeyValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		dsn    string
+		config TLSConfig
+		want   string
+	}{
+		{
+			"replaces ssl settings",
+			"host=db sslmode=disable user=app sslcert=old.crt",
+			TLSConfig{CAFile: "/etc/ssl/ca.pem"},
+			"host=db user=app sslmode='verify-full' sslrootcert='/etc/ssl/ca.pem'",
+		},
+		{
+			"keeps quoted values",
+			"host=db password='a b' user=app",
+			TLSConfig{},
+			"host=db password='a b' user=app sslmode='verify-full'",
+		},
+		{
+			"keeps escaped quotes",
+			`password='it\'s secret' dbname = app`,
+			TLSConfig{},
+			`password='it\'s secret' dbname = app sslmode='verify-full'`,
+		},
+		{
+			"drops quoted ssl settings",
+			"host=db sslrootcert='/path with spaces/ca.pem'",
+			TLSConfig{InsecureSkipVerify: true, AllowInsecure: true},
+			"host=db sslmode='require'",
+		},
+		{
+			"escapes file names",
+			"host=db",
+			TLSConfig{CAFile: `C:\certs\o'brien.pem`},
+			`host=db sslmode='verify-full' sslrootcert='C:\\certs\\o\'brien.pem'`,
+		},
+		{
+			"client certificate",
+			"host=db",
+			TLSConfig{CertFile: "client.crt", KeyFile: "client.key"},
+			"host=db sslmode='verify-full' sslcert='client.crt' sslkey='client.key'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyTLS("postgres", tt.dsn, &tt.config)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestApplyTLSPostgresRejectsServerName(t *testing.T) {
+	if _, err := ApplyTLS("postgres", "host=db", &TLSConfig{ServerName: "db.internal"}); err == nil {
+		t.Errorf("Expected an error for serverName")
+	}
+}
+
+func TestApplyTLSPostgresMalformedKeyValue(t *testing.T) {
+	for _, dsn := range []string{"host=db password='s3cret", "host=db user"} {
+		_, err := ApplyTLS("postgres", dsn, &TLSConfig{})
+		if err == nil {
+			t.Errorf("Expected an error for %q", dsn)
+			continue
+		}
+		if strings.Contains(err.Error(), "s3cret") {
+			t.Errorf("Expected the password to stay out of the error, got %q", err.Error())
+		}
+	}
+}
+
+func TestSplitKeyValueDSN(t *testing.T) {
+	fields, err := splitKeyValueDSN(`  host=db password='a b\\' port =5432 `)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := []keyValueField{
+		{key: "host", raw: "host=db"},
+		{key: "password", raw: `password='a b\\'`},
+		{key: "port", raw: "port =5432"},
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("Expected %#v, got %#v", want, fields)
+	}
+}
+
+func TestApplyTLSMySQL(t *testing.T) {
+	config := &TLSConfig{InsecureSkipVerify: true, AllowInsecure: true}
+	got, err := ApplyTLS("mysql", "root:s3cret@tcp(localhost:3306)/app", config)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	parsed, err := mysql.ParseDSN(got)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if want := "custom-" + config.key(); parsed.TLSConfig != want {
+		t.Errorf("Expected TLS config %q, got %q", want, parsed.TLSConfig)
+	}
+
+	again, err := ApplyTLS("mysql", "root:s3cret@tcp(localhost:3306)/app", config)
+	if err != nil {
+		t.Fatalf("Expected registering the same config twice to succeed, got %v", err)
+	}
+	if again != got {
+		t.Errorf("Expected %q, got %q", got, again)
+	}
+}
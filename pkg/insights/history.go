@@ -0,0 +1,233 @@
+package insights
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+const DefaultReportHistorySize = 100
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+type ReportHistoryStore struct {
+	dir        string
+	maxReports int
+	history    map[string][]*types.DatabaseReport
+	loaded     map[string]bool
+	mu         sync.RWMutex
+}
+
+// NewReportHistoryStore keeps history in memory; a non-empty dir also persists it as one JSON file per connection.
+func NewReportHistoryStore(dir string, maxReports int) (*ReportHistoryStore, error) {
+	if maxReports <= 0 {
+		maxReports = DefaultReportHistorySize
+	}
+
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create report history directory: %w", err)
+		}
+	}
+
+	return &ReportHistoryStore{
+		dir:        dir,
+		maxReports: maxReports,
+		history:    make(map[string][]*types.DatabaseReport),
+		loaded:     make(map[string]bool),
+	}, nil
+}
+
+func (rs *ReportHistoryStore) Record(connectionID string, report *types.DatabaseReport) error {
+	if report == nil {
+		return fmt.Errorf("report is required")
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if err := rs.load(connectionID); err != nil {
+		return err
+	}
+
+	reports := append(rs.history[connectionID], report)
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].AnalysisTime.Before(reports[j].AnalysisTime)
+	})
+	if len(reports) > rs.maxReports {
+		reports = reports[len(reports)-rs.maxReports:]
+	}
+	rs.history[connectionID] = reports
+
+	return rs.persist(connectionID)
+}
+
+func (rs *ReportHistoryStore) List(connectionID string) ([]*types.DatabaseReport, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if err := rs.load(connectionID); err != nil {
+		return nil, err
+	}
+
+	reports := make([]*types.DatabaseReport, len(rs.history[connectionID]))
+	copy(reports, rs.history[connectionID])
+	return reports, nil
+}
+
+func (rs *ReportHistoryStore) Delete(connectionID string) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	delete(rs.history, connectionID)
+	delete(rs.loaded, connectionID)
+
+	if rs.dir == "" {
+		return nil
+	}
+	if err := os.Remove(rs.path(connectionID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete report history: %w", err)
+	}
+	return nil
+}
+
+func (rs *ReportHistoryStore) GetTrends(connectionID string, metric string) ([]types.TrendPoint, error) {
+	extract, err := trendExtractor(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	reports, err := rs.List(connectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]types.TrendPoint, 0, len(reports))
+	for _, report := range reports {
+		points = append(points, types.TrendPoint{
+			Timestamp: report.AnalysisTime,
+			Metric:    metric,
+			Value:     extract(report),
+		})
+	}
+	return points, nil
+}
+
+func trendExtractor(metric string) (func(*types.DatabaseReport) float64, error) {
+	switch metric {
+	case types.TrendMetricRowCount:
+		return func(report *types.DatabaseReport) float64 {
+			return float64(report.Summary.TotalRows)
+		}, nil
+	case types.TrendMetricHealthScore:
+		return func(report *types.DatabaseReport) float64 {
+			return report.Summary.HealthScore
+		}, nil
+	case types.TrendMetricTableCount:
+		return func(report *types.DatabaseReport) float64 {
+			return float64(report.Summary.TotalTables)
+		}, nil
+	case types.TrendMetricSize:
+		return reportSizeBytes, nil
+	default:
+		return nil, types.NewAPIError(types.ErrCodeInvalidRequest,
+			fmt.Sprintf("unsupported trend metric: %s", metric)).WithDetails(map[string][]string{
+			"supported": {types.TrendMetricRowCount, types.TrendMetricHealthScore, types.TrendMetricTableCount, types.TrendMetricSize},
+		})
+	}
+}
+
+func reportSizeBytes(report *types.DatabaseReport) float64 {
+	if size, ok := parseReportSize(report.Summary.TotalSize); ok {
+		return size
+	}
+
+	var total float64
+	for _, table := range report.Tables {
+		if size, ok := parseReportSize(table.Size); ok {
+			total += size
+		}
+	}
+	return total
+}
+
+func parseReportSize(size string) (float64, bool) {
+	fields := strings.Fields(strings.TrimSpace(size))
+	if len(fields) == 0 {
+		return 0, false
+	}
+
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, false
+	}
+	if len(fields) == 1 {
+		return value, true
+	}
+
+	multipliers := map[string]float64{
+		"b": 1, "byte": 1, "bytes": 1,
+		"kb": 1 << 10, "mb": 1 << 20, "gb": 1 << 30, "tb": 1 << 40,
+	}
+	multiplier, ok := multipliers[strings.ToLower(fields[1])]
+	if !ok {
+		return 0, false
+	}
+	return value * multiplier, true
+}
+
+func (rs *ReportHistoryStore) path(connectionID string) string {
+	return filepath.Join(rs.dir, unsafeFileChars.ReplaceAllString(connectionID, "_")+".json")
+}
+
+func (rs *ReportHistoryStore) load(connectionID string) error {
+	if rs.dir == "" || rs.loaded[connectionID] {
+		return nil
+	}
+
+	data, err := os.ReadFile(rs.path(connectionID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			rs.loaded[connectionID] = true
+			return nil
+		}
+		return fmt.Errorf("failed to read report history: %w", err)
+	}
+
+	var reports []*types.DatabaseReport
+	if err := json.Unmarshal(data, &reports); err != nil {
+		return fmt.Errorf("failed to parse report history: %w", err)
+	}
+
+	rs.history[connectionID] = append(reports, rs.history[connectionID]...)
+	rs.loaded[connectionID] = true
+	return nil
+}
+
+func (rs *ReportHistoryStore) persist(connectionID string) error {
+	if rs.dir == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(rs.history[connectionID])
+	if err != nil {
+		return fmt.Errorf("failed to marshal report history: %w", err)
+	}
+
+	tmpPath := rs.path(connectionID) + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write report history: %w", err)
+	}
+	if err := os.Rename(tmpPath, rs.path(connectionID)); err != nil {
+		return fmt.Errorf("failed to save report history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package insights
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+func historyTestReport(at time.Time, rows int64, size string) *types.DatabaseReport {
+	return &types.DatabaseReport{
+		AnalysisTime: at,
+		Summary:      types.DatabaseSummary{TotalRows: rows, TotalSize: size, TotalTables: 3, HealthScore: 90},
+	}
+}
+
+func TestReportHistoryStoreRecordOrdersAndTrims(t *testing.T) {
+	store, err := NewReportHistoryStore("", 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, offset := range []int{2, 0, 1} {
+		if err := store.Record("conn", historyTestReport(base.Add(time.Duration(offset)*time.Hour), int64(offset), "")); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	reports, err := store.List("conn")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("Expected 2 reports, got %d", len(reports))
+	}
+	if reports[0].Summary.TotalRows != 1 || reports[1].Summary.TotalRows != 2 {
+		t.Errorf("Expected the two newest reports in order, got %d and %d", reports[0].Summary.TotalRows, reports[1].Summary.TotalRows)
+	}
+
+	if err := store.Record("conn", nil); err == nil {
+		t.Errorf("Expected an error for a nil report")
+	}
+}
+
+func TestReportHistoryStorePersists(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "history")
+	store, err := NewReportHistoryStore(dir, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := store.Record("prod/db:1", historyTestReport(at, 10, "1 KB")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "prod_db_1.json")); err != nil {
+		t.Fatalf("Expected a sanitized history file, got %v", err)
+	}
+
+	reopened, err := NewReportHistoryStore(dir, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	reports, err := reopened.List("prod/db:1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(reports) != 1 || !reports[0].AnalysisTime.Equal(at) {
+		t.Fatalf("Expected the persisted report, got %+v", reports)
+	}
+
+	if err := reopened.Delete("prod/db:1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "prod_db_1.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected the history file to be removed, got %v", err)
+	}
+	if err := reopened.Delete("prod/db:1"); err != nil {
+		t.Errorf("Expected deleting missing history to succeed, got %v", err)
+	}
+}
+
+func TestReportHistoryStoreCorruptFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "conn.json"), []byte("{"), 0o644); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	store, err := NewReportHistoryStore(dir, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := store.List("conn"); err == nil {
+		t.Errorf("Expected an error for a corrupt history file")
+	}
+}
+
+func TestReportHistoryStoreGetTrends(t *testing.T) {
+	store, err := NewReportHistoryStore("", 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := store.Record("conn", historyTestReport(at, 42, "1.5 MB")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		metric string
+		want   float64
+	}{
+		{types.TrendMetricRowCount, 42},
+		{types.TrendMetricHealthScore, 90},
+		{types.TrendMetricTableCount, 3},
+		{types.TrendMetricSize, 1.5 * (1 << 20)},
+	}
+	for _, tt := range tests {
+		points, err := store.GetTrends("conn", tt.metric)
+		if err != nil {
+			t.Fatalf("Expected no error for %s, got %v", tt.metric, err)
+		}
+		if len(points) != 1 || points[0].Value != tt.want || points[0].Metric != tt.metric {
+			t.Errorf("Expected %s to be %v, got %+v", tt.metric, tt.want, points)
+		}
+	}
+
+	_, err = store.GetTrends("conn", "latency")
+	var apiErr *types.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != types.ErrCodeInvalidRequest {
+		t.Errorf("Expected an invalid request error, got %v", err)
+	}
+}
+
+func TestReportSizeBytesFallsBackToTables(t *testing.T) {
+	report := &types.DatabaseReport{
+		Summary: types.DatabaseSummary{TotalSize: "unknown"},
+		Tables:  []types.TableInfo{{Size: "2 KB"}, {Size: "512"}, {Size: "n/a"}},
+	}
+	if got := reportSizeBytes(report); got != 2560 {
+		t.Errorf("Expected 2560, got %v", got)
+	}
+}
+
+func TestParseReportSize(t *testing.T) {
+	tests := []struct {
+		size string
+		want float64
+		ok   bool
+	}{
+		{"", 0, false},
+		{"100", 100, true},
+		{"3 bytes", 3, true},
+		{"2 GB", 2 << 30, true},
+		{"1 PB", 0, false},
+		{"big", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseReportSize(tt.size)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Expected %v, %v for %q, got %v, %v", tt.want, tt.ok, tt.size, got, ok)
+		}
+	}
+}
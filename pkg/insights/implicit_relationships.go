@@ -0,0 +1,166 @@
+package insights
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+const (
+	// MinRelationshipContainment is the share of sampled source values that must exist in
+	// the target column for a candidate to be reported.
+	MinRelationshipContainment = 0.9
+
+	nameMatchSameColumn = 1.0
+	nameMatchTableKey   = 0.9
+)
+
+// FindRelationshipCandidates pairs columns with the single-column primary key of another
+// table when the names line up (customer_id with customers.customer_id or customers.id)
+// and the types are compatible. Columns already covered by a declared foreign key are
+// skipped. Confidence holds the name-match weight until ScoreRelationship is applied.
+func FindRelationshipCandidates(tables []types.TableInfo) []types.CandidateRelationship {
+	candidates := []types.CandidateRelationship{}
+
+	for _, target := range tables {
+		key, ok := singlePrimaryKey(target)
+		if !ok {
+			continue
+		}
+
+		for _, source := range tables {
+			if source.Name == target.Name {
+				continue
+			}
+			declared := declaredForeignKeyColumns(source)
+
+			for _, column := range source.Columns {
+				if column.IsPrimaryKey || declared[strings.ToLower(column.Name)] {
+					continue
+				}
+				if typeFamily(column.DataType) != typeFamily(key.DataType) {
+					continue
+				}
+
+				weight, reason := relationshipNameMatch(column.Name, target.Name, key.Name)
+				if weight == 0 {
+					continue
+				}
+
+				candidates = append(candidates, types.CandidateRelationship{
+					SourceTable:  source.Name,
+					SourceColumn: column.Name,
+					TargetTable:  target.Name,
+					TargetColumn: key.Name,
+					Confidence:   weight,
+					Reason:       reason,
+				})
+			}
+		}
+	}
+
+	return candidates
+}
+
+// ScoreRelationship records the containment sample on the candidate and reports whether
+// it is strong enough to suggest. Confidence becomes the name-match weight scaled by the
+// share of sampled values found in the target column.
+func ScoreRelationship(candidate *types.CandidateRelationship, sampled, matched int) bool {
+	candidate.SampledValues = sampled
+	candidate.MatchedValues = matched
+	if sampled == 0 {
+		candidate.Confidence = 0
+		return false
+	}
+
+	containment := float64(matched) / float64(sampled)
+	candidate.Confidence = candidate.Confidence * containment
+	candidate.Reason = fmt.Sprintf("%s; %d of %d sampled values found in %s.%s",
+		candidate.Reason, matched, sampled, candidate.TargetTable, candidate.TargetColumn)
+
+	return containment >= MinRelationshipContainment
+}
+
+func singlePrimaryKey(table types.TableInfo) (types.ColumnInfo, bool) {
+	var key types.ColumnInfo
+	count := 0
+	for _, column := range table.Columns {
+		if column.IsPrimaryKey {
+			key = column
+			count++
+		}
+	}
+	return key, count == 1
+}
+
+func declaredForeignKeyColumns(table types.TableInfo) map[string]bool {
+	declared := make(map[string]bool)
+	for _, constraint := range table.Constraints {
+		if strings.EqualFold(constraint.Type, "FOREIGN KEY") {
+			for _, column := range constraint.Columns {
+				declared[strings.ToLower(column)] = true
+			}
+		}
+	}
+	for _, rel := range table.Relationships {
+		if rel.SourceColumn != "" {
+			declared[strings.ToLower(rel.SourceColumn)] = true
+		}
+	}
+	return declared
+}
+
+// relationshipNameMatch weighs how strongly a column name points at a table's key. A
+// bare "id" column matches nothing, since every table has one.
+func relationshipNameMatch(columnName, tableName, keyName string) (float64, string) {
+	column := normalizeIdentifier(columnName)
+	key := normalizeIdentifier(keyName)
+	if column == "" || column == "id" {
+		return 0, ""
+	}
+
+	if column == key {
+		return nameMatchSameColumn, fmt.Sprintf("column name matches the primary key of %s", tableName)
+	}
+
+	table := normalizeIdentifier(singularize(tableName))
+	if column == table+key || (key == "id" && column == table+"id") {
+		return nameMatchTableKey, fmt.Sprintf("column name refers to %s by its key", tableName)
+	}
+	return 0, ""
+}
+
+// normalizeIdentifier lowercases and drops underscores so customer_id and customerId compare equal.
+func normalizeIdentifier(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "_", "")
+}
+
+func singularize(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, "ies"):
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "xes"):
+		return name[:len(name)-2]
+	case strings.HasSuffix(lower, "s") && !strings.HasSuffix(lower, "ss"):
+		return name[:len(name)-1]
+	}
+	return name
+}
+
+// typeFamily groups column types whose values can be compared for containment.
+func typeFamily(dataType string) string {
+	dataType = strings.ToLower(dataType)
+	switch {
+	case strings.Contains(dataType, "uuid"):
+		return "uuid"
+	case strings.Contains(dataType, "int"), strings.Contains(dataType, "serial"):
+		return "integer"
+	case strings.Contains(dataType, "char"), strings.Contains(dataType, "text"):
+		return "text"
+	case strings.Contains(dataType, "numeric"), strings.Contains(dataType, "decimal"):
+		return "numeric"
+	}
+	return dataType
+}
@@ -0,0 +1,142 @@
+package insights
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+const (
+	RedundancyDuplicate = "duplicate"
+	RedundancyPrefix    = "prefix"
+)
+
+// FindRedundantIndexes compares the indexes of each table and reports those made
+// unnecessary by another index of the same type: exact duplicates, and indexes whose
+// columns are a leading prefix of a longer index. Unique and primary-key indexes are
+// only reported when duplicated by an index that enforces the same constraint.
+func FindRedundantIndexes(tables []types.TableInfo, driverName string) []types.RedundantIndexFinding {
+	findings := []types.RedundantIndexFinding{}
+
+	for _, table := range tables {
+		indexes := make([]types.IndexInfo, 0, len(table.Indexes))
+		for _, index := range table.Indexes {
+			if len(index.Columns) > 0 {
+				indexes = append(indexes, index)
+			}
+		}
+		sort.SliceStable(indexes, func(i, j int) bool {
+			return indexKeepRank(indexes[i]) > indexKeepRank(indexes[j])
+		})
+
+		reported := make(map[string]bool)
+		for i, index := range indexes {
+			if reported[index.Name] {
+				continue
+			}
+			for j, other := range indexes {
+				if i == j || reported[other.Name] || !sameIndexType(index, other) {
+					continue
+				}
+
+				reason := redundancyReason(index, other)
+				if reason == "" || (reason == RedundancyDuplicate && j > i) {
+					continue
+				}
+
+				findings = append(findings, types.RedundantIndexFinding{
+					Table:                 table.Name,
+					Index:                 index.Name,
+					Columns:               index.Columns,
+					CoveredBy:             other.Name,
+					CoveringColumns:       other.Columns,
+					Reason:                reason,
+					DropStatement:         dropIndexStatement(driverName, table.Name, index.Name),
+					EstimatedSavingsBytes: index.SizeBytes,
+				})
+				reported[index.Name] = true
+				break
+			}
+		}
+	}
+
+	return findings
+}
+
+// redundancyReason reports why index is unnecessary given other, or "" if it is not.
+func redundancyReason(index, other types.IndexInfo) string {
+	if isPrimaryIndex(index) {
+		return ""
+	}
+
+	switch {
+	case equalColumns(index.Columns, other.Columns):
+		if index.IsUnique && !other.IsUnique {
+			return ""
+		}
+		return RedundancyDuplicate
+	case isColumnPrefix(index.Columns, other.Columns):
+		if index.IsUnique {
+			return ""
+		}
+		return RedundancyPrefix
+	}
+	return ""
+}
+
+// indexKeepRank orders indexes so that the one to keep among duplicates comes first.
+func indexKeepRank(index types.IndexInfo) int {
+	switch {
+	case isPrimaryIndex(index):
+		return 2
+	case index.IsUnique:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func isPrimaryIndex(index types.IndexInfo) bool {
+	name := strings.ToLower(index.Name)
+	return name == "primary" || name == "_id_" || strings.HasSuffix(name, "_pkey")
+}
+
+func sameIndexType(a, b types.IndexInfo) bool {
+	normalize := func(indexType string) string {
+		indexType = strings.ToLower(indexType)
+		if indexType == "" {
+			return "btree"
+		}
+		return indexType
+	}
+	return normalize(a.Type) == normalize(b.Type)
+}
+
+func equalColumns(a, b []string) bool {
+	return len(a) == len(b) && isColumnPrefix(a, b)
+}
+
+func isColumnPrefix(prefix, columns []string) bool {
+	if len(prefix) > len(columns) {
+		return false
+	}
+	for i, column := range prefix {
+		if !strings.EqualFold(column, columns[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func dropIndexStatement(driverName, tableName, indexName string) string {
+	switch strings.ToLower(driverName) {
+	case "mysql":
+		return fmt.Sprintf("ALTER TABLE `%s` DROP INDEX `%s`;", tableName, indexName)
+	case "mongodb":
+		return fmt.Sprintf("db.getCollection(%q).dropIndex(%q)", tableName, indexName)
+	default:
+		return fmt.Sprintf("DROP INDEX %q;", indexName)
+	}
+}
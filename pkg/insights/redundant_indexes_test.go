@@ -0,0 +1,95 @@
+package insights
+
+import (
+	"testing"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+func TestFindRedundantIndexes(t *testing.T) {
+	tables := []types.TableInfo{{
+		Name: "orders",
+		Indexes: []types.IndexInfo{
+			{Name: "orders_pkey", Columns: []string{"id"}, IsUnique: true},
+			{Name: "orders_id_idx", Columns: []string{"id"}, SizeBytes: 2048},
+			{Name: "orders_user_idx", Columns: []string{"user_id"}, SizeBytes: 4096},
+			{Name: "orders_user_created_idx", Columns: []string{"USER_ID", "created_at"}},
+			{Name: "orders_status_key", Columns: []string{"status"}, IsUnique: true},
+			{Name: "orders_status_created_idx", Columns: []string{"status", "created_at"}},
+			{Name: "orders_payload_gin", Columns: []string{"payload"}, Type: "gin"},
+			{Name: "orders_payload_idx", Columns: []string{"payload"}},
+			{Name: "orders_expression_idx"},
+		},
+	}}
+
+	findings := FindRedundantIndexes(tables, "postgres")
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %+v", findings)
+	}
+
+	duplicate := findings[0]
+	if duplicate.Index != "orders_id_idx" || duplicate.CoveredBy != "orders_pkey" || duplicate.Reason != RedundancyDuplicate {
+		t.Errorf("Expected orders_id_idx duplicated by orders_pkey, got %+v", duplicate)
+	}
+	if duplicate.DropStatement != `DROP INDEX "orders_id_idx";` {
+		t.Errorf("Expected a postgres drop statement, got %s", duplicate.DropStatement)
+	}
+	if duplicate.EstimatedSavingsBytes != 2048 {
+		t.Errorf("Expected 2048 bytes saved, got %d", duplicate.EstimatedSavingsBytes)
+	}
+
+	prefix := findings[1]
+	if prefix.Index != "orders_user_idx" || prefix.CoveredBy != "orders_user_created_idx" || prefix.Reason != RedundancyPrefix {
+		t.Errorf("Expected orders_user_idx covered by orders_user_created_idx, got %+v", prefix)
+	}
+}
+
+func TestFindRedundantIndexesReportsOneOfExactDuplicates(t *testing.T) {
+	tables := []types.TableInfo{{
+		Name: "users",
+		Indexes: []types.IndexInfo{
+			{Name: "users_email_a", Columns: []string{"email"}},
+			{Name: "users_email_b", Columns: []string{"email"}},
+			{Name: "users_email_key", Columns: []string{"email"}, IsUnique: true},
+		},
+	}}
+
+	findings := FindRedundantIndexes(tables, "mysql")
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %+v", findings)
+	}
+	for _, finding := range findings {
+		if finding.CoveredBy != "users_email_key" {
+			t.Errorf("Expected the unique index to be kept, got %+v", finding)
+		}
+	}
+	if want := "ALTER TABLE `users` DROP INDEX `users_email_a`;"; findings[0].DropStatement != want {
+		t.Errorf("Expected %s, got %s", want, findings[0].DropStatement)
+	}
+}
+
+func TestFindRedundantIndexesKeepsUniqueIndexes(t *testing.T) {
+	tables := []types.TableInfo{{
+		Name: "accounts",
+		Indexes: []types.IndexInfo{
+			{Name: "accounts_slug_key", Columns: []string{"slug"}, IsUnique: true},
+			{Name: "accounts_slug_idx", Columns: []string{"slug"}},
+			{Name: "accounts_slug_owner_key", Columns: []string{"slug", "owner"}, IsUnique: true},
+		},
+	}}
+
+	findings := FindRedundantIndexes(tables, "postgres")
+	if len(findings) != 1 {
+		t.Fatalf("Expected 1 finding, got %+v", findings)
+	}
+	if findings[0].Index != "accounts_slug_idx" {
+		t.Errorf("Expected only the non-unique index to be reported, got %+v", findings[0])
+	}
+}
+
+func TestDropIndexStatementMongo(t *testing.T) {
+	want := `db.getCollection("users").dropIndex("email_1")`
+	if got := dropIndexStatement("MongoDB", "users", "email_1"); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
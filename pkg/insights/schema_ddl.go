@@ -0,0 +1,348 @@
+package insights
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+const (
+	DialectPostgres = "postgres"
+	DialectMySQL    = "mysql"
+	DialectSQLite   = "sqlite"
+)
+
+type foreignKey struct {
+	name       string
+	columns    []string
+	refTable   string
+	refColumns []string
+}
+
+// RenderSchemaDDL reconstructs CREATE TABLE and CREATE INDEX statements for tables in
+// dialect. Tables are ordered so referenced tables are created first. Foreign keys that
+// still point forward, because of a cycle, are added afterwards with ALTER TABLE, or kept
+// inline for SQLite, which neither needs nor supports that. Foreign keys to tables
+// outside the schema are left out with a comment.
+func RenderSchemaDDL(tables []types.TableInfo, dialect string) (string, error) {
+	dialect = strings.ToLower(dialect)
+	switch dialect {
+	case DialectPostgres, DialectMySQL, DialectSQLite:
+	case "postgresql":
+		dialect = DialectPostgres
+	case "sqlite3":
+		dialect = DialectSQLite
+	default:
+		return "", fmt.Errorf("unsupported DDL dialect: %s", dialect)
+	}
+
+	byName := make(map[string]types.TableInfo, len(tables))
+	keys := make(map[string][]foreignKey, len(tables))
+	for _, table := range tables {
+		byName[table.Name] = table
+		keys[table.Name] = tableForeignKeys(table)
+	}
+
+	var b strings.Builder
+	var deferred []string
+	created := make(map[string]bool, len(tables))
+	for _, table := range orderTablesByDependency(tables, keys) {
+		var lines []string
+		for _, column := range table.Columns {
+			lines = append(lines, "  "+columnDefinition(column, dialect))
+		}
+		if primaryKey := primaryKeyColumns(table); len(primaryKey) > 0 {
+			lines = append(lines, fmt.Sprintf("  PRIMARY KEY (%s)", quoteColumns(dialect, primaryKey)))
+		}
+
+		var skipped []string
+		for _, fk := range keys[table.Name] {
+			if _, exists := byName[fk.refTable]; !exists {
+				skipped = append(skipped, fmt.Sprintf("-- skipped foreign key (%s) on %s: table %s is not in the schema\n",
+					strings.Join(fk.columns, ", "), table.Name, fk.refTable))
+				continue
+			}
+			clause := foreignKeyClause(fk, dialect)
+			if created[fk.refTable] || fk.refTable == table.Name || dialect == DialectSQLite {
+				lines = append(lines, "  "+clause)
+				continue
+			}
+			deferred = append(deferred, fmt.Sprintf("ALTER TABLE %s ADD %s;\n",
+				quoteDDLIdentifier(dialect, table.Name), clause))
+		}
+
+		for _, line := range skipped {
+			b.WriteString(line)
+		}
+		fmt.Fprintf(&b, "CREATE TABLE %s (\n%s\n);\n", quoteDDLIdentifier(dialect, table.Name), strings.Join(lines, ",\n"))
+		for _, index := range table.Indexes {
+			if statement := indexStatement(table, index, dialect); statement != "" {
+				b.WriteString(statement)
+			}
+		}
+		b.WriteString("\n")
+		created[table.Name] = true
+	}
+
+	for _, statement := range deferred {
+		b.WriteString(statement)
+	}
+
+	return strings.TrimRight(b.String(), "\n") + "\n", nil
+}
+
+// tableForeignKeys merges declared constraints, which SQLite reports one row per column
+// under a shared name, with relationships, which MySQL and PostgreSQL report instead.
+func tableForeignKeys(table types.TableInfo) []foreignKey {
+	var keys []foreignKey
+	byName := make(map[string]int)
+	covered := make(map[string]bool)
+
+	for _, constraint := range table.Constraints {
+		if !strings.EqualFold(constraint.Type, "FOREIGN KEY") || constraint.RefTable == "" {
+			continue
+		}
+		if i, exists := byName[constraint.Name]; exists && constraint.Name != "" {
+			keys[i].columns = append(keys[i].columns, constraint.Columns...)
+			keys[i].refColumns = append(keys[i].refColumns, constraint.RefColumns...)
+		} else {
+			byName[constraint.Name] = len(keys)
+			keys = append(keys, foreignKey{
+				name:       constraint.Name,
+				columns:    append([]string{}, constraint.Columns...),
+				refTable:   constraint.RefTable,
+				refColumns: append([]string{}, constraint.RefColumns...),
+			})
+		}
+		for _, column := range constraint.Columns {
+			covered[column] = true
+		}
+	}
+
+	for _, rel := range table.Relationships {
+		if !strings.EqualFold(rel.Type, "FOREIGN KEY") || rel.SourceColumn == "" || covered[rel.SourceColumn] {
+			continue
+		}
+		covered[rel.SourceColumn] = true
+		keys = append(keys, foreignKey{
+			columns:    []string{rel.SourceColumn},
+			refTable:   rel.TargetTable,
+			refColumns: []string{rel.TargetColumn},
+		})
+	}
+
+	return keys
+}
+
+// orderTablesByDependency sorts tables so each follows the tables it references, breaking
+// ties by name. Tables caught in a reference cycle come last, in name order.
+func orderTablesByDependency(tables []types.TableInfo, keys map[string][]foreignKey) []types.TableInfo {
+	sorted := append([]types.TableInfo{}, tables...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	known := make(map[string]bool, len(sorted))
+	for _, table := range sorted {
+		known[table.Name] = true
+	}
+
+	placed := make(map[string]bool, len(sorted))
+	ordered := make([]types.TableInfo, 0, len(sorted))
+	for len(ordered) < len(sorted) {
+		progressed := false
+		for _, table := range sorted {
+			if placed[table.Name] || !dependenciesPlaced(table.Name, keys[table.Name], known, placed) {
+				continue
+			}
+			placed[table.Name] = true
+			ordered = append(ordered, table)
+			progressed = true
+		}
+		if !progressed {
+			break
+		}
+	}
+
+	for _, table := range sorted {
+		if !placed[table.Name] {
+			ordered = append(ordered, table)
+		}
+	}
+	return ordered
+}
+
+func dependenciesPlaced(table string, keys []foreignKey, known, placed map[string]bool) bool {
+	for _, fk := range keys {
+		if fk.refTable != table && known[fk.refTable] && !placed[fk.refTable] {
+			return false
+		}
+	}
+	return true
+}
+
+func primaryKeyColumns(table types.TableInfo) []string {
+	var columns []string
+	for _, column := range table.Columns {
+		if column.IsPrimaryKey {
+			columns = append(columns, column.Name)
+		}
+	}
+	return columns
+}
+
+func columnDefinition(column types.ColumnInfo, dialect string) string {
+	definition := quoteDDLIdentifier(dialect, column.Name) + " " + columnType(column, dialect)
+	if !column.IsNullable {
+		definition += " NOT NULL"
+	}
+	if value, ok := defaultExpression(column.DefaultValue); ok {
+		definition += " DEFAULT " + value
+	}
+	return definition
+}
+
+// columnType keeps the analyzed type, adding the length or precision it was reported
+// with separately, and maps the few common types whose spelling differs by dialect.
+func columnType(column types.ColumnInfo, dialect string) string {
+	dataType := strings.TrimSpace(column.DataType)
+	if dataType == "" {
+		dataType = "TEXT"
+	}
+
+	switch lower := strings.ToLower(dataType); {
+	case lower == "character varying":
+		dataType = "VARCHAR"
+	case lower == "character":
+		dataType = "CHAR"
+	case lower == "timestamp without time zone":
+		dataType = "TIMESTAMP"
+	case lower == "double precision" && dialect == DialectMySQL:
+		dataType = "DOUBLE"
+	case lower == "bytea" && dialect != DialectPostgres:
+		dataType = "BLOB"
+	case lower == "blob" && dialect == DialectPostgres:
+		dataType = "BYTEA"
+	case lower == "datetime" && dialect == DialectPostgres:
+		dataType = "TIMESTAMP"
+	case lower == "text" && dialect == DialectMySQL && column.IsPrimaryKey:
+		// MySQL cannot index TEXT without a prefix length.
+		dataType = "VARCHAR(255)"
+	}
+
+	if i := strings.Index(dataType, "("); i >= 0 {
+		return strings.ToUpper(dataType[:i]) + dataType[i:]
+	}
+	dataType = strings.ToUpper(dataType)
+
+	switch dataType {
+	case "VARCHAR", "CHAR", "VARBINARY", "BINARY":
+		if column.MaxLength > 0 {
+			return fmt.Sprintf("%s(%d)", dataType, column.MaxLength)
+		}
+		if dataType == "VARCHAR" && dialect == DialectMySQL {
+			return "VARCHAR(255)"
+		}
+	case "DECIMAL", "NUMERIC":
+		if column.Precision > 0 {
+			return fmt.Sprintf("%s(%d,%d)", dataType, column.Precision, column.Scale)
+		}
+	}
+	return dataType
+}
+
+// defaultExpression renders a reported default. Sequence defaults are dropped, since the
+// sequence they name is not part of the export.
+func defaultExpression(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case []byte:
+		return defaultExpression(string(v))
+	case bool:
+		if v {
+			return "TRUE", true
+		}
+		return "FALSE", true
+	case string:
+		trimmed := strings.TrimSpace(v)
+		upper := strings.ToUpper(trimmed)
+		switch {
+		case trimmed == "" || strings.Contains(strings.ToLower(trimmed), "nextval("):
+			return "", false
+		case strings.HasPrefix(trimmed, "'") || strings.HasPrefix(trimmed, "("),
+			upper == "NULL" || upper == "TRUE" || upper == "FALSE",
+			strings.HasPrefix(upper, "CURRENT_"):
+			return trimmed, true
+		case strings.HasSuffix(trimmed, ")"):
+			return "(" + trimmed + ")", true
+		}
+		if _, err := strconv.ParseFloat(trimmed, 64); err == nil {
+			return trimmed, true
+		}
+		return "'" + strings.ReplaceAll(trimmed, "'", "''") + "'", true
+	default:
+		return fmt.Sprintf("%v", v), true
+	}
+}
+
+// foreignKeyClause names the constraint only for PostgreSQL, where names are per table.
+// MySQL needs them unique per database, which the synthetic SQLite names are not.
+func foreignKeyClause(fk foreignKey, dialect string) string {
+	clause := ""
+	if fk.name != "" && dialect == DialectPostgres {
+		clause = "CONSTRAINT " + quoteDDLIdentifier(dialect, fk.name) + " "
+	}
+	return clause + fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)",
+		quoteColumns(dialect, fk.columns), quoteDDLIdentifier(dialect, fk.refTable), quoteColumns(dialect, fk.refColumns))
+}
+
+// indexStatement skips expression indexes and the indexes databases create for primary
+// keys themselves, which the CREATE TABLE already implies.
+func indexStatement(table types.TableInfo, index types.IndexInfo, dialect string) string {
+	if len(index.Columns) == 0 || index.Name == "" {
+		return ""
+	}
+	lower := strings.ToLower(index.Name)
+	if lower == "primary" || strings.HasPrefix(lower, "sqlite_autoindex_") {
+		return ""
+	}
+	if primaryKey := primaryKeyColumns(table); index.IsUnique && sameColumns(index.Columns, primaryKey) {
+		return ""
+	}
+
+	unique := ""
+	if index.IsUnique {
+		unique = "UNIQUE "
+	}
+	return fmt.Sprintf("CREATE %sINDEX %s ON %s (%s);\n", unique,
+		quoteDDLIdentifier(dialect, index.Name), quoteDDLIdentifier(dialect, table.Name), quoteColumns(dialect, index.Columns))
+}
+
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func quoteColumns(dialect string, columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quoteDDLIdentifier(dialect, column)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func quoteDDLIdentifier(dialect, name string) string {
+	if dialect == DialectMySQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
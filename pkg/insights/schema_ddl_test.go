@@ -0,0 +1,239 @@
+package insights
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+func schemaDDLTestTables() []types.TableInfo {
+	return []types.TableInfo{
+		{
+			Name: "orders",
+			Columns: []types.ColumnInfo{
+				{Name: "id", DataType: "integer", IsPrimaryKey: true, DefaultValue: "nextval('orders_id_seq'::regclass)"},
+				{Name: "user_id", DataType: "integer"},
+				{Name: "total", DataType: "numeric", Precision: 10, Scale: 2, IsNullable: true, DefaultValue: "0"},
+				{Name: "status", DataType: "character varying", MaxLength: 20, DefaultValue: "pending"},
+			},
+			Indexes: []types.IndexInfo{
+				{Name: "orders_pkey", Columns: []string{"id"}, IsUnique: true},
+				{Name: "orders_user_id_idx", Columns: []string{"user_id"}},
+			},
+			Relationships: []types.Relationship{
+				{Type: "FOREIGN KEY", SourceColumn: "user_id", TargetTable: "users", TargetColumn: "id"},
+			},
+		},
+		{
+			Name: "users",
+			Columns: []types.ColumnInfo{
+				{Name: "id", DataType: "integer", IsPrimaryKey: true},
+				{Name: "email", DataType: "text"},
+				{Name: "active", DataType: "boolean", DefaultValue: true},
+				{Name: "created_at", DataType: "timestamp without time zone", DefaultValue: "CURRENT_TIMESTAMP"},
+			},
+			Indexes: []types.IndexInfo{
+				{Name: "users_email_key", Columns: []string{"email"}, IsUnique: true},
+			},
+		},
+	}
+}
+
+func TestRenderSchemaDDLPostgres(t *testing.T) {
+	ddl, err := RenderSchemaDDL(schemaDDLTestTables(), "postgresql")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := `CREATE TABLE "users" (
+  "id" INTEGER NOT NULL,
+  "email" TEXT NOT NULL,
+  "active" BOOLEAN NOT NULL DEFAULT TRUE,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX "users_email_key" ON "users" ("email");
+
+CREATE TABLE "orders" (
+  "id" INTEGER NOT NULL,
+  "user_id" INTEGER NOT NULL,
+  "total" NUMERIC(10,2) DEFAULT 0,
+  "status" VARCHAR(20) NOT NULL DEFAULT 'pending',
+  PRIMARY KEY ("id"),
+  FOREIGN KEY ("user_id") REFERENCES "users" ("id")
+);
+CREATE INDEX "orders_user_id_idx" ON "orders" ("user_id");
+`
+	if ddl != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, ddl)
+	}
+}
+
+func TestRenderSchemaDDLMySQL(t *testing.T) {
+	tables := schemaDDLTestTables()
+	tables[1].Columns[0].DataType = "text"
+
+	ddl, err := RenderSchemaDDL(tables, DialectMySQL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, want := range []string{
+		"CREATE TABLE `users` (",
+		"`id` VARCHAR(255) NOT NULL",
+		"`status` VARCHAR(20) NOT NULL DEFAULT 'pending'",
+		"FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)",
+	} {
+		if !strings.Contains(ddl, want) {
+			t.Errorf("Expected %q in\n%s", want, ddl)
+		}
+	}
+}
+
+func TestRenderSchemaDDLDefersCyclicForeignKeys(t *testing.T) {
+	tables := []types.TableInfo{
+		{
+			Name:    "a",
+			Columns: []types.ColumnInfo{{Name: "b_id", DataType: "integer"}},
+			Constraints: []types.Constraint{
+				{Name: "a_b_fk", Type: "FOREIGN KEY", Columns: []string{"b_id"}, RefTable: "b", RefColumns: []string{"id"}},
+			},
+		},
+		{
+			Name:    "b",
+			Columns: []types.ColumnInfo{{Name: "a_id", DataType: "integer"}},
+			Constraints: []types.Constraint{
+				{Name: "b_a_fk", Type: "FOREIGN KEY", Columns: []string{"a_id"}, RefTable: "a", RefColumns: []string{"id"}},
+			},
+		},
+	}
+
+	ddl, err := RenderSchemaDDL(tables, DialectPostgres)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := `ALTER TABLE "a" ADD CONSTRAINT "a_b_fk" FOREIGN KEY ("b_id") REFERENCES "b" ("id");`
+	if !strings.HasSuffix(strings.TrimSpace(ddl), want) {
+		t.Errorf("Expected the forward reference to be added last with %q, got\n%s", want, ddl)
+	}
+	if !strings.Contains(ddl, `  CONSTRAINT "b_a_fk" FOREIGN KEY ("a_id") REFERENCES "a" ("id")`) {
+		t.Errorf("Expected the backward reference inline, got\n%s", ddl)
+	}
+
+	sqlite, err := RenderSchemaDDL(tables, "sqlite3")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Contains(sqlite, "ALTER TABLE") {
+		t.Errorf("Expected SQLite to keep every foreign key inline, got\n%s", sqlite)
+	}
+}
+
+func TestRenderSchemaDDLSkipsForeignKeysOutsideSchema(t *testing.T) {
+	tables := []types.TableInfo{{
+		Name:    "orders",
+		Columns: []types.ColumnInfo{{Name: "user_id", DataType: "integer"}},
+		Relationships: []types.Relationship{
+			{Type: "FOREIGN KEY", SourceColumn: "user_id", TargetTable: "users", TargetColumn: "id"},
+		},
+	}}
+
+	ddl, err := RenderSchemaDDL(tables, DialectSQLite)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(ddl, "-- skipped foreign key (user_id) on orders: table users is not in the schema\n") {
+		t.Errorf("Expected a skipped foreign key comment, got\n%s", ddl)
+	}
+	if strings.Contains(ddl, "REFERENCES") {
+		t.Errorf("Expected no reference to the missing table, got\n%s", ddl)
+	}
+}
+
+func TestRenderSchemaDDLUnsupportedDialect(t *testing.T) {
+	if _, err := RenderSchemaDDL(nil, "oracle"); err == nil {
+		t.Errorf("Expected an error for oracle")
+	}
+}
+
+func TestTableForeignKeysMergesCompositeConstraints(t *testing.T) {
+	table := types.TableInfo{
+		Constraints: []types.Constraint{
+			{Name: "fk_0", Type: "FOREIGN KEY", Columns: []string{"order_id"}, RefTable: "order_lines", RefColumns: []string{"order_id"}},
+			{Name: "fk_0", Type: "FOREIGN KEY", Columns: []string{"line_no"}, RefTable: "order_lines", RefColumns: []string{"line_no"}},
+			{Name: "pk", Type: "PRIMARY KEY", Columns: []string{"id"}},
+		},
+		Relationships: []types.Relationship{
+			{Type: "FOREIGN KEY", SourceColumn: "order_id", TargetTable: "order_lines", TargetColumn: "order_id"},
+			{Type: "FOREIGN KEY", SourceColumn: "product_id", TargetTable: "products", TargetColumn: "id"},
+		},
+	}
+
+	keys := tableForeignKeys(table)
+	if len(keys) != 2 {
+		t.Fatalf("Expected 2 foreign keys, got %+v", keys)
+	}
+	if got := strings.Join(keys[0].columns, ","); got != "order_id,line_no" {
+		t.Errorf("Expected order_id,line_no, got %s", got)
+	}
+	if keys[1].refTable != "products" {
+		t.Errorf("Expected the uncovered relationship to be added, got %+v", keys[1])
+	}
+}
+
+func TestDefaultExpression(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+		ok    bool
+	}{
+		{nil, "", false},
+		{"nextval('users_id_seq'::regclass)", "", false},
+		{"  ", "", false},
+		{false, "FALSE", true},
+		{[]byte("42"), "42", true},
+		{"'draft'::character varying", "'draft'::character varying", true},
+		{"now()", "(now())", true},
+		{"NULL", "NULL", true},
+		{"it's", "'it''s'", true},
+		{3.5, "3.5", true},
+	}
+	for _, tt := range tests {
+		got, ok := defaultExpression(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Expected %q, %v for %#v, got %q, %v", tt.want, tt.ok, tt.value, got, ok)
+		}
+	}
+}
+
+func TestColumnType(t *testing.T) {
+	tests := []struct {
+		column  types.ColumnInfo
+		dialect string
+		want    string
+	}{
+		{types.ColumnInfo{DataType: ""}, DialectPostgres, "TEXT"},
+		{types.ColumnInfo{DataType: "double precision"}, DialectMySQL, "DOUBLE"},
+		{types.ColumnInfo{DataType: "bytea"}, DialectSQLite, "BLOB"},
+		{types.ColumnInfo{DataType: "blob"}, DialectPostgres, "BYTEA"},
+		{types.ColumnInfo{DataType: "datetime"}, DialectPostgres, "TIMESTAMP"},
+		{types.ColumnInfo{DataType: "varchar(64)"}, DialectMySQL, "VARCHAR(64)"},
+		{types.ColumnInfo{DataType: "varchar"}, DialectMySQL, "VARCHAR(255)"},
+		{types.ColumnInfo{DataType: "character", MaxLength: 2}, DialectPostgres, "CHAR(2)"},
+	}
+	for _, tt := range tests {
+		if got := columnType(tt.column, tt.dialect); got != tt.want {
+			t.Errorf("Expected %s for %q in %s, got %s", tt.want, tt.column.DataType, tt.dialect, got)
+		}
+	}
+}
+
+func TestQuoteDDLIdentifier(t *testing.T) {
+	if got := quoteDDLIdentifier(DialectMySQL, "we`ird"); got != "`we``ird`" {
+		t.Errorf("Expected `we``ird`, got %s", got)
+	}
+	if got := quoteDDLIdentifier(DialectPostgres, `we"ird`); got != `"we""ird"` {
+		t.Errorf(`Expected "we""ird", got %s`, got)
+	}
+}
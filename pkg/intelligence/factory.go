@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/cherry-pick/pkg/analyzer"
 	"github.com/cherry-pick/pkg/config"
@@ -16,15 +17,22 @@ import (
 )
 
 type ServiceBuilder struct {
-	driverName     string
-	dataSourceName string
-	configPath     string
+	driverName       string
+	dataSourceName   string
+	configPath       string
+	breakerThreshold int
+	breakerCooldown  time.Duration
+	connectTimeout   time.Duration
+	tlsConfig        *connector.TLSConfig
 }
 
 func NewServiceBuilder(driverName, dataSourceName string) *ServiceBuilder {
 	return &ServiceBuilder{
-		driverName:     driverName,
-		dataSourceName: dataSourceName,
+		driverName:       driverName,
+		dataSourceName:   dataSourceName,
+		breakerThreshold: connector.DefaultBreakerThreshold,
+		breakerCooldown:  connector.DefaultBreakerCooldown,
+		connectTimeout:   connector.DefaultConnectTimeout,
 	}
 }
 
@@ -33,12 +41,46 @@ func (sb *ServiceBuilder) WithConfig(configPath string) *ServiceBuilder {
 	return sb
 }
 
+func (sb *ServiceBuilder) WithCircuitBreaker(threshold int, cooldown time.Duration) *ServiceBuilder {
+	sb.breakerThreshold = threshold
+	sb.breakerCooldown = cooldown
+	return sb
+}
+
+// WithConnectTimeout bounds how long Build waits for the database to answer its first
+// ping; zero or less keeps connector.DefaultConnectTimeout.
+func (sb *ServiceBuilder) WithConnectTimeout(timeout time.Duration) *ServiceBuilder {
+	if timeout > 0 {
+		sb.connectTimeout = timeout
+	}
+	return sb
+}
+
+func (sb *ServiceBuilder) WithTLS(config *connector.TLSConfig) *ServiceBuilder {
+	sb.tlsConfig = config
+	return sb
+}
+
 func (sb *ServiceBuilder) Build() (*Service, error) {
+	dataSourceName, err := connector.ExpandEnv(sb.dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+
 	if strings.ToLower(sb.driverName) == "mongodb" {
-		return sb.buildMongoService()
+		return sb.buildMongoService(dataSourceName)
+	}
+
+	dataSourceName, err = connector.ApplyTLS(sb.driverName, dataSourceName, sb.tlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS configuration: %w", err)
 	}
 
-	dbConnector := connector.NewDatabaseConnector(sb.driverName, sb.dataSourceName)
+	breaker := connector.NewCircuitBreaker(sb.breakerThreshold, sb.breakerCooldown)
+	dbConnector := connector.NewBreakerConnector(
+		connector.NewDatabaseConnectorWithTimeout(sb.driverName, dataSourceName, sb.connectTimeout),
+		breaker,
+	)
 	if err := dbConnector.Connect(); err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -47,9 +89,7 @@ func (sb *ServiceBuilder) Build() (*Service, error) {
 
 	insightGenerator := insights.NewInsightGenerator()
 	reportGenerator := insights.NewReportGenerator()
-	securityAnalyzer := security.NewSecurityAnalyzer(dbAnalyzer)
-	queryOptimizer := optimization.NewQueryOptimizer()
-	alertManager := monitoring.NewAlertManager()
+	queryOptimizer := optimization.NewQueryOptimizerWithDialect(sb.driverName)
 	comparisonEngine := monitoring.NewComparisonEngine()
 	lineageTracker := monitoring.NewDataLineageTracker(dbAnalyzer)
 	scheduler := monitoring.NewScheduler(dbAnalyzer, reportGenerator, insightGenerator)
@@ -59,6 +99,16 @@ func (sb *ServiceBuilder) Build() (*Service, error) {
 		return nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
 
+	securityAnalyzer, err := security.NewSecurityAnalyzerWithSettings(dbAnalyzer, configManager.GetConfig().SecuritySettings)
+	if err != nil {
+		return nil, fmt.Errorf("invalid security settings: %w", err)
+	}
+
+	alertManager, err := monitoring.NewAlertManagerWithRules(configManager.GetConfig().AlertSettings.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alert rules: %w", err)
+	}
+
 	service := NewService(
 		dbConnector,
 		dbAnalyzer,
@@ -77,10 +127,10 @@ func (sb *ServiceBuilder) Build() (*Service, error) {
 	return service, nil
 }
 
-func (sb *ServiceBuilder) buildMongoService() (*Service, error) {
+func (sb *ServiceBuilder) buildMongoService(dataSourceName string) (*Service, error) {
 	databaseName := "test"
-	if strings.Contains(sb.dataSourceName, "/") {
-		parts := strings.Split(sb.dataSourceName, "/")
+	if strings.Contains(dataSourceName, "/") {
+		parts := strings.Split(dataSourceName, "/")
 		if len(parts) > 3 {
 			dbPart := parts[3]
 			if strings.Contains(dbPart, "?") {
@@ -91,8 +141,16 @@ func (sb *ServiceBuilder) buildMongoService() (*Service, error) {
 		}
 	}
 
-	mongoConnector := connector.NewMongoConnector(sb.dataSourceName, databaseName)
-	ctx := context.Background()
+	mongoConnector := connector.NewMongoConnector(dataSourceName, databaseName)
+	if sb.tlsConfig != nil {
+		tlsConfig, err := sb.tlsConfig.Build()
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS configuration: %w", err)
+		}
+		mongoConnector = connector.NewMongoConnectorWithTLS(dataSourceName, databaseName, tlsConfig)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sb.connectTimeout)
+	defer cancel()
 	if err := mongoConnector.Connect(ctx); err != nil {
 		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
@@ -0,0 +1,134 @@
+package intelligence
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cherry-pick/pkg/insights"
+	"github.com/cherry-pick/pkg/types"
+)
+
+// MaxRelationshipSample caps the source values checked per candidate, whatever the
+// configured sample size, so each check is a single indexed lookup on the target key.
+const MaxRelationshipSample = 200
+
+// DetectImplicitRelationships suggests undeclared foreign keys: columns whose name and
+// type match another table's primary key and whose sampled values are almost all
+// present in that key. Results are ordered by confidence, highest first.
+func (s *Service) DetectImplicitRelationships() ([]types.CandidateRelationship, error) {
+	if s.mongoService != nil {
+		return nil, fmt.Errorf("implicit relationship detection is not supported for MongoDB")
+	}
+
+	var tables []types.TableInfo
+	err := s.guard(func() error {
+		var err error
+		tables, err = s.analyzer.AnalyzeTables()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze tables: %w", err)
+	}
+
+	sampleSize := MaxRelationshipSample
+	if s.config != nil {
+		if configured := s.config.GetConfig().AnalysisSettings.SampleSize; configured > 0 && configured < sampleSize {
+			sampleSize = configured
+		}
+	}
+
+	driverName := s.connector.GetDatabaseType()
+	found := []types.CandidateRelationship{}
+	for _, candidate := range insights.FindRelationshipCandidates(tables) {
+		var sampled, matched int
+		err := s.guard(func() error {
+			var err error
+			sampled, matched, err = sampleContainment(s.connector.GetDB(), driverName, candidate, sampleSize)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to sample %s.%s: %w", candidate.SourceTable, candidate.SourceColumn, err)
+		}
+
+		if insights.ScoreRelationship(&candidate, sampled, matched) {
+			found = append(found, candidate)
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].Confidence > found[j].Confidence
+	})
+	return found, nil
+}
+
+// sampleContainment reads up to limit non-null source values and counts how many of the
+// distinct ones exist in the target column.
+func sampleContainment(db *sql.DB, driverName string, candidate types.CandidateRelationship, limit int) (int, int, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s IS NOT NULL LIMIT %d",
+		quoteIdentifier(driverName, candidate.SourceColumn),
+		quoteIdentifier(driverName, candidate.SourceTable),
+		quoteIdentifier(driverName, candidate.SourceColumn),
+		limit)
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer rows.Close()
+
+	seen := make(map[string]bool)
+	values := []interface{}{}
+	for rows.Next() {
+		var value sql.NullString
+		if err := rows.Scan(&value); err != nil {
+			return 0, 0, err
+		}
+		if !value.Valid || seen[value.String] {
+			continue
+		}
+		seen[value.String] = true
+		values = append(values, value.String)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+	if len(values) == 0 {
+		return 0, 0, nil
+	}
+
+	placeholders := make([]string, len(values))
+	for i := range values {
+		placeholders[i] = placeholder(driverName, i+1)
+	}
+
+	// Values are bound as text; each driver coerces them to the key's type, so the
+	// lookup still uses the primary key index.
+	target := quoteIdentifier(driverName, candidate.TargetColumn)
+	countQuery := fmt.Sprintf("SELECT COUNT(DISTINCT %s) FROM %s WHERE %s IN (%s)",
+		target,
+		quoteIdentifier(driverName, candidate.TargetTable),
+		target,
+		strings.Join(placeholders, ", "))
+
+	var matched int
+	if err := db.QueryRow(countQuery, values...).Scan(&matched); err != nil {
+		return 0, 0, err
+	}
+	return len(values), matched, nil
+}
+
+func quoteIdentifier(driverName, name string) string {
+	if strings.EqualFold(driverName, "mysql") {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func placeholder(driverName string, position int) string {
+	if strings.EqualFold(driverName, "postgres") {
+		return fmt.Sprintf("$%d", position)
+	}
+	return "?"
+}
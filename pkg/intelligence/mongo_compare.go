@@ -0,0 +1,149 @@
+package intelligence
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+const DefaultCompareSampleSize = 1000
+
+// CompareCollections samples the schema of both collections and reports the fields and
+// indexes each has that the other lacks, fields whose types differ, and a similarity score.
+func (ms *MongoService) CompareCollections(ctx context.Context, collectionA, collectionB string) (*types.CollectionComparison, error) {
+	names, err := ms.analyzer.GetCollectionNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	existing := make(map[string]bool, len(names))
+	for _, name := range names {
+		existing[name] = true
+	}
+	for _, name := range []string{collectionA, collectionB} {
+		if !existing[name] {
+			return nil, types.NewAPIError(types.ErrCodeNotFound, fmt.Sprintf("collection %q not found", name))
+		}
+	}
+
+	sampleSize := DefaultCompareSampleSize
+	if configured := ms.config.GetConfig().AnalysisSettings.SampleSize; configured > 0 {
+		sampleSize = configured
+	}
+
+	fieldsA, err := ms.analyzer.AnalyzeSchema(ctx, collectionA, sampleSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze schema of %s: %w", collectionA, err)
+	}
+	fieldsB, err := ms.analyzer.AnalyzeSchema(ctx, collectionB, sampleSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze schema of %s: %w", collectionB, err)
+	}
+
+	indexesA, err := ms.analyzer.GetIndexes(ctx, collectionA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get indexes of %s: %w", collectionA, err)
+	}
+	indexesB, err := ms.analyzer.GetIndexes(ctx, collectionB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get indexes of %s: %w", collectionB, err)
+	}
+
+	comparison := CompareCollectionSchemas(fieldsA, fieldsB, indexesA, indexesB)
+	comparison.CollectionA = collectionA
+	comparison.CollectionB = collectionB
+	comparison.SampleSize = sampleSize
+	return comparison, nil
+}
+
+// CompareCollectionSchemas diffs two sampled schemas. Indexes are matched on their keys
+// and options rather than their names.
+func CompareCollectionSchemas(fieldsA, fieldsB []types.MongoFieldInfo, indexesA, indexesB []types.MongoIndexInfo) *types.CollectionComparison {
+	comparison := &types.CollectionComparison{
+		FieldsOnlyInA:  []string{},
+		FieldsOnlyInB:  []string{},
+		TypeMismatches: []types.FieldTypeMismatch{},
+		IndexesOnlyInA: []types.MongoIndexInfo{},
+		IndexesOnlyInB: []types.MongoIndexInfo{},
+	}
+
+	typesB := make(map[string]string, len(fieldsB))
+	for _, field := range fieldsB {
+		typesB[field.Name] = field.Type
+	}
+
+	var matched float64
+	seenA := make(map[string]bool, len(fieldsA))
+	for _, field := range fieldsA {
+		seenA[field.Name] = true
+		typeB, ok := typesB[field.Name]
+		switch {
+		case !ok:
+			comparison.FieldsOnlyInA = append(comparison.FieldsOnlyInA, field.Name)
+		case typeB != field.Type:
+			comparison.TypeMismatches = append(comparison.TypeMismatches, types.FieldTypeMismatch{
+				Field: field.Name,
+				TypeA: field.Type,
+				TypeB: typeB,
+			})
+			matched += 0.5
+		default:
+			matched++
+		}
+	}
+	for _, field := range fieldsB {
+		if !seenA[field.Name] {
+			comparison.FieldsOnlyInB = append(comparison.FieldsOnlyInB, field.Name)
+		}
+	}
+
+	signaturesB := make(map[string]bool, len(indexesB))
+	for _, index := range indexesB {
+		signaturesB[indexSignature(index)] = true
+	}
+	signaturesA := make(map[string]bool, len(indexesA))
+	for _, index := range indexesA {
+		signature := indexSignature(index)
+		signaturesA[signature] = true
+		if signaturesB[signature] {
+			matched++
+		} else {
+			comparison.IndexesOnlyInA = append(comparison.IndexesOnlyInA, index)
+		}
+	}
+	for _, index := range indexesB {
+		if !signaturesA[indexSignature(index)] {
+			comparison.IndexesOnlyInB = append(comparison.IndexesOnlyInB, index)
+		}
+	}
+
+	sort.Strings(comparison.FieldsOnlyInA)
+	sort.Strings(comparison.FieldsOnlyInB)
+	sort.Slice(comparison.TypeMismatches, func(i, j int) bool {
+		return comparison.TypeMismatches[i].Field < comparison.TypeMismatches[j].Field
+	})
+
+	total := len(fieldsA) + len(comparison.FieldsOnlyInB) +
+		len(indexesA) + len(comparison.IndexesOnlyInB)
+	if total == 0 {
+		comparison.Similarity = 1
+	} else {
+		comparison.Similarity = matched / float64(total)
+	}
+
+	return comparison
+}
+
+// indexSignature identifies an index by its keys and options. Keys come back as a map,
+// so their order is lost and compound indexes differing only in key order compare equal.
+func indexSignature(index types.MongoIndexInfo) string {
+	keys := make([]string, 0, len(index.Keys))
+	for field, direction := range index.Keys {
+		keys = append(keys, fmt.Sprintf("%s:%v", field, direction))
+	}
+	sort.Strings(keys)
+	return fmt.Sprintf("%s|unique=%t|sparse=%t|partial=%t",
+		strings.Join(keys, ","), index.IsUnique, index.IsSparse, index.IsPartial)
+}
@@ -0,0 +1,180 @@
+package intelligence
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/cherry-pick/pkg/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	DefaultFieldSizeSample = 1000
+
+	// A field holding at least this share of sampled bytes is reported as dominating storage.
+	DominantFieldShare = 0.5
+)
+
+// AnalyzeFieldSizes estimates the storage taken by each top-level field from a sample of
+// documents, largest first. It uses $bsonSize (MongoDB 4.4+) and falls back to measuring
+// sampled documents client-side on older servers.
+func (ms *MongoService) AnalyzeFieldSizes(ctx context.Context, collectionName string) ([]types.FieldSizeStat, error) {
+	stats, _, err := ms.analyzeFieldSizes(ctx, collectionName, DefaultFieldSizeSample)
+	return stats, err
+}
+
+// FieldSizeReport wraps AnalyzeFieldSizes with the sample details and any insights.
+func (ms *MongoService) FieldSizeReport(ctx context.Context, collectionName string) (*types.FieldSizeReport, error) {
+	stats, method, err := ms.analyzeFieldSizes(ctx, collectionName, DefaultFieldSizeSample)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &types.FieldSizeReport{
+		Collection: collectionName,
+		Method:     method,
+		Fields:     stats,
+		Insights:   FieldSizeInsights(collectionName, stats),
+	}
+	for _, stat := range stats {
+		if stat.Occurrences > report.SampledDocs {
+			report.SampledDocs = stat.Occurrences
+		}
+	}
+	return report, nil
+}
+
+func (ms *MongoService) analyzeFieldSizes(ctx context.Context, collectionName string, sampleSize int) ([]types.FieldSizeStat, string, error) {
+	collection := ms.connector.GetDatabase("").Collection(collectionName)
+
+	totals, counts, err := fieldSizesByAggregation(ctx, collection, sampleSize)
+	method := "bsonSize"
+	if err != nil {
+		totals, counts, err = fieldSizesBySampling(ctx, collection, sampleSize)
+		method = "sample"
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to measure field sizes for %s: %w", collectionName, err)
+	}
+
+	return rankFieldSizes(totals, counts), method, nil
+}
+
+func fieldSizesByAggregation(ctx context.Context, collection *mongo.Collection, sampleSize int) (map[string]int64, map[string]int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$sample", Value: bson.D{{Key: "size", Value: sampleSize}}}},
+		{{Key: "$project", Value: bson.D{{Key: "fields", Value: bson.D{{Key: "$objectToArray", Value: "$$ROOT"}}}}}},
+		{{Key: "$unwind", Value: "$fields"}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$fields.k"},
+			{Key: "bytes", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$bsonSize", Value: bson.D{
+				{Key: "$arrayToObject", Value: bson.A{bson.A{"$fields"}}},
+			}}}}}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cursor.Close(ctx)
+
+	totals := make(map[string]int64)
+	counts := make(map[string]int64)
+	for cursor.Next(ctx) {
+		var row struct {
+			Field string `bson:"_id"`
+			Bytes int64  `bson:"bytes"`
+			Count int64  `bson:"count"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, nil, err
+		}
+		totals[row.Field] = row.Bytes
+		counts[row.Field] = row.Count
+	}
+	return totals, counts, cursor.Err()
+}
+
+func fieldSizesBySampling(ctx context.Context, collection *mongo.Collection, sampleSize int) (map[string]int64, map[string]int64, error) {
+	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetLimit(int64(sampleSize)))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cursor.Close(ctx)
+
+	totals := make(map[string]int64)
+	counts := make(map[string]int64)
+	for cursor.Next(ctx) {
+		elements, err := cursor.Current.Elements()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, element := range elements {
+			key := element.Key()
+			totals[key] += int64(len(element))
+			counts[key]++
+		}
+	}
+	return totals, counts, cursor.Err()
+}
+
+func rankFieldSizes(totals, counts map[string]int64) []types.FieldSizeStat {
+	var grandTotal int64
+	for _, bytes := range totals {
+		grandTotal += bytes
+	}
+
+	stats := make([]types.FieldSizeStat, 0, len(totals))
+	for field, bytes := range totals {
+		stat := types.FieldSizeStat{
+			Field:       field,
+			TotalBytes:  bytes,
+			Occurrences: counts[field],
+		}
+		if stat.Occurrences > 0 {
+			stat.AvgBytes = float64(bytes) / float64(stat.Occurrences)
+		}
+		if grandTotal > 0 {
+			stat.Share = float64(bytes) / float64(grandTotal)
+		}
+		stats = append(stats, stat)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].TotalBytes != stats[j].TotalBytes {
+			return stats[i].TotalBytes > stats[j].TotalBytes
+		}
+		return stats[i].Field < stats[j].Field
+	})
+	return stats
+}
+
+// FieldSizeInsights flags a field that accounts for a disproportionate share of storage,
+// typically a large embedded array or binary blob.
+func FieldSizeInsights(collectionName string, stats []types.FieldSizeStat) []types.DatabaseInsight {
+	if len(stats) < 2 || stats[0].Share < DominantFieldShare {
+		return nil
+	}
+
+	top := stats[0]
+	severity := "medium"
+	if top.Share >= 0.8 {
+		severity = "high"
+	}
+
+	return []types.DatabaseInsight{{
+		Type:     "storage",
+		Severity: severity,
+		Title:    "Single Field Dominates Document Size",
+		Description: fmt.Sprintf("Field '%s' accounts for %.0f%% of sampled storage in '%s' (avg %.0f bytes per document)",
+			top.Field, top.Share*100, collectionName, top.AvgBytes),
+		Suggestion:     "Consider moving large arrays or blobs to a separate collection or GridFS, or projecting the field out of frequent queries",
+		AffectedTables: []string{collectionName},
+		MetricValue:    top.Share,
+	}}
+}
@@ -9,6 +9,8 @@ import (
 
 	"github.com/cherry-pick/pkg/config"
 	"github.com/cherry-pick/pkg/interfaces"
+	"github.com/cherry-pick/pkg/logging"
+	"github.com/cherry-pick/pkg/monitoring"
 	"github.com/cherry-pick/pkg/types"
 )
 
@@ -16,6 +18,7 @@ type MongoService struct {
 	connector interfaces.MongoConnector
 	analyzer  interfaces.MongoAnalyzer
 	config    interfaces.ConfigManager
+	rules     *monitoring.AlertRuleSet
 }
 
 func NewMongoService(
@@ -28,10 +31,16 @@ func NewMongoService(
 		fmt.Printf("Warning: Failed to load configuration: %v\n", err)
 	}
 
+	rules, err := monitoring.NewAlertRuleSet(configManager.GetConfig().AlertSettings.Rules)
+	if err != nil {
+		logging.Default().Warn("Ignoring invalid alert rules", "error", err)
+	}
+
 	mongoService := &MongoService{
 		connector: connector,
 		analyzer:  analyzer,
 		config:    configManager,
+		rules:     rules,
 	}
 
 	return &Service{
@@ -169,6 +178,8 @@ func (ms *MongoService) CheckAlerts(ctx context.Context) ([]types.MonitoringAler
 		}
 	}
 
+	alerts = append(alerts, ms.rules.Evaluate(report)...)
+
 	return alerts, nil
 }
 
@@ -2,11 +2,17 @@ package intelligence
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
+	"github.com/cherry-pick/pkg/connector"
+	"github.com/cherry-pick/pkg/insights"
 	"github.com/cherry-pick/pkg/interfaces"
+	"github.com/cherry-pick/pkg/monitoring"
+	"github.com/cherry-pick/pkg/security"
 	"github.com/cherry-pick/pkg/types"
 )
 
@@ -24,6 +30,9 @@ type Service struct {
 	config       interfaces.ConfigManager
 	performance  interfaces.PerformanceAnalyzer
 	mongoService *MongoService
+
+	alertsMu     sync.RWMutex
+	activeAlerts []types.MonitoringAlert
 }
 
 func NewService(
@@ -64,19 +73,175 @@ func (s *Service) AnalyzeDatabase() (*types.DatabaseReport, error) {
 
 	log.Println("Starting comprehensive database analysis...")
 
-	dbName, err := s.connector.GetDatabaseName()
+	dbName, err := s.databaseName()
 	if err != nil {
-		log.Printf("Warning: Could not determine database name: %v", err)
-		dbName = "Unknown"
+		return nil, err
 	}
 
-	dbType := s.connector.GetDatabaseType()
-
-	tables, err := s.analyzer.AnalyzeTables()
+	var tables []types.TableInfo
+	err = s.guard(func() error {
+		var err error
+		tables, err = s.analyzer.AnalyzeTables()
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to analyze tables: %w", err)
 	}
 
+	report := s.buildReport(dbName, tables)
+
+	log.Println("Database analysis completed successfully")
+	return report, nil
+}
+
+// AnalyzeDatabaseStream hands each table to emit as soon as it is analyzed, then returns the full report.
+func (s *Service) AnalyzeDatabaseStream(emit func(types.TableInfo) error) (*types.DatabaseReport, error) {
+	if s.mongoService != nil {
+		report, err := s.mongoService.AnalyzeDatabase(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		for _, table := range report.Tables {
+			if err := emit(table); err != nil {
+				return nil, err
+			}
+		}
+		return report, nil
+	}
+
+	log.Println("Starting streaming database analysis...")
+
+	dbName, err := s.databaseName()
+	if err != nil {
+		return nil, err
+	}
+
+	var tableNames []string
+	err = s.guard(func() error {
+		var err error
+		tableNames, err = s.analyzer.GetTableNames()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table names: %w", err)
+	}
+
+	tables := make([]types.TableInfo, 0, len(tableNames))
+	for _, tableName := range tableNames {
+		var table types.TableInfo
+		err := s.guard(func() error {
+			var err error
+			table, err = s.analyzer.AnalyzeTable(tableName)
+			return err
+		})
+		if errors.Is(err, connector.ErrCircuitOpen) {
+			return nil, err
+		}
+		if err != nil {
+			log.Printf("Warning: Failed to analyze table %s: %v", tableName, err)
+			continue
+		}
+
+		if err := emit(table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+
+	report := s.buildReport(dbName, tables)
+
+	log.Println("Streaming database analysis completed successfully")
+	return report, nil
+}
+
+// AnalyzeDatabaseContext is AnalyzeDatabase that stops once ctx is done. Tables are
+// analyzed one at a time. The SQL analyzer's queries take no context, so a cancelled
+// analysis returns at once and leaves the table in progress to finish in the background.
+func (s *Service) AnalyzeDatabaseContext(ctx context.Context) (*types.DatabaseReport, error) {
+	if s.mongoService != nil {
+		return s.mongoService.AnalyzeDatabase(ctx)
+	}
+
+	log.Println("Starting cancellable database analysis...")
+
+	dbName, err := s.databaseName()
+	if err != nil {
+		return nil, err
+	}
+
+	var tableNames []string
+	err = s.guard(func() error {
+		var err error
+		tableNames, err = s.analyzer.GetTableNames()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table names: %w", err)
+	}
+
+	tables := make([]types.TableInfo, 0, len(tableNames))
+	for _, tableName := range tableNames {
+		table, err := s.analyzeTableContext(ctx, tableName)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("analysis cancelled after %d of %d tables: %w", len(tables), len(tableNames), ctx.Err())
+		}
+		if errors.Is(err, connector.ErrCircuitOpen) {
+			return nil, err
+		}
+		if err != nil {
+			log.Printf("Warning: Failed to analyze table %s: %v", tableName, err)
+			continue
+		}
+		tables = append(tables, table)
+	}
+
+	report := s.buildReport(dbName, tables)
+
+	log.Println("Database analysis completed successfully")
+	return report, nil
+}
+
+func (s *Service) analyzeTableContext(ctx context.Context, tableName string) (types.TableInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return types.TableInfo{}, err
+	}
+
+	type result struct {
+		table types.TableInfo
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		var r result
+		r.err = s.guard(func() error {
+			var err error
+			r.table, err = s.analyzer.AnalyzeTable(tableName)
+			return err
+		})
+		done <- r
+	}()
+
+	select {
+	case r := <-done:
+		return r.table, r.err
+	case <-ctx.Done():
+		return types.TableInfo{}, ctx.Err()
+	}
+}
+
+func (s *Service) databaseName() (string, error) {
+	dbName, err := s.connector.GetDatabaseName()
+	if errors.Is(err, connector.ErrCircuitOpen) {
+		return "", err
+	}
+	if err != nil {
+		log.Printf("Warning: Could not determine database name: %v", err)
+		dbName = "Unknown"
+	}
+	return dbName, nil
+}
+
+func (s *Service) buildReport(dbName string, tables []types.TableInfo) *types.DatabaseReport {
 	insights := s.insights.GenerateInsights(tables)
 	summary := s.reporter.GenerateSummary(tables)
 	recommendations := s.reporter.GenerateRecommendations(tables, insights)
@@ -86,9 +251,22 @@ func (s *Service) AnalyzeDatabase() (*types.DatabaseReport, error) {
 		performanceMetrics = s.performance.AnalyzePerformance()
 	}
 
-	report := &types.DatabaseReport{
+	if tables == nil {
+		tables = []types.TableInfo{}
+	}
+	if insights == nil {
+		insights = []types.DatabaseInsight{}
+	}
+	if recommendations == nil {
+		recommendations = []string{}
+	}
+	if s.optimizer != nil {
+		s.optimizer.SetTableStats(tables)
+	}
+
+	return &types.DatabaseReport{
 		DatabaseName:       dbName,
-		DatabaseType:       dbType,
+		DatabaseType:       s.connector.GetDatabaseType(),
 		AnalysisTime:       time.Now(),
 		Summary:            summary,
 		Tables:             tables,
@@ -96,9 +274,6 @@ func (s *Service) AnalyzeDatabase() (*types.DatabaseReport, error) {
 		Recommendations:    recommendations,
 		PerformanceMetrics: performanceMetrics,
 	}
-
-	log.Println("Database analysis completed successfully")
-	return report, nil
 }
 
 func (s *Service) AnalyzeSecurity() ([]types.SecurityIssue, error) {
@@ -106,7 +281,79 @@ func (s *Service) AnalyzeSecurity() ([]types.SecurityIssue, error) {
 		ctx := context.Background()
 		return s.mongoService.AnalyzeSecurity(ctx)
 	}
-	return s.security.AnalyzeSecurity()
+	var issues []types.SecurityIssue
+	err := s.guard(func() error {
+		var err error
+		issues, err = s.security.AnalyzeSecurity()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	findings, err := s.AnalyzeAccessControl()
+	if err != nil {
+		issues = append(issues, types.SecurityIssue{
+			Type:            "scan_coverage",
+			Severity:        "info",
+			Title:           "Access Control Not Audited",
+			Description:     fmt.Sprintf("Roles and grants could not be read: %v", err),
+			Recommendation:  "Run the security scan as a user that can read pg_roles and information_schema, or mysql.user and SHOW GRANTS, to audit access control",
+			AffectedObjects: []string{},
+		})
+		return issues, nil
+	}
+	return append(issues, security.AccessFindingIssues(findings)...), nil
+}
+
+// AnalyzeAccessControl reports superuser accounts, grants to everyone and all-privilege
+// grants. It returns no findings for databases other than Postgres and MySQL, and
+// security.ErrAccessControlDenied when the connected user cannot read roles and grants.
+func (s *Service) AnalyzeAccessControl() ([]types.AccessFinding, error) {
+	if s.mongoService != nil {
+		return nil, nil
+	}
+
+	var findings []types.AccessFinding
+	var denied error
+	err := s.guard(func() error {
+		var err error
+		findings, err = security.AnalyzeAccessControl(s.connector.GetDB(), s.connector.GetDatabaseType())
+		if errors.Is(err, security.ErrAccessControlDenied) {
+			// The database answered; a missing privilege should not trip the breaker.
+			denied = err
+			return nil
+		}
+		return err
+	})
+	if denied != nil {
+		return nil, denied
+	}
+	return findings, err
+}
+
+// FindRedundantIndexes analyzes every table and reports indexes that duplicate, or are a
+// leading prefix of, another index on the same table.
+func (s *Service) FindRedundantIndexes() ([]types.RedundantIndexFinding, error) {
+	if s.mongoService != nil {
+		report, err := s.mongoService.AnalyzeDatabase(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze collections: %w", err)
+		}
+		return insights.FindRedundantIndexes(report.Tables, "mongodb"), nil
+	}
+
+	var tables []types.TableInfo
+	err := s.guard(func() error {
+		var err error
+		tables, err = s.analyzer.AnalyzeTables()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze tables: %w", err)
+	}
+
+	return insights.FindRedundantIndexes(tables, s.connector.GetDatabaseType()), nil
 }
 
 func (s *Service) OptimizeQuery(query string) (*types.OptimizationSuggestion, error) {
@@ -116,22 +363,51 @@ func (s *Service) OptimizeQuery(query string) (*types.OptimizationSuggestion, er
 	return s.optimizer.AnalyzeQuery(query)
 }
 
+// RecommendIndexes proposes composite and partial indexes for the filter and sort
+// patterns in queries, such as those taken from a slow query log.
+func (s *Service) RecommendIndexes(queries []types.SlowQuery) ([]types.IndexRecommendation, error) {
+	if s.mongoService != nil {
+		return nil, fmt.Errorf("index recommendations from queries are not supported for MongoDB")
+	}
+	return s.optimizer.RecommendIndexes(queries), nil
+}
+
 func (s *Service) GetMongoService() *MongoService {
 	return s.mongoService
 }
 
+// CheckAlerts analyzes the database, evaluates the alert rules against it and keeps the
+// triggered alerts for ActiveAlerts.
 func (s *Service) CheckAlerts() ([]types.MonitoringAlert, error) {
+	var alerts []types.MonitoringAlert
 	if s.mongoService != nil {
-		ctx := context.Background()
-		return s.mongoService.CheckAlerts(ctx)
+		var err error
+		if alerts, err = s.mongoService.CheckAlerts(context.Background()); err != nil {
+			return nil, err
+		}
+	} else {
+		report, err := s.AnalyzeDatabase()
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze database for alerts: %w", err)
+		}
+		alerts = s.alerts.CheckAlerts(report)
 	}
 
-	report, err := s.AnalyzeDatabase()
-	if err != nil {
-		return nil, fmt.Errorf("failed to analyze database for alerts: %w", err)
-	}
+	s.alertsMu.Lock()
+	s.activeAlerts = alerts
+	s.alertsMu.Unlock()
+	return alerts, nil
+}
 
-	return s.alerts.CheckAlerts(report), nil
+// ActiveAlerts returns the alerts triggered by the last CheckAlerts, without analyzing
+// the database again. It is empty until alerts have been checked once.
+func (s *Service) ActiveAlerts() []types.MonitoringAlert {
+	s.alertsMu.RLock()
+	defer s.alertsMu.RUnlock()
+
+	alerts := make([]types.MonitoringAlert, len(s.activeAlerts))
+	copy(alerts, s.activeAlerts)
+	return alerts
 }
 
 func (s *Service) CompareReports(oldReport, newReport *types.DatabaseReport) *types.ComparisonReport {
@@ -143,7 +419,45 @@ func (s *Service) TrackLineage() (map[string]types.DataLineage, error) {
 		ctx := context.Background()
 		return s.mongoService.TrackLineage(ctx)
 	}
-	return s.lineage.TrackLineage()
+	var lineage map[string]types.DataLineage
+	err := s.guard(func() error {
+		var err error
+		lineage, err = s.lineage.TrackLineage()
+		return err
+	})
+	return lineage, err
+}
+
+func (s *Service) ExportLineageGraph(format string) ([]byte, error) {
+	lineage, err := s.TrackLineage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to track lineage: %w", err)
+	}
+
+	return monitoring.RenderLineageGraph(lineage, format)
+}
+
+// ExportSchemaDDL analyzes every table and reconstructs the schema as CREATE statements
+// for dialect, or for the connection's own database when dialect is empty.
+func (s *Service) ExportSchemaDDL(dialect string) (string, error) {
+	if s.mongoService != nil {
+		return "", fmt.Errorf("schema DDL export is not supported for MongoDB")
+	}
+	if dialect == "" {
+		dialect = s.connector.GetDatabaseType()
+	}
+
+	var tables []types.TableInfo
+	err := s.guard(func() error {
+		var err error
+		tables, err = s.analyzer.AnalyzeTables()
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to analyze tables: %w", err)
+	}
+
+	return insights.RenderSchemaDDL(tables, dialect)
 }
 
 func (s *Service) ScheduleAnalysis(interval time.Duration, callback func(*types.DatabaseReport)) error {
@@ -169,6 +483,71 @@ func (s *Service) UpdateConfig(config *types.Config) error {
 	return s.config.UpdateConfig(config)
 }
 
+type ServiceHealth struct {
+	Status       string                 `json:"status"`
+	DatabaseType string                 `json:"database_type"`
+	Breaker      connector.BreakerState `json:"breaker,omitempty"`
+	Failures     int                    `json:"consecutive_failures"`
+}
+
+func (s *Service) Health() ServiceHealth {
+	health := ServiceHealth{Status: "healthy"}
+
+	if s.mongoService != nil {
+		health.DatabaseType = "mongodb"
+		return health
+	}
+
+	if s.connector != nil {
+		health.DatabaseType = s.connector.GetDatabaseType()
+	}
+
+	if bc, ok := s.connector.(*connector.BreakerConnector); ok {
+		breaker := bc.Breaker()
+		health.Breaker = breaker.State()
+		health.Failures = breaker.Failures()
+
+		switch health.Breaker {
+		case connector.BreakerOpen:
+			health.Status = "unavailable"
+		case connector.BreakerHalfOpen:
+			health.Status = "degraded"
+		}
+	}
+
+	return health
+}
+
+// Ping checks the underlying database is reachable. SQL drivers have no context-aware
+// ping here, so the deadline is enforced by abandoning the ping rather than cancelling it.
+func (s *Service) Ping(ctx context.Context) error {
+	if s.mongoService != nil {
+		return s.mongoService.GetConnector().Ping(ctx)
+	}
+	if s.connector == nil {
+		return fmt.Errorf("no database connector configured")
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.guard(s.connector.Ping)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("ping timed out: %w", ctx.Err())
+	}
+}
+
+func (s *Service) guard(fn func() error) error {
+	if bc, ok := s.connector.(*connector.BreakerConnector); ok {
+		return bc.Execute(fn)
+	}
+	return fn()
+}
+
 func (s *Service) Close() error {
 	if s.mongoService != nil {
 		ctx := context.Background()
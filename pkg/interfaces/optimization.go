@@ -6,4 +6,8 @@ type QueryOptimizer interface {
 	AnalyzeQuery(query string) (*types.OptimizationSuggestion, error)
 
 	ValidateQuery(query string) error
+
+	RecommendIndexes(queries []types.SlowQuery) []types.IndexRecommendation
+
+	SetTableStats(tables []types.TableInfo)
 }
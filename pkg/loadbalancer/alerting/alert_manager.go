@@ -2,6 +2,9 @@ package alerting
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,6 +20,9 @@ type AlertManager struct {
 	evaluator   AlertEvaluator
 }
 
+// templateVariablePattern matches a ${name} placeholder in an alert template.
+var templateVariablePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
 type NotificationService interface {
 	SendNotification(alert *core.Alert, trigger *core.AlertTrigger) error
 }
@@ -290,6 +296,86 @@ func (am *AlertManager) GetAlertTemplates() ([]*core.AlertTemplate, error) {
 	return templates, nil
 }
 
+// InstantiateAlertFromTemplate creates an alert for testID from a stored template,
+// filling its ${name} placeholders from vars.
+func (am *AlertManager) InstantiateAlertFromTemplate(templateID, testID string, vars map[string]string) (*core.Alert, error) {
+	am.mu.RLock()
+	template, exists := am.templates[templateID]
+	am.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("template with ID %s not found", templateID)
+	}
+
+	return am.InstantiateAlert(template, testID, vars)
+}
+
+// InstantiateAlert creates an alert for testID from template. Placeholders in the name,
+// description and condition are replaced from vars; a template without a condition gets
+// one from its metric, operator and threshold, where vars["threshold"] overrides the
+// threshold. The alert's metric, operator and threshold are taken from the resulting
+// condition once it validates.
+func (am *AlertManager) InstantiateAlert(template *core.AlertTemplate, testID string, vars map[string]string) (*core.Alert, error) {
+	condition := template.Condition
+	if condition == "" {
+		threshold := strconv.FormatFloat(template.Threshold, 'f', -1, 64)
+		if value, ok := vars["threshold"]; ok {
+			threshold = value
+		}
+		condition = fmt.Sprintf("%s %s %s", template.Metric, template.Operator, threshold)
+	}
+
+	condition, err := substituteTemplateVariables(condition, vars)
+	if err != nil {
+		return nil, err
+	}
+	name, err := substituteTemplateVariables(template.Name, vars)
+	if err != nil {
+		return nil, err
+	}
+	description, err := substituteTemplateVariables(template.Description, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := am.ValidateAlertCondition(condition); err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", condition, err)
+	}
+	metric, operator, threshold, _ := NewAlertEvaluator().ParseCondition(condition)
+
+	return am.CreateAlert(testID, core.AlertRequest{
+		Name:        name,
+		Description: description,
+		Condition:   condition,
+		Threshold:   threshold,
+		Operator:    operator,
+		Metric:      metric,
+		Severity:    template.Severity,
+		IsActive:    true,
+		Tags:        append([]string(nil), template.Tags...),
+	})
+}
+
+// substituteTemplateVariables replaces each ${name} in text with vars[name]. It fails
+// when a placeholder has no value, so a half-filled condition never reaches an alert.
+func substituteTemplateVariables(text string, vars map[string]string) (string, error) {
+	var missing []string
+	result := templateVariablePattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := templateVariablePattern.FindStringSubmatch(placeholder)[1]
+		value, ok := vars[name]
+		if !ok {
+			missing = append(missing, name)
+			return placeholder
+		}
+		return value
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("no value given for template variables: %s", strings.Join(missing, ", "))
+	}
+	return result, nil
+}
+
 func generateAlertID() string {
 	return fmt.Sprintf("alert_%d", time.Now().UnixNano())
 }
@@ -330,6 +416,18 @@ func (am *AlertManager) GetSupportedOperators() []string {
 	return []string{">", "<", ">=", "<=", "==", "!="}
 }
 
+// ValidateAlertCondition checks that condition parses as "<metric> <operator> <number>"
+// and names a supported metric.
 func (am *AlertManager) ValidateAlertCondition(condition string) error {
-	return nil
+	metric, _, _, err := NewAlertEvaluator().ParseCondition(condition)
+	if err != nil {
+		return err
+	}
+
+	for _, supported := range am.GetSupportedMetrics() {
+		if metric == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported metric: %s", metric)
 }
@@ -0,0 +1,54 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+)
+
+// CrawlCredentials lets a crawl reach pages behind a login. Headers, such as an
+// Authorization bearer token, are sent with every page request. Cookies, such as a
+// session cookie, seed the crawl's cookie jar for the base URL's host; cookies the site
+// sets during the crawl are kept and sent back until the crawl ends.
+type CrawlCredentials struct {
+	Headers map[string]string `json:"headers,omitempty"`
+	Cookies map[string]string `json:"cookies,omitempty"`
+}
+
+func (cc CrawlCredentials) Validate() error {
+	for name, value := range cc.Headers {
+		if name == "" || strings.ContainsAny(name, " \t\r\n:") {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("header %s: value cannot contain line breaks", name)
+		}
+	}
+	for name, value := range cc.Cookies {
+		if err := (&http.Cookie{Name: name, Value: value}).Valid(); err != nil {
+			return fmt.Errorf("cookie %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// apply sets the configured headers on req, overriding the analyzer's defaults.
+func (cc CrawlCredentials) apply(req *http.Request) {
+	for name, value := range cc.Headers {
+		req.Header.Set(name, value)
+	}
+}
+
+// newJar returns an empty cookie jar holding the configured cookies for base.
+func (cc CrawlCredentials) newJar(base *url.URL) http.CookieJar {
+	jar, _ := cookiejar.New(nil)
+
+	cookies := make([]*http.Cookie, 0, len(cc.Cookies))
+	for name, value := range cc.Cookies {
+		cookies = append(cookies, &http.Cookie{Name: name, Value: value, Path: "/"})
+	}
+	jar.SetCookies(base, cookies)
+	return jar
+}
@@ -0,0 +1,190 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultUserAgent     = "cherry-pick-crawler/1.0 (+https://github.com/paul-mothapo/cherry-pick)"
+	DefaultMaxCrawlDelay = 10 * time.Second
+	DefaultCrawlTimeout  = 2 * time.Minute
+)
+
+// CrawlPolicy identifies the crawler to the sites it visits and paces its requests.
+// HostDelays overrides MinDelay for particular hosts. A robots.txt Crawl-delay is
+// honoured when RespectRobots is set, up to MaxCrawlDelay. Timeout bounds the whole
+// crawl; 0 leaves it unbounded.
+type CrawlPolicy struct {
+	UserAgent     string                   `json:"userAgent,omitempty"`
+	From          string                   `json:"from,omitempty"`
+	RespectRobots bool                     `json:"respectRobots"`
+	MinDelay      time.Duration            `json:"minDelay,omitempty"`
+	MaxCrawlDelay time.Duration            `json:"maxCrawlDelay,omitempty"`
+	HostDelays    map[string]time.Duration `json:"hostDelays,omitempty"`
+	Timeout       time.Duration            `json:"timeout,omitempty"`
+}
+
+// DefaultCrawlPolicy identifies the tool and respects robots.txt. CRAWLER_USER_AGENT,
+// CRAWLER_FROM, CRAWLER_MIN_DELAY, CRAWLER_RESPECT_ROBOTS and CRAWLER_TIMEOUT override
+// the defaults.
+func DefaultCrawlPolicy() CrawlPolicy {
+	policy := CrawlPolicy{
+		UserAgent:     DefaultUserAgent,
+		RespectRobots: true,
+		MaxCrawlDelay: DefaultMaxCrawlDelay,
+		Timeout:       DefaultCrawlTimeout,
+	}
+	if userAgent := os.Getenv("CRAWLER_USER_AGENT"); userAgent != "" {
+		policy.UserAgent = userAgent
+	}
+	policy.From = os.Getenv("CRAWLER_FROM")
+	if delay, err := time.ParseDuration(os.Getenv("CRAWLER_MIN_DELAY")); err == nil {
+		policy.MinDelay = delay
+	}
+	if respect, err := strconv.ParseBool(os.Getenv("CRAWLER_RESPECT_ROBOTS")); err == nil {
+		policy.RespectRobots = respect
+	}
+	if timeout, err := time.ParseDuration(os.Getenv("CRAWLER_TIMEOUT")); err == nil {
+		policy.Timeout = timeout
+	}
+	return policy
+}
+
+func (cp CrawlPolicy) Validate() error {
+	if strings.ContainsAny(cp.UserAgent, "\r\n") || strings.ContainsAny(cp.From, "\r\n") {
+		return fmt.Errorf("user agent and from must be a single line")
+	}
+	if cp.From != "" && !strings.Contains(cp.From, "@") {
+		return fmt.Errorf("from must be an email address, got %q", cp.From)
+	}
+	if cp.MinDelay < 0 || cp.MaxCrawlDelay < 0 {
+		return fmt.Errorf("crawl delays cannot be negative")
+	}
+	if cp.Timeout < 0 {
+		return fmt.Errorf("crawl timeout cannot be negative")
+	}
+	for host, delay := range cp.HostDelays {
+		if delay < 0 {
+			return fmt.Errorf("crawl delay for %s cannot be negative", host)
+		}
+	}
+	return nil
+}
+
+// politeness tracks robots.txt rules and the last request time per host for one crawl.
+type politeness struct {
+	policy      CrawlPolicy
+	robots      map[string]*robotsRules
+	lastRequest map[string]time.Time
+}
+
+func newPoliteness(policy CrawlPolicy) *politeness {
+	if policy.UserAgent == "" {
+		policy.UserAgent = DefaultUserAgent
+	}
+	return &politeness{
+		policy:      policy,
+		robots:      make(map[string]*robotsRules),
+		lastRequest: make(map[string]time.Time),
+	}
+}
+
+func (p *politeness) reset() {
+	p.robots = make(map[string]*robotsRules)
+	p.lastRequest = make(map[string]time.Time)
+}
+
+func (p *politeness) identify(req *http.Request) {
+	req.Header.Set("User-Agent", p.policy.UserAgent)
+	if p.policy.From != "" {
+		req.Header.Set("From", p.policy.From)
+	}
+}
+
+// allows fetches and caches the host's robots.txt on first use. A missing file allows
+// everything; a server error or unreachable host disallows the host, as RFC 9309 asks.
+func (p *politeness) allows(ctx context.Context, client *http.Client, target *url.URL) bool {
+	if !p.policy.RespectRobots {
+		return true
+	}
+
+	rules, cached := p.robots[target.Host]
+	if !cached {
+		rules = p.fetchRobots(ctx, client, target)
+		p.robots[target.Host] = rules
+	}
+
+	path := target.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if target.RawQuery != "" {
+		path += "?" + target.RawQuery
+	}
+	return rules.allows(path)
+}
+
+func (p *politeness) fetchRobots(ctx context.Context, client *http.Client, target *url.URL) *robotsRules {
+	robotsURL := url.URL{Scheme: target.Scheme, Host: target.Host, Path: "/robots.txt"}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL.String(), nil)
+	if err != nil {
+		return disallowAllRobots()
+	}
+	p.identify(req)
+
+	if err := p.wait(ctx, target.Host); err != nil {
+		return disallowAllRobots()
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return disallowAllRobots()
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return disallowAllRobots()
+	}
+	if resp.StatusCode != http.StatusOK {
+		return allowAllRobots()
+	}
+	return parseRobots(resp.Body, p.policy.UserAgent)
+}
+
+// wait sleeps until the host's delay has passed since the previous request to it, or
+// until ctx is done.
+func (p *politeness) wait(ctx context.Context, host string) error {
+	delay := p.policy.MinDelay
+	if hostDelay, exists := p.policy.HostDelays[host]; exists {
+		delay = hostDelay
+	}
+	if rules, exists := p.robots[host]; exists && p.policy.RespectRobots {
+		crawlDelay := rules.crawlDelay
+		if p.policy.MaxCrawlDelay > 0 && crawlDelay > p.policy.MaxCrawlDelay {
+			crawlDelay = p.policy.MaxCrawlDelay
+		}
+		if crawlDelay > delay {
+			delay = crawlDelay
+		}
+	}
+
+	if last, exists := p.lastRequest[host]; exists && delay > 0 {
+		if remaining := delay - time.Since(last); remaining > 0 {
+			timer := time.NewTimer(remaining)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			}
+		}
+	}
+	p.lastRequest[host] = time.Now()
+	return nil
+}
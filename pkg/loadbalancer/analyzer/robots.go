@@ -0,0 +1,143 @@
+package analyzer
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxRobotsBytes = 512 * 1024
+
+type robotsRule struct {
+	allow   bool
+	length  int
+	pattern *regexp.Regexp
+}
+
+// robotsRules holds the robots.txt group that applies to the crawler's user-agent.
+type robotsRules struct {
+	rules       []robotsRule
+	crawlDelay  time.Duration
+	disallowAll bool
+}
+
+func allowAllRobots() *robotsRules {
+	return &robotsRules{}
+}
+
+func disallowAllRobots() *robotsRules {
+	return &robotsRules{disallowAll: true}
+}
+
+// parseRobots reads the group naming a token of userAgent, falling back to the "*"
+// group. Consecutive User-agent lines share the rules that follow them.
+func parseRobots(body io.Reader, userAgent string) *robotsRules {
+	product := strings.ToLower(userAgent)
+	if i := strings.IndexAny(product, "/ "); i >= 0 {
+		product = product[:i]
+	}
+
+	var specific, wildcard *robotsRules
+	var current []*robotsRules
+	inAgents := false
+
+	scanner := bufio.NewScanner(io.LimitReader(body, maxRobotsBytes))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if key == "user-agent" {
+			if !inAgents {
+				current = nil
+				inAgents = true
+			}
+			agent := strings.ToLower(value)
+			switch {
+			case agent == "*":
+				if wildcard == nil {
+					wildcard = &robotsRules{}
+				}
+				current = append(current, wildcard)
+			case product != "" && strings.Contains(product, agent):
+				if specific == nil {
+					specific = &robotsRules{}
+				}
+				current = append(current, specific)
+			}
+			continue
+		}
+		inAgents = false
+
+		for _, group := range current {
+			switch key {
+			case "allow", "disallow":
+				if value == "" {
+					continue
+				}
+				group.rules = append(group.rules, robotsRule{
+					allow:   key == "allow",
+					length:  len(value),
+					pattern: robotsPattern(value),
+				})
+			case "crawl-delay":
+				if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+					group.crawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		}
+	}
+
+	if specific != nil {
+		return specific
+	}
+	if wildcard != nil {
+		return wildcard
+	}
+	return allowAllRobots()
+}
+
+// robotsPattern turns a path rule into an anchored regexp, where "*" matches anything
+// and a trailing "$" pins the end of the path.
+func robotsPattern(rule string) *regexp.Regexp {
+	anchored := strings.HasSuffix(rule, "$")
+	rule = strings.TrimSuffix(rule, "$")
+
+	parts := strings.Split(rule, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// allows applies the longest matching rule, with Allow winning a tie.
+func (rr *robotsRules) allows(path string) bool {
+	if rr.disallowAll {
+		return false
+	}
+
+	allowed, longest := true, -1
+	for _, rule := range rr.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.length > longest || (rule.length == longest && rule.allow) {
+			allowed, longest = rule.allow, rule.length
+		}
+	}
+	return allowed
+}
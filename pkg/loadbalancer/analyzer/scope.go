@@ -0,0 +1,155 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	RegexPatternPrefix      = "regex:"
+	DefaultMaxURLsPerPrefix = 50
+)
+
+var DefaultExcludePatterns = []string{
+	RegexPatternPrefix + `(?i)/(logout|log-out|signout|sign-out)(/|$)`,
+	RegexPatternPrefix + `(?i)/downloads?(/|$)`,
+	RegexPatternPrefix + `(?i)\.(zip|tar|gz|rar|7z|exe|dmg|iso|pdf)$`,
+	RegexPatternPrefix + `(?i)[?&](print|printable|download|format=pdf)(=|&|$)`,
+}
+
+type CrawlScope struct {
+	IncludePatterns  []string `json:"includePatterns,omitempty"`
+	ExcludePatterns  []string `json:"excludePatterns,omitempty"`
+	MaxURLsPerPrefix int      `json:"maxUrlsPerPrefix,omitempty"`
+}
+
+func DefaultCrawlScope() CrawlScope {
+	return CrawlScope{
+		ExcludePatterns:  append([]string(nil), DefaultExcludePatterns...),
+		MaxURLsPerPrefix: DefaultMaxURLsPerPrefix,
+	}
+}
+
+type crawlScope struct {
+	include          []*regexp.Regexp
+	exclude          []*regexp.Regexp
+	maxURLsPerPrefix int
+	prefixCounts     map[string]int
+}
+
+func compileCrawlScope(scope CrawlScope) (*crawlScope, error) {
+	include, err := compilePatterns(scope.IncludePatterns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include pattern: %w", err)
+	}
+
+	exclude, err := compilePatterns(scope.ExcludePatterns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exclude pattern: %w", err)
+	}
+
+	return &crawlScope{
+		include:          include,
+		exclude:          exclude,
+		maxURLsPerPrefix: scope.MaxURLsPerPrefix,
+		prefixCounts:     make(map[string]int),
+	}, nil
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		var expr string
+		if strings.HasPrefix(pattern, RegexPatternPrefix) {
+			expr = strings.TrimPrefix(pattern, RegexPatternPrefix)
+		} else {
+			expr = globToRegex(pattern)
+		}
+
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// globToRegex matches against the URL path: "*" stays within a segment, "**" spans segments.
+func globToRegex(glob string) string {
+	var builder strings.Builder
+	builder.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				builder.WriteString(".*")
+				i++
+			} else {
+				builder.WriteString("[^/]*")
+			}
+		case '?':
+			builder.WriteString("[^/]")
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	builder.WriteString("$")
+	return builder.String()
+}
+
+func (cs *crawlScope) reset() {
+	cs.prefixCounts = make(map[string]int)
+}
+
+func (cs *crawlScope) allows(link string) (bool, string) {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return false, "unparseable"
+	}
+
+	path := parsed.Path
+	if path == "" {
+		path = "/"
+	}
+	target := path
+	if parsed.RawQuery != "" {
+		target += "?" + parsed.RawQuery
+	}
+
+	for _, re := range cs.exclude {
+		if re.MatchString(path) || re.MatchString(target) {
+			return false, "excluded"
+		}
+	}
+
+	if len(cs.include) > 0 {
+		included := false
+		for _, re := range cs.include {
+			if re.MatchString(path) || re.MatchString(target) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false, "not included"
+		}
+	}
+
+	if cs.maxURLsPerPrefix > 0 {
+		prefix := pathPrefix(path)
+		if cs.prefixCounts[prefix] >= cs.maxURLsPerPrefix {
+			return false, "prefix limit reached"
+		}
+		cs.prefixCounts[prefix]++
+	}
+
+	return true, ""
+}
+
+func pathPrefix(path string) string {
+	segments := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)
+	return "/" + segments[0]
+}
@@ -1,17 +1,24 @@
 package analyzer
 
 import (
+	"bufio"
+	"compress/flate"
 	"compress/gzip"
+	"compress/zlib"
+	"context"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
 	"time"
 
+	"github.com/andybalholm/brotli"
 	"github.com/cherry-pick/pkg/loadbalancer/core"
+	"github.com/cherry-pick/pkg/loadbalancer/utils"
+	"github.com/cherry-pick/pkg/logging"
+	"github.com/cherry-pick/pkg/tracing"
 )
 
 type URLAnalysisResult struct {
@@ -21,6 +28,8 @@ type URLAnalysisResult struct {
 	
 	"analysisTime"`
 	TotalPages      int              `json:"totalPages"`
+	// Partial is set when the crawl stopped at its timeout or on cancellation.
+	Partial bool `json:"partial,omitempty"`
 }
 
 type DiscoveredPage struct {
@@ -33,46 +42,136 @@ type DiscoveredPage struct {
 }
 
 type URLAnalyzer struct {
-	client     *http.Client
-	maxDepth   int
-	maxPages   int
-	visited    map[string]bool
-	discovered []DiscoveredPage
+	client      *http.Client
+	maxDepth    int
+	maxPages    int
+	visited     map[string]bool
+	discovered  []DiscoveredPage
+	scope       *crawlScope
+	politeness  *politeness
+	credentials CrawlCredentials
+	logger      logging.Logger
+}
+
+func DefaultClientOptions() core.HTTPClientOptions {
+	return core.HTTPClientOptions{
+		Timeout: 10 * time.Second,
+	}
 }
 
 func NewURLAnalyzer() *URLAnalyzer {
+	ua, _ := NewURLAnalyzerWithOptions(DefaultClientOptions())
+	return ua
+}
+
+func NewURLAnalyzerWithOptions(options core.HTTPClientOptions) (*URLAnalyzer, error) {
+	if options.Timeout == 0 {
+		options.Timeout = DefaultClientOptions().Timeout
+	}
+
+	client, err := utils.NewHTTPClient(options)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, _ := compileCrawlScope(DefaultCrawlScope())
+
 	return &URLAnalyzer{
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		client:     client,
 		maxDepth:   5,
 		maxPages:   200,
 		visited:    make(map[string]bool),
 		discovered: make([]DiscoveredPage, 0),
+		scope:      scope,
+		politeness: newPoliteness(DefaultCrawlPolicy()),
+		logger:     logging.Default(),
+	}, nil
+}
+
+func (ua *URLAnalyzer) SetCrawlScope(scope CrawlScope) error {
+	compiled, err := compileCrawlScope(scope)
+	if err != nil {
+		return err
 	}
+	ua.scope = compiled
+	return nil
 }
 
-func (ua *URLAnalyzer) AnalyzeURL(baseURL string) (*URLAnalysisResult, error) {
-	log.Printf("Starting URL analysis for: %s", baseURL)
+func (ua *URLAnalyzer) SetCrawlPolicy(policy CrawlPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	ua.politeness = newPoliteness(policy)
+	return nil
+}
+
+func (ua *URLAnalyzer) SetCredentials(credentials CrawlCredentials) error {
+	if err := credentials.Validate(); err != nil {
+		return err
+	}
+	ua.credentials = credentials
+	return nil
+}
+
+func (ua *URLAnalyzer) SetLogger(logger logging.Logger) {
+	if logger != nil {
+		ua.logger = logger
+	}
+}
+
+// AnalyzeURL crawls baseURL until the site is exhausted, the depth or page limit is
+// reached, ctx is done or the crawl policy's timeout passes. A crawl cut short returns
+// the pages found so far with Partial set.
+func (ua *URLAnalyzer) AnalyzeURL(ctx context.Context, baseURL string) (*URLAnalysisResult, error) {
+	ctx, span := tracing.Start(ctx, "crawl.AnalyzeURL",
+		tracing.AttrCrawlURL.String(baseURL))
+
+	if timeout := ua.politeness.policy.Timeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	result, err := ua.analyzeURL(ctx, baseURL)
+	if result != nil {
+		span.SetAttributes(tracing.AttrPages.Int(result.TotalPages))
+	}
+	tracing.End(span, err)
+
+	return result, err
+}
+
+func (ua *URLAnalyzer) analyzeURL(ctx context.Context, baseURL string) (*URLAnalysisResult, error) {
+	ua.logger.Info("Starting URL analysis", "url", baseURL)
 
 	ua.visited = make(map[string]bool)
 	ua.discovered = make([]DiscoveredPage, 0)
+	ua.scope.reset()
+	ua.politeness.reset()
 
 	parsedURL, err := url.Parse(baseURL)
 	if err != nil {
-		log.Printf("Invalid URL: %v", err)
+		ua.logger.Warn("Invalid URL", "url", baseURL, "error", err)
 		return nil, fmt.Errorf("invalid URL: %v", err)
 	}
 
-	log.Printf("Parsed URL - Scheme: %s, Host: %s, Path: %s", parsedURL.Scheme, parsedURL.Host, parsedURL.Path)
+	ua.logger.Debug("Parsed URL", "scheme", parsedURL.Scheme, "host", parsedURL.Host, "path", parsedURL.Path)
 
-	log.Printf("Analyzing root page: %s", baseURL)
-	ua.analyzePage(baseURL, "", 0)
+	// Each crawl starts from the configured cookies and keeps whatever the site sets.
+	ua.client.Jar = ua.credentials.newJar(parsedURL)
 
-	log.Printf("Analysis complete. Found %d pages", len(ua.discovered))
+	ua.logger.Debug("Analyzing root page", "url", baseURL)
+	ua.analyzePage(ctx, baseURL, "", 0)
+
+	partial := ctx.Err() != nil
+	if partial {
+		ua.logger.Warn("URL analysis stopped early", "url", baseURL, "pages", len(ua.discovered), "reason", ctx.Err())
+	} else {
+		ua.logger.Info("URL analysis complete", "url", baseURL, "pages", len(ua.discovered))
+	}
 	for i, page := range ua.discovered {
-		log.Printf("  %d. %s (%s) - Status: %d - Time: %dms",
-			i+1, page.Path, page.Title, page.StatusCode, page.ResponseTime)
+		ua.logger.Debug("Discovered page", "index", i+1, "path", page.Path, "title", page.Title,
+			"status", page.StatusCode, "responseTimeMs", page.ResponseTime)
 	}
 
 	return &URLAnalysisResult{
@@ -80,38 +179,51 @@ func (ua *URLAnalyzer) AnalyzeURL(baseURL string) (*URLAnalysisResult, error) {
 		DiscoveredPages: ua.discovered,
 		AnalysisTime:    time.Now(),
 		TotalPages:      len(ua.discovered),
+		Partial:         partial,
 	}, nil
 }
 
-func (ua *URLAnalyzer) analyzePage(pageURL, referrer string, depth int) {
+func (ua *URLAnalyzer) analyzePage(ctx context.Context, pageURL, referrer string, depth int) {
+	if ctx.Err() != nil {
+		return
+	}
 	if depth > ua.maxDepth || len(ua.discovered) >= ua.maxPages {
-		log.Printf("Stopping analysis - depth: %d, maxDepth: %d, pages: %d, maxPages: %d",
-			depth, ua.maxDepth, len(ua.discovered), ua.maxPages)
+		ua.logger.Debug("Stopping analysis", "depth", depth, "maxDepth", ua.maxDepth,
+			"pages", len(ua.discovered), "maxPages", ua.maxPages)
 		return
 	}
 
 	if ua.visited[pageURL] {
-		log.Printf("Already visited: %s", pageURL)
+		ua.logger.Debug("Already visited", "url", pageURL)
 		return
 	}
 
-	log.Printf("Analyzing page (depth %d): %s", depth, pageURL)
+	ua.logger.Debug("Analyzing page", "url", pageURL, "depth", depth)
 	ua.visited[pageURL] = true
 
-	req, err := http.NewRequest("GET", pageURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
 	if err != nil {
-		log.Printf("Failed to create request for %s: %v", pageURL, err)
+		ua.logger.Warn("Failed to create request", "url", pageURL, "error", err)
 		return
 	}
 
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
+	if !ua.politeness.allows(ctx, ua.client, req.URL) {
+		ua.logger.Debug("Skipping page disallowed by robots.txt", "url", pageURL)
+		return
+	}
+	if err := ua.politeness.wait(ctx, req.URL.Host); err != nil {
+		return
+	}
+
+	ua.politeness.identify(req)
 	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
 	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
-	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
 	req.Header.Set("Connection", "keep-alive")
 	req.Header.Set("Upgrade-Insecure-Requests", "1")
+	ua.credentials.apply(req)
 
-	log.Printf("Making request to: %s", pageURL)
+	ua.logger.Debug("Making request", "url", pageURL)
 	startTime := time.Now()
 	resp, err := ua.client.Do(req)
 	responseTime := time.Since(startTime).Milliseconds()
@@ -125,71 +237,115 @@ func (ua *URLAnalyzer) analyzePage(pageURL, referrer string, depth int) {
 		Depth:        depth,
 	}
 
+	if err != nil && ctx.Err() != nil {
+		return
+	}
 	if err != nil {
-		log.Printf("Request failed for %s: %v", pageURL, err)
+		ua.logger.Warn("Request failed", "url", pageURL, "error", err)
 		page.StatusCode = http.StatusInternalServerError
 		ua.discovered = append(ua.discovered, page)
 		return
 	}
 
 	page.StatusCode = resp.StatusCode
-	log.Printf("Response from %s: Status %d, Time %dms", pageURL, resp.StatusCode, responseTime)
+	ua.logger.Debug("Received response", "url", pageURL, "status", resp.StatusCode, "responseTimeMs", responseTime)
 	ua.discovered = append(ua.discovered, page)
 
-	if resp.StatusCode == http.StatusOK {
-		var reader io.Reader = resp.Body
-		if resp.Header.Get("Content-Encoding") == "gzip" {
-			gzReader, err := gzip.NewReader(resp.Body)
-			if err != nil {
-				log.Printf("Failed to create gzip reader for %s: %v", pageURL, err)
-				resp.Body.Close()
-				return
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		ua.logger.Debug("Non-200 status code", "url", pageURL, "status", resp.StatusCode)
+		return
+	}
+
+	body, err := readResponseBody(resp)
+	if err != nil {
+		ua.logger.Warn("Failed to read response body", "url", pageURL,
+			"encoding", resp.Header.Get("Content-Encoding"), "error", err)
+		return
+	}
+	ua.logger.Debug("Reading HTML content", "url", pageURL, "bytes", len(body))
+
+	links := ua.extractLinks(string(body), pageURL)
+	ua.logger.Debug("Found links", "url", pageURL, "links", len(links))
+
+	if len(links) == 0 {
+		ua.logger.Debug("No links found, analyzing content for potential routes", "url", pageURL)
+		contentLinks := ua.extractRoutesFromContent(string(body), pageURL)
+		links = append(links, contentLinks...)
+		ua.logger.Debug("Found additional routes from content analysis", "url", pageURL, "routes", len(contentLinks))
+	}
+
+	internalLinks := 0
+	for _, link := range links {
+		if ua.isInternalLink(link, pageURL) {
+			internalLinks++
+			if ua.visited[link] {
+				ua.logger.Debug("Skipping already visited internal link", "link", link)
+			} else if allowed, reason := ua.scope.allows(link); !allowed {
+				ua.logger.Debug("Skipping out-of-scope link", "link", link, "reason", reason)
+			} else {
+				ua.logger.Debug("Found internal link", "link", link)
+				ua.analyzePage(ctx, link, pageURL, depth+1)
 			}
-			defer gzReader.Close()
-			reader = gzReader
+		} else {
+			ua.logger.Debug("Skipping external link", "link", link)
 		}
+	}
+	ua.logger.Debug("Internal links found", "url", pageURL, "internal", internalLinks, "total", len(links))
+}
 
-		body, err := io.ReadAll(reader)
-		resp.Body.Close()
+func readResponseBody(resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
 
-		if err != nil {
-			log.Printf("Failed to read response body from %s: %v", pageURL, err)
-		} else {
-			log.Printf("Reading HTML content from %s (%d bytes)", pageURL, len(body))
+	reader, err := decodeContent(resp.Body, resp.Header.Get("Content-Encoding"))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
 
-			links := ua.extractLinks(string(body), pageURL)
-			log.Printf("Found %d links in %s", len(links), pageURL)
+	return io.ReadAll(reader)
+}
 
-			if len(links) == 0 {
-				log.Printf("No links found, analyzing content for potential routes...")
-				contentLinks := ua.extractRoutesFromContent(string(body), pageURL)
-				links = append(links, contentLinks...)
-				log.Printf("Found %d additional routes from content analysis", len(contentLinks))
-			}
+func decodeContent(body io.Reader, contentEncoding string) (io.ReadCloser, error) {
+	reader := io.NopCloser(body)
 
-			internalLinks := 0
-			for _, link := range links {
-				if ua.isInternalLink(link, pageURL) {
-					internalLinks++
-					if !ua.visited[link] {
-						log.Printf("Found internal link: %s", link)
-						ua.analyzePage(link, pageURL, depth+1)
-					} else {
-						log.Printf("Skipping already visited internal link: %s", link)
-					}
-				} else {
-					log.Printf("External link (skipping): %s", link)
-				}
+	encodings := strings.Split(contentEncoding, ",")
+	for i := len(encodings) - 1; i >= 0; i-- {
+		switch strings.ToLower(strings.TrimSpace(encodings[i])) {
+		case "", "identity":
+		case "gzip", "x-gzip":
+			gzReader, err := gzip.NewReader(reader)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create gzip reader: %w", err)
 			}
-			log.Printf("Internal links found: %d/%d", internalLinks, len(links))
+			reader = gzReader
+		case "deflate":
+			reader = newDeflateReader(reader)
+		case "br":
+			reader = io.NopCloser(brotli.NewReader(reader))
+		default:
+			return nil, fmt.Errorf("unsupported content encoding: %s", encodings[i])
 		}
-	} else {
-		log.Printf("Non-200 status code for %s: %d", pageURL, resp.StatusCode)
 	}
+
+	return reader, nil
+}
+
+func newDeflateReader(body io.Reader) io.ReadCloser {
+	buffered := bufio.NewReader(body)
+
+	header, err := buffered.Peek(2)
+	if err == nil && len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		if zReader, err := zlib.NewReader(buffered); err == nil {
+			return zReader
+		}
+	}
+
+	return flate.NewReader(buffered)
 }
 
 func (ua *URLAnalyzer) extractLinks(html, baseURL string) []string {
-	log.Printf("Extracting links from HTML content (%d bytes)", len(html))
+	ua.logger.Debug("Extracting links from HTML content", "bytes", len(html))
 
 	patterns := []*regexp.Regexp{
 		regexp.MustCompile(`href=["']([^"']+)["']`),
@@ -217,7 +373,7 @@ func (ua *URLAnalyzer) extractLinks(html, baseURL string) []string {
 
 	for i, pattern := range patterns {
 		matches := pattern.FindAllStringSubmatch(html, -1)
-		log.Printf("Pattern %d found %d matches", i+1, len(matches))
+		ua.logger.Debug("Link pattern matched", "pattern", i+1, "matches", len(matches))
 		for _, match := range matches {
 			if len(match) > 1 {
 				link := strings.TrimSpace(match[1])
@@ -226,7 +382,7 @@ func (ua *URLAnalyzer) extractLinks(html, baseURL string) []string {
 				if link == "" || strings.HasPrefix(link, "javascript:") ||
 					strings.HasPrefix(link, "mailto:") || strings.HasPrefix(link, "tel:") ||
 					strings.HasPrefix(link, "#") {
-					log.Printf("Skipping link: %s", link)
+					ua.logger.Debug("Skipping link", "link", link)
 					continue
 				}
 
@@ -237,7 +393,7 @@ func (ua *URLAnalyzer) extractLinks(html, baseURL string) []string {
 					strings.Contains(path, "..") ||
 					strings.Contains(path, " ") ||
 					strings.Contains(path, "\\") {
-					log.Printf("Skipping low-quality link: %s", link)
+					ua.logger.Debug("Skipping low-quality link", "link", link)
 					continue
 				}
 
@@ -251,27 +407,27 @@ func (ua *URLAnalyzer) extractLinks(html, baseURL string) []string {
 					link = base.Scheme + "://" + base.Host + link
 				}
 
-				log.Printf("Processing link: %s -> %s", originalLink, link)
+				ua.logger.Debug("Processing link", "original", originalLink, "resolved", link)
 
 				if !seen[link] && ua.isInternalLink(link, baseURL) {
 					seen[link] = true
 					links = append(links, link)
-					log.Printf("Added internal link: %s", link)
+					ua.logger.Debug("Added internal link", "link", link)
 				} else if seen[link] {
-					log.Printf("Already seen link: %s", link)
+					ua.logger.Debug("Already seen link", "link", link)
 				} else {
-					log.Printf("External link (skipping): %s", link)
+					ua.logger.Debug("Skipping external link", "link", link)
 				}
 			}
 		}
 	}
 
-	log.Printf("Link extraction complete. Found %d unique links", len(links))
+	ua.logger.Debug("Link extraction complete", "links", len(links))
 	return links
 }
 
 func (ua *URLAnalyzer) extractRoutesFromContent(html, baseURL string) []string {
-	log.Printf("Analyzing content for potential routes...")
+	ua.logger.Debug("Analyzing content for potential routes")
 
 	routePatterns := []*regexp.Regexp{
 		regexp.MustCompile(`<nav[^>]*>.*?</nav>`),
@@ -292,7 +448,7 @@ func (ua *URLAnalyzer) extractRoutesFromContent(html, baseURL string) []string {
 
 	for i, pattern := range routePatterns {
 		matches := pattern.FindAllStringSubmatch(html, -1)
-		log.Printf("Content pattern %d found %d matches", i+1, len(matches))
+		ua.logger.Debug("Content pattern matched", "pattern", i+1, "matches", len(matches))
 
 		for _, match := range matches {
 			if len(match) > 1 {
@@ -304,13 +460,13 @@ func (ua *URLAnalyzer) extractRoutesFromContent(html, baseURL string) []string {
 
 					fullURL := base.Scheme + "://" + base.Host + route
 					potentialRoutes = append(potentialRoutes, fullURL)
-					log.Printf("Potential route found: %s -> %s", text, route)
+					ua.logger.Debug("Potential route found", "text", text, "route", route)
 				}
 			}
 		}
 	}
 
-	log.Printf("Content analysis found %d potential routes", len(potentialRoutes))
+	ua.logger.Debug("Content analysis complete", "routes", len(potentialRoutes))
 	return potentialRoutes
 }
 
@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cherry-pick/pkg/logging"
+)
+
+func newSiteServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><body><a href="/about">About</a></body></html>`))
+		case "/about":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><body>About us</body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func crawlWithLevel(t *testing.T, level slog.Level) string {
+	t.Helper()
+
+	server := newSiteServer(t)
+	var buf bytes.Buffer
+
+	ua := NewURLAnalyzer()
+	ua.SetLogger(logging.NewTextLogger(&buf, level))
+
+	result, err := ua.AnalyzeURL(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Expected the crawl to succeed, got %v", err)
+	}
+	if result.TotalPages < 2 {
+		t.Fatalf("Expected at least 2 pages crawled, got %d", result.TotalPages)
+	}
+	return buf.String()
+}
+
+func TestInfoLevelHidesPerPageCrawlLogs(t *testing.T) {
+	output := crawlWithLevel(t, slog.LevelInfo)
+
+	for _, msg := range []string{"Analyzing page", "Making request", "Received response", "Found links"} {
+		if strings.Contains(output, msg) {
+			t.Errorf("Expected no %q lines at info level, got:\n%s", msg, output)
+		}
+	}
+	if !strings.Contains(output, "URL analysis complete") {
+		t.Errorf("Expected the crawl summary at info level, got:\n%s", output)
+	}
+}
+
+func TestDebugLevelShowsPerPageCrawlLogs(t *testing.T) {
+	output := crawlWithLevel(t, slog.LevelDebug)
+
+	for _, msg := range []string{"Analyzing page", "Making request", "Received response"} {
+		if !strings.Contains(output, msg) {
+			t.Errorf("Expected %q lines at debug level, got:\n%s", msg, output)
+		}
+	}
+}
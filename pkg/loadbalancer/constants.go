@@ -14,11 +14,15 @@ const (
 	DefaultURLAnalyzerTimeout  = 10 * time.Second
 	DefaultMaxDepth            = 5
 	DefaultMaxPages            = 200
+	DefaultMaxCapturedBodies   = 20
+	MaxCapturedBodiesLimit     = 500
+	DefaultMaxConcurrentTests  = 10
 )
 
 const (
 	StatusPending   = "pending"
 	StatusRunning   = "running"
+	StatusPaused    = "paused"
 	StatusCompleted = "completed"
 	StatusFailed    = "failed"
 	StatusCancelled = "cancelled"
@@ -96,6 +96,13 @@ type AlertTemplate struct {
 	Tags        []string      `json:"tags"`
 }
 
+// AlertTemplateInstantiation creates an alert for TestID from a template, with
+// Variables filling the template's ${name} placeholders.
+type AlertTemplateInstantiation struct {
+	TestID    string            `json:"testId" binding:"required"`
+	Variables map[string]string `json:"variables"`
+}
+
 type AlertStats struct {
 	TotalAlerts     int64 `json:"totalAlerts"`
 	ActiveAlerts    int64 `json:"activeAlerts"`
@@ -107,6 +114,34 @@ type AlertStats struct {
 	LowAlerts       int64 `json:"lowAlerts"`
 }
 
+// NewAlertStats counts alerts by state and severity.
+func NewAlertStats(alerts []*Alert) *AlertStats {
+	stats := &AlertStats{}
+
+	for _, alert := range alerts {
+		stats.TotalAlerts++
+		if alert.IsActive {
+			stats.ActiveAlerts++
+		}
+		if alert.TriggerCount > 0 {
+			stats.TriggeredAlerts++
+		}
+
+		switch alert.Severity {
+		case SeverityCritical:
+			stats.CriticalAlerts++
+		case SeverityHigh:
+			stats.HighAlerts++
+		case SeverityMedium:
+			stats.MediumAlerts++
+		case SeverityLow:
+			stats.LowAlerts++
+		}
+	}
+
+	return stats
+}
+
 type AlertRequest struct {
 	Name           string         `json:"name" binding:"required"`
 	Description    string         `json:"description"`
@@ -11,11 +11,20 @@ type LoadTestEngine interface {
 	GetTestSummary(testID string) (*LoadTestSummary, error)
 	GetTestResults(testID string) ([]LoadTestResult, error)
 	CancelTest(testID string) error
+	PauseTest(testID string) error
+	ResumeTest(testID string) error
 	GetAllTests() map[string]*LoadTestStatus
 	GetRealTimeMetrics(testID string) (*RealTimeMetrics, error)
+	GetCapturedFailures(testID string) ([]CapturedFailure, error)
 	CleanupOldTests(olderThan time.Duration)
 }
 
+type Worker interface {
+	Name() string
+	Region() string
+	Engine() LoadTestEngine
+}
+
 type TestReporter interface {
 	GenerateReport(testID string, summary *LoadTestSummary, results []LoadTestResult) error
 }
@@ -31,10 +40,14 @@ type TestManager interface {
 	GetTestSummary(testID string) (*LoadTestSummary, error)
 	GetTestResults(testID string) ([]LoadTestResult, error)
 	CancelTest(testID string) error
+	PauseTest(testID string) error
+	ResumeTest(testID string) error
 	GetAllTests() map[string]*LoadTestStatus
 	GetRealTimeMetrics(testID string) (*RealTimeMetrics, error)
+	GetCapturedFailures(testID string) ([]CapturedFailure, error)
 	CleanupOldTests(olderThan time.Duration)
 	GetEngineStats() map[string]interface{}
+	SetMaxConcurrentTests(limit int)
 }
 
 type ConfigValidator interface {
@@ -75,8 +88,11 @@ type LoadBalancer interface {
 	GetTestSummary(testID string) (*LoadTestSummary, error)
 	GetTestResults(testID string) ([]LoadTestResult, error)
 	CancelTest(testID string) error
+	PauseTest(testID string) error
+	ResumeTest(testID string) error
 	GetAllTests() map[string]*LoadTestStatus
 	GetRealTimeMetrics(testID string) (*RealTimeMetrics, error)
+	GetCapturedFailures(testID string) ([]CapturedFailure, error)
 	GenerateReport(testID string) error
 	GetEngineStats() map[string]interface{}
 	CleanupOldTests(olderThan time.Duration)
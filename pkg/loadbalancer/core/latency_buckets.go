@@ -0,0 +1,70 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds used when a test sets no LatencyBuckets,
+// giving the <100ms, 100-500ms, 500ms-1s, 1-2s and >2s bins.
+func DefaultLatencyBuckets() []time.Duration {
+	return []time.Duration{
+		100 * time.Millisecond,
+		500 * time.Millisecond,
+		time.Second,
+		2 * time.Second,
+	}
+}
+
+// LatencyBucketLabels returns one label per bin for the ascending bounds, in order:
+// one below the first bound, one between each pair and one above the last.
+func LatencyBucketLabels(bounds []time.Duration) []string {
+	if len(bounds) == 0 {
+		return nil
+	}
+
+	labels := make([]string, 0, len(bounds)+1)
+	labels = append(labels, "<"+formatLatencyBound(bounds[0]))
+	for i := 1; i < len(bounds); i++ {
+		labels = append(labels, latencyRangeLabel(bounds[i-1], bounds[i]))
+	}
+	labels = append(labels, ">"+formatLatencyBound(bounds[len(bounds)-1]))
+	return labels
+}
+
+// LatencyBucketIndex returns the bin a duration falls in; each bound is exclusive.
+func LatencyBucketIndex(bounds []time.Duration, d time.Duration) int {
+	for i, bound := range bounds {
+		if d < bound {
+			return i
+		}
+	}
+	return len(bounds)
+}
+
+// latencyRangeLabel drops the unit from the lower bound when both share it,
+// so 100ms..500ms reads "100-500ms" but 500ms..1s reads "500ms-1s".
+func latencyRangeLabel(lower, upper time.Duration) string {
+	lowerValue, lowerUnit := splitLatencyBound(lower)
+	upperValue, upperUnit := splitLatencyBound(upper)
+	if lowerUnit != "" && lowerUnit == upperUnit {
+		return lowerValue + "-" + upperValue + upperUnit
+	}
+	return formatLatencyBound(lower) + "-" + formatLatencyBound(upper)
+}
+
+func formatLatencyBound(d time.Duration) string {
+	value, unit := splitLatencyBound(d)
+	return value + unit
+}
+
+func splitLatencyBound(d time.Duration) (string, string) {
+	switch {
+	case d%time.Second == 0:
+		return fmt.Sprintf("%d", d/time.Second), "s"
+	case d%time.Millisecond == 0:
+		return fmt.Sprintf("%d", d/time.Millisecond), "ms"
+	default:
+		return d.String(), ""
+	}
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDefaultLatencyBucketLabels(t *testing.T) {
+	want := []string{"<100ms", "100-500ms", "500ms-1s", "1-2s", ">2s"}
+
+	if got := LatencyBucketLabels(DefaultLatencyBuckets()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestCustomLatencyBucketLabels(t *testing.T) {
+	bounds := []time.Duration{50 * time.Millisecond, 250 * time.Millisecond, 1500 * time.Millisecond}
+	want := []string{"<50ms", "50-250ms", "250-1500ms", ">1500ms"}
+
+	if got := LatencyBucketLabels(bounds); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got := LatencyBucketLabels(nil); got != nil {
+		t.Errorf("Expected no labels without bounds, got %v", got)
+	}
+}
+
+func TestLatencyBucketIndexTreatsBoundsAsExclusive(t *testing.T) {
+	bounds := DefaultLatencyBuckets()
+
+	cases := []struct {
+		latency time.Duration
+		want    int
+	}{
+		{0, 0},
+		{99 * time.Millisecond, 0},
+		{100 * time.Millisecond, 1},
+		{499 * time.Millisecond, 1},
+		{500 * time.Millisecond, 2},
+		{time.Second, 3},
+		{2 * time.Second, 4},
+		{time.Minute, 4},
+	}
+
+	for _, c := range cases {
+		if got := LatencyBucketIndex(bounds, c.latency); got != c.want {
+			t.Errorf("Expected %v in bucket %d, got %d", c.latency, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// LatencyDigestAccuracy is the relative error of any percentile read from a LatencyDigest.
+const LatencyDigestAccuracy = 0.01
+
+var (
+	latencyDigestGamma    = (1 + LatencyDigestAccuracy) / (1 - LatencyDigestAccuracy)
+	latencyDigestLogGamma = math.Log(latencyDigestGamma)
+)
+
+// LatencyDigest accumulates latencies into exponentially sized buckets, each a factor of
+// gamma wider than the last, so percentiles can be read in constant memory however many
+// requests a test makes. Min, max and mean are tracked exactly. It is not safe for
+// concurrent use.
+type LatencyDigest struct {
+	buckets map[int]int64
+	zeros   int64
+	count   int64
+	sum     time.Duration
+	min     time.Duration
+	max     time.Duration
+}
+
+func NewLatencyDigest() *LatencyDigest {
+	return &LatencyDigest{buckets: make(map[int]int64)}
+}
+
+func (ld *LatencyDigest) Add(d time.Duration) {
+	if ld.count == 0 || d < ld.min {
+		ld.min = d
+	}
+	if ld.count == 0 || d > ld.max {
+		ld.max = d
+	}
+	ld.count++
+	ld.sum += d
+
+	if d <= 0 {
+		ld.zeros++
+		return
+	}
+	ld.buckets[int(math.Ceil(math.Log(float64(d))/latencyDigestLogGamma))]++
+}
+
+// Merge adds every latency recorded in other, as if each had been added to ld.
+func (ld *LatencyDigest) Merge(other *LatencyDigest) {
+	if other == nil || other.count == 0 {
+		return
+	}
+	if ld.count == 0 || other.min < ld.min {
+		ld.min = other.min
+	}
+	if ld.count == 0 || other.max > ld.max {
+		ld.max = other.max
+	}
+	ld.count += other.count
+	ld.sum += other.sum
+	ld.zeros += other.zeros
+	for index, count := range other.buckets {
+		ld.buckets[index] += count
+	}
+}
+
+func (ld *LatencyDigest) Count() int64 {
+	return ld.count
+}
+
+func (ld *LatencyDigest) Min() time.Duration {
+	return ld.min
+}
+
+func (ld *LatencyDigest) Max() time.Duration {
+	return ld.max
+}
+
+func (ld *LatencyDigest) Mean() time.Duration {
+	if ld.count == 0 {
+		return 0
+	}
+	return ld.sum / time.Duration(ld.count)
+}
+
+// Quantile returns the latency at q, between 0 and 1, to within LatencyDigestAccuracy.
+func (ld *LatencyDigest) Quantile(q float64) time.Duration {
+	if ld.count == 0 {
+		return 0
+	}
+	if q <= 0 {
+		return ld.min
+	}
+	if q >= 1 {
+		return ld.max
+	}
+
+	rank := int64(q * float64(ld.count-1))
+	if rank < ld.zeros {
+		return ld.clamp(0)
+	}
+
+	indexes := make([]int, 0, len(ld.buckets))
+	for index := range ld.buckets {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	seen := ld.zeros
+	for _, index := range indexes {
+		seen += ld.buckets[index]
+		if seen > rank {
+			// The midpoint of (gamma^(i-1), gamma^i] in relative terms.
+			value := 2 * math.Pow(latencyDigestGamma, float64(index)) / (latencyDigestGamma + 1)
+			return ld.clamp(time.Duration(value))
+		}
+	}
+	return ld.max
+}
+
+func (ld *LatencyDigest) clamp(d time.Duration) time.Duration {
+	if d < ld.min {
+		return ld.min
+	}
+	if d > ld.max {
+		return ld.max
+	}
+	return d
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+)
+
+func exactQuantile(sorted []time.Duration, q float64) time.Duration {
+	return sorted[int(q*float64(len(sorted)-1))]
+}
+
+func assertWithinAccuracy(t *testing.T, label string, got, want time.Duration) {
+	t.Helper()
+
+	if diff := math.Abs(float64(got - want)); diff > LatencyDigestAccuracy*float64(want) {
+		t.Errorf("Expected %s within %.0f%% of %v, got %v", label, LatencyDigestAccuracy*100, want, got)
+	}
+}
+
+func TestLatencyDigestQuantilesMatchExactValues(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	digest := NewLatencyDigest()
+
+	latencies := make([]time.Duration, 0, 20000)
+	for i := 0; i < 20000; i++ {
+		// A long-tailed spread from about 1ms to several seconds.
+		d := time.Duration(rng.ExpFloat64()*float64(50*time.Millisecond)) + time.Millisecond
+		latencies = append(latencies, d)
+		digest.Add(d)
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	for _, q := range []float64{0.5, 0.9, 0.95, 0.99, 0.999} {
+		assertWithinAccuracy(t, "quantile", digest.Quantile(q), exactQuantile(latencies, q))
+	}
+
+	if digest.Min() != latencies[0] || digest.Max() != latencies[len(latencies)-1] {
+		t.Errorf("Expected exact min %v and max %v, got %v and %v",
+			latencies[0], latencies[len(latencies)-1], digest.Min(), digest.Max())
+	}
+	if digest.Count() != int64(len(latencies)) {
+		t.Errorf("Expected count %d, got %d", len(latencies), digest.Count())
+	}
+}
+
+func TestLatencyDigestMergeMatchesSingleDigest(t *testing.T) {
+	whole, left, right := NewLatencyDigest(), NewLatencyDigest(), NewLatencyDigest()
+
+	for i := 1; i <= 1000; i++ {
+		d := time.Duration(i) * time.Millisecond
+		whole.Add(d)
+		if i%2 == 0 {
+			left.Add(d)
+		} else {
+			right.Add(d)
+		}
+	}
+	left.Merge(right)
+
+	for _, q := range []float64{0, 0.5, 0.95, 0.99, 1} {
+		if left.Quantile(q) != whole.Quantile(q) {
+			t.Errorf("Expected merged quantile %v of %v, got %v", q, whole.Quantile(q), left.Quantile(q))
+		}
+	}
+	if left.Mean() != whole.Mean() {
+		t.Errorf("Expected merged mean %v, got %v", whole.Mean(), left.Mean())
+	}
+}
+
+func TestLatencyDigestHandlesZeroAndEmpty(t *testing.T) {
+	digest := NewLatencyDigest()
+	if digest.Quantile(0.95) != 0 || digest.Mean() != 0 {
+		t.Errorf("Expected zero values from an empty digest, got p95 %v and mean %v", digest.Quantile(0.95), digest.Mean())
+	}
+
+	digest.Add(0)
+	digest.Add(0)
+	digest.Add(10 * time.Millisecond)
+
+	if got := digest.Quantile(0.5); got != 0 {
+		t.Errorf("Expected a zero median, got %v", got)
+	}
+	if got := digest.Quantile(0.99); got > 10*time.Millisecond {
+		t.Errorf("Expected p99 clamped to the 10ms max, got %v", got)
+	}
+}
@@ -0,0 +1,47 @@
+package core
+
+import "time"
+
+const (
+	DefaultResultBatchSize     = 500
+	DefaultResultFlushInterval = 5 * time.Second
+	DefaultResultWindow        = 1000
+)
+
+// ResultSink receives batches of raw results while a test runs. Distributed tests share
+// one sink across workers, so implementations must be safe for concurrent use. The
+// engine never closes a sink; its owner does once the test has finished.
+type ResultSink interface {
+	WriteResults(testID string, results []LoadTestResult) error
+}
+
+// ResultSinkFunc adapts a function to a ResultSink.
+type ResultSinkFunc func(testID string, results []LoadTestResult) error
+
+func (f ResultSinkFunc) WriteResults(testID string, results []LoadTestResult) error {
+	return f(testID, results)
+}
+
+// ResultStreamConfig streams raw results to Sink in batches instead of holding them all
+// until the test ends. A batch is flushed once it holds BatchSize results or every
+// FlushInterval, whichever comes first. When RetainRawResults is set only the last
+// Window results stay in memory. Zero values fall back to the defaults above.
+//
+// Path records the file name a request asked results to be appended to, relative to the
+// load balancer's output directory; the load balancer turns it into Sink.
+type ResultStreamConfig struct {
+	Sink          ResultSink    `json:"-"`
+	Path          string        `json:"path,omitempty"`
+	BatchSize     int           `json:"batchSize,omitempty"`
+	FlushInterval time.Duration `json:"flushInterval,omitempty"`
+	Window        int           `json:"window,omitempty"`
+}
+
+// ResultStreamRequest is the API form of ResultStreamConfig. Path is a file name, without
+// directories, under the load balancer's output directory.
+type ResultStreamRequest struct {
+	Path          string `json:"path" binding:"required"`
+	BatchSize     int    `json:"batchSize,omitempty"`
+	FlushInterval int    `json:"flushInterval,omitempty"` // in milliseconds
+	Window        int    `json:"window,omitempty"`
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultSuccessStatusCodes is used when a test sets no SuccessStatusCodes.
+const DefaultSuccessStatusCodes = "200-299"
+
+// StatusCodeRange is an inclusive range of HTTP status codes.
+type StatusCodeRange struct {
+	Min int
+	Max int
+}
+
+// ParseStatusCodes reads a comma-separated list of codes and inclusive ranges, such as
+// "200-299,304". An empty expression means DefaultSuccessStatusCodes.
+func ParseStatusCodes(expr string) ([]StatusCodeRange, error) {
+	if strings.TrimSpace(expr) == "" {
+		expr = DefaultSuccessStatusCodes
+	}
+
+	var ranges []StatusCodeRange
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		lower, upper, isRange := strings.Cut(part, "-")
+
+		min, err := parseStatusCode(lower)
+		if err != nil {
+			return nil, err
+		}
+		max := min
+		if isRange {
+			if max, err = parseStatusCode(upper); err != nil {
+				return nil, err
+			}
+			if max < min {
+				return nil, fmt.Errorf("status code range %q is reversed", part)
+			}
+		}
+		ranges = append(ranges, StatusCodeRange{Min: min, Max: max})
+	}
+	return ranges, nil
+}
+
+func parseStatusCode(value string) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || code < 100 || code > 599 {
+		return 0, fmt.Errorf("invalid status code %q: must be between 100 and 599", value)
+	}
+	return code, nil
+}
+
+// StatusCodeMatches reports whether code falls in any of the ranges.
+func StatusCodeMatches(ranges []StatusCodeRange, code int) bool {
+	for _, r := range ranges {
+		if code >= r.Min && code <= r.Max {
+			return true
+		}
+	}
+	return false
+}
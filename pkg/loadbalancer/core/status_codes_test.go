@@ -0,0 +1,39 @@
+package core
+
+import "testing"
+
+func TestStatusCodesDefaultTo2xx(t *testing.T) {
+	ranges, err := ParseStatusCodes("")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	cases := map[int]bool{200: true, 204: true, 299: true, 304: false, 404: false, 500: false}
+	for code, want := range cases {
+		if got := StatusCodeMatches(ranges, code); got != want {
+			t.Errorf("Expected %d to match=%v, got %v", code, want, got)
+		}
+	}
+}
+
+func TestStatusCodesCountNotModifiedAndNotFound(t *testing.T) {
+	ranges, err := ParseStatusCodes("200-299, 304,404")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	cases := map[int]bool{200: true, 304: true, 404: true, 301: false, 403: false, 500: false}
+	for code, want := range cases {
+		if got := StatusCodeMatches(ranges, code); got != want {
+			t.Errorf("Expected %d to match=%v, got %v", code, want, got)
+		}
+	}
+}
+
+func TestInvalidStatusCodesAreRejected(t *testing.T) {
+	for _, expr := range []string{"abc", "99", "600", "299-200", "200-", "200,,300"} {
+		if _, err := ParseStatusCodes(expr); err == nil {
+			t.Errorf("Expected an error for %q", expr)
+		}
+	}
+}
@@ -1,18 +1,95 @@
 package core
 
-import "time"
+import (
+	"crypto/tls"
+	"time"
+)
 
 type LoadTestConfig struct {
-	URL             string            `json:"url" binding:"required"`
-	ConcurrentUsers int               `json:"concurrentUsers" binding:"required,min=1,max=1000"`
-	Duration        time.Duration     `json:"duration"`
-	RampUpTime      time.Duration     `json:"rampUpTime"`
-	RequestDelay    time.Duration     `json:"requestDelay"`
-	Headers         map[string]string `json:"headers"`
-	Method          string            `json:"method"`
-	Body            string            `json:"body"`
+	URL               string            `json:"url" binding:"required"`
+	ConcurrentUsers   int               `json:"concurrentUsers" binding:"required,min=1,max=1000"`
+	Duration          time.Duration     `json:"duration"`
+	RampUpTime        time.Duration     `json:"rampUpTime"`
+	RequestDelay      time.Duration     `json:"requestDelay"`
+	Headers           map[string]string `json:"headers"`
+	Method            string            `json:"method"`
+	Body              string            `json:"body"`
+	CaptureFailures   bool              `json:"captureFailures"`
+	MaxCapturedBodies int               `json:"maxCapturedBodies"`
+	ThinkTime         ThinkTimeConfig   `json:"thinkTime"`
+	Seed              int64             `json:"seed,omitempty"`
+	Workers           []WorkerSpec      `json:"workers,omitempty"`
+	SLA               *SLAConfig        `json:"sla,omitempty"`
+	LatencyBuckets    []time.Duration   `json:"latencyBuckets,omitempty"`
+	MaxResponseBytes  int64             `json:"maxResponseBytes,omitempty"`
+	RetainRawResults  bool              `json:"retainRawResults"`
+	// SuccessStatusCodes lists the status codes counted as successful, e.g. "200-299,304".
+	// Empty means 2xx.
+	SuccessStatusCodes string `json:"successStatusCodes,omitempty"`
+	// ResultStream, when set, flushes raw results to a sink as they arrive. Without it
+	// results are kept in memory until the test ends.
+	ResultStream *ResultStreamConfig `json:"resultStream,omitempty"`
 }
 
+// DefaultMaxResponseBytes is how much of each response body is read when a test sets
+// no MaxResponseBytes.
+const DefaultMaxResponseBytes int64 = 1 << 20
+
+// SLAConfig holds the thresholds a finished test must meet to pass. Zero fields are not checked.
+type SLAConfig struct {
+	MaxP95Latency time.Duration `json:"maxP95Latency,omitempty"`
+	MaxErrorRate  float64       `json:"maxErrorRate,omitempty"`
+	MinThroughput float64       `json:"minThroughput,omitempty"`
+}
+
+// SLARequest is the API form of SLAConfig.
+type SLARequest struct {
+	MaxP95Latency int     `json:"maxP95Latency,omitempty"` // in milliseconds
+	MaxErrorRate  float64 `json:"maxErrorRate,omitempty"`
+	MinThroughput float64 `json:"minThroughput,omitempty"`
+}
+
+type SLAViolation struct {
+	Metric    string  `json:"metric"`
+	Threshold float64 `json:"threshold"`
+	Actual    float64 `json:"actual"`
+	Message   string  `json:"message"`
+}
+
+// HTTPClientOptions configures the client used to issue requests; zero values fall back to the caller's defaults.
+type HTTPClientOptions struct {
+	Timeout             time.Duration `json:"timeout,omitempty"`
+	ProxyURL            string        `json:"proxyUrl,omitempty"`
+	MaxIdleConns        int           `json:"maxIdleConns,omitempty"`
+	MaxIdleConnsPerHost int           `json:"maxIdleConnsPerHost,omitempty"`
+	IdleConnTimeout     time.Duration `json:"idleConnTimeout,omitempty"`
+	DisableKeepAlives   bool          `json:"disableKeepAlives"`
+	DisableRedirects    bool          `json:"disableRedirects"`
+	InsecureSkipVerify  bool          `json:"insecureSkipVerify"`
+	TLSConfig           *tls.Config   `json:"-"`
+}
+
+type WorkerSpec struct {
+	Name   string `json:"name"`
+	Region string `json:"region,omitempty"`
+}
+
+const (
+	ThinkTimeConstant    = "constant"
+	ThinkTimeUniform     = "uniform"
+	ThinkTimeExponential = "exponential"
+)
+
+type ThinkTimeConfig struct {
+	Distribution string        `json:"distribution"`
+	Min          time.Duration `json:"min,omitempty"`
+	Max          time.Duration `json:"max,omitempty"`
+	Mean         time.Duration `json:"mean,omitempty"`
+}
+
+// LoadTestResult records one request. ResponseSize is the body size used for bandwidth:
+// the Content-Length when a body was truncated at MaxResponseBytes and the server sent
+// one, otherwise the bytes read. BytesRead is what was actually read from the body.
 type LoadTestResult struct {
 	RequestID    string        `json:"requestId"`
 	UserID       int           `json:"userId"`
@@ -21,8 +98,35 @@ type LoadTestResult struct {
 	Duration     time.Duration `json:"duration"`
 	StatusCode   int           `json:"statusCode"`
 	ResponseSize int64         `json:"responseSize"`
+	BytesRead    int64         `json:"bytesRead"`
+	Truncated    bool          `json:"truncated,omitempty"`
 	Error        string        `json:"error,omitempty"`
+	ErrorType    string        `json:"errorType,omitempty"`
 	Success      bool          `json:"success"`
+	Worker       string        `json:"worker,omitempty"`
+	Region       string        `json:"region,omitempty"`
+}
+
+// ProbeResult is the outcome of a single dry-run request made before starting a test.
+type ProbeResult struct {
+	URL          string        `json:"url"`
+	Method       string        `json:"method"`
+	Reachable    bool          `json:"reachable"`
+	StatusCode   int           `json:"statusCode,omitempty"`
+	ResponseTime time.Duration `json:"responseTime"`
+	ResponseSize int64         `json:"responseSize"`
+	Error        string        `json:"error,omitempty"`
+	ErrorType    string        `json:"errorType,omitempty"`
+}
+
+type CapturedFailure struct {
+	RequestID  string    `json:"requestId"`
+	UserID     int       `json:"userId"`
+	Timestamp  time.Time `json:"timestamp"`
+	StatusCode int       `json:"statusCode"`
+	Error      string    `json:"error,omitempty"`
+	Body       string    `json:"body,omitempty"`
+	Truncated  bool      `json:"truncated"`
 }
 
 type LoadTestSummary struct {
@@ -39,9 +143,33 @@ type LoadTestSummary struct {
 	MaxResponseTime          time.Duration    `json:"maxResponseTime"`
 	RequestsPerSecond        float64          `json:"requestsPerSecond"`
 	ErrorRate                float64          `json:"errorRate"`
+	Percentile50             time.Duration    `json:"percentile50"`
+	Percentile95             time.Duration    `json:"percentile95"`
+	Percentile99             time.Duration    `json:"percentile99"`
+	Passed                   bool             `json:"passed"`
+	ExitCode                 int              `json:"exitCode"`
+	SLAViolations            []SLAViolation   `json:"slaViolations,omitempty"`
 	StatusCodes              map[int]int64    `json:"statusCodes"`
 	ResponseTimeDistribution map[string]int64 `json:"responseTimeDistribution"`
+	ResponseTimeBuckets      []string         `json:"responseTimeBuckets"`
+	TruncatedResponses       int64            `json:"truncatedResponses,omitempty"`
 	Results                  []LoadTestResult `json:"results,omitempty"`
+	Workers                  []WorkerSummary  `json:"workers,omitempty"`
+	Latency                  *LatencyDigest   `json:"-"`
+}
+
+type WorkerSummary struct {
+	Worker              string        `json:"worker"`
+	Region              string        `json:"region,omitempty"`
+	ConcurrentUsers     int           `json:"concurrentUsers"`
+	TotalRequests       int64         `json:"totalRequests"`
+	SuccessfulRequests  int64         `json:"successfulRequests"`
+	FailedRequests      int64         `json:"failedRequests"`
+	AverageResponseTime time.Duration `json:"averageResponseTime"`
+	MinResponseTime     time.Duration `json:"minResponseTime"`
+	MaxResponseTime     time.Duration `json:"maxResponseTime"`
+	RequestsPerSecond   float64       `json:"requestsPerSecond"`
+	ErrorRate           float64       `json:"errorRate"`
 }
 
 type LoadTestStatus struct {
@@ -51,6 +179,10 @@ type LoadTestStatus struct {
 	StartTime time.Time `json:"startTime,omitempty"`
 	EndTime   time.Time `json:"endTime,omitempty"`
 	Message   string    `json:"message,omitempty"`
+	Passed    *bool     `json:"passed,omitempty"`
+	ExitCode  *int      `json:"exitCode,omitempty"`
+	// QueuePosition is the 1-based place of a pending test waiting for a free slot.
+	QueuePosition int `json:"queuePosition,omitempty"`
 }
 
 type RealTimeMetrics struct {
@@ -75,14 +207,30 @@ type RealTimeMetrics struct {
 }
 
 type LoadTestRequest struct {
-	URL             string            `json:"url" binding:"required"`
-	ConcurrentUsers int               `json:"concurrentUsers" binding:"required,min=1,max=1000"`
-	Duration        int               `json:"duration"`
-	RampUpTime      int               `json:"rampUpTime"`
-	RequestDelay    int               `json:"requestDelay"`
-	Headers         map[string]string `json:"headers,omitempty"`
-	Method          string            `json:"method,omitempty"`
-	Body            string            `json:"body,omitempty"`
+	URL                string            `json:"url" binding:"required"`
+	ConcurrentUsers    int               `json:"concurrentUsers" binding:"required,min=1,max=1000"`
+	Duration           int               `json:"duration"`
+	RampUpTime         int               `json:"rampUpTime"`
+	RequestDelay       int               `json:"requestDelay"`
+	Headers            map[string]string `json:"headers,omitempty"`
+	Method             string            `json:"method,omitempty"`
+	Body               string            `json:"body,omitempty"`
+	CaptureFailures    bool              `json:"captureFailures,omitempty"`
+	MaxCapturedBodies  int               `json:"maxCapturedBodies,omitempty"`
+	ThinkTime          string            `json:"thinkTime,omitempty"`
+	ThinkTimeMin       int               `json:"thinkTimeMin,omitempty"`  // in milliseconds
+	ThinkTimeMax       int               `json:"thinkTimeMax,omitempty"`  // in milliseconds
+	ThinkTimeMean      int               `json:"thinkTimeMean,omitempty"` // in milliseconds
+	Seed               int64             `json:"seed,omitempty"`
+	Workers            []WorkerSpec      `json:"workers,omitempty"`
+	SLA                *SLARequest       `json:"sla,omitempty"`
+	LatencyBuckets     []int             `json:"latencyBuckets,omitempty"` // upper bounds in milliseconds
+	MaxResponseBytes   int64             `json:"maxResponseBytes,omitempty"`
+	SuccessStatusCodes string            `json:"successStatusCodes,omitempty"`
+	// RetainRawResults keeps every result for the results endpoint, the exports and the
+	// report. Unset means true; set it to false for long tests that only need the summary.
+	RetainRawResults *bool                `json:"retainRawResults,omitempty"`
+	ResultStream     *ResultStreamRequest `json:"resultStream,omitempty"`
 }
 
 type LoadTestResponse struct {
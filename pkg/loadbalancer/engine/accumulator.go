@@ -0,0 +1,149 @@
+package engine
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cherry-pick/pkg/loadbalancer/core"
+)
+
+// ResultAccumulator folds results into running totals and a latency digest as they
+// arrive, so a summary can be built without keeping every result.
+type ResultAccumulator struct {
+	mu           sync.Mutex
+	bounds       []time.Duration
+	labels       []string
+	total        int64
+	successful   int64
+	truncated    int64
+	statusCodes  map[int]int64
+	distribution map[string]int64
+	latency      *core.LatencyDigest
+}
+
+// NewResultAccumulator bins latencies by bounds, or DefaultLatencyBuckets when empty.
+func NewResultAccumulator(bounds []time.Duration) *ResultAccumulator {
+	if len(bounds) == 0 {
+		bounds = core.DefaultLatencyBuckets()
+	}
+
+	ra := &ResultAccumulator{
+		bounds:       bounds,
+		labels:       core.LatencyBucketLabels(bounds),
+		statusCodes:  make(map[int]int64),
+		distribution: make(map[string]int64),
+		latency:      core.NewLatencyDigest(),
+	}
+	for _, label := range ra.labels {
+		ra.distribution[label] = 0
+	}
+	return ra
+}
+
+func (ra *ResultAccumulator) Add(result core.LoadTestResult) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	ra.total++
+	if result.Success {
+		ra.successful++
+	}
+	if result.Truncated {
+		ra.truncated++
+	}
+	ra.statusCodes[result.StatusCode]++
+	ra.distribution[ra.labels[core.LatencyBucketIndex(ra.bounds, result.Duration)]]++
+	ra.latency.Add(result.Duration)
+}
+
+// AddSummary folds in a summary built elsewhere, such as on another worker. Its latency
+// buckets must match the accumulator's.
+func (ra *ResultAccumulator) AddSummary(summary *core.LoadTestSummary) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	ra.total += summary.TotalRequests
+	ra.successful += summary.SuccessfulRequests
+	ra.truncated += summary.TruncatedResponses
+	for code, count := range summary.StatusCodes {
+		ra.statusCodes[code] += count
+	}
+	for label, count := range summary.ResponseTimeDistribution {
+		ra.distribution[label] += count
+	}
+	ra.latency.Merge(summary.Latency)
+}
+
+func (ra *ResultAccumulator) Count() int64 {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	return ra.total
+}
+
+// Metrics fills the request counts and latency figures of metrics from what has been
+// accumulated so far.
+func (ra *ResultAccumulator) Metrics(metrics *core.RealTimeMetrics) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	metrics.TotalRequests = ra.total
+	metrics.SuccessfulRequests = ra.successful
+	metrics.FailedRequests = ra.total - ra.successful
+	if ra.total == 0 {
+		return
+	}
+	metrics.ErrorRate = float64(metrics.FailedRequests) / float64(ra.total) * 100
+	metrics.AverageResponseTime = ra.latency.Mean()
+	metrics.MinResponseTime = ra.latency.Min()
+	metrics.MaxResponseTime = ra.latency.Max()
+	metrics.Percentile50 = ra.latency.Quantile(0.50)
+	metrics.Percentile95 = ra.latency.Quantile(0.95)
+	metrics.Percentile99 = ra.latency.Quantile(0.99)
+}
+
+func (ra *ResultAccumulator) Summary(testID string, config core.LoadTestConfig, startTime, endTime time.Time) *core.LoadTestSummary {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	summary := &core.LoadTestSummary{
+		TestID:                   testID,
+		Config:                   config,
+		StartTime:                startTime,
+		EndTime:                  endTime,
+		TotalDuration:            endTime.Sub(startTime),
+		TotalRequests:            ra.total,
+		SuccessfulRequests:       ra.successful,
+		FailedRequests:           ra.total - ra.successful,
+		TruncatedResponses:       ra.truncated,
+		StatusCodes:              make(map[int]int64, len(ra.statusCodes)),
+		ResponseTimeDistribution: make(map[string]int64, len(ra.distribution)),
+		ResponseTimeBuckets:      ra.labels,
+		Latency:                  core.NewLatencyDigest(),
+	}
+	for code, count := range ra.statusCodes {
+		summary.StatusCodes[code] = count
+	}
+	for label, count := range ra.distribution {
+		summary.ResponseTimeDistribution[label] = count
+	}
+	summary.Latency.Merge(ra.latency)
+
+	if ra.total == 0 {
+		EvaluateSLA(summary, config.SLA)
+		return summary
+	}
+
+	summary.AverageResponseTime = ra.latency.Mean()
+	summary.MinResponseTime = ra.latency.Min()
+	summary.MaxResponseTime = ra.latency.Max()
+	summary.Percentile50 = ra.latency.Quantile(0.50)
+	summary.Percentile95 = ra.latency.Quantile(0.95)
+	summary.Percentile99 = ra.latency.Quantile(0.99)
+	summary.RequestsPerSecond = float64(summary.TotalRequests) / summary.TotalDuration.Seconds()
+	summary.ErrorRate = float64(summary.FailedRequests) / float64(summary.TotalRequests) * 100
+
+	EvaluateSLA(summary, config.SLA)
+
+	return summary
+}
@@ -0,0 +1,31 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cherry-pick/pkg/loadbalancer/core"
+)
+
+func TestAccumulatorReportsEveryConfiguredBucket(t *testing.T) {
+	bounds := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}
+	accumulator := NewResultAccumulator(bounds)
+
+	for _, d := range []time.Duration{5 * time.Millisecond, 6 * time.Millisecond, 30 * time.Millisecond} {
+		accumulator.Add(core.LoadTestResult{Duration: d, Success: true, StatusCode: 200})
+	}
+
+	start := time.Now()
+	summary := accumulator.Summary("buckets", core.LoadTestConfig{LatencyBuckets: bounds}, start, start.Add(time.Second))
+
+	wantLabels := []string{"<10ms", "10-20ms", ">20ms"}
+	if !reflect.DeepEqual(summary.ResponseTimeBuckets, wantLabels) {
+		t.Errorf("Expected buckets %v, got %v", wantLabels, summary.ResponseTimeBuckets)
+	}
+
+	want := map[string]int64{"<10ms": 2, "10-20ms": 0, ">20ms": 1}
+	if !reflect.DeepEqual(summary.ResponseTimeDistribution, want) {
+		t.Errorf("Expected distribution %v, got %v", want, summary.ResponseTimeDistribution)
+	}
+}
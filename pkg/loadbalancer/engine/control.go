@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// testControl lets a running test be paused, resumed and cancelled. The test context is
+// cancelled when the configured duration has run, not counting time spent paused, or
+// as soon as cancel is called.
+type testControl struct {
+	mu        sync.Mutex
+	cancel    context.CancelFunc
+	deadline  *time.Timer
+	remaining time.Duration
+	resumedAt time.Time
+	resume    chan struct{}
+}
+
+func newTestControl(cancel context.CancelFunc, duration time.Duration) *testControl {
+	return &testControl{
+		cancel:    cancel,
+		deadline:  time.AfterFunc(duration, cancel),
+		remaining: duration,
+		resumedAt: time.Now(),
+	}
+}
+
+// pause stops the duration clock; users block in waitIfPaused until resume is called.
+func (tc *testControl) pause() bool {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if tc.resume != nil {
+		return false
+	}
+	if !tc.deadline.Stop() {
+		return false
+	}
+	tc.remaining -= time.Since(tc.resumedAt)
+	tc.resume = make(chan struct{})
+	return true
+}
+
+func (tc *testControl) unpause() bool {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if tc.resume == nil {
+		return false
+	}
+	close(tc.resume)
+	tc.resume = nil
+	tc.resumedAt = time.Now()
+	tc.deadline = time.AfterFunc(tc.remaining, tc.cancel)
+	return true
+}
+
+func (tc *testControl) stop() {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	tc.deadline.Stop()
+	tc.cancel()
+}
+
+// waitIfPaused blocks while the test is paused and reports whether the user should
+// carry on, which is false once the test context is done.
+func (tc *testControl) waitIfPaused(ctx context.Context) bool {
+	for {
+		tc.mu.Lock()
+		resume := tc.resume
+		tc.mu.Unlock()
+
+		if resume == nil {
+			return ctx.Err() == nil
+		}
+		select {
+		case <-resume:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
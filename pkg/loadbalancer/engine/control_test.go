@@ -0,0 +1,159 @@
+package engine
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newCountingServer(t *testing.T) (*httptest.Server, *int64) {
+	t.Helper()
+
+	var requests int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// settledCount waits out requests already in flight and returns the count after them.
+func settledCount(requests *int64) int64 {
+	time.Sleep(50 * time.Millisecond)
+	return atomic.LoadInt64(requests)
+}
+
+func TestPausedTestIssuesNoRequestsAndKeepsItsDuration(t *testing.T) {
+	server, requests := newCountingServer(t)
+	e := NewEngine()
+
+	config := shortTestConfig(server.URL)
+	config.Duration = 300 * time.Millisecond
+
+	if err := e.StartLoadTest("paused", config); err != nil {
+		t.Fatalf("Expected the test to start, got %v", err)
+	}
+	waitForStatus(t, e, "paused", "running")
+	time.Sleep(50 * time.Millisecond)
+
+	if err := e.PauseTest("paused"); err != nil {
+		t.Fatalf("Expected the test to pause, got %v", err)
+	}
+	before := settledCount(requests)
+
+	// Sleep past the whole duration: paused time must not count towards it.
+	time.Sleep(config.Duration)
+
+	if after := atomic.LoadInt64(requests); after != before {
+		t.Errorf("Expected no requests while paused, got %d more", after-before)
+	}
+	if status, _ := e.GetTestStatus("paused"); status.Status != "paused" {
+		t.Fatalf("Expected the test to stay paused past its duration, got %s", status.Status)
+	}
+
+	if err := e.ResumeTest("paused"); err != nil {
+		t.Fatalf("Expected the test to resume, got %v", err)
+	}
+	waitForStatus(t, e, "paused", "completed")
+
+	if after := atomic.LoadInt64(requests); after <= before {
+		t.Errorf("Expected requests after resuming, got %d before and %d after", before, after)
+	}
+}
+
+func TestCancelStopsRequestsPromptly(t *testing.T) {
+	server, requests := newCountingServer(t)
+	e := NewEngine()
+
+	config := shortTestConfig(server.URL)
+	config.Duration = time.Minute
+
+	if err := e.StartLoadTest("cancelled", config); err != nil {
+		t.Fatalf("Expected the test to start, got %v", err)
+	}
+	waitForStatus(t, e, "cancelled", "running")
+	time.Sleep(50 * time.Millisecond)
+
+	cancelledAt := time.Now()
+	if err := e.CancelTest("cancelled"); err != nil {
+		t.Fatalf("Expected the test to cancel, got %v", err)
+	}
+	before := settledCount(requests)
+
+	time.Sleep(100 * time.Millisecond)
+	if after := atomic.LoadInt64(requests); after != before {
+		t.Errorf("Expected no requests after cancelling, got %d more", after-before)
+	}
+
+	status := waitForStatus(t, e, "cancelled", "cancelled")
+	if status.EndTime.Sub(cancelledAt) > time.Second {
+		t.Errorf("Expected the test to end promptly, took %v", status.EndTime.Sub(cancelledAt))
+	}
+	if err := e.CancelTest("cancelled"); err == nil {
+		t.Error("Expected an error cancelling a test twice")
+	}
+}
+
+func TestCancelWhilePaused(t *testing.T) {
+	server, _ := newCountingServer(t)
+	e := NewEngine()
+
+	config := shortTestConfig(server.URL)
+	config.Duration = time.Minute
+
+	if err := e.StartLoadTest("paused-cancel", config); err != nil {
+		t.Fatalf("Expected the test to start, got %v", err)
+	}
+	waitForStatus(t, e, "paused-cancel", "running")
+
+	if err := e.PauseTest("paused-cancel"); err != nil {
+		t.Fatalf("Expected the test to pause, got %v", err)
+	}
+	if err := e.CancelTest("paused-cancel"); err != nil {
+		t.Fatalf("Expected a paused test to cancel, got %v", err)
+	}
+	if err := e.ResumeTest("paused-cancel"); err == nil {
+		t.Error("Expected an error resuming a cancelled test")
+	}
+
+	// Blocked users must wake up so the test records its results.
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := e.GetTestResults("paused-cancel"); err == nil {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("Expected the cancelled test to finish")
+}
+
+func TestPauseAndResumeRejectWrongStates(t *testing.T) {
+	server, _ := newCountingServer(t)
+	e := NewEngine()
+
+	config := shortTestConfig(server.URL)
+	config.Duration = time.Minute
+
+	if err := e.PauseTest("missing"); err == nil {
+		t.Error("Expected an error pausing an unknown test")
+	}
+
+	if err := e.StartLoadTest("states", config); err != nil {
+		t.Fatalf("Expected the test to start, got %v", err)
+	}
+	t.Cleanup(func() { e.CancelTest("states") })
+	waitForStatus(t, e, "states", "running")
+
+	if err := e.ResumeTest("states"); err == nil {
+		t.Error("Expected an error resuming a running test")
+	}
+	if err := e.PauseTest("states"); err != nil {
+		t.Fatalf("Expected the test to pause, got %v", err)
+	}
+	if err := e.PauseTest("states"); err == nil {
+		t.Error("Expected an error pausing a paused test")
+	}
+}
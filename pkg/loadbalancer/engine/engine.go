@@ -5,43 +5,172 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/cherry-pick/pkg/loadbalancer/core"
+	"github.com/cherry-pick/pkg/loadbalancer/utils"
+	"github.com/cherry-pick/pkg/logging"
+	"github.com/cherry-pick/pkg/tracing"
 )
 
+const maxCapturedBodySize = 4096
+
 type Engine struct {
-	client    HTTPClient
-	results   map[string][]core.LoadTestResult
-	summaries map[string]*core.LoadTestSummary
-	statuses  map[string]*core.LoadTestStatus
-	mu        sync.RWMutex
+	client       HTTPClient
+	results      map[string][]core.LoadTestResult
+	accumulators map[string]*ResultAccumulator
+	summaries    map[string]*core.LoadTestSummary
+	statuses     map[string]*core.LoadTestStatus
+	failures     map[string]*failureCapture
+	controls     map[string]*testControl
+	logger       logging.Logger
+	worker       string
+	region       string
+	mu           sync.RWMutex
+}
+
+type failureCapture struct {
+	mu       sync.Mutex
+	max      int
+	captured []core.CapturedFailure
+}
+
+func newFailureCapture(max int) *failureCapture {
+	return &failureCapture{
+		max:      max,
+		captured: make([]core.CapturedFailure, 0),
+	}
+}
+
+func (fc *failureCapture) full() bool {
+	if fc == nil {
+		return true
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return len(fc.captured) >= fc.max
+}
+
+func (fc *failureCapture) add(result core.LoadTestResult, body []byte) {
+	if fc == nil {
+		return
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if len(fc.captured) >= fc.max {
+		return
+	}
+
+	failure := core.CapturedFailure{
+		RequestID:  result.RequestID,
+		UserID:     result.UserID,
+		Timestamp:  result.StartTime,
+		StatusCode: result.StatusCode,
+		Error:      result.Error,
+	}
+
+	if len(body) > maxCapturedBodySize {
+		body = body[:maxCapturedBodySize]
+		failure.Truncated = true
+	}
+	failure.Body = string(body)
+
+	fc.captured = append(fc.captured, failure)
+}
+
+func (fc *failureCapture) snapshot() []core.CapturedFailure {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	captured := make([]core.CapturedFailure, len(fc.captured))
+	copy(captured, fc.captured)
+	return captured
 }
 
 type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+func DefaultClientOptions() core.HTTPClientOptions {
+	return core.HTTPClientOptions{
+		Timeout:             30 * time.Second,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 100,
+		IdleConnTimeout:     90 * time.Second,
+	}
+}
+
 func NewEngine() *Engine {
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-		Transport: &http.Transport{
-			MaxIdleConns:        100,
-			MaxIdleConnsPerHost: 100,
-			IdleConnTimeout:     90 * time.Second,
-		},
+	eng, _ := NewEngineWithOptions(DefaultClientOptions())
+	return eng
+}
+
+func NewEngineWithOptions(options core.HTTPClientOptions) (*Engine, error) {
+	defaults := DefaultClientOptions()
+	if options.Timeout == 0 {
+		options.Timeout = defaults.Timeout
+	}
+	if options.MaxIdleConns == 0 {
+		options.MaxIdleConns = defaults.MaxIdleConns
+	}
+	if options.MaxIdleConnsPerHost == 0 {
+		options.MaxIdleConnsPerHost = defaults.MaxIdleConnsPerHost
+	}
+	if options.IdleConnTimeout == 0 {
+		options.IdleConnTimeout = defaults.IdleConnTimeout
+	}
+
+	client, err := utils.NewHTTPClient(options)
+	if err != nil {
+		return nil, err
 	}
 
 	return &Engine{
-		client:    client,
-		results:   make(map[string][]core.LoadTestResult),
-		summaries: make(map[string]*core.LoadTestSummary),
-		statuses:  make(map[string]*core.LoadTestStatus),
+		client:       client,
+		results:      make(map[string][]core.LoadTestResult),
+		accumulators: make(map[string]*ResultAccumulator),
+		summaries:    make(map[string]*core.LoadTestSummary),
+		statuses:     make(map[string]*core.LoadTestStatus),
+		failures:     make(map[string]*failureCapture),
+		controls:     make(map[string]*testControl),
+		logger:       logging.Default(),
+	}, nil
+}
+
+// CloseIdleConnections closes the keep-alive connections the engine's client holds open
+// between requests. The engine stays usable; later requests dial new connections.
+func (e *Engine) CloseIdleConnections() {
+	if closer, ok := e.client.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+func (e *Engine) SetLogger(logger logging.Logger) {
+	if logger != nil {
+		e.logger = logger
 	}
 }
 
+func (e *Engine) SetWorkerLabel(worker, region string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.worker = worker
+	e.region = region
+	e.logger = e.logger.With("worker", worker)
+}
+
 func (e *Engine) StartLoadTest(testID string, config core.LoadTestConfig) error {
+	successCodes, err := core.ParseStatusCodes(config.SuccessStatusCodes)
+	if err != nil {
+		return fmt.Errorf("invalid success status codes: %w", err)
+	}
+
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -55,31 +184,70 @@ func (e *Engine) StartLoadTest(testID string, config core.LoadTestConfig) error
 		Progress: 0.0,
 	}
 
-	go e.runLoadTest(testID, config)
+	if config.CaptureFailures && config.MaxCapturedBodies > 0 {
+		e.failures[testID] = newFailureCapture(config.MaxCapturedBodies)
+	}
+	e.accumulators[testID] = NewResultAccumulator(config.LatencyBuckets)
+
+	go e.runLoadTest(testID, config, successCodes)
 	return nil
 }
 
-func (e *Engine) runLoadTest(testID string, config core.LoadTestConfig) {
+func (e *Engine) runLoadTest(testID string, config core.LoadTestConfig, successCodes []core.StatusCodeRange) {
+	ctx, cancel := context.WithCancel(context.Background())
+	control := newTestControl(cancel, config.Duration)
+
+	ctx, span := tracing.Start(ctx, "loadtest.run",
+		tracing.AttrTestID.String(testID),
+		tracing.AttrTargetURL.String(config.URL),
+		tracing.AttrUsers.Int(config.ConcurrentUsers))
+
 	e.mu.Lock()
+	if e.statuses[testID].Status == "cancelled" {
+		// Cancelled while still pending.
+		e.mu.Unlock()
+		control.stop()
+		span.End()
+		return
+	}
 	e.statuses[testID].Status = "running"
 	e.statuses[testID].StartTime = time.Now()
+	e.controls[testID] = control
 	e.mu.Unlock()
 
+	e.logger.Info("Starting load test", "testId", testID, "url", config.URL,
+		"users", config.ConcurrentUsers, "duration", config.Duration)
+
 	defer func() {
+		control.stop()
+
 		e.mu.Lock()
-		e.statuses[testID].Status = "completed"
+		delete(e.controls, testID)
+		if e.statuses[testID].Status != "cancelled" {
+			e.statuses[testID].Status = "completed"
+		}
 		e.statuses[testID].EndTime = time.Now()
 		e.statuses[testID].Progress = 1.0
+		if summary, ok := e.summaries[testID]; ok {
+			passed, exitCode := summary.Passed, summary.ExitCode
+			e.statuses[testID].Passed = &passed
+			e.statuses[testID].ExitCode = &exitCode
+			span.SetAttributes(tracing.AttrRequests.Int64(summary.TotalRequests))
+		}
+		span.SetAttributes(tracing.AttrTestStatus.String(e.statuses[testID].Status))
 		e.mu.Unlock()
-	}()
 
-	ctx, cancel := context.WithTimeout(context.Background(), config.Duration)
-	defer cancel()
+		span.End()
+	}()
 
 	resultsChan := make(chan core.LoadTestResult, config.ConcurrentUsers*10)
 	done := make(chan bool)
 
-	go e.collectResults(testID, resultsChan, done)
+	go e.collectResults(testID, config, resultsChan, done)
+
+	e.mu.RLock()
+	capture := e.failures[testID]
+	e.mu.RUnlock()
 
 	var wg sync.WaitGroup
 	startTime := time.Now()
@@ -88,7 +256,7 @@ func (e *Engine) runLoadTest(testID string, config core.LoadTestConfig) {
 		wg.Add(1)
 		go func(userID int) {
 			defer wg.Done()
-			e.runUser(ctx, userID, config, resultsChan)
+			e.runUser(ctx, control, userID, config, successCodes, capture, resultsChan)
 		}(i)
 	}
 
@@ -97,41 +265,62 @@ func (e *Engine) runLoadTest(testID string, config core.LoadTestConfig) {
 	<-done
 
 	e.generateSummary(testID, config, startTime)
+	e.logger.Info("Load test completed", "testId", testID, "elapsed", time.Since(startTime))
 }
 
-func (e *Engine) runUser(ctx context.Context, userID int, config core.LoadTestConfig, resultsChan chan<- core.LoadTestResult) {
-	ticker := time.NewTicker(config.RequestDelay)
-	defer ticker.Stop()
+func (e *Engine) runUser(ctx context.Context, control *testControl, userID int, config core.LoadTestConfig, successCodes []core.StatusCodeRange, capture *failureCapture, resultsChan chan<- core.LoadTestResult) {
+	thinkTime := newThinkTimer(userID, config)
+	timer := time.NewTimer(thinkTime.Next())
+	defer timer.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
-			result := e.makeRequest(userID, config)
+		case <-timer.C:
+			if !control.waitIfPaused(ctx) {
+				return
+			}
+			result := e.makeRequest(ctx, userID, config, successCodes, capture)
+			if ctx.Err() != nil {
+				return
+			}
 			select {
 			case resultsChan <- result:
 			case <-ctx.Done():
 				return
 			}
+			timer.Reset(thinkTime.Next())
 		}
 	}
 }
 
-func (e *Engine) makeRequest(userID int, config core.LoadTestConfig) core.LoadTestResult {
+// makeRequest counts a response as successful when its status is in successCodes, which
+// StartLoadTest parses once from the config's SuccessStatusCodes.
+func (e *Engine) makeRequest(ctx context.Context, userID int, config core.LoadTestConfig, successCodes []core.StatusCodeRange, capture *failureCapture) core.LoadTestResult {
 	startTime := time.Now()
 	result := core.LoadTestResult{
 		RequestID: fmt.Sprintf("%d-%d", userID, startTime.UnixNano()),
 		UserID:    userID,
 		StartTime: startTime,
+		Worker:    e.worker,
+		Region:    e.region,
+	}
+
+	var requestBody io.Reader
+	if config.Body != "" {
+		requestBody = strings.NewReader(config.Body)
 	}
 
-	req, err := http.NewRequest(config.Method, config.URL, nil)
+	req, err := http.NewRequestWithContext(ctx, config.Method, config.URL, requestBody)
 	if err != nil {
+		e.logger.Debug("Failed to build request", "userId", userID, "error", err)
 		result.Error = err.Error()
+		result.ErrorType = ErrorTypeInvalidRequest
 		result.EndTime = time.Now()
 		result.Duration = result.EndTime.Sub(result.StartTime)
 		result.Success = false
+		capture.add(result, nil)
 		return result
 	}
 
@@ -141,51 +330,126 @@ func (e *Engine) makeRequest(userID int, config core.LoadTestConfig) core.LoadTe
 
 	resp, err := e.client.Do(req)
 	if err != nil {
+		e.logger.Debug("Request failed", "userId", userID, "url", config.URL, "error", err)
 		result.Error = err.Error()
+		result.ErrorType = classifyRequestError(err)
 		result.EndTime = time.Now()
 		result.Duration = result.EndTime.Sub(result.StartTime)
 		result.Success = false
+		capture.add(result, nil)
 		return result
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	result.StatusCode = resp.StatusCode
+	success := core.StatusCodeMatches(successCodes, resp.StatusCode)
+
+	limit := config.MaxResponseBytes
+	if limit <= 0 {
+		limit = core.DefaultMaxResponseBytes
+	}
+	// One byte past the limit is read so a body of exactly limit bytes is not flagged.
+	reader := io.LimitReader(resp.Body, limit+1)
+
+	var read int64
+	var body []byte
+	if !success && !capture.full() {
+		body, err = io.ReadAll(io.LimitReader(reader, maxCapturedBodySize+1))
+		read = int64(len(body))
+	}
+	if err == nil {
+		var rest int64
+		rest, err = io.Copy(io.Discard, reader)
+		read += rest
+	}
+
+	size := read
+	result.BytesRead = read
+	if read > limit {
+		result.Truncated = true
+		if resp.ContentLength > 0 {
+			size = resp.ContentLength
+		}
+	}
+
 	if err != nil {
 		result.Error = err.Error()
 		result.EndTime = time.Now()
 		result.Duration = result.EndTime.Sub(result.StartTime)
 		result.Success = false
+		capture.add(result, body)
 		return result
 	}
 
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(result.StartTime)
-	result.StatusCode = resp.StatusCode
-	result.ResponseSize = int64(len(body))
-	result.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
+	result.ResponseSize = size
+	result.Success = success
+
+	if !success {
+		capture.add(result, body)
+	}
 
 	return result
 }
 
-func (e *Engine) collectResults(testID string, resultsChan <-chan core.LoadTestResult, done chan<- bool) {
-	var results []core.LoadTestResult
+// collectResults folds every result into the test's accumulator, and keeps the raw
+// results too only when retain is set. With a result stream the raw results go to its
+// sink in batches, and only the most recent ones are retained.
+func (e *Engine) collectResults(testID string, config core.LoadTestConfig, resultsChan <-chan core.LoadTestResult, done chan<- bool) {
+	results := []core.LoadTestResult{}
+
+	e.mu.RLock()
+	accumulator := e.accumulators[testID]
+	e.mu.RUnlock()
+
+	var stream *resultStream
+	var flushes <-chan time.Time
+	if config.ResultStream != nil {
+		stream = newResultStream(testID, config.ResultStream, config.RetainRawResults, e.logger)
+		ticker := time.NewTicker(stream.interval)
+		defer ticker.Stop()
+		flushes = ticker.C
+	}
 
-	for result := range resultsChan {
-		results = append(results, result)
+collect:
+	for {
+		select {
+		case <-flushes:
+			stream.flush()
+		case result, ok := <-resultsChan:
+			if !ok {
+				break collect
+			}
 
-		e.mu.Lock()
-		if status, exists := e.statuses[testID]; exists {
-			if status.StartTime.IsZero() {
-				status.Progress = 0.0
-			} else {
-				elapsed := time.Since(status.StartTime)
-				status.Progress = float64(elapsed) / float64(30*time.Second)
-				if status.Progress > 1.0 {
-					status.Progress = 1.0
+			accumulator.Add(result)
+			if stream != nil {
+				stream.add(result)
+			} else if config.RetainRawResults {
+				results = append(results, result)
+			}
+
+			e.mu.Lock()
+			if status, exists := e.statuses[testID]; exists {
+				if status.StartTime.IsZero() {
+					status.Progress = 0.0
+				} else {
+					elapsed := time.Since(status.StartTime)
+					status.Progress = float64(elapsed) / float64(30*time.Second)
+					if status.Progress > 1.0 {
+						status.Progress = 1.0
+					}
 				}
 			}
+			e.mu.Unlock()
 		}
-		e.mu.Unlock()
+	}
+
+	if stream != nil {
+		stream.flush()
+		results = stream.recent()
+		e.logger.Info("Streamed load test results", "testId", testID,
+			"flushed", stream.flushed, "failed", stream.failed)
 	}
 
 	e.mu.Lock()
@@ -199,64 +463,22 @@ func (e *Engine) generateSummary(testID string, config core.LoadTestConfig, star
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	results := e.results[testID]
-	if len(results) == 0 {
+	accumulator, exists := e.accumulators[testID]
+	if !exists || accumulator.Count() == 0 {
 		return
 	}
 
-	summary := &core.LoadTestSummary{
-		TestID:                   testID,
-		Config:                   config,
-		StartTime:                startTime,
-		EndTime:                  time.Now(),
-		TotalDuration:            time.Since(startTime),
-		TotalRequests:            int64(len(results)),
-		StatusCodes:              make(map[int]int64),
-		ResponseTimeDistribution: make(map[string]int64),
-	}
-
-	var totalResponseTime time.Duration
-	var minResponseTime, maxResponseTime time.Duration
-	var successfulRequests int64
-
-	for i, result := range results {
-		if result.Success {
-			successfulRequests++
-		}
-
-		summary.StatusCodes[result.StatusCode]++
-
-		totalResponseTime += result.Duration
-		if i == 0 || result.Duration < minResponseTime {
-			minResponseTime = result.Duration
-		}
-		if result.Duration > maxResponseTime {
-			maxResponseTime = result.Duration
-		}
+	e.summaries[testID] = accumulator.Summary(testID, config, startTime, time.Now())
+}
 
-		switch {
-		case result.Duration < 100*time.Millisecond:
-			summary.ResponseTimeDistribution["<100ms"]++
-		case result.Duration < 500*time.Millisecond:
-			summary.ResponseTimeDistribution["100-500ms"]++
-		case result.Duration < 1000*time.Millisecond:
-			summary.ResponseTimeDistribution["500ms-1s"]++
-		case result.Duration < 2000*time.Millisecond:
-			summary.ResponseTimeDistribution["1-2s"]++
-		default:
-			summary.ResponseTimeDistribution[">2s"]++
-		}
+// BuildSummary summarises raw results. Percentiles come from a LatencyDigest, the same
+// as for summaries built while a test runs.
+func BuildSummary(testID string, config core.LoadTestConfig, startTime, endTime time.Time, results []core.LoadTestResult) *core.LoadTestSummary {
+	accumulator := NewResultAccumulator(config.LatencyBuckets)
+	for _, result := range results {
+		accumulator.Add(result)
 	}
-
-	summary.SuccessfulRequests = successfulRequests
-	summary.FailedRequests = summary.TotalRequests - successfulRequests
-	summary.AverageResponseTime = totalResponseTime / time.Duration(len(results))
-	summary.MinResponseTime = minResponseTime
-	summary.MaxResponseTime = maxResponseTime
-	summary.RequestsPerSecond = float64(summary.TotalRequests) / summary.TotalDuration.Seconds()
-	summary.ErrorRate = float64(summary.FailedRequests) / float64(summary.TotalRequests) * 100
-
-	e.summaries[testID] = summary
+	return accumulator.Summary(testID, config, startTime, endTime)
 }
 
 func (e *Engine) GetTestStatus(testID string) (*core.LoadTestStatus, error) {
@@ -268,7 +490,9 @@ func (e *Engine) GetTestStatus(testID string) (*core.LoadTestStatus, error) {
 		return nil, fmt.Errorf("test with ID %s not found", testID)
 	}
 
-	return status, nil
+	// The running test keeps updating its status, so callers get a snapshot.
+	snapshot := *status
+	return &snapshot, nil
 }
 
 func (e *Engine) GetTestSummary(testID string) (*core.LoadTestSummary, error) {
@@ -295,6 +519,22 @@ func (e *Engine) GetTestResults(testID string) ([]core.LoadTestResult, error) {
 	return results, nil
 }
 
+func (e *Engine) GetCapturedFailures(testID string) ([]core.CapturedFailure, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if _, exists := e.statuses[testID]; !exists {
+		return nil, fmt.Errorf("test with ID %s not found", testID)
+	}
+
+	capture, exists := e.failures[testID]
+	if !exists {
+		return []core.CapturedFailure{}, nil
+	}
+
+	return capture.snapshot(), nil
+}
+
 func (e *Engine) CancelTest(testID string) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -304,14 +544,62 @@ func (e *Engine) CancelTest(testID string) error {
 		return fmt.Errorf("test with ID %s not found", testID)
 	}
 
-	if status.Status != "running" {
+	if status.Status != "pending" && status.Status != "running" && status.Status != "paused" {
 		return fmt.Errorf("test with ID %s is not running", testID)
 	}
 
+	if control, ok := e.controls[testID]; ok {
+		control.stop()
+	}
+
 	status.Status = "cancelled"
 	status.EndTime = time.Now()
 	status.Progress = 1.0
 
+	e.logger.Info("Load test cancelled", "testId", testID)
+
+	return nil
+}
+
+// PauseTest stops virtual users from issuing new requests without ending the test. Time
+// spent paused does not count towards the configured duration.
+func (e *Engine) PauseTest(testID string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	status, exists := e.statuses[testID]
+	if !exists {
+		return fmt.Errorf("test with ID %s not found", testID)
+	}
+
+	control, ok := e.controls[testID]
+	if status.Status != "running" || !ok || !control.pause() {
+		return fmt.Errorf("test with ID %s is not running", testID)
+	}
+
+	status.Status = "paused"
+	e.logger.Info("Load test paused", "testId", testID)
+
+	return nil
+}
+
+func (e *Engine) ResumeTest(testID string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	status, exists := e.statuses[testID]
+	if !exists {
+		return fmt.Errorf("test with ID %s not found", testID)
+	}
+
+	control, ok := e.controls[testID]
+	if status.Status != "paused" || !ok || !control.unpause() {
+		return fmt.Errorf("test with ID %s is not paused", testID)
+	}
+
+	status.Status = "running"
+	e.logger.Info("Load test resumed", "testId", testID)
+
 	return nil
 }
 
@@ -336,46 +624,24 @@ func (e *Engine) GetRealTimeMetrics(testID string) (*core.RealTimeMetrics, error
 		return nil, fmt.Errorf("test with ID %s not found", testID)
 	}
 
-	if status.Status != "running" {
+	if status.Status != "running" && status.Status != "paused" {
 		return nil, fmt.Errorf("test with ID %s is not running", testID)
 	}
 
-	results := e.results[testID]
-	if len(results) == 0 {
-		return &core.RealTimeMetrics{
-			TestID:    testID,
-			Timestamp: time.Now(),
-		}, nil
-	}
-
-	cutoff := time.Now().Add(-10 * time.Second)
-	var recentResults []core.LoadTestResult
-	var totalResponseTime time.Duration
-	var successfulRequests int64
-
-	for _, result := range results {
-		if result.StartTime.After(cutoff) {
-			recentResults = append(recentResults, result)
-			totalResponseTime += result.Duration
-			if result.Success {
-				successfulRequests++
-			}
-		}
+	metrics := &core.RealTimeMetrics{
+		TestID:      testID,
+		Timestamp:   time.Now(),
+		ActiveUsers: int(status.Progress * 100),
 	}
 
-	metrics := &core.RealTimeMetrics{
-		TestID:             testID,
-		Timestamp:          time.Now(),
-		ActiveUsers:        int(status.Progress * 100),
-		TotalRequests:      int64(len(results)),
-		SuccessfulRequests: successfulRequests,
-		FailedRequests:     int64(len(results)) - successfulRequests,
+	accumulator, exists := e.accumulators[testID]
+	if !exists {
+		return metrics, nil
 	}
+	accumulator.Metrics(metrics)
 
-	if len(recentResults) > 0 {
-		metrics.RequestsPerSecond = float64(len(recentResults)) / 10.0
-		metrics.AverageResponseTime = totalResponseTime / time.Duration(len(recentResults))
-		metrics.ErrorRate = float64(len(recentResults)-int(successfulRequests)) / float64(len(recentResults)) * 100
+	if elapsed := time.Since(status.StartTime); !status.StartTime.IsZero() && elapsed > 0 {
+		metrics.RequestsPerSecond = float64(metrics.TotalRequests) / elapsed.Seconds()
 	}
 
 	return metrics, nil
@@ -392,7 +658,9 @@ func (e *Engine) CleanupOldTests(olderThan time.Duration) {
 			if status.EndTime.Before(cutoff) {
 				delete(e.statuses, testID)
 				delete(e.results, testID)
+				delete(e.accumulators, testID)
 				delete(e.summaries, testID)
+				delete(e.failures, testID)
 			}
 		}
 	}
@@ -0,0 +1,216 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cherry-pick/pkg/loadbalancer/core"
+)
+
+func newFailingServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func shortTestConfig(url string) core.LoadTestConfig {
+	return core.LoadTestConfig{
+		URL:             url,
+		Method:          http.MethodGet,
+		ConcurrentUsers: 2,
+		Duration:        200 * time.Millisecond,
+		RequestDelay:    10 * time.Millisecond,
+	}
+}
+
+func waitForStatus(t *testing.T, e *Engine, testID string, want ...string) *core.LoadTestStatus {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if status, err := e.GetTestStatus(testID); err == nil {
+			for _, w := range want {
+				if status.Status == w {
+					return status
+				}
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Expected test %s to reach %v in time", testID, want)
+	return nil
+}
+
+func runToCompletion(t *testing.T, e *Engine, testID string, config core.LoadTestConfig) *core.LoadTestSummary {
+	t.Helper()
+
+	if err := e.StartLoadTest(testID, config); err != nil {
+		t.Fatalf("Expected the test to start, got %v", err)
+	}
+	waitForStatus(t, e, testID, "completed")
+
+	summary, err := e.GetTestSummary(testID)
+	if err != nil {
+		t.Fatalf("Expected a summary, got %v", err)
+	}
+	return summary
+}
+
+func TestFailureCaptureStopsAtMaxCapturedBodies(t *testing.T) {
+	server := newFailingServer(t, strings.Repeat("x", 2*maxCapturedBodySize))
+	e := NewEngine()
+
+	config := shortTestConfig(server.URL)
+	config.CaptureFailures = true
+	config.MaxCapturedBodies = 3
+
+	summary := runToCompletion(t, e, "failures", config)
+	if summary.FailedRequests <= int64(config.MaxCapturedBodies) {
+		t.Fatalf("Expected more than %d failed requests, got %d", config.MaxCapturedBodies, summary.FailedRequests)
+	}
+
+	failures, err := e.GetCapturedFailures("failures")
+	if err != nil {
+		t.Fatalf("Expected captured failures, got %v", err)
+	}
+	if len(failures) != config.MaxCapturedBodies {
+		t.Fatalf("Expected %d captured failures, got %d", config.MaxCapturedBodies, len(failures))
+	}
+	for _, failure := range failures {
+		if failure.StatusCode != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", failure.StatusCode)
+		}
+		if len(failure.Body) != maxCapturedBodySize || !failure.Truncated {
+			t.Errorf("Expected a truncated %d byte body, got %d bytes (truncated=%v)",
+				maxCapturedBodySize, len(failure.Body), failure.Truncated)
+		}
+	}
+}
+
+func TestFailureCaptureIsOffByDefault(t *testing.T) {
+	server := newFailingServer(t, "boom")
+	e := NewEngine()
+
+	runToCompletion(t, e, "uncaptured", shortTestConfig(server.URL))
+
+	failures, err := e.GetCapturedFailures("uncaptured")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(failures) != 0 {
+		t.Errorf("Expected no captured failures, got %d", len(failures))
+	}
+}
+
+func newSizedServer(t *testing.T, size int) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(size))
+		w.Write([]byte(strings.Repeat("x", size)))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func requestOnce(t *testing.T, url string, maxResponseBytes int64) core.LoadTestResult {
+	t.Helper()
+
+	config := shortTestConfig(url)
+	config.MaxResponseBytes = maxResponseBytes
+	successCodes, _ := core.ParseStatusCodes("")
+
+	return NewEngine().makeRequest(context.Background(), 0, config, successCodes, nil)
+}
+
+func TestMaxResponseBytesTruncatesLargeBodies(t *testing.T) {
+	server := newSizedServer(t, 10000)
+
+	result := requestOnce(t, server.URL, 1000)
+	if !result.Success {
+		t.Fatalf("Expected a truncated response to still succeed, got %s", result.Error)
+	}
+	if !result.Truncated {
+		t.Error("Expected the response to be marked truncated")
+	}
+	if result.BytesRead != 1001 {
+		t.Errorf("Expected 1001 bytes read, got %d", result.BytesRead)
+	}
+	if result.ResponseSize != 10000 {
+		t.Errorf("Expected the Content-Length of 10000 as the response size, got %d", result.ResponseSize)
+	}
+}
+
+func TestMaxResponseBytesKeepsBodyAtTheLimit(t *testing.T) {
+	server := newSizedServer(t, 1000)
+
+	result := requestOnce(t, server.URL, 1000)
+	if result.Truncated {
+		t.Error("Expected a body of exactly the limit not to be truncated")
+	}
+	if result.BytesRead != 1000 || result.ResponseSize != 1000 {
+		t.Errorf("Expected 1000 bytes read and reported, got %d and %d", result.BytesRead, result.ResponseSize)
+	}
+}
+
+func TestMaxResponseBytesDefaultsWhenUnset(t *testing.T) {
+	server := newSizedServer(t, 10000)
+
+	result := requestOnce(t, server.URL, 0)
+	if result.Truncated || result.BytesRead != 10000 {
+		t.Errorf("Expected the default limit to read all 10000 bytes, got %d (truncated=%v)", result.BytesRead, result.Truncated)
+	}
+}
+
+func TestSummaryCountsTruncatedResponses(t *testing.T) {
+	server := newSizedServer(t, 10000)
+	e := NewEngine()
+
+	config := shortTestConfig(server.URL)
+	config.MaxResponseBytes = 100
+
+	summary := runToCompletion(t, e, "truncated", config)
+	if summary.TotalRequests == 0 || summary.TruncatedResponses != summary.TotalRequests {
+		t.Errorf("Expected all %d responses truncated, got %d", summary.TotalRequests, summary.TruncatedResponses)
+	}
+}
+
+func TestSuccessStatusCodesClassifyResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	t.Cleanup(server.Close)
+	e := NewEngine()
+
+	summary := runToCompletion(t, e, "default-codes", shortTestConfig(server.URL))
+	if summary.SuccessfulRequests != 0 {
+		t.Errorf("Expected 304 to fail by default, got %d successes", summary.SuccessfulRequests)
+	}
+
+	config := shortTestConfig(server.URL)
+	config.SuccessStatusCodes = "200-299,304"
+
+	summary = runToCompletion(t, e, "with-304", config)
+	if summary.TotalRequests == 0 || summary.SuccessfulRequests != summary.TotalRequests {
+		t.Errorf("Expected all %d requests to succeed, got %d", summary.TotalRequests, summary.SuccessfulRequests)
+	}
+}
+
+func TestInvalidSuccessStatusCodesFailToStart(t *testing.T) {
+	config := shortTestConfig("http://localhost")
+	config.SuccessStatusCodes = "2xx"
+
+	if err := NewEngine().StartLoadTest("invalid-codes", config); err == nil {
+		t.Error("Expected an error for invalid success status codes")
+	}
+}
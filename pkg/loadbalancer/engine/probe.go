@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/url"
+	"syscall"
+
+	"github.com/cherry-pick/pkg/loadbalancer/core"
+)
+
+const (
+	ErrorTypeDNS               = "dns"
+	ErrorTypeTLS               = "tls"
+	ErrorTypeTimeout           = "timeout"
+	ErrorTypeConnectionRefused = "connection_refused"
+	ErrorTypeConnection        = "connection"
+	ErrorTypeInvalidRequest    = "invalid_request"
+)
+
+// Probe sends one request with the test's method, headers and body so a config can be
+// checked against the real target without starting a test. A non-2xx response still
+// counts as reachable.
+func (e *Engine) Probe(config core.LoadTestConfig) core.ProbeResult {
+	// Probes report the status code rather than success, so unparsable codes only leave
+	// every response counted as failed.
+	successCodes, _ := core.ParseStatusCodes(config.SuccessStatusCodes)
+	result := e.makeRequest(context.Background(), 0, config, successCodes, nil)
+
+	return core.ProbeResult{
+		URL:          config.URL,
+		Method:       config.Method,
+		Reachable:    result.StatusCode != 0,
+		StatusCode:   result.StatusCode,
+		ResponseTime: result.Duration,
+		ResponseSize: result.ResponseSize,
+		Error:        result.Error,
+		ErrorType:    result.ErrorType,
+	}
+}
+
+// classifyRequestError maps a transport error to one of the ErrorType constants.
+func classifyRequestError(err error) string {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ErrorTypeDNS
+	}
+
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidCert x509.CertificateInvalidError
+	var recordErr tls.RecordHeaderError
+	if errors.As(err, &certErr) || errors.As(err, &unknownAuthority) || errors.As(err, &hostnameErr) ||
+		errors.As(err, &invalidCert) || errors.As(err, &recordErr) {
+		return ErrorTypeTLS
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && urlErr.Timeout() {
+		return ErrorTypeTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorTypeTimeout
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return ErrorTypeConnectionRefused
+	}
+	return ErrorTypeConnection
+}
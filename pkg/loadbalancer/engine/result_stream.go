@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cherry-pick/pkg/loadbalancer/core"
+	"github.com/cherry-pick/pkg/logging"
+)
+
+// resultStream batches results for a sink and keeps a rolling window of the most recent
+// ones. It is only used from the collecting goroutine.
+type resultStream struct {
+	testID    string
+	sink      core.ResultSink
+	batchSize int
+	interval  time.Duration
+	batch     []core.LoadTestResult
+	window    []core.LoadTestResult
+	next      int
+	retain    bool
+	flushed   int64
+	failed    int64
+	logger    logging.Logger
+}
+
+func newResultStream(testID string, config *core.ResultStreamConfig, retain bool, logger logging.Logger) *resultStream {
+	rs := &resultStream{
+		testID:    testID,
+		sink:      config.Sink,
+		batchSize: config.BatchSize,
+		interval:  config.FlushInterval,
+		retain:    retain,
+		logger:    logger,
+	}
+	if rs.batchSize <= 0 {
+		rs.batchSize = core.DefaultResultBatchSize
+	}
+	if rs.interval <= 0 {
+		rs.interval = core.DefaultResultFlushInterval
+	}
+
+	size := config.Window
+	if size <= 0 {
+		size = core.DefaultResultWindow
+	}
+	if retain {
+		rs.window = make([]core.LoadTestResult, 0, size)
+	}
+	rs.batch = make([]core.LoadTestResult, 0, rs.batchSize)
+	return rs
+}
+
+func (rs *resultStream) add(result core.LoadTestResult) {
+	if rs.retain {
+		if len(rs.window) < cap(rs.window) {
+			rs.window = append(rs.window, result)
+		} else {
+			rs.window[rs.next] = result
+			rs.next = (rs.next + 1) % len(rs.window)
+		}
+	}
+
+	rs.batch = append(rs.batch, result)
+	if len(rs.batch) >= rs.batchSize {
+		rs.flush()
+	}
+}
+
+// flush hands the pending batch to the sink. A failed batch is logged and dropped so a
+// broken sink cannot grow memory without bound.
+func (rs *resultStream) flush() {
+	if len(rs.batch) == 0 {
+		return
+	}
+
+	if err := rs.sink.WriteResults(rs.testID, rs.batch); err != nil {
+		rs.failed += int64(len(rs.batch))
+		rs.logger.Warn("Failed to flush load test results", "testId", rs.testID,
+			"results", len(rs.batch), "error", err)
+	} else {
+		rs.flushed += int64(len(rs.batch))
+	}
+	// The sink may hold on to the slice it was given.
+	rs.batch = make([]core.LoadTestResult, 0, rs.batchSize)
+}
+
+// recent returns the rolling window oldest first.
+func (rs *resultStream) recent() []core.LoadTestResult {
+	results := make([]core.LoadTestResult, 0, len(rs.window))
+	results = append(results, rs.window[rs.next:]...)
+	return append(results, rs.window[:rs.next]...)
+}
+
+// JSONLFileSink appends each batch to a file as JSON lines, opening the file only for the
+// duration of a write, so nothing has to close it once the test ends.
+type JSONLFileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewJSONLFileSink(path string) *JSONLFileSink {
+	return &JSONLFileSink{path: path}
+}
+
+func (s *JSONLFileSink) WriteResults(testID string, results []core.LoadTestResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create result sink directory: %w", err)
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open result sink: %w", err)
+	}
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, result := range results {
+		if err := encoder.Encode(result); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
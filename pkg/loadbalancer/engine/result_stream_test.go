@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cherry-pick/pkg/loadbalancer/core"
+)
+
+func readJSONLResults(t *testing.T, path string) []core.LoadTestResult {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Expected the sink file to exist, got %v", err)
+	}
+	defer file.Close()
+
+	var results []core.LoadTestResult
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var result core.LoadTestResult
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			t.Fatalf("Expected a JSON result on line %d, got %v", len(results)+1, err)
+		}
+		results = append(results, result)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Expected to read the sink file, got %v", err)
+	}
+	return results
+}
+
+func TestJSONLFileSinkRoundTripsBatches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "results.jsonl")
+	sink := NewJSONLFileSink(path)
+
+	const total = 25
+	written := make([]core.LoadTestResult, 0, total)
+	for i := 0; i < total; i++ {
+		written = append(written, core.LoadTestResult{
+			RequestID:  fmt.Sprintf("req-%d", i),
+			UserID:     i % 3,
+			StatusCode: http.StatusOK,
+			Duration:   time.Duration(i) * time.Millisecond,
+			Success:    true,
+		})
+	}
+	for start := 0; start < total; start += 10 {
+		end := start + 10
+		if end > total {
+			end = total
+		}
+		if err := sink.WriteResults("round-trip", written[start:end]); err != nil {
+			t.Fatalf("Expected the batch to be written, got %v", err)
+		}
+	}
+
+	read := readJSONLResults(t, path)
+	if len(read) != total {
+		t.Fatalf("Expected %d results read back, got %d", total, len(read))
+	}
+	for i, result := range read {
+		if result.RequestID != written[i].RequestID || result.Duration != written[i].Duration {
+			t.Errorf("Expected result %d to be %s after %v, got %s after %v",
+				i, written[i].RequestID, written[i].Duration, result.RequestID, result.Duration)
+		}
+	}
+}
+
+func TestResultStreamWritesEveryResultToFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	e := NewEngine()
+
+	path := filepath.Join(t.TempDir(), "stream.jsonl")
+	config := shortTestConfig(server.URL)
+	config.ResultStream = &core.ResultStreamConfig{Sink: NewJSONLFileSink(path), BatchSize: 3}
+
+	summary := runToCompletion(t, e, "streamed", config)
+
+	read := readJSONLResults(t, path)
+	if int64(len(read)) != summary.TotalRequests {
+		t.Errorf("Expected %d streamed results, got %d", summary.TotalRequests, len(read))
+	}
+}
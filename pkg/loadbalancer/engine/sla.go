@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cherry-pick/pkg/loadbalancer/core"
+)
+
+// SLAFailedExitCode is reported in LoadTestSummary.ExitCode when a test misses its SLA,
+// so a CLI wrapper can pass it straight to os.Exit.
+const SLAFailedExitCode = 1
+
+// EvaluateSLA checks a summary against its thresholds and records the verdict on it.
+// A summary with no SLA configured always passes.
+func EvaluateSLA(summary *core.LoadTestSummary, sla *core.SLAConfig) {
+	summary.SLAViolations = nil
+
+	if sla != nil {
+		if sla.MaxP95Latency > 0 && summary.Percentile95 > sla.MaxP95Latency {
+			summary.SLAViolations = append(summary.SLAViolations, core.SLAViolation{
+				Metric:    "p95_latency_ms",
+				Threshold: durationMillis(sla.MaxP95Latency),
+				Actual:    durationMillis(summary.Percentile95),
+				Message:   fmt.Sprintf("p95 latency %v exceeds %v", summary.Percentile95, sla.MaxP95Latency),
+			})
+		}
+		if sla.MaxErrorRate > 0 && summary.ErrorRate > sla.MaxErrorRate {
+			summary.SLAViolations = append(summary.SLAViolations, core.SLAViolation{
+				Metric:    "error_rate",
+				Threshold: sla.MaxErrorRate,
+				Actual:    summary.ErrorRate,
+				Message:   fmt.Sprintf("error rate %.2f%% exceeds %.2f%%", summary.ErrorRate, sla.MaxErrorRate),
+			})
+		}
+		if sla.MinThroughput > 0 && summary.RequestsPerSecond < sla.MinThroughput {
+			summary.SLAViolations = append(summary.SLAViolations, core.SLAViolation{
+				Metric:    "throughput",
+				Threshold: sla.MinThroughput,
+				Actual:    summary.RequestsPerSecond,
+				Message:   fmt.Sprintf("throughput %.2f req/s is below %.2f req/s", summary.RequestsPerSecond, sla.MinThroughput),
+			})
+		}
+	}
+
+	summary.Passed = len(summary.SLAViolations) == 0
+	summary.ExitCode = 0
+	if !summary.Passed {
+		summary.ExitCode = SLAFailedExitCode
+	}
+}
+
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
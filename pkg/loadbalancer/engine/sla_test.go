@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cherry-pick/pkg/loadbalancer/core"
+)
+
+func slaSummary() *core.LoadTestSummary {
+	return &core.LoadTestSummary{
+		Percentile95:      200 * time.Millisecond,
+		ErrorRate:         2,
+		RequestsPerSecond: 50,
+	}
+}
+
+func TestSLAWithoutThresholdsPasses(t *testing.T) {
+	summary := slaSummary()
+	EvaluateSLA(summary, nil)
+
+	if !summary.Passed || summary.ExitCode != 0 || len(summary.SLAViolations) != 0 {
+		t.Errorf("Expected a pass with exit code 0, got passed=%v exit=%d violations=%v",
+			summary.Passed, summary.ExitCode, summary.SLAViolations)
+	}
+}
+
+func TestSLAPassesWithinThresholds(t *testing.T) {
+	summary := slaSummary()
+	EvaluateSLA(summary, &core.SLAConfig{
+		MaxP95Latency: 300 * time.Millisecond,
+		MaxErrorRate:  5,
+		MinThroughput: 10,
+	})
+
+	if !summary.Passed || summary.ExitCode != 0 {
+		t.Errorf("Expected a pass, got violations %v", summary.SLAViolations)
+	}
+}
+
+func TestSLAReportsEachViolatedThreshold(t *testing.T) {
+	summary := slaSummary()
+	EvaluateSLA(summary, &core.SLAConfig{
+		MaxP95Latency: 100 * time.Millisecond,
+		MaxErrorRate:  1,
+		MinThroughput: 100,
+	})
+
+	if summary.Passed || summary.ExitCode != SLAFailedExitCode {
+		t.Fatalf("Expected a failure with exit code %d, got passed=%v exit=%d",
+			SLAFailedExitCode, summary.Passed, summary.ExitCode)
+	}
+
+	want := map[string][2]float64{
+		"p95_latency_ms": {100, 200},
+		"error_rate":     {1, 2},
+		"throughput":     {100, 50},
+	}
+	if len(summary.SLAViolations) != len(want) {
+		t.Fatalf("Expected %d violations, got %v", len(want), summary.SLAViolations)
+	}
+	for _, violation := range summary.SLAViolations {
+		expected, ok := want[violation.Metric]
+		if !ok {
+			t.Errorf("Expected no violation for %s", violation.Metric)
+			continue
+		}
+		if violation.Threshold != expected[0] || violation.Actual != expected[1] {
+			t.Errorf("Expected %s threshold %v and actual %v, got %v and %v",
+				violation.Metric, expected[0], expected[1], violation.Threshold, violation.Actual)
+		}
+	}
+}
+
+func TestSLAReevaluationClearsEarlierViolations(t *testing.T) {
+	summary := slaSummary()
+	EvaluateSLA(summary, &core.SLAConfig{MaxErrorRate: 1})
+	EvaluateSLA(summary, &core.SLAConfig{MaxErrorRate: 5})
+
+	if !summary.Passed || len(summary.SLAViolations) != 0 {
+		t.Errorf("Expected a pass after relaxing the SLA, got %v", summary.SLAViolations)
+	}
+}
+
+func TestFailedSLAIsReportedOnTestStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+	e := NewEngine()
+
+	config := shortTestConfig(server.URL)
+	config.SLA = &core.SLAConfig{MaxErrorRate: 10}
+
+	summary := runToCompletion(t, e, "sla", config)
+	if summary.Passed {
+		t.Fatalf("Expected the SLA to fail at %.0f%% errors", summary.ErrorRate)
+	}
+
+	status, err := e.GetTestStatus("sla")
+	if err != nil {
+		t.Fatalf("Expected a status, got %v", err)
+	}
+	if status.Passed == nil || *status.Passed {
+		t.Errorf("Expected the status to report a failed SLA, got %v", status.Passed)
+	}
+	if status.ExitCode == nil || *status.ExitCode != SLAFailedExitCode {
+		t.Errorf("Expected exit code %d on the status, got %v", SLAFailedExitCode, status.ExitCode)
+	}
+}
@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/cherry-pick/pkg/loadbalancer/core"
+)
+
+type thinkTimer struct {
+	config core.ThinkTimeConfig
+	delay  time.Duration
+	rng    *rand.Rand
+}
+
+func newThinkTimer(userID int, config core.LoadTestConfig) *thinkTimer {
+	seed := time.Now().UnixNano() + int64(userID)
+	if config.Seed != 0 {
+		seed = config.Seed + int64(userID)
+	}
+
+	return &thinkTimer{
+		config: config.ThinkTime,
+		delay:  config.RequestDelay,
+		rng:    rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (tt *thinkTimer) Next() time.Duration {
+	switch tt.config.Distribution {
+	case core.ThinkTimeUniform:
+		if tt.config.Max <= tt.config.Min {
+			return tt.config.Min
+		}
+		return tt.config.Min + time.Duration(tt.rng.Int63n(int64(tt.config.Max-tt.config.Min)+1))
+	case core.ThinkTimeExponential:
+		if tt.config.Mean <= 0 {
+			return tt.delay
+		}
+		return time.Duration(tt.rng.ExpFloat64() * float64(tt.config.Mean))
+	default:
+		return tt.delay
+	}
+}
@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cherry-pick/pkg/loadbalancer/core"
+)
+
+func thinkTimeConfig(thinkTime core.ThinkTimeConfig) core.LoadTestConfig {
+	return core.LoadTestConfig{
+		RequestDelay: 50 * time.Millisecond,
+		ThinkTime:    thinkTime,
+		Seed:         42,
+	}
+}
+
+func TestConstantThinkTimeUsesRequestDelay(t *testing.T) {
+	for _, distribution := range []string{"", core.ThinkTimeConstant} {
+		tt := newThinkTimer(0, thinkTimeConfig(core.ThinkTimeConfig{Distribution: distribution}))
+		for i := 0; i < 10; i++ {
+			if got := tt.Next(); got != 50*time.Millisecond {
+				t.Fatalf("Expected 50ms for %q, got %v", distribution, got)
+			}
+		}
+	}
+}
+
+func TestUniformThinkTimeStaysWithinBounds(t *testing.T) {
+	tt := newThinkTimer(0, thinkTimeConfig(core.ThinkTimeConfig{
+		Distribution: core.ThinkTimeUniform,
+		Min:          10 * time.Millisecond,
+		Max:          20 * time.Millisecond,
+	}))
+
+	distinct := map[time.Duration]bool{}
+	for i := 0; i < 1000; i++ {
+		got := tt.Next()
+		if got < 10*time.Millisecond || got > 20*time.Millisecond {
+			t.Fatalf("Expected a delay between 10ms and 20ms, got %v", got)
+		}
+		distinct[got] = true
+	}
+	if len(distinct) < 2 {
+		t.Errorf("Expected varying delays, got %v", distinct)
+	}
+}
+
+func TestUniformThinkTimeWithEqualBoundsIsConstant(t *testing.T) {
+	tt := newThinkTimer(0, thinkTimeConfig(core.ThinkTimeConfig{
+		Distribution: core.ThinkTimeUniform,
+		Min:          15 * time.Millisecond,
+		Max:          15 * time.Millisecond,
+	}))
+
+	if got := tt.Next(); got != 15*time.Millisecond {
+		t.Errorf("Expected 15ms, got %v", got)
+	}
+}
+
+func TestExponentialThinkTimeAveragesToMean(t *testing.T) {
+	tt := newThinkTimer(0, thinkTimeConfig(core.ThinkTimeConfig{
+		Distribution: core.ThinkTimeExponential,
+		Mean:         100 * time.Millisecond,
+	}))
+
+	const samples = 10000
+	var total time.Duration
+	for i := 0; i < samples; i++ {
+		got := tt.Next()
+		if got < 0 {
+			t.Fatalf("Expected a non-negative delay, got %v", got)
+		}
+		total += got
+	}
+
+	mean := total / samples
+	if mean < 90*time.Millisecond || mean > 110*time.Millisecond {
+		t.Errorf("Expected a mean delay near 100ms, got %v", mean)
+	}
+}
+
+func TestExponentialThinkTimeWithoutMeanFallsBackToRequestDelay(t *testing.T) {
+	tt := newThinkTimer(0, thinkTimeConfig(core.ThinkTimeConfig{Distribution: core.ThinkTimeExponential}))
+
+	if got := tt.Next(); got != 50*time.Millisecond {
+		t.Errorf("Expected 50ms, got %v", got)
+	}
+}
+
+func TestSeededThinkTimeIsReproduciblePerUser(t *testing.T) {
+	config := thinkTimeConfig(core.ThinkTimeConfig{
+		Distribution: core.ThinkTimeUniform,
+		Min:          0,
+		Max:          time.Second,
+	})
+
+	first, again, other := newThinkTimer(1, config), newThinkTimer(1, config), newThinkTimer(2, config)
+
+	same, differs := true, false
+	for i := 0; i < 20; i++ {
+		a, b, c := first.Next(), again.Next(), other.Next()
+		if a != b {
+			same = false
+		}
+		if a != c {
+			differs = true
+		}
+	}
+	if !same {
+		t.Error("Expected the same seed and user to give the same delays")
+	}
+	if !differs {
+		t.Error("Expected different users to get different delays")
+	}
+}
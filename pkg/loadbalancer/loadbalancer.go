@@ -2,9 +2,12 @@ package loadbalancer
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/cherry-pick/pkg/loadbalancer/core"
+	"github.com/cherry-pick/pkg/loadbalancer/engine"
 	"github.com/cherry-pick/pkg/loadbalancer/manager"
 	"github.com/cherry-pick/pkg/loadbalancer/reporter"
 )
@@ -13,17 +16,52 @@ type LoadBalancer struct {
 	manager   core.TestManager
 	reporter  core.TestReporter
 	validator core.ConfigValidator
+	prober    *engine.Engine
+	outputDir string
 }
 
 func NewLoadBalancer(outputDir string) *LoadBalancer {
+	testManager := manager.NewManager()
+	testManager.SetMaxConcurrentTests(DefaultMaxConcurrentTests)
+
 	return &LoadBalancer{
-		manager:   manager.NewManager(),
+		manager:   testManager,
 		reporter:  reporter.NewReporter(outputDir),
 		validator: NewConfigValidator(),
+		prober:    engine.NewEngine(),
+		outputDir: outputDir,
 	}
 }
 
+// SetMaxConcurrentTests caps how many tests run at once across the server. Tests started
+// beyond the cap stay pending until a running test finishes; 0 removes the cap.
+func (lb *LoadBalancer) SetMaxConcurrentTests(limit int) {
+	lb.manager.SetMaxConcurrentTests(limit)
+}
+
 func (lb *LoadBalancer) StartTest(testID string, config core.LoadTestConfig) error {
+	config = applyTestDefaults(config)
+
+	if err := lb.validator.ValidateConfig(config); err != nil {
+		return err
+	}
+
+	return lb.manager.StartLoadTest(testID, config)
+}
+
+// ProbeTarget validates config and sends it as a single request, without starting a test.
+func (lb *LoadBalancer) ProbeTarget(config core.LoadTestConfig) (*core.ProbeResult, error) {
+	config = applyTestDefaults(config)
+
+	if err := lb.validator.ValidateConfig(config); err != nil {
+		return nil, err
+	}
+
+	result := lb.prober.Probe(config)
+	return &result, nil
+}
+
+func applyTestDefaults(config core.LoadTestConfig) core.LoadTestConfig {
 	if config.Method == "" {
 		config.Method = "GET"
 	}
@@ -33,12 +71,16 @@ func (lb *LoadBalancer) StartTest(testID string, config core.LoadTestConfig) err
 	if config.RequestDelay == 0 {
 		config.RequestDelay = 100 * time.Millisecond
 	}
-
-	if err := lb.validator.ValidateConfig(config); err != nil {
-		return err
+	if config.ThinkTime.Distribution == "" {
+		config.ThinkTime.Distribution = core.ThinkTimeConstant
 	}
-
-	return lb.manager.StartLoadTest(testID, config)
+	if config.CaptureFailures && config.MaxCapturedBodies == 0 {
+		config.MaxCapturedBodies = DefaultMaxCapturedBodies
+	}
+	if config.MaxResponseBytes == 0 {
+		config.MaxResponseBytes = core.DefaultMaxResponseBytes
+	}
+	return config
 }
 
 func (lb *LoadBalancer) GetTestStatus(testID string) (*core.LoadTestStatus, error) {
@@ -57,6 +99,14 @@ func (lb *LoadBalancer) CancelTest(testID string) error {
 	return lb.manager.CancelTest(testID)
 }
 
+func (lb *LoadBalancer) PauseTest(testID string) error {
+	return lb.manager.PauseTest(testID)
+}
+
+func (lb *LoadBalancer) ResumeTest(testID string) error {
+	return lb.manager.ResumeTest(testID)
+}
+
 func (lb *LoadBalancer) GetAllTests() map[string]*core.LoadTestStatus {
 	return lb.manager.GetAllTests()
 }
@@ -65,6 +115,10 @@ func (lb *LoadBalancer) GetRealTimeMetrics(testID string) (*core.RealTimeMetrics
 	return lb.manager.GetRealTimeMetrics(testID)
 }
 
+func (lb *LoadBalancer) GetCapturedFailures(testID string) ([]core.CapturedFailure, error) {
+	return lb.manager.GetCapturedFailures(testID)
+}
+
 func (lb *LoadBalancer) GenerateReport(testID string) error {
 	summary, err := lb.GetTestSummary(testID)
 	if err != nil {
@@ -93,11 +147,24 @@ func (lb *LoadBalancer) ValidateConfig(config core.LoadTestConfig) error {
 
 func (lb *LoadBalancer) ConvertRequestToConfig(req core.LoadTestRequest) core.LoadTestConfig {
 	config := core.LoadTestConfig{
-		URL:             req.URL,
-		ConcurrentUsers: req.ConcurrentUsers,
-		Headers:         req.Headers,
-		Method:          req.Method,
-		Body:            req.Body,
+		URL:               req.URL,
+		ConcurrentUsers:   req.ConcurrentUsers,
+		Headers:           req.Headers,
+		Method:            req.Method,
+		Body:              req.Body,
+		CaptureFailures:   req.CaptureFailures,
+		MaxCapturedBodies: req.MaxCapturedBodies,
+		ThinkTime: core.ThinkTimeConfig{
+			Distribution: strings.ToLower(req.ThinkTime),
+			Min:          time.Duration(req.ThinkTimeMin) * time.Millisecond,
+			Max:          time.Duration(req.ThinkTimeMax) * time.Millisecond,
+			Mean:         time.Duration(req.ThinkTimeMean) * time.Millisecond,
+		},
+		Seed:               req.Seed,
+		Workers:            req.Workers,
+		MaxResponseBytes:   req.MaxResponseBytes,
+		RetainRawResults:   req.RetainRawResults == nil || *req.RetainRawResults,
+		SuccessStatusCodes: req.SuccessStatusCodes,
 	}
 
 	if req.Duration > 0 {
@@ -112,5 +179,38 @@ func (lb *LoadBalancer) ConvertRequestToConfig(req core.LoadTestRequest) core.Lo
 		config.RequestDelay = time.Duration(req.RequestDelay) * time.Millisecond
 	}
 
+	if len(req.LatencyBuckets) > 0 {
+		config.LatencyBuckets = make([]time.Duration, len(req.LatencyBuckets))
+		for i, bound := range req.LatencyBuckets {
+			config.LatencyBuckets[i] = time.Duration(bound) * time.Millisecond
+		}
+	}
+
+	if req.ResultStream != nil {
+		config.ResultStream = &core.ResultStreamConfig{
+			Path:          req.ResultStream.Path,
+			BatchSize:     req.ResultStream.BatchSize,
+			FlushInterval: time.Duration(req.ResultStream.FlushInterval) * time.Millisecond,
+			Window:        req.ResultStream.Window,
+		}
+		if isPlainFileName(req.ResultStream.Path) {
+			config.ResultStream.Sink = engine.NewJSONLFileSink(filepath.Join(lb.outputDir, req.ResultStream.Path))
+		}
+	}
+
+	if req.SLA != nil {
+		config.SLA = &core.SLAConfig{
+			MaxP95Latency: time.Duration(req.SLA.MaxP95Latency) * time.Millisecond,
+			MaxErrorRate:  req.SLA.MaxErrorRate,
+			MinThroughput: req.SLA.MinThroughput,
+		}
+	}
+
 	return config
-}
\ No newline at end of file
+}
+
+// isPlainFileName reports whether name names a file directly inside a directory, so a
+// request cannot stream results outside the output directory.
+func isPlainFileName(name string) bool {
+	return name != "" && name != "." && name != ".." && filepath.Base(name) == name && !strings.ContainsAny(name, `/\`)
+}
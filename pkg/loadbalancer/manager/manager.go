@@ -11,12 +11,17 @@ import (
 
 type Manager struct {
 	engines map[string]core.LoadTestEngine
+	tests   map[string]string
 	mu      sync.RWMutex
+	queue   *testQueue
+	queueMu sync.Mutex
 }
 
 func NewManager() *Manager {
 	return &Manager{
 		engines: make(map[string]core.LoadTestEngine),
+		tests:   make(map[string]string),
+		queue:   newTestQueue(),
 	}
 }
 
@@ -82,48 +87,149 @@ func (m *Manager) GetDefaultEngine() core.LoadTestEngine {
 	return eng
 }
 
+// StartLoadTest runs the test now when fewer than the maximum concurrent tests are
+// running, and otherwise queues it until a running test finishes.
 func (m *Manager) StartLoadTest(testID string, config core.LoadTestConfig) error {
-	eng := m.GetDefaultEngine()
-	return eng.StartLoadTest(testID, config)
+	startNow, err := m.enqueueOrReserve(testID, config)
+	if err != nil || !startNow {
+		return err
+	}
+	if err := m.launch(testID, config); err != nil {
+		m.dispatchQueued()
+		return err
+	}
+	return nil
+}
+
+func (m *Manager) startDistributedTest(testID string, config core.LoadTestConfig) error {
+	engineID := "pool-" + testID
+	pool := NewInProcessWorkerPool(config.Workers)
+
+	m.mu.Lock()
+	if _, exists := m.engines[engineID]; exists {
+		m.mu.Unlock()
+		return fmt.Errorf("engine with ID %s already exists", engineID)
+	}
+	m.engines[engineID] = pool
+	m.tests[testID] = engineID
+	m.mu.Unlock()
+
+	if err := pool.StartLoadTest(testID, config); err != nil {
+		m.mu.Lock()
+		delete(m.engines, engineID)
+		delete(m.tests, testID)
+		m.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// distributedPool returns the worker pool running testID, or nil when the test runs on
+// the default engine.
+func (m *Manager) distributedPool(testID string) *WorkerPool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	pool, _ := m.engines[m.tests[testID]].(*WorkerPool)
+	return pool
+}
+
+func (m *Manager) engineFor(testID string) core.LoadTestEngine {
+	m.mu.RLock()
+	engineID, exists := m.tests[testID]
+	var eng core.LoadTestEngine
+	if exists {
+		eng = m.engines[engineID]
+	}
+	m.mu.RUnlock()
+
+	if eng != nil {
+		return eng
+	}
+	return m.GetDefaultEngine()
 }
 
 func (m *Manager) GetTestStatus(testID string) (*core.LoadTestStatus, error) {
-	eng := m.GetDefaultEngine()
-	return eng.GetTestStatus(testID)
+	if status, queued := m.queuedStatus(testID); queued {
+		return status, nil
+	}
+	return m.engineFor(testID).GetTestStatus(testID)
 }
 
 func (m *Manager) GetTestSummary(testID string) (*core.LoadTestSummary, error) {
-	eng := m.GetDefaultEngine()
-	return eng.GetTestSummary(testID)
+	return m.engineFor(testID).GetTestSummary(testID)
 }
 
 func (m *Manager) GetTestResults(testID string) ([]core.LoadTestResult, error) {
-	eng := m.GetDefaultEngine()
-	return eng.GetTestResults(testID)
+	return m.engineFor(testID).GetTestResults(testID)
 }
 
 func (m *Manager) CancelTest(testID string) error {
-	eng := m.GetDefaultEngine()
-	return eng.CancelTest(testID)
+	if m.cancelQueued(testID) {
+		return nil
+	}
+	return m.engineFor(testID).CancelTest(testID)
+}
+
+func (m *Manager) PauseTest(testID string) error {
+	return m.engineFor(testID).PauseTest(testID)
+}
+
+func (m *Manager) ResumeTest(testID string) error {
+	return m.engineFor(testID).ResumeTest(testID)
 }
 
 func (m *Manager) GetAllTests() map[string]*core.LoadTestStatus {
-	eng := m.GetDefaultEngine()
-	return eng.GetAllTests()
+	m.GetDefaultEngine()
+
+	m.mu.RLock()
+	engines := make([]core.LoadTestEngine, 0, len(m.engines))
+	for _, eng := range m.engines {
+		engines = append(engines, eng)
+	}
+	m.mu.RUnlock()
+
+	tests := m.queuedTests()
+	for _, eng := range engines {
+		for testID, status := range eng.GetAllTests() {
+			tests[testID] = status
+		}
+	}
+
+	return tests
 }
 
 func (m *Manager) GetRealTimeMetrics(testID string) (*core.RealTimeMetrics, error) {
-	eng := m.GetDefaultEngine()
-	return eng.GetRealTimeMetrics(testID)
+	return m.engineFor(testID).GetRealTimeMetrics(testID)
+}
+
+func (m *Manager) GetCapturedFailures(testID string) ([]core.CapturedFailure, error) {
+	return m.engineFor(testID).GetCapturedFailures(testID)
 }
 
 func (m *Manager) CleanupOldTests(olderThan time.Duration) {
+	m.cleanupDequeued(olderThan)
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	for _, eng := range m.engines {
 		eng.CleanupOldTests(olderThan)
 	}
+
+	// A distributed test's pool serves only that test, so it goes once the test does.
+	for testID, engineID := range m.tests {
+		pool, ok := m.engines[engineID].(*WorkerPool)
+		if ok && pool.hasTest(testID) {
+			continue
+		}
+		if ok {
+			pool.CloseIdleConnections()
+		}
+		delete(m.engines, engineID)
+		delete(m.tests, testID)
+	}
 }
 
 func (m *Manager) GetEngineStats() map[string]interface{} {
@@ -136,6 +242,7 @@ func (m *Manager) GetEngineStats() map[string]interface{} {
 		engineStats := map[string]interface{}{
 			"totalTests":     0,
 			"runningTests":   0,
+			"pausedTests":    0,
 			"completedTests": 0,
 			"failedTests":    0,
 			"cancelledTests": 0,
@@ -148,6 +255,8 @@ func (m *Manager) GetEngineStats() map[string]interface{} {
 			switch status.Status {
 			case "running":
 				engineStats["runningTests"] = engineStats["runningTests"].(int) + 1
+			case "paused":
+				engineStats["pausedTests"] = engineStats["pausedTests"].(int) + 1
 			case "completed":
 				engineStats["completedTests"] = engineStats["completedTests"].(int) + 1
 			case "failed":
@@ -159,6 +268,7 @@ func (m *Manager) GetEngineStats() map[string]interface{} {
 
 		stats[engineID] = engineStats
 	}
+	stats["queue"] = m.queueStats()
 
 	return stats
 }
@@ -0,0 +1,73 @@
+package manager
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cherry-pick/pkg/loadbalancer/core"
+)
+
+func distributedConfig(url string) core.LoadTestConfig {
+	config := poolTestConfig(url, 2)
+	config.Workers = []core.WorkerSpec{{Name: "a"}, {Name: "b"}}
+	return config
+}
+
+func poolEngines(m *Manager) []string {
+	var pools []string
+	for _, id := range m.ListEngines() {
+		if strings.HasPrefix(id, "pool-") {
+			pools = append(pools, id)
+		}
+	}
+	return pools
+}
+
+func TestDistributedTestPoolIsRemovedOnCleanup(t *testing.T) {
+	server := newTargetServer(t)
+	m := NewManager()
+
+	if err := m.StartLoadTest("distributed", distributedConfig(server.URL)); err != nil {
+		t.Fatalf("Expected the test to start, got %v", err)
+	}
+	if pools := poolEngines(m); len(pools) != 1 {
+		t.Fatalf("Expected 1 pool engine while the test runs, got %v", pools)
+	}
+
+	waitForStatus(t, m.engineFor("distributed"), "distributed", "completed")
+
+	summary, err := m.GetTestSummary("distributed")
+	if err != nil {
+		t.Fatalf("Expected the summary to stay readable after the test finished, got %v", err)
+	}
+	if len(summary.Workers) != 2 {
+		t.Errorf("Expected 2 worker summaries, got %d", len(summary.Workers))
+	}
+
+	m.CleanupOldTests(0)
+
+	if pools := poolEngines(m); len(pools) != 0 {
+		t.Errorf("Expected no pool engines after cleanup, got %v", pools)
+	}
+	m.mu.RLock()
+	tracked := len(m.tests)
+	m.mu.RUnlock()
+	if tracked != 0 {
+		t.Errorf("Expected no tracked distributed tests after cleanup, got %d", tracked)
+	}
+}
+
+func TestDistributedTestStartFailureLeavesNoPool(t *testing.T) {
+	server := newTargetServer(t)
+	m := NewManager()
+
+	config := distributedConfig(server.URL)
+	config.ConcurrentUsers = 1
+
+	if err := m.StartLoadTest("too-few-users", config); err == nil {
+		t.Fatal("Expected an error with fewer users than workers")
+	}
+	if pools := poolEngines(m); len(pools) != 0 {
+		t.Errorf("Expected no pool engines after a failed start, got %v", pools)
+	}
+}
@@ -0,0 +1,236 @@
+package manager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cherry-pick/pkg/loadbalancer/core"
+)
+
+// queuePollInterval is how often a running test is checked for completion to free its
+// slot for the next queued test.
+const queuePollInterval = 250 * time.Millisecond
+
+type queuedTest struct {
+	testID string
+	config core.LoadTestConfig
+}
+
+// testQueue caps how many tests run at once across all engines. Tests started beyond the
+// limit wait in FIFO order and report "pending" with their queue position.
+type testQueue struct {
+	limit   int
+	active  map[string]bool
+	waiting []queuedTest
+	// dequeued holds queued tests that were cancelled or failed to start, so their status
+	// stays visible.
+	dequeued map[string]*core.LoadTestStatus
+}
+
+func newTestQueue() *testQueue {
+	return &testQueue{
+		active:   make(map[string]bool),
+		dequeued: make(map[string]*core.LoadTestStatus),
+	}
+}
+
+func (q *testQueue) hasCapacity() bool {
+	return q.limit <= 0 || len(q.active) < q.limit
+}
+
+func (q *testQueue) position(testID string) int {
+	for i, queued := range q.waiting {
+		if queued.testID == testID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func (q *testQueue) pendingStatus(testID string, position int) *core.LoadTestStatus {
+	return &core.LoadTestStatus{
+		TestID:        testID,
+		Status:        "pending",
+		QueuePosition: position,
+		Message:       fmt.Sprintf("Waiting for a free slot (%d running, limit %d)", len(q.active), q.limit),
+	}
+}
+
+// SetMaxConcurrentTests caps how many tests run at once; 0 or less removes the cap.
+// Raising the limit starts queued tests straight away.
+func (m *Manager) SetMaxConcurrentTests(limit int) {
+	m.queueMu.Lock()
+	m.queue.limit = limit
+	m.queueMu.Unlock()
+
+	m.dispatchQueued()
+}
+
+// enqueueOrReserve either reserves a running slot for testID or queues it. It reports
+// whether the caller should start the test now.
+func (m *Manager) enqueueOrReserve(testID string, config core.LoadTestConfig) (bool, error) {
+	m.queueMu.Lock()
+	defer m.queueMu.Unlock()
+
+	if m.queue.active[testID] || m.queue.position(testID) > 0 || m.queue.dequeued[testID] != nil {
+		return false, fmt.Errorf("test with ID %s already exists", testID)
+	}
+
+	if m.queue.hasCapacity() && len(m.queue.waiting) == 0 {
+		m.queue.active[testID] = true
+		return true, nil
+	}
+
+	m.queue.waiting = append(m.queue.waiting, queuedTest{testID: testID, config: config})
+	return false, nil
+}
+
+// launch starts a test that holds a slot and watches it until it finishes. If the test
+// fails to start its slot is freed, but queued tests are left for the caller to dispatch.
+func (m *Manager) launch(testID string, config core.LoadTestConfig) error {
+	var err error
+	if len(config.Workers) > 1 {
+		err = m.startDistributedTest(testID, config)
+	} else {
+		err = m.GetDefaultEngine().StartLoadTest(testID, config)
+	}
+	if err != nil {
+		m.freeSlot(testID)
+		return err
+	}
+
+	go m.awaitCompletion(testID)
+	return nil
+}
+
+func (m *Manager) awaitCompletion(testID string) {
+	ticker := time.NewTicker(queuePollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		status, err := m.engineFor(testID).GetTestStatus(testID)
+		if err != nil || isFinished(status.Status) {
+			break
+		}
+	}
+
+	if pool := m.distributedPool(testID); pool != nil {
+		pool.CloseIdleConnections()
+	}
+	m.release(testID)
+}
+
+func isFinished(status string) bool {
+	return status == "completed" || status == "failed" || status == "cancelled"
+}
+
+func (m *Manager) release(testID string) {
+	m.freeSlot(testID)
+	m.dispatchQueued()
+}
+
+func (m *Manager) freeSlot(testID string) {
+	m.queueMu.Lock()
+	delete(m.queue.active, testID)
+	m.queueMu.Unlock()
+}
+
+// dispatchQueued starts queued tests, oldest first, while there is capacity. A test that
+// fails to start frees its slot and the loop moves on to the next one.
+func (m *Manager) dispatchQueued() {
+	for {
+		m.queueMu.Lock()
+		if len(m.queue.waiting) == 0 || !m.queue.hasCapacity() {
+			m.queueMu.Unlock()
+			return
+		}
+		next := m.queue.waiting[0]
+		m.queue.waiting = m.queue.waiting[1:]
+		m.queue.active[next.testID] = true
+		m.queueMu.Unlock()
+
+		if err := m.launch(next.testID, next.config); err != nil {
+			m.queueMu.Lock()
+			m.queue.dequeued[next.testID] = &core.LoadTestStatus{
+				TestID:  next.testID,
+				Status:  "failed",
+				EndTime: time.Now(),
+				Message: err.Error(),
+			}
+			m.queueMu.Unlock()
+		}
+	}
+}
+
+// queuedStatus returns the status of a test that has not reached an engine yet.
+func (m *Manager) queuedStatus(testID string) (*core.LoadTestStatus, bool) {
+	m.queueMu.Lock()
+	defer m.queueMu.Unlock()
+
+	if position := m.queue.position(testID); position > 0 {
+		return m.queue.pendingStatus(testID, position), true
+	}
+	if status, exists := m.queue.dequeued[testID]; exists {
+		return status, true
+	}
+	return nil, false
+}
+
+// cancelQueued removes testID from the queue. It reports false when the test is not
+// queued.
+func (m *Manager) cancelQueued(testID string) bool {
+	m.queueMu.Lock()
+	defer m.queueMu.Unlock()
+
+	position := m.queue.position(testID)
+	if position == 0 {
+		return false
+	}
+
+	m.queue.waiting = append(m.queue.waiting[:position-1], m.queue.waiting[position:]...)
+	m.queue.dequeued[testID] = &core.LoadTestStatus{
+		TestID:   testID,
+		Status:   "cancelled",
+		Progress: 1.0,
+		EndTime:  time.Now(),
+		Message:  "Cancelled before it started",
+	}
+	return true
+}
+
+func (m *Manager) queuedTests() map[string]*core.LoadTestStatus {
+	m.queueMu.Lock()
+	defer m.queueMu.Unlock()
+
+	tests := make(map[string]*core.LoadTestStatus, len(m.queue.waiting)+len(m.queue.dequeued))
+	for i, queued := range m.queue.waiting {
+		tests[queued.testID] = m.queue.pendingStatus(queued.testID, i+1)
+	}
+	for testID, status := range m.queue.dequeued {
+		tests[testID] = status
+	}
+	return tests
+}
+
+func (m *Manager) queueStats() map[string]interface{} {
+	m.queueMu.Lock()
+	defer m.queueMu.Unlock()
+
+	return map[string]interface{}{
+		"maxConcurrentTests": m.queue.limit,
+		"activeTests":        len(m.queue.active),
+		"queuedTests":        len(m.queue.waiting),
+	}
+}
+
+func (m *Manager) cleanupDequeued(olderThan time.Duration) {
+	m.queueMu.Lock()
+	defer m.queueMu.Unlock()
+
+	cutoff := time.Now().Add(-olderThan)
+	for testID, status := range m.queue.dequeued {
+		if status.EndTime.Before(cutoff) {
+			delete(m.queue.dequeued, testID)
+		}
+	}
+}
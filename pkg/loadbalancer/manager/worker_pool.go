@@ -0,0 +1,400 @@
+package manager
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cherry-pick/pkg/loadbalancer/core"
+	"github.com/cherry-pick/pkg/loadbalancer/engine"
+)
+
+type InProcessWorker struct {
+	name   string
+	region string
+	engine *engine.Engine
+}
+
+func NewInProcessWorker(name, region string) *InProcessWorker {
+	eng := engine.NewEngine()
+	eng.SetWorkerLabel(name, region)
+
+	return &InProcessWorker{
+		name:   name,
+		region: region,
+		engine: eng,
+	}
+}
+
+func (w *InProcessWorker) Name() string {
+	return w.name
+}
+
+func (w *InProcessWorker) Region() string {
+	return w.region
+}
+
+func (w *InProcessWorker) Engine() core.LoadTestEngine {
+	return w.engine
+}
+
+type WorkerPool struct {
+	workers []core.Worker
+	configs map[string]core.LoadTestConfig
+	users   map[string]map[string]int
+	mu      sync.RWMutex
+}
+
+func NewWorkerPool(workers ...core.Worker) *WorkerPool {
+	return &WorkerPool{
+		workers: workers,
+		configs: make(map[string]core.LoadTestConfig),
+		users:   make(map[string]map[string]int),
+	}
+}
+
+func NewInProcessWorkerPool(specs []core.WorkerSpec) *WorkerPool {
+	workers := make([]core.Worker, 0, len(specs))
+	for _, spec := range specs {
+		workers = append(workers, NewInProcessWorker(spec.Name, spec.Region))
+	}
+	return NewWorkerPool(workers...)
+}
+
+func (wp *WorkerPool) Workers() []core.Worker {
+	return wp.workers
+}
+
+func (wp *WorkerPool) StartLoadTest(testID string, config core.LoadTestConfig) error {
+	if len(wp.workers) == 0 {
+		return fmt.Errorf("worker pool has no workers")
+	}
+	if config.ConcurrentUsers < len(wp.workers) {
+		return fmt.Errorf("concurrent users (%d) must be at least the number of workers (%d)",
+			config.ConcurrentUsers, len(wp.workers))
+	}
+
+	wp.mu.Lock()
+	if _, exists := wp.configs[testID]; exists {
+		wp.mu.Unlock()
+		return fmt.Errorf("test with ID %s already exists", testID)
+	}
+	wp.configs[testID] = config
+	wp.users[testID] = make(map[string]int)
+	wp.mu.Unlock()
+
+	perWorker := config.ConcurrentUsers / len(wp.workers)
+	remainder := config.ConcurrentUsers % len(wp.workers)
+
+	for i, worker := range wp.workers {
+		workerConfig := config
+		workerConfig.Workers = nil
+		// SLA thresholds apply to the combined run and are evaluated in GetTestSummary
+		workerConfig.SLA = nil
+		workerConfig.ConcurrentUsers = perWorker
+		if i < remainder {
+			workerConfig.ConcurrentUsers++
+		}
+
+		if err := worker.Engine().StartLoadTest(testID, workerConfig); err != nil {
+			wp.abortStart(testID, wp.workers[:i])
+			return fmt.Errorf("failed to start test on worker %s: %w", worker.Name(), err)
+		}
+
+		wp.mu.Lock()
+		wp.users[testID][worker.Name()] = workerConfig.ConcurrentUsers
+		wp.mu.Unlock()
+	}
+
+	return nil
+}
+
+// abortStart cancels the test on the workers it already started on and forgets it, so
+// a test that failed to start on one worker does not keep running on the others.
+func (wp *WorkerPool) abortStart(testID string, started []core.Worker) {
+	for _, worker := range started {
+		worker.Engine().CancelTest(testID)
+	}
+
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	delete(wp.configs, testID)
+	delete(wp.users, testID)
+}
+
+func (wp *WorkerPool) hasTest(testID string) bool {
+	wp.mu.RLock()
+	defer wp.mu.RUnlock()
+
+	_, exists := wp.configs[testID]
+	return exists
+}
+
+// CloseIdleConnections closes the idle connections of every worker engine that keeps
+// any, so a pool whose test has finished does not hold sockets to the target.
+func (wp *WorkerPool) CloseIdleConnections() {
+	for _, worker := range wp.workers {
+		if closer, ok := worker.Engine().(interface{ CloseIdleConnections() }); ok {
+			closer.CloseIdleConnections()
+		}
+	}
+}
+
+func (wp *WorkerPool) GetTestStatus(testID string) (*core.LoadTestStatus, error) {
+	aggregate := &core.LoadTestStatus{TestID: testID}
+	statuses := make(map[string]int)
+
+	for _, worker := range wp.workers {
+		status, err := worker.Engine().GetTestStatus(testID)
+		if err != nil {
+			return nil, fmt.Errorf("worker %s: %w", worker.Name(), err)
+		}
+
+		statuses[status.Status]++
+		aggregate.Progress += status.Progress / float64(len(wp.workers))
+
+		if aggregate.StartTime.IsZero() || (!status.StartTime.IsZero() && status.StartTime.Before(aggregate.StartTime)) {
+			aggregate.StartTime = status.StartTime
+		}
+		if status.EndTime.After(aggregate.EndTime) {
+			aggregate.EndTime = status.EndTime
+		}
+	}
+
+	switch {
+	case statuses["running"] > 0:
+		aggregate.Status = "running"
+		aggregate.EndTime = time.Time{}
+	case statuses["paused"] > 0:
+		aggregate.Status = "paused"
+		aggregate.EndTime = time.Time{}
+	case statuses["pending"] > 0:
+		aggregate.Status = "pending"
+	case statuses["failed"] > 0:
+		aggregate.Status = "failed"
+	case statuses["cancelled"] > 0:
+		aggregate.Status = "cancelled"
+	default:
+		aggregate.Status = "completed"
+		if summary, err := wp.GetTestSummary(testID); err == nil {
+			passed, exitCode := summary.Passed, summary.ExitCode
+			aggregate.Passed = &passed
+			aggregate.ExitCode = &exitCode
+		}
+	}
+
+	return aggregate, nil
+}
+
+func (wp *WorkerPool) GetTestSummary(testID string) (*core.LoadTestSummary, error) {
+	wp.mu.RLock()
+	config, exists := wp.configs[testID]
+	users := wp.users[testID]
+	wp.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("test summary with ID %s not found", testID)
+	}
+
+	accumulator := engine.NewResultAccumulator(config.LatencyBuckets)
+	var startTime, endTime time.Time
+	workerSummaries := make([]core.WorkerSummary, 0, len(wp.workers))
+
+	for _, worker := range wp.workers {
+		summary, err := wp.workerSummary(worker, testID, config)
+		if err != nil {
+			return nil, fmt.Errorf("worker %s: %w", worker.Name(), err)
+		}
+
+		accumulator.AddSummary(summary)
+
+		if startTime.IsZero() || summary.StartTime.Before(startTime) {
+			startTime = summary.StartTime
+		}
+		if summary.EndTime.After(endTime) {
+			endTime = summary.EndTime
+		}
+
+		workerSummaries = append(workerSummaries, core.WorkerSummary{
+			Worker:              worker.Name(),
+			Region:              worker.Region(),
+			ConcurrentUsers:     users[worker.Name()],
+			TotalRequests:       summary.TotalRequests,
+			SuccessfulRequests:  summary.SuccessfulRequests,
+			FailedRequests:      summary.FailedRequests,
+			AverageResponseTime: summary.AverageResponseTime,
+			MinResponseTime:     summary.MinResponseTime,
+			MaxResponseTime:     summary.MaxResponseTime,
+			RequestsPerSecond:   summary.RequestsPerSecond,
+			ErrorRate:           summary.ErrorRate,
+		})
+	}
+
+	overall := accumulator.Summary(testID, config, startTime, endTime)
+	overall.Workers = workerSummaries
+
+	return overall, nil
+}
+
+// workerSummary returns an empty summary for a worker that finished without recording
+// any results, since engines only build a summary once they have at least one.
+func (wp *WorkerPool) workerSummary(worker core.Worker, testID string, config core.LoadTestConfig) (*core.LoadTestSummary, error) {
+	summary, err := worker.Engine().GetTestSummary(testID)
+	if err == nil {
+		return summary, nil
+	}
+
+	status, statusErr := worker.Engine().GetTestStatus(testID)
+	if statusErr != nil || status.Status == "pending" || status.Status == "running" || status.Status == "paused" {
+		return nil, err
+	}
+	return engine.NewResultAccumulator(config.LatencyBuckets).Summary(testID, config, status.StartTime, status.EndTime), nil
+}
+
+func (wp *WorkerPool) GetTestResults(testID string) ([]core.LoadTestResult, error) {
+	var allResults []core.LoadTestResult
+
+	for _, worker := range wp.workers {
+		results, err := worker.Engine().GetTestResults(testID)
+		if err != nil {
+			return nil, fmt.Errorf("worker %s: %w", worker.Name(), err)
+		}
+		allResults = append(allResults, results...)
+	}
+
+	return allResults, nil
+}
+
+func (wp *WorkerPool) GetCapturedFailures(testID string) ([]core.CapturedFailure, error) {
+	var allFailures []core.CapturedFailure
+
+	for _, worker := range wp.workers {
+		failures, err := worker.Engine().GetCapturedFailures(testID)
+		if err != nil {
+			return nil, fmt.Errorf("worker %s: %w", worker.Name(), err)
+		}
+		allFailures = append(allFailures, failures...)
+	}
+
+	return allFailures, nil
+}
+
+func (wp *WorkerPool) CancelTest(testID string) error {
+	var lastErr error
+	cancelled := 0
+
+	for _, worker := range wp.workers {
+		if err := worker.Engine().CancelTest(testID); err != nil {
+			lastErr = err
+			continue
+		}
+		cancelled++
+	}
+
+	if cancelled == 0 && lastErr != nil {
+		return lastErr
+	}
+	return nil
+}
+
+// PauseTest pauses the test on every worker; it fails only if no worker could be paused.
+func (wp *WorkerPool) PauseTest(testID string) error {
+	return wp.eachWorker(testID, core.LoadTestEngine.PauseTest)
+}
+
+func (wp *WorkerPool) ResumeTest(testID string) error {
+	return wp.eachWorker(testID, core.LoadTestEngine.ResumeTest)
+}
+
+func (wp *WorkerPool) eachWorker(testID string, action func(core.LoadTestEngine, string) error) error {
+	var lastErr error
+	succeeded := 0
+
+	for _, worker := range wp.workers {
+		if err := action(worker.Engine(), testID); err != nil {
+			lastErr = err
+			continue
+		}
+		succeeded++
+	}
+
+	if succeeded == 0 && lastErr != nil {
+		return lastErr
+	}
+	return nil
+}
+
+func (wp *WorkerPool) GetAllTests() map[string]*core.LoadTestStatus {
+	wp.mu.RLock()
+	testIDs := make([]string, 0, len(wp.configs))
+	for testID := range wp.configs {
+		testIDs = append(testIDs, testID)
+	}
+	wp.mu.RUnlock()
+
+	tests := make(map[string]*core.LoadTestStatus)
+	for _, testID := range testIDs {
+		if status, err := wp.GetTestStatus(testID); err == nil {
+			tests[testID] = status
+		}
+	}
+
+	return tests
+}
+
+func (wp *WorkerPool) GetRealTimeMetrics(testID string) (*core.RealTimeMetrics, error) {
+	aggregate := &core.RealTimeMetrics{
+		TestID:    testID,
+		Timestamp: time.Now(),
+	}
+
+	var weightedResponseTime time.Duration
+	var running int
+
+	for _, worker := range wp.workers {
+		metrics, err := worker.Engine().GetRealTimeMetrics(testID)
+		if err != nil {
+			continue
+		}
+		running++
+
+		aggregate.ActiveUsers += metrics.ActiveUsers
+		aggregate.RequestsPerSecond += metrics.RequestsPerSecond
+		aggregate.TotalRequests += metrics.TotalRequests
+		aggregate.SuccessfulRequests += metrics.SuccessfulRequests
+		aggregate.FailedRequests += metrics.FailedRequests
+		weightedResponseTime += metrics.AverageResponseTime * time.Duration(metrics.TotalRequests)
+	}
+
+	if running == 0 {
+		return nil, fmt.Errorf("test with ID %s is not running", testID)
+	}
+
+	if aggregate.TotalRequests > 0 {
+		aggregate.AverageResponseTime = weightedResponseTime / time.Duration(aggregate.TotalRequests)
+		aggregate.ErrorRate = float64(aggregate.FailedRequests) / float64(aggregate.TotalRequests) * 100
+	}
+
+	return aggregate, nil
+}
+
+func (wp *WorkerPool) CleanupOldTests(olderThan time.Duration) {
+	if len(wp.workers) == 0 {
+		return
+	}
+
+	for _, worker := range wp.workers {
+		worker.Engine().CleanupOldTests(olderThan)
+	}
+
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	for testID := range wp.configs {
+		if _, err := wp.workers[0].Engine().GetTestStatus(testID); err != nil {
+			delete(wp.configs, testID)
+			delete(wp.users, testID)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package manager
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cherry-pick/pkg/loadbalancer/core"
+)
+
+// brokenEngine stands in for a worker engine that cannot start tests and never records a
+// summary. Calls it does not override panic on the nil embedded interface.
+type brokenEngine struct {
+	core.LoadTestEngine
+	startErr error
+	status   *core.LoadTestStatus
+}
+
+func (b *brokenEngine) StartLoadTest(testID string, config core.LoadTestConfig) error {
+	return b.startErr
+}
+
+func (b *brokenEngine) GetTestStatus(testID string) (*core.LoadTestStatus, error) {
+	if b.status == nil {
+		return nil, errors.New("test not found")
+	}
+	return b.status, nil
+}
+
+func (b *brokenEngine) GetTestSummary(testID string) (*core.LoadTestSummary, error) {
+	return nil, errors.New("test summary not found")
+}
+
+type brokenWorker struct {
+	engine *brokenEngine
+}
+
+func (w *brokenWorker) Name() string                { return "broken" }
+func (w *brokenWorker) Region() string              { return "nowhere" }
+func (w *brokenWorker) Engine() core.LoadTestEngine { return w.engine }
+
+func newTargetServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func poolTestConfig(url string, users int) core.LoadTestConfig {
+	return core.LoadTestConfig{
+		URL:              url,
+		Method:           http.MethodGet,
+		ConcurrentUsers:  users,
+		Duration:         200 * time.Millisecond,
+		RequestDelay:     10 * time.Millisecond,
+		RetainRawResults: true,
+	}
+}
+
+func waitForStatus(t *testing.T, engine core.LoadTestEngine, testID string, want ...string) *core.LoadTestStatus {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if status, err := engine.GetTestStatus(testID); err == nil {
+			for _, w := range want {
+				if status.Status == w {
+					return status
+				}
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("Expected test %s to reach %v in time", testID, want)
+	return nil
+}
+
+func TestWorkerPoolAggregatesWorkerResults(t *testing.T) {
+	server := newTargetServer(t)
+	pool := NewInProcessWorkerPool([]core.WorkerSpec{{Name: "a", Region: "eu"}, {Name: "b", Region: "us"}})
+
+	if err := pool.StartLoadTest("pooled", poolTestConfig(server.URL, 3)); err != nil {
+		t.Fatalf("Expected the test to start, got %v", err)
+	}
+	waitForStatus(t, pool, "pooled", "completed")
+
+	summary, err := pool.GetTestSummary("pooled")
+	if err != nil {
+		t.Fatalf("Expected a summary, got %v", err)
+	}
+	if len(summary.Workers) != 2 {
+		t.Fatalf("Expected 2 worker summaries, got %d", len(summary.Workers))
+	}
+
+	var workerRequests int64
+	users := map[string]int{}
+	for _, worker := range summary.Workers {
+		workerRequests += worker.TotalRequests
+		users[worker.Worker] = worker.ConcurrentUsers
+	}
+	if summary.TotalRequests == 0 || summary.TotalRequests != workerRequests {
+		t.Errorf("Expected %d total requests summed over workers, got %d", workerRequests, summary.TotalRequests)
+	}
+	if users["a"] != 2 || users["b"] != 1 {
+		t.Errorf("Expected 3 users split 2/1, got %v", users)
+	}
+
+	results, err := pool.GetTestResults("pooled")
+	if err != nil {
+		t.Fatalf("Expected results, got %v", err)
+	}
+	if int64(len(results)) != summary.TotalRequests {
+		t.Errorf("Expected %d results, got %d", summary.TotalRequests, len(results))
+	}
+	regions := map[string]bool{}
+	for _, result := range results {
+		regions[result.Region] = true
+	}
+	if !regions["eu"] || !regions["us"] {
+		t.Errorf("Expected results from both regions, got %v", regions)
+	}
+}
+
+func TestWorkerPoolStartFailureCancelsStartedWorkers(t *testing.T) {
+	server := newTargetServer(t)
+	healthy := NewInProcessWorker("a", "eu")
+	broken := &brokenWorker{engine: &brokenEngine{startErr: errors.New("worker unreachable")}}
+	pool := NewWorkerPool(healthy, broken)
+
+	config := poolTestConfig(server.URL, 2)
+	config.Duration = time.Minute
+
+	if err := pool.StartLoadTest("partial", config); err == nil {
+		t.Fatal("Expected an error when a worker fails to start")
+	}
+
+	status := waitForStatus(t, healthy.Engine(), "partial", "cancelled")
+	if status.Status != "cancelled" {
+		t.Errorf("Expected the healthy worker's test to be cancelled, got %s", status.Status)
+	}
+	if pool.hasTest("partial") {
+		t.Error("Expected the pool to forget a test that failed to start")
+	}
+	if _, err := pool.GetTestSummary("partial"); err == nil {
+		t.Error("Expected no summary for a test that failed to start")
+	}
+}
+
+func TestWorkerPoolSummaryToleratesWorkerWithoutResults(t *testing.T) {
+	server := newTargetServer(t)
+	healthy := NewInProcessWorker("a", "eu")
+	silent := &brokenWorker{engine: &brokenEngine{status: &core.LoadTestStatus{TestID: "silent", Status: "completed"}}}
+	pool := NewWorkerPool(healthy, silent)
+
+	if err := pool.StartLoadTest("silent", poolTestConfig(server.URL, 2)); err != nil {
+		t.Fatalf("Expected the test to start, got %v", err)
+	}
+	waitForStatus(t, healthy.Engine(), "silent", "completed")
+
+	summary, err := pool.GetTestSummary("silent")
+	if err != nil {
+		t.Fatalf("Expected a summary, got %v", err)
+	}
+	if len(summary.Workers) != 2 || summary.Workers[1].TotalRequests != 0 {
+		t.Errorf("Expected an empty summary for the silent worker, got %+v", summary.Workers)
+	}
+	if summary.TotalRequests != summary.Workers[0].TotalRequests {
+		t.Errorf("Expected %d total requests, got %d", summary.Workers[0].TotalRequests, summary.TotalRequests)
+	}
+}
+
+func TestWorkerPoolStatusFailsWhenWorkerLosesTest(t *testing.T) {
+	pool := NewWorkerPool(&brokenWorker{engine: &brokenEngine{}})
+
+	if _, err := pool.GetTestStatus("missing"); err == nil {
+		t.Error("Expected an error when a worker does not know the test")
+	}
+}
@@ -0,0 +1,89 @@
+package reporter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/cherry-pick/pkg/loadbalancer/core"
+)
+
+const exportFlushEvery = 500
+
+var ResultsCSVHeader = []string{"request_id", "user_id", "start_time", "duration_ms", "status_code", "response_size", "success", "error"}
+
+// WriteResultsCSV writes one row per result, flushing in batches so large result sets are never buffered whole.
+func WriteResultsCSV(w io.Writer, results []core.LoadTestResult) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(ResultsCSVHeader); err != nil {
+		return err
+	}
+
+	for i, result := range results {
+		record := []string{
+			result.RequestID,
+			strconv.Itoa(result.UserID),
+			result.StartTime.Format(time.RFC3339Nano),
+			strconv.FormatFloat(float64(result.Duration)/float64(time.Millisecond), 'f', 3, 64),
+			strconv.Itoa(result.StatusCode),
+			strconv.FormatInt(result.ResponseSize, 10),
+			strconv.FormatBool(result.Success),
+			result.Error,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+		if (i+1)%exportFlushEvery == 0 {
+			writer.Flush()
+			if err := writer.Error(); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+type exportedResult struct {
+	RequestID    string    `json:"requestId"`
+	UserID       int       `json:"userId"`
+	StartTime    time.Time `json:"startTime"`
+	DurationMs   float64   `json:"durationMs"`
+	StatusCode   int       `json:"statusCode"`
+	ResponseSize int64     `json:"responseSize"`
+	Success      bool      `json:"success"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// WriteResultsJSONL writes one JSON object per line with the same fields as the CSV export.
+func WriteResultsJSONL(w io.Writer, results []core.LoadTestResult) error {
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+
+	for i, result := range results {
+		line := exportedResult{
+			RequestID:    result.RequestID,
+			UserID:       result.UserID,
+			StartTime:    result.StartTime,
+			DurationMs:   float64(result.Duration) / float64(time.Millisecond),
+			StatusCode:   result.StatusCode,
+			ResponseSize: result.ResponseSize,
+			Success:      result.Success,
+			Error:        result.Error,
+		}
+		if err := encoder.Encode(line); err != nil {
+			return err
+		}
+		if (i+1)%exportFlushEvery == 0 {
+			if err := buffered.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return buffered.Flush()
+}
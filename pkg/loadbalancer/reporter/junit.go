@@ -0,0 +1,213 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cherry-pick/pkg/loadbalancer/core"
+)
+
+// AlertOutcome is an alert attached to a test with the triggers it fired.
+type AlertOutcome struct {
+	Alert    *core.Alert
+	Triggers []*core.AlertTrigger
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	Time       string           `xml:"time,attr"`
+	Timestamp  string           `xml:"timestamp,attr,omitempty"`
+	Properties *junitProperties `xml:"properties,omitempty"`
+	Cases      []junitTestCase  `xml:"testcase"`
+}
+
+type junitProperties struct {
+	Property []junitProperty `xml:"property"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",cdata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// MarshalJUnit renders a finished test as JUnit XML for CI test reports. Each configured
+// SLA threshold is a test case in the "sla" suite and each alert on the test is one in the
+// "alerts" suite; violated thresholds and alerts that fired are failures.
+func MarshalJUnit(summary *core.LoadTestSummary, alerts []AlertOutcome) ([]byte, error) {
+	elapsed := junitSeconds(summary.TotalDuration)
+
+	suites := junitTestSuites{
+		Name:   "loadtest " + summary.TestID,
+		Time:   elapsed,
+		Suites: []junitTestSuite{slaSuite(summary), alertSuite(summary.TestID, alerts)},
+	}
+	for i := range suites.Suites {
+		suite := &suites.Suites[i]
+		suite.Time = elapsed
+		if !summary.StartTime.IsZero() {
+			suite.Timestamp = summary.StartTime.UTC().Format(time.RFC3339)
+		}
+		suite.Tests = len(suite.Cases)
+		for _, testCase := range suite.Cases {
+			if testCase.Failure != nil {
+				suite.Failures++
+			}
+			if testCase.Skipped != nil {
+				suite.Skipped++
+			}
+		}
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+	}
+
+	output, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JUnit report: %w", err)
+	}
+	return append([]byte(xml.Header), append(output, '\n')...), nil
+}
+
+func slaSuite(summary *core.LoadTestSummary) junitTestSuite {
+	className := "loadtest." + summary.TestID + ".sla"
+	suite := junitTestSuite{
+		Name: "sla",
+		Properties: &junitProperties{Property: []junitProperty{
+			{Name: "url", Value: summary.Config.URL},
+			{Name: "method", Value: summary.Config.Method},
+			{Name: "concurrentUsers", Value: strconv.Itoa(summary.Config.ConcurrentUsers)},
+			{Name: "totalRequests", Value: strconv.FormatInt(summary.TotalRequests, 10)},
+			{Name: "failedRequests", Value: strconv.FormatInt(summary.FailedRequests, 10)},
+			{Name: "errorRate", Value: strconv.FormatFloat(summary.ErrorRate, 'f', 2, 64)},
+			{Name: "requestsPerSecond", Value: strconv.FormatFloat(summary.RequestsPerSecond, 'f', 2, 64)},
+			{Name: "p95LatencyMs", Value: strconv.FormatFloat(durationMillis(summary.Percentile95), 'f', 3, 64)},
+		}},
+	}
+
+	violations := make(map[string]core.SLAViolation, len(summary.SLAViolations))
+	for _, violation := range summary.SLAViolations {
+		violations[violation.Metric] = violation
+	}
+
+	sla := summary.Config.SLA
+	var metrics []string
+	if sla != nil {
+		if sla.MaxP95Latency > 0 {
+			metrics = append(metrics, "p95_latency_ms")
+		}
+		if sla.MaxErrorRate > 0 {
+			metrics = append(metrics, "error_rate")
+		}
+		if sla.MinThroughput > 0 {
+			metrics = append(metrics, "throughput")
+		}
+	}
+
+	if len(metrics) == 0 {
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      "sla",
+			ClassName: className,
+			Time:      junitSeconds(0),
+			Skipped:   &junitSkipped{Message: "no SLA thresholds configured"},
+		})
+		return suite
+	}
+
+	for _, metric := range metrics {
+		testCase := junitTestCase{Name: metric, ClassName: className, Time: junitSeconds(0)}
+		if violation, violated := violations[metric]; violated {
+			testCase.Failure = &junitFailure{
+				Message: violation.Message,
+				Type:    "SLAViolation",
+				Text: fmt.Sprintf("metric: %s\nthreshold: %s\nactual: %s",
+					violation.Metric, formatJUnitNumber(violation.Threshold), formatJUnitNumber(violation.Actual)),
+			}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	return suite
+}
+
+func alertSuite(testID string, alerts []AlertOutcome) junitTestSuite {
+	className := "loadtest." + testID + ".alerts"
+	suite := junitTestSuite{Name: "alerts", Cases: []junitTestCase{}}
+
+	for _, outcome := range alerts {
+		alert := outcome.Alert
+		testCase := junitTestCase{Name: alert.Name, ClassName: className, Time: junitSeconds(0)}
+		if testCase.Name == "" {
+			testCase.Name = alert.ID
+		}
+
+		triggers := make([]*core.AlertTrigger, len(outcome.Triggers))
+		copy(triggers, outcome.Triggers)
+		sort.Slice(triggers, func(i, j int) bool {
+			return triggers[i].TriggeredAt.Before(triggers[j].TriggeredAt)
+		})
+
+		if len(triggers) > 0 || alert.TriggerCount > 0 {
+			message := fmt.Sprintf("alert %s (%s) fired %d time(s)", testCase.Name, alert.Condition, alert.TriggerCount)
+			var details []string
+			for _, trigger := range triggers {
+				details = append(details, fmt.Sprintf("%s %s", trigger.TriggeredAt.UTC().Format(time.RFC3339), trigger.Message))
+			}
+			if len(triggers) > 0 {
+				message = triggers[len(triggers)-1].Message
+			}
+			testCase.Failure = &junitFailure{
+				Message: message,
+				Type:    "AlertTriggered",
+				Text:    strings.Join(details, "\n"),
+			}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	return suite
+}
+
+func junitSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func formatJUnitNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
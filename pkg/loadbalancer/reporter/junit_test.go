@@ -0,0 +1,112 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/cherry-pick/pkg/loadbalancer/core"
+)
+
+func junitSummary() *core.LoadTestSummary {
+	return &core.LoadTestSummary{
+		TestID:        "checkout",
+		TotalDuration: 30 * time.Second,
+		Config: core.LoadTestConfig{
+			URL: "http://example.com",
+			SLA: &core.SLAConfig{MaxP95Latency: 100 * time.Millisecond, MaxErrorRate: 5},
+		},
+		Percentile95: 250 * time.Millisecond,
+		ErrorRate:    1,
+		SLAViolations: []core.SLAViolation{{
+			Metric:    "p95_latency_ms",
+			Threshold: 100,
+			Actual:    250,
+			Message:   "p95 latency 250ms exceeds 100ms",
+		}},
+	}
+}
+
+func unmarshalJUnit(t *testing.T, output []byte) junitTestSuites {
+	t.Helper()
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(output, &suites); err != nil {
+		t.Fatalf("Expected valid XML, got %v:\n%s", err, output)
+	}
+	return suites
+}
+
+func TestJUnitReportsViolatedSLAAsFailure(t *testing.T) {
+	output, err := MarshalJUnit(junitSummary(), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	suites := unmarshalJUnit(t, output)
+	if suites.Tests != 2 || suites.Failures != 1 {
+		t.Fatalf("Expected 2 tests and 1 failure, got %d and %d", suites.Tests, suites.Failures)
+	}
+
+	sla := suites.Suites[0]
+	if sla.Name != "sla" || len(sla.Cases) != 2 {
+		t.Fatalf("Expected an sla suite with 2 cases, got %q with %d", sla.Name, len(sla.Cases))
+	}
+
+	latency, errorRate := sla.Cases[0], sla.Cases[1]
+	if latency.Name != "p95_latency_ms" || latency.Failure == nil {
+		t.Fatalf("Expected a failure on p95_latency_ms, got %+v", latency)
+	}
+	if latency.Failure.Type != "SLAViolation" || latency.Failure.Message != "p95 latency 250ms exceeds 100ms" {
+		t.Errorf("Expected the violation as the failure, got %+v", latency.Failure)
+	}
+	if latency.Failure.Text != "metric: p95_latency_ms\nthreshold: 100\nactual: 250" {
+		t.Errorf("Expected the threshold and actual value in the failure text, got %q", latency.Failure.Text)
+	}
+	if errorRate.Name != "error_rate" || errorRate.Failure != nil {
+		t.Errorf("Expected error_rate to pass, got %+v", errorRate)
+	}
+}
+
+func TestJUnitSkipsSLAWhenNoneConfigured(t *testing.T) {
+	summary := junitSummary()
+	summary.Config.SLA = nil
+	summary.SLAViolations = nil
+
+	output, err := MarshalJUnit(summary, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	suites := unmarshalJUnit(t, output)
+	if suites.Failures != 0 || suites.Skipped != 1 {
+		t.Errorf("Expected no failures and 1 skipped case, got %d and %d", suites.Failures, suites.Skipped)
+	}
+}
+
+func TestJUnitReportsFiredAlertAsFailure(t *testing.T) {
+	firedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	alerts := []AlertOutcome{
+		{Alert: &core.Alert{ID: "quiet", Name: "quiet"}},
+		{
+			Alert:    &core.Alert{ID: "slow", Name: "slow", Condition: "avg_response_time > 100", TriggerCount: 1},
+			Triggers: []*core.AlertTrigger{{TriggeredAt: firedAt, Message: "average response time 180ms"}},
+		},
+	}
+
+	output, err := MarshalJUnit(junitSummary(), alerts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	suite := unmarshalJUnit(t, output).Suites[1]
+	if suite.Name != "alerts" || suite.Tests != 2 || suite.Failures != 1 {
+		t.Fatalf("Expected an alerts suite with 2 tests and 1 failure, got %+v", suite)
+	}
+	if suite.Cases[0].Failure != nil {
+		t.Errorf("Expected the quiet alert to pass, got %+v", suite.Cases[0].Failure)
+	}
+	if failure := suite.Cases[1].Failure; failure == nil || failure.Message != "average response time 180ms" {
+		t.Errorf("Expected the latest trigger as the failure message, got %+v", failure)
+	}
+}
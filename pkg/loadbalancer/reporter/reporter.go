@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/cherry-pick/pkg/loadbalancer/core"
@@ -197,7 +198,7 @@ func (r *Reporter) generateHTMLContent(report LoadTestReport) string {
 		summary.ErrorRate,
 		summary.AverageResponseTime.String(),
 		summary.RequestsPerSecond,
-		r.generateResponseTimeDistributionHTML(summary.ResponseTimeDistribution),
+		r.generateResponseTimeDistributionHTML(summary.ResponseTimeDistribution, summary.ResponseTimeBuckets),
 		r.generateStatusCodeDistributionHTML(summary.StatusCodes, summary.TotalRequests),
 		summary.MinResponseTime.String(),
 		summary.AverageResponseTime.String(),
@@ -206,15 +207,26 @@ func (r *Reporter) generateHTMLContent(report LoadTestReport) string {
 }
 
 // generateResponseTimeDistributionHTML generates HTML for response time distribution
-func (r *Reporter) generateResponseTimeDistributionHTML(distribution map[string]int64) string {
+func (r *Reporter) generateResponseTimeDistributionHTML(distribution map[string]int64, buckets []string) string {
 	var html string
 	total := int64(0)
 	for _, count := range distribution {
 		total += count
 	}
 
-	for timeRange, count := range distribution {
-		percentage := float64(count) / float64(total) * 100
+	if len(buckets) == 0 {
+		for timeRange := range distribution {
+			buckets = append(buckets, timeRange)
+		}
+		sort.Strings(buckets)
+	}
+
+	for _, timeRange := range buckets {
+		count := distribution[timeRange]
+		percentage := 0.0
+		if total > 0 {
+			percentage = float64(count) / float64(total) * 100
+		}
 		html += fmt.Sprintf(`
 			<div style="margin: 10px 0;">
 				<div style="display: flex; justify-content: space-between; margin-bottom: 5px;">
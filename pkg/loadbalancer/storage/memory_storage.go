@@ -311,30 +311,12 @@ func (ms *MemoryStorage) GetAlertStats() (*core.AlertStats, error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 
-	stats := &core.AlertStats{}
-
+	alerts := make([]*core.Alert, 0, len(ms.alerts))
 	for _, alert := range ms.alerts {
-		stats.TotalAlerts++
-		if alert.IsActive {
-			stats.ActiveAlerts++
-		}
-		if alert.TriggerCount > 0 {
-			stats.TriggeredAlerts++
-		}
-
-		switch alert.Severity {
-		case core.SeverityCritical:
-			stats.CriticalAlerts++
-		case core.SeverityHigh:
-			stats.HighAlerts++
-		case core.SeverityMedium:
-			stats.MediumAlerts++
-		case core.SeverityLow:
-			stats.LowAlerts++
-		}
+		alerts = append(alerts, alert)
 	}
 
-	return stats, nil
+	return core.NewAlertStats(alerts), nil
 }
 
 func (ms *MemoryStorage) CleanupOldTests(olderThan time.Time) error {
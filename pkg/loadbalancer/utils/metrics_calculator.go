@@ -164,28 +164,23 @@ func (mc *MetricsCalculator) calculateBandwidth(results []core.LoadTestResult) f
 }
 
 func (mc *MetricsCalculator) CalculateResponseTimeDistribution(results []core.LoadTestResult) map[string]int64 {
-	distribution := map[string]int64{
-		"<100ms":    0,
-		"100-500ms": 0,
-		"500ms-1s":  0,
-		"1-2s":      0,
-		">2s":       0,
+	return mc.CalculateResponseTimeDistributionWithBuckets(results, core.DefaultLatencyBuckets())
+}
+
+// CalculateResponseTimeDistributionWithBuckets bins durations by the ascending bounds,
+// keyed by core.LatencyBucketLabels.
+func (mc *MetricsCalculator) CalculateResponseTimeDistributionWithBuckets(results []core.LoadTestResult, bounds []time.Duration) map[string]int64 {
+	labels := core.LatencyBucketLabels(bounds)
+	distribution := make(map[string]int64, len(labels))
+	for _, label := range labels {
+		distribution[label] = 0
+	}
+	if len(labels) == 0 {
+		return distribution
 	}
 
 	for _, result := range results {
-		duration := result.Duration
-		switch {
-		case duration < 100*time.Millisecond:
-			distribution["<100ms"]++
-		case duration < 500*time.Millisecond:
-			distribution["100-500ms"]++
-		case duration < 1000*time.Millisecond:
-			distribution["500ms-1s"]++
-		case duration < 2000*time.Millisecond:
-			distribution["1-2s"]++
-		default:
-			distribution[">2s"]++
-		}
+		distribution[labels[core.LatencyBucketIndex(bounds, result.Duration)]]++
 	}
 
 	return distribution
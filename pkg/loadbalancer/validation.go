@@ -34,6 +34,52 @@ func (v *ConfigValidator) ValidateConfig(config core.LoadTestConfig) error {
 	if err := v.validateHeaders(config.Headers); err != nil {
 		return err
 	}
+	if err := v.validateCapturedBodies(config.MaxCapturedBodies); err != nil {
+		return err
+	}
+	if err := v.validateThinkTime(config.ThinkTime); err != nil {
+		return err
+	}
+	if err := v.validateWorkers(config.Workers, config.ConcurrentUsers); err != nil {
+		return err
+	}
+	if err := v.validateSLA(config.SLA); err != nil {
+		return err
+	}
+	if err := v.validateLatencyBuckets(config.LatencyBuckets); err != nil {
+		return err
+	}
+	if _, err := core.ParseStatusCodes(config.SuccessStatusCodes); err != nil {
+		return NewValidationError("SuccessStatusCodes", config.SuccessStatusCodes, "valid_status_codes", err.Error())
+	}
+	if config.MaxResponseBytes < 0 {
+		return NewValidationError("MaxResponseBytes", config.MaxResponseBytes, "positive", "max response bytes cannot be negative")
+	}
+	if err := v.validateResultStream(config.ResultStream); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v *ConfigValidator) validateResultStream(stream *core.ResultStreamConfig) error {
+	if stream == nil {
+		return nil
+	}
+	if stream.Sink == nil {
+		if stream.Path != "" {
+			return NewValidationError("ResultStream.Path", stream.Path, "file_name", "result stream path must be a file name without directories")
+		}
+		return NewValidationError("ResultStream.Sink", nil, "required", "result stream requires a sink")
+	}
+	if stream.BatchSize < 0 {
+		return NewValidationError("ResultStream.BatchSize", stream.BatchSize, "positive", "batch size cannot be negative")
+	}
+	if stream.FlushInterval < 0 {
+		return NewValidationError("ResultStream.FlushInterval", stream.FlushInterval, "positive", "flush interval cannot be negative")
+	}
+	if stream.Window < 0 {
+		return NewValidationError("ResultStream.Window", stream.Window, "positive", "window cannot be negative")
+	}
 	return nil
 }
 
@@ -80,6 +126,88 @@ func (v *ConfigValidator) validateRequestDelay(delay time.Duration) error {
 	return nil
 }
 
+func (v *ConfigValidator) validateCapturedBodies(max int) error {
+	if max < 0 {
+		return NewValidationError("MaxCapturedBodies", max, "positive", "max captured bodies cannot be negative")
+	}
+	if max > MaxCapturedBodiesLimit {
+		return NewValidationError("MaxCapturedBodies", max, "max",
+			fmt.Sprintf("max captured bodies cannot exceed %d", MaxCapturedBodiesLimit))
+	}
+	return nil
+}
+
+func (v *ConfigValidator) validateThinkTime(thinkTime core.ThinkTimeConfig) error {
+	switch thinkTime.Distribution {
+	case "", core.ThinkTimeConstant:
+		return nil
+	case core.ThinkTimeUniform:
+		if thinkTime.Min < 0 || thinkTime.Max < 0 {
+			return NewValidationError("ThinkTime", thinkTime, "positive", "think time bounds cannot be negative")
+		}
+		if thinkTime.Max < thinkTime.Min {
+			return NewValidationError("ThinkTime", thinkTime, "range", "think time max must be greater than or equal to min")
+		}
+		return nil
+	case core.ThinkTimeExponential:
+		if thinkTime.Mean <= 0 {
+			return NewValidationError("ThinkTime", thinkTime, "positive", "exponential think time requires a positive mean")
+		}
+		return nil
+	}
+	return NewValidationError("ThinkTime", thinkTime.Distribution, "valid_distribution",
+		"think time distribution must be one of: constant, uniform, exponential")
+}
+
+func (v *ConfigValidator) validateWorkers(workers []core.WorkerSpec, users int) error {
+	if len(workers) == 0 {
+		return nil
+	}
+	if len(workers) > users {
+		return NewValidationError("Workers", len(workers), "max",
+			"number of workers cannot exceed concurrent users")
+	}
+	seen := make(map[string]bool)
+	for _, worker := range workers {
+		if worker.Name == "" {
+			return NewValidationError("Workers", worker, "non_empty", "worker name cannot be empty")
+		}
+		if seen[worker.Name] {
+			return NewValidationError("Workers", worker.Name, "unique", "worker names must be unique")
+		}
+		seen[worker.Name] = true
+	}
+	return nil
+}
+
+func (v *ConfigValidator) validateSLA(sla *core.SLAConfig) error {
+	if sla == nil {
+		return nil
+	}
+	if sla.MaxP95Latency < 0 {
+		return NewValidationError("SLA", sla.MaxP95Latency, "positive", "SLA p95 latency cannot be negative")
+	}
+	if sla.MaxErrorRate < 0 || sla.MaxErrorRate > 100 {
+		return NewValidationError("SLA", sla.MaxErrorRate, "range", "SLA error rate must be between 0 and 100")
+	}
+	if sla.MinThroughput < 0 {
+		return NewValidationError("SLA", sla.MinThroughput, "positive", "SLA throughput cannot be negative")
+	}
+	return nil
+}
+
+func (v *ConfigValidator) validateLatencyBuckets(bounds []time.Duration) error {
+	for i, bound := range bounds {
+		if bound <= 0 {
+			return NewValidationError("LatencyBuckets", bound, "positive", "latency bucket bounds must be positive")
+		}
+		if i > 0 && bound <= bounds[i-1] {
+			return NewValidationError("LatencyBuckets", bound, "ascending", "latency bucket bounds must be strictly ascending")
+		}
+	}
+	return nil
+}
+
 func (v *ConfigValidator) validateMethod(method string) error {
 	if method == "" {
 		return nil
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+type Logger interface {
+	Debug(msg string, fields ...any)
+	Info(msg string, fields ...any)
+	Warn(msg string, fields ...any)
+	Error(msg string, fields ...any)
+	With(fields ...any) Logger
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func New(handler slog.Handler) Logger {
+	return &slogLogger{logger: slog.New(handler)}
+}
+
+func NewTextLogger(w io.Writer, level slog.Level) Logger {
+	return New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+func (l *slogLogger) Debug(msg string, fields ...any) {
+	l.logger.Debug(msg, fields...)
+}
+
+func (l *slogLogger) Info(msg string, fields ...any) {
+	l.logger.Info(msg, fields...)
+}
+
+func (l *slogLogger) Warn(msg string, fields ...any) {
+	l.logger.Warn(msg, fields...)
+}
+
+func (l *slogLogger) Error(msg string, fields ...any) {
+	l.logger.Error(msg, fields...)
+}
+
+func (l *slogLogger) With(fields ...any) Logger {
+	return &slogLogger{logger: l.logger.With(fields...)}
+}
+
+func ParseLevel(value string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+var (
+	defaultLogger Logger
+	defaultOnce   sync.Once
+)
+
+func Default() Logger {
+	defaultOnce.Do(func() {
+		defaultLogger = NewTextLogger(os.Stderr, ParseLevel(os.Getenv("LOG_LEVEL")))
+	})
+	return defaultLogger
+}
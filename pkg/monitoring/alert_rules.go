@@ -0,0 +1,321 @@
+package monitoring
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+const (
+	ruleLevelDatabase = "database"
+	ruleLevelTable    = "table"
+	ruleLevelColumn   = "column"
+)
+
+// alertRuleMetrics maps each metric a rule can use to the level it is read at.
+var alertRuleMetrics = map[string]string{
+	"health_score":       ruleLevelDatabase,
+	"complexity_score":   ruleLevelDatabase,
+	"total_tables":       ruleLevelDatabase,
+	"total_columns":      ruleLevelDatabase,
+	"total_rows":         ruleLevelDatabase,
+	"insight_count":      ruleLevelDatabase,
+	"row_count":          ruleLevelTable,
+	"column_count":       ruleLevelTable,
+	"index_count":        ruleLevelTable,
+	"constraint_count":   ruleLevelTable,
+	"relationship_count": ruleLevelTable,
+	"quality_score":      ruleLevelColumn,
+	"null_count":         ruleLevelColumn,
+	"null_ratio":         ruleLevelColumn,
+	"unique_values":      ruleLevelColumn,
+}
+
+var alertRuleSeverities = map[string]bool{
+	"low":      true,
+	"medium":   true,
+	"high":     true,
+	"critical": true,
+}
+
+// SupportedAlertRuleMetrics lists the metrics an AlertRule condition can name.
+func SupportedAlertRuleMetrics() []string {
+	return []string{
+		"health_score", "complexity_score", "total_tables", "total_columns", "total_rows", "insight_count",
+		"row_count", "column_count", "index_count", "constraint_count", "relationship_count",
+		"quality_score", "null_count", "null_ratio", "unique_values",
+	}
+}
+
+// ParseAlertCondition splits a condition of the form "<metric> <operator> <number>".
+// It reads conditions the same way as the load balancer's alert evaluator.
+func ParseAlertCondition(condition string) (metric, operator string, threshold float64, err error) {
+	condition = strings.TrimSpace(condition)
+	operatorIndex := -1
+	for _, op := range []string{">=", "<=", "!=", "==", ">", "<", "="} {
+		if index := strings.Index(condition, op); index != -1 {
+			operator = op
+			operatorIndex = index
+			break
+		}
+	}
+	if operatorIndex == -1 {
+		return "", "", 0, fmt.Errorf("no valid operator found in condition: %s", condition)
+	}
+
+	metric = strings.TrimSpace(condition[:operatorIndex])
+	thresholdStr := strings.TrimSpace(condition[operatorIndex+len(operator):])
+	threshold, err = strconv.ParseFloat(thresholdStr, 64)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid threshold value: %s", thresholdStr)
+	}
+	if operator == "=" {
+		operator = "=="
+	}
+	return metric, operator, threshold, nil
+}
+
+type compiledAlertRule struct {
+	rule      types.AlertRule
+	metric    string
+	level     string
+	operator  string
+	threshold float64
+}
+
+// AlertRuleSet holds validated alert rules, ready to evaluate against reports.
+type AlertRuleSet struct {
+	rules []compiledAlertRule
+}
+
+// NewAlertRuleSet validates rules. It rejects missing or duplicate IDs, conditions that
+// do not parse or name an unknown metric, unknown severities, malformed scopes, and
+// scopes on database-wide metrics. Severity defaults to "medium".
+func NewAlertRuleSet(rules []types.AlertRule) (*AlertRuleSet, error) {
+	set := &AlertRuleSet{}
+	seen := make(map[string]bool, len(rules))
+
+	for _, rule := range rules {
+		if rule.ID == "" {
+			return nil, fmt.Errorf("alert rule %q needs an id", rule.Name)
+		}
+		if seen[rule.ID] {
+			return nil, fmt.Errorf("duplicate alert rule id %s", rule.ID)
+		}
+		seen[rule.ID] = true
+
+		metric, operator, threshold, err := ParseAlertCondition(rule.Condition)
+		if err != nil {
+			return nil, fmt.Errorf("alert rule %s: %w", rule.ID, err)
+		}
+		level, ok := alertRuleMetrics[metric]
+		if !ok {
+			return nil, fmt.Errorf("alert rule %s: unsupported metric: %s", rule.ID, metric)
+		}
+
+		if rule.Severity == "" {
+			rule.Severity = "medium"
+		}
+		if !alertRuleSeverities[rule.Severity] {
+			return nil, fmt.Errorf("alert rule %s: unsupported severity: %s", rule.ID, rule.Severity)
+		}
+
+		if rule.Scope != "" {
+			if level == ruleLevelDatabase {
+				return nil, fmt.Errorf("alert rule %s: %s applies to the whole database and takes no scope", rule.ID, metric)
+			}
+			if _, err := path.Match(rule.Scope, ""); err != nil {
+				return nil, fmt.Errorf("alert rule %s: invalid scope %q: %w", rule.ID, rule.Scope, err)
+			}
+		}
+		if rule.Name == "" {
+			rule.Name = rule.ID
+		}
+
+		set.rules = append(set.rules, compiledAlertRule{
+			rule:      rule,
+			metric:    metric,
+			level:     level,
+			operator:  operator,
+			threshold: threshold,
+		})
+	}
+
+	return set, nil
+}
+
+// Len returns how many rules the set holds.
+func (rs *AlertRuleSet) Len() int {
+	if rs == nil {
+		return 0
+	}
+	return len(rs.rules)
+}
+
+// Evaluate returns an alert for every rule the report breaches. An alert names the
+// first table or column, in report order, that breached and counts any others.
+func (rs *AlertRuleSet) Evaluate(report *types.DatabaseReport) []types.MonitoringAlert {
+	if rs == nil || report == nil {
+		return nil
+	}
+
+	var alerts []types.MonitoringAlert
+	for _, compiled := range rs.rules {
+		if alert, triggered := compiled.evaluate(report); triggered {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts
+}
+
+type ruleSample struct {
+	subject string
+	value   float64
+}
+
+func (cr compiledAlertRule) evaluate(report *types.DatabaseReport) (types.MonitoringAlert, bool) {
+	var breaches []ruleSample
+	for _, sample := range cr.samples(report) {
+		if cr.breached(sample.value) {
+			breaches = append(breaches, sample)
+		}
+	}
+	if len(breaches) == 0 {
+		return types.MonitoringAlert{}, false
+	}
+
+	first := breaches[0]
+	message := fmt.Sprintf("%s is %s (%s %s)", cr.metric, formatRuleValue(first.value),
+		cr.operator, formatRuleValue(cr.threshold))
+	if first.subject != "" {
+		message = fmt.Sprintf("%s %s: %s", cr.level, first.subject, message)
+	}
+	if others := len(breaches) - 1; others > 0 {
+		message += fmt.Sprintf(", and %d more %s(s)", others, cr.level)
+	}
+
+	return types.MonitoringAlert{
+		ID:          cr.rule.ID,
+		Name:        cr.rule.Name,
+		Condition:   cr.rule.Condition,
+		Threshold:   cr.threshold,
+		Severity:    cr.rule.Severity,
+		Triggered:   true,
+		LastTrigger: time.Now(),
+		Message:     message,
+		Metric:      cr.metric,
+		Value:       first.value,
+		Subject:     first.subject,
+	}, true
+}
+
+func (cr compiledAlertRule) samples(report *types.DatabaseReport) []ruleSample {
+	switch cr.level {
+	case ruleLevelDatabase:
+		return []ruleSample{{value: databaseMetric(cr.metric, report)}}
+	case ruleLevelTable:
+		var samples []ruleSample
+		for _, table := range report.Tables {
+			if cr.inScope(table.Name) {
+				samples = append(samples, ruleSample{subject: table.Name, value: tableMetric(cr.metric, table)})
+			}
+		}
+		return samples
+	default:
+		var samples []ruleSample
+		for _, table := range report.Tables {
+			for _, column := range table.Columns {
+				subject := table.Name + "." + column.Name
+				if cr.inScope(subject) {
+					samples = append(samples, ruleSample{subject: subject, value: columnMetric(cr.metric, table, column)})
+				}
+			}
+		}
+		return samples
+	}
+}
+
+func (cr compiledAlertRule) inScope(subject string) bool {
+	if cr.rule.Scope == "" {
+		return true
+	}
+	matched, _ := path.Match(cr.rule.Scope, subject)
+	return matched
+}
+
+func (cr compiledAlertRule) breached(value float64) bool {
+	switch cr.operator {
+	case ">":
+		return value > cr.threshold
+	case "<":
+		return value < cr.threshold
+	case ">=":
+		return value >= cr.threshold
+	case "<=":
+		return value <= cr.threshold
+	case "==":
+		return value == cr.threshold
+	case "!=":
+		return value != cr.threshold
+	}
+	return false
+}
+
+func databaseMetric(metric string, report *types.DatabaseReport) float64 {
+	switch metric {
+	case "health_score":
+		return report.Summary.HealthScore
+	case "complexity_score":
+		return report.Summary.ComplexityScore
+	case "total_tables":
+		return float64(report.Summary.TotalTables)
+	case "total_columns":
+		return float64(report.Summary.TotalColumns)
+	case "total_rows":
+		return float64(report.Summary.TotalRows)
+	case "insight_count":
+		return float64(len(report.Insights))
+	}
+	return 0
+}
+
+func tableMetric(metric string, table types.TableInfo) float64 {
+	switch metric {
+	case "row_count":
+		return float64(table.RowCount)
+	case "column_count":
+		return float64(len(table.Columns))
+	case "index_count":
+		return float64(len(table.Indexes))
+	case "constraint_count":
+		return float64(len(table.Constraints))
+	case "relationship_count":
+		return float64(len(table.Relationships))
+	}
+	return 0
+}
+
+func columnMetric(metric string, table types.TableInfo, column types.ColumnInfo) float64 {
+	switch metric {
+	case "quality_score":
+		return column.DataProfile.Quality
+	case "null_count":
+		return float64(column.NullCount)
+	case "null_ratio":
+		if table.RowCount == 0 {
+			return 0
+		}
+		return float64(column.NullCount) / float64(table.RowCount)
+	case "unique_values":
+		return float64(column.UniqueValues)
+	}
+	return 0
+}
+
+func formatRuleValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,171 @@
+package monitoring
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+func TestParseAlertCondition(t *testing.T) {
+	tests := []struct {
+		condition string
+		metric    string
+		operator  string
+		threshold float64
+	}{
+		{"health_score < 70", "health_score", "<", 70},
+		{"row_count>=1000000", "row_count", ">=", 1000000},
+		{" null_ratio <= 0.25 ", "null_ratio", "<=", 0.25},
+		{"index_count = 0", "index_count", "==", 0},
+		{"unique_values != 1", "unique_values", "!=", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			metric, operator, threshold, err := ParseAlertCondition(tt.condition)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if metric != tt.metric || operator != tt.operator || threshold != tt.threshold {
+				t.Errorf("Expected %s %s %v, got %s %s %v", tt.metric, tt.operator, tt.threshold, metric, operator, threshold)
+			}
+		})
+	}
+}
+
+func TestParseAlertConditionErrors(t *testing.T) {
+	for _, condition := range []string{"health_score", "health_score > high", ""} {
+		if _, _, _, err := ParseAlertCondition(condition); err == nil {
+			t.Errorf("Expected an error for %q", condition)
+		}
+	}
+}
+
+func TestNewAlertRuleSetValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []types.AlertRule
+		want  string
+	}{
+		{"missing id", []types.AlertRule{{Name: "Low health", Condition: "health_score < 70"}}, "needs an id"},
+		{"duplicate id", []types.AlertRule{
+			{ID: "r1", Condition: "health_score < 70"},
+			{ID: "r1", Condition: "row_count > 10"},
+		}, "duplicate"},
+		{"bad condition", []types.AlertRule{{ID: "r1", Condition: "health_score"}}, "no valid operator"},
+		{"unknown metric", []types.AlertRule{{ID: "r1", Condition: "latency > 10"}}, "unsupported metric"},
+		{"unknown severity", []types.AlertRule{{ID: "r1", Condition: "row_count > 10", Severity: "urgent"}}, "unsupported severity"},
+		{"scoped database metric", []types.AlertRule{{ID: "r1", Condition: "health_score < 70", Scope: "users"}}, "takes no scope"},
+		{"bad scope", []types.AlertRule{{ID: "r1", Condition: "row_count > 10", Scope: "users["}}, "invalid scope"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAlertRuleSet(tt.rules)
+			if err == nil {
+				t.Fatalf("Expected an error containing %q", tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestSupportedAlertRuleMetricsAreKnown(t *testing.T) {
+	metrics := SupportedAlertRuleMetrics()
+	if len(metrics) != len(alertRuleMetrics) {
+		t.Errorf("Expected %d metrics, got %d", len(alertRuleMetrics), len(metrics))
+	}
+	for _, metric := range metrics {
+		if _, ok := alertRuleMetrics[metric]; !ok {
+			t.Errorf("Expected %s to have a level", metric)
+		}
+	}
+}
+
+func alertRuleTestReport() *types.DatabaseReport {
+	return &types.DatabaseReport{
+		Summary:  types.DatabaseSummary{HealthScore: 62, TotalTables: 2, TotalRows: 1500},
+		Insights: []types.DatabaseInsight{{}, {}},
+		Tables: []types.TableInfo{
+			{
+				Name:     "users",
+				RowCount: 1000,
+				Columns: []types.ColumnInfo{
+					{Name: "email", NullCount: 400, DataProfile: types.DataProfile{Quality: 0.6}},
+					{Name: "id", UniqueValues: 1000, DataProfile: types.DataProfile{Quality: 1}},
+				},
+			},
+			{
+				Name:     "orders",
+				RowCount: 500,
+				Columns: []types.ColumnInfo{
+					{Name: "note", NullCount: 450, DataProfile: types.DataProfile{Quality: 0.1}},
+				},
+				Indexes: []types.IndexInfo{{Name: "orders_pkey"}},
+			},
+		},
+	}
+}
+
+func TestAlertRuleSetEvaluate(t *testing.T) {
+	set, err := NewAlertRuleSet([]types.AlertRule{
+		{ID: "health", Condition: "health_score < 70", Severity: "high"},
+		{ID: "insights", Condition: "insight_count >= 5"},
+		{ID: "unindexed", Condition: "index_count == 0"},
+		{ID: "nulls", Condition: "null_ratio > 0.3"},
+		{ID: "orders-quality", Condition: "quality_score < 0.5", Scope: "orders.*"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if set.Len() != 5 {
+		t.Fatalf("Expected 5 rules, got %d", set.Len())
+	}
+
+	alerts := set.Evaluate(alertRuleTestReport())
+	byID := make(map[string]types.MonitoringAlert, len(alerts))
+	for _, alert := range alerts {
+		byID[alert.ID] = alert
+	}
+
+	if _, ok := byID["insights"]; ok {
+		t.Errorf("Expected insight_count >= 5 not to trigger with 2 insights")
+	}
+
+	health, ok := byID["health"]
+	if !ok {
+		t.Fatalf("Expected the health rule to trigger")
+	}
+	if health.Severity != "high" || health.Value != 62 || health.Name != "health" {
+		t.Errorf("Expected a high alert named health with value 62, got %+v", health)
+	}
+	if want := "health_score is 62 (< 70)"; health.Message != want {
+		t.Errorf("Expected %q, got %q", want, health.Message)
+	}
+
+	unindexed := byID["unindexed"]
+	if unindexed.Subject != "users" || unindexed.Severity != "medium" {
+		t.Errorf("Expected users with the default severity, got %q %q", unindexed.Subject, unindexed.Severity)
+	}
+
+	nulls := byID["nulls"]
+	if want := "column users.email: null_ratio is 0.4 (> 0.3), and 1 more column(s)"; nulls.Message != want {
+		t.Errorf("Expected %q, got %q", want, nulls.Message)
+	}
+
+	quality := byID["orders-quality"]
+	if quality.Subject != "orders.note" {
+		t.Errorf("Expected the scope to limit the rule to orders, got %q", quality.Subject)
+	}
+}
+
+func TestAlertRuleSetEvaluateNil(t *testing.T) {
+	var set *AlertRuleSet
+	if set.Len() != 0 {
+		t.Errorf("Expected 0 rules, got %d", set.Len())
+	}
+	if alerts := set.Evaluate(alertRuleTestReport()); alerts != nil {
+		t.Errorf("Expected no alerts, got %v", alerts)
+	}
+}
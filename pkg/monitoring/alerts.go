@@ -5,11 +5,14 @@ import (
 	"time"
 
 	"github.com/cherry-pick/pkg/interfaces"
+	"github.com/cherry-pick/pkg/notification"
 	"github.com/cherry-pick/pkg/types"
 )
 
 type AlertManagerImpl struct {
-	alerts []types.MonitoringAlert
+	alerts     []types.MonitoringAlert
+	rules      *AlertRuleSet
+	dispatcher *notification.Dispatcher
 }
 
 func NewAlertManager() interfaces.AlertManager {
@@ -37,7 +40,24 @@ func NewAlertManager() interfaces.AlertManager {
 		},
 	}
 
-	return &AlertManagerImpl{alerts: defaultAlerts}
+	return &AlertManagerImpl{alerts: defaultAlerts, dispatcher: notification.Default()}
+}
+
+// NewAlertManagerWithRules returns the default alert manager that also evaluates the
+// custom rules, typically AlertSettings.Rules from the config file.
+func NewAlertManagerWithRules(rules []types.AlertRule) (interfaces.AlertManager, error) {
+	ruleSet, err := NewAlertRuleSet(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	am := NewAlertManager().(*AlertManagerImpl)
+	am.rules = ruleSet
+	return am, nil
+}
+
+func (am *AlertManagerImpl) SetDispatcher(dispatcher *notification.Dispatcher) {
+	am.dispatcher = dispatcher
 }
 
 func (am *AlertManagerImpl) CheckAlerts(report *types.DatabaseReport) []types.MonitoringAlert {
@@ -57,6 +77,23 @@ func (am *AlertManagerImpl) CheckAlerts(report *types.DatabaseReport) []types.Mo
 			}
 		}
 	}
+	triggeredAlerts = append(triggeredAlerts, am.rules.Evaluate(report)...)
+
+	for _, alert := range triggeredAlerts {
+		am.dispatcher.Dispatch(notification.Notification{
+			ID:        alert.ID,
+			Source:    "database",
+			Title:     alert.Name,
+			Message:   alert.Message,
+			Severity:  alert.Severity,
+			Timestamp: alert.LastTrigger,
+			Metadata: map[string]interface{}{
+				"database":  report.DatabaseName,
+				"condition": alert.Condition,
+				"threshold": alert.Threshold,
+			},
+		})
+	}
 
 	return triggeredAlerts
 }
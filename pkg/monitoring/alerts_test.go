@@ -0,0 +1,116 @@
+package monitoring
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cherry-pick/pkg/notification"
+	"github.com/cherry-pick/pkg/types"
+)
+
+// recordingChannel hands every notification it is sent to sent.
+type recordingChannel struct {
+	sent chan notification.Notification
+}
+
+func (rc *recordingChannel) Name() string { return "recording" }
+
+func (rc *recordingChannel) Send(ctx context.Context, n notification.Notification) error {
+	rc.sent <- n
+	return nil
+}
+
+func TestNewAlertManagerWithRulesRejectsInvalidRules(t *testing.T) {
+	if _, err := NewAlertManagerWithRules([]types.AlertRule{{ID: "r1", Condition: "latency > 10"}}); err == nil {
+		t.Errorf("Expected an error for an unknown metric")
+	}
+}
+
+func TestCheckAlertsDispatchesRuleAlerts(t *testing.T) {
+	manager, err := NewAlertManagerWithRules([]types.AlertRule{
+		{ID: "health", Condition: "health_score < 70", Severity: "critical"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	channel := &recordingChannel{sent: make(chan notification.Notification, 8)}
+	dispatcher := notification.NewDispatcher()
+	dispatcher.Register(channel, "critical")
+	am := manager.(*AlertManagerImpl)
+	am.SetDispatcher(dispatcher)
+
+	report := alertRuleTestReport()
+	report.DatabaseName = "app"
+	alerts := am.CheckAlerts(report)
+
+	ids := make(map[string]bool, len(alerts))
+	for _, alert := range alerts {
+		ids[alert.ID] = true
+	}
+	for _, id := range []string{"data_quality_degradation", "health"} {
+		if !ids[id] {
+			t.Errorf("Expected %s to trigger, got %v", id, ids)
+		}
+	}
+	if ids["missing_indexes"] {
+		t.Errorf("Expected missing_indexes not to trigger for tables under 10000 rows")
+	}
+
+	select {
+	case sent := <-channel.sent:
+		if sent.ID != "health" || sent.Metadata["database"] != "app" {
+			t.Errorf("Expected the health alert for app, got %+v", sent)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the critical alert to be dispatched")
+	}
+	select {
+	case sent := <-channel.sent:
+		t.Errorf("Expected only critical alerts on the channel, got %s", sent.ID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestCheckAlertsMissingIndexes(t *testing.T) {
+	am := NewAlertManager().(*AlertManagerImpl)
+	am.SetDispatcher(nil)
+
+	report := &types.DatabaseReport{Tables: []types.TableInfo{{Name: "events", RowCount: 50000}}}
+	alerts := am.CheckAlerts(report)
+	if len(alerts) != 1 || alerts[0].ID != "missing_indexes" {
+		t.Fatalf("Expected only missing_indexes, got %+v", alerts)
+	}
+	if want := "Table events has 50000 rows but only 0 indexes"; alerts[0].Message != want {
+		t.Errorf("Expected %q, got %q", want, alerts[0].Message)
+	}
+}
+
+func TestAlertManagerAddAndRemove(t *testing.T) {
+	am := NewAlertManager()
+	count := len(am.GetAlerts())
+
+	if err := am.AddAlert(types.MonitoringAlert{ID: "custom"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := am.AddAlert(types.MonitoringAlert{ID: "custom"}); err == nil {
+		t.Errorf("Expected an error for a duplicate ID")
+	}
+	if got := len(am.GetAlerts()); got != count+1 {
+		t.Errorf("Expected %d alerts, got %d", count+1, got)
+	}
+
+	if err := am.RemoveAlert("custom"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := am.RemoveAlert("custom"); err == nil {
+		t.Errorf("Expected an error for a removed alert")
+	}
+
+	alerts := am.GetAlerts()
+	alerts[0].ID = "changed"
+	if am.GetAlerts()[0].ID == "changed" {
+		t.Errorf("Expected GetAlerts to return a copy")
+	}
+}
@@ -37,9 +37,10 @@ func (dlt *DataLineageTrackerImpl) TrackLineage() (map[string]types.DataLineage,
 		for _, relationship := range table.Relationships {
 			if relationship.Type == "foreign_key" {
 				dep := types.LineageDependency{
-					TableName:  relationship.TargetTable,
-					ColumnName: relationship.TargetColumn,
-					Type:       "foreign_key",
+					TableName:    relationship.TargetTable,
+					ColumnName:   relationship.TargetColumn,
+					SourceColumn: relationship.SourceColumn,
+					Type:         "foreign_key",
 				}
 				tableLineage.UpstreamDeps = append(tableLineage.UpstreamDeps, dep)
 			}
@@ -0,0 +1,93 @@
+package monitoring
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+var mermaidIDPattern = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+func RenderLineageGraph(lineage map[string]types.DataLineage, format string) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case "", "dot":
+		return renderLineageDOT(lineage), nil
+	case "mermaid":
+		return renderLineageMermaid(lineage), nil
+	default:
+		return nil, fmt.Errorf("unsupported lineage graph format: %s", format)
+	}
+}
+
+func sortedLineageTables(lineage map[string]types.DataLineage) []string {
+	tables := make([]string, 0, len(lineage))
+	for tableName := range lineage {
+		tables = append(tables, tableName)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+func lineageEdgeLabel(dep types.LineageDependency) string {
+	if dep.SourceColumn == "" {
+		return dep.ColumnName
+	}
+	if dep.ColumnName == "" {
+		return dep.SourceColumn
+	}
+	return dep.SourceColumn + " -> " + dep.ColumnName
+}
+
+func renderLineageDOT(lineage map[string]types.DataLineage) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString("digraph lineage {\n")
+	buf.WriteString("  rankdir=LR;\n")
+	buf.WriteString("  node [shape=box];\n")
+
+	tables := sortedLineageTables(lineage)
+	for _, tableName := range tables {
+		fmt.Fprintf(&buf, "  %q;\n", tableName)
+	}
+
+	for _, tableName := range tables {
+		for _, dep := range lineage[tableName].UpstreamDeps {
+			fmt.Fprintf(&buf, "  %q -> %q [label=%q];\n", tableName, dep.TableName, lineageEdgeLabel(dep))
+		}
+	}
+
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+func renderLineageMermaid(lineage map[string]types.DataLineage) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString("flowchart LR\n")
+
+	tables := sortedLineageTables(lineage)
+	for _, tableName := range tables {
+		fmt.Fprintf(&buf, "  %s[\"%s\"]\n", mermaidNodeID(tableName), mermaidEscape(tableName))
+	}
+
+	for _, tableName := range tables {
+		for _, dep := range lineage[tableName].UpstreamDeps {
+			fmt.Fprintf(&buf, "  %s -->|\"%s\"| %s\n",
+				mermaidNodeID(tableName), mermaidEscape(lineageEdgeLabel(dep)), mermaidNodeID(dep.TableName))
+		}
+	}
+
+	return buf.Bytes()
+}
+
+func mermaidNodeID(name string) string {
+	return "t_" + mermaidIDPattern.ReplaceAllString(name, "_")
+}
+
+func mermaidEscape(text string) string {
+	return strings.ReplaceAll(text, `"`, "#quot;")
+}
@@ -0,0 +1,89 @@
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+func lineageGraphTestLineage() map[string]types.DataLineage {
+	return map[string]types.DataLineage{
+		"orders": {
+			TableName: "orders",
+			UpstreamDeps: []types.LineageDependency{
+				{TableName: "users", ColumnName: "id", SourceColumn: "user_id", Type: "foreign_key"},
+			},
+		},
+		"order-items": {
+			TableName: "order-items",
+			UpstreamDeps: []types.LineageDependency{
+				{TableName: "orders", ColumnName: `"id"`, Type: "foreign_key"},
+			},
+		},
+		"users": {TableName: "users"},
+	}
+}
+
+func TestRenderLineageGraphDOT(t *testing.T) {
+	for _, format := range []string{"", "dot", "DOT"} {
+		graph, err := RenderLineageGraph(lineageGraphTestLineage(), format)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		want := `digraph lineage {
+  rankdir=LR;
+  node [shape=box];
+  "order-items";
+  "orders";
+  "users";
+  "order-items" -> "orders" [label="\"id\""];
+  "orders" -> "users" [label="user_id -> id"];
+}
+`
+		if string(graph) != want {
+			t.Errorf("Expected\n%s\ngot\n%s", want, graph)
+		}
+	}
+}
+
+func TestRenderLineageGraphMermaid(t *testing.T) {
+	graph, err := RenderLineageGraph(lineageGraphTestLineage(), "mermaid")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := `flowchart LR
+  t_order_items["order-items"]
+  t_orders["orders"]
+  t_users["users"]
+  t_order_items -->|"#quot;id#quot;"| t_orders
+  t_orders -->|"user_id -> id"| t_users
+`
+	if string(graph) != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, graph)
+	}
+}
+
+func TestRenderLineageGraphUnsupportedFormat(t *testing.T) {
+	if _, err := RenderLineageGraph(lineageGraphTestLineage(), "svg"); err == nil {
+		t.Errorf("Expected an error for svg")
+	}
+}
+
+func TestLineageEdgeLabel(t *testing.T) {
+	tests := []struct {
+		dep  types.LineageDependency
+		want string
+	}{
+		{types.LineageDependency{SourceColumn: "user_id", ColumnName: "id"}, "user_id -> id"},
+		{types.LineageDependency{ColumnName: "id"}, "id"},
+		{types.LineageDependency{SourceColumn: "user_id"}, "user_id"},
+		{types.LineageDependency{}, ""},
+	}
+	for _, tt := range tests {
+		if got := lineageEdgeLabel(tt.dep); got != tt.want {
+			t.Errorf("Expected %q, got %q", tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package notification
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cherry-pick/pkg/logging"
+)
+
+type Notification struct {
+	ID        string                 `json:"id"`
+	Source    string                 `json:"source"`
+	Title     string                 `json:"title"`
+	Message   string                 `json:"message"`
+	Severity  string                 `json:"severity"`
+	Timestamp time.Time              `json:"timestamp"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+type NotificationChannel interface {
+	Name() string
+	Send(ctx context.Context, notification Notification) error
+}
+
+type registration struct {
+	channel    NotificationChannel
+	severities map[string]bool
+}
+
+type Dispatcher struct {
+	registrations []registration
+	timeout       time.Duration
+	logger        logging.Logger
+	mu            sync.RWMutex
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		timeout: time.Minute,
+		logger:  logging.Default(),
+	}
+}
+
+func (d *Dispatcher) Register(channel NotificationChannel, severities ...string) {
+	reg := registration{channel: channel}
+	if len(severities) > 0 {
+		reg.severities = make(map[string]bool, len(severities))
+		for _, severity := range severities {
+			reg.severities[strings.ToLower(strings.TrimSpace(severity))] = true
+		}
+	}
+
+	d.mu.Lock()
+	d.registrations = append(d.registrations, reg)
+	d.mu.Unlock()
+}
+
+func (d *Dispatcher) SetLogger(logger logging.Logger) {
+	if logger != nil {
+		d.logger = logger
+	}
+}
+
+func (d *Dispatcher) channelsFor(severity string) []NotificationChannel {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	severity = strings.ToLower(severity)
+	var channels []NotificationChannel
+	for _, reg := range d.registrations {
+		if reg.severities == nil || reg.severities[severity] {
+			channels = append(channels, reg.channel)
+		}
+	}
+	return channels
+}
+
+func (d *Dispatcher) Notify(ctx context.Context, notification Notification) error {
+	var firstErr error
+	for _, channel := range d.channelsFor(notification.Severity) {
+		if err := channel.Send(ctx, notification); err != nil {
+			d.logger.Warn("Failed to send notification", "channel", channel.Name(), "id", notification.ID, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+func (d *Dispatcher) Dispatch(notification Notification) {
+	if d == nil || len(d.channelsFor(notification.Severity)) == 0 {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+		defer cancel()
+		d.Notify(ctx, notification)
+	}()
+}
+
+var (
+	defaultDispatcher *Dispatcher
+	defaultOnce       sync.Once
+)
+
+func Default() *Dispatcher {
+	defaultOnce.Do(func() {
+		defaultDispatcher = NewDispatcher()
+
+		url := os.Getenv("ALERT_WEBHOOK_URL")
+		if url == "" {
+			return
+		}
+
+		var severities []string
+		if value := os.Getenv("ALERT_WEBHOOK_SEVERITIES"); value != "" {
+			severities = strings.Split(value, ",")
+		}
+
+		webhook, err := NewWebhookChannel(WebhookConfig{
+			URL:      url,
+			Secret:   os.Getenv("ALERT_WEBHOOK_SECRET"),
+			Template: os.Getenv("ALERT_WEBHOOK_TEMPLATE"),
+		})
+		if err != nil {
+			defaultDispatcher.logger.Warn("Ignoring invalid webhook configuration", "error", err)
+			return
+		}
+		defaultDispatcher.Register(webhook, severities...)
+	})
+	return defaultDispatcher
+}
@@ -0,0 +1,99 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cherry-pick/pkg/logging"
+)
+
+// mockChannel records what it is sent and fails with err when set.
+type mockChannel struct {
+	mu   sync.Mutex
+	name string
+	err  error
+	sent []Notification
+}
+
+func (m *mockChannel) Name() string { return m.name }
+
+func (m *mockChannel) Send(ctx context.Context, notification Notification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, notification)
+	return m.err
+}
+
+func (m *mockChannel) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sent)
+}
+
+func TestDispatcherFiltersBySeverity(t *testing.T) {
+	all := &mockChannel{name: "all"}
+	critical := &mockChannel{name: "critical"}
+	dispatcher := NewDispatcher()
+	dispatcher.Register(all)
+	dispatcher.Register(critical, " Critical ", "HIGH")
+
+	for _, severity := range []string{"low", "high", "critical"} {
+		if err := dispatcher.Notify(context.Background(), Notification{ID: severity, Severity: severity}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	if all.count() != 3 {
+		t.Errorf("Expected 3 notifications on the unfiltered channel, got %d", all.count())
+	}
+	if critical.count() != 2 {
+		t.Errorf("Expected 2 notifications on the filtered channel, got %d", critical.count())
+	}
+}
+
+func TestDispatcherNotifyReturnsFirstError(t *testing.T) {
+	errFirst := errors.New("first")
+	failing := &mockChannel{name: "failing", err: errFirst}
+	other := &mockChannel{name: "other", err: errors.New("second")}
+	healthy := &mockChannel{name: "healthy"}
+
+	dispatcher := NewDispatcher()
+	dispatcher.SetLogger(logging.NewTextLogger(io.Discard, slog.LevelWarn))
+	dispatcher.Register(failing)
+	dispatcher.Register(other)
+	dispatcher.Register(healthy)
+
+	if err := dispatcher.Notify(context.Background(), Notification{ID: "n1"}); !errors.Is(err, errFirst) {
+		t.Errorf("Expected the first error, got %v", err)
+	}
+	if healthy.count() != 1 {
+		t.Errorf("Expected later channels to still be notified, got %d", healthy.count())
+	}
+}
+
+func TestDispatcherDispatchIsAsynchronous(t *testing.T) {
+	channel := &mockChannel{name: "mock"}
+	dispatcher := NewDispatcher()
+	dispatcher.Register(channel)
+
+	dispatcher.Dispatch(Notification{ID: "n1"})
+
+	deadline := time.Now().Add(time.Second)
+	for channel.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if channel.count() != 1 {
+		t.Errorf("Expected 1 notification, got %d", channel.count())
+	}
+}
+
+func TestDispatcherDispatchWithoutChannels(t *testing.T) {
+	var nilDispatcher *Dispatcher
+	nilDispatcher.Dispatch(Notification{ID: "n1"})
+	NewDispatcher().Dispatch(Notification{ID: "n1"})
+}
@@ -0,0 +1,178 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"text/template"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Signature-256"
+	TimestampHeader = "X-Signature-Timestamp"
+
+	DefaultWebhookRetries = 3
+	DefaultWebhookBackoff = 500 * time.Millisecond
+	DefaultWebhookTimeout = 10 * time.Second
+)
+
+type WebhookConfig struct {
+	URL        string
+	Secret     string
+	Template   string
+	Headers    map[string]string
+	MaxRetries int
+	Backoff    time.Duration
+	Timeout    time.Duration
+}
+
+type WebhookChannel struct {
+	config   WebhookConfig
+	template *template.Template
+	client   *http.Client
+}
+
+func NewWebhookChannel(config WebhookConfig) (*WebhookChannel, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("webhook URL is required")
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	} else if config.MaxRetries == 0 {
+		config.MaxRetries = DefaultWebhookRetries
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = DefaultWebhookBackoff
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultWebhookTimeout
+	}
+
+	channel := &WebhookChannel{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+
+	if config.Template != "" {
+		tmpl, err := template.New("webhook").Funcs(template.FuncMap{"json": toJSON}).Parse(config.Template)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse webhook template: %w", err)
+		}
+		channel.template = tmpl
+	}
+
+	return channel, nil
+}
+
+func (wc *WebhookChannel) Name() string {
+	return "webhook"
+}
+
+func (wc *WebhookChannel) Send(ctx context.Context, notification Notification) error {
+	payload, err := wc.buildPayload(notification)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	backoff := wc.config.Backoff
+
+	for attempt := 0; attempt <= wc.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("webhook delivery cancelled: %w", ctx.Err())
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		retry, err := wc.deliver(ctx, payload)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+
+	return fmt.Errorf("failed to deliver webhook: %w", lastErr)
+}
+
+func (wc *WebhookChannel) buildPayload(notification Notification) ([]byte, error) {
+	if wc.template == nil {
+		payload, err := json.Marshal(notification)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal webhook payload: %w", err)
+		}
+		return payload, nil
+	}
+
+	var buf bytes.Buffer
+	if err := wc.template.Execute(&buf, notification); err != nil {
+		return nil, fmt.Errorf("failed to render webhook template: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("webhook template did not produce valid JSON")
+	}
+	return buf.Bytes(), nil
+}
+
+func (wc *WebhookChannel) deliver(ctx context.Context, payload []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wc.config.URL, bytes.NewReader(payload))
+	if err != nil {
+		return false, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range wc.config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	if wc.config.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, "sha256="+Sign(wc.config.Secret, timestamp, payload))
+	}
+
+	resp, err := wc.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("failed to send webhook request: %w", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		return true, fmt.Errorf("webhook returned error status: %d", resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return false, fmt.Errorf("webhook returned error status: %d", resp.StatusCode)
+	default:
+		return false, nil
+	}
+}
+
+func Sign(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func toJSON(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
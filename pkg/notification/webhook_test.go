@@ -0,0 +1,217 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testNotification() Notification {
+	return Notification{
+		ID:        "health",
+		Source:    "database",
+		Title:     "Low health",
+		Message:   "health_score is 62 (< 70)",
+		Severity:  "high",
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestNewWebhookChannelDefaults(t *testing.T) {
+	if _, err := NewWebhookChannel(WebhookConfig{}); err == nil {
+		t.Errorf("Expected an error without a URL")
+	}
+	if _, err := NewWebhookChannel(WebhookConfig{URL: "http://hooks", Template: "{{"}); err == nil {
+		t.Errorf("Expected an error for an invalid template")
+	}
+
+	channel, err := NewWebhookChannel(WebhookConfig{URL: "http://hooks"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if channel.config.MaxRetries != DefaultWebhookRetries {
+		t.Errorf("Expected %d retries, got %d", DefaultWebhookRetries, channel.config.MaxRetries)
+	}
+	if channel.config.Backoff != DefaultWebhookBackoff || channel.config.Timeout != DefaultWebhookTimeout {
+		t.Errorf("Expected default backoff and timeout, got %s and %s", channel.config.Backoff, channel.config.Timeout)
+	}
+
+	channel, _ = NewWebhookChannel(WebhookConfig{URL: "http://hooks", MaxRetries: -1})
+	if channel.config.MaxRetries != 0 {
+		t.Errorf("Expected a negative retry count to disable retries, got %d", channel.config.MaxRetries)
+	}
+	if channel.Name() != "webhook" {
+		t.Errorf("Expected webhook, got %s", channel.Name())
+	}
+}
+
+func TestWebhookSendsSignedJSON(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		request *http.Request
+		body    []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		request = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	channel, err := NewWebhookChannel(WebhookConfig{
+		URL:     server.URL,
+		Secret:  "topsecret",
+		Headers: map[string]string{"X-Team": "data"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := channel.Send(context.Background(), testNotification()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	var sent Notification
+	if err := json.Unmarshal(body, &sent); err != nil {
+		t.Fatalf("Expected a JSON body, got %v", err)
+	}
+	if sent.ID != "health" || sent.Severity != "high" {
+		t.Errorf("Expected the notification in the body, got %+v", sent)
+	}
+	if got := request.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Expected application/json, got %s", got)
+	}
+	if got := request.Header.Get("X-Team"); got != "data" {
+		t.Errorf("Expected the custom header, got %q", got)
+	}
+
+	timestamp := request.Header.Get(TimestampHeader)
+	want := "sha256=" + Sign("topsecret", timestamp, body)
+	if got := request.Header.Get(SignatureHeader); got != want {
+		t.Errorf("Expected signature %s, got %s", want, got)
+	}
+}
+
+func TestWebhookWithoutSecretIsUnsigned(t *testing.T) {
+	var signature atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature.Store(r.Header.Get(SignatureHeader))
+	}))
+	defer server.Close()
+
+	channel, _ := NewWebhookChannel(WebhookConfig{URL: server.URL})
+	if err := channel.Send(context.Background(), testNotification()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := signature.Load(); got != "" {
+		t.Errorf("Expected no signature, got %v", got)
+	}
+}
+
+func TestWebhookTemplate(t *testing.T) {
+	var body atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body.Store(string(data))
+	}))
+	defer server.Close()
+
+	channel, err := NewWebhookChannel(WebhookConfig{
+		URL:      server.URL,
+		Template: `{"text": {{json .Message}}, "level": "{{.Severity}}"}`,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := channel.Send(context.Background(), testNotification()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := `{"text": "health_score is 62 (\u003c 70)", "level": "high"}`
+	if got := body.Load(); got != want {
+		t.Errorf("Expected %s, got %v", want, got)
+	}
+}
+
+func TestWebhookTemplateMustProduceJSON(t *testing.T) {
+	channel, err := NewWebhookChannel(WebhookConfig{URL: "http://hooks", Template: "{{.Message}}"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	err = channel.Send(context.Background(), testNotification())
+	if err == nil || !strings.Contains(err.Error(), "valid JSON") {
+		t.Errorf("Expected an invalid JSON error, got %v", err)
+	}
+}
+
+func TestWebhookRetriesServerErrors(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	channel, _ := NewWebhookChannel(WebhookConfig{URL: server.URL, Backoff: time.Millisecond})
+	if err := channel.Send(context.Background(), testNotification()); err != nil {
+		t.Fatalf("Expected the third attempt to succeed, got %v", err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+}
+
+func TestWebhookDoesNotRetryClientErrors(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	channel, _ := NewWebhookChannel(WebhookConfig{URL: server.URL, Backoff: time.Millisecond})
+	if err := channel.Send(context.Background(), testNotification()); err == nil {
+		t.Fatalf("Expected an error for a 400 response")
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Errorf("Expected 1 attempt, got %d", got)
+	}
+}
+
+func TestWebhookStopsRetryingWhenCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	channel, _ := NewWebhookChannel(WebhookConfig{URL: server.URL, Backoff: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := channel.Send(ctx, testNotification())
+	if err == nil || !strings.Contains(err.Error(), "cancelled") {
+		t.Errorf("Expected a cancellation error, got %v", err)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{}' | openssl dgst -sha256 -hmac secret
+	want := "b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163"
+	if got := Sign("secret", "1700000000", []byte("{}")); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+	if Sign("secret", "1700000001", []byte("{}")) == want {
+		t.Errorf("Expected the timestamp to change the signature")
+	}
+}
@@ -0,0 +1,575 @@
+package optimization
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+// MaxIndexNameLength keeps generated index names within PostgreSQL's identifier limit.
+const MaxIndexNameLength = 63
+
+var (
+	fromClausePattern   = regexp.MustCompile(`(?i)\bfrom\s+([\w."` + "`" + `]+)(?:\s+(?:as\s+)?(\w+))?`)
+	updateClausePattern = regexp.MustCompile(`(?i)^update\s+([\w."` + "`" + `]+)(?:\s+(?:as\s+)?(\w+))?`)
+	wherePattern        = regexp.MustCompile(`(?i)\bwhere\b`)
+	whereEndPattern     = regexp.MustCompile(`(?i)\b(order\s+by|group\s+by|having|limit|offset|returning|for\s+update)\b`)
+	orderByPattern      = regexp.MustCompile(`(?i)\border\s+by\b`)
+	orderEndPattern     = regexp.MustCompile(`(?i)\b(limit|offset|for\s+update)\b`)
+	columnRefPattern    = regexp.MustCompile(`^(?:([\w"` + "`" + `]+)\.)?([\w"` + "`" + `]+)$`)
+	predicatePattern    = regexp.MustCompile(`(?i)^([\w."` + "`" + `]+)\s*(is\s+not\s+null|is\s+null|not\s+in\b|in\b|between\b|not\s+like\b|like\b|<>|!=|<=|>=|=|<|>)\s*(.*)$`)
+	placeholderPattern  = regexp.MustCompile(`^(\?|\$\d+|:\w+|@\w+)$`)
+	numberPattern       = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+	indexNamePattern    = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// aliasKeywords are words that can follow a table name without being its alias.
+var aliasKeywords = map[string]bool{
+	"where": true, "join": true, "inner": true, "left": true, "right": true, "full": true,
+	"cross": true, "on": true, "order": true, "group": true, "limit": true, "set": true,
+	"having": true, "union": true, "offset": true, "returning": true, "natural": true,
+}
+
+// queryPattern is what an index can serve in one query: the columns compared for
+// equality, the columns scanned by range, the ORDER BY columns and the predicates that
+// compare a column to a fixed value, which are candidates for a partial index.
+type queryPattern struct {
+	table     string
+	equality  []string
+	ranges    []string
+	orderBy   []string
+	constants []constantPredicate
+}
+
+type constantPredicate struct {
+	column   string
+	operator string
+	value    string
+	// filter is true when an index on the column can seek on the predicate itself, as it
+	// can for = and IN but not for <> or IS NOT NULL.
+	filter bool
+}
+
+func (cp constantPredicate) sql(dialect string) string {
+	if cp.value == "" {
+		return quoteIndexIdentifier(dialect, cp.column) + " " + cp.operator
+	}
+	return quoteIndexIdentifier(dialect, cp.column) + " " + cp.operator + " " + cp.value
+}
+
+// parseQueryPattern reads the main table, WHERE predicates and ORDER BY columns of a
+// single-table SELECT, UPDATE or DELETE. Columns qualified with another table's name, as
+// in joins, are ignored. A WHERE clause with a top-level OR yields no predicates, since no
+// single index serves both branches.
+//
+// String, boolean and NULL comparisons are treated as constant predicates. Numbers are
+// not, because slow query logs usually show parameters inlined as numbers.
+func parseQueryPattern(query string) (queryPattern, bool) {
+	query = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(query), ";"))
+	masked := maskStringLiterals(query)
+	lower := strings.ToLower(masked)
+
+	var match []int
+	switch {
+	case strings.HasPrefix(lower, "select"), strings.HasPrefix(lower, "delete"):
+		match = fromClausePattern.FindStringSubmatchIndex(masked)
+	case strings.HasPrefix(lower, "update"):
+		match = updateClausePattern.FindStringSubmatchIndex(masked)
+	}
+	if match == nil {
+		return queryPattern{}, false
+	}
+
+	pattern := queryPattern{table: unquoteIdentifier(lastIdentifierPart(query[match[2]:match[3]]))}
+	qualifiers := map[string]bool{strings.ToLower(pattern.table): true}
+	if match[4] != -1 {
+		if alias := query[match[4]:match[5]]; !aliasKeywords[strings.ToLower(alias)] {
+			qualifiers[strings.ToLower(alias)] = true
+		}
+	}
+
+	if where := clauseBounds(masked, wherePattern, whereEndPattern); where != nil {
+		pattern.addPredicates(query[where[0]:where[1]], masked[where[0]:where[1]], qualifiers)
+	}
+	if order := clauseBounds(masked, orderByPattern, orderEndPattern); order != nil {
+		for _, term := range strings.Split(query[order[0]:order[1]], ",") {
+			fields := strings.Fields(term)
+			if len(fields) == 0 {
+				continue
+			}
+			if column, ok := ownColumn(fields[0], qualifiers); ok {
+				pattern.orderBy = appendUnique(pattern.orderBy, column)
+			}
+		}
+	}
+
+	return pattern, pattern.table != ""
+}
+
+func (qp *queryPattern) addPredicates(where, masked string, qualifiers map[string]bool) {
+	terms, ok := splitConjunction(where, masked)
+	if !ok {
+		return
+	}
+
+	for _, term := range terms {
+		parts := predicatePattern.FindStringSubmatch(strings.TrimSpace(term))
+		if parts == nil {
+			continue
+		}
+		column, ok := ownColumn(parts[1], qualifiers)
+		if !ok {
+			continue
+		}
+		operator := strings.ToUpper(strings.Join(strings.Fields(parts[2]), " "))
+		value := strings.TrimSpace(parts[3])
+
+		switch operator {
+		case "IS NULL":
+			qp.constants = append(qp.constants, constantPredicate{column: column, operator: operator, filter: true})
+		case "IS NOT NULL":
+			qp.constants = append(qp.constants, constantPredicate{column: column, operator: operator})
+		case "=":
+			if isConstantValue(value) {
+				qp.constants = append(qp.constants, constantPredicate{column: column, operator: operator, value: value, filter: true})
+			} else if isBoundValue(value) {
+				qp.equality = appendUnique(qp.equality, column)
+			}
+		case "IN":
+			if values, ok := literalList(value); ok && allConstantValues(values) {
+				qp.constants = append(qp.constants, constantPredicate{column: column, operator: operator, value: value, filter: true})
+			} else {
+				qp.equality = appendUnique(qp.equality, column)
+			}
+		case "<>", "!=", "NOT IN":
+			if isConstantValue(value) || (operator == "NOT IN" && allConstantValues(mustLiteralList(value))) {
+				qp.constants = append(qp.constants, constantPredicate{column: column, operator: operator, value: value})
+			}
+		case "<", ">", "<=", ">=", "BETWEEN":
+			qp.ranges = appendUnique(qp.ranges, column)
+		case "LIKE":
+			if strings.HasPrefix(value, "'") && !strings.HasPrefix(value, "'%") && !strings.HasPrefix(value, "'_") {
+				qp.ranges = appendUnique(qp.ranges, column)
+			}
+		}
+	}
+}
+
+// RecommendIndexes proposes composite and partial indexes for the filter and sort
+// patterns in queries, weighting each query by its Frequency.
+func (qo *QueryOptimizerImpl) RecommendIndexes(queries []types.SlowQuery) []types.IndexRecommendation {
+	qo.mu.RLock()
+	tables := qo.tables
+	qo.mu.RUnlock()
+	return RecommendIndexes(queries, tables, qo.dialect)
+}
+
+// RecommendIndexes builds index recommendations for queries against tables, whose
+// column statistics decide the order of equality columns, most selective first. Sort
+// columns follow them and a range column comes last, since an index cannot be used for
+// ordering past a range scan.
+//
+// A constant predicate present in at least half of a table's weighted queries becomes the
+// WHERE clause of a partial index in dialects that support them (PostgreSQL and SQLite).
+// Recommendations already served by the leading columns of an existing index are left out.
+func RecommendIndexes(queries []types.SlowQuery, tables []types.TableInfo, dialect string) []types.IndexRecommendation {
+	dialect = normalizeIndexDialect(dialect)
+	byName := make(map[string]types.TableInfo, len(tables))
+	for _, table := range tables {
+		byName[strings.ToLower(table.Name)] = table
+	}
+
+	type weightedPattern struct {
+		pattern queryPattern
+		weight  int
+	}
+	var patterns []weightedPattern
+	tableWeight := make(map[string]int)
+	constantWeight := make(map[string]map[string]int)
+	for _, query := range queries {
+		pattern, ok := parseQueryPattern(query.Query)
+		if !ok {
+			continue
+		}
+		weight := query.Frequency
+		if weight <= 0 {
+			weight = 1
+		}
+		patterns = append(patterns, weightedPattern{pattern: pattern, weight: weight})
+		tableWeight[pattern.table] += weight
+
+		if constantWeight[pattern.table] == nil {
+			constantWeight[pattern.table] = make(map[string]int)
+		}
+		for _, constant := range pattern.constants {
+			constantWeight[pattern.table][constant.sql(dialect)] += weight
+		}
+	}
+
+	type candidate struct {
+		recommendation types.IndexRecommendation
+		weight         int
+	}
+	candidates := make(map[string]*candidate)
+	var order []string
+	for _, wp := range patterns {
+		pattern := wp.pattern
+		table := byName[strings.ToLower(pattern.table)]
+
+		var partial []constantPredicate
+		var inline []constantPredicate
+		for _, constant := range pattern.constants {
+			common := 2*constantWeight[pattern.table][constant.sql(dialect)] >= tableWeight[pattern.table]
+			if common && dialect != "mysql" {
+				partial = append(partial, constant)
+			} else if constant.filter {
+				inline = append(inline, constant)
+			}
+		}
+
+		equality := append([]string{}, pattern.equality...)
+		for _, constant := range inline {
+			equality = appendUnique(equality, constant.column)
+		}
+		columns := orderIndexColumns(equality, pattern.orderBy, pattern.ranges, table)
+		if len(columns) == 0 && len(partial) > 0 {
+			// Every filter is constant: index the filtered columns and keep the predicate so
+			// the index only holds the matching rows.
+			for _, constant := range partial {
+				columns = appendUnique(columns, constant.column)
+			}
+		}
+		if len(columns) == 0 || coveredByIndex(table, columns, len(partial) > 0) {
+			continue
+		}
+
+		var where []string
+		for _, constant := range partial {
+			where = append(where, constant.sql(dialect))
+		}
+		sort.Strings(where)
+		whereClause := strings.Join(where, " AND ")
+
+		key := pattern.table + "|" + strings.Join(columns, ",") + "|" + whereClause
+		if existing, ok := candidates[key]; ok {
+			existing.weight += wp.weight
+			existing.recommendation.Queries++
+			continue
+		}
+		candidates[key] = &candidate{
+			recommendation: types.IndexRecommendation{
+				Table:     pattern.table,
+				Columns:   columns,
+				Where:     whereClause,
+				Statement: createIndexStatement(dialect, pattern.table, columns, whereClause),
+				Reason:    indexReason(equality, pattern, columns, whereClause),
+				Queries:   1,
+			},
+			weight: wp.weight,
+		}
+		order = append(order, key)
+	}
+
+	recommendations := make([]types.IndexRecommendation, 0, len(order))
+	sort.SliceStable(order, func(i, j int) bool {
+		return candidates[order[i]].weight > candidates[order[j]].weight
+	})
+	for _, key := range order {
+		recommendations = append(recommendations, candidates[key].recommendation)
+	}
+	return recommendations
+}
+
+// orderIndexColumns places equality columns first, most selective first, then the sort
+// columns and finally one range column.
+func orderIndexColumns(equality, orderBy, ranges []string, table types.TableInfo) []string {
+	selectivity := columnSelectivity(table)
+	sorted := append([]string{}, equality...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return selectivity[strings.ToLower(sorted[i])] > selectivity[strings.ToLower(sorted[j])]
+	})
+
+	columns := sorted
+	for _, column := range orderBy {
+		columns = appendUnique(columns, column)
+	}
+	for _, column := range ranges {
+		if !containsFold(columns, column) {
+			columns = append(columns, column)
+			break
+		}
+	}
+	return columns
+}
+
+// columnSelectivity is the share of distinct values per column, from the last analysis.
+// Columns without statistics are treated as least selective and keep their query order.
+func columnSelectivity(table types.TableInfo) map[string]float64 {
+	selectivity := make(map[string]float64, len(table.Columns))
+	if table.RowCount <= 0 {
+		return selectivity
+	}
+	for _, column := range table.Columns {
+		if column.UniqueValues > 0 {
+			selectivity[strings.ToLower(column.Name)] = float64(column.UniqueValues) / float64(table.RowCount)
+		}
+	}
+	return selectivity
+}
+
+// coveredByIndex reports whether an existing index starts with columns. Analysis does not
+// record index predicates, so a partial recommendation is only covered by an index on
+// exactly the same columns.
+func coveredByIndex(table types.TableInfo, columns []string, partial bool) bool {
+	for _, index := range table.Indexes {
+		if len(index.Columns) < len(columns) || (partial && len(index.Columns) != len(columns)) {
+			continue
+		}
+		covered := true
+		for i, column := range columns {
+			if !strings.EqualFold(index.Columns[i], column) {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+	return false
+}
+
+func createIndexStatement(dialect, table string, columns []string, where string) string {
+	name := "idx_" + table + "_" + strings.Join(columns, "_")
+	if where != "" {
+		name += "_partial"
+	}
+	name = strings.Trim(indexNamePattern.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if len(name) > MaxIndexNameLength {
+		name = name[:MaxIndexNameLength]
+	}
+
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quoteIndexIdentifier(dialect, column)
+	}
+	statement := fmt.Sprintf("CREATE INDEX %s ON %s (%s)",
+		quoteIndexIdentifier(dialect, name), quoteIndexIdentifier(dialect, table), strings.Join(quoted, ", "))
+	if where != "" {
+		statement += " WHERE " + where
+	}
+	return statement + ";"
+}
+
+func indexReason(equality []string, pattern queryPattern, columns []string, where string) string {
+	var parts []string
+	if len(equality) > 0 {
+		parts = append(parts, "filters on "+strings.Join(equality, ", "))
+	}
+	if len(pattern.ranges) > 0 {
+		parts = append(parts, "scans a range of "+strings.Join(pattern.ranges, ", "))
+	}
+	if len(pattern.orderBy) > 0 {
+		parts = append(parts, "sorts by "+strings.Join(pattern.orderBy, ", "))
+	}
+	reason := fmt.Sprintf("Query %s", strings.Join(parts, "; "))
+	if len(parts) == 0 {
+		reason = "Query filters on constant values"
+	}
+	if len(columns) > 1 {
+		reason += "; a composite index serves the whole pattern"
+	}
+	if where != "" {
+		reason += "; the partial index only holds rows where " + where
+	}
+	return reason
+}
+
+func normalizeIndexDialect(dialect string) string {
+	switch strings.ToLower(dialect) {
+	case "mysql":
+		return "mysql"
+	case "sqlite", "sqlite3":
+		return "sqlite"
+	}
+	return "postgres"
+}
+
+func quoteIndexIdentifier(dialect, name string) string {
+	if dialect == "mysql" {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// clauseBounds finds the text between the start keyword and the next end keyword in
+// masked, returning its byte offsets.
+func clauseBounds(masked string, start, end *regexp.Regexp) []int {
+	loc := start.FindStringIndex(masked)
+	if loc == nil {
+		return nil
+	}
+	bounds := []int{loc[1], len(masked)}
+	if stop := end.FindStringIndex(masked[loc[1]:]); stop != nil {
+		bounds[1] = loc[1] + stop[0]
+	}
+	return bounds
+}
+
+// splitConjunction splits a WHERE clause on top-level AND, keeping BETWEEN x AND y
+// together. It fails when the clause has a top-level OR.
+func splitConjunction(where, masked string) ([]string, bool) {
+	var terms []string
+	depth, start := 0, 0
+	lower := strings.ToLower(masked)
+	pendingBetween := false
+	for i := 0; i < len(lower); i++ {
+		switch lower[i] {
+		case '(':
+			depth++
+			continue
+		case ')':
+			depth--
+			continue
+		}
+		if depth != 0 || !wordAt(lower, i) {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(lower[i:], "between"):
+			pendingBetween = true
+		case strings.HasPrefix(lower[i:], "or") && wordEnds(lower, i+2):
+			return nil, false
+		case strings.HasPrefix(lower[i:], "and") && wordEnds(lower, i+3):
+			if pendingBetween {
+				pendingBetween = false
+				continue
+			}
+			terms = append(terms, where[start:i])
+			start = i + 3
+		}
+	}
+	return append(terms, where[start:]), true
+}
+
+func wordAt(s string, i int) bool {
+	return i == 0 || !isIdentifierByte(s[i-1])
+}
+
+func wordEnds(s string, i int) bool {
+	return i >= len(s) || !isIdentifierByte(s[i])
+}
+
+func isIdentifierByte(b byte) bool {
+	return b == '_' || b == '.' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// maskStringLiterals blanks out the contents of quoted strings so keywords inside them
+// are not mistaken for clauses. Offsets are preserved.
+func maskStringLiterals(query string) string {
+	masked := []byte(query)
+	inString := false
+	for i := 0; i < len(masked); i++ {
+		if masked[i] == '\'' {
+			if inString && i+1 < len(masked) && masked[i+1] == '\'' {
+				masked[i], masked[i+1] = 'x', 'x'
+				i++
+				continue
+			}
+			inString = !inString
+			continue
+		}
+		if inString {
+			masked[i] = 'x'
+		}
+	}
+	return string(masked)
+}
+
+// ownColumn returns the column name of ref when it is unqualified or qualified with the
+// query's main table or alias.
+func ownColumn(ref string, qualifiers map[string]bool) (string, bool) {
+	parts := columnRefPattern.FindStringSubmatch(strings.TrimSpace(ref))
+	if parts == nil {
+		return "", false
+	}
+	if parts[1] != "" && !qualifiers[strings.ToLower(unquoteIdentifier(parts[1]))] {
+		return "", false
+	}
+	column := unquoteIdentifier(parts[2])
+	if numberPattern.MatchString(column) {
+		return "", false
+	}
+	return column, true
+}
+
+func lastIdentifierPart(name string) string {
+	if i := strings.LastIndex(name, "."); i != -1 {
+		return name[i+1:]
+	}
+	return name
+}
+
+func unquoteIdentifier(name string) string {
+	return strings.Trim(name, "\"`")
+}
+
+func isConstantValue(value string) bool {
+	lower := strings.ToLower(value)
+	return (strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") && len(value) >= 2) ||
+		lower == "true" || lower == "false"
+}
+
+// isBoundValue reports whether value is a parameter or literal rather than another
+// column, which would make the predicate a join condition.
+func isBoundValue(value string) bool {
+	return placeholderPattern.MatchString(value) || numberPattern.MatchString(value)
+}
+
+func literalList(value string) ([]string, bool) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "(") || !strings.HasSuffix(value, ")") {
+		return nil, false
+	}
+	var values []string
+	for _, item := range strings.Split(value[1:len(value)-1], ",") {
+		values = append(values, strings.TrimSpace(item))
+	}
+	return values, true
+}
+
+func mustLiteralList(value string) []string {
+	values, _ := literalList(value)
+	return values
+}
+
+func allConstantValues(values []string) bool {
+	if len(values) == 0 {
+		return false
+	}
+	for _, value := range values {
+		if !isConstantValue(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func appendUnique(columns []string, column string) []string {
+	if containsFold(columns, column) {
+		return columns
+	}
+	return append(columns, column)
+}
+
+func containsFold(columns []string, column string) bool {
+	for _, existing := range columns {
+		if strings.EqualFold(existing, column) {
+			return true
+		}
+	}
+	return false
+}
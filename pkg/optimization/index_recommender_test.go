@@ -0,0 +1,217 @@
+package optimization
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+func TestParseQueryPattern(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  queryPattern
+	}{
+		{
+			"equality, range and order",
+			"SELECT * FROM public.orders o WHERE o.user_id = $1 AND created_at > $2 ORDER BY o.created_at DESC LIMIT 10;",
+			queryPattern{table: "orders", equality: []string{"user_id"}, ranges: []string{"created_at"}, orderBy: []string{"created_at"}},
+		},
+		{
+			"constants",
+			"SELECT id FROM orders WHERE status = 'open' AND deleted_at IS NULL AND archived IS NOT NULL",
+			queryPattern{table: "orders", constants: []constantPredicate{
+				{column: "status", operator: "=", value: "'open'", filter: true},
+				{column: "deleted_at", operator: "IS NULL", filter: true},
+				{column: "archived", operator: "IS NOT NULL"},
+			}},
+		},
+		{
+			"between and like",
+			"DELETE FROM events WHERE ts BETWEEN ? AND ? AND name LIKE 'abc%' AND tag LIKE '%x'",
+			queryPattern{table: "events", ranges: []string{"ts", "name"}},
+		},
+		{
+			"update with in lists",
+			"UPDATE `users` u SET active = false WHERE u.id IN (?, ?) AND role NOT IN ('admin', 'owner') AND kind IN ('a', 'b')",
+			queryPattern{table: "users", equality: []string{"id"}, constants: []constantPredicate{
+				{column: "role", operator: "NOT IN", value: "('admin', 'owner')"},
+				{column: "kind", operator: "IN", value: "('a', 'b')", filter: true},
+			}},
+		},
+		{
+			"top-level or",
+			"SELECT * FROM users WHERE email = ? OR name = ? ORDER BY id",
+			queryPattern{table: "users", orderBy: []string{"id"}},
+		},
+		{
+			"join columns ignored",
+			"SELECT * FROM orders o JOIN users u ON u.id = o.user_id WHERE u.email = ? AND o.total >= 10 AND o.user_id = u.id",
+			queryPattern{table: "orders", ranges: []string{"total"}},
+		},
+		{
+			"keywords inside strings",
+			"SELECT * FROM notes WHERE body = 'x or y order by z' AND owner = :owner",
+			queryPattern{table: "notes", equality: []string{"owner"}, constants: []constantPredicate{
+				{column: "body", operator: "=", value: "'x or y order by z'", filter: true},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseQueryPattern(tt.query)
+			if !ok {
+				t.Fatalf("Expected the query to parse")
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseQueryPatternRejectsOtherStatements(t *testing.T) {
+	for _, query := range []string{"INSERT INTO users VALUES (1)", "EXPLAIN SELECT 1", ""} {
+		if _, ok := parseQueryPattern(query); ok {
+			t.Errorf("Expected %q not to parse", query)
+		}
+	}
+}
+
+func TestRecommendIndexesOrdersColumnsBySelectivity(t *testing.T) {
+	tables := []types.TableInfo{{
+		Name:     "orders",
+		RowCount: 1000,
+		Columns: []types.ColumnInfo{
+			{Name: "status", UniqueValues: 5},
+			{Name: "user_id", UniqueValues: 800},
+		},
+	}}
+	queries := []types.SlowQuery{
+		{Query: "SELECT * FROM orders WHERE status = ? AND user_id = ? AND created_at > ? ORDER BY placed_at", Frequency: 3},
+		{Query: "SELECT * FROM orders WHERE status = $1 AND user_id = $2 AND created_at > $3 ORDER BY placed_at"},
+	}
+
+	recommendations := RecommendIndexes(queries, tables, "postgres")
+	if len(recommendations) != 1 {
+		t.Fatalf("Expected 1 recommendation, got %+v", recommendations)
+	}
+
+	got := recommendations[0]
+	if want := []string{"user_id", "status", "placed_at", "created_at"}; !reflect.DeepEqual(got.Columns, want) {
+		t.Errorf("Expected columns %v, got %v", want, got.Columns)
+	}
+	if got.Queries != 2 {
+		t.Errorf("Expected 2 queries, got %d", got.Queries)
+	}
+	want := `CREATE INDEX "idx_orders_user_id_status_placed_at_created_at" ON "orders" ("user_id", "status", "placed_at", "created_at");`
+	if got.Statement != want {
+		t.Errorf("Expected %s, got %s", want, got.Statement)
+	}
+}
+
+func TestRecommendIndexesPartialIndex(t *testing.T) {
+	queries := []types.SlowQuery{
+		{Query: "SELECT * FROM jobs WHERE state = 'queued' AND run_at < ?", Frequency: 5},
+		{Query: "SELECT * FROM jobs WHERE state = 'queued'", Frequency: 2},
+		{Query: "SELECT * FROM jobs WHERE state = 'failed' AND owner = ?", Frequency: 1},
+	}
+
+	recommendations := RecommendIndexes(queries, nil, "sqlite3")
+	if len(recommendations) != 3 {
+		t.Fatalf("Expected 3 recommendations, got %+v", recommendations)
+	}
+
+	first := recommendations[0]
+	if first.Where != `"state" = 'queued'` || !reflect.DeepEqual(first.Columns, []string{"run_at"}) {
+		t.Errorf("Expected a partial index on run_at, got %+v", first)
+	}
+	if want := `CREATE INDEX "idx_jobs_run_at_partial" ON "jobs" ("run_at") WHERE "state" = 'queued';`; first.Statement != want {
+		t.Errorf("Expected %s, got %s", want, first.Statement)
+	}
+
+	second := recommendations[1]
+	if second.Where != `"state" = 'queued'` || !reflect.DeepEqual(second.Columns, []string{"state"}) {
+		t.Errorf("Expected a partial index on the constant column, got %+v", second)
+	}
+
+	third := recommendations[2]
+	if third.Where != "" || !reflect.DeepEqual(third.Columns, []string{"owner", "state"}) {
+		t.Errorf("Expected the rare constant to be indexed inline, got %+v", third)
+	}
+}
+
+func TestRecommendIndexesMySQLHasNoPartialIndexes(t *testing.T) {
+	queries := []types.SlowQuery{{Query: "SELECT * FROM jobs WHERE state = 'queued' AND run_at < ?"}}
+
+	recommendations := RecommendIndexes(queries, nil, "mysql")
+	if len(recommendations) != 1 {
+		t.Fatalf("Expected 1 recommendation, got %+v", recommendations)
+	}
+	want := "CREATE INDEX `idx_jobs_state_run_at` ON `jobs` (`state`, `run_at`);"
+	if recommendations[0].Statement != want {
+		t.Errorf("Expected %s, got %s", want, recommendations[0].Statement)
+	}
+}
+
+func TestRecommendIndexesSkipsCoveredPatterns(t *testing.T) {
+	tables := []types.TableInfo{{
+		Name:    "users",
+		Indexes: []types.IndexInfo{{Name: "users_email_name_idx", Columns: []string{"EMAIL", "name"}}},
+	}}
+	queries := []types.SlowQuery{
+		{Query: "SELECT * FROM users WHERE email = ?"},
+		{Query: "SELECT * FROM users WHERE email = 'a@b.c'", Frequency: 2},
+		{Query: "SELECT * FROM users"},
+	}
+
+	recommendations := RecommendIndexes(queries, tables, "postgres")
+	if len(recommendations) != 1 {
+		t.Fatalf("Expected only the partial index to be recommended, got %+v", recommendations)
+	}
+	if want := `"email" = 'a@b.c'`; recommendations[0].Where != want {
+		t.Errorf("Expected %s, got %s", want, recommendations[0].Where)
+	}
+}
+
+func TestQueryOptimizerRecommendIndexesUsesTableStats(t *testing.T) {
+	optimizer := NewQueryOptimizerWithDialect("mysql").(*QueryOptimizerImpl)
+	optimizer.SetTableStats([]types.TableInfo{{
+		Name:    "users",
+		Indexes: []types.IndexInfo{{Name: "users_email_idx", Columns: []string{"email"}}},
+	}})
+
+	if recommendations := optimizer.RecommendIndexes([]types.SlowQuery{{Query: "SELECT * FROM users WHERE email = ?"}}); len(recommendations) != 0 {
+		t.Errorf("Expected the existing index to cover the query, got %+v", recommendations)
+	}
+}
+
+func TestCreateIndexStatementTruncatesName(t *testing.T) {
+	columns := []string{"a_very_long_column_name", "another_very_long_column_name", "third"}
+	statement := createIndexStatement("postgres", "Some Table", columns, "")
+	want := `CREATE INDEX "idx_some_table_a_very_long_column_name_another_very_long_column" ON "Some Table" ("a_very_long_column_name", "another_very_long_column_name", "third");`
+	if statement != want {
+		t.Errorf("Expected %s, got %s", want, statement)
+	}
+}
+
+func TestIndexReason(t *testing.T) {
+	pattern := queryPattern{ranges: []string{"created_at"}, orderBy: []string{"id"}}
+	got := indexReason([]string{"user_id"}, pattern, []string{"user_id", "id", "created_at"}, `"deleted" = false`)
+	want := `Query filters on user_id; scans a range of created_at; sorts by id; a composite index serves the whole pattern; the partial index only holds rows where "deleted" = false`
+	if got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+
+	if got := indexReason(nil, queryPattern{}, []string{"state"}, ""); got != "Query filters on constant values" {
+		t.Errorf("Expected the constant reason, got %s", got)
+	}
+}
+
+func TestMaskStringLiterals(t *testing.T) {
+	got := maskStringLiterals("a = 'it''s' AND b = 'x'")
+	if want := "a = 'xxxxx' AND b = 'x'"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
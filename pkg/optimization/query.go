@@ -2,18 +2,47 @@ package optimization
 
 import (
 	"strings"
+	"sync"
 
 	"github.com/cherry-pick/pkg/interfaces"
 	"github.com/cherry-pick/pkg/types"
 )
 
-type QueryOptimizerImpl struct{}
+type QueryOptimizerImpl struct {
+	dialect string
+
+	mu     sync.RWMutex
+	tables []types.TableInfo
+}
 
 func NewQueryOptimizer() interfaces.QueryOptimizer {
-	return &QueryOptimizerImpl{}
+	return NewQueryOptimizerWithDialect("")
+}
+
+// NewQueryOptimizerWithDialect writes index recommendations for the given driver's SQL
+// dialect; an unknown or empty driver gets PostgreSQL syntax.
+func NewQueryOptimizerWithDialect(driverName string) interfaces.QueryOptimizer {
+	return &QueryOptimizerImpl{dialect: normalizeIndexDialect(driverName)}
+}
+
+// SetTableStats gives the optimizer the tables from the latest analysis, whose row and
+// distinct-value counts order the columns of recommended indexes.
+func (qo *QueryOptimizerImpl) SetTableStats(tables []types.TableInfo) {
+	qo.mu.Lock()
+	defer qo.mu.Unlock()
+	qo.tables = tables
 }
 
 func (qo *QueryOptimizerImpl) AnalyzeQuery(query string) (*types.OptimizationSuggestion, error) {
+	suggestion, err := qo.analyzeQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	suggestion.IndexRecommendations = qo.RecommendIndexes([]types.SlowQuery{{Query: query, Frequency: 1}})
+	return suggestion, nil
+}
+
+func (qo *QueryOptimizerImpl) analyzeQuery(query string) (*types.OptimizationSuggestion, error) {
 	suggestion := &types.OptimizationSuggestion{
 		OriginalQuery: query,
 	}
@@ -0,0 +1,200 @@
+package optimization
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+const DefaultHistorySize = 200
+
+var savedQuerySequence uint64
+
+type SavedQueryStore struct {
+	maxHistory int
+	queries    map[string]map[string]*types.SavedQuery
+	history    map[string][]types.OptimizationHistoryEntry
+	mu         sync.RWMutex
+}
+
+// NewSavedQueryStore keeps at most maxHistory optimization entries per connection, oldest dropped first.
+func NewSavedQueryStore(maxHistory int) *SavedQueryStore {
+	if maxHistory <= 0 {
+		maxHistory = DefaultHistorySize
+	}
+
+	return &SavedQueryStore{
+		maxHistory: maxHistory,
+		queries:    make(map[string]map[string]*types.SavedQuery),
+		history:    make(map[string][]types.OptimizationHistoryEntry),
+	}
+}
+
+func (ss *SavedQueryStore) Save(query types.SavedQuery) (*types.SavedQuery, error) {
+	if query.ConnectionID == "" {
+		return nil, fmt.Errorf("connection ID is required")
+	}
+	if query.Query == "" {
+		return nil, fmt.Errorf("query text is required")
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	now := time.Now()
+	query.ID = nextSavedQueryID("query")
+	query.CreatedAt = now
+	query.UpdatedAt = now
+	query.LatestSuggestion = nil
+	query.Applied = false
+	query.AppliedAt = nil
+
+	if ss.queries[query.ConnectionID] == nil {
+		ss.queries[query.ConnectionID] = make(map[string]*types.SavedQuery)
+	}
+	ss.queries[query.ConnectionID][query.ID] = &query
+
+	saved := query
+	return &saved, nil
+}
+
+func (ss *SavedQueryStore) Get(connectionID, queryID string) (*types.SavedQuery, error) {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	query, exists := ss.queries[connectionID][queryID]
+	if !exists {
+		return nil, fmt.Errorf("saved query %s not found", queryID)
+	}
+	saved := *query
+	return &saved, nil
+}
+
+func (ss *SavedQueryStore) List(connectionID string) []types.SavedQuery {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	queries := make([]types.SavedQuery, 0, len(ss.queries[connectionID]))
+	for _, query := range ss.queries[connectionID] {
+		queries = append(queries, *query)
+	}
+	sort.Slice(queries, func(i, j int) bool {
+		return queries[i].CreatedAt.Before(queries[j].CreatedAt)
+	})
+	return queries
+}
+
+// Update changes the name, text or applied flag. Editing the query text clears the
+// applied flag since the previous suggestion no longer describes it.
+func (ss *SavedQueryStore) Update(connectionID, queryID string, name, text *string, applied *bool) (*types.SavedQuery, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	query, exists := ss.queries[connectionID][queryID]
+	if !exists {
+		return nil, fmt.Errorf("saved query %s not found", queryID)
+	}
+
+	now := time.Now()
+	if name != nil {
+		query.Name = *name
+	}
+	if text != nil && *text != query.Query {
+		if *text == "" {
+			return nil, fmt.Errorf("query text is required")
+		}
+		query.Query = *text
+		query.Applied = false
+		query.AppliedAt = nil
+	}
+	if applied != nil && *applied != query.Applied {
+		query.Applied = *applied
+		query.AppliedAt = nil
+		if *applied {
+			query.AppliedAt = &now
+		}
+	}
+	query.UpdatedAt = now
+
+	saved := *query
+	return &saved, nil
+}
+
+func (ss *SavedQueryStore) Delete(connectionID, queryID string) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if _, exists := ss.queries[connectionID][queryID]; !exists {
+		return fmt.Errorf("saved query %s not found", queryID)
+	}
+	delete(ss.queries[connectionID], queryID)
+	return nil
+}
+
+// RecordSuggestion appends a history entry and, when queryID names a saved query,
+// makes the suggestion its latest one.
+func (ss *SavedQueryStore) RecordSuggestion(connectionID, queryID, query string, suggestion types.OptimizationSuggestion) (types.OptimizationHistoryEntry, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	now := time.Now()
+	if queryID != "" {
+		saved, exists := ss.queries[connectionID][queryID]
+		if !exists {
+			return types.OptimizationHistoryEntry{}, fmt.Errorf("saved query %s not found", queryID)
+		}
+		latest := suggestion
+		saved.LatestSuggestion = &latest
+		saved.UpdatedAt = now
+	}
+
+	entry := types.OptimizationHistoryEntry{
+		ID:           nextSavedQueryID("optimization"),
+		ConnectionID: connectionID,
+		QueryID:      queryID,
+		Query:        query,
+		Suggestion:   suggestion,
+		CreatedAt:    now,
+	}
+
+	entries := append(ss.history[connectionID], entry)
+	if len(entries) > ss.maxHistory {
+		entries = entries[len(entries)-ss.maxHistory:]
+	}
+	ss.history[connectionID] = entries
+
+	return entry, nil
+}
+
+// History returns entries newest first, optionally restricted to one saved query.
+func (ss *SavedQueryStore) History(connectionID, queryID string) []types.OptimizationHistoryEntry {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	entries := ss.history[connectionID]
+	history := make([]types.OptimizationHistoryEntry, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		if queryID != "" && entries[i].QueryID != queryID {
+			continue
+		}
+		history = append(history, entries[i])
+	}
+	return history
+}
+
+func (ss *SavedQueryStore) DeleteConnection(connectionID string) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	delete(ss.queries, connectionID)
+	delete(ss.history, connectionID)
+}
+
+func nextSavedQueryID(prefix string) string {
+	return prefix + "_" + strconv.FormatInt(time.Now().UnixNano(), 36) + "_" + strconv.FormatUint(atomic.AddUint64(&savedQuerySequence, 1), 10)
+}
@@ -0,0 +1,205 @@
+package optimization
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+func TestSavedQueryStoreSave(t *testing.T) {
+	store := NewSavedQueryStore(0)
+
+	if _, err := store.Save(types.SavedQuery{Query: "SELECT 1"}); err == nil {
+		t.Errorf("Expected an error without a connection ID")
+	}
+	if _, err := store.Save(types.SavedQuery{ConnectionID: "conn"}); err == nil {
+		t.Errorf("Expected an error without query text")
+	}
+
+	saved, err := store.Save(types.SavedQuery{
+		ConnectionID:     "conn",
+		Name:             "recent orders",
+		Query:            "SELECT * FROM orders",
+		Applied:          true,
+		LatestSuggestion: &types.OptimizationSuggestion{},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if saved.ID == "" || saved.CreatedAt.IsZero() {
+		t.Errorf("Expected an ID and creation time, got %+v", saved)
+	}
+	if saved.Applied || saved.LatestSuggestion != nil {
+		t.Errorf("Expected client-supplied state to be reset, got %+v", saved)
+	}
+
+	saved.Name = "changed"
+	got, err := store.Get("conn", saved.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.Name != "recent orders" {
+		t.Errorf("Expected the store to keep its own copy, got %s", got.Name)
+	}
+
+	if _, err := store.Get("other", saved.ID); err == nil {
+		t.Errorf("Expected an error for another connection")
+	}
+}
+
+func TestSavedQueryStoreListOrdersByCreation(t *testing.T) {
+	store := NewSavedQueryStore(0)
+	for _, text := range []string{"SELECT 1", "SELECT 2", "SELECT 3"} {
+		if _, err := store.Save(types.SavedQuery{ConnectionID: "conn", Query: text}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	queries := store.List("conn")
+	if len(queries) != 3 {
+		t.Fatalf("Expected 3 queries, got %d", len(queries))
+	}
+	for i := 1; i < len(queries); i++ {
+		if queries[i].CreatedAt.Before(queries[i-1].CreatedAt) {
+			t.Errorf("Expected queries in creation order, got %+v", queries)
+		}
+	}
+	if len(store.List("other")) != 0 {
+		t.Errorf("Expected no queries for another connection")
+	}
+}
+
+func TestSavedQueryStoreUpdate(t *testing.T) {
+	store := NewSavedQueryStore(0)
+	saved, err := store.Save(types.SavedQuery{ConnectionID: "conn", Query: "SELECT 1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	applied := true
+	updated, err := store.Update("conn", saved.ID, nil, nil, &applied)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !updated.Applied || updated.AppliedAt == nil {
+		t.Errorf("Expected the query to be marked applied, got %+v", updated)
+	}
+
+	name, text := "renamed", "SELECT 2"
+	updated, err = store.Update("conn", saved.ID, &name, &text, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.Name != name || updated.Query != text {
+		t.Errorf("Expected the name and text to change, got %+v", updated)
+	}
+	if updated.Applied || updated.AppliedAt != nil {
+		t.Errorf("Expected editing the text to clear the applied flag, got %+v", updated)
+	}
+
+	empty := ""
+	if _, err := store.Update("conn", saved.ID, nil, &empty, nil); err == nil {
+		t.Errorf("Expected an error for empty query text")
+	}
+	if _, err := store.Update("conn", "missing", &name, nil, nil); err == nil {
+		t.Errorf("Expected an error for a missing query")
+	}
+}
+
+func TestSavedQueryStoreDelete(t *testing.T) {
+	store := NewSavedQueryStore(0)
+	saved, err := store.Save(types.SavedQuery{ConnectionID: "conn", Query: "SELECT 1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := store.Delete("conn", saved.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := store.Delete("conn", saved.ID); err == nil {
+		t.Errorf("Expected an error deleting twice")
+	}
+}
+
+func TestSavedQueryStoreRecordSuggestion(t *testing.T) {
+	store := NewSavedQueryStore(2)
+	saved, err := store.Save(types.SavedQuery{ConnectionID: "conn", Query: "SELECT * FROM orders"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := store.RecordSuggestion("conn", "missing", "SELECT 1", types.OptimizationSuggestion{}); err == nil {
+		t.Errorf("Expected an error for a missing saved query")
+	}
+
+	if _, err := store.RecordSuggestion("conn", "", "SELECT 1", types.OptimizationSuggestion{Confidence: 0.1}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := store.RecordSuggestion("conn", saved.ID, saved.Query, types.OptimizationSuggestion{Confidence: 0.5}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	entry, err := store.RecordSuggestion("conn", saved.ID, saved.Query, types.OptimizationSuggestion{Confidence: 0.9})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if entry.ID == "" || entry.QueryID != saved.ID {
+		t.Errorf("Expected an entry for the saved query, got %+v", entry)
+	}
+
+	got, err := store.Get("conn", saved.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.LatestSuggestion == nil || got.LatestSuggestion.Confidence != 0.9 {
+		t.Errorf("Expected the latest suggestion to be stored, got %+v", got.LatestSuggestion)
+	}
+
+	history := store.History("conn", "")
+	if len(history) != 2 {
+		t.Fatalf("Expected history trimmed to 2 entries, got %d", len(history))
+	}
+	if history[0].Suggestion.Confidence != 0.9 || history[1].Suggestion.Confidence != 0.5 {
+		t.Errorf("Expected the newest entries first, got %+v", history)
+	}
+	if filtered := store.History("conn", "other"); len(filtered) != 0 {
+		t.Errorf("Expected no entries for another query, got %+v", filtered)
+	}
+
+	store.DeleteConnection("conn")
+	if len(store.History("conn", "")) != 0 || len(store.List("conn")) != 0 {
+		t.Errorf("Expected the connection's queries and history to be removed")
+	}
+}
+
+func TestSavedQueryStoreConcurrentAccess(t *testing.T) {
+	store := NewSavedQueryStore(10)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			saved, err := store.Save(types.SavedQuery{ConnectionID: "conn", Query: "SELECT 1"})
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+				return
+			}
+			if _, err := store.RecordSuggestion("conn", saved.ID, saved.Query, types.OptimizationSuggestion{}); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			store.History("conn", saved.ID)
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	for _, query := range store.List("conn") {
+		if seen[query.ID] {
+			t.Errorf("Expected unique IDs, got %s twice", query.ID)
+		}
+		seen[query.ID] = true
+	}
+	if len(seen) != 8 {
+		t.Errorf("Expected 8 saved queries, got %d", len(seen))
+	}
+}
@@ -0,0 +1,364 @@
+package security
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+// ErrAccessControlDenied is returned when the connected user may not read the catalog
+// tables that hold roles and grants.
+var ErrAccessControlDenied = errors.New("insufficient privileges to read roles and grants")
+
+// postgresTablePrivileges is everything GRANT ALL gives on a table.
+var postgresTablePrivileges = []string{"DELETE", "INSERT", "REFERENCES", "SELECT", "TRIGGER", "TRUNCATE", "UPDATE"}
+
+var mysqlGrantPattern = regexp.MustCompile("(?i)^GRANT (.+) ON (\\S+) TO ")
+
+type roleRow struct {
+	name      string
+	superuser bool
+	canLogin  bool
+}
+
+type grantRow struct {
+	grantee   string
+	object    string
+	privilege string
+}
+
+// AnalyzeAccessControl reads roles and table grants from a Postgres or MySQL database
+// and reports superuser accounts, access granted to everyone and all-privilege grants.
+// Other databases return no findings. A user without access to the catalog gets
+// ErrAccessControlDenied.
+func AnalyzeAccessControl(db *sql.DB, dbType string) ([]types.AccessFinding, error) {
+	switch dbType {
+	case "postgres":
+		return analyzePostgresAccess(db)
+	case "mysql":
+		return analyzeMySQLAccess(db)
+	default:
+		return nil, nil
+	}
+}
+
+func analyzePostgresAccess(db *sql.DB) ([]types.AccessFinding, error) {
+	roles, err := queryRoles(db, `SELECT rolname, rolsuper, rolcanlogin FROM pg_roles WHERE rolname NOT LIKE 'pg\_%'`)
+	if err != nil {
+		return nil, accessError(err)
+	}
+
+	rows, err := db.Query(`
+		SELECT grantee, table_schema || '.' || table_name, privilege_type
+		FROM information_schema.role_table_grants
+		WHERE table_schema NOT IN ('pg_catalog', 'information_schema') AND grantor <> grantee`)
+	if err != nil {
+		return nil, accessError(err)
+	}
+	defer rows.Close()
+
+	var grants []grantRow
+	for rows.Next() {
+		var grant grantRow
+		if err := rows.Scan(&grant.grantee, &grant.object, &grant.privilege); err != nil {
+			return nil, fmt.Errorf("failed to scan table grant: %w", err)
+		}
+		grants = append(grants, grant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, accessError(err)
+	}
+
+	findings := superuserFindings(roles)
+	findings = append(findings, postgresGrantFindings(grants)...)
+	return findings, nil
+}
+
+func analyzeMySQLAccess(db *sql.DB) ([]types.AccessFinding, error) {
+	rows, err := db.Query(`SELECT user, host, super_priv = 'Y' FROM mysql.user WHERE user NOT LIKE 'mysql.%'`)
+	if err != nil {
+		return nil, accessError(err)
+	}
+	defer rows.Close()
+
+	var roles []roleRow
+	var accounts []string
+	for rows.Next() {
+		var user, host string
+		var role roleRow
+		if err := rows.Scan(&user, &host, &role.superuser); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		role.name = fmt.Sprintf("'%s'@'%s'", strings.ReplaceAll(user, "'", "''"), strings.ReplaceAll(host, "'", "''"))
+		role.canLogin = true
+		roles = append(roles, role)
+		accounts = append(accounts, role.name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, accessError(err)
+	}
+
+	var grants []grantRow
+	for _, account := range accounts {
+		accountGrants, err := queryMySQLGrants(db, account)
+		if err != nil {
+			return nil, accessError(err)
+		}
+		grants = append(grants, accountGrants...)
+	}
+
+	findings := superuserFindings(roles)
+	findings = append(findings, mysqlGrantFindings(grants)...)
+	return findings, nil
+}
+
+func queryRoles(db *sql.DB, query string) ([]roleRow, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []roleRow
+	for rows.Next() {
+		var role roleRow
+		if err := rows.Scan(&role.name, &role.superuser, &role.canLogin); err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+// queryMySQLGrants turns each "GRANT <privileges> ON <object> TO ..." line from SHOW
+// GRANTS into one row per privilege.
+func queryMySQLGrants(db *sql.DB, account string) ([]grantRow, error) {
+	rows, err := db.Query("SHOW GRANTS FOR " + account)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grants []grantRow
+	for rows.Next() {
+		var statement string
+		if err := rows.Scan(&statement); err != nil {
+			return nil, fmt.Errorf("failed to scan grant: %w", err)
+		}
+		grants = append(grants, parseMySQLGrant(account, statement)...)
+	}
+	return grants, rows.Err()
+}
+
+func parseMySQLGrant(account, statement string) []grantRow {
+	match := mysqlGrantPattern.FindStringSubmatch(statement)
+	if match == nil {
+		return nil
+	}
+
+	object := strings.ReplaceAll(match[2], "`", "")
+	var grants []grantRow
+	for _, privilege := range strings.Split(match[1], ",") {
+		grants = append(grants, grantRow{
+			grantee:   account,
+			object:    object,
+			privilege: strings.ToUpper(strings.TrimSpace(privilege)),
+		})
+	}
+	return grants
+}
+
+func superuserFindings(roles []roleRow) []types.AccessFinding {
+	var findings []types.AccessFinding
+	for _, role := range roles {
+		if !role.superuser {
+			continue
+		}
+		severity := "high"
+		if !role.canLogin {
+			severity = "medium"
+		}
+		findings = append(findings, types.AccessFinding{
+			Type:        types.AccessFindingSuperuser,
+			Severity:    severity,
+			Grantee:     role.name,
+			Description: fmt.Sprintf("Role %s is a superuser and bypasses every permission check", role.name),
+		})
+	}
+	return findings
+}
+
+// postgresGrantFindings flags tables readable or writable by PUBLIC, and roles holding
+// every table privilege, which is what GRANT ALL gives.
+func postgresGrantFindings(grants []grantRow) []types.AccessFinding {
+	var findings []types.AccessFinding
+	for _, group := range groupGrants(grants) {
+		switch {
+		case strings.EqualFold(group.grantee, "PUBLIC"):
+			findings = append(findings, publicAccessFinding(group))
+		case containsAll(group.privileges, postgresTablePrivileges):
+			findings = append(findings, wildcardFinding(group))
+		}
+	}
+	return findings
+}
+
+// mysqlGrantFindings flags ALL PRIVILEGES grants, any grant on every database ("*.*")
+// and grants to the anonymous account, MySQL's equivalent of PUBLIC.
+func mysqlGrantFindings(grants []grantRow) []types.AccessFinding {
+	var findings []types.AccessFinding
+	for _, group := range groupGrants(grants) {
+		switch {
+		case strings.HasPrefix(group.grantee, "''@"):
+			if onlyUsage(group.privileges) {
+				continue
+			}
+			findings = append(findings, publicAccessFinding(group))
+		case containsPrivilege(group.privileges, "ALL PRIVILEGES") || containsPrivilege(group.privileges, "ALL"):
+			findings = append(findings, wildcardFinding(group))
+		case group.object == "*.*" && !onlyUsage(group.privileges):
+			findings = append(findings, wildcardFinding(group))
+		}
+	}
+	return findings
+}
+
+type grantGroup struct {
+	grantee    string
+	object     string
+	privileges []string
+}
+
+// groupGrants collects the privileges each grantee holds on each object, in a stable order.
+func groupGrants(grants []grantRow) []grantGroup {
+	index := make(map[string]int)
+	var groups []grantGroup
+	for _, grant := range grants {
+		key := grant.grantee + "\x00" + grant.object
+		i, exists := index[key]
+		if !exists {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, grantGroup{grantee: grant.grantee, object: grant.object})
+		}
+		groups[i].privileges = append(groups[i].privileges, grant.privilege)
+	}
+	for i := range groups {
+		sort.Strings(groups[i].privileges)
+	}
+	return groups
+}
+
+func publicAccessFinding(group grantGroup) types.AccessFinding {
+	severity := "medium"
+	if !onlyRead(group.privileges) {
+		severity = "high"
+	}
+	return types.AccessFinding{
+		Type:       types.AccessFindingPublic,
+		Severity:   severity,
+		Grantee:    group.grantee,
+		Object:     group.object,
+		Privileges: group.privileges,
+		Description: fmt.Sprintf("Every user can %s on %s",
+			strings.Join(group.privileges, ", "), group.object),
+	}
+}
+
+func wildcardFinding(group grantGroup) types.AccessFinding {
+	severity := "medium"
+	description := fmt.Sprintf("%s holds all privileges on %s", group.grantee, group.object)
+	if group.object == "*.*" {
+		severity = "high"
+		if !containsPrivilege(group.privileges, "ALL PRIVILEGES") && !containsPrivilege(group.privileges, "ALL") {
+			description = fmt.Sprintf("%s holds %s on every database",
+				group.grantee, strings.Join(group.privileges, ", "))
+		}
+	}
+	return types.AccessFinding{
+		Type:        types.AccessFindingWildcard,
+		Severity:    severity,
+		Grantee:     group.grantee,
+		Object:      group.object,
+		Privileges:  group.privileges,
+		Description: description,
+	}
+}
+
+func containsPrivilege(privileges []string, privilege string) bool {
+	for _, held := range privileges {
+		if held == privilege {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAll(privileges, required []string) bool {
+	for _, privilege := range required {
+		if !containsPrivilege(privileges, privilege) {
+			return false
+		}
+	}
+	return true
+}
+
+func onlyRead(privileges []string) bool {
+	for _, privilege := range privileges {
+		if privilege != "SELECT" && privilege != "USAGE" {
+			return false
+		}
+	}
+	return true
+}
+
+func onlyUsage(privileges []string) bool {
+	return len(privileges) == 1 && privileges[0] == "USAGE"
+}
+
+// accessError maps the permission errors Postgres (SQLSTATE 42501) and MySQL (1142,
+// 1044, 1227) return for catalog reads to ErrAccessControlDenied.
+func accessError(err error) error {
+	message := strings.ToLower(err.Error())
+	for _, marker := range []string{"permission denied", "command denied", "access denied", "42501", "1142", "1044", "1227"} {
+		if strings.Contains(message, marker) {
+			return fmt.Errorf("%w: %v", ErrAccessControlDenied, err)
+		}
+	}
+	return fmt.Errorf("failed to read access control: %w", err)
+}
+
+// AccessFindingIssues converts findings into security issues for the security report.
+func AccessFindingIssues(findings []types.AccessFinding) []types.SecurityIssue {
+	issues := make([]types.SecurityIssue, 0, len(findings))
+	for _, finding := range findings {
+		issue := types.SecurityIssue{
+			Type:            "access_control",
+			Severity:        finding.Severity,
+			Description:     finding.Description,
+			AffectedObjects: []string{finding.Grantee},
+		}
+		if finding.Object != "" {
+			issue.AffectedObjects = append(issue.AffectedObjects, finding.Object)
+		}
+
+		switch finding.Type {
+		case types.AccessFindingSuperuser:
+			issue.Title = "Superuser Account"
+			issue.Recommendation = "Use superuser accounts only for administration and connect applications with a role limited to the privileges they need"
+		case types.AccessFindingPublic:
+			issue.Title = "Access Granted To Everyone"
+			issue.Recommendation = "Revoke the privileges from PUBLIC or the anonymous account and grant them to specific roles"
+		case types.AccessFindingWildcard:
+			issue.Title = "All Privileges Granted"
+			issue.Recommendation = "Replace the blanket grant with the specific privileges the role needs, on the objects it uses"
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
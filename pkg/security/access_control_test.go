@@ -0,0 +1,270 @@
+package security
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+// catalogDriver is a database/sql driver answering queries from a script, keyed by a
+// substring of the query, so the catalog readers can run without a real server.
+type catalogDriver struct{}
+
+type catalogScript struct {
+	rows map[string][][]driver.Value
+	errs map[string]error
+}
+
+var (
+	catalogMu      sync.Mutex
+	catalogScripts = make(map[string]*catalogScript)
+)
+
+func init() {
+	sql.Register("catalog-test", catalogDriver{})
+}
+
+func openCatalogDB(t *testing.T, script *catalogScript) *sql.DB {
+	t.Helper()
+	catalogMu.Lock()
+	catalogScripts[t.Name()] = script
+	catalogMu.Unlock()
+
+	db, err := sql.Open("catalog-test", t.Name())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func (catalogDriver) Open(name string) (driver.Conn, error) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	return &catalogConn{script: catalogScripts[name]}, nil
+}
+
+type catalogConn struct {
+	script *catalogScript
+}
+
+func (c *catalogConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *catalogConn) Close() error                        { return nil }
+func (c *catalogConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *catalogConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	for marker, err := range c.script.errs {
+		if strings.Contains(query, marker) {
+			return nil, err
+		}
+	}
+	for marker, rows := range c.script.rows {
+		if strings.Contains(query, marker) {
+			return &catalogRows{rows: rows}, nil
+		}
+	}
+	return &catalogRows{}, nil
+}
+
+type catalogRows struct {
+	rows [][]driver.Value
+}
+
+func (r *catalogRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return []string{"value"}
+	}
+	return make([]string, len(r.rows[0]))
+}
+
+func (r *catalogRows) Close() error { return nil }
+
+func (r *catalogRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestAnalyzeAccessControlPostgres(t *testing.T) {
+	db := openCatalogDB(t, &catalogScript{rows: map[string][][]driver.Value{
+		"pg_roles": {
+			{"postgres", true, true},
+			{"app", false, true},
+			{"admin_group", true, false},
+		},
+		"role_table_grants": {
+			{"PUBLIC", "public.users", "SELECT"},
+			{"PUBLIC", "public.orders", "SELECT"},
+			{"PUBLIC", "public.orders", "UPDATE"},
+			{"etl", "public.events", "DELETE"},
+			{"etl", "public.events", "INSERT"},
+			{"etl", "public.events", "REFERENCES"},
+			{"etl", "public.events", "SELECT"},
+			{"etl", "public.events", "TRIGGER"},
+			{"etl", "public.events", "TRUNCATE"},
+			{"etl", "public.events", "UPDATE"},
+			{"app", "public.users", "SELECT"},
+		},
+	}})
+
+	findings, err := AnalyzeAccessControl(db, "postgres")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := []types.AccessFinding{
+		{Type: types.AccessFindingSuperuser, Severity: "high", Grantee: "postgres"},
+		{Type: types.AccessFindingSuperuser, Severity: "medium", Grantee: "admin_group"},
+		{Type: types.AccessFindingPublic, Severity: "medium", Grantee: "PUBLIC", Object: "public.users"},
+		{Type: types.AccessFindingPublic, Severity: "high", Grantee: "PUBLIC", Object: "public.orders"},
+		{Type: types.AccessFindingWildcard, Severity: "medium", Grantee: "etl", Object: "public.events"},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("Expected %d findings, got %+v", len(want), findings)
+	}
+	for i, finding := range findings {
+		if finding.Type != want[i].Type || finding.Severity != want[i].Severity ||
+			finding.Grantee != want[i].Grantee || finding.Object != want[i].Object {
+			t.Errorf("Expected %+v, got %+v", want[i], finding)
+		}
+	}
+}
+
+func TestAnalyzeAccessControlMySQL(t *testing.T) {
+	db := openCatalogDB(t, &catalogScript{rows: map[string][][]driver.Value{
+		"mysql.user": {
+			{"root", "localhost", true},
+			{"app", "%", false},
+			{"", "localhost", false},
+		},
+		"SHOW GRANTS FOR 'root'@'localhost'": {
+			{"GRANT ALL PRIVILEGES ON *.* TO `root`@`localhost` WITH GRANT OPTION"},
+		},
+		"SHOW GRANTS FOR 'app'@'%'": {
+			{"GRANT USAGE ON *.* TO `app`@`%`"},
+			{"GRANT SELECT, INSERT ON `shop`.* TO `app`@`%`"},
+		},
+		"SHOW GRANTS FOR ''@'localhost'": {
+			{"GRANT SELECT ON `test`.* TO ``@`localhost`"},
+		},
+	}})
+
+	findings, err := AnalyzeAccessControl(db, "mysql")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var got []string
+	for _, finding := range findings {
+		got = append(got, finding.Type+" "+finding.Grantee+" "+finding.Object)
+	}
+	want := []string{
+		types.AccessFindingSuperuser + " 'root'@'localhost' ",
+		types.AccessFindingWildcard + " 'root'@'localhost' *.*",
+		types.AccessFindingPublic + " ''@'localhost' test.*",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestAnalyzeAccessControlPermissionDenied(t *testing.T) {
+	db := openCatalogDB(t, &catalogScript{errs: map[string]error{
+		"pg_roles": errors.New("pq: permission denied for table pg_authid"),
+	}})
+
+	if _, err := AnalyzeAccessControl(db, "postgres"); !errors.Is(err, ErrAccessControlDenied) {
+		t.Errorf("Expected ErrAccessControlDenied, got %v", err)
+	}
+}
+
+func TestAnalyzeAccessControlOtherDatabases(t *testing.T) {
+	findings, err := AnalyzeAccessControl(nil, "sqlite3")
+	if err != nil || findings != nil {
+		t.Errorf("Expected no findings and no error, got %v, %v", findings, err)
+	}
+}
+
+func TestParseMySQLGrant(t *testing.T) {
+	grants := parseMySQLGrant("'app'@'%'", "GRANT SELECT, insert ON `shop`.`orders` TO `app`@`%`")
+	want := []grantRow{
+		{grantee: "'app'@'%'", object: "shop.orders", privilege: "SELECT"},
+		{grantee: "'app'@'%'", object: "shop.orders", privilege: "INSERT"},
+	}
+	if !reflect.DeepEqual(grants, want) {
+		t.Errorf("Expected %+v, got %+v", want, grants)
+	}
+
+	if grants := parseMySQLGrant("'app'@'%'", "GRANT PROXY ON ''@'' TO 'app'@'%'"); len(grants) != 1 {
+		t.Errorf("Expected 1 grant, got %+v", grants)
+	}
+	if grants := parseMySQLGrant("'app'@'%'", "REVOKE ALL"); grants != nil {
+		t.Errorf("Expected no grants, got %+v", grants)
+	}
+}
+
+func TestMySQLGrantFindingsOnEveryDatabase(t *testing.T) {
+	findings := mysqlGrantFindings([]grantRow{
+		{grantee: "'ops'@'%'", object: "*.*", privilege: "SELECT"},
+		{grantee: "'ops'@'%'", object: "*.*", privilege: "RELOAD"},
+	})
+	if len(findings) != 1 {
+		t.Fatalf("Expected 1 finding, got %+v", findings)
+	}
+	if want := "'ops'@'%' holds RELOAD, SELECT on every database"; findings[0].Description != want {
+		t.Errorf("Expected %q, got %q", want, findings[0].Description)
+	}
+	if findings[0].Severity != "high" {
+		t.Errorf("Expected high, got %s", findings[0].Severity)
+	}
+}
+
+func TestAccessError(t *testing.T) {
+	for _, message := range []string{
+		"Error 1142: SELECT command denied to user",
+		"pq: permission denied for relation pg_authid",
+		"ERROR: 42501",
+	} {
+		if err := accessError(errors.New(message)); !errors.Is(err, ErrAccessControlDenied) {
+			t.Errorf("Expected ErrAccessControlDenied for %q, got %v", message, err)
+		}
+	}
+
+	original := errors.New("connection reset")
+	err := accessError(original)
+	if errors.Is(err, ErrAccessControlDenied) || !errors.Is(err, original) {
+		t.Errorf("Expected a wrapped error that is not a denial, got %v", err)
+	}
+}
+
+func TestAccessFindingIssues(t *testing.T) {
+	issues := AccessFindingIssues([]types.AccessFinding{
+		{Type: types.AccessFindingSuperuser, Severity: "high", Grantee: "postgres"},
+		{Type: types.AccessFindingPublic, Severity: "medium", Grantee: "PUBLIC", Object: "public.users"},
+		{Type: types.AccessFindingWildcard, Severity: "medium", Grantee: "etl", Object: "public.events"},
+	})
+
+	titles := []string{"Superuser Account", "Access Granted To Everyone", "All Privileges Granted"}
+	for i, issue := range issues {
+		if issue.Title != titles[i] || issue.Type != "access_control" || issue.Recommendation == "" {
+			t.Errorf("Expected a %q access_control issue, got %+v", titles[i], issue)
+		}
+	}
+	if want := []string{"PUBLIC", "public.users"}; !reflect.DeepEqual(issues[1].AffectedObjects, want) {
+		t.Errorf("Expected %v, got %v", want, issues[1].AffectedObjects)
+	}
+	if want := []string{"postgres"}; !reflect.DeepEqual(issues[0].AffectedObjects, want) {
+		t.Errorf("Expected %v, got %v", want, issues[0].AffectedObjects)
+	}
+}
@@ -9,44 +9,131 @@ import (
 )
 
 type SecurityAnalyzerImpl struct {
-	analyzer interfaces.DatabaseAnalyzer
+	analyzer    interfaces.DatabaseAnalyzer
+	maxScanRows int64
+	pii         *piiClassifier
 }
 
 func NewSecurityAnalyzer(analyzer interfaces.DatabaseAnalyzer) interfaces.SecurityAnalyzer {
+	return NewSecurityAnalyzerWithLimit(analyzer, types.DefaultMaxSecurityScanRows)
+}
+
+func NewSecurityAnalyzerWithLimit(analyzer interfaces.DatabaseAnalyzer, maxScanRows int64) interfaces.SecurityAnalyzer {
+	pii, _ := newPIIClassifier(types.SecuritySettings{})
 	return &SecurityAnalyzerImpl{
-		analyzer: analyzer,
+		analyzer:    analyzer,
+		maxScanRows: maxScanRows,
+		pii:         pii,
 	}
 }
 
+// NewSecurityAnalyzerWithSettings applies the scan limit and PII name heuristics from
+// settings. It fails if a configured name pattern does not compile.
+func NewSecurityAnalyzerWithSettings(analyzer interfaces.DatabaseAnalyzer, settings types.SecuritySettings) (interfaces.SecurityAnalyzer, error) {
+	pii, err := newPIIClassifier(settings)
+	if err != nil {
+		return nil, err
+	}
+	return &SecurityAnalyzerImpl{
+		analyzer:    analyzer,
+		maxScanRows: settings.MaxSecurityScanRows,
+		pii:         pii,
+	}, nil
+}
+
 func (sa *SecurityAnalyzerImpl) AnalyzeSecurity() ([]types.SecurityIssue, error) {
 	var issues []types.SecurityIssue
 
-	tables, err := sa.analyzer.AnalyzeTables()
+	tables, sampled, err := sa.collectTables()
 	if err != nil {
 		return nil, fmt.Errorf("failed to analyze tables: %w", err)
 	}
 
+	issues = append(issues, sampled...)
+
 	issues = append(issues, sa.checkPIIColumns(tables)...)
 
 	issues = append(issues, sa.checkUnindexedTables(tables)...)
 
 	issues = append(issues, sa.DetectVulnerabilities(tables)...)
 
+	issues = append(issues, sa.checkCredentialColumns(tables)...)
+
 	return issues, nil
 }
 
+func (sa *SecurityAnalyzerImpl) collectTables() ([]types.TableInfo, []types.SecurityIssue, error) {
+	tableNames, err := sa.analyzer.GetTableNames()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tables []types.TableInfo
+	var sampled []types.SecurityIssue
+
+	for _, tableName := range tableNames {
+		rowCount, err := sa.analyzer.GetRowCount(tableName)
+		if err != nil || sa.maxScanRows <= 0 || rowCount <= sa.maxScanRows {
+			table, err := sa.analyzer.AnalyzeTable(tableName)
+			if err != nil {
+				continue
+			}
+			tables = append(tables, table)
+			continue
+		}
+
+		table, err := sa.schemaOnlyTable(tableName, rowCount)
+		if err != nil {
+			continue
+		}
+		tables = append(tables, table)
+
+		sampled = append(sampled, types.SecurityIssue{
+			Type:     "scan_coverage",
+			Severity: "info",
+			Title:    "Security Scan Sampled",
+			Description: fmt.Sprintf("Table '%s' has %d rows, above the scan limit of %d; data-based checks were skipped and only schema checks ran",
+				tableName, rowCount, sa.maxScanRows),
+			Recommendation:  "Raise max_security_scan_rows or scan this table during a maintenance window for full coverage",
+			AffectedObjects: []string{tableName},
+		})
+	}
+
+	return tables, sampled, nil
+}
+
+func (sa *SecurityAnalyzerImpl) schemaOnlyTable(tableName string, rowCount int64) (types.TableInfo, error) {
+	columns, err := sa.analyzer.AnalyzeColumns(tableName)
+	if err != nil {
+		return types.TableInfo{}, err
+	}
+	for i := range columns {
+		columns[i].DataProfile = types.DataProfile{}
+	}
+
+	indexes, _ := sa.analyzer.GetIndexes(tableName)
+
+	return types.TableInfo{
+		Name:     tableName,
+		RowCount: rowCount,
+		Columns:  columns,
+		Indexes:  indexes,
+	}, nil
+}
+
 func (sa *SecurityAnalyzerImpl) checkPIIColumns(tables []types.TableInfo) []types.SecurityIssue {
 	var issues []types.SecurityIssue
 
 	for _, table := range tables {
 		for _, column := range table.Columns {
-			if sa.IsPotentialPII(column.Name, column.DataProfile.Pattern) {
+			category, confidence := sa.ClassifyPII(column.Name, column.DataProfile.Pattern)
+			if confidence >= sa.pii.minConfidence {
 				issue := types.SecurityIssue{
 					Type:     "privacy",
 					Severity: "high",
 					Title:    "Potential PII Data",
-					Description: fmt.Sprintf("Column '%s.%s' may contain personally identifiable information",
-						table.Name, column.Name),
+					Description: fmt.Sprintf("Column '%s.%s' may contain personally identifiable information (%s, %.0f%% confidence)",
+						table.Name, column.Name, category, confidence*100),
 					Recommendation:  "Consider encryption, masking, or access controls",
 					AffectedObjects: []string{fmt.Sprintf("%s.%s", table.Name, column.Name)},
 				}
@@ -80,16 +167,14 @@ func (sa *SecurityAnalyzerImpl) checkUnindexedTables(tables []types.TableInfo) [
 }
 
 func (sa *SecurityAnalyzerImpl) IsPotentialPII(columnName, pattern string) bool {
-	piiPatterns := []string{"email", "phone", "ssn", "social", "address", "name", "first_name", "last_name", "password"}
-	columnLower := strings.ToLower(columnName)
-
-	for _, piiPattern := range piiPatterns {
-		if strings.Contains(columnLower, piiPattern) {
-			return true
-		}
-	}
+	_, confidence := sa.ClassifyPII(columnName, pattern)
+	return confidence >= sa.pii.minConfidence
+}
 
-	return pattern == "Email pattern" || pattern == "Phone number pattern"
+// ClassifyPII returns the likely PII category of a column and a confidence between 0
+// and 1, from its name and the pattern detected in its values.
+func (sa *SecurityAnalyzerImpl) ClassifyPII(columnName, pattern string) (string, float64) {
+	return sa.pii.classify(columnName, pattern)
 }
 
 func (sa *SecurityAnalyzerImpl) DetectVulnerabilities(tables []types.TableInfo) []types.SecurityIssue {
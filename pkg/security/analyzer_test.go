@@ -0,0 +1,148 @@
+package security
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+// mockDatabaseAnalyzer serves tables from memory and records which were fully analyzed.
+type mockDatabaseAnalyzer struct {
+	tables   map[string]types.TableInfo
+	order    []string
+	analyzed []string
+	err      error
+}
+
+func (m *mockDatabaseAnalyzer) AnalyzeTables() ([]types.TableInfo, error) { return nil, nil }
+
+func (m *mockDatabaseAnalyzer) AnalyzeTable(tableName string) (types.TableInfo, error) {
+	m.analyzed = append(m.analyzed, tableName)
+	return m.tables[tableName], nil
+}
+
+func (m *mockDatabaseAnalyzer) AnalyzeColumns(tableName string) ([]types.ColumnInfo, error) {
+	columns := make([]types.ColumnInfo, len(m.tables[tableName].Columns))
+	copy(columns, m.tables[tableName].Columns)
+	return columns, nil
+}
+
+func (m *mockDatabaseAnalyzer) AnalyzeColumnData(tableName, columnName, dataType string) (types.DataProfile, error) {
+	return types.DataProfile{}, nil
+}
+
+func (m *mockDatabaseAnalyzer) GetTableNames() ([]string, error) { return m.order, m.err }
+
+func (m *mockDatabaseAnalyzer) GetRowCount(tableName string) (int64, error) {
+	return m.tables[tableName].RowCount, nil
+}
+
+func (m *mockDatabaseAnalyzer) GetUniqueValueCount(tableName, columnName string) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockDatabaseAnalyzer) GetNullCount(tableName, columnName string) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockDatabaseAnalyzer) GetIndexes(tableName string) ([]types.IndexInfo, error) {
+	return m.tables[tableName].Indexes, nil
+}
+
+func (m *mockDatabaseAnalyzer) GetConstraints(tableName string) ([]types.Constraint, error) {
+	return nil, nil
+}
+
+func (m *mockDatabaseAnalyzer) GetRelationships(tableName string) ([]types.Relationship, error) {
+	return nil, nil
+}
+
+func (m *mockDatabaseAnalyzer) GetTableSize(tableName string) (string, error) { return "", nil }
+
+func (m *mockDatabaseAnalyzer) CalculateDataQuality(tableName, columnName string) float64 {
+	return 1
+}
+
+func newMockDatabaseAnalyzer() *mockDatabaseAnalyzer {
+	return &mockDatabaseAnalyzer{
+		order: []string{"users", "events"},
+		tables: map[string]types.TableInfo{
+			"users": {
+				Name:     "users",
+				RowCount: 10,
+				Columns: []types.ColumnInfo{
+					{Name: "email", DataProfile: types.DataProfile{Pattern: "Email pattern"}},
+					{Name: "password", MaxLength: 16, DataProfile: types.DataProfile{SampleData: []string{"hunter2"}}},
+				},
+			},
+			"events": {
+				Name:     "events",
+				RowCount: 5000,
+				Columns: []types.ColumnInfo{
+					{Name: "payload", DataProfile: types.DataProfile{SampleData: []string{"{}"}}},
+				},
+			},
+		},
+	}
+}
+
+func securityIssueTitles(issues []types.SecurityIssue) map[string][]string {
+	titles := make(map[string][]string)
+	for _, issue := range issues {
+		titles[issue.Title] = append(titles[issue.Title], issue.AffectedObjects...)
+	}
+	return titles
+}
+
+func TestAnalyzeSecurity(t *testing.T) {
+	mock := newMockDatabaseAnalyzer()
+	issues, err := NewSecurityAnalyzer(mock).AnalyzeSecurity()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	titles := securityIssueTitles(issues)
+	for _, title := range []string{
+		"Potential PII Data",
+		"Unindexed Large Table",
+		"Sensitive Table Without Proper Indexing",
+		"Potential Plain Text Password Storage",
+		"Plaintext Credential Values",
+	} {
+		if len(titles[title]) == 0 {
+			t.Errorf("Expected a %q issue, got %v", title, titles)
+		}
+	}
+	if _, sampled := titles["Security Scan Sampled"]; sampled {
+		t.Errorf("Expected every table to be scanned under the default limit")
+	}
+}
+
+func TestAnalyzeSecuritySamplesLargeTables(t *testing.T) {
+	mock := newMockDatabaseAnalyzer()
+	issues, err := NewSecurityAnalyzerWithLimit(mock, 100).AnalyzeSecurity()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(mock.analyzed) != 1 || mock.analyzed[0] != "users" {
+		t.Errorf("Expected only users to be fully analyzed, got %v", mock.analyzed)
+	}
+	titles := securityIssueTitles(issues)
+	if objects := titles["Security Scan Sampled"]; len(objects) != 1 || objects[0] != "events" {
+		t.Errorf("Expected events to be reported as sampled, got %v", objects)
+	}
+	if len(titles["Unindexed Large Table"]) != 1 {
+		t.Errorf("Expected schema checks to still run on events")
+	}
+}
+
+func TestAnalyzeSecurityTableNamesError(t *testing.T) {
+	mock := newMockDatabaseAnalyzer()
+	mock.err = errors.New("connection refused")
+
+	if _, err := NewSecurityAnalyzer(mock).AnalyzeSecurity(); !errors.Is(err, mock.err) {
+		t.Errorf("Expected the analyzer's error, got %v", err)
+	}
+}
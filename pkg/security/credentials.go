@@ -0,0 +1,206 @@
+package security
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+const (
+	maxCredentialSamples    = 20
+	minHashedValueLength    = 20
+	plaintextPrintableRatio = 0.9
+)
+
+var (
+	credentialIndicators = []string{"password", "passwd", "pwd", "secret", "api_key", "apikey", "access_key", "private_key", "token"}
+
+	hashPrefixes = []string{"$2a$", "$2b$", "$2y$", "$argon2", "$pbkdf2", "$scrypt", "$1$", "$5$", "$6$", "pbkdf2_", "bcrypt$", "argon2$", "sha256$", "sha512$"}
+
+	hexDigestPattern = regexp.MustCompile(`^[a-fA-F0-9]{32}$|^[a-fA-F0-9]{40}$|^[a-fA-F0-9]{64}$|^[a-fA-F0-9]{128}$`)
+
+	weakDefaultValues = []string{"password", "changeme", "admin", "secret", "default", "123456", "root", "test"}
+
+	privilegeColumns    = []string{"is_admin", "admin", "is_superuser", "superuser", "is_staff", "can_write", "can_delete"}
+	roleColumns         = []string{"role", "roles", "permission", "permissions", "access_level"}
+	privilegedRoleNames = []string{"admin", "administrator", "superuser", "root", "owner", "all", "*"}
+)
+
+func (sa *SecurityAnalyzerImpl) checkCredentialColumns(tables []types.TableInfo) []types.SecurityIssue {
+	var issues []types.SecurityIssue
+
+	for _, table := range tables {
+		for _, column := range table.Columns {
+			object := fmt.Sprintf("%s.%s", table.Name, column.Name)
+
+			if isCredentialColumn(column.Name) {
+				if samples := plaintextSamples(column.DataProfile.SampleData); samples > 0 {
+					issues = append(issues, types.SecurityIssue{
+						Type:     "encryption",
+						Severity: "critical",
+						Title:    "Plaintext Credential Values",
+						Description: fmt.Sprintf("Column '%s' holds %d sampled value(s) that do not look hashed or encrypted",
+							object, samples),
+						Recommendation:  "Hash passwords with bcrypt or argon2 and store API keys and secrets encrypted or in a secrets manager",
+						AffectedObjects: []string{object},
+					})
+				}
+
+				if defaultValue := defaultString(column.DefaultValue); defaultValue != "" {
+					issues = append(issues, types.SecurityIssue{
+						Type:     "encryption",
+						Severity: "high",
+						Title:    "Credential Column With Default Value",
+						Description: fmt.Sprintf("Column '%s' has a default value, so every new row starts with the same known secret",
+							object),
+						Recommendation:  "Remove the default and require a generated credential for each row",
+						AffectedObjects: []string{object},
+					})
+				}
+				continue
+			}
+
+			if defaultValue := defaultString(column.DefaultValue); isWeakDefault(defaultValue) {
+				issues = append(issues, types.SecurityIssue{
+					Type:     "encryption",
+					Severity: "medium",
+					Title:    "Weak Default Value",
+					Description: fmt.Sprintf("Column '%s' defaults to '%s', which looks like a well-known secret",
+						object, defaultValue),
+					Recommendation:  "Replace the default with a non-sensitive value or drop it",
+					AffectedObjects: []string{object},
+				})
+			}
+
+			if isPermissiveDefault(column) {
+				issues = append(issues, types.SecurityIssue{
+					Type:     "access_control",
+					Severity: "high",
+					Title:    "Permissive Default Privilege",
+					Description: fmt.Sprintf("Column '%s' grants elevated privileges by default (default: %v)",
+						object, column.DefaultValue),
+					Recommendation:  "Default privilege columns to the least-privileged value and grant access explicitly",
+					AffectedObjects: []string{object},
+				})
+			}
+		}
+	}
+
+	return issues
+}
+
+func isCredentialColumn(columnName string) bool {
+	columnLower := strings.ToLower(columnName)
+	for _, indicator := range credentialIndicators {
+		if strings.Contains(columnLower, indicator) {
+			return true
+		}
+	}
+	return false
+}
+
+func plaintextSamples(samples []string) int {
+	if len(samples) > maxCredentialSamples {
+		samples = samples[:maxCredentialSamples]
+	}
+
+	count := 0
+	for _, sample := range samples {
+		if looksUnhashed(sample) {
+			count++
+		}
+	}
+	return count
+}
+
+func looksUnhashed(value string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.EqualFold(value, "null") {
+		return false
+	}
+
+	for _, prefix := range hashPrefixes {
+		if strings.HasPrefix(strings.ToLower(value), prefix) {
+			return false
+		}
+	}
+	if hexDigestPattern.MatchString(value) {
+		return false
+	}
+
+	if len(value) >= minHashedValueLength {
+		return false
+	}
+
+	return printableRatio(value) >= plaintextPrintableRatio
+}
+
+func printableRatio(value string) float64 {
+	printable := 0
+	for i := 0; i < len(value); i++ {
+		if value[i] >= 0x20 && value[i] <= 0x7e {
+			printable++
+		}
+	}
+	return float64(printable) / float64(len(value))
+}
+
+func defaultString(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+
+	var str string
+	switch v := value.(type) {
+	case string:
+		str = v
+	case []byte:
+		str = string(v)
+	default:
+		str = fmt.Sprint(v)
+	}
+
+	str = strings.Trim(strings.TrimSpace(str), "'\"")
+	if strings.EqualFold(str, "null") {
+		return ""
+	}
+	return str
+}
+
+func isWeakDefault(value string) bool {
+	valueLower := strings.ToLower(value)
+	for _, weak := range weakDefaultValues {
+		if valueLower == weak {
+			return true
+		}
+	}
+	return false
+}
+
+func isPermissiveDefault(column types.ColumnInfo) bool {
+	columnLower := strings.ToLower(column.Name)
+	defaultValue := strings.ToLower(defaultString(column.DefaultValue))
+	if defaultValue == "" {
+		return false
+	}
+
+	for _, name := range privilegeColumns {
+		if columnLower == name {
+			return defaultValue == "true" || defaultValue == "1" || defaultValue == "b'1'" || defaultValue == "yes"
+		}
+	}
+
+	for _, name := range roleColumns {
+		if columnLower == name {
+			for _, role := range privilegedRoleNames {
+				if defaultValue == role {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,143 @@
+package security
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+func TestIsCredentialColumn(t *testing.T) {
+	for _, name := range []string{"password", "PasswordHash", "api_key", "refresh_token", "client_secret"} {
+		if !isCredentialColumn(name) {
+			t.Errorf("Expected %s to be a credential column", name)
+		}
+	}
+	for _, name := range []string{"email", "username", "created_at"} {
+		if isCredentialColumn(name) {
+			t.Errorf("Expected %s not to be a credential column", name)
+		}
+	}
+}
+
+func TestLooksUnhashed(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"hunter2", true},
+		{"", false},
+		{"NULL", false},
+		{"$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy", false},
+		{"$argon2id$v=19$m=65536", false},
+		{"5f4dcc3b5aa765d61d8327deb882cf99", false},
+		{"this-is-a-long-random-looking-value", false},
+		{"\x00\x01\x02\x03", false},
+	}
+	for _, tt := range tests {
+		if got := looksUnhashed(tt.value); got != tt.want {
+			t.Errorf("Expected %v for %q, got %v", tt.want, tt.value, got)
+		}
+	}
+}
+
+func TestPlaintextSamplesCapsSamples(t *testing.T) {
+	samples := make([]string, maxCredentialSamples+5)
+	for i := range samples {
+		samples[i] = "hunter2"
+	}
+	if got := plaintextSamples(samples); got != maxCredentialSamples {
+		t.Errorf("Expected %d, got %d", maxCredentialSamples, got)
+	}
+}
+
+func TestDefaultString(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, ""},
+		{"'changeme'", "changeme"},
+		{[]byte(`"admin"`), "admin"},
+		{"NULL", ""},
+		{true, "true"},
+		{1, "1"},
+	}
+	for _, tt := range tests {
+		if got := defaultString(tt.value); got != tt.want {
+			t.Errorf("Expected %q for %#v, got %q", tt.want, tt.value, got)
+		}
+	}
+}
+
+func TestIsPermissiveDefault(t *testing.T) {
+	tests := []struct {
+		column types.ColumnInfo
+		want   bool
+	}{
+		{types.ColumnInfo{Name: "is_admin", DefaultValue: "true"}, true},
+		{types.ColumnInfo{Name: "IS_ADMIN", DefaultValue: 1}, true},
+		{types.ColumnInfo{Name: "is_admin", DefaultValue: "false"}, false},
+		{types.ColumnInfo{Name: "role", DefaultValue: "'admin'"}, true},
+		{types.ColumnInfo{Name: "role", DefaultValue: "'viewer'"}, false},
+		{types.ColumnInfo{Name: "role"}, false},
+		{types.ColumnInfo{Name: "enabled", DefaultValue: "true"}, false},
+	}
+	for _, tt := range tests {
+		if got := isPermissiveDefault(tt.column); got != tt.want {
+			t.Errorf("Expected %v for %s default %v, got %v", tt.want, tt.column.Name, tt.column.DefaultValue, got)
+		}
+	}
+}
+
+func TestCheckCredentialColumns(t *testing.T) {
+	sa := NewSecurityAnalyzer(nil).(*SecurityAnalyzerImpl)
+	tables := []types.TableInfo{{
+		Name: "users",
+		Columns: []types.ColumnInfo{
+			{Name: "password", DataProfile: types.DataProfile{SampleData: []string{"hunter2", "$2b$12$abcdefghijklmnopqrstuv"}}},
+			{Name: "api_key", DefaultValue: "'abc'"},
+			{Name: "nickname", DefaultValue: "'changeme'"},
+			{Name: "is_superuser", DefaultValue: "1"},
+			{Name: "email"},
+		},
+	}}
+
+	titles := make(map[string]string)
+	for _, issue := range sa.checkCredentialColumns(tables) {
+		titles[issue.Title] = issue.AffectedObjects[0]
+	}
+
+	want := map[string]string{
+		"Plaintext Credential Values":          "users.password",
+		"Credential Column With Default Value": "users.api_key",
+		"Weak Default Value":                   "users.nickname",
+		"Permissive Default Privilege":         "users.is_superuser",
+	}
+	if len(titles) != len(want) {
+		t.Errorf("Expected %d issues, got %v", len(want), titles)
+	}
+	for title, object := range want {
+		if titles[title] != object {
+			t.Errorf("Expected %q on %s, got %q", title, object, titles[title])
+		}
+	}
+}
+
+func TestCheckCredentialColumnsCountsPlaintextSamples(t *testing.T) {
+	sa := NewSecurityAnalyzer(nil).(*SecurityAnalyzerImpl)
+	tables := []types.TableInfo{{
+		Name: "users",
+		Columns: []types.ColumnInfo{
+			{Name: "password", DataProfile: types.DataProfile{SampleData: []string{"hunter2", "letmein"}}},
+		},
+	}}
+
+	issues := sa.checkCredentialColumns(tables)
+	if len(issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d", len(issues))
+	}
+	if !strings.Contains(issues[0].Description, "holds 2 sampled value(s)") {
+		t.Errorf("Expected 2 plaintext samples in %q", issues[0].Description)
+	}
+}
@@ -0,0 +1,163 @@
+package security
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+const (
+	PIICategoryEmail      = "email"
+	PIICategoryPhone      = "phone"
+	PIICategoryNationalID = "national_id"
+	PIICategoryAddress    = "address"
+	PIICategoryName       = "name"
+	PIICategoryBirthDate  = "birth_date"
+	PIICategoryCredential = "credential"
+
+	exactNameConfidence    = 0.9
+	patternNameConfidence  = 0.8
+	partialNameConfidence  = 0.6
+	valuePatternConfidence = 0.9
+)
+
+var (
+	defaultPIINameCategories = map[string]string{
+		"email":       PIICategoryEmail,
+		"e_mail":      PIICategoryEmail,
+		"phone":       PIICategoryPhone,
+		"mobile":      PIICategoryPhone,
+		"ssn":         PIICategoryNationalID,
+		"social":      PIICategoryNationalID,
+		"passport":    PIICategoryNationalID,
+		"national_id": PIICategoryNationalID,
+		"tax_id":      PIICategoryNationalID,
+		"address":     PIICategoryAddress,
+		"postcode":    PIICategoryAddress,
+		"zip_code":    PIICategoryAddress,
+		"name":        PIICategoryName,
+		"first_name":  PIICategoryName,
+		"last_name":   PIICategoryName,
+		"surname":     PIICategoryName,
+		"birth":       PIICategoryBirthDate,
+		"dob":         PIICategoryBirthDate,
+		"password":    PIICategoryCredential,
+	}
+
+	valuePatternCategories = map[string]string{
+		"Email pattern":        PIICategoryEmail,
+		"Phone number pattern": PIICategoryPhone,
+	}
+)
+
+type piiNamePattern struct {
+	pattern  *regexp.Regexp
+	category string
+}
+
+// piiClassifier scores how likely a column holds PII from its name, using a term
+// dictionary and regular expressions, and from the pattern seen in its values.
+type piiClassifier struct {
+	terms         map[string]string
+	patterns      []piiNamePattern
+	minConfidence float64
+}
+
+// newPIIClassifier extends the built-in dictionary with the configured PII patterns,
+// name categories and name patterns. Name patterns match case-insensitively.
+func newPIIClassifier(settings types.SecuritySettings) (*piiClassifier, error) {
+	pc := &piiClassifier{
+		terms:         make(map[string]string, len(defaultPIINameCategories)),
+		minConfidence: settings.PIIMinConfidence,
+	}
+	if pc.minConfidence <= 0 {
+		pc.minConfidence = types.DefaultPIIMinConfidence
+	}
+
+	for term, category := range defaultPIINameCategories {
+		pc.terms[term] = category
+	}
+	for _, term := range settings.PIIPatterns {
+		term = strings.ToLower(strings.TrimSpace(term))
+		if _, exists := pc.terms[term]; term != "" && !exists {
+			pc.terms[term] = term
+		}
+	}
+	for term, category := range settings.PIINameCategories {
+		term = strings.ToLower(strings.TrimSpace(term))
+		if term == "" || category == "" {
+			continue
+		}
+		pc.terms[term] = category
+	}
+
+	for _, namePattern := range settings.PIINamePatterns {
+		if namePattern.Category == "" {
+			return nil, fmt.Errorf("pii name pattern %q needs a category", namePattern.Pattern)
+		}
+		pattern, err := regexp.Compile("(?i)" + namePattern.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pii name pattern %q: %w", namePattern.Pattern, err)
+		}
+		pc.patterns = append(pc.patterns, piiNamePattern{pattern: pattern, category: namePattern.Category})
+	}
+
+	return pc, nil
+}
+
+// nameMatch prefers a term equal to the whole name, then a name pattern, then the
+// longest term contained in the name.
+func (pc *piiClassifier) nameMatch(columnName string) (string, float64) {
+	name := strings.ToLower(strings.TrimSpace(columnName))
+	if name == "" {
+		return "", 0
+	}
+
+	if category, exists := pc.terms[name]; exists {
+		return category, exactNameConfidence
+	}
+
+	for _, namePattern := range pc.patterns {
+		if namePattern.pattern.MatchString(name) {
+			return namePattern.category, patternNameConfidence
+		}
+	}
+
+	best, category := "", ""
+	for term, termCategory := range pc.terms {
+		if !strings.Contains(name, term) {
+			continue
+		}
+		if len(term) > len(best) || (len(term) == len(best) && term < best) {
+			best, category = term, termCategory
+		}
+	}
+	if category != "" {
+		return category, partialNameConfidence
+	}
+	return "", 0
+}
+
+// classify combines the name and value evidence. When both point at the same category
+// they reinforce each other; when they disagree the stronger one wins.
+func (pc *piiClassifier) classify(columnName, pattern string) (string, float64) {
+	nameCategory, nameConfidence := pc.nameMatch(columnName)
+
+	valueCategory, valueConfidence := valuePatternCategories[pattern], 0.0
+	if valueCategory != "" {
+		valueConfidence = valuePatternConfidence
+	}
+
+	switch {
+	case nameCategory == "":
+		return valueCategory, valueConfidence
+	case valueCategory == "" || valueCategory == nameCategory:
+		return nameCategory, 1 - (1-nameConfidence)*(1-valueConfidence)
+	case valueConfidence >= nameConfidence:
+		return valueCategory, valueConfidence
+	default:
+		return nameCategory, nameConfidence
+	}
+}
@@ -0,0 +1,133 @@
+package security
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cherry-pick/pkg/types"
+)
+
+func TestPIIClassifierNameMatch(t *testing.T) {
+	pc, err := newPIIClassifier(types.SecuritySettings{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		column     string
+		category   string
+		confidence float64
+	}{
+		{"email", PIICategoryEmail, exactNameConfidence},
+		{" Phone ", PIICategoryPhone, exactNameConfidence},
+		{"customer_first_name", PIICategoryName, partialNameConfidence},
+		{"billing_address_line1", PIICategoryAddress, partialNameConfidence},
+		{"order_total", "", 0},
+		{"", "", 0},
+	}
+	for _, tt := range tests {
+		category, confidence := pc.nameMatch(tt.column)
+		if category != tt.category || confidence != tt.confidence {
+			t.Errorf("Expected %q at %v for %q, got %q at %v", tt.category, tt.confidence, tt.column, category, confidence)
+		}
+	}
+}
+
+func TestPIIClassifierPrefersLongestTerm(t *testing.T) {
+	pc, _ := newPIIClassifier(types.SecuritySettings{})
+
+	// "last_name" and "name" both match; the longer term decides.
+	if category, _ := pc.nameMatch("customer_last_name"); category != PIICategoryName {
+		t.Errorf("Expected %s, got %s", PIICategoryName, category)
+	}
+	// "birth" is longer than "name" in "birth_name_date".
+	if category, _ := pc.nameMatch("birth_name_date"); category != PIICategoryBirthDate {
+		t.Errorf("Expected %s, got %s", PIICategoryBirthDate, category)
+	}
+}
+
+func TestPIIClassifierSettings(t *testing.T) {
+	pc, err := newPIIClassifier(types.SecuritySettings{
+		PIIPatterns:       []string{" IBAN ", "email"},
+		PIINameCategories: map[string]string{"correo": PIICategoryEmail, "": "ignored"},
+		PIINamePatterns:   []types.PIINamePattern{{Pattern: `^tel_\d+$`, Category: PIICategoryPhone}},
+		PIIMinConfidence:  0.7,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if pc.minConfidence != 0.7 {
+		t.Errorf("Expected min confidence 0.7, got %v", pc.minConfidence)
+	}
+
+	tests := []struct {
+		column     string
+		category   string
+		confidence float64
+	}{
+		{"iban", "iban", exactNameConfidence},
+		{"email", PIICategoryEmail, exactNameConfidence},
+		{"correo", PIICategoryEmail, exactNameConfidence},
+		{"TEL_2", PIICategoryPhone, patternNameConfidence},
+	}
+	for _, tt := range tests {
+		category, confidence := pc.nameMatch(tt.column)
+		if category != tt.category || confidence != tt.confidence {
+			t.Errorf("Expected %q at %v for %q, got %q at %v", tt.category, tt.confidence, tt.column, category, confidence)
+		}
+	}
+}
+
+func TestPIIClassifierRejectsInvalidPatterns(t *testing.T) {
+	if _, err := newPIIClassifier(types.SecuritySettings{
+		PIINamePatterns: []types.PIINamePattern{{Pattern: "tel_.*"}},
+	}); err == nil {
+		t.Errorf("Expected an error for a pattern without a category")
+	}
+	if _, err := newPIIClassifier(types.SecuritySettings{
+		PIINamePatterns: []types.PIINamePattern{{Pattern: "(", Category: PIICategoryPhone}},
+	}); err == nil {
+		t.Errorf("Expected an error for a pattern that does not compile")
+	}
+}
+
+func TestPIIClassifierClassify(t *testing.T) {
+	pc, _ := newPIIClassifier(types.SecuritySettings{})
+
+	tests := []struct {
+		name       string
+		column     string
+		pattern    string
+		category   string
+		confidence float64
+	}{
+		{"value only", "contact", "Email pattern", PIICategoryEmail, valuePatternConfidence},
+		{"name only", "email", "Text pattern", PIICategoryEmail, exactNameConfidence},
+		{"agreeing evidence", "user_email", "Email pattern", PIICategoryEmail, 1 - (1-partialNameConfidence)*(1-valuePatternConfidence)},
+		{"stronger value", "user_name", "Phone number pattern", PIICategoryPhone, valuePatternConfidence},
+		{"nothing", "total", "Text pattern", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			category, confidence := pc.classify(tt.column, tt.pattern)
+			if category != tt.category || math.Abs(confidence-tt.confidence) > 1e-9 {
+				t.Errorf("Expected %q at %v, got %q at %v", tt.category, tt.confidence, category, confidence)
+			}
+		})
+	}
+}
+
+func TestSecurityAnalyzerIsPotentialPII(t *testing.T) {
+	analyzer, err := NewSecurityAnalyzerWithSettings(nil, types.SecuritySettings{PIIMinConfidence: 0.85})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	sa := analyzer.(*SecurityAnalyzerImpl)
+
+	if !sa.IsPotentialPII("email", "") {
+		t.Errorf("Expected an exact name match to clear 0.85")
+	}
+	if sa.IsPotentialPII("user_name", "") {
+		t.Errorf("Expected a partial name match to fall below 0.85")
+	}
+}
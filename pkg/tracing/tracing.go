@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const instrumentationName = "github.com/cherry-pick"
+
+const (
+	AttrDatabaseType = attribute.Key("db.system")
+	AttrDatabaseName = attribute.Key("db.name")
+	AttrTableName    = attribute.Key("db.table")
+	AttrRowCount     = attribute.Key("db.row_count")
+	AttrTableCount   = attribute.Key("db.table_count")
+	AttrTestID       = attribute.Key("loadtest.id")
+	AttrTargetURL    = attribute.Key("loadtest.url")
+	AttrTestStatus   = attribute.Key("loadtest.status")
+	AttrUsers        = attribute.Key("loadtest.users")
+	AttrRequests     = attribute.Key("loadtest.requests")
+	AttrCrawlURL     = attribute.Key("crawl.url")
+	AttrPages        = attribute.Key("crawl.pages")
+)
+
+var (
+	provider trace.TracerProvider = noop.NewTracerProvider()
+	mu       sync.RWMutex
+)
+
+// SetTracerProvider installs the provider used for all spans; nil restores the no-op
+// default. Spans are not recorded unless a provider is set.
+func SetTracerProvider(tp trace.TracerProvider) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if tp == nil {
+		tp = noop.NewTracerProvider()
+	}
+	provider = tp
+}
+
+func Tracer() trace.Tracer {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return provider.Tracer(instrumentationName)
+}
+
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on the span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
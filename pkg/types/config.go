@@ -1,5 +1,10 @@
 package types
 
+const (
+	DefaultMaxSecurityScanRows int64 = 1000000
+	DefaultPIIMinConfidence          = 0.5
+)
+
 type Config struct {
 	DatabaseConnections map[string]string `json:"database_connections"`
 	AnalysisSettings    AnalysisSettings  `json:"analysis_settings"`
@@ -15,15 +20,28 @@ type AnalysisSettings struct {
 }
 
 type AlertSettings struct {
-	EnableAlerts    bool     `json:"enable_alerts"`
-	EmailRecipients []string `json:"email_recipients"`
-	SlackWebhook    string   `json:"slack_webhook"`
+	EnableAlerts    bool        `json:"enable_alerts"`
+	EmailRecipients []string    `json:"email_recipients"`
+	SlackWebhook    string      `json:"slack_webhook"`
+	Rules           []AlertRule `json:"rules,omitempty"`
 }
 
 type SecuritySettings struct {
-	EnablePIIDetection bool     `json:"enable_pii_detection"`
-	PIIPatterns        []string `json:"pii_patterns"`
-	RequireEncryption  bool     `json:"require_encryption"`
+	EnablePIIDetection  bool     `json:"enable_pii_detection"`
+	PIIPatterns         []string `json:"pii_patterns"`
+	RequireEncryption   bool     `json:"require_encryption"`
+	MaxSecurityScanRows int64    `json:"max_security_scan_rows"`
+	// PIINameCategories maps column-name terms, in any language, to a PII category and
+	// extends the built-in dictionary. PIINamePatterns does the same with regular
+	// expressions for names a fixed term cannot describe.
+	PIINameCategories map[string]string `json:"pii_name_categories,omitempty"`
+	PIINamePatterns   []PIINamePattern  `json:"pii_name_patterns,omitempty"`
+	PIIMinConfidence  float64           `json:"pii_min_confidence,omitempty"`
+}
+
+type PIINamePattern struct {
+	Pattern  string `json:"pattern"`
+	Category string `json:"category"`
 }
 
 type APIResponse struct {
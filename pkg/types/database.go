@@ -57,10 +57,38 @@ type DataProfile struct {
 }
 
 type IndexInfo struct {
-	Name     string   `json:"name"`
-	Columns  []string `json:"columns"`
-	IsUnique bool     `json:"is_unique"`
-	Type     string   `json:"type"`
+	Name      string   `json:"name"`
+	Columns   []string `json:"columns"`
+	IsUnique  bool     `json:"is_unique"`
+	Type      string   `json:"type"`
+	SizeBytes int64    `json:"size_bytes,omitempty"`
+}
+
+// RedundantIndexFinding reports an index that another index on the same table makes
+// unnecessary, either because both cover identical columns or because its columns are
+// a leading prefix of the other's.
+type RedundantIndexFinding struct {
+	Table                 string   `json:"table"`
+	Index                 string   `json:"index"`
+	Columns               []string `json:"columns"`
+	CoveredBy             string   `json:"covered_by"`
+	CoveringColumns       []string `json:"covering_columns"`
+	Reason                string   `json:"reason"`
+	DropStatement         string   `json:"drop_statement"`
+	EstimatedSavingsBytes int64    `json:"estimated_savings_bytes,omitempty"`
+}
+
+// CandidateRelationship is an undeclared foreign key suggested by matching column names
+// and types and by how many sampled source values exist in the target column.
+type CandidateRelationship struct {
+	SourceTable   string  `json:"source_table"`
+	SourceColumn  string  `json:"source_column"`
+	TargetTable   string  `json:"target_table"`
+	TargetColumn  string  `json:"target_column"`
+	Confidence    float64 `json:"confidence"`
+	SampledValues int     `json:"sampled_values"`
+	MatchedValues int     `json:"matched_values"`
+	Reason        string  `json:"reason"`
 }
 
 type Constraint struct {
@@ -0,0 +1,101 @@
+package types
+
+import (
+	"errors"
+	"net/http"
+)
+
+type ErrorCode string
+
+const (
+	ErrCodeConnectionNotFound ErrorCode = "CONNECTION_NOT_FOUND"
+	ErrCodeNotConnected       ErrorCode = "NOT_CONNECTED"
+	ErrCodeInvalidRequest     ErrorCode = "INVALID_REQUEST"
+	ErrCodeNotFound           ErrorCode = "NOT_FOUND"
+	ErrCodeDatabaseError      ErrorCode = "DB_ERROR"
+	ErrCodeUnavailable        ErrorCode = "SERVICE_UNAVAILABLE"
+	ErrCodeRateLimited        ErrorCode = "RATE_LIMITED"
+	ErrCodeUnauthorized       ErrorCode = "UNAUTHORIZED"
+	ErrCodeForbidden          ErrorCode = "FORBIDDEN"
+	ErrCodeInternal           ErrorCode = "INTERNAL_ERROR"
+	ErrCodeCancelled          ErrorCode = "CANCELLED"
+	// ErrCodeConfirmationRequired rejects a risky request until the caller confirms it.
+	ErrCodeConfirmationRequired ErrorCode = "CONFIRMATION_REQUIRED"
+)
+
+type APIError struct {
+	Code    ErrorCode   `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+func NewAPIError(code ErrorCode, message string) *APIError {
+	return &APIError{Code: code, Message: message}
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+func (e *APIError) WithDetails(details interface{}) *APIError {
+	e.Details = details
+	return e
+}
+
+func StatusForCode(code ErrorCode) int {
+	switch code {
+	case ErrCodeConnectionNotFound, ErrCodeNotFound:
+		return http.StatusNotFound
+	case ErrCodeNotConnected, ErrCodeInvalidRequest:
+		return http.StatusBadRequest
+	case ErrCodeUnavailable:
+		return http.StatusServiceUnavailable
+	case ErrCodeRateLimited:
+		return http.StatusTooManyRequests
+	case ErrCodeUnauthorized:
+		return http.StatusUnauthorized
+	case ErrCodeForbidden:
+		return http.StatusForbidden
+	case ErrCodeCancelled:
+		return http.StatusConflict
+	case ErrCodeConfirmationRequired:
+		return http.StatusPreconditionFailed
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func CodeForStatus(status int, internal ErrorCode) ErrorCode {
+	switch {
+	case status == http.StatusNotFound:
+		return ErrCodeNotFound
+	case status == http.StatusServiceUnavailable:
+		return ErrCodeUnavailable
+	case status == http.StatusTooManyRequests:
+		return ErrCodeRateLimited
+	case status == http.StatusUnauthorized:
+		return ErrCodeUnauthorized
+	case status == http.StatusForbidden:
+		return ErrCodeForbidden
+	case status == http.StatusPreconditionFailed:
+		return ErrCodeConfirmationRequired
+	case status >= 400 && status < 500:
+		return ErrCodeInvalidRequest
+	default:
+		return internal
+	}
+}
+
+func ToAPIError(err error, status int, internal ErrorCode) (*APIError, int) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Code != "" {
+		return apiErr, StatusForCode(apiErr.Code)
+	}
+
+	message := ""
+	if err != nil {
+		message = err.Error()
+	}
+
+	return &APIError{Code: CodeForStatus(status, internal), Message: message}, status
+}
@@ -150,3 +150,42 @@ type MongoWTConnection struct {
 	TotalReadIOs          int64 `json:"total_read_ios"`
 	TotalWriteIOs         int64 `json:"total_write_ios"`
 }
+
+// FieldSizeStat estimates how much of a collection's storage a top-level field takes,
+// from a document sample. Share is the field's fraction of all sampled bytes.
+type FieldSizeStat struct {
+	Field       string  `json:"field"`
+	AvgBytes    float64 `json:"avg_bytes"`
+	TotalBytes  int64   `json:"total_bytes"`
+	Occurrences int64   `json:"occurrences"`
+	Share       float64 `json:"share"`
+}
+
+type FieldSizeReport struct {
+	Collection  string            `json:"collection"`
+	SampledDocs int64             `json:"sampled_docs"`
+	Method      string            `json:"method"`
+	Fields      []FieldSizeStat   `json:"fields"`
+	Insights    []DatabaseInsight `json:"insights,omitempty"`
+}
+
+type FieldTypeMismatch struct {
+	Field string `json:"field"`
+	TypeA string `json:"type_a"`
+	TypeB string `json:"type_b"`
+}
+
+// CollectionComparison describes how two collections differ in shape. Similarity is the
+// share of fields and indexes the two have in common, from 0 to 1, with a field of
+// differing type counting as half a match.
+type CollectionComparison struct {
+	CollectionA    string              `json:"collection_a"`
+	CollectionB    string              `json:"collection_b"`
+	SampleSize     int                 `json:"sample_size"`
+	FieldsOnlyInA  []string            `json:"fields_only_in_a"`
+	FieldsOnlyInB  []string            `json:"fields_only_in_b"`
+	TypeMismatches []FieldTypeMismatch `json:"type_mismatches"`
+	IndexesOnlyInA []MongoIndexInfo    `json:"indexes_only_in_a"`
+	IndexesOnlyInB []MongoIndexInfo    `json:"indexes_only_in_b"`
+	Similarity     float64             `json:"similarity"`
+}
@@ -37,6 +37,22 @@ type MonitoringAlert struct {
 	Triggered   bool      `json:"triggered"`
 	LastTrigger time.Time `json:"last_trigger"`
 	Message     string    `json:"message"`
+	// Metric, Value and Subject are set on alerts raised by an AlertRule: the metric
+	// that breached, its value and the table or column it was read from.
+	Metric  string  `json:"metric,omitempty"`
+	Value   float64 `json:"value,omitempty"`
+	Subject string  `json:"subject,omitempty"`
+}
+
+// AlertRule defines a custom alert. Condition reads "<metric> <operator> <threshold>",
+// such as "row_count > 1000000". Scope is a glob that limits table metrics to matching
+// table names and column metrics to matching "table.column" names; empty means all.
+type AlertRule struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Condition string `json:"condition"`
+	Scope     string `json:"scope,omitempty"`
+	Severity  string `json:"severity,omitempty"`
 }
 
 type DataLineage struct {
@@ -48,7 +64,8 @@ type DataLineage struct {
 }
 
 type LineageDependency struct {
-	TableName  string `json:"table_name"`
-	ColumnName string `json:"column_name,omitempty"`
-	Type       string `json:"type"`
+	TableName    string `json:"table_name"`
+	ColumnName   string `json:"column_name,omitempty"`
+	SourceColumn string `json:"source_column,omitempty"`
+	Type         string `json:"type"`
 }
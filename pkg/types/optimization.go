@@ -1,10 +1,45 @@
 package types
 
+import "time"
+
 type OptimizationSuggestion struct {
-	OriginalQuery   string   `json:"original_query"`
-	OptimizedQuery  string   `json:"optimized_query"`
-	Explanation     string   `json:"explanation"`
-	ExpectedGain    string   `json:"expected_gain"`
-	Confidence      float64  `json:"confidence"`
-	AffectedTables  []string `json:"affected_tables"`
+	OriginalQuery        string                `json:"original_query"`
+	OptimizedQuery       string                `json:"optimized_query"`
+	Explanation          string                `json:"explanation"`
+	ExpectedGain         string                `json:"expected_gain"`
+	Confidence           float64               `json:"confidence"`
+	AffectedTables       []string              `json:"affected_tables"`
+	IndexRecommendations []IndexRecommendation `json:"index_recommendations,omitempty"`
+}
+
+// IndexRecommendation proposes an index for the filter and sort columns seen in one or
+// more queries. Where is set for a partial index.
+type IndexRecommendation struct {
+	Table     string   `json:"table"`
+	Columns   []string `json:"columns"`
+	Where     string   `json:"where,omitempty"`
+	Statement string   `json:"statement"`
+	Reason    string   `json:"reason"`
+	Queries   int      `json:"queries"`
+}
+
+type SavedQuery struct {
+	ID               string                  `json:"id"`
+	ConnectionID     string                  `json:"connection_id"`
+	Name             string                  `json:"name"`
+	Query            string                  `json:"query"`
+	CreatedAt        time.Time               `json:"created_at"`
+	UpdatedAt        time.Time               `json:"updated_at"`
+	LatestSuggestion *OptimizationSuggestion `json:"latest_suggestion,omitempty"`
+	Applied          bool                    `json:"applied"`
+	AppliedAt        *time.Time              `json:"applied_at,omitempty"`
+}
+
+type OptimizationHistoryEntry struct {
+	ID           string                 `json:"id"`
+	ConnectionID string                 `json:"connection_id"`
+	QueryID      string                 `json:"query_id,omitempty"`
+	Query        string                 `json:"query"`
+	Suggestion   OptimizationSuggestion `json:"suggestion"`
+	CreatedAt    time.Time              `json:"created_at"`
 }
@@ -0,0 +1,18 @@
+package types
+
+import "time"
+
+// DatabaseOverview gathers what a dashboard shows for one connection from stored
+// state. Analyzed is false, and the report fields empty, until an analysis has run.
+type DatabaseOverview struct {
+	ConnectionID string            `json:"connection_id"`
+	Analyzed     bool              `json:"analyzed"`
+	DatabaseName string            `json:"database_name,omitempty"`
+	DatabaseType string            `json:"database_type,omitempty"`
+	AnalysisTime *time.Time        `json:"analysis_time,omitempty"`
+	Summary      *DatabaseSummary  `json:"summary,omitempty"`
+	TopInsights  []DatabaseInsight `json:"top_insights"`
+	TotalIssues  int               `json:"total_issues"`
+	ActiveAlerts []MonitoringAlert `json:"active_alerts"`
+	Trends       []TrendPoint      `json:"trends"`
+}
@@ -8,3 +8,21 @@ type SecurityIssue struct {
 	Recommendation  string   `json:"recommendation"`
 	AffectedObjects []string `json:"affected_objects"`
 }
+
+const (
+	AccessFindingPublic    = "public_access"
+	AccessFindingWildcard  = "wildcard_privilege"
+	AccessFindingSuperuser = "superuser"
+)
+
+// AccessFinding is an overly broad grant or role found in the database's access control.
+// Object is the table, schema or "*.*" the privileges apply to, and is empty for
+// role-level findings such as a superuser account.
+type AccessFinding struct {
+	Type        string   `json:"type"`
+	Severity    string   `json:"severity"`
+	Grantee     string   `json:"grantee"`
+	Object      string   `json:"object,omitempty"`
+	Privileges  []string `json:"privileges,omitempty"`
+	Description string   `json:"description"`
+}
@@ -0,0 +1,16 @@
+package types
+
+import "time"
+
+const (
+	TrendMetricRowCount    = "row_count"
+	TrendMetricHealthScore = "health_score"
+	TrendMetricTableCount  = "table_count"
+	TrendMetricSize        = "size"
+)
+
+type TrendPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Metric    string    `json:"metric"`
+	Value     float64   `json:"value"`
+}
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultCacheTTL     = 5 * time.Minute
+	DefaultCacheEntries = 256
+)
+
+type cacheEntry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+type TTLCache struct {
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+	mu         sync.Mutex
+}
+
+func NewTTLCache(ttl time.Duration, maxEntries int) *TTLCache {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheEntries
+	}
+
+	return &TTLCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func CacheKey(parts ...string) string {
+	return strings.Join(parts, "|")
+}
+
+func (c *TTLCache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, exists := c.entries[key]
+	if !exists {
+		return nil, false
+	}
+
+	entry := element.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.removeElement(element)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+func (c *TTLCache) Set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(c.ttl)
+	if element, exists := c.entries[key]; exists {
+		entry := element.Value.(*cacheEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, value: value, expiresAt: expiresAt})
+
+	for c.order.Len() > c.maxEntries {
+		c.removeElement(c.order.Back())
+	}
+}
+
+func (c *TTLCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, exists := c.entries[key]; exists {
+		c.removeElement(element)
+	}
+}
+
+func (c *TTLCache) DeletePrefix(prefix string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key, element := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			c.removeElement(element)
+			removed++
+		}
+	}
+	return removed
+}
+
+func (c *TTLCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *TTLCache) removeElement(element *list.Element) {
+	entry := element.Value.(*cacheEntry)
+	delete(c.entries, entry.key)
+	c.order.Remove(element)
+}
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewTTLCacheDefaults(t *testing.T) {
+	cache := NewTTLCache(0, 0)
+	if cache.ttl != DefaultCacheTTL {
+		t.Errorf("Expected TTL %s, got %s", DefaultCacheTTL, cache.ttl)
+	}
+	if cache.maxEntries != DefaultCacheEntries {
+		t.Errorf("Expected %d entries, got %d", DefaultCacheEntries, cache.maxEntries)
+	}
+}
+
+func TestTTLCacheSetAndGet(t *testing.T) {
+	cache := NewTTLCache(time.Minute, 10)
+	cache.Set("users", 42)
+
+	value, ok := cache.Get("users")
+	if !ok {
+		t.Fatalf("Expected a cached value")
+	}
+	if value != 42 {
+		t.Errorf("Expected 42, got %v", value)
+	}
+
+	cache.Set("users", 43)
+	if value, _ := cache.Get("users"); value != 43 {
+		t.Errorf("Expected the value to be replaced, got %v", value)
+	}
+	if cache.Len() != 1 {
+		t.Errorf("Expected 1 entry, got %d", cache.Len())
+	}
+
+	if _, ok := cache.Get("orders"); ok {
+		t.Errorf("Expected a miss for an unknown key")
+	}
+}
+
+func TestTTLCacheExpires(t *testing.T) {
+	cache := NewTTLCache(10*time.Millisecond, 10)
+	cache.Set("users", 42)
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := cache.Get("users"); ok {
+		t.Errorf("Expected the entry to have expired")
+	}
+	if cache.Len() != 0 {
+		t.Errorf("Expected the expired entry to be removed, got %d entries", cache.Len())
+	}
+}
+
+func TestTTLCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewTTLCache(time.Minute, 2)
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")
+	cache.Set("c", 3)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("Expected the least recently used entry to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("Expected %s to be cached", key)
+		}
+	}
+}
+
+func TestTTLCacheDelete(t *testing.T) {
+	cache := NewTTLCache(time.Minute, 10)
+	cache.Set(CacheKey("conn1", "stats"), 1)
+	cache.Set(CacheKey("conn1", "tables"), 2)
+	cache.Set(CacheKey("conn2", "stats"), 3)
+
+	cache.Delete(CacheKey("conn2", "stats"))
+	cache.Delete("missing")
+	if _, ok := cache.Get(CacheKey("conn2", "stats")); ok {
+		t.Errorf("Expected the entry to be deleted")
+	}
+
+	if removed := cache.DeletePrefix(CacheKey("conn1", "")); removed != 2 {
+		t.Errorf("Expected 2 entries removed, got %d", removed)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("Expected an empty cache, got %d entries", cache.Len())
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	if got := CacheKey("conn1", "stats", "users"); got != "conn1|stats|users" {
+		t.Errorf("Expected conn1|stats|users, got %s", got)
+	}
+}
+
+func TestTTLCacheConcurrentUse(t *testing.T) {
+	cache := NewTTLCache(time.Minute, 16)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := fmt.Sprintf("%d-%d", i, j%20)
+				cache.Set(key, j)
+				cache.Get(key)
+				if j%10 == 0 {
+					cache.DeletePrefix(fmt.Sprintf("%d-", i))
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if cache.Len() > 16 {
+		t.Errorf("Expected at most 16 entries, got %d", cache.Len())
+	}
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"hash/maphash"
+	"math"
+	"math/bits"
+)
+
+// DefaultHyperLogLogPrecision gives 16384 registers, a standard error of about 0.8%.
+const DefaultHyperLogLogPrecision = 14
+
+// HyperLogLog estimates the number of distinct values added to it in fixed memory.
+// Sketches are only comparable within one process since the hash seed is random.
+type HyperLogLog struct {
+	precision uint8
+	registers []uint8
+	seed      maphash.Seed
+}
+
+// NewHyperLogLog clamps precision to the 4..18 range.
+func NewHyperLogLog(precision uint8) *HyperLogLog {
+	if precision < 4 {
+		precision = 4
+	}
+	if precision > 18 {
+		precision = 18
+	}
+	return &HyperLogLog{
+		precision: precision,
+		registers: make([]uint8, 1<<precision),
+		seed:      maphash.MakeSeed(),
+	}
+}
+
+func (h *HyperLogLog) Add(value []byte) {
+	hash := maphash.Bytes(h.seed, value)
+
+	index := hash >> (64 - h.precision)
+	rest := hash<<h.precision | 1<<(h.precision-1)
+	rank := uint8(bits.LeadingZeros64(rest)) + 1
+
+	if rank > h.registers[index] {
+		h.registers[index] = rank
+	}
+}
+
+func (h *HyperLogLog) Estimate() uint64 {
+	m := float64(len(h.registers))
+
+	var sum float64
+	zeros := 0
+	for _, register := range h.registers {
+		sum += 1 / float64(uint64(1)<<register)
+		if register == 0 {
+			zeros++
+		}
+	}
+
+	alpha := 0.7213 / (1 + 1.079/m)
+	estimate := alpha * m * m / sum
+
+	// Small cardinalities are estimated more accurately by linear counting.
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestNewHyperLogLogClampsPrecision(t *testing.T) {
+	tests := []struct {
+		precision uint8
+		want      int
+	}{
+		{0, 1 << 4},
+		{10, 1 << 10},
+		{30, 1 << 18},
+	}
+	for _, tt := range tests {
+		if got := len(NewHyperLogLog(tt.precision).registers); got != tt.want {
+			t.Errorf("Expected %d registers for precision %d, got %d", tt.want, tt.precision, got)
+		}
+	}
+}
+
+func TestHyperLogLogEmpty(t *testing.T) {
+	if got := NewHyperLogLog(DefaultHyperLogLogPrecision).Estimate(); got != 0 {
+		t.Errorf("Expected 0, got %d", got)
+	}
+}
+
+func TestHyperLogLogIgnoresDuplicates(t *testing.T) {
+	h := NewHyperLogLog(DefaultHyperLogLogPrecision)
+	for i := 0; i < 1000; i++ {
+		h.Add([]byte(strconv.Itoa(i % 10)))
+	}
+
+	if got := h.Estimate(); got != 10 {
+		t.Errorf("Expected 10, got %d", got)
+	}
+}
+
+func TestHyperLogLogEstimate(t *testing.T) {
+	for _, distinct := range []int{100, 10000, 200000} {
+		h := NewHyperLogLog(DefaultHyperLogLogPrecision)
+		for i := 0; i < distinct; i++ {
+			h.Add([]byte("value-" + strconv.Itoa(i)))
+		}
+
+		got := float64(h.Estimate())
+		if relativeError := math.Abs(got-float64(distinct)) / float64(distinct); relativeError > 0.05 {
+			t.Errorf("Expected about %d, got %.0f (%.1f%% off)", distinct, got, relativeError*100)
+		}
+	}
+}
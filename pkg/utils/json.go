@@ -0,0 +1,83 @@
+package utils
+
+import "reflect"
+
+// WithEmptySlices returns a copy of v in which nil slices are replaced with empty ones,
+// so they encode as [] rather than null. v itself is never modified, which keeps it safe
+// to call on objects shared with other goroutines, and struct values held in maps are
+// reached as well. Byte slices are left alone since they encode as base64 strings.
+func WithEmptySlices(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return copyWithEmptySlices(reflect.ValueOf(v), make(map[copiedPointer]reflect.Value)).Interface()
+}
+
+// copiedPointer identifies a pointer already copied. The type is part of the key since a
+// struct and its first field share an address.
+type copiedPointer struct {
+	address uintptr
+	typ     reflect.Type
+}
+
+func copyWithEmptySlices(v reflect.Value, seen map[copiedPointer]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		key := copiedPointer{address: v.Pointer(), typ: v.Type()}
+		if copied, ok := seen[key]; ok {
+			return copied
+		}
+		copied := reflect.New(v.Type().Elem())
+		seen[key] = copied
+		copied.Elem().Set(copyWithEmptySlices(v.Elem(), seen))
+		return copied
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(copyWithEmptySlices(v.Elem(), seen))
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := copied.Field(i); field.CanSet() {
+				field.Set(copyWithEmptySlices(v.Field(i), seen))
+			}
+		}
+		return copied
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		if v.IsNil() {
+			return reflect.MakeSlice(v.Type(), 0, 0)
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(copyWithEmptySlices(v.Index(i), seen))
+		}
+		return copied
+	case reflect.Array:
+		copied := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(copyWithEmptySlices(v.Index(i), seen))
+		}
+		return copied
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), copyWithEmptySlices(iter.Value(), seen))
+		}
+		return copied
+	}
+	return v
+}
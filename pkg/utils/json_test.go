@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+type jsonTestTable struct {
+	Name    string
+	Columns []string
+	Data    []byte
+	Next    *jsonTestTable
+	hidden  []string
+}
+
+func encode(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return string(data)
+}
+
+func TestWithEmptySlicesReplacesNilSlices(t *testing.T) {
+	table := &jsonTestTable{Name: "users"}
+
+	got := encode(t, WithEmptySlices(table))
+	want := `{"Name":"users","Columns":[],"Data":null,"Next":null}`
+	if got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
+
+func TestWithEmptySlicesLeavesOriginalUntouched(t *testing.T) {
+	table := &jsonTestTable{Name: "users"}
+	WithEmptySlices(table)
+
+	if table.Columns != nil {
+		t.Errorf("Expected original Columns to stay nil, got %#v", table.Columns)
+	}
+}
+
+func TestWithEmptySlicesReachesStructsInMaps(t *testing.T) {
+	tables := map[string]jsonTestTable{"users": {Name: "users"}}
+
+	got := encode(t, WithEmptySlices(tables))
+	want := `{"users":{"Name":"users","Columns":[],"Data":null,"Next":null}}`
+	if got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+	if tables["users"].Columns != nil {
+		t.Errorf("Expected map value to stay unchanged")
+	}
+}
+
+func TestWithEmptySlicesInInterfaces(t *testing.T) {
+	response := map[string]interface{}{
+		"tables": []string(nil),
+		"count":  0,
+		"nested": []interface{}{[]int(nil)},
+	}
+
+	got := encode(t, WithEmptySlices(response))
+	want := `{"count":0,"nested":[[]],"tables":[]}`
+	if got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
+
+func TestWithEmptySlicesKeepsUnexportedFields(t *testing.T) {
+	table := jsonTestTable{Name: "users", hidden: []string{"secret"}}
+
+	copied := WithEmptySlices(table).(jsonTestTable)
+	if len(copied.hidden) != 1 || copied.hidden[0] != "secret" {
+		t.Errorf("Expected unexported field to be copied, got %#v", copied.hidden)
+	}
+}
+
+func TestWithEmptySlicesHandlesCycles(t *testing.T) {
+	table := &jsonTestTable{Name: "users"}
+	table.Next = table
+
+	copied := WithEmptySlices(table).(*jsonTestTable)
+	if copied == table {
+		t.Fatal("Expected a copy, got the original pointer")
+	}
+	if copied.Next != copied {
+		t.Errorf("Expected the cycle to point at the copy")
+	}
+	if copied.Columns == nil {
+		t.Errorf("Expected Columns to be an empty slice")
+	}
+}
+
+func TestWithEmptySlicesNil(t *testing.T) {
+	if got := WithEmptySlices(nil); got != nil {
+		t.Errorf("Expected nil, got %#v", got)
+	}
+}
+
+func TestWithEmptySlicesConcurrentUse(t *testing.T) {
+	shared := map[string]*jsonTestTable{"users": {Name: "users"}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			json.Marshal(WithEmptySlices(shared))
+		}()
+	}
+	wg.Wait()
+}
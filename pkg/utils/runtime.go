@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"runtime"
+	"time"
+)
+
+// RuntimeStats is a snapshot of the process's memory and scheduler health, reported
+// alongside service stats so a growing heap or goroutine count stands out.
+type RuntimeStats struct {
+	Goroutines     int        `json:"goroutines"`
+	HeapAllocBytes uint64     `json:"heap_alloc_bytes"`
+	HeapObjects    uint64     `json:"heap_objects"`
+	SysBytes       uint64     `json:"sys_bytes"`
+	NumGC          uint32     `json:"num_gc"`
+	LastGC         *time.Time `json:"last_gc,omitempty"`
+	LastGCPauseNs  uint64     `json:"last_gc_pause_ns"`
+	TotalGCPauseNs uint64     `json:"total_gc_pause_ns"`
+	GCCPUFraction  float64    `json:"gc_cpu_fraction"`
+}
+
+// ReadRuntimeStats briefly stops the world to read runtime.MemStats, so it suits a
+// stats endpoint but not a hot path.
+func ReadRuntimeStats() RuntimeStats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	stats := RuntimeStats{
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAllocBytes: mem.HeapAlloc,
+		HeapObjects:    mem.HeapObjects,
+		SysBytes:       mem.Sys,
+		NumGC:          mem.NumGC,
+		TotalGCPauseNs: mem.PauseTotalNs,
+		GCCPUFraction:  mem.GCCPUFraction,
+	}
+	if mem.NumGC > 0 {
+		lastGC := time.Unix(0, int64(mem.LastGC))
+		stats.LastGC = &lastGC
+		stats.LastGCPauseNs = mem.PauseNs[(mem.NumGC+255)%256]
+	}
+	return stats
+}
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestReadRuntimeStats(t *testing.T) {
+	runtime.GC()
+	stats := ReadRuntimeStats()
+
+	if stats.Goroutines < 1 {
+		t.Errorf("Expected at least 1 goroutine, got %d", stats.Goroutines)
+	}
+	if stats.HeapAllocBytes == 0 || stats.SysBytes == 0 {
+		t.Errorf("Expected non-zero memory stats, got %+v", stats)
+	}
+	if stats.NumGC == 0 {
+		t.Fatalf("Expected at least 1 GC after runtime.GC")
+	}
+	if stats.LastGC == nil {
+		t.Errorf("Expected LastGC to be set once a GC has run")
+	}
+}